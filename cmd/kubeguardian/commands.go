@@ -0,0 +1,739 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/api"
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/controller"
+	"github.com/NotHarshhaa/kubeguardian/pkg/export"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/onboard"
+	"github.com/NotHarshhaa/kubeguardian/pkg/version"
+)
+
+// command represents a CLI subcommand
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string) error
+}
+
+// commands lists the available CLI subcommands
+var commands = []command{
+	{
+		name:        "check-permissions",
+		description: "Verify RBAC permissions required by enabled rules and actions",
+		run:         runCheckPermissions,
+	},
+	{
+		name:        "preflight",
+		description: "Check API connectivity, RBAC, cluster capabilities, notifier credentials, rules and config; exits non-zero on failure",
+		run:         runPreflight,
+	},
+	{
+		name:        "capabilities",
+		description: "Show the cluster version and the capabilities remediation actions depend on",
+		run:         runCapabilities,
+	},
+	{
+		name:        "undo",
+		description: "List or undo reversible remediation actions via the action API",
+		run:         runUndo,
+	},
+	{
+		name:        "resync",
+		description: "Force an immediate detection cycle, optionally scoped with --namespace or --rule",
+		run:         runResync,
+	},
+	{
+		name:        "trace",
+		description: "Show why a rule does or does not fire for a resource, e.g. 'trace --rule NAME --namespace NS POD'",
+		run:         runTrace,
+	},
+	{
+		name:        "rules",
+		description: "List, enable or disable detection rules at runtime, e.g. 'rules disable --reason TEXT NAME', or show the 'rules audit' trail",
+		run:         runRules,
+	},
+	{
+		name:        "incident",
+		description: "Start, list or end a time-bounded incident mode relaxing cooldowns, e.g. 'incident start --namespace payments --duration 30m'",
+		run:         runIncident,
+	},
+	{
+		name:        "state",
+		description: "Export the operational state as a versioned bundle, or import one, e.g. 'state export > state.json' and 'state import state.json'",
+		run:         runState,
+	},
+	{
+		name:        "config",
+		description: "Inspect the configuration, e.g. 'config effective --namespace NAME'",
+		run:         runConfig,
+	},
+	{
+		name:        "onboard",
+		description: "Propose baseline namespace settings from the restarts, replicas and resource usage of its workloads, e.g. 'onboard --namespace team-x'",
+		run:         runOnboard,
+	},
+	{
+		name:        "report",
+		description: "Export detections and actions of a period from the export indices as CSV or JSON",
+		run:         runReport,
+	},
+	{
+		name:        "hygiene",
+		description: "Scan workloads with the hygiene checks and print the findings as JSON or SARIF, e.g. 'hygiene --format sarif > hygiene.sarif'",
+		run:         runHygiene,
+	},
+}
+
+// findCommand returns the subcommand with the given name, or nil
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// commandFlags holds flags shared by all subcommands
+type commandFlags struct {
+	configFile string
+	kubeconfig string
+}
+
+// newCommandFlagSet creates a flag set with the shared subcommand flags
+func newCommandFlagSet(name string) (*flag.FlagSet, *commandFlags) {
+	flags := &commandFlags{}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&flags.configFile, "config", "", "Path to configuration file")
+	fs.StringVar(&flags.kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to in-cluster or $KUBECONFIG)")
+	return fs, flags
+}
+
+// newRESTConfig loads a kubeconfig file, $KUBECONFIG, or the in-cluster config
+func newRESTConfig(kubeconfig string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kubernetes config: %w", err)
+	}
+	return restConfig, nil
+}
+
+// newKubernetesClient creates a client from a kubeconfig file, $KUBECONFIG, or the in-cluster config
+func newKubernetesClient(kubeconfig string) (kubernetes.Interface, error) {
+	restConfig, err := newRESTConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	return client, nil
+}
+
+// newCommandController loads the configuration and creates a controller for subcommands
+func newCommandController(flags *commandFlags) (*controller.Controller, error) {
+	cfg, err := config.LoadConfig(flags.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	client, err := newKubernetesClient(flags.kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return controller.NewControllerWithClient(client, cfg, metrics.NewMetrics())
+}
+
+// runCheckPermissions verifies RBAC permissions and prints a report
+func runCheckPermissions(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("check-permissions")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctrl, err := newCommandController(flags)
+	if err != nil {
+		return err
+	}
+
+	report, err := ctrl.CheckPermissions(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stdout, report.Summary())
+
+	if !report.DetectionAllowed() || len(report.MissingActions()) > 0 || len(report.MissingFeatures()) > 0 {
+		return fmt.Errorf("missing permissions detected")
+	}
+	return nil
+}
+
+// runPreflight runs the startup self-test and prints a pass/fail report
+func runPreflight(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("preflight")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctrl, err := newCommandController(flags)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "[FAIL] config: %v\n", err)
+		return fmt.Errorf("preflight failed")
+	}
+
+	report := ctrl.Preflight(ctx)
+	fmt.Fprint(os.Stdout, report.Summary())
+
+	if !report.Passed() {
+		return fmt.Errorf("preflight failed")
+	}
+	return nil
+}
+
+// runCapabilities probes the cluster capabilities and prints a report
+func runCapabilities(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("capabilities")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctrl, err := newCommandController(flags)
+	if err != nil {
+		return err
+	}
+
+	report, err := ctrl.CheckCapabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stdout, report.Summary())
+	return nil
+}
+
+// runUndo lists undo records or undoes an action through the action API
+func runUndo(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("undo", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the undo to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	list := fs.Bool("list", false, "List actions that can be undone")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+
+	method, path := http.MethodGet, "/api/v1/undo"
+	if !*list {
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: kubeguardian undo [--server URL] [--token TOKEN] <id> | --list")
+		}
+		method, path = http.MethodPost, "/api/v1/undo/"+url.PathEscape(fs.Arg(0))
+	}
+
+	return callActionAPI(ctx, *server, bearer, method, path, nil)
+}
+
+// runResync forces an immediate detection cycle through the action API
+func runResync(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("resync", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the resync to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	namespace := fs.String("namespace", "", "Only evaluate resources in this namespace")
+	rule := fs.String("rule", "", "Only evaluate this rule")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(controller.ResyncRequest{Namespace: *namespace, Rule: *rule})
+	if err != nil {
+		return err
+	}
+	return callActionAPI(ctx, *server, bearer, http.MethodPost, "/api/v1/resync", bytes.NewReader(body))
+}
+
+// runTrace prints the evaluation trace of a rule against a resource through the action API
+func runTrace(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("trace", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the request to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	rule := fs.String("rule", "", "Name of the rule to evaluate")
+	namespace := fs.String("namespace", "default", "Namespace of the resource")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+	if *rule == "" || fs.NArg() != 1 {
+		return fmt.Errorf("usage: kubeguardian trace [--server URL] [--token TOKEN] --rule RULE [--namespace NS] <name>")
+	}
+
+	query := url.Values{"namespace": {*namespace}, "name": {fs.Arg(0)}}
+	path := "/api/v1/rules/" + url.PathEscape(*rule) + "/trace?" + query.Encode()
+	return callActionAPI(ctx, *server, bearer, http.MethodGet, path, nil)
+}
+
+// runRules lists detection rules, enables or disables one at runtime, or lists the
+// rule audit trail through the action API
+func runRules(ctx context.Context, args []string) error {
+	usage := fmt.Errorf("usage: kubeguardian rules list | audit | enable <name> | disable [--reason TEXT] <name> [--server URL] [--token TOKEN]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("rules "+args[0], flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the change to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	reason := fs.String("reason", "", "Why the rule is enabled or disabled, recorded in the audit trail")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		return callActionAPI(ctx, *server, bearer, http.MethodGet, "/api/v1/rules", nil)
+	case "audit":
+		return callActionAPI(ctx, *server, bearer, http.MethodGet, "/api/v1/rules/audit", nil)
+	case "enable", "disable":
+		if fs.NArg() != 1 {
+			return usage
+		}
+		enabled := args[0] == "enable"
+		body, err := json.Marshal(api.RuleRequest{Enabled: &enabled, Reason: *reason})
+		if err != nil {
+			return err
+		}
+		return callActionAPI(ctx, *server, bearer, http.MethodPut, "/api/v1/rules/"+url.PathEscape(fs.Arg(0)), bytes.NewReader(body))
+	default:
+		return usage
+	}
+}
+
+// runIncident starts, lists or ends incident mode through the action API
+func runIncident(ctx context.Context, args []string) error {
+	usage := fmt.Errorf("usage: kubeguardian incident list | start [--namespace NAME] [--rules A,B] --duration 30m [--cooldown SECONDS] [--reason TEXT] | end <id> [--server URL] [--token TOKEN]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("incident "+args[0], flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the incident to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	namespace := fs.String("namespace", "", "Namespace whose issues are relaxed")
+	rules := fs.String("rules", "", "Comma-separated rules whose issues are relaxed")
+	duration := fs.String("duration", "", "How long incident mode lasts before reverting, e.g. 30m")
+	cooldown := fs.Int("cooldown", 0, "Cooldown in seconds of the actions of matching issues; 0 lets actions repeat every cycle")
+	reason := fs.String("reason", "", "Why incident mode is started, included in the Slack announcement")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		return callActionAPI(ctx, *server, bearer, http.MethodGet, "/api/v1/incidents", nil)
+	case "start":
+		req := controller.IncidentRequest{Namespace: *namespace, Duration: *duration, CooldownSeconds: *cooldown, Reason: *reason}
+		if *rules != "" {
+			req.Rules = strings.Split(*rules, ",")
+		}
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		return callActionAPI(ctx, *server, bearer, http.MethodPost, "/api/v1/incidents", bytes.NewReader(body))
+	case "end":
+		if fs.NArg() != 1 {
+			return usage
+		}
+		return callActionAPI(ctx, *server, bearer, http.MethodDelete, "/api/v1/incidents/"+url.PathEscape(fs.Arg(0)), nil)
+	default:
+		return usage
+	}
+}
+
+// runState exports the operational state bundle to stdout or imports a bundle
+// file through the action API
+func runState(ctx context.Context, args []string) error {
+	usage := fmt.Errorf("usage: kubeguardian state export | import <file> [--server URL] [--token TOKEN]")
+	if len(args) == 0 {
+		return usage
+	}
+
+	fs := flag.NewFlagSet("state "+args[0], flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8082", "Address of the KubeGuardian action API")
+	token := fs.String("token", "", "Bearer token of the user to attribute the import to, e.g. from kubectl create token; defaults to $"+tokenEnv)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	bearer, err := bearerToken(*token)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "export":
+		return callActionAPI(ctx, *server, bearer, http.MethodGet, "/api/v1/state", nil)
+	case "import":
+		if fs.NArg() != 1 {
+			return usage
+		}
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("failed to read state bundle: %w", err)
+		}
+		return callActionAPI(ctx, *server, bearer, http.MethodPost, "/api/v1/state", bytes.NewReader(data))
+	default:
+		return usage
+	}
+}
+
+// tokenEnv is the environment variable holding the bearer token of API commands
+const tokenEnv = "KUBEGUARDIAN_TOKEN"
+
+// bearerToken returns the bearer token of an API command: the --token flag, or
+// the token in $KUBEGUARDIAN_TOKEN
+func bearerToken(flag string) (string, error) {
+	if flag == "" {
+		flag = os.Getenv(tokenEnv)
+	}
+	if flag == "" {
+		return "", fmt.Errorf("--token or $%s is required", tokenEnv)
+	}
+	return flag, nil
+}
+
+// callActionAPI sends a request authenticated with token to the action API and prints the response
+func callActionAPI(ctx context.Context, server, token, method, path string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(server, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call action API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("action API returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	fmt.Fprintln(os.Stdout, strings.TrimSpace(string(data)))
+	return nil
+}
+
+// runConfig runs a config subcommand
+func runConfig(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "effective" {
+		return fmt.Errorf("usage: kubeguardian config effective --namespace NAME [--workload KIND/NAME]")
+	}
+	return runConfigEffective(ctx, args[1:])
+}
+
+// runConfigEffective prints the merged settings that apply to a namespace or workload
+func runConfigEffective(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("config effective")
+	namespace := fs.String("namespace", "", "Namespace to render the effective configuration for")
+	workload := fs.String("workload", "", "Workload whose kubeguardian.io annotations to apply, as deployment/NAME or pod/NAME")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *namespace == "" {
+		return fmt.Errorf("--namespace is required")
+	}
+
+	cfg, err := config.LoadConfig(flags.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// The cluster is only needed to match selectors by labels and to read workload annotations
+	var client kubernetes.Interface
+	var labels map[string]string
+	if len(cfg.NamespaceSelectors) > 0 || *workload != "" {
+		client, err = newKubernetesClient(flags.kubeconfig)
+		if err != nil {
+			return err
+		}
+	}
+	if len(cfg.NamespaceSelectors) > 0 {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, *namespace, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get namespace %s: %w", *namespace, err)
+		}
+		labels = ns.Labels
+	}
+
+	effective := cfg.EffectiveNamespace(*namespace, labels)
+
+	if *workload != "" {
+		annotations, err := workloadAnnotations(ctx, client, *namespace, *workload)
+		if err != nil {
+			return err
+		}
+		for _, err := range effective.ApplyWorkloadOverrides(cfg.OverrideBounds(), *workload, annotations) {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring workload override: %v\n", err)
+		}
+	}
+
+	out, err := yaml.Marshal(effective)
+	if err != nil {
+		return fmt.Errorf("failed to render configuration: %w", err)
+	}
+	fmt.Fprint(os.Stdout, string(out))
+	return nil
+}
+
+// workloadAnnotations returns the annotations of a deployment/NAME or pod/NAME workload
+func workloadAnnotations(ctx context.Context, client kubernetes.Interface, namespace, workload string) (map[string]string, error) {
+	kind, name, found := strings.Cut(workload, "/")
+	if !found || name == "" {
+		return nil, fmt.Errorf("invalid workload %q: expected deployment/NAME or pod/NAME", workload)
+	}
+
+	switch strings.ToLower(kind) {
+	case "deployment", "deploy":
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment %s/%s: %w", namespace, name, err)
+		}
+		return deployment.Annotations, nil
+	case "pod":
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, name, err)
+		}
+		return pod.Annotations, nil
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q: expected deployment or pod", kind)
+	}
+}
+
+// runOnboard inspects the workloads of a namespace and prints a namespaces block
+// with proposed settings, ready to be added to the configuration file
+func runOnboard(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("onboard")
+	namespace := fs.String("namespace", "", "Namespace to propose settings for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *namespace == "" {
+		return fmt.Errorf("usage: kubeguardian onboard --namespace NAME [--config FILE] [--kubeconfig FILE]")
+	}
+
+	cfg, err := config.LoadConfig(flags.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, exists := cfg.NamespacePolicy(*namespace); exists {
+		fmt.Fprintf(os.Stderr, "Warning: namespace %s is already configured, the proposal starts from the defaults\n", *namespace)
+	}
+
+	restConfig, err := newRESTConfig(flags.kubeconfig)
+	if err != nil {
+		return err
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	metricsClient, err := metricsclientset.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics client: %w", err)
+	}
+
+	proposal, err := onboard.NewInspector(client, metricsClient).Propose(ctx, *namespace, cfg.DefaultNamespaceConfig(), time.Now())
+	if err != nil {
+		return err
+	}
+	out, err := proposal.YAML()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stdout, string(out))
+	return nil
+}
+
+// runReport prints an auditable report of all detections, resolutions and remediation
+// actions in a period, read from the Elasticsearch/OpenSearch export indices
+func runReport(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("report")
+	from := fs.String("from", "", "Start of the period, as YYYY-MM-DD or RFC 3339 (inclusive)")
+	to := fs.String("to", "", "End of the period, as YYYY-MM-DD or RFC 3339 (exclusive, defaults to now)")
+	format := fs.String("format", export.FormatCSV, "Output format: csv or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != export.FormatCSV && *format != export.FormatJSON {
+		return fmt.Errorf("unsupported format %q: expected csv or json", *format)
+	}
+	if *from == "" {
+		return fmt.Errorf("usage: kubeguardian report --from TIME [--to TIME] [--format csv|json]")
+	}
+	start, err := parseReportTime(*from)
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	end := time.Now()
+	if *to != "" {
+		if end, err = parseReportTime(*to); err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("--from must be before --to")
+	}
+
+	cfg, err := config.LoadConfig(flags.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Export.URL == "" {
+		return fmt.Errorf("export.url is not configured, reports are read from the export indices")
+	}
+
+	resolver := cfg.SecretResolver()
+	credentials := []*string{&cfg.Export.Username, &cfg.Export.Password, &cfg.Export.APIKey}
+	for _, credential := range credentials {
+		if *credential, err = resolver.Resolve(ctx, *credential); err != nil {
+			return fmt.Errorf("failed to resolve export credentials: %w", err)
+		}
+	}
+
+	history := export.NewHistory(export.Config{
+		URL:         cfg.Export.URL,
+		Username:    cfg.Export.Username,
+		Password:    cfg.Export.Password,
+		APIKey:      cfg.Export.APIKey,
+		IndexPrefix: cfg.Export.IndexPrefix,
+		Timeout:     cfg.Export.Timeout,
+	})
+	issues, err := history.Issues(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	remediations, err := history.Remediations(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	report := export.NewReport(start, end, issues, remediations)
+	if *format == export.FormatJSON {
+		return report.WriteJSON(os.Stdout)
+	}
+	return report.WriteCSV(os.Stdout)
+}
+
+// runHygiene scans the workloads of the cluster with the configured hygiene checks,
+// whether or not scheduled hygiene reports are enabled, and prints the findings
+func runHygiene(ctx context.Context, args []string) error {
+	fs, flags := newCommandFlagSet("hygiene")
+	format := fs.String("format", export.FormatJSON, "Output format: json or sarif")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != export.FormatJSON && *format != hygiene.FormatSARIF {
+		return fmt.Errorf("unsupported format %q: expected json or sarif", *format)
+	}
+
+	cfg, err := config.LoadConfig(flags.configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	client, err := newKubernetesClient(flags.kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	scanner := hygiene.NewScanner(client, hygiene.Config{
+		Checks:               cfg.Detection.Hygiene.Checks,
+		ProductionNamespaces: cfg.Detection.Hygiene.ProductionNamespaces,
+		ExcludeNamespaces:    cfg.Detection.Hygiene.ExcludeNamespaces,
+		PageSize:             500, // Like scheduled hygiene reports
+	})
+	report, err := scanner.Scan(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if *format == hygiene.FormatSARIF {
+		return report.WriteSARIF(os.Stdout, version.Version)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// parseReportTime parses a date (midnight UTC) or an RFC 3339 timestamp
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
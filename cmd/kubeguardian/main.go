@@ -3,19 +3,27 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/NotHarshhaa/kubeguardian/pkg/api"
 	"github.com/NotHarshhaa/kubeguardian/pkg/config"
 	"github.com/NotHarshhaa/kubeguardian/pkg/controller"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
 	"github.com/NotHarshhaa/kubeguardian/pkg/health"
+	"github.com/NotHarshhaa/kubeguardian/pkg/httpserver"
 	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tuning"
 	"github.com/NotHarshhaa/kubeguardian/pkg/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/errgroup"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
@@ -25,6 +33,7 @@ var (
 	leaderElection = flag.Bool("leader-elect", false, "Enable leader election for controller manager. "+
 		"Enabling this will ensure there is only one active controller manager.")
 	dryRunMode = flag.Bool("dry-run", false, "Enable dry-run mode to simulate remediation actions without making changes")
+	mode       = flag.String("mode", "", "Operating mode: enforce or observe (read-only, overrides config)")
 	zapOpts    = zap.Options{
 		Development: true,
 	}
@@ -37,6 +46,20 @@ func init() {
 }
 
 func main() {
+	// Run a subcommand if one is given
+	if len(os.Args) > 1 {
+		if cmd := findCommand(os.Args[1]); cmd != nil {
+			logger := zap.New(zap.UseFlagOptions(&zapOpts))
+			log.SetLogger(logger)
+
+			if err := cmd.run(log.IntoContext(context.Background(), logger), os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	flag.Parse()
 
 	// Setup logging
@@ -67,8 +90,42 @@ func main() {
 		cfg.Remediation.DryRun = true
 	}
 
+	// Override operating mode if specified via command line
+	if *mode != "" {
+		cfg.Mode = *mode
+		if result := cfg.Validate(); !result.Valid {
+			logger.Error(fmt.Errorf("%v", result.Errors), "Invalid configuration")
+			os.Exit(1)
+		}
+	}
+
+	// Observe mode must work with a read-only ClusterRole, so leader election
+	// (which writes Leases) is disabled and the controller keeps its stores in
+	// memory instead of the state ConfigMap
+	if cfg.IsObserveMode() && cfg.Controller.LeaderElection {
+		logger.Info("Observe mode: disabling leader election")
+		cfg.Controller.LeaderElection = false
+	}
+	if cfg.IsObserveMode() && cfg.Detection.State.Backend == detection.StateBackendConfigMap {
+		logger.Info("Observe mode: keeping state in memory instead of the state ConfigMap")
+	}
+
+	// Fit the Go runtime into the container limits
+	settings := tuning.Apply(tuning.Config{
+		AutoMaxProcs:     cfg.Runtime.AutoMaxProcs,
+		MemoryLimitRatio: cfg.Runtime.MemoryLimitRatio,
+		GCPercent:        cfg.Runtime.GCPercent,
+	})
+	logger.Info("Runtime tuned to container limits",
+		"cpuQuota", settings.CPUQuota,
+		"gomaxprocs", settings.MaxProcs,
+		"memoryLimit", settings.MemoryLimit,
+		"softMemoryLimit", settings.SoftMemoryLimit,
+		"gcPercent", settings.GCPercent)
+
 	// Initialize metrics
 	metricsCollector := metrics.NewMetrics()
+	metricsCollector.SetLabelLimits(labelLimits(cfg.Metrics.Cardinality))
 
 	// Create controller
 	ctrl, err := controller.NewController(cfg, metricsCollector)
@@ -79,84 +136,141 @@ func main() {
 
 	// Initialize health checks
 	healthChecker := health.NewHealthCheck(version.Version, ctrl.GetClient())
+	healthChecker.SetControllerStats(ctrl.Stats)
+	if check := ctrl.CacheCheck(); check != nil {
+		healthChecker.RegisterCheck(check, health.TagCritical)
+	}
+	for _, check := range cfg.Controller.HealthChecks {
+		healthChecker.RegisterCheck(health.NewURLCheck(check.Name, check.URL, check.Timeout, check.ExpectedStatus), check.Tags...)
+	}
 
-	// Setup HTTP servers for health checks and metrics
-	setupHTTPServers(cfg, healthChecker, metricsCollector)
+	// Setup HTTP servers for health checks, metrics and the action API
+	servers := httpserver.NewManager(cfg.Controller.ShutdownTimeout)
+	setupHTTPServers(servers, cfg, healthChecker, metricsCollector)
+	if cfg.API.Enabled {
+		if err := setupAPIServer(servers, cfg, ctrl, metricsCollector); err != nil {
+			logger.Error(err, "Failed to set up the action API")
+			os.Exit(1)
+		}
+	}
 
 	// Log configuration
 	logger.Info("Configuration loaded",
+		"mode", cfg.Mode,
 		"metricsAddr", cfg.Controller.MetricsAddr,
 		"probeAddr", cfg.Controller.ProbeAddr,
 		"leaderElection", cfg.Controller.LeaderElection,
 		"remediationEnabled", cfg.Remediation.Enabled,
 		"slackEnabled", cfg.Notification.Slack.Enabled,
 		"dryRun", cfg.Remediation.DryRun,
+		"apiEnabled", cfg.API.Enabled,
 	)
 
-	// Setup signal handling with graceful shutdown
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Start the controller in a goroutine
-	go func() {
+	// Run the controller and the HTTP servers until a signal is received or one
+	// of them fails, which stops the others
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
 		if err := ctrl.Run(ctx); err != nil {
-			logger.Error(err, "Controller failed")
-			os.Exit(1)
+			return fmt.Errorf("controller: %w", err)
 		}
-	}()
-
-	// Start metrics updater
+		return nil
+	})
+	g.Go(func() error {
+		return servers.Run(ctx)
+	})
 	go startMetricsUpdater(ctx, metricsCollector)
 
-	// Wait for signals with graceful shutdown
-	<-sigCh
-	logger.Info("Shutdown signal received, stopping KubeGuardian")
+	done := make(chan error, 1)
+	go func() { done <- g.Wait() }()
 
-	// Graceful shutdown with timeout
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
-
-	// Cancel context to stop the controller
-	cancel()
-
-	// Wait for graceful shutdown or timeout
-	done := make(chan struct{})
-	go func() {
-		// Give some time for cleanup
-		time.Sleep(5 * time.Second)
-		close(done)
-	}()
+	<-ctx.Done()
+	logger.Info("Stopping KubeGuardian")
 
+	// Wait for the detection cycle in progress and in-flight requests, up to the shutdown timeout
+	shutdownTimeout := cfg.Controller.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = httpserver.DefaultShutdownTimeout
+	}
 	select {
-	case <-done:
+	case err := <-done:
+		if err != nil {
+			logger.Error(err, "KubeGuardian failed")
+			os.Exit(1)
+		}
 		logger.Info("KubeGuardian stopped gracefully")
-	case <-shutdownCtx.Done():
+	case <-time.After(shutdownTimeout):
 		logger.Info("KubeGuardian stopped due to timeout")
 	}
 }
 
 // setupHTTPServers sets up HTTP servers for health checks and metrics
-func setupHTTPServers(cfg *config.Config, healthChecker *health.HealthCheck, metricsCollector *metrics.Metrics) {
-	// Setup health check server
-	healthServer := &http.Server{
-		Addr:    cfg.Controller.ProbeAddr,
-		Handler: healthChecker.HTTPHandler(),
-	}
+func setupHTTPServers(servers *httpserver.Manager, cfg *config.Config, healthChecker *health.HealthCheck, metricsCollector *metrics.Metrics) {
+	// Setup health check server; the full health response is served on any other path
+	probes := http.NewServeMux()
+	probes.HandleFunc("/", healthChecker.HTTPHandler())
+	probes.HandleFunc("/readyz", healthChecker.ReadinessHandler())
+	probes.HandleFunc("/healthz", healthChecker.LivenessHandler())
+	healthServer := httpserver.NewServer(cfg.Controller.ProbeAddr, probes, httpTimeouts(cfg.Controller.HTTP), httpserver.Options{
+		Name:         "probe",
+		Metrics:      metricsCollector,
+		QuietSuccess: true,
+	})
+
+	// Setup metrics server for the metrics registered with controller-runtime
+	scrapes := http.NewServeMux()
+	// Exemplars are only exposed in the OpenMetrics format
+	scrapes.Handle("/metrics", promhttp.HandlerFor(crmetrics.Registry, promhttp.HandlerOpts{EnableOpenMetrics: cfg.Tracing.Enabled}))
+	metricsServer := httpserver.NewServer(cfg.Controller.MetricsAddr, scrapes, httpTimeouts(cfg.Controller.HTTP), httpserver.Options{
+		Name:         "metrics",
+		Metrics:      metricsCollector,
+		QuietSuccess: true,
+	})
+
+	servers.Add("probe", healthServer)
+	servers.Add("metrics", metricsServer)
+}
+
+// setupAPIServer sets up the HTTP server for manually triggered actions. Callers
+// are authenticated as configured and authorized with SubjectAccessReviews.
+func setupAPIServer(servers *httpserver.Manager, cfg *config.Config, ctrl *controller.Controller, metricsCollector *metrics.Metrics) error {
+	server := api.NewServer(ctrl)
+	server.SetMaxInFlight(cfg.Runtime.MaxInFlightRequests)
 
-	// Setup readiness probe
-	http.HandleFunc("/readyz", healthChecker.ReadinessHandler())
-	http.HandleFunc("/healthz", healthChecker.LivenessHandler())
+	authenticator := api.NewTokenReviewAuthenticator(ctrl.GetClient())
+	if cfg.API.Authentication.Mode == config.APIAuthRequestHeader {
+		authenticator = api.NewRequestHeaderAuthenticator(cfg.API.Authentication.AllowedNames)
+	}
+	server.SetAuthenticator(api.WithAuthorization(authenticator, ctrl.GetClient()))
 
-	// Setup metrics server (handled by controller-runtime)
-	go func() {
-		if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Log.Error(err, "Health server failed")
+	apiServer := httpserver.NewServer(cfg.API.BindAddress, server.Handler(), httpTimeouts(cfg.Controller.HTTP), httpserver.Options{
+		Name:    "api",
+		Metrics: metricsCollector,
+		Tracing: cfg.Tracing.Enabled,
+	})
+	if cfg.API.TLSCertFile != "" {
+		tlsConfig, err := api.TLSConfig(cfg.API.TLSCertFile, cfg.API.TLSKeyFile, cfg.API.Authentication.ClientCAFile)
+		if err != nil {
+			return err
 		}
-	}()
+		apiServer.TLSConfig = tlsConfig
+	}
+
+	servers.Add("api", apiServer)
+	return nil
+}
+
+// httpTimeouts converts the server-side timeouts of the HTTP servers
+func httpTimeouts(cfg config.HTTPConfig) httpserver.Timeouts {
+	return httpserver.Timeouts{
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
 }
 
 // startMetricsUpdater starts a goroutine to update metrics periodically
@@ -173,3 +287,18 @@ func startMetricsUpdater(ctx context.Context, metricsCollector *metrics.Metrics)
 		}
 	}
 }
+
+// labelLimits converts the metric cardinality configuration
+func labelLimits(cfg config.CardinalityConfig) metrics.LabelLimits {
+	limits := metrics.LabelLimits{
+		MaxNamespaces: cfg.MaxNamespaces,
+		MaxRules:      cfg.MaxRules,
+	}
+	for _, bucket := range cfg.NamespaceBuckets {
+		limits.NamespaceBuckets = append(limits.NamespaceBuckets, metrics.NamespaceBucket{
+			Name:     bucket.Name,
+			Patterns: bucket.Patterns,
+		})
+	}
+	return limits
+}
@@ -0,0 +1,58 @@
+package adaptive
+
+import (
+	"sync"
+	"time"
+)
+
+// Sizer adapts a batch size to the observed latency of the work it sizes. The
+// size doubles while the latency exceeds the target and halves while the latency
+// is below a quarter of it, within its bounds; in between it is left alone so the
+// size does not oscillate.
+type Sizer struct {
+	mu     sync.Mutex
+	size   int
+	min    int
+	max    int
+	target time.Duration
+}
+
+// NewSizer creates a sizer starting at initial, clamped to [min, max]
+func NewSizer(initial, min, max int, target time.Duration) *Sizer {
+	s := &Sizer{min: min, max: max, target: target}
+	s.size = s.clamp(initial)
+	return s
+}
+
+// Size returns the current size
+func (s *Sizer) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Observe adjusts the size to the latency of one run and returns the new size
+func (s *Sizer) Observe(latency time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.target <= 0:
+	case latency > s.target:
+		s.size = s.clamp(s.size * 2)
+	case latency < s.target/4:
+		s.size = s.clamp(s.size / 2)
+	}
+	return s.size
+}
+
+// clamp bounds a size
+func (s *Sizer) clamp(size int) int {
+	if size < s.min {
+		return s.min
+	}
+	if size > s.max {
+		return s.max
+	}
+	return size
+}
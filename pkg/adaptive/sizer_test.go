@@ -0,0 +1,76 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSizerObserve(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   int
+		target    time.Duration
+		latencies []time.Duration
+		want      int
+	}{
+		{
+			name:      "grows while slow",
+			initial:   100,
+			target:    time.Second,
+			latencies: []time.Duration{2 * time.Second, 2 * time.Second},
+			want:      400,
+		},
+		{
+			name:      "grows up to max",
+			initial:   100,
+			target:    time.Second,
+			latencies: []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second},
+			want:      1000,
+		},
+		{
+			name:      "shrinks while fast",
+			initial:   400,
+			target:    time.Second,
+			latencies: []time.Duration{100 * time.Millisecond},
+			want:      200,
+		},
+		{
+			name:      "shrinks down to min",
+			initial:   400,
+			target:    time.Second,
+			latencies: []time.Duration{0, 0, 0, 0},
+			want:      50,
+		},
+		{
+			name:      "holds within the band",
+			initial:   400,
+			target:    time.Second,
+			latencies: []time.Duration{500 * time.Millisecond, time.Second},
+			want:      400,
+		},
+		{
+			name:      "holds without target",
+			initial:   400,
+			latencies: []time.Duration{time.Hour},
+			want:      400,
+		},
+		{
+			name:    "clamps initial size",
+			initial: 10,
+			target:  time.Second,
+			want:    50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizer := NewSizer(tt.initial, 50, 1000, tt.target)
+			for _, latency := range tt.latencies {
+				sizer.Observe(latency)
+			}
+			if got := sizer.Size(); got != tt.want {
+				t.Errorf("Size() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Package analysis produces root-cause hypotheses for detected issues. Analysis
+// is experimental and only runs while the aiAnalysis feature flag is enabled.
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxLogBytes bounds the log excerpt sent for analysis
+const maxLogBytes = 4096
+
+// Context is the information about an issue that is analyzed
+type Context struct {
+	RuleName    string
+	Description string
+	Severity    string
+	Namespace   string
+	Kind        string
+	Name        string
+	Reason      string
+	// Change summarizes the recent rollout of the owning Deployment
+	Change string
+	// Containers are the current and last states of the pod's containers
+	Containers []ContainerState
+	// Events are recent events of the resource, oldest first
+	Events []string
+	// Logs is an excerpt of the logs of the most restarted container
+	Logs string
+}
+
+// ContainerState is the waiting or terminated state of a container
+type ContainerState struct {
+	Name     string
+	Reason   string
+	Message  string
+	ExitCode int32
+}
+
+// Result is a root-cause hypothesis with suggested next steps
+type Result struct {
+	Hypothesis string   `yaml:"hypothesis" json:"hypothesis"`
+	NextSteps  []string `yaml:"nextSteps" json:"nextSteps"`
+}
+
+// Analyzer produces a root-cause hypothesis for an issue
+type Analyzer interface {
+	Analyze(ctx context.Context, issue Context) (*Result, error)
+}
+
+// Collector gathers events and logs of affected resources for analysis
+type Collector struct {
+	client    kubernetes.Interface
+	maxEvents int
+	logLines  int64
+}
+
+// NewCollector creates a collector returning up to maxEvents events and logLines
+// log lines; zero skips events or logs
+func NewCollector(client kubernetes.Interface, maxEvents int, logLines int64) *Collector {
+	return &Collector{client: client, maxEvents: maxEvents, logLines: logLines}
+}
+
+// Collect adds the recent events and, for pods, the container states and a log
+// excerpt of the resource to the context. Failures are logged and leave the
+// context incomplete.
+func (c *Collector) Collect(ctx context.Context, issue *Context, resource runtime.Object) {
+	logger := log.FromContext(ctx)
+
+	if pod, ok := resource.(*corev1.Pod); ok {
+		issue.Containers = containerStates(pod)
+	}
+
+	if c.maxEvents > 0 {
+		events, err := c.events(ctx, issue.Namespace, issue.Kind, issue.Name)
+		if err != nil {
+			logger.Error(err, "Failed to collect events for analysis", "namespace", issue.Namespace, "name", issue.Name)
+		}
+		issue.Events = events
+	}
+
+	if pod, ok := resource.(*corev1.Pod); ok && c.logLines > 0 {
+		logs, err := c.logs(ctx, pod)
+		if err != nil {
+			logger.Error(err, "Failed to collect logs for analysis", "namespace", pod.Namespace, "name", pod.Name)
+		}
+		issue.Logs = logs
+	}
+}
+
+// containerStates returns the waiting and terminated states of a pod's containers,
+// including the last termination of restarted containers
+func containerStates(pod *corev1.Pod) []ContainerState {
+	var states []ContainerState
+	statuses := append(append([]corev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		for _, state := range []corev1.ContainerState{status.State, status.LastTerminationState} {
+			switch {
+			case state.Waiting != nil:
+				states = append(states, ContainerState{Name: status.Name, Reason: state.Waiting.Reason, Message: state.Waiting.Message})
+			case state.Terminated != nil:
+				states = append(states, ContainerState{
+					Name:     status.Name,
+					Reason:   state.Terminated.Reason,
+					Message:  state.Terminated.Message,
+					ExitCode: state.Terminated.ExitCode,
+				})
+			}
+		}
+	}
+	return states
+}
+
+// events returns the most recent events of a resource, oldest first
+func (c *Collector) events(ctx context.Context, namespace, kind, name string) ([]string, error) {
+	list, err := c.client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var events []corev1.Event
+	for _, event := range list.Items {
+		if event.InvolvedObject.Kind == kind && event.InvolvedObject.Name == name {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	if len(events) > c.maxEvents {
+		events = events[len(events)-c.maxEvents:]
+	}
+
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		line := fmt.Sprintf("%s %s: %s", event.Type, event.Reason, event.Message)
+		if event.Count > 1 {
+			line = fmt.Sprintf("%s (x%d)", line, event.Count)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// logs returns the tail of the logs of the most restarted container, preferring
+// the previous instance which holds the output of the crash
+func (c *Collector) logs(ctx context.Context, pod *corev1.Pod) (string, error) {
+	container := ""
+	restarts := int32(-1)
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.RestartCount > restarts {
+			container, restarts = status.Name, status.RestartCount
+		}
+	}
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	logs, err := c.readLogs(ctx, pod, container, restarts > 0)
+	if err != nil && restarts > 0 {
+		logs, err = c.readLogs(ctx, pod, container, false)
+	}
+	return logs, err
+}
+
+func (c *Collector) readLogs(ctx context.Context, pod *corev1.Pod, container string, previous bool) (string, error) {
+	stream, err := c.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &c.logLines,
+		Previous:  previous,
+	}).Stream(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs: %w", err)
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(io.LimitReader(stream, maxLogBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
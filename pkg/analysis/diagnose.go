@@ -0,0 +1,199 @@
+package analysis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Diagnosis is a known cause matched by a failure signature
+type Diagnosis struct {
+	// Signature identifies the matched failure signature, e.g. missing-secret
+	Signature string `yaml:"signature" json:"signature"`
+	Cause     string `yaml:"cause" json:"cause"`
+	Runbook   string `yaml:"runbook,omitempty" json:"runbook,omitempty"`
+}
+
+// signature matches container exit codes, reasons, events and logs to a known cause
+type signature struct {
+	id      string
+	runbook string
+	match   func(issue Context) (string, bool)
+}
+
+var (
+	configMapNotFound = regexp.MustCompile(`configmaps? "([^"]+)" not found`)
+	secretNotFound    = regexp.MustCompile(`secrets? "([^"]+)" not found`)
+	dnsFailure        = regexp.MustCompile(`(?i)(no such host|temporary failure in name resolution|server misbehaving|could not resolve host|name or service not known)`)
+	imageNotFound     = regexp.MustCompile(`(?i)(manifest unknown|not found|does not exist)`)
+	imagePullDenied   = regexp.MustCompile(`(?i)(unauthorized|pull access denied|authentication required|forbidden)`)
+	commandNotFound   = regexp.MustCompile(`(?i)(executable file not found|no such file or directory)`)
+	livenessFailure   = regexp.MustCompile(`Liveness probe failed`)
+)
+
+// signatures are checked in order; more specific causes come first
+var signatures = []signature{
+	{
+		id:      "missing-configmap",
+		runbook: "https://kubernetes.io/docs/tasks/configure-pod-container/configure-pod-configmap/",
+		match: func(issue Context) (string, bool) {
+			if name := findSubmatch(configMapNotFound, issue.stateMessages(), issue.Events); name != "" {
+				return fmt.Sprintf("ConfigMap %q referenced by the pod does not exist", name), true
+			}
+			return "", false
+		},
+	},
+	{
+		id:      "missing-secret",
+		runbook: "https://kubernetes.io/docs/concepts/configuration/secret/",
+		match: func(issue Context) (string, bool) {
+			if name := findSubmatch(secretNotFound, issue.stateMessages(), issue.Events); name != "" {
+				return fmt.Sprintf("Secret %q referenced by the pod does not exist", name), true
+			}
+			return "", false
+		},
+	},
+	{
+		id:      "image-pull-denied",
+		runbook: "https://kubernetes.io/docs/tasks/configure-pod-container/pull-image-private-registry/",
+		match: func(issue Context) (string, bool) {
+			return "The registry rejected the image pull; check imagePullSecrets and registry permissions",
+				issue.imagePullFailed() && matchesAny(imagePullDenied, issue.stateMessages(), issue.Events)
+		},
+	},
+	{
+		id:      "bad-image-tag",
+		runbook: "https://kubernetes.io/docs/concepts/containers/images/",
+		match: func(issue Context) (string, bool) {
+			if issue.hasReason("InvalidImageName") {
+				return "The image reference is invalid", true
+			}
+			return "The image or tag does not exist in the registry",
+				issue.imagePullFailed() && matchesAny(imageNotFound, issue.stateMessages(), issue.Events)
+		},
+	},
+	{
+		id:      "oom-killed",
+		runbook: "https://kubernetes.io/docs/tasks/configure-pod-container/assign-memory-resource/",
+		match: func(issue Context) (string, bool) {
+			return "The container exceeded its memory limit and was killed by the kernel",
+				issue.hasReason("OOMKilled") || issue.Reason == "OOMKilled"
+		},
+	},
+	{
+		id:      "dns-failure",
+		runbook: "https://kubernetes.io/docs/tasks/administer-cluster/dns-debugging-resolution/",
+		match: func(issue Context) (string, bool) {
+			return "The application failed to resolve a hostname; check the service name and cluster DNS",
+				matchesAny(dnsFailure, []string{issue.Logs}, issue.Events)
+		},
+	},
+	{
+		id:      "command-not-found",
+		runbook: "https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/",
+		match: func(issue Context) (string, bool) {
+			return "The container command or entrypoint does not exist in the image",
+				issue.hasExitCode(127) || (issue.hasReason("StartError", "ContainerCannotRun", "RunContainerError") && matchesAny(commandNotFound, issue.stateMessages(), issue.Events))
+		},
+	},
+	{
+		id:      "liveness-probe",
+		runbook: "https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/",
+		match: func(issue Context) (string, bool) {
+			return "The container is restarted because its liveness probe fails",
+				matchesAny(livenessFailure, issue.Events)
+		},
+	},
+}
+
+// Signatures returns the IDs of all failure signatures
+func Signatures() []string {
+	ids := make([]string, 0, len(signatures))
+	for _, s := range signatures {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
+
+// Diagnose matches the issue context against known failure signatures. Runbooks
+// override the default runbook of a signature by ID.
+func Diagnose(issue Context, runbooks map[string]string) []Diagnosis {
+	var diagnoses []Diagnosis
+	for _, s := range signatures {
+		cause, ok := s.match(issue)
+		if !ok {
+			continue
+		}
+		runbook := s.runbook
+		if override, exists := runbooks[s.id]; exists {
+			runbook = override
+		}
+		diagnoses = append(diagnoses, Diagnosis{Signature: s.id, Cause: cause, Runbook: runbook})
+	}
+	return diagnoses
+}
+
+// stateMessages returns the reasons and messages of all container states
+func (c Context) stateMessages() []string {
+	messages := make([]string, 0, len(c.Containers))
+	for _, container := range c.Containers {
+		messages = append(messages, container.Reason+": "+container.Message)
+	}
+	return messages
+}
+
+func (c Context) hasReason(reasons ...string) bool {
+	for _, container := range c.Containers {
+		for _, reason := range reasons {
+			if container.Reason == reason {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c Context) hasExitCode(code int32) bool {
+	for _, container := range c.Containers {
+		if container.ExitCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Context) imagePullFailed() bool {
+	if c.hasReason("ErrImagePull", "ImagePullBackOff") {
+		return true
+	}
+	for _, event := range c.Events {
+		if strings.Contains(event, "Failed to pull image") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny returns true if the pattern matches any of the texts
+func matchesAny(pattern *regexp.Regexp, texts ...[]string) bool {
+	for _, group := range texts {
+		for _, text := range group {
+			if pattern.MatchString(text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findSubmatch returns the first capture group of the first match in the texts
+func findSubmatch(pattern *regexp.Regexp, texts ...[]string) string {
+	for _, group := range texts {
+		for _, text := range group {
+			if match := pattern.FindStringSubmatch(text); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
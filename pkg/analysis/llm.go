@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// systemPrompt instructs the model to answer with a parseable hypothesis
+const systemPrompt = `You are a Kubernetes site reliability engineer. Given a detected issue with its events, ` +
+	`logs and recent changes, state the most likely root cause in one or two sentences and suggest ` +
+	`up to three concrete next steps. Answer only with JSON: {"hypothesis": "...", "nextSteps": ["..."]}`
+
+// LLMConfig configures an OpenAI-compatible chat completions endpoint
+type LLMConfig struct {
+	Endpoint  string
+	Model     string
+	APIKey    string
+	MaxTokens int
+}
+
+// LLMAnalyzer asks a large language model for a root-cause hypothesis. The issue
+// context is sanitized before it is sent.
+type LLMAnalyzer struct {
+	config LLMConfig
+	client *http.Client
+}
+
+// NewLLMAnalyzer creates an analyzer using an OpenAI-compatible chat completions endpoint
+func NewLLMAnalyzer(config LLMConfig, client *http.Client) *LLMAnalyzer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &LLMAnalyzer{config: config, client: client}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Analyze sends the sanitized issue context to the endpoint and parses the answer
+func (a *LLMAnalyzer) Analyze(ctx context.Context, issue Context) (*Result, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: a.config.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt(sanitizeContext(issue))},
+		},
+		Temperature: 0.2,
+		MaxTokens:   a.config.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analysis request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analysis request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.config.APIKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analysis endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("analysis endpoint returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("failed to decode analysis response: %w", err)
+	}
+	if len(chat.Choices) == 0 || strings.TrimSpace(chat.Choices[0].Message.Content) == "" {
+		return nil, fmt.Errorf("analysis endpoint returned no answer")
+	}
+	return parseAnswer(chat.Choices[0].Message.Content), nil
+}
+
+// prompt describes an issue for the model
+func prompt(issue Context) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Issue: %s (%s severity)\n", issue.RuleName, issue.Severity)
+	fmt.Fprintf(&b, "Resource: %s %s/%s\n", issue.Kind, issue.Namespace, issue.Name)
+	fmt.Fprintf(&b, "Reason: %s\n", issue.Reason)
+	fmt.Fprintf(&b, "Description: %s\n", issue.Description)
+	if issue.Change != "" {
+		fmt.Fprintf(&b, "Recent change: %s\n", issue.Change)
+	}
+	if len(issue.Containers) > 0 {
+		b.WriteString("\nContainers:\n")
+		for _, container := range issue.Containers {
+			fmt.Fprintf(&b, "- %s: %s (exit code %d) %s\n", container.Name, container.Reason, container.ExitCode, container.Message)
+		}
+	}
+	if len(issue.Events) > 0 {
+		b.WriteString("\nEvents:\n")
+		for _, event := range issue.Events {
+			fmt.Fprintf(&b, "- %s\n", event)
+		}
+	}
+	if issue.Logs != "" {
+		fmt.Fprintf(&b, "\nLog excerpt:\n%s\n", issue.Logs)
+	}
+	return b.String()
+}
+
+// parseAnswer parses the JSON answer, tolerating code fences. Answers that are
+// not JSON are used as the hypothesis.
+func parseAnswer(content string) *Result {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	var result Result
+	if err := json.Unmarshal([]byte(content), &result); err != nil || result.Hypothesis == "" {
+		return &Result{Hypothesis: content}
+	}
+	return &result
+}
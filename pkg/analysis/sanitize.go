@@ -0,0 +1,54 @@
+package analysis
+
+import "regexp"
+
+// redacted replaces sensitive values before issue context leaves the cluster
+const redacted = "[REDACTED]"
+
+var sensitivePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	// key=value and key: value pairs with sensitive keys
+	{regexp.MustCompile(`(?i)\b((?:[a-z0-9_-]*)(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|credentials?))(["']?\s*[:=]\s*)("[^"]*"|'[^']*'|\S+)`), "${1}${2}" + redacted},
+	// Authorization headers
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-z0-9._~+/=-]{8,}`), "${1} " + redacted},
+	// Credentials in URLs
+	{regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`), "${1}" + redacted + "@"},
+	// JSON web tokens
+	{regexp.MustCompile(`\beyJ[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+`), redacted},
+	// AWS access key IDs
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), redacted},
+	// Slack tokens
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]+`), redacted},
+	// Private keys
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), redacted},
+}
+
+// Sanitize redacts credentials, tokens and keys from text
+func Sanitize(text string) string {
+	for _, p := range sensitivePatterns {
+		text = p.pattern.ReplaceAllString(text, p.replacement)
+	}
+	return text
+}
+
+// sanitizeContext redacts all free-form fields of an issue context
+func sanitizeContext(issue Context) Context {
+	issue.Description = Sanitize(issue.Description)
+	issue.Reason = Sanitize(issue.Reason)
+	issue.Change = Sanitize(issue.Change)
+	issue.Logs = Sanitize(issue.Logs)
+	events := make([]string, len(issue.Events))
+	for i, event := range issue.Events {
+		events[i] = Sanitize(event)
+	}
+	issue.Events = events
+	containers := make([]ContainerState, len(issue.Containers))
+	for i, container := range issue.Containers {
+		container.Message = Sanitize(container.Message)
+		containers[i] = container
+	}
+	issue.Containers = containers
+	return issue
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+)
+
+var (
+	// ErrUnauthenticated is returned when a request carries no valid identity
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is returned when the caller may not make a request
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authenticator identifies the caller of an API request. It returns an error
+// wrapping ErrUnauthenticated or ErrForbidden for callers it rejects; other
+// errors mean the caller could not be checked.
+type Authenticator interface {
+	Authenticate(r *http.Request) (remediation.Requester, error)
+}
+
+// AuthenticatorFunc is a function used as an Authenticator
+type AuthenticatorFunc func(r *http.Request) (remediation.Requester, error)
+
+// Authenticate calls f(r)
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (remediation.Requester, error) {
+	return f(r)
+}
+
+// NewTokenReviewAuthenticator authenticates the bearer token of requests with a
+// TokenReview, as the Kubernetes API server would
+func NewTokenReviewAuthenticator(client kubernetes.Interface) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (remediation.Requester, error) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			return remediation.Requester{}, fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+		}
+
+		review, err := client.AuthenticationV1().TokenReviews().Create(r.Context(), &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: strings.TrimSpace(token)},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return remediation.Requester{}, fmt.Errorf("failed to review token: %w", err)
+		}
+		if !review.Status.Authenticated {
+			return remediation.Requester{}, fmt.Errorf("%w: invalid bearer token", ErrUnauthenticated)
+		}
+
+		user := review.Status.User
+		requester := remediation.Requester{User: user.Username, Groups: user.Groups, Source: "api"}
+		for key, values := range user.Extra {
+			if requester.Extra == nil {
+				requester.Extra = make(map[string][]string)
+			}
+			requester.Extra[key] = values
+		}
+		return requester, nil
+	})
+}
+
+// NewRequestHeaderAuthenticator trusts the X-Remote-User, X-Remote-Group and
+// X-Remote-Extra-* headers only on TLS connections whose client certificate was
+// verified against the request header client CA and, unless allowedNames is
+// empty, has one of allowedNames as its common name, as in Kubernetes front-proxy
+// authentication. The headers of any other connection are ignored.
+func NewRequestHeaderAuthenticator(allowedNames []string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (remediation.Requester, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return remediation.Requester{}, fmt.Errorf("%w: no verified front proxy client certificate", ErrUnauthenticated)
+		}
+		proxy := r.TLS.VerifiedChains[0][0].Subject.CommonName
+		if len(allowedNames) > 0 && !slices.Contains(allowedNames, proxy) {
+			return remediation.Requester{}, fmt.Errorf("%w: front proxy %q is not allowed", ErrUnauthenticated, proxy)
+		}
+
+		requester, ok := requesterFromHeaders(r.Header)
+		if !ok {
+			return remediation.Requester{}, fmt.Errorf("%w: missing %s header", ErrUnauthenticated, HeaderRemoteUser)
+		}
+		return requester, nil
+	})
+}
+
+// WithAuthorization authorizes the callers identified by authenticator with a
+// SubjectAccessReview of the request path and method, so access to the API is
+// granted with nonResourceURLs rules, e.g. verbs get on /api/v1/issues or create
+// on /api/v1/actions
+func WithAuthorization(authenticator Authenticator, client kubernetes.Interface) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (remediation.Requester, error) {
+		requester, err := authenticator.Authenticate(r)
+		if err != nil {
+			return requester, err
+		}
+
+		allowed, err := authorize(r.Context(), client, requester, r.URL.Path, authorizationVerb(r.Method))
+		if err != nil {
+			return remediation.Requester{}, err
+		}
+		if !allowed {
+			return remediation.Requester{}, fmt.Errorf("%w: %s may not %s %s", ErrForbidden, requester.User, authorizationVerb(r.Method), r.URL.Path)
+		}
+		return requester, nil
+	})
+}
+
+// authorize asks the API server whether the requester may use verb on path
+func authorize(ctx context.Context, client kubernetes.Interface, requester remediation.Requester, path, verb string) (bool, error) {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:                  requester.User,
+			Groups:                requester.Groups,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+		},
+	}
+	for key, values := range requester.Extra {
+		if review.Spec.Extra == nil {
+			review.Spec.Extra = make(map[string]authorizationv1.ExtraValue)
+		}
+		review.Spec.Extra[key] = values
+	}
+
+	result, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to review access: %w", err)
+	}
+	return result.Status.Allowed, nil
+}
+
+// authorizationVerb maps an HTTP method to the verb of a non-resource request,
+// as the Kubernetes API server does
+func authorizationVerb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "get"
+	}
+}
+
+// TLSConfig returns the TLS configuration serving the API with the certificate
+// and key files. With a client CA file, client certificates are verified against
+// it when presented, which the request header mode relies on.
+func TLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API serving certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", clientCAFile)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
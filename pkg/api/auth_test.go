@@ -0,0 +1,119 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestServerWithoutAuthenticatorRejectsRequests(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/undo", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	req.Header.Set(HeaderRemoteGroup, "system:masters")
+
+	rec := httptest.NewRecorder()
+	NewServer(&fakeTrigger{}).Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestTokenReviewAuthenticatorWithAuthorization(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if review.Spec.Token == "alice-token" {
+			review.Status = authenticationv1.TokenReviewStatus{
+				Authenticated: true,
+				User:          authenticationv1.UserInfo{Username: "alice", Groups: []string{"sre"}},
+			}
+		}
+		return true, review, nil
+	})
+	var reviewed *authorizationv1.SubjectAccessReview
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviewed = action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviewed.Status.Allowed = reviewed.Spec.NonResourceAttributes.Verb == "get"
+		return true, reviewed, nil
+	})
+	authenticator := WithAuthorization(NewTokenReviewAuthenticator(client), client)
+
+	tests := []struct {
+		name    string
+		method  string
+		token   string
+		wantErr error
+	}{
+		{"authorized", http.MethodGet, "alice-token", nil},
+		{"not authorized", http.MethodPost, "alice-token", ErrForbidden},
+		{"invalid token", http.MethodGet, "mallory-token", ErrUnauthenticated},
+		{"no token", http.MethodGet, "", ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/undo", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			// Identity headers are ignored in this mode
+			req.Header.Set(HeaderRemoteUser, "admin")
+
+			requester, err := authenticator.Authenticate(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (requester.User != "alice" || requester.Source != "api") {
+				t.Errorf("requester = %+v, want alice from the api", requester)
+			}
+		})
+	}
+
+	if reviewed.Spec.User != "alice" || reviewed.Spec.NonResourceAttributes.Path != "/api/v1/undo" {
+		t.Errorf("reviewed access = %+v, want alice on /api/v1/undo", reviewed.Spec)
+	}
+}
+
+func TestRequestHeaderAuthenticatorRequiresVerifiedProxy(t *testing.T) {
+	authenticator := NewRequestHeaderAuthenticator([]string{"front-proxy"})
+	verified := func(commonName string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	tests := []struct {
+		name    string
+		tls     *tls.ConnectionState
+		wantErr error
+	}{
+		{"allowed proxy", verified("front-proxy"), nil},
+		{"other client certificate", verified("mallory"), ErrUnauthenticated},
+		{"unverified certificate", &tls.ConnectionState{}, ErrUnauthenticated},
+		{"plain HTTP", nil, ErrUnauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/undo", nil)
+			req.TLS = tt.tls
+			req.Header.Set(HeaderRemoteUser, "alice")
+			req.Header.Set(HeaderRemoteGroup, "sre")
+
+			requester, err := authenticator.Authenticate(req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (requester.User != "alice" || len(requester.Groups) != 1) {
+				t.Errorf("requester = %+v, want alice in sre", requester)
+			}
+		})
+	}
+}
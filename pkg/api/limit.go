@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+)
+
+// SetMaxInFlight bounds the requests served concurrently; requests beyond the
+// limit are rejected with 429 Too Many Requests. Zero is unlimited.
+func (s *Server) SetMaxInFlight(max int) {
+	s.maxInFlight = max
+}
+
+// limitInFlight rejects requests while max requests are being served
+func limitInFlight(next http.Handler, max int) http.Handler {
+	slots := make(chan struct{}, max)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, ErrorResponse{Error: "too many requests in flight"})
+		}
+	})
+}
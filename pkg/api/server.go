@@ -0,0 +1,942 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/controller"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overlay"
+	"github.com/NotHarshhaa/kubeguardian/pkg/queue"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+	"github.com/NotHarshhaa/kubeguardian/pkg/version"
+)
+
+// Headers set by the authenticating front proxy in front of the API, trusted only
+// in the request header authentication mode
+const (
+	HeaderRemoteUser        = "X-Remote-User"
+	HeaderRemoteGroup       = "X-Remote-Group"
+	HeaderRemoteExtraPrefix = "X-Remote-Extra-"
+)
+
+// ActionTrigger executes and undoes manually requested actions
+type ActionTrigger interface {
+	TriggerAction(ctx context.Context, req controller.ActionRequest) (*remediation.Result, error)
+	UndoAction(ctx context.Context, id string, requester remediation.Requester) (*remediation.Result, error)
+	UndoRecords() []remediation.UndoRecord
+}
+
+// FeatureToggler lists and changes feature flags at runtime; the API serves the
+// feature endpoints if the action trigger implements it
+type FeatureToggler interface {
+	Features() []features.Status
+	SetFeature(ctx context.Context, name string, enabled bool, requester remediation.Requester) (features.Status, error)
+}
+
+// IssueLister lists the active issues; the API serves the issues endpoint if the
+// action trigger implements it
+type IssueLister interface {
+	ActiveIssues() []tracker.Record
+}
+
+// Resyncer forces immediate detection cycles; the API serves the resync endpoint
+// if the action trigger implements it
+type Resyncer interface {
+	Resync(ctx context.Context, req controller.ResyncRequest) (*controller.ResyncResult, error)
+}
+
+// RuleTracer evaluates a rule against a single resource; the API serves the rule
+// trace endpoint if the action trigger implements it
+type RuleTracer interface {
+	TraceRule(ctx context.Context, rule, namespace, name string) (*detection.Trace, error)
+}
+
+// RuleLister lists the loaded detection rules; the API serves the rules endpoint
+// if the action trigger implements it
+type RuleLister interface {
+	Rules() []controller.RuleStatus
+}
+
+// RuleToggler enables and disables detection rules at runtime; the API serves the
+// rule toggle and audit endpoints if the action trigger implements it
+type RuleToggler interface {
+	SetRuleEnabled(ctx context.Context, name string, enabled bool, reason string, requester remediation.Requester) (controller.RuleStatus, error)
+	RuleAudit(ctx context.Context) ([]overlay.AuditRecord, error)
+}
+
+// QueueManager lists and drains the remediation queue; the API serves the queue
+// endpoint if the action trigger implements it
+type QueueManager interface {
+	RemediationQueue() ([]queue.Item, error)
+	DrainRemediationQueue(ctx context.Context, requester remediation.Requester) ([]queue.Item, error)
+}
+
+// HygieneReporter scans workloads for advisory configuration findings; the API
+// serves the hygiene endpoint if the action trigger implements it
+type HygieneReporter interface {
+	HygieneReport(ctx context.Context) (*hygiene.Report, error)
+}
+
+// UsageReporter reports the monthly usage of teams; the API serves the usage
+// endpoint if the action trigger implements it
+type UsageReporter interface {
+	UsageReport(ctx context.Context, month time.Time) (*digest.UsageReport, error)
+}
+
+// StateManager exports and imports the operational state as a bundle; the API
+// serves the state endpoint if the action trigger implements it
+type StateManager interface {
+	ExportState(ctx context.Context) (*controller.StateBundle, error)
+	ImportState(ctx context.Context, bundle *controller.StateBundle, requester remediation.Requester) (*controller.ImportResult, error)
+}
+
+// IncidentManager starts, lists and ends incidents relaxing remediation; the API
+// serves the incidents endpoints if the action trigger implements it
+type IncidentManager interface {
+	StartIncident(ctx context.Context, req controller.IncidentRequest, requester remediation.Requester) (controller.Incident, error)
+	EndIncident(ctx context.Context, id string, requester remediation.Requester) (controller.Incident, error)
+	Incidents() []controller.Incident
+}
+
+// ConfigDriftReporter reports the configuration hashes of KubeGuardian
+// instances; the API serves the config drift endpoint if the action trigger
+// implements it
+type ConfigDriftReporter interface {
+	ConfigDrift(ctx context.Context) (controller.ConfigDriftReport, error)
+}
+
+// DecisionReader reads the decision log of the detection rules; the API serves
+// the decisions endpoint
+type DecisionReader interface {
+	Decisions(query detection.DecisionQuery) (detection.DecisionReport, error)
+}
+
+// defaultDecisionLimit bounds the decisions returned without a limit
+const defaultDecisionLimit = 100
+
+// FeatureRequest changes a feature flag
+type FeatureRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// RuleRequest enables or disables a detection rule
+type RuleRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Server serves the KubeGuardian action API
+type Server struct {
+	trigger   ActionTrigger
+	features  FeatureToggler
+	issues    IssueLister
+	resyncer  Resyncer
+	tracer    RuleTracer
+	rules     RuleLister
+	toggler   RuleToggler
+	queue     QueueManager
+	hygiene   HygieneReporter
+	usage     UsageReporter
+	state     StateManager
+	incidents IncidentManager
+	drift     ConfigDriftReporter
+	decisions DecisionReader
+
+	authenticator Authenticator // Nil rejects every request
+	maxInFlight   int           // Zero is unlimited
+}
+
+// ErrorResponse represents an API error
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer creates a new API server
+func NewServer(trigger ActionTrigger) *Server {
+	server := &Server{trigger: trigger}
+	if toggler, ok := trigger.(FeatureToggler); ok {
+		server.features = toggler
+	}
+	if lister, ok := trigger.(IssueLister); ok {
+		server.issues = lister
+	}
+	if resyncer, ok := trigger.(Resyncer); ok {
+		server.resyncer = resyncer
+	}
+	if tracer, ok := trigger.(RuleTracer); ok {
+		server.tracer = tracer
+	}
+	if lister, ok := trigger.(RuleLister); ok {
+		server.rules = lister
+	}
+	if toggler, ok := trigger.(RuleToggler); ok {
+		server.toggler = toggler
+	}
+	if manager, ok := trigger.(QueueManager); ok {
+		server.queue = manager
+	}
+	if reporter, ok := trigger.(HygieneReporter); ok {
+		server.hygiene = reporter
+	}
+	if reporter, ok := trigger.(UsageReporter); ok {
+		server.usage = reporter
+	}
+	if manager, ok := trigger.(StateManager); ok {
+		server.state = manager
+	}
+	if manager, ok := trigger.(IncidentManager); ok {
+		server.incidents = manager
+	}
+	if reporter, ok := trigger.(ConfigDriftReporter); ok {
+		server.drift = reporter
+	}
+	if reader, ok := trigger.(DecisionReader); ok {
+		server.decisions = reader
+	}
+	return server
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/actions", s.handleActions)
+	mux.HandleFunc("/api/v1/undo", s.handleUndoRecords)
+	mux.HandleFunc("/api/v1/undo/", s.handleUndo)
+	if s.features != nil {
+		mux.HandleFunc("/api/v1/features", s.handleFeatures)
+		mux.HandleFunc("/api/v1/features/", s.handleFeature)
+	}
+	if s.issues != nil {
+		mux.HandleFunc("/api/v1/issues", s.handleIssues)
+	}
+	if s.resyncer != nil {
+		mux.HandleFunc("/api/v1/resync", s.handleResync)
+	}
+	if s.rules != nil {
+		mux.HandleFunc("/api/v1/rules", s.handleRules)
+	}
+	if s.toggler != nil {
+		mux.HandleFunc("/api/v1/rules/audit", s.handleRuleAudit)
+	}
+	if s.tracer != nil || s.toggler != nil {
+		mux.HandleFunc("/api/v1/rules/", s.handleRule)
+	}
+	if s.queue != nil {
+		mux.HandleFunc("/api/v1/queue", s.handleQueue)
+	}
+	if s.hygiene != nil {
+		mux.HandleFunc("/api/v1/hygiene", s.handleHygiene)
+	}
+	if s.usage != nil {
+		mux.HandleFunc("/api/v1/usage", s.handleUsage)
+	}
+	if s.state != nil {
+		mux.HandleFunc("/api/v1/state", s.handleState)
+	}
+	if s.incidents != nil {
+		mux.HandleFunc("/api/v1/incidents", s.handleIncidents)
+		mux.HandleFunc("/api/v1/incidents/", s.handleIncident)
+	}
+	if s.drift != nil {
+		mux.HandleFunc("/api/v1/config/drift", s.handleConfigDrift)
+	}
+	if s.decisions != nil {
+		mux.HandleFunc("/api/v1/decisions", s.handleDecisions)
+	}
+	if s.maxInFlight > 0 {
+		return limitInFlight(mux, s.maxInFlight)
+	}
+	return mux
+}
+
+// handleActions triggers an action on behalf of the authenticated user
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var req controller.ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	req.Requester = requester
+
+	if err := req.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := s.trigger.TriggerAction(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, controller.ErrObserveMode) || errors.Is(err, remediation.ErrConcurrentChange) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, controller.ErrFeatureDisabled) {
+			writeJSON(w, http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to trigger action", "action", req.Action, "requestedBy", requester.User)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleUndoRecords lists the reversible actions that can be undone
+func (s *Server) handleUndoRecords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.trigger.UndoRecords())
+}
+
+// handleUndo restores the state recorded before a reversible action
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/undo/")
+	if id == "" || strings.Contains(id, "/") {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid undo id"})
+		return
+	}
+
+	result, err := s.trigger.UndoAction(r.Context(), id, requester)
+	if err != nil {
+		switch {
+		case errors.Is(err, remediation.ErrUndoNotFound):
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, remediation.ErrAlreadyUndone), errors.Is(err, controller.ErrObserveMode), errors.Is(err, remediation.ErrConcurrentChange):
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			log.FromContext(r.Context()).Error(err, "Failed to undo action", "id", id, "requestedBy", requester.User)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleIssues lists the active issues with their runbooks
+func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.issues.ActiveIssues())
+}
+
+// handleRules lists the detection rules with their owner, source and last change
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.rules.Rules())
+}
+
+// handleResync runs a detection cycle immediately on behalf of the authenticated user
+func (s *Server) handleResync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	// The scope is optional; an empty body resyncs everything
+	var req controller.ResyncRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+			return
+		}
+	}
+	req.Requester = requester
+
+	result, err := s.resyncer.Resync(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrUnknownRule):
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, controller.ErrNotRunning):
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+		default:
+			log.FromContext(r.Context()).Error(err, "Resync failed", "requestedBy", requester.User)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleRule serves the trace of a rule, or enables or disables it
+func (s *Server) handleRule(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/rules/")
+	if rule, found := strings.CutSuffix(path, "/trace"); found && s.tracer != nil {
+		s.handleRuleTrace(w, r, rule)
+		return
+	}
+	if s.toggler != nil && path != "" && !strings.Contains(path, "/") {
+		s.handleRuleToggle(w, r, path)
+		return
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+}
+
+// handleRuleToggle enables or disables a detection rule at runtime on behalf of the
+// authenticated user
+func (s *Server) handleRuleToggle(w http.ResponseWriter, r *http.Request, rule string) {
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	if req.Enabled == nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "enabled is required"})
+		return
+	}
+
+	status, err := s.toggler.SetRuleEnabled(r.Context(), rule, *req.Enabled, req.Reason, requester)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrUnknownRule):
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, controller.ErrRuleUnavailable):
+			writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			log.FromContext(r.Context()).Error(err, "Failed to change rule", "rule", rule, "requestedBy", requester.User)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRuleAudit lists the rules enabled and disabled at runtime, newest first
+func (s *Server) handleRuleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	records, err := s.toggler.RuleAudit(r.Context())
+	if err != nil {
+		log.FromContext(r.Context()).Error(err, "Failed to list rule audit records")
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, records)
+}
+
+// handleRuleTrace evaluates a rule against the resource named by the namespace and
+// name query parameters and returns the evaluation trace
+func (s *Server) handleRuleTrace(w http.ResponseWriter, r *http.Request, rule string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	if rule == "" || strings.Contains(rule, "/") {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "not found"})
+		return
+	}
+
+	namespace, name := r.URL.Query().Get("namespace"), r.URL.Query().Get("name")
+	if namespace == "" || name == "" {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "namespace and name are required"})
+		return
+	}
+
+	trace, err := s.tracer.TraceRule(r.Context(), rule, namespace, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrUnknownRule), apierrors.IsNotFound(err):
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		default:
+			log.FromContext(r.Context()).Error(err, "Failed to trace rule", "rule", rule, "namespace", namespace, "name", name)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trace)
+}
+
+// handleQueue lists the queued remediation actions, or drains the queue on behalf
+// of the authenticated user
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var items []queue.Item
+	var err error
+	if r.Method == http.MethodDelete {
+		items, err = s.queue.DrainRemediationQueue(r.Context(), requester)
+	} else {
+		items, err = s.queue.RemediationQueue()
+	}
+	if err != nil {
+		if errors.Is(err, controller.ErrQueueDisabled) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to serve remediation queue", "method", r.Method, "requestedBy", requester.User)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// handleIncidents lists the active incidents, or starts one on behalf of the
+// authenticated user
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, s.incidents.Incidents())
+		return
+	}
+
+	var req controller.IncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	incident, err := s.incidents.StartIncident(r.Context(), req, requester)
+	if err != nil {
+		switch {
+		case errors.Is(err, controller.ErrInvalidIncident):
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, controller.ErrUnknownRule):
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		default:
+			log.FromContext(r.Context()).Error(err, "Failed to start incident", "requestedBy", requester.User)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, incident)
+}
+
+// handleIncident ends an active incident on behalf of the authenticated user
+func (s *Server) handleIncident(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/incidents/")
+	incident, err := s.incidents.EndIncident(r.Context(), id, requester)
+	if err != nil {
+		if errors.Is(err, controller.ErrIncidentNotFound) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to end incident", "incident", id, "requestedBy", requester.User)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, incident)
+}
+
+// handleConfigDrift returns the configuration hash of this instance and the
+// hashes of the instances it was compared with; peers read their hash from it
+func (s *Server) handleConfigDrift(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	report, err := s.drift.ConfigDrift(r.Context())
+	if err != nil {
+		if errors.Is(err, controller.ErrConfigDriftDisabled) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to report config drift")
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleDecisions returns the decision counts of the last detection cycle and
+// the sampled decisions, filtered by rule, namespace and outcome
+func (s *Server) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	query := detection.DecisionQuery{
+		Rule:      params.Get("rule"),
+		Namespace: params.Get("namespace"),
+		Outcome:   params.Get("outcome"),
+		Limit:     defaultDecisionLimit,
+	}
+	if query.Outcome != "" && query.Outcome != detection.DecisionFired && query.Outcome != detection.DecisionSkipped {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "outcome must be fired or skipped"})
+		return
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive number"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	report, err := s.decisions.Decisions(query)
+	if err != nil {
+		if errors.Is(err, detection.ErrDecisionLogDisabled) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to read decisions")
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleHygiene scans the workloads of the cluster and returns the advisory
+// findings, as SARIF with format=sarif
+func (s *Server) handleHygiene(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	report, err := s.hygiene.HygieneReport(r.Context())
+	if err != nil {
+		if errors.Is(err, controller.ErrHygieneDisabled) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to scan workload hygiene")
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if r.URL.Query().Get("format") == hygiene.FormatSARIF {
+		w.Header().Set("Content-Type", hygiene.SARIFContentType)
+		w.WriteHeader(http.StatusOK)
+		if err := report.WriteSARIF(w, version.Version); err != nil {
+			log.FromContext(r.Context()).Error(err, "Failed to write SARIF hygiene report")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleUsage returns the remediations, notifications and issues of each team in
+// the month of the month query parameter, e.g. 2026-03, or in the current month
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	month := time.Now()
+	if value := r.URL.Query().Get("month"); value != "" {
+		parsed, err := digest.ParseMonth(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "month must be formatted as YYYY-MM"})
+			return
+		}
+		month = parsed
+	}
+
+	report, err := s.usage.UsageReport(r.Context(), month)
+	if err != nil {
+		if errors.Is(err, controller.ErrDigestDisabled) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to report usage")
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleState exports the operational state as a bundle, or imports a bundle on
+// behalf of the authenticated user
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		bundle, err := s.state.ExportState(r.Context())
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "Failed to export state", "requestedBy", requester.User)
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, bundle)
+		return
+	}
+
+	var bundle controller.StateBundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid state bundle: " + err.Error()})
+		return
+	}
+	result, err := s.state.ImportState(r.Context(), &bundle, requester)
+	if err != nil {
+		if errors.Is(err, controller.ErrBundleVersion) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to import state", "requestedBy", requester.User)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleFeatures lists the feature flags
+func (s *Server) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	if _, ok := s.authenticate(w, r); !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.features.Features())
+}
+
+// handleFeature enables or disables a feature flag on behalf of the authenticated user
+func (s *Server) handleFeature(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+		return
+	}
+
+	requester, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/v1/features/")
+	if name == "" || strings.Contains(name, "/") {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid feature name"})
+		return
+	}
+
+	var req FeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request body: " + err.Error()})
+		return
+	}
+	if req.Enabled == nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "enabled is required"})
+		return
+	}
+
+	status, err := s.features.SetFeature(r.Context(), name, *req.Enabled, requester)
+	if err != nil {
+		if errors.Is(err, features.ErrUnknownFeature) {
+			writeJSON(w, http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.FromContext(r.Context()).Error(err, "Failed to set feature flag", "feature", name, "requestedBy", requester.User)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// SetAuthenticator sets how the callers of the API are identified; until it is
+// set, every request is rejected
+func (s *Server) SetAuthenticator(authenticator Authenticator) {
+	s.authenticator = authenticator
+}
+
+// authenticate identifies the caller of a request. It writes the error response
+// and returns false if the caller is rejected or cannot be checked.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (remediation.Requester, bool) {
+	if s.authenticator == nil {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication is not configured"})
+		return remediation.Requester{}, false
+	}
+
+	requester, err := s.authenticator.Authenticate(r)
+	switch {
+	case err == nil:
+		return requester, true
+	case errors.Is(err, ErrUnauthenticated):
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrForbidden):
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		log.FromContext(r.Context()).Error(err, "Failed to authenticate API request", "path", r.URL.Path)
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "authentication unavailable"})
+	}
+	return remediation.Requester{}, false
+}
+
+// requesterFromHeaders extracts the user identity set by the authenticating proxy
+func requesterFromHeaders(header http.Header) (remediation.Requester, bool) {
+	user := header.Get(HeaderRemoteUser)
+	if user == "" {
+		return remediation.Requester{}, false
+	}
+
+	requester := remediation.Requester{
+		User:   user,
+		Groups: header.Values(HeaderRemoteGroup),
+		Source: "api",
+	}
+
+	for name, values := range header {
+		if !strings.HasPrefix(name, HeaderRemoteExtraPrefix) {
+			continue
+		}
+		// Extra keys are percent-encoded and case-insensitive, as in Kubernetes front-proxy auth
+		key, err := url.PathUnescape(strings.ToLower(strings.TrimPrefix(name, HeaderRemoteExtraPrefix)))
+		if err != nil || key == "" {
+			continue
+		}
+		if requester.Extra == nil {
+			requester.Extra = make(map[string][]string)
+		}
+		requester.Extra[key] = append(requester.Extra[key], values...)
+	}
+
+	return requester, true
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,835 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/controller"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overlay"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// fakeTrigger records the last action request
+type fakeTrigger struct {
+	req controller.ActionRequest
+	err error
+}
+
+func (f *fakeTrigger) TriggerAction(ctx context.Context, req controller.ActionRequest) (*remediation.Result, error) {
+	f.req = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &remediation.Result{Action: req.Action, Success: true, RequestedBy: req.Requester.User}, nil
+}
+
+func (f *fakeTrigger) UndoAction(ctx context.Context, id string, requester remediation.Requester) (*remediation.Result, error) {
+	f.req = controller.ActionRequest{Action: "undo", Name: id, Requester: requester}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &remediation.Result{Action: "undo", Success: true, RequestedBy: requester.User}, nil
+}
+
+func (f *fakeTrigger) UndoRecords() []remediation.UndoRecord {
+	return []remediation.UndoRecord{{ID: "abc", Action: "scale-replicas"}}
+}
+
+// newTestServer creates a server that trusts the X-Remote-* headers of every request
+func newTestServer(trigger ActionTrigger) *Server {
+	server := NewServer(trigger)
+	server.SetAuthenticator(AuthenticatorFunc(func(r *http.Request) (remediation.Requester, error) {
+		requester, ok := requesterFromHeaders(r.Header)
+		if !ok {
+			return requester, ErrUnauthenticated
+		}
+		return requester, nil
+	}))
+	return server
+}
+
+func TestHandleActionsAttributesRequester(t *testing.T) {
+	trigger := &fakeTrigger{}
+	server := newTestServer(trigger)
+
+	body := `{"action":"restart-pod","namespace":"default","kind":"Pod","name":"web-1"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/actions", strings.NewReader(body))
+	req.Header.Set(HeaderRemoteUser, "alice@example.com")
+	req.Header.Add(HeaderRemoteGroup, "sre")
+	req.Header.Add(HeaderRemoteGroup, "oncall")
+	req.Header.Set("X-Remote-Extra-Reason", "incident-42")
+	req.Header.Set("X-Remote-Extra-Acme.com%2Fteam", "platform")
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	requester := trigger.req.Requester
+	if requester.User != "alice@example.com" || requester.Source != "api" {
+		t.Errorf("unexpected requester: %+v", requester)
+	}
+	if len(requester.Groups) != 2 {
+		t.Errorf("groups = %v, want 2 groups", requester.Groups)
+	}
+	if got := requester.Extra["reason"]; len(got) != 1 || got[0] != "incident-42" {
+		t.Errorf("extra[reason] = %v", got)
+	}
+	if got := requester.Extra["acme.com/team"]; len(got) != 1 || got[0] != "platform" {
+		t.Errorf("extra[acme.com/team] = %v", got)
+	}
+
+	var result remediation.Result
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.RequestedBy != "alice@example.com" {
+		t.Errorf("RequestedBy = %q", result.RequestedBy)
+	}
+}
+
+func TestHandleActionsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		user   string
+		body   string
+		err    error
+		want   int
+	}{
+		{"wrong method", http.MethodGet, "alice", "", nil, http.StatusMethodNotAllowed},
+		{"missing user", http.MethodPost, "", `{}`, nil, http.StatusUnauthorized},
+		{"invalid body", http.MethodPost, "alice", `{`, nil, http.StatusBadRequest},
+		{"incomplete request", http.MethodPost, "alice", `{"action":"restart-pod"}`, nil, http.StatusBadRequest},
+		{"observe mode", http.MethodPost, "alice", `{"action":"restart-pod","namespace":"default","kind":"Pod","name":"web-1"}`, controller.ErrObserveMode, http.StatusConflict},
+		{"concurrent change", http.MethodPost, "alice", `{"action":"scale-replicas","namespace":"default","kind":"Deployment","name":"web"}`, remediation.ErrConcurrentChange, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(&fakeTrigger{err: tt.err})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/actions", strings.NewReader(tt.body))
+			if tt.user != "" {
+				req.Header.Set(HeaderRemoteUser, tt.user)
+			}
+
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleUndo(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		err    error
+		want   int
+	}{
+		{"list records", http.MethodGet, "/api/v1/undo", nil, http.StatusOK},
+		{"undo", http.MethodPost, "/api/v1/undo/abc", nil, http.StatusOK},
+		{"missing id", http.MethodPost, "/api/v1/undo/", nil, http.StatusBadRequest},
+		{"unknown id", http.MethodPost, "/api/v1/undo/abc", remediation.ErrUndoNotFound, http.StatusNotFound},
+		{"already undone", http.MethodPost, "/api/v1/undo/abc", remediation.ErrAlreadyUndone, http.StatusConflict},
+		{"changed since the action", http.MethodPost, "/api/v1/undo/abc", remediation.ErrConcurrentChange, http.StatusConflict},
+		{"wrong method", http.MethodGet, "/api/v1/undo/abc", nil, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := &fakeTrigger{err: tt.err}
+			server := newTestServer(trigger)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(HeaderRemoteUser, "alice")
+
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && tt.method == http.MethodPost && trigger.req.Requester.User != "alice" {
+				t.Errorf("undo not attributed to requester: %+v", trigger.req.Requester)
+			}
+		})
+	}
+}
+
+// fakeToggler is a trigger that also serves feature flags
+type fakeToggler struct {
+	fakeTrigger
+	flags *features.Flags
+}
+
+func (f *fakeToggler) Features() []features.Status {
+	return f.flags.List()
+}
+
+func (f *fakeToggler) SetFeature(ctx context.Context, name string, enabled bool, requester remediation.Requester) (features.Status, error) {
+	return f.flags.Set(name, enabled)
+}
+
+func TestHandleFeatures(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"list", http.MethodGet, "/api/v1/features", "", http.StatusOK},
+		{"enable", http.MethodPut, "/api/v1/features/" + features.NodeActions, `{"enabled":true}`, http.StatusOK},
+		{"missing value", http.MethodPut, "/api/v1/features/" + features.NodeActions, `{}`, http.StatusBadRequest},
+		{"unknown feature", http.MethodPut, "/api/v1/features/teleport", `{"enabled":true}`, http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/api/v1/features/" + features.NodeActions, `{"enabled":true}`, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, _ := features.New(nil)
+			server := newTestServer(&fakeToggler{flags: flags})
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(HeaderRemoteUser, "alice")
+
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.name == "enable" && !flags.Enabled(features.NodeActions) {
+				t.Error("feature was not enabled")
+			}
+		})
+	}
+
+	// Triggers without feature flags do not serve the endpoints
+	rec := httptest.NewRecorder()
+	newTestServer(&fakeTrigger{}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/features", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// fakeLister is a trigger that also lists active issues
+type fakeLister struct {
+	fakeTrigger
+}
+
+func (f *fakeLister) ActiveIssues() []tracker.Record {
+	return []tracker.Record{{RuleName: "crashloop-backoff", Namespace: "default", Kind: "Pod", Name: "web-1", Runbook: "https://runbooks.example.com/crashloop-backoff/default/web-1"}}
+}
+
+func TestHandleIssues(t *testing.T) {
+	server := newTestServer(&fakeLister{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/issues", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var records []tracker.Record
+	if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(records) != 1 || records[0].Runbook != "https://runbooks.example.com/crashloop-backoff/default/web-1" {
+		t.Errorf("unexpected issues: %+v", records)
+	}
+
+	// Unauthenticated requests are rejected
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/issues", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+// fakeHygieneReporter is a trigger that also scans workload hygiene
+type fakeHygieneReporter struct {
+	fakeTrigger
+	err error
+}
+
+func (f *fakeHygieneReporter) HygieneReport(ctx context.Context) (*hygiene.Report, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &hygiene.Report{
+		Workloads: 3,
+		Counts:    map[string]int{hygiene.CheckSingleReplica: 1},
+		Findings:  []hygiene.Finding{{Check: hygiene.CheckSingleReplica, Namespace: "prod", Kind: "Deployment", Name: "worker"}},
+	}, nil
+}
+
+func TestHandleHygiene(t *testing.T) {
+	server := newTestServer(&fakeHygieneReporter{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/hygiene", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var report hygiene.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.Workloads != 3 || len(report.Findings) != 1 || report.Findings[0].Name != "worker" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/hygiene?format=sarif", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != hygiene.SARIFContentType || !strings.Contains(rec.Body.String(), `"version": "2.1.0"`) {
+		t.Errorf("SARIF response = %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	server = newTestServer(&fakeHygieneReporter{err: controller.ErrHygieneDisabled})
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d while hygiene reports are disabled", rec.Code, http.StatusNotFound)
+	}
+}
+
+// fakeUsageReporter is a trigger that also reports usage
+type fakeUsageReporter struct {
+	fakeTrigger
+	err error
+}
+
+func (f *fakeUsageReporter) UsageReport(ctx context.Context, month time.Time) (*digest.UsageReport, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &digest.UsageReport{
+		Month: digest.MonthOf(month),
+		Teams: []digest.TeamUsage{{Team: "payments", Namespaces: []string{"prod"}, Usage: digest.Usage{Remediations: 4}, RemediationShare: 100}},
+	}, nil
+}
+
+func TestHandleUsage(t *testing.T) {
+	server := newTestServer(&fakeUsageReporter{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?month=2026-03", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var report digest.UsageReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.Month != "2026-03" || len(report.Teams) != 1 || report.Teams[0].Remediations != 4 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/usage?month=march", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d for an invalid month", rec.Code, http.StatusBadRequest)
+	}
+
+	server = newTestServer(&fakeUsageReporter{err: controller.ErrDigestDisabled})
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/usage", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d while digests are disabled", rec.Code, http.StatusNotFound)
+	}
+}
+
+// fakeStateManager is a trigger that also exports and imports state
+type fakeStateManager struct {
+	fakeTrigger
+	imported *controller.StateBundle
+}
+
+func (f *fakeStateManager) ExportState(ctx context.Context) (*controller.StateBundle, error) {
+	return &controller.StateBundle{Version: controller.StateBundleVersion, Issues: []tracker.Record{{Fingerprint: "abc"}}}, nil
+}
+
+func (f *fakeStateManager) ImportState(ctx context.Context, bundle *controller.StateBundle, requester remediation.Requester) (*controller.ImportResult, error) {
+	if bundle.Version != controller.StateBundleVersion {
+		return nil, controller.ErrBundleVersion
+	}
+	f.imported = bundle
+	return &controller.ImportResult{Issues: len(bundle.Issues)}, nil
+}
+
+func TestHandleState(t *testing.T) {
+	manager := &fakeStateManager{}
+	server := newTestServer(manager)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/state", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d: %s", rec.Code, rec.Body.String())
+	}
+	exported := rec.Body.String()
+
+	// The exported bundle imports as is
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/state", strings.NewReader(exported))
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if manager.imported == nil || len(manager.imported.Issues) != 1 || manager.imported.Issues[0].Fingerprint != "abc" {
+		t.Errorf("imported bundle = %+v", manager.imported)
+	}
+
+	for body, want := range map[string]int{`{"version":99}`: http.StatusBadRequest, `{`: http.StatusBadRequest} {
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/state", strings.NewReader(body))
+		req.Header.Set(HeaderRemoteUser, "alice")
+		rec = httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("import of %s: status = %d, want %d", body, rec.Code, want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/state", nil)
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without user = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+// fakeResyncer is a trigger that also runs resyncs
+type fakeResyncer struct {
+	fakeTrigger
+	resync controller.ResyncRequest
+}
+
+func (f *fakeResyncer) Resync(ctx context.Context, req controller.ResyncRequest) (*controller.ResyncResult, error) {
+	f.resync = req
+	if req.Rule == "teleport" {
+		return nil, controller.ErrUnknownRule
+	}
+	return &controller.ResyncResult{Namespace: req.Namespace, Rule: req.Rule, Issues: 2}, nil
+}
+
+func TestHandleResync(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"everything", "", http.StatusOK},
+		{"scoped", `{"namespace":"payments","rule":"crash-loop-backoff"}`, http.StatusOK},
+		{"unknown rule", `{"rule":"teleport"}`, http.StatusBadRequest},
+		{"invalid body", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resyncer := &fakeResyncer{}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/resync", strings.NewReader(tt.body))
+			req.Header.Set(HeaderRemoteUser, "alice")
+
+			rec := httptest.NewRecorder()
+			newTestServer(resyncer).Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && resyncer.resync.Requester.User != "alice" {
+				t.Errorf("requester = %+v", resyncer.resync.Requester)
+			}
+			if tt.name == "scoped" && resyncer.resync.Namespace != "payments" {
+				t.Errorf("namespace = %q, want payments", resyncer.resync.Namespace)
+			}
+		})
+	}
+}
+
+// fakeTracer is a trigger that also traces rules
+type fakeTracer struct {
+	fakeTrigger
+}
+
+func (f *fakeTracer) TraceRule(ctx context.Context, rule, namespace, name string) (*detection.Trace, error) {
+	switch {
+	case rule == "teleport":
+		return nil, controller.ErrUnknownRule
+	case name == "missing":
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+	}
+	return &detection.Trace{Rule: rule, Namespace: namespace, Name: name, Kind: "Pod"}, nil
+}
+
+func TestHandleRuleTrace(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"trace", "/api/v1/rules/crash-loop-backoff/trace?namespace=default&name=web-1", http.StatusOK},
+		{"unknown rule", "/api/v1/rules/teleport/trace?namespace=default&name=web-1", http.StatusNotFound},
+		{"missing resource", "/api/v1/rules/crash-loop-backoff/trace?namespace=default&name=missing", http.StatusNotFound},
+		{"missing name", "/api/v1/rules/crash-loop-backoff/trace?namespace=default", http.StatusBadRequest},
+		{"unknown path", "/api/v1/rules/crash-loop-backoff", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(HeaderRemoteUser, "alice")
+
+			rec := httptest.NewRecorder()
+			newTestServer(&fakeTracer{}).Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+// fakeRuleLister is a trigger that also lists rules
+type fakeRuleLister struct {
+	fakeTrigger
+}
+
+func (f *fakeRuleLister) Rules() []controller.RuleStatus {
+	return []controller.RuleStatus{{RuleSummary: detection.RuleSummary{Name: "crash-loop-backoff", Enabled: true, RuleMetadata: detection.RuleMetadata{Owner: "sre", Source: detection.RuleSourceBuiltin}}}}
+}
+
+func TestHandleRules(t *testing.T) {
+	server := newTestServer(&fakeRuleLister{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rules", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var rules []map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&rules); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(rules) != 1 || rules[0]["owner"] != "sre" || rules[0]["source"] != "builtin" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+	if _, exists := rules[0]["lastModified"]; exists {
+		t.Errorf("unknown last modification time should be omitted: %+v", rules[0])
+	}
+}
+
+// fakeRuleToggler is a trigger that also enables and disables rules
+type fakeRuleToggler struct {
+	fakeTrigger
+	audit []overlay.AuditRecord
+}
+
+func (f *fakeRuleToggler) SetRuleEnabled(ctx context.Context, name string, enabled bool, reason string, requester remediation.Requester) (controller.RuleStatus, error) {
+	switch name {
+	case "teleport":
+		return controller.RuleStatus{}, controller.ErrUnknownRule
+	case "ephemeral-container-age":
+		return controller.RuleStatus{}, controller.ErrRuleUnavailable
+	}
+	f.audit = append([]overlay.AuditRecord{{Rule: name, Enabled: enabled, Reason: reason, User: requester.User}}, f.audit...)
+	return controller.RuleStatus{RuleSummary: detection.RuleSummary{Name: name, Enabled: enabled}}, nil
+}
+
+func (f *fakeRuleToggler) RuleAudit(ctx context.Context) ([]overlay.AuditRecord, error) {
+	return f.audit, nil
+}
+
+func TestHandleRuleToggle(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		user   string
+		want   int
+	}{
+		{"disable", http.MethodPut, "/api/v1/rules/high-cpu-usage", `{"enabled":false,"reason":"noisy"}`, "alice", http.StatusOK},
+		{"missing user", http.MethodPut, "/api/v1/rules/high-cpu-usage", `{"enabled":false}`, "", http.StatusUnauthorized},
+		{"missing enabled", http.MethodPut, "/api/v1/rules/high-cpu-usage", `{}`, "alice", http.StatusBadRequest},
+		{"unknown rule", http.MethodPut, "/api/v1/rules/teleport", `{"enabled":false}`, "alice", http.StatusNotFound},
+		{"unavailable rule", http.MethodPut, "/api/v1/rules/ephemeral-container-age", `{"enabled":true}`, "alice", http.StatusConflict},
+		{"wrong method", http.MethodPost, "/api/v1/rules/high-cpu-usage", `{"enabled":false}`, "alice", http.StatusMethodNotAllowed},
+		{"nested path", http.MethodPut, "/api/v1/rules/high-cpu-usage/other", `{"enabled":false}`, "alice", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toggler := &fakeRuleToggler{}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.user != "" {
+				req.Header.Set(HeaderRemoteUser, tt.user)
+			}
+
+			rec := httptest.NewRecorder()
+			newTestServer(toggler).Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && (len(toggler.audit) != 1 || toggler.audit[0].User != "alice" || toggler.audit[0].Reason != "noisy") {
+				t.Errorf("audit = %+v", toggler.audit)
+			}
+		})
+	}
+}
+
+func TestHandleRuleAudit(t *testing.T) {
+	toggler := &fakeRuleToggler{audit: []overlay.AuditRecord{{Rule: "high-cpu-usage", User: "alice"}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rules/audit", nil)
+	req.Header.Set(HeaderRemoteUser, "alice")
+	rec := httptest.NewRecorder()
+	newTestServer(toggler).Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var records []overlay.AuditRecord
+	if err := json.NewDecoder(rec.Body).Decode(&records); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(records) != 1 || records[0].Rule != "high-cpu-usage" {
+		t.Errorf("unexpected audit records: %+v", records)
+	}
+}
+
+func TestLimitInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := limitInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}), 1)
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/issues", nil))
+		done <- rec.Code
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/issues", nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("second request = %d, want 429 with Retry-After", rec.Code)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("first request = %d, want 200", code)
+	}
+}
+
+// fakeIncidentManager is a trigger that also manages incidents
+type fakeIncidentManager struct {
+	fakeTrigger
+	incidents []controller.Incident
+}
+
+func (f *fakeIncidentManager) StartIncident(ctx context.Context, req controller.IncidentRequest, requester remediation.Requester) (controller.Incident, error) {
+	if req.Namespace == "" && len(req.Rules) == 0 {
+		return controller.Incident{}, controller.ErrInvalidIncident
+	}
+	incident := controller.Incident{ID: "a1b2", Namespace: req.Namespace, Rules: req.Rules, StartedBy: requester.User}
+	f.incidents = append(f.incidents, incident)
+	return incident, nil
+}
+
+func (f *fakeIncidentManager) EndIncident(ctx context.Context, id string, requester remediation.Requester) (controller.Incident, error) {
+	for i, incident := range f.incidents {
+		if incident.ID == id {
+			f.incidents = append(f.incidents[:i], f.incidents[i+1:]...)
+			return incident, nil
+		}
+	}
+	return controller.Incident{}, controller.ErrIncidentNotFound
+}
+
+func (f *fakeIncidentManager) Incidents() []controller.Incident {
+	return f.incidents
+}
+
+func TestHandleIncidents(t *testing.T) {
+	manager := &fakeIncidentManager{}
+	handler := newTestServer(manager).Handler()
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(HeaderRemoteUser, "alice")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/api/v1/incidents", `{"duration": "30m"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d for an incident without a scope", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := serve(http.MethodPost, "/api/v1/incidents", `{"namespace": "payments", "duration": "30m", "cooldownSeconds": 0}`)
+	var incident controller.Incident
+	if err := json.NewDecoder(rec.Body).Decode(&incident); err != nil || rec.Code != http.StatusOK || incident.StartedBy != "alice" {
+		t.Fatalf("POST = %d %+v, %v, want an incident started by alice", rec.Code, incident, err)
+	}
+
+	rec = serve(http.MethodGet, "/api/v1/incidents", "")
+	var incidents []controller.Incident
+	if err := json.NewDecoder(rec.Body).Decode(&incidents); err != nil || len(incidents) != 1 {
+		t.Errorf("GET = %d %+v, %v, want 1 incident", rec.Code, incidents, err)
+	}
+
+	if rec := serve(http.MethodDelete, "/api/v1/incidents/"+incident.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serve(http.MethodDelete, "/api/v1/incidents/"+incident.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE status = %d, want %d for an ended incident", rec.Code, http.StatusNotFound)
+	}
+}
+
+// fakeDriftReporter is a trigger that also reports config drift
+type fakeDriftReporter struct {
+	fakeTrigger
+	err error
+}
+
+func (f *fakeDriftReporter) ConfigDrift(ctx context.Context) (controller.ConfigDriftReport, error) {
+	self := controller.ConfigInstance{Instance: "kubeguardian-0", Hash: "abc"}
+	return controller.ConfigDriftReport{
+		Instance:  self,
+		Instances: []controller.ConfigInstance{self, {Instance: "kubeguardian-1", Hash: "def"}},
+		Drifted:   true,
+	}, f.err
+}
+
+func TestHandleConfigDrift(t *testing.T) {
+	server := newTestServer(&fakeDriftReporter{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/config/drift", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d without a user", rec.Code, http.StatusUnauthorized)
+	}
+
+	req.Header.Set(HeaderRemoteUser, "kubeguardian")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	var report controller.ConfigDriftReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, err = %v", rec.Code, err)
+	}
+	if report.Instance.Hash != "abc" || len(report.Instances) != 2 || !report.Drifted {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	server = newTestServer(&fakeDriftReporter{err: controller.ErrConfigDriftDisabled})
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d while drift detection is disabled", rec.Code, http.StatusNotFound)
+	}
+}
+
+// fakeDecisionReader is a trigger that also reads the decision log
+type fakeDecisionReader struct {
+	fakeTrigger
+	query detection.DecisionQuery
+	err   error
+}
+
+func (f *fakeDecisionReader) Decisions(query detection.DecisionQuery) (detection.DecisionReport, error) {
+	f.query = query
+	return detection.DecisionReport{
+		SampleRate: 0.5,
+		Counts:     []detection.DecisionCount{{Rule: "crash-loop-backoff", Outcome: detection.DecisionSkipped, Condition: "restart count", Count: 3}},
+		Decisions:  []detection.Decision{{Rule: "crash-loop-backoff", Namespace: "shop", Kind: "Pod", Name: "web-1", Outcome: detection.DecisionSkipped}},
+	}, f.err
+}
+
+func TestHandleDecisions(t *testing.T) {
+	reader := &fakeDecisionReader{}
+	server := newTestServer(reader)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(HeaderRemoteUser, "alice")
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/api/v1/decisions?rule=crash-loop-backoff&outcome=skipped&limit=10")
+	var report detection.DecisionReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, err = %v", rec.Code, err)
+	}
+	if len(report.Counts) != 1 || len(report.Decisions) != 1 || report.SampleRate != 0.5 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	want := detection.DecisionQuery{Rule: "crash-loop-backoff", Outcome: detection.DecisionSkipped, Limit: 10}
+	if reader.query != want {
+		t.Errorf("query = %+v, want %+v", reader.query, want)
+	}
+
+	get("/api/v1/decisions")
+	if reader.query.Limit != defaultDecisionLimit {
+		t.Errorf("limit = %d without a limit, want %d", reader.query.Limit, defaultDecisionLimit)
+	}
+
+	for _, target := range []string{"/api/v1/decisions?outcome=maybe", "/api/v1/decisions?limit=-1"} {
+		if rec := get(target); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	reader.err = detection.ErrDecisionLogDisabled
+	if rec := get("/api/v1/decisions"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d while the decision log is disabled", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,175 @@
+package capabilities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
+)
+
+// Capabilities of the cluster that remediation actions depend on
+const (
+	// EphemeralContainers means pods/ephemeralcontainers is served (Kubernetes 1.23+)
+	EphemeralContainers = "ephemeral-containers"
+	// SidecarContainers means init containers with restartPolicy Always run as
+	// sidecars (enabled by default in Kubernetes 1.29+)
+	SidecarContainers = "sidecar-containers"
+	// Eviction means pods can be evicted through policy/v1 (Kubernetes 1.22+)
+	Eviction = "eviction"
+	// AutoscalingV2 means autoscaling/v2 HorizontalPodAutoscalers are served (Kubernetes 1.23+)
+	AutoscalingV2 = "autoscaling-v2"
+	// MetricsServer means the resource metrics API (metrics.k8s.io) is served
+	MetricsServer = "metrics-server"
+
+	// ArgoRollouts, CertManager and Kyverno mean the CRDs of these optional
+	// integrations are installed
+	ArgoRollouts = "argo-rollouts"
+	CertManager  = "cert-manager"
+	Kyverno      = "kyverno"
+)
+
+// integrations maps the capabilities of optional integrations to a resource served by their CRDs
+var integrations = map[string]struct{ groupVersion, resource string }{
+	ArgoRollouts: {"argoproj.io/v1alpha1", "rollouts"},
+	CertManager:  {"cert-manager.io/v1", "certificates"},
+	Kyverno:      {"kyverno.io/v1", "clusterpolicies"},
+}
+
+// sidecarVersion is the first version with sidecar containers enabled by default
+var sidecarVersion = utilversion.MajorMinor(1, 29)
+
+// ActionRequirements maps remediation actions to the capabilities they need.
+// Rules declare theirs in detection.Rule.Requires.
+var ActionRequirements = map[string][]string{
+	"restart-container": {EphemeralContainers},
+}
+
+// Report represents the capabilities of a cluster
+type Report struct {
+	// Version is the version reported by the API server, e.g. v1.29.4-eks-1552ad0
+	Version      string          `json:"version"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// Has returns true if the cluster has a capability
+func (r *Report) Has(capability string) bool {
+	return r.Capabilities[capability]
+}
+
+// MissingActions returns the given actions the cluster cannot execute, mapped to
+// their missing capabilities
+func (r *Report) MissingActions(actions []string) map[string][]string {
+	missing := make(map[string][]string)
+	for _, action := range actions {
+		for _, capability := range ActionRequirements[action] {
+			if !r.Has(capability) {
+				missing[action] = append(missing[action], capability)
+			}
+		}
+	}
+	return missing
+}
+
+// Summary returns a human-readable report of the capabilities
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Kubernetes %s\n", r.Version)
+
+	names := make([]string, 0, len(r.Capabilities))
+	for name := range r.Capabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		status := "[FAIL]"
+		if r.Capabilities[name] {
+			status = "[PASS]"
+		}
+		fmt.Fprintf(&b, "%s %s\n", status, name)
+	}
+	return b.String()
+}
+
+// Prober detects the capabilities of a cluster from its version and discovery information
+type Prober struct {
+	client discovery.DiscoveryInterface
+}
+
+// NewProber creates a new capability prober
+func NewProber(client discovery.DiscoveryInterface) *Prober {
+	return &Prober{
+		client: client,
+	}
+}
+
+// Probe inspects the server version and the served APIs
+func (p *Prober) Probe() (*Report, error) {
+	info, err := p.client.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	report := &Report{
+		Version:      info.GitVersion,
+		Capabilities: make(map[string]bool),
+	}
+
+	// Managed clusters report versions like v1.29.4-eks-1552ad0, which parse as 1.29.4
+	version, err := utilversion.ParseGeneric(info.GitVersion)
+	report.Capabilities[SidecarContainers] = err == nil && version.AtLeast(sidecarVersion)
+
+	core, err := p.served("v1")
+	if err != nil {
+		return nil, err
+	}
+	report.Capabilities[EphemeralContainers] = core["pods/ephemeralcontainers"]
+
+	policy, err := p.served("policy/v1")
+	if err != nil {
+		return nil, err
+	}
+	report.Capabilities[Eviction] = core["pods/eviction"] && policy["poddisruptionbudgets"]
+
+	autoscaling, err := p.served("autoscaling/v2")
+	if err != nil {
+		return nil, err
+	}
+	report.Capabilities[AutoscalingV2] = autoscaling["horizontalpodautoscalers"]
+
+	resourceMetrics, err := p.served("metrics.k8s.io/v1beta1")
+	if err != nil {
+		return nil, err
+	}
+	report.Capabilities[MetricsServer] = resourceMetrics["pods"]
+
+	for capability, integration := range integrations {
+		resources, err := p.served(integration.groupVersion)
+		if err != nil {
+			return nil, err
+		}
+		report.Capabilities[capability] = resources[integration.resource]
+	}
+
+	return report, nil
+}
+
+// served returns the resources served for a group version; a group version that
+// is not served, or whose aggregated API server is unavailable, has no resources
+func (p *Prober) served(groupVersion string) (map[string]bool, error) {
+	list, err := p.client.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s resources: %w", groupVersion, err)
+	}
+
+	resources := make(map[string]bool, len(list.APIResources))
+	for _, resource := range list.APIResources {
+		resources[resource.Name] = true
+	}
+	return resources, nil
+}
@@ -0,0 +1,107 @@
+package capabilities
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newFakeDiscovery returns a fake discovery client for a server version serving the given resources
+func newFakeDiscovery(gitVersion string, resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+	discovery := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: gitVersion}
+	discovery.Resources = resources
+	return discovery
+}
+
+func resourceList(groupVersion string, names ...string) *metav1.APIResourceList {
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, name := range names {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: name})
+	}
+	return list
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery *fakediscovery.FakeDiscovery
+		want      map[string]bool
+	}{
+		{
+			name: "current cluster",
+			discovery: newFakeDiscovery("v1.30.2-eks-1552ad0",
+				resourceList("v1", "pods", "pods/eviction", "pods/ephemeralcontainers"),
+				resourceList("policy/v1", "poddisruptionbudgets"),
+				resourceList("autoscaling/v2", "horizontalpodautoscalers"),
+			),
+			want: map[string]bool{EphemeralContainers: true, SidecarContainers: true, Eviction: true, AutoscalingV2: true},
+		},
+		{
+			name: "old cluster",
+			discovery: newFakeDiscovery("v1.21.14",
+				resourceList("v1", "pods", "pods/eviction"),
+				resourceList("policy/v1beta1", "poddisruptionbudgets"),
+			),
+			want: map[string]bool{EphemeralContainers: false, SidecarContainers: false, Eviction: false, AutoscalingV2: false},
+		},
+		{
+			name: "unparseable version",
+			discovery: newFakeDiscovery("unknown",
+				resourceList("v1", "pods", "pods/ephemeralcontainers"),
+			),
+			want: map[string]bool{EphemeralContainers: true, SidecarContainers: false, Eviction: false, AutoscalingV2: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewProber(tt.discovery).Probe()
+			if err != nil {
+				t.Fatalf("Probe() error = %v", err)
+			}
+			for capability, want := range tt.want {
+				if got := report.Has(capability); got != want {
+					t.Errorf("Has(%s) = %v, want %v", capability, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMissingActions(t *testing.T) {
+	report := &Report{Capabilities: map[string]bool{EphemeralContainers: false}}
+
+	missing := report.MissingActions([]string{"restart-pod", "restart-container"})
+	if len(missing) != 1 || len(missing["restart-container"]) != 1 {
+		t.Errorf("MissingActions() = %v, want only restart-container", missing)
+	}
+
+	report.Capabilities[EphemeralContainers] = true
+	if missing := report.MissingActions([]string{"restart-container"}); len(missing) != 0 {
+		t.Errorf("MissingActions() = %v, want none", missing)
+	}
+}
+
+func TestProbeIntegrations(t *testing.T) {
+	discovery := newFakeDiscovery("v1.30.0",
+		resourceList("v1", "pods"),
+		resourceList("cert-manager.io/v1", "certificates", "issuers"),
+	)
+
+	report, err := NewProber(discovery).Probe()
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if !report.Has(CertManager) {
+		t.Errorf("Has(%s) = false, want true", CertManager)
+	}
+	for _, capability := range []string{ArgoRollouts, Kyverno} {
+		if report.Has(capability) {
+			t.Errorf("Has(%s) = true, want false without its CRDs", capability)
+		}
+	}
+}
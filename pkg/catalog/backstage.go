@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Backstage component annotations read in addition to the owner
+const (
+	AnnotationSlackChannel     = "kubeguardian.io/slack-channel"
+	AnnotationEscalationPolicy = "kubeguardian.io/escalation-policy"
+)
+
+// backstageEntity is the subset of a Backstage catalog entity that is used
+type backstageEntity struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Owner string `json:"owner"`
+	} `json:"spec"`
+}
+
+// BackstageResolver resolves workloads to Backstage components. The component
+// owner is used as the team; the channel and escalation policy are read from
+// the component annotations.
+type BackstageResolver struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewBackstageResolver creates a resolver querying the Backstage catalog API at baseURL
+func NewBackstageResolver(baseURL, token string, client *http.Client) *BackstageResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &BackstageResolver{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+// Resolve looks up the component of the workload in the default Backstage namespace
+func (r *BackstageResolver) Resolve(ctx context.Context, workload Workload) (Entry, bool, error) {
+	endpoint := fmt.Sprintf("%s/api/catalog/entities/by-name/component/default/%s", r.baseURL, url.PathEscape(workload.Component()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to create catalog request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to query catalog: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Entry{}, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return Entry{}, false, fmt.Errorf("catalog returned status %d", resp.StatusCode)
+	}
+
+	var entity backstageEntity
+	if err := json.NewDecoder(resp.Body).Decode(&entity); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to decode catalog entity: %w", err)
+	}
+
+	return Entry{
+		Team:             ownerName(entity.Spec.Owner),
+		Channel:          entity.Metadata.Annotations[AnnotationSlackChannel],
+		EscalationPolicy: entity.Metadata.Annotations[AnnotationEscalationPolicy],
+	}, true, nil
+}
+
+// ownerName strips the kind and namespace from an entity reference such as group:default/payments
+func ownerName(ref string) string {
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	if i := strings.Index(ref, ":"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	return ref
+}
@@ -0,0 +1,100 @@
+// Package catalog resolves workloads to their owners using an external service
+// catalog, such as Backstage or a ConfigMap maintained by platform teams.
+package catalog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Catalog backends
+const (
+	BackendConfigMap = "configmap"
+	BackendBackstage = "backstage"
+)
+
+// LabelKubernetesID is the Backstage label linking a workload to its component
+const LabelKubernetesID = "backstage.io/kubernetes-id"
+
+// Workload identifies a resource to look up
+type Workload struct {
+	Namespace string
+	Kind      string
+	Name      string
+	Labels    map[string]string
+}
+
+// Component returns the catalog name of the workload: the Backstage
+// kubernetes-id label, the application name label or the resource name
+func (w Workload) Component() string {
+	for _, key := range []string{LabelKubernetesID, "app.kubernetes.io/name", "app"} {
+		if value := w.Labels[key]; value != "" {
+			return value
+		}
+	}
+	return w.Name
+}
+
+// Entry is the ownership information of a workload
+type Entry struct {
+	Team             string `yaml:"team" json:"team,omitempty"`
+	Channel          string `yaml:"channel" json:"channel,omitempty"`
+	EscalationPolicy string `yaml:"escalationPolicy" json:"escalationPolicy,omitempty"`
+}
+
+// Resolver looks up the owner of a workload. It returns false if the catalog
+// has no entry for the workload.
+type Resolver interface {
+	Resolve(ctx context.Context, workload Workload) (Entry, bool, error)
+}
+
+// Cache caches the results of a resolver, including workloads without an entry,
+// so the catalog is queried at most once per workload and TTL
+type Cache struct {
+	resolver Resolver
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedEntry // Key: namespace/kind/component
+}
+
+type cachedEntry struct {
+	entry   Entry
+	found   bool
+	expires time.Time
+}
+
+// NewCache creates a cache in front of a resolver
+func NewCache(resolver Resolver, ttl time.Duration) *Cache {
+	return &Cache{
+		resolver: resolver,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]cachedEntry),
+	}
+}
+
+// Resolve returns the cached entry of a workload, querying the resolver if it
+// is missing or expired. Errors are not cached.
+func (c *Cache) Resolve(ctx context.Context, workload Workload) (Entry, bool, error) {
+	key := workload.Namespace + "/" + workload.Kind + "/" + workload.Component()
+
+	c.mu.Lock()
+	cached, exists := c.entries[key]
+	c.mu.Unlock()
+	if exists && c.now().Before(cached.expires) {
+		return cached.entry, cached.found, nil
+	}
+
+	entry, found, err := c.resolver.Resolve(ctx, workload)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = cachedEntry{entry: entry, found: found, expires: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+	return entry, found, nil
+}
@@ -0,0 +1,168 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type countingResolver struct {
+	calls int
+	err   error
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, workload Workload) (Entry, bool, error) {
+	r.calls++
+	if r.err != nil {
+		return Entry{}, false, r.err
+	}
+	return Entry{Team: workload.Component()}, workload.Namespace != "unknown", nil
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	resolver := &countingResolver{}
+	cache := NewCache(resolver, time.Minute)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	pod := Workload{Namespace: "payments", Kind: "Pod", Name: "api-7d9f", Labels: map[string]string{"app.kubernetes.io/name": "api"}}
+	for i := 0; i < 3; i++ {
+		entry, found, err := cache.Resolve(ctx, pod)
+		if err != nil || !found || entry.Team != "api" {
+			t.Fatalf("Resolve() = %+v, %v, %v", entry, found, err)
+		}
+	}
+	if resolver.calls != 1 {
+		t.Errorf("resolver calls = %d, want 1", resolver.calls)
+	}
+
+	// Workloads without an entry are cached as well
+	unknown := Workload{Namespace: "unknown", Kind: "Pod", Name: "web"}
+	cache.Resolve(ctx, unknown)
+	if _, found, _ := cache.Resolve(ctx, unknown); found || resolver.calls != 2 {
+		t.Errorf("missing entry should be cached, found = %v, calls = %d", found, resolver.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	cache.Resolve(ctx, pod)
+	if resolver.calls != 3 {
+		t.Errorf("expired entry should be resolved again, calls = %d", resolver.calls)
+	}
+
+	// Errors are not cached
+	resolver.err = errors.New("catalog unavailable")
+	now = now.Add(2 * time.Minute)
+	if _, _, err := cache.Resolve(ctx, pod); err == nil {
+		t.Error("expected resolver error")
+	}
+	resolver.err = nil
+	if _, found, err := cache.Resolve(ctx, pod); err != nil || !found {
+		t.Errorf("Resolve() after error = %v, %v", found, err)
+	}
+}
+
+func TestConfigMapResolver(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeguardian-catalog", Namespace: "kubeguardian"},
+		Data: map[string]string{configMapKey: `
+workloads:
+  payments/api:
+    team: payments
+    channel: "#payments-alerts"
+    escalationPolicy: payments-oncall
+namespaces:
+  payments:
+    team: payments-platform
+`},
+	})
+	resolver := NewConfigMapResolver(client, "kubeguardian", "kubeguardian-catalog")
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		workload Workload
+		want     Entry
+		found    bool
+	}{
+		{
+			name:     "workload entry",
+			workload: Workload{Namespace: "payments", Kind: "Pod", Name: "api-7d9f", Labels: map[string]string{LabelKubernetesID: "api"}},
+			want:     Entry{Team: "payments", Channel: "#payments-alerts", EscalationPolicy: "payments-oncall"},
+			found:    true,
+		},
+		{
+			name:     "namespace entry",
+			workload: Workload{Namespace: "payments", Kind: "Deployment", Name: "worker"},
+			want:     Entry{Team: "payments-platform"},
+			found:    true,
+		},
+		{
+			name:     "no entry",
+			workload: Workload{Namespace: "search", Kind: "Deployment", Name: "api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, found, err := resolver.Resolve(ctx, tt.workload)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if entry != tt.want || found != tt.found {
+				t.Errorf("Resolve() = %+v, %v, want %+v, %v", entry, found, tt.want, tt.found)
+			}
+		})
+	}
+
+	// A missing ConfigMap is an empty catalog
+	missing := NewConfigMapResolver(fake.NewSimpleClientset(), "kubeguardian", "kubeguardian-catalog")
+	if _, found, err := missing.Resolve(ctx, tests[0].workload); err != nil || found {
+		t.Errorf("Resolve() with missing configmap = %v, %v", found, err)
+	}
+}
+
+func TestBackstageResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/catalog/entities/by-name/component/default/checkout":
+			w.Write([]byte(`{
+				"metadata": {"annotations": {"kubeguardian.io/slack-channel": "#checkout", "kubeguardian.io/escalation-policy": "checkout-oncall"}},
+				"spec": {"owner": "group:default/payments"}
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolver := NewBackstageResolver(server.URL+"/", "secret", server.Client())
+	ctx := context.Background()
+
+	entry, found, err := resolver.Resolve(ctx, Workload{Namespace: "shop", Kind: "Pod", Name: "checkout-5c8b", Labels: map[string]string{LabelKubernetesID: "checkout"}})
+	if err != nil || !found {
+		t.Fatalf("Resolve() = %v, %v", found, err)
+	}
+	if want := (Entry{Team: "payments", Channel: "#checkout", EscalationPolicy: "checkout-oncall"}); entry != want {
+		t.Errorf("Resolve() = %+v, want %+v", entry, want)
+	}
+
+	if _, found, err := resolver.Resolve(ctx, Workload{Namespace: "shop", Kind: "Deployment", Name: "cart"}); err != nil || found {
+		t.Errorf("Resolve() for unknown component = %v, %v", found, err)
+	}
+
+	unauthorized := NewBackstageResolver(server.URL, "", server.Client())
+	if _, _, err := unauthorized.Resolve(ctx, Workload{Name: "checkout"}); err == nil {
+		t.Error("expected error for unauthorized request")
+	}
+}
@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// configMapKey is the ConfigMap key holding the catalog
+const configMapKey = "catalog.yaml"
+
+// configMapCatalog is the content of the catalog ConfigMap:
+//
+//	workloads:
+//	  payments/api: {team: payments, channel: "#payments-alerts", escalationPolicy: payments-oncall}
+//	namespaces:
+//	  search: {team: search, channel: "#search-oncall"}
+//
+// Workloads are keyed by namespace and component name and take precedence over namespaces.
+type configMapCatalog struct {
+	Workloads  map[string]Entry `yaml:"workloads"`
+	Namespaces map[string]Entry `yaml:"namespaces"`
+}
+
+// ConfigMapResolver resolves workloads from a ConfigMap
+type ConfigMapResolver struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapResolver creates a resolver reading the catalog from a ConfigMap
+func NewConfigMapResolver(client kubernetes.Interface, namespace, name string) *ConfigMapResolver {
+	return &ConfigMapResolver{client: client, namespace: namespace, name: name}
+}
+
+// Resolve looks up the workload, then its namespace. A missing ConfigMap is an empty catalog.
+func (r *ConfigMapResolver) Resolve(ctx context.Context, workload Workload) (Entry, bool, error) {
+	cm, err := r.client.CoreV1().ConfigMaps(r.namespace).Get(ctx, r.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to get catalog configmap: %w", err)
+	}
+
+	var catalog configMapCatalog
+	if err := yaml.Unmarshal([]byte(cm.Data[configMapKey]), &catalog); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to parse catalog configmap: %w", err)
+	}
+
+	if entry, exists := catalog.Workloads[workload.Namespace+"/"+workload.Component()]; exists {
+		return entry, true, nil
+	}
+	entry, exists := catalog.Namespaces[workload.Namespace]
+	return entry, exists, nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"k8s.io/utils/clock"
 )
 
 // State represents the circuit breaker state
@@ -16,6 +18,20 @@ const (
 	StateHalfOpen
 )
 
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
 // Circuit breaker errors
 var (
 	ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
@@ -31,6 +47,8 @@ type Config struct {
 	OnStateChange func(name string, from State, to State)
 	IsSuccessful  func(err error) bool
 	Fallback      func(ctx context.Context, name string, err error) error
+	// Clock times the interval and timeout; nil is the real clock
+	Clock clock.PassiveClock
 }
 
 // Counts holds circuit breaker counts
@@ -52,6 +70,7 @@ type CircuitBreaker struct {
 	onStateChange func(name string, from State, to State)
 	isSuccessful  func(err error) bool
 	fallback      func(ctx context.Context, name string, err error) error
+	clock         clock.PassiveClock
 
 	mutex      sync.Mutex
 	state      State
@@ -71,6 +90,7 @@ func NewCircuitBreaker(name string, cfg Config) *CircuitBreaker {
 		onStateChange: cfg.OnStateChange,
 		isSuccessful:  cfg.IsSuccessful,
 		fallback:      cfg.Fallback,
+		clock:         cfg.Clock,
 	}
 
 	if cfg.MaxRequests == 0 {
@@ -93,7 +113,11 @@ func NewCircuitBreaker(name string, cfg Config) *CircuitBreaker {
 		cb.isSuccessful = DefaultIsSuccessful
 	}
 
-	cb.toNewGeneration(time.Now())
+	if cfg.Clock == nil {
+		cb.clock = clock.RealClock{}
+	}
+
+	cb.toNewGeneration(cb.clock.Now())
 
 	return cb
 }
@@ -136,7 +160,7 @@ func (cb *CircuitBreaker) beforeRequest() (uint64, error) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
 	state, generation := cb.currentState(now)
 
 	if state == StateOpen {
@@ -154,7 +178,7 @@ func (cb *CircuitBreaker) afterRequest(before uint64, success bool) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
 	state, generation := cb.currentState(now)
 	if generation != before {
 		return
@@ -240,12 +264,17 @@ func (cb *CircuitBreaker) toNewGeneration(now time.Time) {
 	}
 }
 
+// Name returns the name of the circuit breaker
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
 // State returns the current circuit breaker state
 func (cb *CircuitBreaker) State() State {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
-	now := time.Now()
+	now := cb.clock.Now()
 	state, _ := cb.currentState(now)
 	return state
 }
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestCircuitBreakerStates(t *testing.T) {
@@ -74,6 +76,7 @@ func TestCircuitBreakerTripping(t *testing.T) {
 }
 
 func TestCircuitBreakerHalfOpen(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
 	cb := NewCircuitBreaker("test", Config{
 		MaxRequests: 1,
 		Interval:    50 * time.Millisecond,
@@ -81,6 +84,7 @@ func TestCircuitBreakerHalfOpen(t *testing.T) {
 		ReadyToTrip: func(counts Counts) bool {
 			return counts.ConsecutiveFailures >= 2
 		},
+		Clock: clock,
 	})
 
 	// Trip the circuit
@@ -90,8 +94,14 @@ func TestCircuitBreakerHalfOpen(t *testing.T) {
 		})
 	}
 
+	// Still open until the timeout expires
+	clock.SetTime(clock.Now().Add(40 * time.Millisecond))
+	if cb.State() != StateOpen {
+		t.Errorf("state before timeout = %v, want %v", cb.State(), StateOpen)
+	}
+
 	// Wait for timeout to enter half-open state
-	time.Sleep(60 * time.Millisecond)
+	clock.SetTime(clock.Now().Add(20 * time.Millisecond))
 
 	// Should be in half-open state now
 	if cb.State() != StateHalfOpen {
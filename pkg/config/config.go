@@ -2,11 +2,27 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
+	"github.com/NotHarshhaa/kubeguardian/pkg/permissions"
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+	"github.com/NotHarshhaa/kubeguardian/pkg/secrets"
 )
 
 // ValidationResult represents a configuration validation result
@@ -24,6 +40,9 @@ func (c *Config) Validate() *ValidationResult {
 		Warnings: []string{},
 	}
 
+	// Validate operating mode
+	c.validateMode(result)
+
 	// Validate controller config
 	c.validateController(result)
 
@@ -36,13 +55,298 @@ func (c *Config) Validate() *ValidationResult {
 	// Validate notification config
 	c.validateNotification(result)
 
+	// Validate API config
+	c.validateAPI(result)
+
 	// Validate namespace configs
 	c.validateNamespaces(result)
+	c.validateLegacyNamespaces(result)
+	c.validateNamespaceSelectors(result)
+
+	// Validate schedules
+	c.validateSchedules(result)
+
+	// Validate workload override bounds
+	c.validateWorkloadOverrides(result)
+
+	// Validate feature flags
+	c.validateFeatures(result)
+	c.validateAnalysis(result)
+	c.validateExport(result)
+	c.validateMetrics(result)
+	c.validateRuntime(result)
+	c.validateSecretProviders(result)
 
 	result.Valid = len(result.Errors) == 0
 	return result
 }
 
+func (c *Config) validateMode(result *ValidationResult) {
+	switch c.Mode {
+	case "", ModeEnforce, ModeObserve:
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid mode '%s' (must be %s or %s)", c.Mode, ModeEnforce, ModeObserve))
+	}
+}
+
+func (c *Config) validateSchedules(result *ValidationResult) {
+	for name, sc := range c.Schedules {
+		if _, err := schedule.NewWindow(sc.Cron, sc.Timezone, sc.Duration); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("schedule '%s': %v", name, err))
+		}
+	}
+}
+
+func (c *Config) validateWorkloadOverrides(result *ValidationResult) {
+	o := c.WorkloadOverrides
+	if !o.Enabled {
+		return
+	}
+
+	intRanges := []struct {
+		name string
+		r    IntRange
+	}{{"restartLimit", o.RestartLimit}, {"oomKillThreshold", o.OOMKillThreshold}}
+	for _, ir := range intRanges {
+		name, r := ir.name, ir.r
+		if r.Min < 1 {
+			result.Errors = append(result.Errors, fmt.Sprintf("workload override %s min must be at least 1", name))
+		}
+		if r.Max > 0 && r.Max < r.Min {
+			result.Errors = append(result.Errors, fmt.Sprintf("workload override %s max cannot be less than min", name))
+		}
+	}
+
+	durationRanges := []struct {
+		name string
+		r    DurationRange
+	}{{"checkDuration", o.CheckDuration}, {"cooldown", o.Cooldown}}
+	for _, dr := range durationRanges {
+		name, r := dr.name, dr.r
+		if r.Min < 0 || r.Max < 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("workload override %s bounds cannot be negative", name))
+		}
+		if r.Max > 0 && r.Max < r.Min {
+			result.Errors = append(result.Errors, fmt.Sprintf("workload override %s max cannot be less than min", name))
+		}
+	}
+
+	// Cooldown entries are cleaned up after an hour
+	if o.Cooldown.Max <= 0 || o.Cooldown.Max > time.Hour {
+		result.Errors = append(result.Errors, "workload override cooldown max must be between 1s and 1h")
+	}
+}
+
+func (c *Config) validateFeatures(result *ValidationResult) {
+	names := make([]string, 0, len(c.Features))
+	for name := range c.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !features.Known(name) {
+			result.Errors = append(result.Errors, fmt.Sprintf("unknown feature flag '%s'", name))
+		}
+	}
+}
+
+func (c *Config) validateAnalysis(result *ValidationResult) {
+	settings := c.Analysis
+	if settings.MaxEvents < 0 || settings.LogLines < 0 {
+		result.Errors = append(result.Errors, "analysis maxEvents and logLines cannot be negative")
+	}
+
+	known := make(map[string]bool)
+	for _, signature := range analysis.Signatures() {
+		known[signature] = true
+	}
+	signatures := make([]string, 0, len(settings.Heuristics.Runbooks))
+	for signature := range settings.Heuristics.Runbooks {
+		signatures = append(signatures, signature)
+	}
+	sort.Strings(signatures)
+	for _, signature := range signatures {
+		if !known[signature] {
+			result.Errors = append(result.Errors, fmt.Sprintf("unknown failure signature '%s' in analysis runbooks", signature))
+		}
+		runbook := settings.Heuristics.Runbooks[signature]
+		if u, err := url.Parse(runbook); err != nil || u.Scheme == "" || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid runbook URL '%s' for failure signature '%s'", runbook, signature))
+		}
+	}
+
+	if settings.Endpoint == "" {
+		if c.Features[features.AIAnalysis] {
+			result.Errors = append(result.Errors, "analysis endpoint is required when the aiAnalysis feature is enabled")
+		}
+		return
+	}
+
+	if u, err := url.Parse(settings.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid analysis endpoint '%s'", settings.Endpoint))
+	} else if u.Scheme != "https" {
+		result.Warnings = append(result.Warnings, "analysis endpoint does not use HTTPS, issue context is sent unencrypted")
+	}
+	if settings.Timeout <= 0 {
+		result.Errors = append(result.Errors, "analysis timeout must be positive")
+	}
+	if settings.MaxTokens < 0 {
+		result.Errors = append(result.Errors, "analysis maxTokens cannot be negative")
+	}
+	if !isValidSeverity(settings.MinSeverity) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid analysis minSeverity '%s'", settings.MinSeverity))
+	}
+	if !c.Features[features.AIAnalysis] {
+		result.Warnings = append(result.Warnings, "analysis endpoint is set but the aiAnalysis feature is disabled")
+	}
+}
+
+// validateExport validates the document export settings
+func (c *Config) validateExport(result *ValidationResult) {
+	export := c.Export
+	if !export.Enabled {
+		return
+	}
+
+	if u, err := url.Parse(export.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid export URL '%s'", export.URL))
+	} else if u.Scheme != "https" {
+		result.Warnings = append(result.Warnings, "export URL does not use HTTPS, documents are sent unencrypted")
+	}
+	if export.IndexPrefix == "" || export.IndexPrefix != strings.ToLower(export.IndexPrefix) || strings.ContainsAny(export.IndexPrefix, `*\/?"<>| ,#:`) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid export indexPrefix '%s', must be a lowercase index name", export.IndexPrefix))
+	}
+	if export.BatchSize <= 0 {
+		result.Errors = append(result.Errors, "export batchSize must be positive")
+	}
+	if export.FlushInterval <= 0 || export.Timeout <= 0 {
+		result.Errors = append(result.Errors, "export flushInterval and timeout must be positive")
+	}
+	if export.BufferSize < 0 {
+		result.Errors = append(result.Errors, "export bufferSize cannot be negative")
+	}
+	if export.Username != "" && export.APIKey != "" {
+		result.Warnings = append(result.Warnings, "both export username and apiKey are set, the apiKey is used")
+	}
+}
+
+// validateSecretProviders validates the secret stores and the credentials
+// referencing them
+func (c *Config) validateSecretProviders(result *ValidationResult) {
+	providers := c.SecretProviders
+	if providers.RefreshInterval < 0 || providers.Vault.Timeout < 0 || providers.AWS.Timeout < 0 || providers.GCP.Timeout < 0 {
+		result.Errors = append(result.Errors, "secretProviders refreshInterval and timeouts cannot be negative")
+	}
+
+	credentials := c.Credentials()
+	names := make([]string, 0, len(credentials))
+	for name := range credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]bool)
+	for _, name := range names {
+		ref, ok, err := secrets.ParseRef(credentials[name])
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		used[ref.Provider] = true
+		if ref.Provider == secrets.ProviderGCP && !strings.HasPrefix(ref.Path, "projects/") && providers.GCP.Project == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: secret '%s' has no project and secretProviders.gcp.project is not set", name, ref))
+		}
+	}
+
+	vault := providers.Vault
+	if used[secrets.ProviderVault] {
+		if u, err := url.Parse(vault.Address); err != nil || u.Scheme == "" || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid secretProviders vault address '%s'", vault.Address))
+		}
+		switch vault.AuthMethod {
+		case "", secrets.VaultAuthKubernetes:
+			if vault.Role == "" {
+				result.Errors = append(result.Errors, "secretProviders vault role is required by the kubernetes auth method")
+			}
+		case secrets.VaultAuthToken:
+			if vault.TokenFile == "" {
+				result.Errors = append(result.Errors, "secretProviders vault tokenFile is required by the token auth method")
+			}
+		default:
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid secretProviders vault authMethod '%s' (must be %s or %s)", vault.AuthMethod, secrets.VaultAuthKubernetes, secrets.VaultAuthToken))
+		}
+	}
+	if used[secrets.ProviderAWS] && providers.AWS.Region == "" && os.Getenv("AWS_REGION") == "" {
+		result.Errors = append(result.Errors, "secretProviders aws region is required when AWS_REGION is not set")
+	}
+}
+
+// validateMetrics validates the metric label cardinality limits
+func (c *Config) validateMetrics(result *ValidationResult) {
+	cardinality := c.Metrics.Cardinality
+	if cardinality.MaxNamespaces < 0 || cardinality.MaxRules < 0 {
+		result.Errors = append(result.Errors, "metrics cardinality maxNamespaces and maxRules cannot be negative")
+	}
+
+	names := make(map[string]bool)
+	for i, bucket := range cardinality.NamespaceBuckets {
+		if bucket.Name == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("metrics namespace bucket %d has no name", i))
+		} else if names[bucket.Name] {
+			result.Errors = append(result.Errors, fmt.Sprintf("duplicate metrics namespace bucket '%s'", bucket.Name))
+		}
+		names[bucket.Name] = true
+
+		if len(bucket.Patterns) == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("metrics namespace bucket '%s' has no patterns", bucket.Name))
+		}
+		for _, pattern := range bucket.Patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("invalid pattern '%s' in metrics namespace bucket '%s': %v", pattern, bucket.Name, err))
+			}
+		}
+	}
+}
+
+// validateRuntime validates the runtime tuning and resource limits
+func (c *Config) validateRuntime(result *ValidationResult) {
+	rt := c.Runtime
+	if rt.MemoryLimitRatio < 0 || rt.MemoryLimitRatio > 1 {
+		result.Errors = append(result.Errors, "runtime memoryLimitRatio must be between 0 and 1")
+	}
+	if rt.GCPercent < 0 {
+		result.Errors = append(result.Errors, "runtime gcPercent cannot be negative")
+	}
+	if rt.APIQPS < 0 || rt.APIBurst < 0 {
+		result.Errors = append(result.Errors, "runtime apiQPS and apiBurst cannot be negative")
+	}
+	if rt.APIBurst > 0 && float64(rt.APIBurst) < float64(rt.APIQPS) {
+		result.Warnings = append(result.Warnings, "runtime apiBurst is lower than apiQPS")
+	}
+	if rt.MaxInFlightRequests < 0 {
+		result.Errors = append(result.Errors, "runtime maxInFlightRequests cannot be negative")
+	}
+	if rt.APITimeouts.List < 0 || rt.APITimeouts.Mutate < 0 {
+		result.Errors = append(result.Errors, "runtime apiTimeouts cannot be negative")
+	}
+
+	if batching := rt.Batching; batching.Enabled {
+		if batching.TargetRatio <= 0 || batching.TargetRatio > 1 {
+			result.Errors = append(result.Errors, "runtime batching targetRatio must be greater than 0 and at most 1")
+		}
+		if batching.MinPageSize <= 0 || batching.MaxPageSize < batching.MinPageSize {
+			result.Errors = append(result.Errors, "runtime batching minPageSize must be positive and at most maxPageSize")
+		}
+		if batching.MaxWorkers <= 0 {
+			result.Errors = append(result.Errors, "runtime batching maxWorkers must be positive")
+		}
+	}
+}
+
 func (c *Config) validateController(result *ValidationResult) {
 	if c.Controller.MetricsAddr == "" {
 		result.Errors = append(result.Errors, "metrics address cannot be empty")
@@ -52,6 +356,16 @@ func (c *Config) validateController(result *ValidationResult) {
 		result.Errors = append(result.Errors, "probe address cannot be empty")
 	}
 
+	if h := c.Controller.HTTP; h.ReadHeaderTimeout < 0 || h.ReadTimeout < 0 || h.WriteTimeout < 0 || h.IdleTimeout < 0 {
+		result.Errors = append(result.Errors, "controller http timeouts cannot be negative")
+	}
+
+	if c.Controller.ShutdownTimeout < 0 {
+		result.Errors = append(result.Errors, "controller shutdown timeout cannot be negative")
+	}
+
+	c.validateHealthChecks(result)
+
 	if c.Controller.MaxConcurrentReconciles < 1 {
 		result.Errors = append(result.Errors, "max concurrent reconciles must be at least 1")
 	}
@@ -61,6 +375,33 @@ func (c *Config) validateController(result *ValidationResult) {
 	}
 }
 
+// validateHealthChecks validates the custom health checks
+func (c *Config) validateHealthChecks(result *ValidationResult) {
+	names := make(map[string]bool, len(c.Controller.HealthChecks))
+	for i, check := range c.Controller.HealthChecks {
+		prefix := fmt.Sprintf("healthChecks[%d]", i)
+		switch {
+		case check.Name == "":
+			result.Errors = append(result.Errors, prefix+": name is required")
+		case names[check.Name] || slices.Contains(builtinHealthChecks, check.Name):
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate name '%s'", prefix, check.Name))
+		}
+		names[check.Name] = true
+		if u, err := url.Parse(check.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid URL '%s'", prefix, check.URL))
+		}
+		if check.Timeout < 0 {
+			result.Errors = append(result.Errors, prefix+": timeout cannot be negative")
+		}
+		if check.ExpectedStatus != 0 && (check.ExpectedStatus < 100 || check.ExpectedStatus > 599) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid expectedStatus %d", prefix, check.ExpectedStatus))
+		}
+		if slices.Contains(check.Tags, "") {
+			result.Errors = append(result.Errors, prefix+": tags cannot be empty")
+		}
+	}
+}
+
 func (c *Config) validateDetection(result *ValidationResult) {
 	if c.Detection.EvaluationInterval < time.Second {
 		result.Errors = append(result.Errors, "evaluation interval must be at least 1 second")
@@ -85,6 +426,339 @@ func (c *Config) validateDetection(result *ValidationResult) {
 	if c.Detection.OOMKillThreshold < 1 {
 		result.Errors = append(result.Errors, "OOM kill threshold must be at least 1")
 	}
+
+	if c.Detection.ReverifyInterval < 0 {
+		result.Errors = append(result.Errors, "reverify interval cannot be negative")
+	}
+
+	if c.Detection.ChangeWindow < 0 {
+		result.Errors = append(result.Errors, "change window cannot be negative")
+	}
+
+	if c.Detection.EphemeralContainerMaxAge < 0 {
+		result.Errors = append(result.Errors, "ephemeral container max age cannot be negative")
+	}
+
+	if c.Detection.StuckRolloutAfter < 0 {
+		result.Errors = append(result.Errors, "stuck rollout threshold cannot be negative")
+	}
+
+	if c.Detection.StuckTerminatingAfter < 0 {
+		result.Errors = append(result.Errors, "stuck terminating threshold cannot be negative")
+	}
+
+	if c.Detection.SchedulingConflictAfter < 0 {
+		result.Errors = append(result.Errors, "scheduling conflict threshold cannot be negative")
+	}
+
+	if c.Detection.ServiceWithoutEndpointsAfter < 0 {
+		result.Errors = append(result.Errors, "service without endpoints threshold cannot be negative")
+	}
+
+	if threshold := c.Detection.ResourceQuota.ThresholdPercent; threshold < 0 || threshold > 100 {
+		result.Errors = append(result.Errors, "resource quota threshold percent must be between 0 and 100")
+	}
+
+	if flapping := c.Detection.Flapping; flapping.RestartLimit < 0 || flapping.Window < 0 {
+		result.Errors = append(result.Errors, "flapping restartLimit and window must not be negative")
+	}
+
+	if evicted := c.Detection.EvictedPods; evicted.Threshold < 0 || evicted.MinAge < 0 {
+		result.Errors = append(result.Errors, "evictedPods threshold and minAge must not be negative")
+	}
+
+	rules := make([]string, 0, len(c.Detection.Runbooks))
+	for rule := range c.Detection.Runbooks {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		if !isValidRunbookTemplate(c.Detection.Runbooks[rule]) {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid runbook URL template '%s' for rule '%s'", c.Detection.Runbooks[rule], rule))
+		}
+	}
+
+	validateExclusions("detection", c.Detection.Exclude, result)
+	validateScope(c.Detection.Scope, result)
+
+	for i, container := range c.Detection.Containers {
+		prefix := fmt.Sprintf("detection containers[%d]", i)
+		if (container.Name == "") == (container.Pattern == "") {
+			result.Errors = append(result.Errors, prefix+": exactly one of name or pattern is required")
+		}
+		if container.Pattern != "" {
+			if _, err := regexp.Compile(container.Pattern); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid pattern '%s': %v", prefix, container.Pattern, err))
+			}
+		}
+		set := 0
+		for _, option := range []bool{container.Ignore, container.NotifyOnly, len(container.Actions) > 0} {
+			if option {
+				set++
+			}
+		}
+		if set > 1 {
+			result.Errors = append(result.Errors, prefix+": ignore, notifyOnly and actions are mutually exclusive")
+		}
+	}
+
+	state := c.Detection.State
+	switch state.Backend {
+	case "", "memory":
+	case "configmap":
+		if state.ConfigMapName == "" || state.ConfigMapNamespace == "" {
+			result.Errors = append(result.Errors, "state configmap name and namespace are required for the configmap backend")
+		}
+	case "file":
+		if state.Path == "" {
+			result.Errors = append(result.Errors, "state path is required for the file backend")
+		}
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid state backend '%s' (must be memory, configmap or file)", state.Backend))
+	}
+
+	if state.TTL < 0 {
+		result.Errors = append(result.Errors, "state TTL cannot be negative")
+	} else if state.TTL > 0 && state.TTL < c.Detection.EvaluationInterval {
+		result.Warnings = append(result.Warnings, "state TTL shorter than the evaluation interval resets duration-based conditions every cycle")
+	}
+
+	escalation := c.Detection.Escalation
+	if escalation.Enabled {
+		if len(escalation.Steps) == 0 {
+			result.Errors = append(result.Errors, "escalation steps are required when escalation is enabled")
+		}
+		for i, step := range escalation.Steps {
+			prefix := fmt.Sprintf("escalation steps[%d]", i)
+			if step.After <= 0 {
+				result.Errors = append(result.Errors, prefix+": after must be positive")
+			}
+			if step.Severity == "" || !isValidSeverity(step.Severity) {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid severity '%s' (must be low, medium, high or critical)", prefix, step.Severity))
+			}
+			if i > 0 {
+				previous := escalation.Steps[i-1]
+				if step.After <= previous.After || SeverityRank(step.Severity) <= SeverityRank(previous.Severity) {
+					result.Errors = append(result.Errors, prefix+": steps must raise the severity after increasing durations")
+				}
+			}
+		}
+		if escalation.Hysteresis < 0 {
+			result.Errors = append(result.Errors, "escalation hysteresis cannot be negative")
+		}
+	}
+
+	if c.Detection.MetricsServer.Enabled && c.Detection.MetricsServer.RetryInterval <= 0 {
+		result.Errors = append(result.Errors, "metrics server retry interval must be positive")
+	}
+	if c.Detection.Watch.Debounce < 0 {
+		result.Errors = append(result.Errors, "watch debounce cannot be negative")
+	}
+	if c.Detection.Watch.StaleAfter < 0 {
+		result.Errors = append(result.Errors, "watch staleAfter cannot be negative")
+	}
+	c.validateNodeProblems(result)
+	c.validateWarningEvents(result)
+	c.validateNodeConditions(result)
+	c.validateConfigDrift(result)
+	if decisions := c.Detection.DecisionLog; decisions.SampleRate < 0 || decisions.SampleRate > 1 || decisions.Size < 0 {
+		result.Errors = append(result.Errors, "decisionLog sampleRate must be between 0 and 1 and size must not be negative")
+	}
+
+	c.validateHygiene(result)
+	c.validatePrometheus(result)
+}
+
+// validatePrometheus validates the query rules and their Prometheus servers
+func (c *Config) validatePrometheus(result *ValidationResult) {
+	prometheus := c.Detection.Prometheus
+	validURL := func(value string) bool {
+		u, err := url.Parse(value)
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	}
+	if prometheus.URL != "" && !validURL(prometheus.URL) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid prometheus URL '%s'", prometheus.URL))
+	}
+	if len(prometheus.Rules) > 0 && prometheus.Timeout <= 0 {
+		result.Errors = append(result.Errors, "prometheus timeout must be positive")
+	}
+
+	names := make(map[string]bool, len(prometheus.Rules))
+	for i, rule := range prometheus.Rules {
+		prefix := fmt.Sprintf("prometheus rules[%d]", i)
+		if rule.Name == "" {
+			result.Errors = append(result.Errors, prefix+": name is required")
+		} else if names[rule.Name] {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate rule name '%s'", prefix, rule.Name))
+		}
+		names[rule.Name] = true
+		if rule.Query == "" {
+			result.Errors = append(result.Errors, prefix+": query is required")
+		}
+		if rule.Endpoint == "" && prometheus.URL == "" {
+			result.Errors = append(result.Errors, prefix+": endpoint is required without a prometheus URL")
+		} else if rule.Endpoint != "" && !validURL(rule.Endpoint) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid endpoint '%s'", prefix, rule.Endpoint))
+		}
+		if !slices.Contains(detection.QueryResources, rule.Resource) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid resource '%s' (must be %s)", prefix, rule.Resource, strings.Join(detection.QueryResources, " or ")))
+		}
+		if !slices.Contains(detection.QueryOperators, rule.Operator) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid operator '%s' (must be %s)", prefix, rule.Operator, strings.Join(detection.QueryOperators, ", ")))
+		}
+		if rule.For < 0 {
+			result.Errors = append(result.Errors, prefix+": for cannot be negative")
+		}
+		if rule.Severity == "" || !isValidSeverity(rule.Severity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid severity '%s' (must be low, medium, high or critical)", prefix, rule.Severity))
+		}
+	}
+}
+
+// validateNodeProblems validates the node conditions and events of Node Problem Detector
+func (c *Config) validateNodeProblems(result *ValidationResult) {
+	problems := c.Detection.NodeProblems
+	if !problems.Enabled {
+		return
+	}
+	c.validateNodeProblemEntries(result, "nodeProblems conditions", problems.Conditions)
+	c.validateNodeProblemEntries(result, "nodeProblems events", problems.Events)
+	if len(problems.Conditions)+len(problems.Events) == 0 {
+		result.Warnings = append(result.Warnings, "nodeProblems is enabled without conditions or events")
+	}
+}
+
+// validateWarningEvents validates the event reasons of the warning event rule
+func (c *Config) validateWarningEvents(result *ValidationResult) {
+	events := c.Detection.WarningEvents
+	if !events.Enabled {
+		return
+	}
+	reasons := make(map[string]bool, len(events.Reasons))
+	for i, entry := range events.Reasons {
+		prefix := fmt.Sprintf("warningEvents reasons[%d]", i)
+		if entry.Reason == "" {
+			result.Errors = append(result.Errors, prefix+": reason is required")
+		} else if reasons[entry.Reason] {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate reason '%s'", prefix, entry.Reason))
+		}
+		reasons[entry.Reason] = true
+		if entry.Severity != "" && !isValidSeverity(entry.Severity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid severity '%s' (must be low, medium, high or critical)", prefix, entry.Severity))
+		}
+		if entry.MinCount < 0 {
+			result.Errors = append(result.Errors, prefix+": minCount cannot be negative")
+		}
+		if entry.Within < 0 {
+			result.Errors = append(result.Errors, prefix+": within cannot be negative")
+		}
+		for _, action := range entry.Actions {
+			if !c.knownAction(action) {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: unknown action '%s'", prefix, action))
+			}
+		}
+	}
+	if len(events.Reasons) == 0 {
+		result.Warnings = append(result.Warnings, "warningEvents is enabled without reasons")
+	}
+}
+
+// validateNodeConditions validates the kubelet conditions of the node condition rule
+func (c *Config) validateNodeConditions(result *ValidationResult) {
+	conditions := c.Detection.NodeConditions
+	if !conditions.Enabled {
+		return
+	}
+	c.validateNodeProblemEntries(result, "nodeConditions conditions", conditions.Conditions)
+	if len(conditions.Conditions) == 0 {
+		result.Warnings = append(result.Warnings, "nodeConditions is enabled without conditions")
+	}
+}
+
+// validateConfigDrift validates the instance comparison of the config drift detector
+func (c *Config) validateConfigDrift(result *ValidationResult) {
+	drift := c.Detection.ConfigDrift
+	if !drift.Enabled {
+		return
+	}
+	if drift.ConfigMapName == "" || drift.ConfigMapNamespace == "" {
+		result.Errors = append(result.Errors, "configDrift configmap name and namespace are required")
+	}
+	for i, peer := range drift.Peers {
+		if u, err := url.Parse(peer); err != nil || u.Scheme == "" || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("configDrift peers[%d]: invalid URL '%s'", i, peer))
+		}
+	}
+	for i, path := range drift.Ignore {
+		if path == "" || slices.Contains(strings.Split(path, "."), "") {
+			result.Errors = append(result.Errors, fmt.Sprintf("configDrift ignore[%d]: invalid path '%s'", i, path))
+		}
+	}
+	if drift.StaleAfter <= 0 {
+		result.Errors = append(result.Errors, "configDrift staleAfter must be positive")
+	} else if drift.StaleAfter < 2*c.Detection.EvaluationInterval {
+		result.Warnings = append(result.Warnings, "configDrift staleAfter shorter than two evaluation intervals ignores live instances")
+	}
+	if !isValidSeverity(drift.Severity) {
+		result.Errors = append(result.Errors, fmt.Sprintf("configDrift: invalid severity '%s' (must be low, medium, high or critical)", drift.Severity))
+	}
+}
+
+// validateNodeProblemEntries validates node condition types or event reasons with
+// their severity, duration and actions
+func (c *Config) validateNodeProblemEntries(result *ValidationResult, section string, entries []NodeProblemConfig) {
+	types := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		prefix := fmt.Sprintf("%s[%d]", section, i)
+		if entry.Type == "" {
+			result.Errors = append(result.Errors, prefix+": type is required")
+		} else if types[entry.Type] {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate type '%s'", prefix, entry.Type))
+		}
+		types[entry.Type] = true
+		if entry.Severity != "" && !isValidSeverity(entry.Severity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid severity '%s' (must be low, medium, high or critical)", prefix, entry.Severity))
+		}
+		if entry.For < 0 {
+			result.Errors = append(result.Errors, prefix+": for cannot be negative")
+		}
+		for _, action := range entry.Actions {
+			if !c.knownAction(action) {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: unknown action '%s'", prefix, action))
+			}
+		}
+	}
+}
+
+// validateHygiene validates the advisory workload checks and their report schedule
+func (c *Config) validateHygiene(result *ValidationResult) {
+	h := c.Detection.Hygiene
+	if !h.Enabled {
+		return
+	}
+
+	if _, err := schedule.Parse(h.Cron, h.Timezone); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("hygiene: %v", err))
+	}
+	for _, check := range h.Checks {
+		if !hygiene.IsValidCheck(check) {
+			result.Errors = append(result.Errors, fmt.Sprintf("hygiene: unknown check '%s' (must be one of %s)", check, strings.Join(hygiene.Checks, ", ")))
+		}
+	}
+	for _, pattern := range append(append([]string{}, h.ProductionNamespaces...), h.ExcludeNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("hygiene: invalid namespace pattern '%s'", pattern))
+		}
+	}
+	if h.MaxFindings < 1 {
+		result.Errors = append(result.Errors, "hygiene maxFindings must be at least 1")
+	}
+	if h.SlackChannel != "" && !isValidSlackChannel(h.SlackChannel) {
+		result.Errors = append(result.Errors, fmt.Sprintf("hygiene: invalid slack channel '%s'", h.SlackChannel))
+	}
+	if !c.Notification.Slack.Enabled {
+		result.Warnings = append(result.Warnings, "hygiene reports are only served by the API and metrics without slack notifications")
+	}
 }
 
 func (c *Config) validateRemediation(result *ValidationResult) {
@@ -115,9 +789,350 @@ func (c *Config) validateRemediation(result *ValidationResult) {
 	if c.Remediation.CooldownSeconds > 3600 {
 		result.Warnings = append(result.Warnings, "cooldown period greater than 1 hour may be too long")
 	}
+
+	if !isValidSeverity(c.Remediation.MinSeverity) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid remediation minSeverity '%s' (must be low, medium, high or critical)", c.Remediation.MinSeverity))
+	}
+
+	if c.Remediation.Priority.MaxActionsPerCycle < 0 {
+		result.Errors = append(result.Errors, "max actions per cycle cannot be negative")
+	}
+
+	for namespace, tier := range c.Remediation.Priority.NamespaceTiers {
+		if !isValidNamespaceName(namespace) {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid namespace name '%s' in namespace tiers", namespace))
+		}
+		if tier < 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': tier cannot be negative", namespace))
+		}
+	}
+
+	for namespace, nsConfig := range c.Remediation.Namespaces {
+		if !isValidSeverity(nsConfig.MinSeverity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': invalid remediation minSeverity '%s'", namespace, nsConfig.MinSeverity))
+		}
+	}
+
+	validateExclusions("remediation", c.Remediation.Exclude, result)
+	c.validateExecutor(result)
+
+	if batch := c.Remediation.Batch; batch.Enabled {
+		switch batch.Action {
+		case "restart-deployment", "rolling-restart-pods", "rollback-deployment":
+		default:
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid batch action '%s' (must be restart-deployment, rolling-restart-pods or rollback-deployment)", batch.Action))
+		}
+		if batch.MinPods < 2 {
+			result.Errors = append(result.Errors, "batch minPods must be at least 2")
+		}
+	}
+
+	if c.Remediation.RollingRestart.ReadyTimeout < 0 {
+		result.Errors = append(result.Errors, "rollingRestart readyTimeout must not be negative")
+	}
+	if c.Remediation.RollingRestart.PollInterval < 0 {
+		result.Errors = append(result.Errors, "rollingRestart pollInterval must not be negative")
+	}
+	if watch := c.Remediation.RolloutWatch; watch.Timeout < 0 || watch.PollInterval < 0 {
+		result.Errors = append(result.Errors, "rolloutWatch timeout and pollInterval must not be negative")
+	}
+
+	podRestart := c.Remediation.PodRestart
+	switch podRestart.Method {
+	case "", "evict", "delete":
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid podRestart method '%s' (must be evict or delete)", podRestart.Method))
+	}
+	if podRestart.EvictionTimeout < 0 || podRestart.RetryInterval < 0 {
+		result.Errors = append(result.Errors, "podRestart evictionTimeout and retryInterval must not be negative")
+	}
+	if c.Remediation.ContainerRestartTimeout < 0 {
+		result.Errors = append(result.Errors, "containerRestartTimeout must not be negative")
+	}
+	if c.Remediation.IncidentMode.MaxDuration < 0 {
+		result.Errors = append(result.Errors, "incidentMode maxDuration must not be negative")
+	}
+	if drain := c.Remediation.NodeDrain; drain.Timeout < 0 || drain.GracePeriod < 0 || drain.MaxNodesPerHour < 0 {
+		result.Errors = append(result.Errors, "nodeDrain timeout, gracePeriod and maxNodesPerHour must not be negative")
+	}
+	if c.Remediation.EvictedPodCleanup.MaxPodsPerCycle < 0 {
+		result.Errors = append(result.Errors, "evictedPodCleanup maxPodsPerCycle must not be negative")
+	}
+	if c.Remediation.PreChecks.MaxAffectedPods < 0 {
+		result.Errors = append(result.Errors, "preChecks maxAffectedPods must not be negative")
+	}
+	preCheckActions := make([]string, 0, len(c.Remediation.PreChecks.Actions))
+	for action := range c.Remediation.PreChecks.Actions {
+		preCheckActions = append(preCheckActions, action)
+	}
+	sort.Strings(preCheckActions)
+	for _, action := range preCheckActions {
+		for _, check := range c.Remediation.PreChecks.Actions[action] {
+			if !slices.Contains(preCheckNames, check) {
+				result.Errors = append(result.Errors, fmt.Sprintf("invalid pre-check '%s' of action '%s' (must be one of %s)", check, action, strings.Join(preCheckNames, ", ")))
+			}
+		}
+	}
+
+	c.validateActionDefaults(result)
+
+	hook := c.Remediation.PreRemediationHook
+	if hook.Annotation != "" && !isValidAnnotationKey(hook.Annotation) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid preRemediationHook annotation '%s'", hook.Annotation))
+	}
+	if hook.GracePeriod < 0 {
+		result.Errors = append(result.Errors, "preRemediationHook gracePeriod must not be negative")
+	} else if hook.GracePeriod > 10*time.Minute {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("preRemediationHook gracePeriod %s delays every disruptive action", hook.GracePeriod))
+	}
+
+	c.validateFinalizers(result)
+	c.validatePlugins(result)
+
+	if queue := c.Remediation.Queue; queue.Enabled {
+		if queue.RatePerSecond < 0 || queue.Burst < 0 {
+			result.Errors = append(result.Errors, "queue ratePerSecond and burst must not be negative")
+		}
+		if queue.MaxAttempts < 1 {
+			result.Errors = append(result.Errors, "queue maxAttempts must be at least 1")
+		}
+		if queue.InitialBackoff <= 0 || queue.MaxBackoff < queue.InitialBackoff {
+			result.Errors = append(result.Errors, "queue initialBackoff must be positive and at most maxBackoff")
+		}
+		if queue.PollInterval <= 0 {
+			result.Errors = append(result.Errors, "queue pollInterval must be positive")
+		}
+		if c.Detection.State.Backend == "" || c.Detection.State.Backend == "memory" {
+			result.Warnings = append(result.Warnings, "remediation queue uses the memory state backend, queued actions are lost on restart")
+		}
+	}
+}
+
+// validateFinalizers validates the finalizers allowed for removal
+func (c *Config) validateFinalizers(result *ValidationResult) {
+	finalizers := c.Remediation.Finalizers
+	if len(finalizers.Allowed) > 0 && finalizers.StuckAfter <= 0 {
+		result.Errors = append(result.Errors, "finalizers stuckAfter must be positive")
+	}
+	seen := make(map[string]bool)
+	for _, allowed := range finalizers.Allowed {
+		if allowed.Name == "" {
+			result.Errors = append(result.Errors, "allowed finalizer must have a name")
+			continue
+		}
+		if seen[allowed.Name] {
+			result.Errors = append(result.Errors, fmt.Sprintf("duplicate allowed finalizer '%s'", allowed.Name))
+		}
+		seen[allowed.Name] = true
+		if len(allowed.Kinds) == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("allowed finalizer '%s' must list its kinds", allowed.Name))
+		}
+		for _, kind := range allowed.Kinds {
+			if !slices.Contains(detection.FinalizerKinds, kind) {
+				result.Errors = append(result.Errors, fmt.Sprintf("allowed finalizer '%s' has invalid kind '%s' (must be one of %s)",
+					allowed.Name, kind, strings.Join(detection.FinalizerKinds, ", ")))
+			}
+		}
+		switch allowed.Mode {
+		case "", "approval":
+		case "automatic":
+			result.Warnings = append(result.Warnings, fmt.Sprintf("finalizer '%s' is removed without approval", allowed.Name))
+		default:
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid mode '%s' of allowed finalizer '%s' (must be approval or automatic)", allowed.Mode, allowed.Name))
+		}
+	}
+}
+
+// validatePlugins validates the remediation actions implemented by external programs
+func (c *Config) validatePlugins(result *ValidationResult) {
+	seen := make(map[string]bool)
+	for i, plugin := range c.Remediation.Plugins {
+		prefix := fmt.Sprintf("plugins[%d]", i)
+		switch _, builtin := permissions.ActionRequirements[plugin.Name]; {
+		case plugin.Name == "":
+			result.Errors = append(result.Errors, prefix+": name is required")
+		case builtin || plugin.Name == detection.NotifyOnlyAction:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: name '%s' is a built-in action", prefix, plugin.Name))
+		case seen[plugin.Name]:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: duplicate name '%s'", prefix, plugin.Name))
+		}
+		seen[plugin.Name] = true
+		if len(plugin.Command) == 0 || !path.IsAbs(plugin.Command[0]) {
+			result.Errors = append(result.Errors, prefix+": command must start with the absolute path of the program")
+		}
+		if plugin.Timeout < 0 || plugin.MaxCPUTime < 0 || plugin.MaxOutputBytes < 0 || plugin.MaxMemoryMB < 0 {
+			result.Errors = append(result.Errors, prefix+": timeout and limits cannot be negative")
+		}
+	}
+}
+
+// knownAction returns true if an action is a built-in action, notify-only or
+// implemented by a plugin
+func (c *Config) knownAction(action string) bool {
+	if _, builtin := permissions.ActionRequirements[action]; builtin || action == detection.NotifyOnlyAction {
+		return true
+	}
+	return slices.ContainsFunc(c.Remediation.Plugins, func(plugin PluginConfig) bool { return plugin.Name == action })
+}
+
+// validateActionDefaults validates the default actions by severity and the
+// stripped actions
+func (c *Config) validateActionDefaults(result *ValidationResult) {
+	defaults := c.Remediation.ActionDefaults
+	severities := make([]string, 0, len(defaults.BySeverity))
+	for severity := range defaults.BySeverity {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+	for _, severity := range severities {
+		if !isValidSeverity(severity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("actionDefaults: invalid severity '%s' (must be low, medium, high or critical)", severity))
+		}
+		actions := defaults.BySeverity[severity]
+		for _, action := range actions {
+			if !c.knownAction(action) {
+				result.Errors = append(result.Errors, fmt.Sprintf("actionDefaults %s: unknown action '%s'", severity, action))
+			} else if slices.Contains(defaults.Strip, action) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("actionDefaults %s: action '%s' is stripped", severity, action))
+			}
+		}
+		if slices.Contains(actions, detection.NotifyOnlyAction) && len(actions) > 1 {
+			result.Errors = append(result.Errors, fmt.Sprintf("actionDefaults %s: %s cannot be combined with other actions", severity, detection.NotifyOnlyAction))
+		}
+	}
+	for _, action := range defaults.Strip {
+		if action == detection.NotifyOnlyAction || !c.knownAction(action) {
+			result.Errors = append(result.Errors, fmt.Sprintf("actionDefaults strip: unknown action '%s'", action))
+		}
+	}
+}
+
+// validateExecutor validates the remediation executor
+func (c *Config) validateExecutor(result *ValidationResult) {
+	executor := c.Remediation.Executor
+	switch executor.Type {
+	case "", "client", "record":
+	case "webhook":
+		if u, err := url.Parse(executor.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid executor URL '%s'", executor.URL))
+		} else if u.Scheme != "https" {
+			result.Warnings = append(result.Warnings, "executor URL does not use HTTPS, desired actions are sent unencrypted")
+		}
+		if executor.Timeout <= 0 {
+			result.Errors = append(result.Errors, "executor timeout must be positive")
+		}
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid executor type '%s' (must be client, record or webhook)", executor.Type))
+	}
+}
+
+// exclusionKinds are the workload kinds exclusions can match
+var exclusionKinds = map[string]bool{
+	"Pod":         true,
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"ReplicaSet":  true,
+	"Job":         true,
+}
+
+// validateExclusions validates the workload exclusions of a config section
+func validateExclusions(section string, exclusions []ExclusionConfig, result *ValidationResult) {
+	for i, exclusion := range exclusions {
+		prefix := fmt.Sprintf("%s exclude[%d]", section, i)
+		if (exclusion.Name == "") == (exclusion.Pattern == "") {
+			result.Errors = append(result.Errors, prefix+": exactly one of name or pattern is required")
+		}
+		if exclusion.Pattern != "" {
+			if _, err := regexp.Compile(exclusion.Pattern); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid pattern '%s': %v", prefix, exclusion.Pattern, err))
+			}
+		}
+		if exclusion.Kind != "" && !exclusionKinds[exclusion.Kind] {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: unsupported kind '%s'", prefix, exclusion.Kind))
+		}
+		if exclusion.Namespace != "" && !isValidNamespaceName(exclusion.Namespace) {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid namespace name '%s'", prefix, exclusion.Namespace))
+		}
+	}
+}
+
+func validateScope(scope ScopeConfig, result *ValidationResult) {
+	for _, pattern := range append(append([]string{}, scope.IncludeNamespaces...), scope.ExcludeNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("detection scope: invalid namespace pattern '%s'", pattern))
+		}
+	}
+	for _, selector := range scope.NamespaceSelectors {
+		if _, err := labels.Parse(selector); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("detection scope: invalid namespace selector '%s': %v", selector, err))
+		}
+	}
 }
 
 func (c *Config) validateNotification(result *ValidationResult) {
+	if c.Notification.RepeatInterval < 0 {
+		result.Errors = append(result.Errors, "notification repeat interval cannot be negative")
+	}
+
+	queue := c.Notification.Queue
+	if queue.Size < 0 {
+		result.Errors = append(result.Errors, "notification queue size cannot be negative")
+	}
+	if queue.Size > 0 {
+		if queue.MaxRetries < 0 {
+			result.Errors = append(result.Errors, "notification queue max retries cannot be negative")
+		}
+		if queue.InitialBackoff <= 0 {
+			result.Errors = append(result.Errors, "notification queue initial backoff must be positive")
+		}
+		if queue.MaxBackoff < queue.InitialBackoff {
+			result.Errors = append(result.Errors, "notification queue max backoff must be at least the initial backoff")
+		}
+	}
+
+	batch := c.Notification.Batch
+	if batch.Enabled {
+		if batch.Window <= 0 {
+			result.Errors = append(result.Errors, "notification batch window must be positive when batching is enabled")
+		}
+		if batch.MinIssues < 1 {
+			result.Errors = append(result.Errors, "notification batch minIssues must be at least 1")
+		}
+		if batch.DashboardURL != "" {
+			if u, err := url.Parse(batch.DashboardURL); err != nil || u.Scheme == "" || u.Host == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("invalid notification batch dashboard URL '%s'", batch.DashboardURL))
+			}
+		}
+	}
+
+	catalog := c.Notification.Catalog
+	switch catalog.Backend {
+	case "":
+	case "configmap":
+		if catalog.ConfigMapName == "" || catalog.ConfigMapNamespace == "" {
+			result.Errors = append(result.Errors, "catalog configmap name and namespace are required for the configmap backend")
+		}
+	case "backstage":
+		if u, err := url.Parse(catalog.BackstageURL); err != nil || u.Scheme == "" || u.Host == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("invalid catalog backstage URL '%s'", catalog.BackstageURL))
+		}
+		if catalog.Timeout <= 0 {
+			result.Errors = append(result.Errors, "catalog timeout must be positive")
+		}
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid catalog backend '%s' (must be configmap or backstage)", catalog.Backend))
+	}
+	if catalog.Backend != "" && catalog.TTL <= 0 {
+		result.Errors = append(result.Errors, "catalog TTL must be positive")
+	}
+
+	if c.Notification.Lifecycle.MinUptime < 0 {
+		result.Errors = append(result.Errors, "notification lifecycle minUptime cannot be negative")
+	}
+
+	c.validateDigest(result)
+
 	if c.Notification.Slack.Enabled {
 		if c.Notification.Slack.Token == "" {
 			result.Errors = append(result.Errors, "slack token is required when slack notifications are enabled")
@@ -127,6 +1142,10 @@ func (c *Config) validateNotification(result *ValidationResult) {
 			result.Errors = append(result.Errors, "slack channel is required when slack notifications are enabled")
 		}
 
+		if c.Notification.Slack.MinInterval < 0 {
+			result.Errors = append(result.Errors, "slack minInterval cannot be negative")
+		}
+
 		if c.Notification.Slack.Username == "" {
 			result.Warnings = append(result.Warnings, "slack username is not set, using default")
 		}
@@ -138,21 +1157,200 @@ func (c *Config) validateNotification(result *ValidationResult) {
 				result.Warnings = append(result.Warnings, fmt.Sprintf("slack channel name may be invalid: %s", c.Notification.Slack.Channel))
 			}
 		}
+
+		teams := make([]string, 0, len(c.Notification.Slack.TeamChannels))
+		for team := range c.Notification.Slack.TeamChannels {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+		for _, team := range teams {
+			channel := c.Notification.Slack.TeamChannels[team]
+			if channel == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("slack teamChannels.%s: channel cannot be empty", team))
+			} else if !isValidSlackChannel(channel) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("slack teamChannels.%s: channel name may be invalid: %s", team, channel))
+			}
+		}
+
+		severities := make([]string, 0, len(c.Notification.Slack.SeverityChannels))
+		for severity := range c.Notification.Slack.SeverityChannels {
+			severities = append(severities, severity)
+		}
+		sort.Strings(severities)
+		for _, severity := range severities {
+			channel := c.Notification.Slack.SeverityChannels[severity]
+			if severity == "" || !isValidSeverity(severity) {
+				result.Errors = append(result.Errors, fmt.Sprintf("slack severityChannels: invalid severity '%s' (must be low, medium, high or critical)", severity))
+			}
+			if channel == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("slack severityChannels.%s: channel cannot be empty", severity))
+			} else if !isValidSlackChannel(channel) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("slack severityChannels.%s: channel name may be invalid: %s", severity, channel))
+			}
+		}
+	}
+}
+
+func (c *Config) validateDigest(result *ValidationResult) {
+	d := c.Notification.Digest
+	if !d.Enabled {
+		return
+	}
+
+	if d.TopWorkloads < 1 {
+		result.Errors = append(result.Errors, "digest topWorkloads must be at least 1")
+	}
+	if d.ToilPerIssue < 0 {
+		result.Errors = append(result.Errors, "digest toilPerIssue cannot be negative")
+	}
+	if len(d.Channels) == 0 {
+		result.Warnings = append(result.Warnings, "digest is enabled but no channels are configured")
+	}
+
+	names := make(map[string]bool, len(d.Channels))
+	for i, ch := range d.Channels {
+		prefix := fmt.Sprintf("digest channel %d", i)
+		if ch.Name == "" {
+			result.Errors = append(result.Errors, prefix+": name is required")
+		} else {
+			prefix = fmt.Sprintf("digest channel '%s'", ch.Name)
+			if names[ch.Name] {
+				result.Errors = append(result.Errors, prefix+": duplicate name")
+			}
+			names[ch.Name] = true
+		}
+		if _, err := schedule.Parse(ch.Cron, ch.Timezone); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", prefix, err))
+		}
+
+		switch ch.Type {
+		case "slack":
+			if !c.Notification.Slack.Enabled {
+				result.Errors = append(result.Errors, prefix+": slack notifications must be enabled for slack digests")
+			}
+		case "email":
+			if d.SMTP.Host == "" || d.SMTP.From == "" {
+				result.Errors = append(result.Errors, prefix+": digest smtp host and from are required for email digests")
+			}
+			if len(ch.To) == 0 {
+				result.Errors = append(result.Errors, prefix+": at least one recipient is required")
+			}
+		case "webhook":
+			if u, err := url.Parse(ch.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid webhook URL '%s'", prefix, ch.URL))
+			}
+		default:
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid type '%s' (must be slack, email or webhook)", prefix, ch.Type))
+		}
+	}
+}
+
+func (c *Config) validateAPI(result *ValidationResult) {
+	if c.API.Enabled && c.API.BindAddress == "" {
+		result.Errors = append(result.Errors, "API bind address cannot be empty when the API is enabled")
+	}
+	if (c.API.TLSCertFile == "") != (c.API.TLSKeyFile == "") {
+		result.Errors = append(result.Errors, "API tlsCertFile and tlsKeyFile must be set together")
+	}
+
+	switch auth := c.API.Authentication; auth.Mode {
+	case "", APIAuthKubernetes:
+		if c.API.Enabled && c.API.TLSCertFile == "" {
+			result.Warnings = append(result.Warnings, "API is served over plain HTTP, bearer tokens are sent unencrypted")
+		}
+	case APIAuthRequestHeader:
+		if c.API.TLSCertFile == "" || auth.ClientCAFile == "" {
+			result.Errors = append(result.Errors, "API requestHeader authentication requires tlsCertFile, tlsKeyFile and authentication.clientCAFile")
+		}
+	default:
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid API authentication mode '%s' (must be %s or %s)", auth.Mode, APIAuthKubernetes, APIAuthRequestHeader))
+	}
+
+	if slices.Contains(c.Remediation.ImpersonateGroups, "system:masters") {
+		result.Errors = append(result.Errors, "remediation.impersonateGroups must not contain system:masters")
+	}
+	if c.Remediation.ImpersonateRequester && !c.API.Enabled {
+		result.Warnings = append(result.Warnings, "requester impersonation is enabled but the API is disabled, no manual actions can be triggered")
 	}
 }
 
 func (c *Config) validateNamespaces(result *ValidationResult) {
+	for namespace, nsConfig := range c.Namespaces {
+		c.validateNamespaceConfig(namespace, nsConfig, result)
+	}
 	for namespace, nsConfig := range c.Detection.Namespaces {
-		if !isValidNamespaceName(namespace) {
-			result.Errors = append(result.Errors, fmt.Sprintf("invalid namespace name '%s'", namespace))
+		c.validateNamespaceConfig(namespace, nsConfig, result)
+	}
+}
+
+func (c *Config) validateNamespaceConfig(namespace string, nsConfig NamespaceConfig, result *ValidationResult) {
+	if !isValidNamespaceName(namespace) {
+		result.Errors = append(result.Errors, fmt.Sprintf("invalid namespace name '%s'", namespace))
+		return
+	}
+
+	c.validateNamespaceCrashLoopConfig(namespace, nsConfig.CrashLoop, result)
+	c.validateNamespaceDeploymentConfig(namespace, nsConfig.Deployment, result)
+	c.validateNamespaceCPUConfig(namespace, nsConfig.CPU, result)
+	c.validateNamespaceResourceQuotaConfig(namespace, nsConfig.ResourceQuota, result)
+	c.validateNamespaceFlappingConfig(namespace, nsConfig.Flapping, result)
+	c.validateNamespaceMemoryConfig(namespace, nsConfig.Memory, result)
+	c.validateNamespaceRemediationConfig(namespace, nsConfig.Remediation, result)
+	if !isValidSeverity(nsConfig.Remediation.MinSeverity) {
+		result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': invalid remediation minSeverity '%s'", namespace, nsConfig.Remediation.MinSeverity))
+	}
+}
+
+// validateLegacyNamespaces flags settings in the deprecated detection.namespaces and
+// remediation.namespaces maps that cannot be migrated unambiguously
+func (c *Config) validateLegacyNamespaces(result *ValidationResult) {
+	legacy := c.legacyNamespaceNames()
+	if len(legacy) == 0 {
+		return
+	}
+
+	result.Warnings = append(result.Warnings, "detection.namespaces and remediation.namespaces are deprecated, move namespace settings to namespaces")
+
+	for _, namespace := range legacy {
+		if _, exists := c.Namespaces[namespace]; exists {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("namespace '%s': configured in namespaces, legacy settings are ignored", namespace))
+			continue
+		}
+
+		detectionConfig, inDetection := c.Detection.Namespaces[namespace]
+		remediationConfig, inRemediation := c.Remediation.Namespaces[namespace]
+		if inDetection && inRemediation &&
+			detectionConfig.Remediation != (NamespaceRemediationConfig{}) &&
+			detectionConfig.Remediation != remediationConfig {
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': remediation settings in detection.namespaces conflict with remediation.namespaces, move one of them to namespaces", namespace))
+		}
+	}
+}
+
+func (c *Config) validateNamespaceSelectors(result *ValidationResult) {
+	for i, selector := range c.NamespaceSelectors {
+		name := fmt.Sprintf("selector[%d]", i)
+		if len(selector.Names) == 0 && len(selector.MatchLabels) == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace %s: names or matchLabels are required", name))
 			continue
 		}
 
-		c.validateNamespaceCrashLoopConfig(namespace, nsConfig.CrashLoop, result)
-		c.validateNamespaceDeploymentConfig(namespace, nsConfig.Deployment, result)
-		c.validateNamespaceCPUConfig(namespace, nsConfig.CPU, result)
-		c.validateNamespaceMemoryConfig(namespace, nsConfig.Memory, result)
-		c.validateNamespaceRemediationConfig(namespace, nsConfig.Remediation, result)
+		for _, pattern := range selector.Names {
+			if _, err := path.Match(pattern, ""); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("namespace %s: invalid name pattern '%s'", name, pattern))
+			}
+		}
+
+		c.validateNamespaceCrashLoopConfig(name, selector.CrashLoop, result)
+		c.validateNamespaceDeploymentConfig(name, selector.Deployment, result)
+		c.validateNamespaceCPUConfig(name, selector.CPU, result)
+		c.validateNamespaceResourceQuotaConfig(name, selector.ResourceQuota, result)
+		c.validateNamespaceFlappingConfig(name, selector.Flapping, result)
+		c.validateNamespaceMemoryConfig(name, selector.Memory, result)
+		c.validateNamespaceRemediationConfig(name, selector.Remediation, result)
+		if !isValidSeverity(selector.Remediation.MinSeverity) {
+			result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': invalid remediation minSeverity '%s'", name, selector.Remediation.MinSeverity))
+		}
 	}
 }
 
@@ -186,6 +1384,18 @@ func (c *Config) validateNamespaceCPUConfig(namespace string, config CPUConfig,
 	}
 }
 
+func (c *Config) validateNamespaceResourceQuotaConfig(namespace string, config ResourceQuotaConfig, result *ValidationResult) {
+	if config.ThresholdPercent < 0 || config.ThresholdPercent > 100 {
+		result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': resource quota threshold percent must be between 0 and 100", namespace))
+	}
+}
+
+func (c *Config) validateNamespaceFlappingConfig(namespace string, config FlappingConfig, result *ValidationResult) {
+	if config.RestartLimit < 0 || config.Window < 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': flapping restartLimit and window must not be negative", namespace))
+	}
+}
+
 func (c *Config) validateNamespaceMemoryConfig(namespace string, config MemoryConfig, result *ValidationResult) {
 	if config.ThresholdPercent < 0 || config.ThresholdPercent > 100 {
 		result.Errors = append(result.Errors, fmt.Sprintf("namespace '%s': memory threshold percent must be between 0 and 100", namespace))
@@ -214,6 +1424,327 @@ func (c *Config) validateNamespaceRemediationConfig(namespace string, config Nam
 	}
 }
 
+// severityRanks orders issue severities from least to most severe
+var severityRanks = map[string]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// isValidSeverity returns true if the severity is empty or a known severity
+func isValidSeverity(severity string) bool {
+	if severity == "" {
+		return true
+	}
+	_, ok := severityRanks[strings.ToLower(severity)]
+	return ok
+}
+
+// SeverityRank returns the rank of a severity, higher is more severe; unknown severities rank 0
+func SeverityRank(severity string) int {
+	return severityRanks[strings.ToLower(severity)]
+}
+
+// NamespaceSelectorFor returns the first selector matching the namespace, or nil
+func (c *Config) NamespaceSelectorFor(namespace string, labels map[string]string) *NamespaceSelectorConfig {
+	for i := range c.NamespaceSelectors {
+		if c.NamespaceSelectors[i].Matches(namespace, labels) {
+			return &c.NamespaceSelectors[i]
+		}
+	}
+	return nil
+}
+
+// ExpandNamespaceSelectors returns a copy of the configuration in which each of the
+// given namespaces (name to labels) matched by a selector has explicit settings.
+// Precedence: an explicit namespace entry, then the first matching selector in
+// order, then the global defaults.
+func (c *Config) ExpandNamespaceSelectors(namespaces map[string]map[string]string) *Config {
+	if len(c.NamespaceSelectors) == 0 {
+		return c
+	}
+
+	selected := make(map[string]NamespaceConfig)
+	for namespace, labels := range namespaces {
+		if selector := c.NamespaceSelectorFor(namespace, labels); selector != nil {
+			selected[namespace] = selector.NamespaceConfig
+		}
+	}
+	return c.withSelectedNamespaces(selected)
+}
+
+// withSelectedNamespaces returns a copy of the configuration with the settings of
+// namespaces matched by selectors added to the explicit namespace entries, which
+// take precedence
+func (c *Config) withSelectedNamespaces(selected map[string]NamespaceConfig) *Config {
+	expanded := *c
+	expanded.Namespaces = c.NamespacePolicies()
+	expanded.Detection.Namespaces = nil
+	expanded.Remediation.Namespaces = nil
+
+	for namespace, nsConfig := range selected {
+		if _, exists := expanded.Namespaces[namespace]; !exists {
+			expanded.Namespaces[namespace] = nsConfig
+		}
+	}
+	return &expanded
+}
+
+// DefaultNamespaceConfig returns the settings applied to namespaces without an
+// explicit entry, derived from the global detection and remediation settings
+func (c *Config) DefaultNamespaceConfig() NamespaceConfig {
+	return NamespaceConfig{
+		CrashLoop: CrashLoopConfig{
+			RestartLimit:  c.Detection.CrashLoopThreshold,
+			CheckDuration: time.Minute,
+			Enabled:       true,
+		},
+		Deployment: DeploymentConfig{
+			FailureThreshold: c.Detection.FailedDeploymentThreshold,
+			CheckDuration:    10 * time.Minute,
+			Enabled:          true,
+		},
+		CPU: CPUConfig{
+			ThresholdPercent: c.Detection.CPUThresholdPercent,
+			CheckDuration:    5 * time.Minute,
+			Enabled:          true,
+		},
+		Memory: MemoryConfig{
+			ThresholdPercent: c.Detection.MemoryThresholdPercent,
+			CheckDuration:    5 * time.Minute,
+			OOMKillThreshold: c.Detection.OOMKillThreshold,
+			Enabled:          true,
+		},
+		Remediation: NamespaceRemediationConfig{
+			Enabled:             c.Remediation.Enabled,
+			AutoRollbackEnabled: c.Remediation.AutoRollbackEnabled,
+			AutoScaleEnabled:    c.Remediation.AutoScaleEnabled,
+			MaxRetries:          c.Remediation.MaxRetries,
+			RetryInterval:       c.Remediation.RetryInterval,
+			CooldownSeconds:     c.Remediation.CooldownSeconds,
+			HandshakeEnabled:    c.Remediation.PreRemediationHook.Enabled,
+		},
+		ResourceQuota: c.Detection.ResourceQuota,
+		Flapping:      c.Detection.Flapping,
+	}
+}
+
+// NamespacePolicies returns the settings of all explicitly configured namespaces,
+// including entries of the deprecated detection.namespaces and remediation.namespaces
+// maps that have not been migrated. The returned map is a copy.
+func (c *Config) NamespacePolicies() map[string]NamespaceConfig {
+	policies := make(map[string]NamespaceConfig, len(c.Namespaces))
+	for namespace, nsConfig := range c.Namespaces {
+		policies[namespace] = nsConfig
+	}
+	for _, namespace := range c.legacyNamespaceNames() {
+		if _, exists := policies[namespace]; !exists {
+			policies[namespace] = c.legacyNamespaceConfig(namespace)
+		}
+	}
+	return policies
+}
+
+// NamespacePolicy returns the explicit settings of a namespace and false if the
+// namespace is not configured
+func (c *Config) NamespacePolicy(namespace string) (NamespaceConfig, bool) {
+	if nsConfig, exists := c.Namespaces[namespace]; exists {
+		return nsConfig, true
+	}
+
+	_, inDetection := c.Detection.Namespaces[namespace]
+	_, inRemediation := c.Remediation.Namespaces[namespace]
+	if !inDetection && !inRemediation {
+		return NamespaceConfig{}, false
+	}
+	return c.legacyNamespaceConfig(namespace), true
+}
+
+// MigrateNamespaces moves the entries of the deprecated detection.namespaces and
+// remediation.namespaces maps into namespaces. Namespaces already configured in
+// namespaces keep their settings.
+func (c *Config) MigrateNamespaces() {
+	c.Namespaces = c.NamespacePolicies()
+	c.Detection.Namespaces = nil
+	c.Remediation.Namespaces = nil
+}
+
+// legacyNamespaceNames returns the sorted names of namespaces in the deprecated maps
+func (c *Config) legacyNamespaceNames() []string {
+	seen := make(map[string]bool, len(c.Detection.Namespaces)+len(c.Remediation.Namespaces))
+	var names []string
+	for namespace := range c.Detection.Namespaces {
+		if !seen[namespace] {
+			seen[namespace] = true
+			names = append(names, namespace)
+		}
+	}
+	for namespace := range c.Remediation.Namespaces {
+		if !seen[namespace] {
+			seen[namespace] = true
+			names = append(names, namespace)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// legacyNamespaceConfig merges the deprecated entries of a namespace. The
+// remediation.namespaces entry is what the remediation engine used, so it takes
+// precedence over the remediation block of detection.namespaces; missing parts
+// fall back to the defaults.
+func (c *Config) legacyNamespaceConfig(namespace string) NamespaceConfig {
+	nsConfig, inDetection := c.Detection.Namespaces[namespace]
+	if !inDetection {
+		nsConfig = c.DefaultNamespaceConfig()
+	}
+
+	if remediationConfig, exists := c.Remediation.Namespaces[namespace]; exists {
+		nsConfig.Remediation = remediationConfig
+	} else if nsConfig.Remediation == (NamespaceRemediationConfig{}) {
+		nsConfig.Remediation = c.DefaultNamespaceConfig().Remediation
+	}
+	return nsConfig
+}
+
+// EffectiveNamespaceConfig describes the settings that apply to a namespace, or to a
+// single workload in it, after all configuration layers are merged
+type EffectiveNamespaceConfig struct {
+	Namespace string `yaml:"namespace"`
+	// Source is where the namespace settings come from: namespaces, a
+	// namespaceSelectors entry or the global defaults
+	Source      string          `yaml:"source"`
+	Mode        string          `yaml:"mode"`
+	DryRun      bool            `yaml:"dryRun"`
+	MinSeverity string          `yaml:"minSeverity"`
+	Tier        int             `yaml:"tier"`
+	Settings    NamespaceConfig `yaml:"settings"`
+	// Workload and Overrides are set when workload annotations were applied
+	Workload  string            `yaml:"workload,omitempty"`
+	Overrides map[string]string `yaml:"overrides,omitempty"`
+}
+
+// EffectiveNamespace returns the merged settings of a namespace with the given
+// labels, using the same precedence as the controller: an explicit namespace
+// entry, then the first matching selector, then the global defaults
+func (c *Config) EffectiveNamespace(namespace string, labels map[string]string) EffectiveNamespaceConfig {
+	effective := EffectiveNamespaceConfig{
+		Namespace: namespace,
+		Mode:      c.Mode,
+		DryRun:    c.Remediation.DryRun,
+		Tier:      c.NamespaceTier(namespace),
+	}
+
+	if nsConfig, exists := c.NamespacePolicy(namespace); exists {
+		effective.Source = "namespaces." + namespace
+		effective.Settings = nsConfig
+	} else {
+		effective.Source = "defaults"
+		effective.Settings = c.DefaultNamespaceConfig()
+		for i, selector := range c.NamespaceSelectors {
+			if selector.Matches(namespace, labels) {
+				effective.Source = fmt.Sprintf("namespaceSelectors[%d]", i)
+				effective.Settings = selector.NamespaceConfig
+				break
+			}
+		}
+	}
+
+	effective.MinSeverity = effective.Settings.Remediation.MinSeverity
+	if effective.MinSeverity == "" {
+		effective.MinSeverity = c.Remediation.MinSeverity
+	}
+
+	return effective
+}
+
+// ApplyWorkloadOverrides applies the kubeguardian.io annotations of a workload
+// within the workloadOverrides bounds, as detection and remediation do. Invalid
+// annotations are ignored and returned as errors.
+func (e *EffectiveNamespaceConfig) ApplyWorkloadOverrides(bounds *overrides.Bounds, workload string, annotations map[string]string) []error {
+	e.Workload = workload
+	if bounds == nil {
+		return nil
+	}
+
+	var errs []error
+	applyInt := func(key string, intBounds overrides.IntBounds, target *int) {
+		value, ok, err := overrides.Int(annotations, key, intBounds)
+		if err != nil {
+			errs = append(errs, err)
+		} else if ok {
+			*target = value
+			e.recordOverride(key, strconv.Itoa(value))
+		}
+	}
+	applyDuration := func(key string, durationBounds overrides.DurationBounds, target *time.Duration) {
+		value, ok, err := overrides.Duration(annotations, key, durationBounds)
+		if err != nil {
+			errs = append(errs, err)
+		} else if ok {
+			*target = value
+			e.recordOverride(key, value.String())
+		}
+	}
+
+	applyInt(overrides.AnnotationCrashLoopRestartLimit, bounds.RestartLimit, &e.Settings.CrashLoop.RestartLimit)
+	applyDuration(overrides.AnnotationCrashLoopCheckDuration, bounds.CheckDuration, &e.Settings.CrashLoop.CheckDuration)
+	applyDuration(overrides.AnnotationDeploymentCheckDuration, bounds.CheckDuration, &e.Settings.Deployment.CheckDuration)
+	applyInt(overrides.AnnotationOOMKillThreshold, bounds.OOMKillThreshold, &e.Settings.Memory.OOMKillThreshold)
+
+	cooldown := time.Duration(e.Settings.Remediation.CooldownSeconds) * time.Second
+	applyDuration(overrides.AnnotationCooldown, bounds.Cooldown, &cooldown)
+	e.Settings.Remediation.CooldownSeconds = int(cooldown / time.Second)
+
+	if bounds.OptOut {
+		if disabled, err := overrides.Disabled(annotations); err != nil {
+			errs = append(errs, err)
+		} else if disabled {
+			e.recordOverride(overrides.AnnotationDisable, "true")
+		}
+		if actions, ok := overrides.Actions(annotations); ok {
+			e.recordOverride(overrides.AnnotationActions, strings.Join(actions, ","))
+		}
+	}
+
+	return errs
+}
+
+// recordOverride records an applied annotation with its value after clamping
+func (e *EffectiveNamespaceConfig) recordOverride(key, value string) {
+	if e.Overrides == nil {
+		e.Overrides = make(map[string]string)
+	}
+	e.Overrides[key] = value
+}
+
+// NamespaceTier returns the remediation tier of a namespace, lower tiers are remediated first
+func (c *Config) NamespaceTier(namespace string) int {
+	if tier, exists := c.Remediation.Priority.NamespaceTiers[namespace]; exists {
+		return tier
+	}
+	return c.Remediation.Priority.DefaultTier
+}
+
+// RemediationSeverityFloor returns the minimum severity remediated automatically in a namespace
+func (c *Config) RemediationSeverityFloor(namespace string) string {
+	if nsConfig, exists := c.NamespacePolicy(namespace); exists && nsConfig.Remediation.MinSeverity != "" {
+		return nsConfig.Remediation.MinSeverity
+	}
+	return c.Remediation.MinSeverity
+}
+
+// MeetsRemediationSeverity returns true if an issue with the given severity may be
+// remediated automatically in the namespace. Unknown severities never meet a floor.
+func (c *Config) MeetsRemediationSeverity(namespace, severity string) bool {
+	floor := c.RemediationSeverityFloor(namespace)
+	if floor == "" {
+		return true
+	}
+	return SeverityRank(severity) >= SeverityRank(floor)
+}
+
 // isValidNamespaceName validates Kubernetes namespace name
 func isValidNamespaceName(name string) bool {
 	// Kubernetes namespace name regex
@@ -221,6 +1752,34 @@ func isValidNamespaceName(name string) bool {
 	return namespaceRegex.MatchString(name) && len(name) <= 63
 }
 
+// isValidAnnotationKey validates a Kubernetes annotation key: an optional DNS
+// subdomain prefix and a name of at most 63 characters
+func isValidAnnotationKey(key string) bool {
+	prefix, name, found := strings.Cut(key, "/")
+	if !found {
+		prefix, name = "", key
+	}
+	prefixRegex := regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+	nameRegex := regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	if found && (!prefixRegex.MatchString(prefix) || len(prefix) > 253) {
+		return false
+	}
+	return nameRegex.MatchString(name) && len(name) <= 63
+}
+
+// isValidRunbookTemplate checks that a runbook template is an absolute URL once
+// its placeholders are substituted
+func isValidRunbookTemplate(template string) bool {
+	u, err := url.Parse(strings.NewReplacer(
+		"{rule}", "rule",
+		"{namespace}", "namespace",
+		"{kind}", "kind",
+		"{name}", "name",
+		"{team}", "team",
+	).Replace(template))
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
 // isValidSlackChannel validates Slack channel name
 func isValidSlackChannel(channel string) bool {
 	// Slack channel names start with # and contain lowercase letters, numbers, hyphens, and underscores
@@ -231,12 +1790,343 @@ func isValidSlackChannel(channel string) bool {
 	return channelRegex.MatchString(channel) && len(channel) <= 22
 }
 
+// Operating modes
+const (
+	// ModeEnforce detects issues and executes remediation actions
+	ModeEnforce = "enforce"
+	// ModeObserve only detects and notifies; no write operations are made against the cluster
+	ModeObserve = "observe"
+)
+
 // Config represents the main configuration for KubeGuardian
 type Config struct {
+	Mode         string             `yaml:"mode"`
 	Controller   ControllerConfig   `yaml:"controller"`
 	Detection    DetectionConfig    `yaml:"detection"`
 	Remediation  RemediationConfig  `yaml:"remediation"`
 	Notification NotificationConfig `yaml:"notification"`
+	API          APIConfig          `yaml:"api"`
+	// Schedules are named recurring time windows referenced by rules, silences and reports
+	Schedules map[string]ScheduleConfig `yaml:"schedules"`
+	// WorkloadOverrides bounds the settings workloads may override with kubeguardian.io annotations
+	WorkloadOverrides WorkloadOverridesConfig `yaml:"workloadOverrides"`
+	// Namespaces holds the detection and remediation settings of individual namespaces
+	Namespaces map[string]NamespaceConfig `yaml:"namespaces"`
+	// NamespaceSelectors apply namespace settings to namespaces matched by name
+	// pattern or labels; explicit namespace entries take precedence
+	NamespaceSelectors []NamespaceSelectorConfig `yaml:"namespaceSelectors"`
+	// Features enables or disables experimental subsystems by feature flag name
+	Features map[string]bool `yaml:"features"`
+	// Analysis configures the diagnosis of new issues
+	Analysis AnalysisConfig `yaml:"analysis"`
+	// Export indexes issue and remediation documents into Elasticsearch or OpenSearch
+	Export ExportConfig `yaml:"export"`
+	// Metrics bounds the cardinality of metric labels
+	Metrics MetricsConfig `yaml:"metrics"`
+	// Runtime tunes the Go runtime to the container limits and bounds concurrent work
+	Runtime RuntimeConfig `yaml:"runtime"`
+	// Tracing correlates detection cycles and remediation actions with traces
+	Tracing TracingConfig `yaml:"tracing"`
+	// SecretProviders are the secret stores credentials may reference instead
+	// of holding the credential
+	SecretProviders SecretProvidersConfig `yaml:"secretProviders"`
+}
+
+// SecretProvidersConfig configures the secret stores that credentials in this
+// configuration may reference as provider://path#key, e.g.
+// vault://kv/data/kubeguardian#slack-token, aws-sm://prod/kubeguardian#slackToken
+// or gcp-sm://slack-token. References are resolved on startup and re-read every
+// refresh interval to pick up rotated credentials.
+type SecretProvidersConfig struct {
+	RefreshInterval time.Duration             `yaml:"refreshInterval"`
+	Vault           VaultSecretProviderConfig `yaml:"vault"`
+	AWS             AWSSecretProviderConfig   `yaml:"aws"`
+	GCP             GCPSecretProviderConfig   `yaml:"gcp"`
+}
+
+// VaultSecretProviderConfig configures reading secrets from HashiCorp Vault
+type VaultSecretProviderConfig struct {
+	Address string `yaml:"address"`
+	// Namespace is the Vault Enterprise namespace
+	Namespace string `yaml:"namespace"`
+	// AuthMethod is kubernetes, logging in with the service account token, or
+	// token, reading a Vault token from TokenFile
+	AuthMethod string `yaml:"authMethod"`
+	// Role and AuthPath select the role and mount of the Kubernetes auth method
+	Role      string        `yaml:"role"`
+	AuthPath  string        `yaml:"authPath"`
+	TokenFile string        `yaml:"tokenFile"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// AWSSecretProviderConfig configures reading secrets from AWS Secrets Manager,
+// with the credentials of the environment or of an IAM role for service accounts
+type AWSSecretProviderConfig struct {
+	Region string `yaml:"region"`
+	// Endpoint overrides the Secrets Manager endpoint, e.g. a VPC endpoint
+	Endpoint string        `yaml:"endpoint"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// GCPSecretProviderConfig configures reading secrets from Google Cloud Secret
+// Manager, with the Workload Identity of the pod
+type GCPSecretProviderConfig struct {
+	// Project of references naming only the secret
+	Project string        `yaml:"project"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// SecretResolver returns the resolver of the credentials referencing the
+// configured secret stores
+func (c *Config) SecretResolver() *secrets.Resolver {
+	providers := c.SecretProviders
+	return secrets.New(secrets.Config{
+		RefreshInterval: providers.RefreshInterval,
+		Vault: secrets.VaultConfig{
+			Address:    providers.Vault.Address,
+			Namespace:  providers.Vault.Namespace,
+			AuthMethod: providers.Vault.AuthMethod,
+			Role:       providers.Vault.Role,
+			AuthPath:   providers.Vault.AuthPath,
+			TokenFile:  providers.Vault.TokenFile,
+			Timeout:    providers.Vault.Timeout,
+		},
+		AWS: secrets.AWSConfig{
+			Region:   providers.AWS.Region,
+			Endpoint: providers.AWS.Endpoint,
+			Timeout:  providers.AWS.Timeout,
+		},
+		GCP: secrets.GCPConfig{
+			Project: providers.GCP.Project,
+			Timeout: providers.GCP.Timeout,
+		},
+	})
+}
+
+// Credentials returns the settings holding credentials by their path in the
+// configuration; each may reference a secret store instead
+func (c *Config) Credentials() map[string]string {
+	return map[string]string{
+		"notification.slack.token":            c.Notification.Slack.Token,
+		"notification.digest.smtp.password":   c.Notification.Digest.SMTP.Password,
+		"notification.catalog.backstageToken": c.Notification.Catalog.BackstageToken,
+		"remediation.executor.secret":         c.Remediation.Executor.Secret,
+		"detection.prometheus.bearerToken":    c.Detection.Prometheus.BearerToken,
+		"analysis.apiKey":                     c.Analysis.APIKey,
+		"export.username":                     c.Export.Username,
+		"export.password":                     c.Export.Password,
+		"export.apiKey":                       c.Export.APIKey,
+	}
+}
+
+// TracingConfig assigns W3C trace IDs to detection cycles, the remediation actions
+// they cause and action API requests
+type TracingConfig struct {
+	// Enabled logs the trace ID as traceID and attaches it as a trace_id exemplar
+	// to the detection, remediation and HTTP latency histograms, served in the
+	// OpenMetrics format. API requests with a traceparent header continue its trace.
+	Enabled bool `yaml:"enabled"`
+}
+
+// RuntimeConfig tunes the Go runtime to the CPU and memory limits of the container
+// and bounds the work KubeGuardian does concurrently, so it behaves well inside
+// tight limits on large clusters
+type RuntimeConfig struct {
+	// AutoMaxProcs sets GOMAXPROCS to the container CPU quota; ignored when the
+	// GOMAXPROCS environment variable is set
+	AutoMaxProcs bool `yaml:"autoMaxProcs"`
+	// MemoryLimitRatio sets the soft memory limit of the Go runtime to this
+	// fraction of the container memory limit; 0 disables it. Ignored when the
+	// GOMEMLIMIT environment variable is set.
+	MemoryLimitRatio float64 `yaml:"memoryLimitRatio"`
+	// GCPercent is the GC target percentage; 0 keeps the default of 100. Ignored
+	// when the GOGC environment variable is set.
+	GCPercent int `yaml:"gcPercent"`
+	// APIQPS and APIBurst rate limit requests to the Kubernetes API; 0 uses the
+	// client-go defaults
+	APIQPS   float32 `yaml:"apiQPS"`
+	APIBurst int     `yaml:"apiBurst"`
+	// MaxInFlightRequests bounds the requests the action API serves concurrently;
+	// 0 is unlimited
+	MaxInFlightRequests int `yaml:"maxInFlightRequests"`
+	// Batching sizes list pages and remediation workers to the cycle latency
+	Batching BatchingConfig `yaml:"batching"`
+	// APITimeouts bounds each Kubernetes API request
+	APITimeouts APITimeoutsConfig `yaml:"apiTimeouts"`
+}
+
+// APITimeoutsConfig bounds each Kubernetes API request made by the detector,
+// the remediation engine and the controller, so a hung connection fails the
+// request instead of stalling a detection cycle. Watches are not bounded; 0
+// disables a bound.
+type APITimeoutsConfig struct {
+	// List bounds get and list requests
+	List time.Duration `yaml:"list"`
+	// Mutate bounds create, update, patch, delete and eviction requests
+	Mutate time.Duration `yaml:"mutate"`
+}
+
+// BatchingConfig adapts the pods listed per page and the workers processing issues
+// to the observed detection cycle latency, so cycles keep within the evaluation
+// interval as the cluster grows. Both double while cycles take longer than the
+// target and halve while they take less than a quarter of it.
+type BatchingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// TargetRatio is the fraction of the evaluation interval a cycle should take
+	TargetRatio float64 `yaml:"targetRatio"`
+	// MinPageSize and MaxPageSize bound the objects per list request
+	MinPageSize int `yaml:"minPageSize"`
+	MaxPageSize int `yaml:"maxPageSize"`
+	// MaxWorkers bounds the issues processed concurrently
+	MaxWorkers int `yaml:"maxWorkers"`
+}
+
+// MetricsConfig configures the Prometheus metrics
+type MetricsConfig struct {
+	Cardinality CardinalityConfig `yaml:"cardinality"`
+}
+
+// CardinalityConfig bounds the number of distinct namespace and rule label values
+// on large multi-tenant clusters. Values beyond the limits are reported as "other".
+type CardinalityConfig struct {
+	// NamespaceBuckets report all namespaces matching a bucket's glob patterns
+	// under the bucket name; the first matching bucket wins
+	NamespaceBuckets []NamespaceBucketConfig `yaml:"namespaceBuckets"`
+	// MaxNamespaces and MaxRules cap the distinct label values; 0 is unlimited
+	MaxNamespaces int `yaml:"maxNamespaces"`
+	MaxRules      int `yaml:"maxRules"`
+}
+
+// NamespaceBucketConfig aggregates namespaces into a single namespace label value
+type NamespaceBucketConfig struct {
+	Name     string   `yaml:"name"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// ExportConfig configures the export of issue and remediation documents into
+// Elasticsearch or OpenSearch for dashboards and long-term search
+type ExportConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL is the Elasticsearch or OpenSearch endpoint
+	URL string `yaml:"url"`
+	// Username and Password authenticate with basic auth; APIKey with an
+	// Elasticsearch API key instead
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	APIKey   string `yaml:"apiKey"`
+	// IndexPrefix prefixes the daily indices, e.g. kubeguardian-issues-v1-2026.10.16
+	IndexPrefix string `yaml:"indexPrefix"`
+	// BatchSize is the number of documents sent in one bulk request
+	BatchSize int `yaml:"batchSize"`
+	// FlushInterval is the longest time a document is buffered before it is sent
+	FlushInterval time.Duration `yaml:"flushInterval"`
+	// BufferSize is the number of buffered documents; further documents are dropped
+	BufferSize int           `yaml:"bufferSize"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+// AnalysisConfig configures the diagnosis of new issues. Heuristic diagnosis
+// matches known failure signatures; root-cause summaries by a large language
+// model only run while the aiAnalysis feature flag is enabled.
+type AnalysisConfig struct {
+	// Heuristics matches issues against known failure signatures without AI
+	Heuristics HeuristicsConfig `yaml:"heuristics"`
+	// Endpoint is an OpenAI-compatible chat completions URL
+	Endpoint string `yaml:"endpoint"`
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"apiKey"`
+	// MaxTokens bounds the length of the answer; zero uses the endpoint default
+	MaxTokens int `yaml:"maxTokens"`
+	// Timeout bounds the analysis of a single issue
+	Timeout time.Duration `yaml:"timeout"`
+	// MinSeverity is the lowest severity of issues that are analyzed
+	MinSeverity string `yaml:"minSeverity"`
+	// MaxEvents and LogLines bound the events and pod log lines sent for analysis;
+	// zero sends none
+	MaxEvents int `yaml:"maxEvents"`
+	LogLines  int `yaml:"logLines"`
+}
+
+// HeuristicsConfig configures the diagnosis of common failure signatures such as
+// bad image tags, missing ConfigMaps or Secrets, OOM kills and DNS failures
+type HeuristicsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Runbooks overrides the runbook linked for a signature, keyed by signature ID
+	Runbooks map[string]string `yaml:"runbooks"`
+}
+
+// NamespaceSelectorConfig applies detection and remediation settings to every
+// namespace matching its name patterns and labels
+type NamespaceSelectorConfig struct {
+	// Names are glob patterns such as team-*; a namespace must match one of them
+	Names []string `yaml:"names"`
+	// MatchLabels must all be set on the namespace
+	MatchLabels map[string]string `yaml:"matchLabels"`
+	// NamespaceConfig holds the settings applied to matching namespaces
+	NamespaceConfig `yaml:",inline"`
+}
+
+// Matches returns true if the namespace matches the selector. A selector without
+// names or labels matches nothing.
+func (s NamespaceSelectorConfig) Matches(namespace string, labels map[string]string) bool {
+	if len(s.Names) == 0 && len(s.MatchLabels) == 0 {
+		return false
+	}
+
+	if len(s.Names) > 0 {
+		matched := false
+		for _, pattern := range s.Names {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for key, value := range s.MatchLabels {
+		if actual, exists := labels[key]; !exists || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkloadOverridesConfig controls annotation overrides on Deployments and Pods.
+// Values outside of the bounds are clamped.
+type WorkloadOverridesConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	RestartLimit     IntRange      `yaml:"restartLimit"`
+	OOMKillThreshold IntRange      `yaml:"oomKillThreshold"`
+	CheckDuration    DurationRange `yaml:"checkDuration"`
+	Cooldown         DurationRange `yaml:"cooldown"`
+	// OptOut honors the kubeguardian.io/disable and kubeguardian.io/actions
+	// annotations, which exclude a workload or limit its remediation actions
+	OptOut bool `yaml:"optOut"`
+}
+
+// IntRange is an inclusive integer range; a zero Max means no upper bound
+type IntRange struct {
+	Min int `yaml:"min"`
+	Max int `yaml:"max"`
+}
+
+// DurationRange is an inclusive duration range; a zero Max means no upper bound
+type DurationRange struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
+// ScheduleConfig defines a recurring time window using a cron expression
+type ScheduleConfig struct {
+	// Cron is a five-field cron expression or macro such as @daily
+	Cron string `yaml:"cron"`
+	// Timezone is an IANA time zone name, defaults to UTC
+	Timezone string `yaml:"timezone"`
+	// Duration is how long the window stays open after each activation
+	Duration time.Duration `yaml:"duration"`
 }
 
 // ControllerConfig contains controller-specific settings
@@ -246,18 +2136,410 @@ type ControllerConfig struct {
 	LeaderElection          bool          `yaml:"leaderElection"`
 	SyncPeriod              time.Duration `yaml:"syncPeriod"`
 	MaxConcurrentReconciles int           `yaml:"maxConcurrentReconciles"`
+	// HTTP bounds how long the probe, metrics and API servers wait for clients
+	HTTP HTTPConfig `yaml:"http"`
+	// ShutdownTimeout bounds the wait for the detection cycle in progress and
+	// in-flight HTTP requests on shutdown
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+	// HealthChecks are custom health checks reported with the built-in ones
+	HealthChecks []HealthCheckConfig `yaml:"healthChecks"`
+}
+
+// HealthCheckConfig configures a custom health check pinging a URL with GET. It
+// is healthy if the URL answers with ExpectedStatus, or with any status below
+// 400 if ExpectedStatus is zero. Readiness only considers checks tagged
+// critical; the full health report includes every check.
+type HealthCheckConfig struct {
+	Name           string        `yaml:"name"`
+	URL            string        `yaml:"url"`
+	Timeout        time.Duration `yaml:"timeout"`
+	ExpectedStatus int           `yaml:"expectedStatus"`
+	Tags           []string      `yaml:"tags"`
+}
+
+// builtinHealthChecks are the names of the health checks KubeGuardian registers itself
+var builtinHealthChecks = []string{"kubernetes-api", "memory", "disk", "informer-cache"}
+
+// HTTPConfig contains the server-side timeouts of the HTTP servers; zero uses the default
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	// WriteTimeout bounds serving a request, so it must cover forced resyncs
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`
 }
 
 // DetectionConfig contains detection engine settings
 type DetectionConfig struct {
-	RulesFile                 string                     `yaml:"rulesFile"`
-	EvaluationInterval        time.Duration              `yaml:"evaluationInterval"`
-	CrashLoopThreshold        int                        `yaml:"crashLoopThreshold"`
-	FailedDeploymentThreshold int                        `yaml:"failedDeploymentThreshold"`
-	CPUThresholdPercent       float64                    `yaml:"cpuThresholdPercent"`
-	MemoryThresholdPercent    float64                    `yaml:"memoryThresholdPercent"`
-	OOMKillThreshold          int                        `yaml:"oomKillThreshold"`
-	Namespaces                map[string]NamespaceConfig `yaml:"namespaces"`
+	RulesFile                 string        `yaml:"rulesFile"`
+	EvaluationInterval        time.Duration `yaml:"evaluationInterval"`
+	CrashLoopThreshold        int           `yaml:"crashLoopThreshold"`
+	FailedDeploymentThreshold int           `yaml:"failedDeploymentThreshold"`
+	CPUThresholdPercent       float64       `yaml:"cpuThresholdPercent"`
+	MemoryThresholdPercent    float64       `yaml:"memoryThresholdPercent"`
+	OOMKillThreshold          int           `yaml:"oomKillThreshold"`
+	// Deprecated: use Config.Namespaces; entries are migrated when the configuration is loaded
+	Namespaces map[string]NamespaceConfig `yaml:"namespaces"`
+	State      StateConfig                `yaml:"state"`
+	// ReverifyInterval is how often still-active issues are processed again; new
+	// issues are processed immediately. Zero processes active issues every cycle.
+	ReverifyInterval time.Duration `yaml:"reverifyInterval"`
+	// Runbooks sets runbook URL templates of rules by rule name. The placeholders
+	// {rule}, {namespace}, {kind}, {name} and {team} are substituted per issue.
+	Runbooks map[string]string `yaml:"runbooks"`
+	// Rules sets the owner and last modification time of rules by rule name
+	Rules map[string]RuleMetadataConfig `yaml:"rules"`
+	// ChangeWindow includes the most recent rollout of the owning Deployment in
+	// issues if it happened within this window. Zero disables change correlation.
+	ChangeWindow time.Duration `yaml:"changeWindow"`
+	// Exclude lists workloads for which no issues are created
+	Exclude []ExclusionConfig `yaml:"exclude"`
+	// Scope limits the namespaces detection runs in
+	Scope ScopeConfig `yaml:"scope"`
+	// Containers select containers of multi-container pods and the actions for
+	// their issues; the first matching entry applies
+	Containers []ContainerRuleConfig `yaml:"containers"`
+	// EphemeralContainerMaxAge reports ephemeral debug containers running for
+	// longer than this. Zero disables the rule.
+	EphemeralContainerMaxAge time.Duration `yaml:"ephemeralContainerMaxAge"`
+	// StuckRolloutAfter reports Deployment rollouts that are paused or not
+	// progressing for longer than this. Zero disables the rule.
+	StuckRolloutAfter time.Duration `yaml:"stuckRolloutAfter"`
+	// StuckTerminatingAfter reports pods still terminating this long after their
+	// grace period ended. Zero disables the rule.
+	StuckTerminatingAfter time.Duration `yaml:"stuckTerminatingAfter"`
+	// SchedulingConflictAfter reports workloads whose pods have been
+	// unschedulable this long because of their anti-affinity or topology spread
+	// constraints. Zero disables the rule.
+	SchedulingConflictAfter time.Duration `yaml:"schedulingConflictAfter"`
+	// ServiceWithoutEndpointsAfter reports Services with a selector that have
+	// had no ready endpoints this long. Zero disables the rule.
+	ServiceWithoutEndpointsAfter time.Duration `yaml:"serviceWithoutEndpointsAfter"`
+	// ResourceQuota reports ResourceQuotas whose usage of a resource reaches the
+	// threshold, so teams are notified before new pods are rejected
+	ResourceQuota ResourceQuotaConfig `yaml:"resourceQuota"`
+	// Flapping reports containers restarting repeatedly within a recent window,
+	// rather than by their lifetime restart count
+	Flapping FlappingConfig `yaml:"flapping"`
+	// EvictedPods reports namespaces where evicted and failed pods accumulate
+	EvictedPods EvictedPodsConfig `yaml:"evictedPods"`
+	// Escalation raises the severity of issues that stay unresolved
+	Escalation EscalationConfig `yaml:"escalation"`
+	// Hygiene reports advisory findings about the configuration of workloads
+	Hygiene HygieneConfig `yaml:"hygiene"`
+	// MetricsServer sets where the CPU and memory rules read container usage from
+	MetricsServer MetricsServerConfig `yaml:"metricsServer"`
+	// Prometheus defines rules on the results of PromQL queries
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	// Watch re-evaluates pods and deployments when they change
+	Watch WatchConfig `yaml:"watch"`
+	// NodeProblems reports the node conditions and events of Node Problem Detector
+	NodeProblems NodeProblemsConfig `yaml:"nodeProblems"`
+	// WarningEvents reports objects with recurring Warning events of selected reasons
+	WarningEvents WarningEventsConfig `yaml:"warningEvents"`
+	// NodeConditions reports nodes whose kubelet conditions stay unhealthy
+	NodeConditions NodeConditionsConfig `yaml:"nodeConditions"`
+	// ConfigDrift reports KubeGuardian instances running different configurations
+	ConfigDrift ConfigDriftConfig `yaml:"configDrift"`
+	// DecisionLog records the outcome of every rule evaluated against every resource
+	DecisionLog DecisionLogConfig `yaml:"decisionLog"`
+}
+
+// DecisionLogConfig records, for every detection cycle, whether each rule fired
+// for each resource it evaluated or which condition kept it from firing. Counts
+// are exported as metrics; SampleRate of the decisions, at most Size, are kept
+// for the decisions endpoint of the action API, so rule authors can tune
+// thresholds on near misses.
+type DecisionLogConfig struct {
+	Enabled    bool    `yaml:"enabled"`
+	SampleRate float64 `yaml:"sampleRate"`
+	Size       int     `yaml:"size"`
+}
+
+// ConfigDriftConfig compares the effective configuration of KubeGuardian
+// instances, e.g. the replicas of an HA deployment or the instances of several
+// clusters, and reports a config-drift issue while they diverge. The instances
+// of a cluster publish a hash of their configuration to a shared ConfigMap;
+// instances of other clusters are asked for theirs through their action API.
+type ConfigDriftConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Instance names this instance; defaults to the POD_NAME environment
+	// variable, then the hostname
+	Instance string `yaml:"instance"`
+	// Cluster names the cluster of this instance in drift reports
+	Cluster string `yaml:"cluster"`
+	// ConfigMapName and ConfigMapNamespace locate the ConfigMap the instances of
+	// a cluster publish their hashes to
+	ConfigMapName      string `yaml:"configMapName"`
+	ConfigMapNamespace string `yaml:"configMapNamespace"`
+	// Peers are the action API addresses of instances in other clusters
+	Peers []string `yaml:"peers"`
+	// PeerTokenFile holds the bearer token sent to peers, which they authenticate
+	// with a TokenReview; defaults to the service account token of the pod
+	PeerTokenFile string `yaml:"peerTokenFile"`
+	// PeerCAFile verifies the serving certificates of peers; empty uses the
+	// system roots
+	PeerCAFile string `yaml:"peerCAFile"`
+	// Ignore lists the dotted paths of settings excluded from the hash because
+	// they differ between instances by design, e.g. notification.slack.channel
+	Ignore []string `yaml:"ignore"`
+	// StaleAfter ignores instances that did not publish their hash for this
+	// long, e.g. replicas that were scaled down
+	StaleAfter time.Duration `yaml:"staleAfter"`
+	// Severity is the severity of config-drift issues
+	Severity string `yaml:"severity"`
+}
+
+// NodeConditionsConfig selects the kubelet conditions of nodes that are reported
+// once unhealthy for a duration: Ready while it is not True, and DiskPressure,
+// MemoryPressure, PIDPressure or NetworkUnavailable while they are True. Each
+// condition type has its own severity and actions, e.g. cordon-node, or notify-only.
+type NodeConditionsConfig struct {
+	Enabled    bool                `yaml:"enabled"`
+	Conditions []NodeProblemConfig `yaml:"conditions"`
+}
+
+// NodeProblemsConfig selects the problems Node Problem Detector reports on nodes:
+// permanent problems are node conditions, e.g. KernelDeadlock, temporary
+// problems are node events, e.g. KernelOops. Each condition type and event
+// reason has its own severity and actions, e.g. cordon-node, or notify-only.
+type NodeProblemsConfig struct {
+	Enabled    bool                `yaml:"enabled"`
+	Conditions []NodeProblemConfig `yaml:"conditions"`
+	Events     []NodeProblemConfig `yaml:"events"`
+}
+
+// WarningEventsConfig selects the reasons of Warning events, e.g. BackOff or
+// FailedMount, that are reported once seen often enough within a window. Events
+// carry the cause the pod status lacks, e.g. the volume that failed to mount.
+// Each reason has its own severity and actions, e.g. restart-pod, which only
+// apply to events of pods; events of other objects only notify.
+type WarningEventsConfig struct {
+	Enabled bool                `yaml:"enabled"`
+	Reasons []EventReasonConfig `yaml:"reasons"`
+}
+
+// EventReasonConfig configures the issues of a Warning event reason
+type EventReasonConfig struct {
+	Reason   string   `yaml:"reason"`
+	Severity string   `yaml:"severity"`
+	Actions  []string `yaml:"actions"`
+	// MinCount is how many times the event must have been seen within Within
+	MinCount int32 `yaml:"minCount"`
+	// Within is how recent counted events must be; zero counts all events
+	Within time.Duration `yaml:"within"`
+}
+
+// NodeProblemConfig configures the issues of a node condition type or event reason
+type NodeProblemConfig struct {
+	// Type is the condition type or the event reason
+	Type     string   `yaml:"type"`
+	Severity string   `yaml:"severity"`
+	Actions  []string `yaml:"actions"`
+	// For is how long a condition must be True, or how recent an event must be
+	For time.Duration `yaml:"for"`
+}
+
+// WatchConfig configures event-driven detection. The pod and deployment rules
+// read from shared informer caches instead of listing the cluster each cycle,
+// and a changed pod or deployment is re-evaluated on its own once its watch
+// events settle. Full cycles still run every evaluation interval for the rules
+// of other resources and for conditions that only change with time.
+type WatchConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Debounce is how long a changed object waits before it is re-evaluated, so
+	// a burst of updates is evaluated once
+	Debounce time.Duration `yaml:"debounce"`
+	// StaleAfter is how long the watch of a cache may go without an event or a
+	// new resource version before the informer-cache health check reports it
+	// stale. Zero disables the staleness check.
+	StaleAfter time.Duration `yaml:"staleAfter"`
+}
+
+// PrometheusConfig configures query rules, which compare the series returned by
+// a PromQL query against a threshold and report issues for the Deployments or
+// Pods they refer to, e.g. to roll back a Deployment whose error rate breaks its SLO
+type PrometheusConfig struct {
+	// URL is the Prometheus server of rules without their own endpoint
+	URL         string `yaml:"url"`
+	BearerToken string `yaml:"bearerToken"`
+	// Timeout bounds a single query
+	Timeout time.Duration     `yaml:"timeout"`
+	Rules   []QueryRuleConfig `yaml:"rules"`
+}
+
+// QueryRuleConfig is a rule on the result of a PromQL query. Each series of the
+// result is mapped to a resource by its namespace label and NameLabel, and reports
+// an issue while its value compares to Threshold with Operator for at least For.
+type QueryRuleConfig struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Query       string `yaml:"query"`
+	// Endpoint overrides the Prometheus server of the rule
+	Endpoint string `yaml:"endpoint"`
+	// Resource is the kind of the resources the series refer to: Deployment or Pod
+	Resource string `yaml:"resource"`
+	// NameLabel is the label holding the resource name; defaults to the lowercase resource kind
+	NameLabel string `yaml:"nameLabel"`
+	// Operator is greater_than, less_than or equals
+	Operator  string        `yaml:"operator"`
+	Threshold float64       `yaml:"threshold"`
+	For       time.Duration `yaml:"for"`
+	Severity  string        `yaml:"severity"`
+	Actions   []string      `yaml:"actions"`
+}
+
+// MetricsServerConfig configures the pod metrics of the high CPU and memory rules.
+// Container usage from the metrics.k8s.io API is compared against the thresholds
+// as a percentage of the container's limit, or of its request without a limit.
+// While metrics-server is not available, or if disabled, the rules fall back to
+// restart heuristics.
+type MetricsServerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RetryInterval is how long the rules use the heuristics after pod metrics
+	// could not be listed before trying again
+	RetryInterval time.Duration `yaml:"retryInterval"`
+}
+
+// HygieneConfig configures advisory checks of workload configuration: missing
+// readiness probes, identical liveness and readiness probes, missing resource
+// requests and limits, and single replicas in production namespaces. Findings are
+// never remediated nor notified one by one; they are aggregated into a report
+// sent on the Cron schedule in Timezone.
+type HygieneConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Cron     string `yaml:"cron"`
+	Timezone string `yaml:"timezone"`
+	// Checks are the checks run; empty runs all of them
+	Checks []string `yaml:"checks"`
+	// ProductionNamespaces are glob patterns of the namespaces in which
+	// single-replica Deployments and StatefulSets are reported
+	ProductionNamespaces []string `yaml:"productionNamespaces"`
+	// ExcludeNamespaces are glob patterns of namespaces that are not checked
+	ExcludeNamespaces []string `yaml:"excludeNamespaces"`
+	// SlackChannel overrides the default Slack channel for reports
+	SlackChannel string `yaml:"slackChannel"`
+	// MaxFindings limits the findings listed in a report; all of them are counted
+	MaxFindings int `yaml:"maxFindings"`
+}
+
+// EscalationConfig raises the severity of long-lived unresolved issues, which
+// also routes their notifications to the channels of the new severity
+type EscalationConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Steps raise issues active for After to at least Severity, in increasing order
+	Steps []EscalationStepConfig `yaml:"steps"`
+	// Hysteresis is how long an issue may disappear and keep its escalation and
+	// active time when it is detected again, so flapping issues still escalate
+	// and their severity does not drop back and forth
+	Hysteresis time.Duration `yaml:"hysteresis"`
+}
+
+// EscalationStepConfig raises the severity of issues active for After
+type EscalationStepConfig struct {
+	After    time.Duration `yaml:"after"`
+	Severity string        `yaml:"severity"`
+}
+
+// RuleMetadataConfig records whose rule it is and when it last changed; the
+// values are shown in notifications, the API and metrics
+type RuleMetadataConfig struct {
+	Owner string `yaml:"owner"`
+	// LastModified is an RFC 3339 timestamp or date
+	LastModified time.Time `yaml:"lastModified"`
+}
+
+// ContainerRuleConfig matches containers by name and sets the actions for their issues
+type ContainerRuleConfig struct {
+	// Rules limits the entry to these rules; empty applies to all pod rules
+	Rules []string `yaml:"rules"`
+	// Name is an exact container name and Pattern a regular expression matched
+	// against the whole container name; exactly one of them is required
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+	// Ignore creates no issues for matching containers
+	Ignore bool `yaml:"ignore"`
+	// NotifyOnly notifies about issues of matching containers without remediating them
+	NotifyOnly bool `yaml:"notifyOnly"`
+	// Actions replace the actions of the rule for matching containers
+	Actions []string `yaml:"actions"`
+}
+
+// ExclusionConfig matches workloads by name. The workload of a pod is its
+// controlling Deployment, StatefulSet, DaemonSet or Job.
+type ExclusionConfig struct {
+	// Namespace and Kind limit the exclusion; empty values match all
+	Namespace string `yaml:"namespace"`
+	Kind      string `yaml:"kind"`
+	// Name is an exact workload name and Pattern a regular expression matched
+	// against the whole workload name; exactly one of them is required
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// ScopeConfig limits detection to some namespaces. A namespace is in scope if it
+// matches an include pattern or a selector, or if neither is set, and it matches
+// no exclude pattern. Resources of namespaces out of scope are not listed where
+// possible and never reported; cluster-scoped resources such as nodes are always
+// in scope.
+type ScopeConfig struct {
+	// IncludeNamespaces are glob patterns such as team-* of namespaces in scope
+	IncludeNamespaces []string `yaml:"includeNamespaces"`
+	// NamespaceSelectors are label selectors such as team in (payments,shop);
+	// namespaces matching any of them are in scope
+	NamespaceSelectors []string `yaml:"namespaceSelectors"`
+	// ExcludeNamespaces are glob patterns of namespaces never in scope, even if
+	// they are included or selected
+	ExcludeNamespaces []string `yaml:"excludeNamespaces"`
+}
+
+// Enabled returns true if the scope leaves out any namespace
+func (s ScopeConfig) Enabled() bool {
+	return s.Restricted() || len(s.ExcludeNamespaces) > 0
+}
+
+// Restricted returns true if only included or selected namespaces are in scope,
+// rather than all namespaces but the excluded ones
+func (s ScopeConfig) Restricted() bool {
+	return len(s.IncludeNamespaces) > 0 || len(s.NamespaceSelectors) > 0
+}
+
+// Includes returns true if a namespace with the given labels is in scope;
+// invalid patterns and selectors match nothing
+func (s ScopeConfig) Includes(namespace string, namespaceLabels map[string]string) bool {
+	for _, pattern := range s.ExcludeNamespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return false
+		}
+	}
+	if !s.Restricted() {
+		return true
+	}
+
+	for _, pattern := range s.IncludeNamespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	for _, selector := range s.NamespaceSelectors {
+		if parsed, err := labels.Parse(selector); err == nil && parsed.Matches(labels.Set(namespaceLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// StateConfig controls where first-seen times of duration-based conditions are stored
+type StateConfig struct {
+	// Backend is memory, configmap or file; memory state is lost on restart
+	Backend string `yaml:"backend"`
+	// TTL removes conditions that have not been observed for this long
+	TTL time.Duration `yaml:"ttl"`
+	// ConfigMapName and ConfigMapNamespace locate the configmap backend
+	ConfigMapName      string `yaml:"configMapName"`
+	ConfigMapNamespace string `yaml:"configMapNamespace"`
+	// Path is the state file of the file backend, e.g. on a persistent volume
+	Path string `yaml:"path"`
 }
 
 // NamespaceConfig contains namespace-specific detection and remediation settings
@@ -267,6 +2549,26 @@ type NamespaceConfig struct {
 	CPU         CPUConfig                  `yaml:"cpu"`
 	Memory      MemoryConfig               `yaml:"memory"`
 	Remediation NamespaceRemediationConfig `yaml:"remediation"`
+	// ResourceQuota overrides the resource quota threshold of the namespace
+	ResourceQuota ResourceQuotaConfig `yaml:"resourceQuota"`
+	// Flapping overrides the pod flapping restart limit and window of the namespace
+	Flapping FlappingConfig `yaml:"flapping"`
+}
+
+// FlappingConfig contains pod flapping detection settings. In detection, a zero
+// restartLimit or window disables the rule; in a namespace, zero values use the
+// global ones.
+type FlappingConfig struct {
+	// RestartLimit is how many restarts of a container within Window are reported
+	RestartLimit int           `yaml:"restartLimit"`
+	Window       time.Duration `yaml:"window"`
+}
+
+// ResourceQuotaConfig contains resource quota detection settings
+type ResourceQuotaConfig struct {
+	// ThresholdPercent is the used/hard ratio of a quota resource reported. In
+	// detection, zero disables the rule; in a namespace, zero uses the global value.
+	ThresholdPercent float64 `yaml:"thresholdPercent"`
 }
 
 // CrashLoopConfig contains crash loop detection settings for a namespace
@@ -306,23 +2608,418 @@ type NamespaceRemediationConfig struct {
 	MaxRetries          int           `yaml:"maxRetries"`
 	RetryInterval       time.Duration `yaml:"retryInterval"`
 	CooldownSeconds     int           `yaml:"cooldownSeconds"`
+	// MinSeverity overrides the global remediation severity floor for the namespace
+	MinSeverity string `yaml:"minSeverity"`
+	// HandshakeEnabled announces disruptive actions to the pods of the namespace
+	// with the preRemediationHook annotation and grace period
+	HandshakeEnabled bool `yaml:"handshakeEnabled"`
+	// ForceDeleteEnabled opts the namespace in to force-delete-pod, which deletes
+	// pods stuck terminating without waiting for their kubelet
+	ForceDeleteEnabled bool `yaml:"forceDeleteEnabled"`
 }
 
 // RemediationConfig contains remediation engine settings
 type RemediationConfig struct {
-	Enabled             bool                                  `yaml:"enabled"`
-	MaxRetries          int                                   `yaml:"maxRetries"`
-	RetryInterval       time.Duration                         `yaml:"retryInterval"`
-	DryRun              bool                                  `yaml:"dryRun"`
-	AutoRollbackEnabled bool                                  `yaml:"autoRollbackEnabled"`
-	AutoScaleEnabled    bool                                  `yaml:"autoScaleEnabled"`
-	CooldownSeconds     int                                   `yaml:"cooldownSeconds"`
-	Namespaces          map[string]NamespaceRemediationConfig `yaml:"namespaces"`
+	Enabled             bool          `yaml:"enabled"`
+	MaxRetries          int           `yaml:"maxRetries"`
+	RetryInterval       time.Duration `yaml:"retryInterval"`
+	DryRun              bool          `yaml:"dryRun"`
+	AutoRollbackEnabled bool          `yaml:"autoRollbackEnabled"`
+	AutoScaleEnabled    bool          `yaml:"autoScaleEnabled"`
+	CooldownSeconds     int           `yaml:"cooldownSeconds"`
+	// Deprecated: use Config.Namespaces; entries are migrated when the configuration is loaded
+	Namespaces         map[string]NamespaceRemediationConfig `yaml:"namespaces"`
+	FailureDomainAware bool                                  `yaml:"failureDomainAware"`
+	TopologyKey        string                                `yaml:"topologyKey"`
+	// MinSeverity is the minimum issue severity that is remediated automatically;
+	// issues below it are only notified. Empty remediates all severities.
+	MinSeverity string `yaml:"minSeverity"`
+	// ImpersonateRequester executes manually triggered actions as the requesting user
+	ImpersonateRequester bool `yaml:"impersonateRequester"`
+	// ImpersonateGroups are the groups of the requesting user that are
+	// impersonated with it; other groups are left out
+	ImpersonateGroups []string `yaml:"impersonateGroups"`
+	// Priority controls the order in which issues are remediated
+	Priority PriorityConfig `yaml:"priority"`
+	// Exclude lists workloads that are never remediated automatically; their
+	// issues are still detected and notified
+	Exclude []ExclusionConfig `yaml:"exclude"`
+	// ContainerRestartImage is the image of the ephemeral container the
+	// restart-container action adds to a pod; it must provide kill. Empty uses busybox.
+	ContainerRestartImage string `yaml:"containerRestartImage"`
+	// ContainerRestartTimeout bounds the wait for the restart count of the
+	// signaled container to go up; the pod is removed once it expires
+	ContainerRestartTimeout time.Duration `yaml:"containerRestartTimeout"`
+	// Executor selects how remediation actions are applied
+	Executor ExecutorConfig `yaml:"executor"`
+	// Batch replaces pod actions with one workload action when all pods of a Deployment fail
+	Batch BatchConfig `yaml:"batch"`
+	// RollingRestart paces the rolling-restart-pods action
+	RollingRestart RollingRestartConfig `yaml:"rollingRestart"`
+	// RolloutWatch follows the rollouts started by rollback and restart actions
+	RolloutWatch RolloutWatchConfig `yaml:"rolloutWatch"`
+	// PodRestart selects how the restart-pod action removes pods
+	PodRestart PodRestartConfig `yaml:"podRestart"`
+	// NodeDrain paces the cordon-drain-node action
+	NodeDrain NodeDrainConfig `yaml:"nodeDrain"`
+	// EvictedPodCleanup paces the cleanup-evicted-pods action
+	EvictedPodCleanup EvictedPodCleanupConfig `yaml:"evictedPodCleanup"`
+	// PreChecks configures the checks every action passes through before it is applied
+	PreChecks PreChecksConfig `yaml:"preChecks"`
+	// ActionDefaults sets the actions of rules that omit them by severity, and
+	// strips actions from every rule
+	ActionDefaults ActionDefaultsConfig `yaml:"actionDefaults"`
+	// Queue executes actions from a persistent work queue instead of during detection cycles
+	Queue RemediationQueueConfig `yaml:"queue"`
+	// IncidentMode bounds the incidents operators start through the API
+	IncidentMode IncidentModeConfig `yaml:"incidentMode"`
+	// PreRemediationHook announces disruptive actions to the pods they disrupt
+	PreRemediationHook PreRemediationHookConfig `yaml:"preRemediationHook"`
+	// Finalizers allow-lists the finalizers remove-finalizer may remove
+	Finalizers FinalizerRemovalConfig `yaml:"finalizers"`
+	// Plugins are remediation actions implemented by external programs
+	Plugins []PluginConfig `yaml:"plugins"`
+}
+
+// PluginConfig configures a remediation action implemented by an external
+// program, e.g. an organization's proprietary action. The program receives the
+// action and its resource as JSON on stdin and answers with JSON on stdout. It
+// runs with only Env as its environment, is killed with its children after
+// Timeout, and is limited to MaxMemoryMB of address space and MaxCPUTime.
+type PluginConfig struct {
+	// Name is the action rules refer to
+	Name string `yaml:"name"`
+	// Command is the absolute path of the program and its arguments
+	Command []string `yaml:"command"`
+	// Kinds are the resource kinds the action applies to; empty is any kind
+	Kinds          []string          `yaml:"kinds"`
+	Env            map[string]string `yaml:"env"`
+	Timeout        time.Duration     `yaml:"timeout"`
+	MaxOutputBytes int               `yaml:"maxOutputBytes"`
+	MaxMemoryMB    int               `yaml:"maxMemoryMB"`
+	MaxCPUTime     time.Duration     `yaml:"maxCPUTime"`
+}
+
+// FinalizerRemovalConfig configures the stuck-finalizer rule and the
+// remove-finalizer action. Only the finalizers listed in Allowed are ever
+// removed, from resources deleting for longer than StuckAfter; without entries
+// the rule is disabled.
+type FinalizerRemovalConfig struct {
+	StuckAfter time.Duration            `yaml:"stuckAfter"`
+	Allowed    []AllowedFinalizerConfig `yaml:"allowed"`
+}
+
+// AllowedFinalizerConfig opts a finalizer of some resource kinds in to removal.
+// In the approval mode, the default, detected issues only ask for approval and
+// the finalizer is removed when remove-finalizer is requested through the action
+// API; in the automatic mode it is removed when the issue is detected.
+type AllowedFinalizerConfig struct {
+	Name  string   `yaml:"name"`
+	Kinds []string `yaml:"kinds"`
+	Mode  string   `yaml:"mode"`
+}
+
+// PreRemediationHookConfig configures the handshake with applications before
+// restart-pod, restart-container and rolling-restart-pods: the pods are annotated,
+// then the action waits GracePeriod so applications watching their own metadata
+// can checkpoint or drain. Enabled is the default of namespaces without settings;
+// namespaces opt in or out with handshakeEnabled.
+type PreRemediationHookConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Annotation is set to "true" on the pods about to be disrupted
+	Annotation  string        `yaml:"annotation"`
+	GracePeriod time.Duration `yaml:"gracePeriod"`
+}
+
+// RemediationQueueConfig queues remediation actions between detection and
+// execution. Queued actions are persisted with the state backend and executed at
+// a limited rate by a background worker; failed actions are retried with an
+// exponential backoff, also across restarts, until MaxAttempts.
+type RemediationQueueConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// RatePerSecond is how many actions are started per second, with bursts of up to Burst
+	RatePerSecond int `yaml:"ratePerSecond"`
+	Burst         int `yaml:"burst"`
+	MaxAttempts   int `yaml:"maxAttempts"`
+	// InitialBackoff is the delay before the first retry; it doubles with each
+	// further attempt up to MaxBackoff
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
+	// PollInterval is how often the worker looks for due actions
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// IncidentModeConfig bounds incident mode, in which operators relax the
+// cooldowns and action budget of a namespace or set of rules for a while
+type IncidentModeConfig struct {
+	// MaxDuration is the longest an incident may relax remediation; zero is 4h
+	MaxDuration time.Duration `yaml:"maxDuration"`
+}
+
+// PodRestartConfig selects how the restart-pod action removes pods. Evictions
+// honor PodDisruptionBudgets; an eviction a budget keeps refusing is retried
+// until EvictionTimeout expires, then the pod is deleted if FallbackToDelete is set.
+type PodRestartConfig struct {
+	// Method is evict or delete
+	Method           string        `yaml:"method"`
+	EvictionTimeout  time.Duration `yaml:"evictionTimeout"`
+	RetryInterval    time.Duration `yaml:"retryInterval"`
+	FallbackToDelete bool          `yaml:"fallbackToDelete"`
+}
+
+// EvictedPodsConfig reports namespaces holding at least Threshold pods that
+// failed, e.g. were evicted under node pressure, at least MinAge ago. Failed pods
+// of Jobs are left to the Job. A zero threshold disables the rule.
+type EvictedPodsConfig struct {
+	Threshold int           `yaml:"threshold"`
+	MinAge    time.Duration `yaml:"minAge"`
+}
+
+// EvictedPodCleanupConfig paces the cleanup-evicted-pods action, which deletes
+// the failed pods of a namespace the evicted-pods rule reports, the earliest
+// failed first
+type EvictedPodCleanupConfig struct {
+	// MaxPodsPerCycle is how many pods may be deleted per detection cycle across
+	// all namespaces; the remaining pods are deleted in later cycles
+	MaxPodsPerCycle int `yaml:"maxPodsPerCycle"`
+}
+
+// PreChecksConfig configures the pre-checks remediation actions pass through:
+// policy, conflict, pdb, capacity and blast-radius. Actions overrides the
+// pre-checks of actions by name; the others keep their defaults. The policy
+// check always runs. MaxAffectedPods is the most pods the blast-radius check
+// lets an action disrupt; zero is unlimited.
+type PreChecksConfig struct {
+	Actions         map[string][]string `yaml:"actions"`
+	MaxAffectedPods int                 `yaml:"maxAffectedPods"`
+}
+
+// ActionDefaultsConfig sets cluster-wide action policy. BySeverity maps a
+// severity to the actions of custom rules and query rules of that severity that
+// omit actions; rules that can only notify, e.g. probe rules, keep none. Strip
+// lists actions the engine never executes, whichever rule or request names them.
+type ActionDefaultsConfig struct {
+	BySeverity map[string][]string `yaml:"bySeverity"`
+	Strip      []string            `yaml:"strip"`
+}
+
+// preCheckNames are the pre-checks remediation actions may be configured with
+var preCheckNames = []string{"policy", "conflict", "pdb", "capacity", "blast-radius"}
+
+// NodeDrainConfig paces the cordon-drain-node action, which cordons a node and
+// evicts its pods, honoring PodDisruptionBudgets. Evictions a budget refuses are
+// retried every podRestart retryInterval; the action fails if the pods are not
+// gone within Timeout, leaving the node cordoned.
+type NodeDrainConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+	// GracePeriod overrides the termination grace period of evicted pods; zero
+	// keeps their own
+	GracePeriod time.Duration `yaml:"gracePeriod"`
+	// MaxNodesPerHour is how many nodes may be drained per hour; further drains
+	// are skipped
+	MaxNodesPerHour int `yaml:"maxNodesPerHour"`
+}
+
+// RollingRestartConfig paces the rolling-restart-pods action, which deletes the
+// pods of a workload one at a time and waits for each replacement to be Ready
+type RollingRestartConfig struct {
+	// ReadyTimeout bounds the wait for each replacement; the action fails when it expires
+	ReadyTimeout time.Duration `yaml:"readyTimeout"`
+	// PollInterval is how often the readiness of the replacement is checked
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// RolloutWatchConfig configures following the Deployment rollout started by the
+// rollback-deployment and restart-deployment actions until it completes, fails
+// or Timeout expires, and notifying its final state. Zero durations use the
+// defaults of 10m and 10s.
+type RolloutWatchConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Timeout      time.Duration `yaml:"timeout"`
+	PollInterval time.Duration `yaml:"pollInterval"`
+}
+
+// BatchConfig collapses the pod issues of a rule into a single issue of their
+// Deployment when all of its pods are affected, so one workload-level action
+// replaces an action per pod
+type BatchConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Action is the Deployment action: restart-deployment, rolling-restart-pods or
+	// rollback-deployment
+	Action string `yaml:"action"`
+	// MinPods is the smallest number of affected pods that is batched
+	MinPods int `yaml:"minPods"`
+}
+
+// ExecutorConfig selects how remediation actions are applied. The client executor
+// applies them through the Kubernetes API; the record executor only logs them, and
+// the webhook executor posts them to another system that applies them.
+type ExecutorConfig struct {
+	// Type is client, record or webhook
+	Type string `yaml:"type"`
+	// URL receives the desired actions of the webhook executor
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Secret signs the desired actions posted by the webhook executor with
+	// HMAC-SHA256 in the X-KubeGuardian-Signature header
+	Secret string `yaml:"secret"`
+}
+
+// PriorityConfig controls remediation ordering when many issues fire at once.
+// Issues are ordered by namespace tier (lower first), then by workload PriorityClass
+// value (higher first), then by severity.
+type PriorityConfig struct {
+	NamespaceTiers     map[string]int `yaml:"namespaceTiers"`
+	DefaultTier        int            `yaml:"defaultTier"`
+	MaxActionsPerCycle int            `yaml:"maxActionsPerCycle"` // 0 means unlimited
+}
+
+// APIConfig contains settings for the management API used to trigger manual actions
+type APIConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	BindAddress string `yaml:"bindAddress"`
+	// TLSCertFile and TLSKeyFile serve the API over TLS instead of plain HTTP
+	TLSCertFile string `yaml:"tlsCertFile"`
+	TLSKeyFile  string `yaml:"tlsKeyFile"`
+	// Authentication sets how callers of the API are identified
+	Authentication APIAuthenticationConfig `yaml:"authentication"`
+}
+
+// Authentication modes of the API
+const (
+	// APIAuthKubernetes authenticates bearer tokens with a TokenReview
+	APIAuthKubernetes = "kubernetes"
+	// APIAuthRequestHeader trusts the X-Remote-User, X-Remote-Group and
+	// X-Remote-Extra-* headers of a front proxy presenting a client certificate
+	// signed by ClientCAFile
+	APIAuthRequestHeader = "requestHeader"
+)
+
+// APIAuthenticationConfig sets how callers of the API are identified. In every
+// mode requests are authorized with a SubjectAccessReview of their path.
+type APIAuthenticationConfig struct {
+	// Mode is kubernetes (default) or requestHeader
+	Mode string `yaml:"mode"`
+	// ClientCAFile verifies the client certificates of front proxies; the
+	// requestHeader mode requires it and TLS
+	ClientCAFile string `yaml:"clientCAFile"`
+	// AllowedNames are the common names of front proxy client certificates whose
+	// headers are trusted; empty trusts any certificate signed by ClientCAFile
+	AllowedNames []string `yaml:"allowedNames"`
 }
 
 // NotificationConfig contains notification settings
 type NotificationConfig struct {
 	Slack SlackConfig `yaml:"slack"`
+	// RepeatInterval is the minimum time between notifications for the same unresolved
+	// issue. Zero notifies on every evaluation cycle.
+	RepeatInterval time.Duration `yaml:"repeatInterval"`
+	// Queue buffers notifications and retries failed deliveries in the background
+	Queue NotificationQueueConfig `yaml:"queue"`
+	// Batch aggregates issue notifications during bursts
+	Batch NotificationBatchConfig `yaml:"batch"`
+	// Catalog resolves workloads to their owners for routing notifications
+	Catalog CatalogConfig `yaml:"catalog"`
+	// Lifecycle controls startup and shutdown notifications
+	Lifecycle LifecycleNotificationConfig `yaml:"lifecycle"`
+	// Digest sends scheduled summaries of the activity of KubeGuardian
+	Digest DigestConfig `yaml:"digest"`
+}
+
+// DigestConfig configures scheduled digests of issues detected and resolved,
+// escalations, the noisiest workloads and the estimated toil saved
+type DigestConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// ToilPerIssue is the manual effort an automatically resolved issue saves
+	ToilPerIssue time.Duration `yaml:"toilPerIssue"`
+	// TopWorkloads is the number of workloads with the most issues listed
+	TopWorkloads int `yaml:"topWorkloads"`
+	// SMTP is the mail server of email channels
+	SMTP SMTPConfig `yaml:"smtp"`
+	// Channels receive digests, each on its own schedule
+	Channels []DigestChannelConfig `yaml:"channels"`
+}
+
+// SMTPConfig configures the mail server digests are sent through
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// Username and Password authenticate with PLAIN auth if set
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// DigestChannelConfig configures a channel receiving digests. A digest covers the
+// activity since the previous digest of the channel, e.g. a day for a daily cron.
+type DigestChannelConfig struct {
+	Name string `yaml:"name"`
+	// Type is slack, email or webhook
+	Type string `yaml:"type"`
+	// Cron is when digests are sent, e.g. "0 9 * * *" for daily at 9:00
+	Cron string `yaml:"cron"`
+	// Timezone of the cron expression and the digest times; empty uses UTC
+	Timezone string `yaml:"timezone"`
+	// SlackChannel is the channel of slack digests; empty uses the default channel
+	SlackChannel string `yaml:"slackChannel"`
+	// To are the recipients of email digests
+	To []string `yaml:"to"`
+	// URL receives webhook digests as JSON
+	URL string `yaml:"url"`
+	// Usage adds the remediations, notifications and issues of each team in the
+	// month the digest period starts in, e.g. the previous month for "0 9 1 * *"
+	Usage bool `yaml:"usage"`
+}
+
+// LifecycleNotificationConfig controls notifications about KubeGuardian itself
+// starting and stopping. Start times are persisted with the state backend so a
+// controller in a crash loop does not notify on every restart.
+type LifecycleNotificationConfig struct {
+	// Shutdown sends a notification when KubeGuardian stops gracefully
+	Shutdown bool `yaml:"shutdown"`
+	// MinUptime suppresses the startup notification when the previous run stopped
+	// before running this long, and the shutdown notification of such a run; zero
+	// notifies on every start and stop
+	MinUptime time.Duration `yaml:"minUptime"`
+}
+
+// CatalogConfig configures lookups of workload owners in a service catalog
+type CatalogConfig struct {
+	// Backend is configmap or backstage; empty disables catalog lookups
+	Backend string `yaml:"backend"`
+	// TTL is how long lookups, including workloads without an entry, are cached
+	TTL time.Duration `yaml:"ttl"`
+	// ConfigMapName and ConfigMapNamespace locate the configmap backend
+	ConfigMapName      string `yaml:"configMapName"`
+	ConfigMapNamespace string `yaml:"configMapNamespace"`
+	// BackstageURL is the base URL of the Backstage backend
+	BackstageURL   string `yaml:"backstageURL"`
+	BackstageToken string `yaml:"backstageToken"`
+	// Timeout bounds a single Backstage request
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// NotificationBatchConfig controls aggregation of issue notifications. Issues are
+// collected per channel for Window; if at least MinIssues were collected a single
+// message with per-rule counts is sent instead of one message per issue.
+type NotificationBatchConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Window    time.Duration `yaml:"window"`
+	MinIssues int           `yaml:"minIssues"`
+	// DashboardURL is linked from aggregated messages
+	DashboardURL string `yaml:"dashboardURL"`
+}
+
+// NotificationQueueConfig controls asynchronous delivery of notifications
+type NotificationQueueConfig struct {
+	// Size is the number of buffered notifications; 0 sends synchronously
+	Size int `yaml:"size"`
+	// MaxRetries is the number of retries after a failed delivery before the
+	// notification is dropped and logged
+	MaxRetries int `yaml:"maxRetries"`
+	// InitialBackoff is doubled after each failed attempt up to MaxBackoff
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
 }
 
 // SlackConfig contains Slack-specific settings
@@ -332,59 +3029,175 @@ type SlackConfig struct {
 	Channel   string `yaml:"channel"`
 	Username  string `yaml:"username"`
 	IconEmoji string `yaml:"iconEmoji"`
+	// MinInterval is the minimum time between messages to the same channel;
+	// Slack allows about one message per second per channel
+	MinInterval time.Duration `yaml:"minInterval"`
+	// TeamChannels routes notifications about resources owned by a team to the
+	// team's channel instead of Channel. The team is read from the kubeguardian.io/team
+	// annotation, the team label or the app.kubernetes.io/part-of label.
+	TeamChannels map[string]string `yaml:"teamChannels"`
+	// SeverityChannels routes issue notifications of a severity, e.g. issues
+	// escalated to critical, to a channel instead of the team or default channel
+	SeverityChannels map[string]string `yaml:"severityChannels"`
+}
+
+// ScheduleWindows parses the configured schedules into time windows keyed by name
+func (c *Config) ScheduleWindows() (map[string]*schedule.Window, error) {
+	windows := make(map[string]*schedule.Window, len(c.Schedules))
+	for name, sc := range c.Schedules {
+		window, err := schedule.NewWindow(sc.Cron, sc.Timezone, sc.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", name, err)
+		}
+		windows[name] = window
+	}
+	return windows, nil
+}
+
+// OverrideBounds returns the bounds for workload annotation overrides, or nil if
+// overrides are disabled
+func (c *Config) OverrideBounds() *overrides.Bounds {
+	o := c.WorkloadOverrides
+	if !o.Enabled {
+		return nil
+	}
+	return &overrides.Bounds{
+		RestartLimit:     overrides.IntBounds{Min: o.RestartLimit.Min, Max: o.RestartLimit.Max},
+		OOMKillThreshold: overrides.IntBounds{Min: o.OOMKillThreshold.Min, Max: o.OOMKillThreshold.Max},
+		CheckDuration:    overrides.DurationBounds{Min: o.CheckDuration.Min, Max: o.CheckDuration.Max},
+		Cooldown:         overrides.DurationBounds{Min: o.Cooldown.Min, Max: o.Cooldown.Max},
+		OptOut:           o.OptOut,
+	}
+}
+
+// IsObserveMode returns true if KubeGuardian runs in read-only observe mode
+func (c *Config) IsObserveMode() bool {
+	return c.Mode == ModeObserve
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Mode: ModeEnforce,
 		Controller: ControllerConfig{
 			MetricsAddr:             ":8080",
 			ProbeAddr:               ":8081",
 			LeaderElection:          true,
 			SyncPeriod:              30 * time.Second,
 			MaxConcurrentReconciles: 1,
+			HTTP: HTTPConfig{
+				ReadHeaderTimeout: 5 * time.Second,
+				ReadTimeout:       30 * time.Second,
+				WriteTimeout:      2 * time.Minute,
+				IdleTimeout:       2 * time.Minute,
+			},
+			ShutdownTimeout: 30 * time.Second,
 		},
 		Detection: DetectionConfig{
-			RulesFile:                 "/etc/kubeguardian/rules.yaml",
-			EvaluationInterval:        30 * time.Second,
-			CrashLoopThreshold:        3,
-			FailedDeploymentThreshold: 5,
-			CPUThresholdPercent:       80.0,
-			MemoryThresholdPercent:    85.0,
-			OOMKillThreshold:          2,
-			Namespaces: map[string]NamespaceConfig{
-				"default": {
-					CrashLoop: CrashLoopConfig{
-						RestartLimit:  3,
-						CheckDuration: 5 * time.Minute,
-						Enabled:       true,
-					},
-					Deployment: DeploymentConfig{
-						FailureThreshold: 5,
-						CheckDuration:    10 * time.Minute,
-						Enabled:          true,
-					},
-					CPU: CPUConfig{
-						ThresholdPercent: 80.0,
-						CheckDuration:    5 * time.Minute,
-						Enabled:          true,
-					},
-					Memory: MemoryConfig{
-						ThresholdPercent: 85.0,
-						CheckDuration:    5 * time.Minute,
-						OOMKillThreshold: 2,
-						Enabled:          true,
-					},
-					Remediation: NamespaceRemediationConfig{
-						Enabled:             true,
-						AutoRollbackEnabled: true,
-						AutoScaleEnabled:    true,
-						MaxRetries:          3,
-						RetryInterval:       10 * time.Second,
-						CooldownSeconds:     300, // 5 minutes default cooldown
-					},
+			RulesFile:                    "/etc/kubeguardian/rules.yaml",
+			EvaluationInterval:           30 * time.Second,
+			CrashLoopThreshold:           3,
+			FailedDeploymentThreshold:    5,
+			CPUThresholdPercent:          80.0,
+			MemoryThresholdPercent:       85.0,
+			OOMKillThreshold:             2,
+			ReverifyInterval:             5 * time.Minute,
+			ChangeWindow:                 time.Hour,
+			EphemeralContainerMaxAge:     time.Hour,
+			StuckRolloutAfter:            15 * time.Minute,
+			StuckTerminatingAfter:        10 * time.Minute,
+			SchedulingConflictAfter:      10 * time.Minute,
+			ServiceWithoutEndpointsAfter: 5 * time.Minute,
+			ResourceQuota: ResourceQuotaConfig{
+				ThresholdPercent: 90,
+			},
+			Flapping: FlappingConfig{
+				RestartLimit: 5,
+				Window:       10 * time.Minute,
+			},
+			EvictedPods: EvictedPodsConfig{
+				Threshold: 20,
+				MinAge:    time.Hour,
+			},
+			State: StateConfig{
+				Backend:            "memory",
+				TTL:                5 * time.Minute,
+				ConfigMapName:      "kubeguardian-state",
+				ConfigMapNamespace: "kubeguardian",
+				Path:               "/var/lib/kubeguardian/state.json",
+			},
+			Escalation: EscalationConfig{
+				Enabled: false,
+				Steps: []EscalationStepConfig{
+					{After: time.Hour, Severity: "high"},
+					{After: 4 * time.Hour, Severity: "critical"},
+				},
+				Hysteresis: 15 * time.Minute,
+			},
+			Hygiene: HygieneConfig{
+				Enabled:           false,
+				Cron:              "0 9 * * 1",
+				Timezone:          "UTC",
+				ExcludeNamespaces: []string{"kube-*"},
+				MaxFindings:       20,
+			},
+			MetricsServer: MetricsServerConfig{
+				Enabled:       true,
+				RetryInterval: 5 * time.Minute,
+			},
+			Prometheus: PrometheusConfig{
+				Timeout: 10 * time.Second,
+			},
+			Watch: WatchConfig{
+				Enabled:    true,
+				Debounce:   2 * time.Second,
+				StaleAfter: 15 * time.Minute,
+			},
+			NodeProblems: NodeProblemsConfig{
+				Enabled: false,
+				Conditions: []NodeProblemConfig{
+					{Type: "KernelDeadlock", Severity: "critical", Actions: []string{"cordon-node"}},
+					{Type: "ReadonlyFilesystem", Severity: "critical", Actions: []string{"cordon-node"}},
+					{Type: "FrequentKubeletRestart", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, For: 10 * time.Minute},
+					{Type: "FrequentContainerdRestart", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, For: 10 * time.Minute},
+				},
+				Events: []NodeProblemConfig{
+					{Type: "KernelOops", Severity: "medium", Actions: []string{detection.NotifyOnlyAction}, For: time.Hour},
+					{Type: "TaskHung", Severity: "medium", Actions: []string{detection.NotifyOnlyAction}, For: time.Hour},
+				},
+			},
+			WarningEvents: WarningEventsConfig{
+				Enabled: false,
+				Reasons: []EventReasonConfig{
+					{Reason: "BackOff", Severity: "medium", Actions: []string{detection.NotifyOnlyAction}, MinCount: 5, Within: 10 * time.Minute},
+					{Reason: "FailedMount", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, MinCount: 3, Within: 10 * time.Minute},
+					{Reason: "FailedScheduling", Severity: "medium", Actions: []string{detection.NotifyOnlyAction}, MinCount: 3, Within: 10 * time.Minute},
+					{Reason: "Unhealthy", Severity: "medium", Actions: []string{detection.NotifyOnlyAction}, MinCount: 10, Within: 10 * time.Minute},
+					{Reason: "FailedCreatePodSandBox", Severity: "high", Actions: []string{"restart-pod"}, MinCount: 3, Within: 10 * time.Minute},
+				},
+			},
+			NodeConditions: NodeConditionsConfig{
+				Enabled: true,
+				Conditions: []NodeProblemConfig{
+					{Type: "Ready", Severity: "critical", Actions: []string{detection.NotifyOnlyAction}, For: 5 * time.Minute},
+					{Type: "DiskPressure", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, For: 5 * time.Minute},
+					{Type: "MemoryPressure", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, For: 5 * time.Minute},
+					{Type: "PIDPressure", Severity: "high", Actions: []string{detection.NotifyOnlyAction}, For: 5 * time.Minute},
 				},
 			},
+			ConfigDrift: ConfigDriftConfig{
+				Enabled:            false,
+				ConfigMapName:      "kubeguardian-config-hashes",
+				ConfigMapNamespace: "kubeguardian",
+				Ignore:             []string{"notification.slack.token"},
+				StaleAfter:         10 * time.Minute,
+				Severity:           "high",
+			},
+			DecisionLog: DecisionLogConfig{
+				Enabled:    false,
+				SampleRate: 0.1,
+				Size:       1000,
+			},
 		},
 		Remediation: RemediationConfig{
 			Enabled:             true,
@@ -394,15 +3207,192 @@ func DefaultConfig() *Config {
 			AutoRollbackEnabled: true,
 			AutoScaleEnabled:    true,
 			CooldownSeconds:     300, // 5 minutes default cooldown
+			FailureDomainAware:  true,
+			TopologyKey:         "topology.kubernetes.io/zone",
+			Priority: PriorityConfig{
+				DefaultTier: 2,
+			},
+			ContainerRestartImage:   "busybox:1.36",
+			ContainerRestartTimeout: time.Minute,
+			Executor: ExecutorConfig{
+				Type:    "client",
+				Timeout: 10 * time.Second,
+			},
+			Batch: BatchConfig{
+				Enabled: true,
+				Action:  "restart-deployment",
+				MinPods: 2,
+			},
+			RollingRestart: RollingRestartConfig{
+				ReadyTimeout: 5 * time.Minute,
+				PollInterval: 5 * time.Second,
+			},
+			RolloutWatch: RolloutWatchConfig{
+				Enabled:      true,
+				Timeout:      10 * time.Minute,
+				PollInterval: 10 * time.Second,
+			},
+			PodRestart: PodRestartConfig{
+				Method:           "evict",
+				EvictionTimeout:  2 * time.Minute,
+				RetryInterval:    10 * time.Second,
+				FallbackToDelete: true,
+			},
+			NodeDrain: NodeDrainConfig{
+				Timeout:         5 * time.Minute,
+				MaxNodesPerHour: 1,
+			},
+			EvictedPodCleanup: EvictedPodCleanupConfig{
+				MaxPodsPerCycle: 50,
+			},
+			PreRemediationHook: PreRemediationHookConfig{
+				Enabled:     false,
+				Annotation:  "kubeguardian.io/pending-restart",
+				GracePeriod: 30 * time.Second,
+			},
+			Finalizers: FinalizerRemovalConfig{
+				StuckAfter: 30 * time.Minute,
+			},
+			Queue: RemediationQueueConfig{
+				Enabled:        false,
+				RatePerSecond:  2,
+				Burst:          5,
+				MaxAttempts:    5,
+				InitialBackoff: 10 * time.Second,
+				MaxBackoff:     5 * time.Minute,
+				PollInterval:   time.Second,
+			},
+			IncidentMode: IncidentModeConfig{
+				MaxDuration: 4 * time.Hour,
+			},
 		},
 		Notification: NotificationConfig{
 			Slack: SlackConfig{
+				Enabled:     false,
+				Token:       "",
+				Channel:     "#kubeguardian",
+				Username:    "KubeGuardian",
+				IconEmoji:   ":robot_face:",
+				MinInterval: time.Second,
+			},
+			RepeatInterval: time.Hour,
+			Queue: NotificationQueueConfig{
+				Size:           100,
+				MaxRetries:     5,
+				InitialBackoff: time.Second,
+				MaxBackoff:     time.Minute,
+			},
+			Batch: NotificationBatchConfig{
 				Enabled:   false,
-				Token:     "",
-				Channel:   "#kubeguardian",
-				Username:  "KubeGuardian",
-				IconEmoji: ":robot_face:",
+				Window:    30 * time.Second,
+				MinIssues: 5,
+			},
+			Catalog: CatalogConfig{
+				TTL:                10 * time.Minute,
+				ConfigMapName:      "kubeguardian-catalog",
+				ConfigMapNamespace: "kubeguardian",
+				Timeout:            5 * time.Second,
+			},
+			Lifecycle: LifecycleNotificationConfig{
+				Shutdown:  true,
+				MinUptime: 5 * time.Minute,
+			},
+			Digest: DigestConfig{
+				Enabled:      false,
+				ToilPerIssue: 15 * time.Minute,
+				TopWorkloads: 5,
+				SMTP: SMTPConfig{
+					Port: 587,
+				},
+			},
+		},
+		API: APIConfig{
+			Enabled:        false,
+			BindAddress:    ":8082",
+			Authentication: APIAuthenticationConfig{Mode: APIAuthKubernetes},
+		},
+		Analysis: AnalysisConfig{
+			Heuristics: HeuristicsConfig{
+				Enabled: true,
+			},
+			MaxTokens:   400,
+			Timeout:     30 * time.Second,
+			MinSeverity: "high",
+			MaxEvents:   10,
+			LogLines:    50,
+		},
+		Runtime: RuntimeConfig{
+			AutoMaxProcs:        true,
+			MemoryLimitRatio:    0.9,
+			APIQPS:              20,
+			APIBurst:            30,
+			MaxInFlightRequests: 32,
+			APITimeouts: APITimeoutsConfig{
+				List:   30 * time.Second,
+				Mutate: 15 * time.Second,
 			},
+			Batching: BatchingConfig{
+				Enabled:     true,
+				TargetRatio: 0.5,
+				MinPageSize: 100,
+				MaxPageSize: 5000,
+				MaxWorkers:  4,
+			},
+		},
+		SecretProviders: SecretProvidersConfig{
+			RefreshInterval: secrets.DefaultRefreshInterval,
+			Vault: VaultSecretProviderConfig{
+				AuthMethod: secrets.VaultAuthKubernetes,
+				AuthPath:   "kubernetes",
+			},
+		},
+		Export: ExportConfig{
+			IndexPrefix:   "kubeguardian",
+			BatchSize:     100,
+			FlushInterval: 10 * time.Second,
+			BufferSize:    1000,
+			Timeout:       10 * time.Second,
+		},
+		Namespaces: map[string]NamespaceConfig{
+			"default": {
+				CrashLoop: CrashLoopConfig{
+					RestartLimit:  3,
+					CheckDuration: 5 * time.Minute,
+					Enabled:       true,
+				},
+				Deployment: DeploymentConfig{
+					FailureThreshold: 5,
+					CheckDuration:    10 * time.Minute,
+					Enabled:          true,
+				},
+				CPU: CPUConfig{
+					ThresholdPercent: 80.0,
+					CheckDuration:    5 * time.Minute,
+					Enabled:          true,
+				},
+				Memory: MemoryConfig{
+					ThresholdPercent: 85.0,
+					CheckDuration:    5 * time.Minute,
+					OOMKillThreshold: 2,
+					Enabled:          true,
+				},
+				Remediation: NamespaceRemediationConfig{
+					Enabled:             true,
+					AutoRollbackEnabled: true,
+					AutoScaleEnabled:    true,
+					MaxRetries:          3,
+					RetryInterval:       10 * time.Second,
+					CooldownSeconds:     300, // 5 minutes default cooldown
+				},
+			},
+		},
+		WorkloadOverrides: WorkloadOverridesConfig{
+			Enabled:          true,
+			RestartLimit:     IntRange{Min: 1, Max: 50},
+			OOMKillThreshold: IntRange{Min: 1, Max: 20},
+			CheckDuration:    DurationRange{Max: time.Hour},
+			Cooldown:         DurationRange{Min: time.Minute, Max: time.Hour},
+			OptOut:           true,
 		},
 	}
 }
@@ -415,6 +3405,11 @@ func LoadConfig(configPath string) (*Config, error) {
 		return config, nil
 	}
 
+	// Default namespace entries are added after migrating the deprecated namespace
+	// maps so that legacy entries for the same namespaces are not shadowed
+	defaultNamespaces := config.Namespaces
+	config.Namespaces = nil
+
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -438,5 +3433,12 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
+	config.MigrateNamespaces()
+	for namespace, nsConfig := range defaultNamespaces {
+		if _, exists := config.Namespaces[namespace]; !exists {
+			config.Namespaces[namespace] = nsConfig
+		}
+	}
+
 	return config, nil
 }
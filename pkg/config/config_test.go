@@ -1,8 +1,16 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
 )
 
 func TestConfigValidation(t *testing.T) {
@@ -252,3 +260,1217 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("default max retries = %v, want %v", config.Remediation.MaxRetries, 3)
 	}
 }
+
+func TestModeValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		wantErr bool
+	}{
+		{"empty mode defaults to enforce", "", false},
+		{"enforce mode", ModeEnforce, false},
+		{"observe mode", ModeObserve, false},
+		{"unknown mode", "readonly", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Mode = tt.mode
+
+			result := config.Validate()
+			if tt.wantErr && result.Valid {
+				t.Errorf("expected validation errors for mode %q but got none", tt.mode)
+			}
+			if !tt.wantErr && !result.Valid {
+				t.Errorf("unexpected validation errors for mode %q: %v", tt.mode, result.Errors)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	if config.IsObserveMode() {
+		t.Error("default config should not be in observe mode")
+	}
+	config.Mode = ModeObserve
+	if !config.IsObserveMode() {
+		t.Error("IsObserveMode() = false, want true")
+	}
+}
+
+func TestAPIValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.API.Enabled = true
+	config.API.BindAddress = ""
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for enabled API without bind address")
+	}
+
+	config = DefaultConfig()
+	config.Remediation.ImpersonateRequester = true
+	result := config.Validate()
+	if !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("expected warning for impersonation with API disabled")
+	}
+
+	// Identity headers are only trusted from a verified front proxy
+	config = DefaultConfig()
+	config.API.Authentication.Mode = APIAuthRequestHeader
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for requestHeader authentication without TLS and client CA")
+	}
+	config.API.TLSCertFile = "/etc/kubeguardian/tls/tls.crt"
+	config.API.TLSKeyFile = "/etc/kubeguardian/tls/tls.key"
+	config.API.Authentication.ClientCAFile = "/etc/kubeguardian/tls/front-proxy-ca.crt"
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+
+	config.API.Authentication.Mode = "none"
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for unknown authentication mode")
+	}
+}
+
+func TestRemediationSeverityFloor(t *testing.T) {
+	config := DefaultConfig()
+	config.Remediation.MinSeverity = "high"
+	config.Remediation.Namespaces = map[string]NamespaceRemediationConfig{
+		"staging": {MinSeverity: "critical"},
+		"dev":     {MinSeverity: "low"},
+	}
+
+	tests := []struct {
+		namespace string
+		severity  string
+		want      bool
+	}{
+		{"default", "critical", true},
+		{"default", "high", true},
+		{"default", "medium", false},
+		{"default", "low", false},
+		{"default", "unknown", false},
+		{"staging", "high", false},
+		{"staging", "critical", true},
+		{"dev", "low", true},
+	}
+
+	for _, tt := range tests {
+		if got := config.MeetsRemediationSeverity(tt.namespace, tt.severity); got != tt.want {
+			t.Errorf("MeetsRemediationSeverity(%q, %q) = %v, want %v", tt.namespace, tt.severity, got, tt.want)
+		}
+	}
+
+	config.Remediation.MinSeverity = ""
+	if !config.MeetsRemediationSeverity("default", "low") {
+		t.Error("empty severity floor should allow all severities")
+	}
+
+	config.Remediation.MinSeverity = "urgent"
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for unknown minSeverity")
+	}
+}
+
+func TestNotificationRepeatIntervalValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.Notification.RepeatInterval = -time.Minute
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for negative repeat interval")
+	}
+
+	config.Notification.RepeatInterval = 0
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("zero repeat interval should be valid: %v", result.Errors)
+	}
+}
+
+func TestNamespaceTier(t *testing.T) {
+	config := DefaultConfig()
+	config.Remediation.Priority.NamespaceTiers = map[string]int{"production": 0}
+
+	if tier := config.NamespaceTier("production"); tier != 0 {
+		t.Errorf("NamespaceTier(production) = %d, want 0", tier)
+	}
+	if tier := config.NamespaceTier("dev"); tier != config.Remediation.Priority.DefaultTier {
+		t.Errorf("NamespaceTier(dev) = %d, want default tier %d", tier, config.Remediation.Priority.DefaultTier)
+	}
+
+	config.Remediation.Priority.NamespaceTiers["Invalid_NS"] = 1
+	config.Remediation.Priority.MaxActionsPerCycle = -1
+	if result := config.Validate(); len(result.Errors) < 2 {
+		t.Errorf("expected errors for invalid namespace tier and negative budget, got %v", result.Errors)
+	}
+}
+
+func TestScheduleValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.Schedules = map[string]ScheduleConfig{
+		"nightly-maintenance": {Cron: "0 2 * * *", Timezone: "Europe/Berlin", Duration: 2 * time.Hour},
+		"business-hours":      {Cron: "CRON_TZ=America/New_York 0 9 * * mon-fri", Duration: 8 * time.Hour},
+	}
+
+	if result := config.Validate(); !result.Valid {
+		t.Fatalf("unexpected validation errors: %v", result.Errors)
+	}
+
+	windows, err := config.ScheduleWindows()
+	if err != nil {
+		t.Fatalf("ScheduleWindows() error = %v", err)
+	}
+	if len(windows) != 2 {
+		t.Errorf("expected 2 windows, got %d", len(windows))
+	}
+
+	config.Schedules["broken"] = ScheduleConfig{Cron: "0 25 * * *"}
+	config.Schedules["bad-zone"] = ScheduleConfig{Cron: "@daily", Timezone: "Nowhere/City"}
+	if result := config.Validate(); len(result.Errors) != 2 {
+		t.Errorf("expected 2 schedule errors, got %v", result.Errors)
+	}
+}
+
+func TestStateValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*StateConfig)
+		valid  bool
+	}{
+		{"default memory backend", func(s *StateConfig) {}, true},
+		{"configmap backend", func(s *StateConfig) { s.Backend = "configmap" }, true},
+		{"configmap without name", func(s *StateConfig) { s.Backend = "configmap"; s.ConfigMapName = "" }, false},
+		{"file without path", func(s *StateConfig) { s.Backend = "file"; s.Path = "" }, false},
+		{"unknown backend", func(s *StateConfig) { s.Backend = "redis" }, false},
+		{"negative ttl", func(s *StateConfig) { s.TTL = -time.Minute }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config.Detection.State)
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestWorkloadOverridesValidation(t *testing.T) {
+	config := DefaultConfig()
+	if bounds := config.OverrideBounds(); bounds == nil || bounds.Cooldown.Max != time.Hour {
+		t.Fatalf("unexpected default override bounds: %+v", bounds)
+	}
+
+	config.WorkloadOverrides.RestartLimit = IntRange{Min: 10, Max: 5}
+	config.WorkloadOverrides.Cooldown.Max = 2 * time.Hour
+	if result := config.Validate(); len(result.Errors) != 2 {
+		t.Errorf("expected 2 errors, got %v", result.Errors)
+	}
+
+	config.WorkloadOverrides.Enabled = false
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("bounds should not be validated when overrides are disabled: %v", result.Errors)
+	}
+	if config.OverrideBounds() != nil {
+		t.Error("expected nil bounds when overrides are disabled")
+	}
+}
+
+func TestNamespaceSelectors(t *testing.T) {
+	data := `
+namespaceSelectors:
+  - names: ["team-*"]
+    matchLabels:
+      tier: prod
+    crashloop:
+      restartLimit: 10
+      checkDuration: 2m
+      enabled: true
+    deployment:
+      failureThreshold: 3
+      checkDuration: 5m
+      enabled: true
+    memory:
+      oomKillThreshold: 2
+      checkDuration: 5m
+    remediation:
+      enabled: true
+      minSeverity: critical
+      retryInterval: 10s
+  - names: ["team-*", "shared-?"]
+    crashloop:
+      restartLimit: 5
+      checkDuration: 2m
+    deployment:
+      failureThreshold: 3
+      checkDuration: 5m
+    memory:
+      oomKillThreshold: 2
+      checkDuration: 5m
+    remediation:
+      retryInterval: 10s
+`
+	config := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		t.Fatalf("failed to parse selectors: %v", err)
+	}
+	if result := config.Validate(); !result.Valid {
+		t.Fatalf("unexpected validation errors: %v", result.Errors)
+	}
+
+	config.Remediation.Namespaces = map[string]NamespaceRemediationConfig{"team-explicit": {MinSeverity: "low"}}
+	expanded := config.ExpandNamespaceSelectors(map[string]map[string]string{
+		"team-payments": {"tier": "prod"},
+		"team-sandbox":  {},
+		"team-explicit": {"tier": "prod"},
+		"shared-1":      nil,
+		"kube-system":   nil,
+	})
+
+	tests := []struct {
+		namespace    string
+		restartLimit int
+		minSeverity  string
+	}{
+		{"team-payments", 10, "critical"}, // First matching selector wins
+		{"team-sandbox", 5, ""},
+		{"team-explicit", 3, "low"}, // Explicit entries take precedence, unset parts use the defaults
+		{"shared-1", 5, ""},
+	}
+	for _, tt := range tests {
+		if got := expanded.Namespaces[tt.namespace].CrashLoop.RestartLimit; got != tt.restartLimit {
+			t.Errorf("%s: restart limit = %d, want %d", tt.namespace, got, tt.restartLimit)
+		}
+		if got := expanded.RemediationSeverityFloor(tt.namespace); got != tt.minSeverity {
+			t.Errorf("%s: severity floor = %q, want %q", tt.namespace, got, tt.minSeverity)
+		}
+	}
+	if _, exists := expanded.Namespaces["kube-system"]; exists {
+		t.Error("kube-system should not match any selector")
+	}
+	if len(config.Remediation.Namespaces) != 1 {
+		t.Error("expanding selectors must not modify the original configuration")
+	}
+
+	config.NamespaceSelectors = append(config.NamespaceSelectors, NamespaceSelectorConfig{}, NamespaceSelectorConfig{Names: []string{"team-["}})
+	if result := config.Validate(); len(result.Errors) < 2 {
+		t.Errorf("expected errors for empty selector and invalid pattern, got %v", result.Errors)
+	}
+}
+
+func TestNamespaceMigration(t *testing.T) {
+	data := `
+detection:
+  namespaces:
+    default:
+      crashloop:
+        restartLimit: 7
+        enabled: true
+      deployment:
+        failureThreshold: 5
+      memory:
+        oomKillThreshold: 2
+    prod:
+      crashloop:
+        restartLimit: 2
+        checkDuration: 3m
+        enabled: true
+      deployment:
+        failureThreshold: 3
+      memory:
+        oomKillThreshold: 1
+remediation:
+  namespaces:
+    prod:
+      enabled: true
+      retryInterval: 10s
+      cooldownSeconds: 600
+    dev:
+      enabled: true
+      retryInterval: 10s
+      minSeverity: low
+`
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if config.Detection.Namespaces != nil || config.Remediation.Namespaces != nil {
+		t.Error("legacy namespace maps should be cleared after migration")
+	}
+	if got := config.Namespaces["default"].CrashLoop.RestartLimit; got != 7 {
+		t.Errorf("default: restart limit = %d, want 7", got)
+	}
+
+	prod := config.Namespaces["prod"]
+	if prod.CrashLoop.RestartLimit != 2 || prod.Remediation.CooldownSeconds != 600 {
+		t.Errorf("prod: detection and remediation entries should be merged, got %+v", prod)
+	}
+
+	dev := config.Namespaces["dev"]
+	if dev.CrashLoop.RestartLimit != config.Detection.CrashLoopThreshold {
+		t.Errorf("dev: restart limit = %d, want the global default", dev.CrashLoop.RestartLimit)
+	}
+	if got := config.RemediationSeverityFloor("dev"); got != "low" {
+		t.Errorf("dev: severity floor = %q, want low", got)
+	}
+}
+
+func TestLegacyNamespaceConflicts(t *testing.T) {
+	config := DefaultConfig()
+	prod := config.DefaultNamespaceConfig()
+	prod.Remediation.CooldownSeconds = 600
+	config.Detection.Namespaces = map[string]NamespaceConfig{"prod": prod}
+	config.Remediation.Namespaces = map[string]NamespaceRemediationConfig{
+		"prod": {Enabled: true, RetryInterval: 10 * time.Second, CooldownSeconds: 120},
+	}
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for conflicting legacy namespace settings")
+	}
+
+	config.Remediation.Namespaces["prod"] = config.Detection.Namespaces["prod"].Remediation
+	result := config.Validate()
+	if !result.Valid {
+		t.Errorf("unexpected validation errors for matching legacy settings: %v", result.Errors)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("expected deprecation warning for legacy namespace maps")
+	}
+
+	config.Namespaces["prod"] = config.DefaultNamespaceConfig()
+	config.Remediation.Namespaces["prod"] = NamespaceRemediationConfig{CooldownSeconds: 60}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("legacy settings of namespaces configured in namespaces should be ignored, got %v", result.Errors)
+	}
+	if policy, _ := config.NamespacePolicy("prod"); policy.Remediation.CooldownSeconds != config.Remediation.CooldownSeconds {
+		t.Errorf("namespaces entry should take precedence, got cooldown %d", policy.Remediation.CooldownSeconds)
+	}
+}
+
+func TestEffectiveNamespace(t *testing.T) {
+	config := DefaultConfig()
+	config.Remediation.MinSeverity = "medium"
+	config.Namespaces["payments"] = NamespaceConfig{
+		CrashLoop:   CrashLoopConfig{RestartLimit: 2, CheckDuration: 3 * time.Minute, Enabled: true},
+		Remediation: NamespaceRemediationConfig{Enabled: true, CooldownSeconds: 600, MinSeverity: "high"},
+	}
+	team := config.DefaultNamespaceConfig()
+	team.CrashLoop.RestartLimit = 8
+	config.NamespaceSelectors = []NamespaceSelectorConfig{
+		{Names: []string{"team-*"}, NamespaceConfig: team},
+	}
+
+	tests := []struct {
+		namespace    string
+		source       string
+		restartLimit int
+		minSeverity  string
+	}{
+		{"payments", "namespaces.payments", 2, "high"},
+		{"team-a", "namespaceSelectors[0]", 8, "medium"},
+		{"other", "defaults", config.Detection.CrashLoopThreshold, "medium"},
+	}
+	for _, tt := range tests {
+		effective := config.EffectiveNamespace(tt.namespace, nil)
+		if effective.Source != tt.source {
+			t.Errorf("%s: source = %q, want %q", tt.namespace, effective.Source, tt.source)
+		}
+		if effective.Settings.CrashLoop.RestartLimit != tt.restartLimit {
+			t.Errorf("%s: restart limit = %d, want %d", tt.namespace, effective.Settings.CrashLoop.RestartLimit, tt.restartLimit)
+		}
+		if effective.MinSeverity != tt.minSeverity {
+			t.Errorf("%s: min severity = %q, want %q", tt.namespace, effective.MinSeverity, tt.minSeverity)
+		}
+	}
+
+	effective := config.EffectiveNamespace("payments", nil)
+	errs := effective.ApplyWorkloadOverrides(config.OverrideBounds(), "deployment/api", map[string]string{
+		overrides.AnnotationCrashLoopRestartLimit: "100",
+		overrides.AnnotationCooldown:              "15m",
+		overrides.AnnotationOOMKillThreshold:      "many",
+	})
+	if len(errs) != 1 {
+		t.Errorf("expected one error for the invalid annotation, got %v", errs)
+	}
+	if effective.Settings.CrashLoop.RestartLimit != 50 {
+		t.Errorf("restart limit = %d, want 50 (clamped)", effective.Settings.CrashLoop.RestartLimit)
+	}
+	if effective.Settings.Remediation.CooldownSeconds != 900 {
+		t.Errorf("cooldown = %ds, want 900s", effective.Settings.Remediation.CooldownSeconds)
+	}
+	if len(effective.Overrides) != 2 || effective.Overrides[overrides.AnnotationCrashLoopRestartLimit] != "50" {
+		t.Errorf("unexpected recorded overrides: %v", effective.Overrides)
+	}
+
+	effective = config.EffectiveNamespace("payments", nil)
+	effective.ApplyWorkloadOverrides(config.OverrideBounds(), "deployment/api", map[string]string{
+		overrides.AnnotationDisable: "true",
+		overrides.AnnotationActions: "restart-pod, rollback-deployment",
+	})
+	if effective.Overrides[overrides.AnnotationDisable] != "true" || effective.Overrides[overrides.AnnotationActions] != "restart-pod,rollback-deployment" {
+		t.Errorf("unexpected recorded opt-out overrides: %v", effective.Overrides)
+	}
+}
+
+func TestFeatureValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.Features = map[string]bool{"nodeActions": true}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+
+	config.Features["teleport"] = true
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for unknown feature flag")
+	}
+}
+
+func TestAnalysisValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.Analysis.Heuristics.Runbooks = map[string]string{"missing-secret": "https://wiki.example.com/runbooks/secrets"}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+
+	config.Analysis.Heuristics.Runbooks["gremlins"] = "https://wiki.example.com/runbooks/gremlins"
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for unknown failure signature")
+	}
+	delete(config.Analysis.Heuristics.Runbooks, "gremlins")
+
+	// The aiAnalysis feature needs an endpoint
+	config.Features = map[string]bool{"aiAnalysis": true}
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for aiAnalysis without an endpoint")
+	}
+	config.Analysis.Endpoint = "https://llm.example.com/v1/chat/completions"
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+}
+
+func TestExclusionValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		exclusion ExclusionConfig
+		valid     bool
+	}{
+		{"name", ExclusionConfig{Name: "legacy-batch"}, true},
+		{"pattern with kind", ExclusionConfig{Kind: "Deployment", Pattern: ".*-canary"}, true},
+		{"name and pattern", ExclusionConfig{Name: "web", Pattern: "web-.*"}, false},
+		{"neither", ExclusionConfig{Namespace: "default"}, false},
+		{"invalid pattern", ExclusionConfig{Pattern: "web-("}, false},
+		{"unsupported kind", ExclusionConfig{Kind: "CronJob", Name: "nightly"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.Exclude = []ExclusionConfig{tt.exclusion}
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestContainerRuleValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		container ContainerRuleConfig
+		valid     bool
+	}{
+		{"notify only", ContainerRuleConfig{Name: "istio-proxy", NotifyOnly: true}, true},
+		{"actions by pattern", ContainerRuleConfig{Pattern: "app|web", Actions: []string{"restart-pod"}}, true},
+		{"neither name nor pattern", ContainerRuleConfig{Ignore: true}, false},
+		{"invalid pattern", ContainerRuleConfig{Pattern: "app("}, false},
+		{"conflicting options", ContainerRuleConfig{Name: "app", Ignore: true, Actions: []string{"restart-pod"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Containers = []ContainerRuleConfig{tt.container}
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestExecutorValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor ExecutorConfig
+		valid    bool
+	}{
+		{"client", ExecutorConfig{Type: "client"}, true},
+		{"record", ExecutorConfig{Type: "record"}, true},
+		{"webhook", ExecutorConfig{Type: "webhook", URL: "https://gitops.example.com/actions", Timeout: time.Second}, true},
+		{"webhook without URL", ExecutorConfig{Type: "webhook", Timeout: time.Second}, false},
+		{"webhook without timeout", ExecutorConfig{Type: "webhook", URL: "https://gitops.example.com/actions"}, false},
+		{"unknown type", ExecutorConfig{Type: "queue"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.Executor = tt.executor
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestPreChecksValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		preChecks PreChecksConfig
+		valid     bool
+	}{
+		{"default", DefaultConfig().Remediation.PreChecks, true},
+		{"per action", PreChecksConfig{Actions: map[string][]string{"restart-pod": {"pdb", "blast-radius"}}, MaxAffectedPods: 20}, true},
+		{"no checks besides policy", PreChecksConfig{Actions: map[string][]string{"scale-replicas": {}}}, true},
+		{"unknown check", PreChecksConfig{Actions: map[string][]string{"restart-pod": {"quota"}}}, false},
+		{"negative maxAffectedPods", PreChecksConfig{MaxAffectedPods: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.PreChecks = tt.preChecks
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestResourceQuotaValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    float64
+		namespace float64
+		valid     bool
+	}{
+		{"default", DefaultConfig().Detection.ResourceQuota.ThresholdPercent, 0, true},
+		{"disabled", 0, 0, true},
+		{"namespace override", 90, 75, true},
+		{"global above 100", 120, 0, false},
+		{"negative namespace override", 90, -5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.ResourceQuota.ThresholdPercent = tt.global
+			ns := config.Namespaces["default"]
+			ns.ResourceQuota.ThresholdPercent = tt.namespace
+			config.Namespaces["default"] = ns
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestFlappingValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    FlappingConfig
+		namespace FlappingConfig
+		valid     bool
+	}{
+		{"default", DefaultConfig().Detection.Flapping, FlappingConfig{}, true},
+		{"disabled", FlappingConfig{}, FlappingConfig{}, true},
+		{"namespace override", FlappingConfig{RestartLimit: 5, Window: 10 * time.Minute}, FlappingConfig{Window: time.Hour}, true},
+		{"negative limit", FlappingConfig{RestartLimit: -1, Window: 10 * time.Minute}, FlappingConfig{}, false},
+		{"negative namespace window", FlappingConfig{RestartLimit: 5, Window: 10 * time.Minute}, FlappingConfig{Window: -time.Minute}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Flapping = tt.global
+			ns := config.Namespaces["default"]
+			ns.Flapping = tt.namespace
+			config.Namespaces["default"] = ns
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestPreRemediationHookValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		hook  PreRemediationHookConfig
+		valid bool
+	}{
+		{"default", DefaultConfig().Remediation.PreRemediationHook, true},
+		{"unprefixed annotation", PreRemediationHookConfig{Enabled: true, Annotation: "pending-restart", GracePeriod: time.Minute}, true},
+		{"invalid annotation", PreRemediationHookConfig{Enabled: true, Annotation: "kubeguardian.io/pending restart", GracePeriod: time.Minute}, false},
+		{"invalid annotation prefix", PreRemediationHookConfig{Enabled: true, Annotation: "KubeGuardian/pending-restart", GracePeriod: time.Minute}, false},
+		{"negative grace period", PreRemediationHookConfig{Enabled: true, GracePeriod: -time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.PreRemediationHook = tt.hook
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.Remediation.PreRemediationHook.Enabled = true
+	if !config.DefaultNamespaceConfig().Remediation.HandshakeEnabled {
+		t.Error("expected namespaces without settings to use the handshake when it is enabled")
+	}
+}
+
+func TestNodeProblemValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []NodeProblemConfig
+		valid      bool
+	}{
+		{"default", DefaultConfig().Detection.NodeProblems.Conditions, true},
+		{"without type", []NodeProblemConfig{{Severity: "high"}}, false},
+		{"duplicate type", []NodeProblemConfig{{Type: "KernelDeadlock"}, {Type: "KernelDeadlock"}}, false},
+		{"invalid severity", []NodeProblemConfig{{Type: "KernelDeadlock", Severity: "urgent"}}, false},
+		{"unknown action", []NodeProblemConfig{{Type: "KernelDeadlock", Actions: []string{"reboot-node"}}}, false},
+		{"negative duration", []NodeProblemConfig{{Type: "KernelDeadlock", For: -time.Minute}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.NodeProblems.Enabled = true
+			config.Detection.NodeProblems.Conditions = tt.conditions
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	// Node conditions are validated alike
+	config := DefaultConfig()
+	config.Detection.NodeConditions.Conditions = append(config.Detection.NodeConditions.Conditions, NodeProblemConfig{Type: "Ready"})
+	if result := config.Validate(); result.Valid {
+		t.Error("Valid = true, want false for a duplicate node condition")
+	}
+}
+
+func TestWarningEventValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		reasons []EventReasonConfig
+		valid   bool
+	}{
+		{"default", DefaultConfig().Detection.WarningEvents.Reasons, true},
+		{"without reason", []EventReasonConfig{{Severity: "high"}}, false},
+		{"duplicate reason", []EventReasonConfig{{Reason: "BackOff"}, {Reason: "BackOff"}}, false},
+		{"invalid severity", []EventReasonConfig{{Reason: "BackOff", Severity: "urgent"}}, false},
+		{"unknown action", []EventReasonConfig{{Reason: "BackOff", Actions: []string{"reboot-node"}}}, false},
+		{"negative count", []EventReasonConfig{{Reason: "BackOff", MinCount: -1}}, false},
+		{"negative window", []EventReasonConfig{{Reason: "BackOff", Within: -time.Minute}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.WarningEvents.Enabled = true
+			config.Detection.WarningEvents.Reasons = tt.reasons
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestActionDefaultsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults ActionDefaultsConfig
+		valid    bool
+	}{
+		{"empty", ActionDefaultsConfig{}, true},
+		{"valid", ActionDefaultsConfig{BySeverity: map[string][]string{"critical": {"restart-pod"}, "low": {"notify-only"}}, Strip: []string{"scale-replicas"}}, true},
+		{"invalid severity", ActionDefaultsConfig{BySeverity: map[string][]string{"urgent": {"restart-pod"}}}, false},
+		{"unknown action", ActionDefaultsConfig{BySeverity: map[string][]string{"high": {"reboot-node"}}}, false},
+		{"notify-only with actions", ActionDefaultsConfig{BySeverity: map[string][]string{"high": {"notify-only", "restart-pod"}}}, false},
+		{"unknown stripped action", ActionDefaultsConfig{Strip: []string{"reboot-node"}}, false},
+		{"stripped notify-only", ActionDefaultsConfig{Strip: []string{"notify-only"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.ActionDefaults = tt.defaults
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	// A default action that is also stripped is never executed
+	config := DefaultConfig()
+	config.Remediation.ActionDefaults = ActionDefaultsConfig{BySeverity: map[string][]string{"high": {"scale-replicas"}}, Strip: []string{"scale-replicas"}}
+	if result := config.Validate(); !result.Valid || !slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "is stripped") }) {
+		t.Errorf("Validate() = %+v, want a warning about the stripped default action", result)
+	}
+}
+
+func TestHealthCheckValidation(t *testing.T) {
+	check := HealthCheckConfig{Name: "search", URL: "http://search.default.svc:9200/_cluster/health", Tags: []string{"critical"}}
+	tests := []struct {
+		name   string
+		modify func(*HealthCheckConfig)
+		valid  bool
+	}{
+		{"valid", func(*HealthCheckConfig) {}, true},
+		{"without name", func(c *HealthCheckConfig) { c.Name = "" }, false},
+		{"built-in name", func(c *HealthCheckConfig) { c.Name = "kubernetes-api" }, false},
+		{"invalid URL", func(c *HealthCheckConfig) { c.URL = "search:9200" }, false},
+		{"negative timeout", func(c *HealthCheckConfig) { c.Timeout = -time.Second }, false},
+		{"invalid status", func(c *HealthCheckConfig) { c.ExpectedStatus = 42 }, false},
+		{"empty tag", func(c *HealthCheckConfig) { c.Tags = []string{""} }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			configured := check
+			tt.modify(&configured)
+			config.Controller.HealthChecks = []HealthCheckConfig{configured}
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.Controller.HealthChecks = []HealthCheckConfig{check, check}
+	if result := config.Validate(); result.Valid {
+		t.Error("Valid = true, want false for duplicate health checks")
+	}
+}
+
+func TestPluginValidation(t *testing.T) {
+	plugin := PluginConfig{Name: "flush-cache", Command: []string{"/plugins/flush-cache"}}
+	tests := []struct {
+		name   string
+		modify func(*PluginConfig)
+		valid  bool
+	}{
+		{"valid", func(*PluginConfig) {}, true},
+		{"without name", func(p *PluginConfig) { p.Name = "" }, false},
+		{"built-in name", func(p *PluginConfig) { p.Name = "restart-pod" }, false},
+		{"without command", func(p *PluginConfig) { p.Command = nil }, false},
+		{"relative command", func(p *PluginConfig) { p.Command = []string{"flush-cache"} }, false},
+		{"negative timeout", func(p *PluginConfig) { p.Timeout = -time.Second }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			configured := plugin
+			tt.modify(&configured)
+			config.Remediation.Plugins = []PluginConfig{configured}
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	// Plugin actions can be used like built-in actions
+	config := DefaultConfig()
+	config.Remediation.Plugins = []PluginConfig{plugin, plugin}
+	config.Detection.NodeConditions.Conditions[0].Actions = []string{"flush-cache"}
+	if result := config.Validate(); result.Valid || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "duplicate name") {
+		t.Errorf("Validate() errors = %v, want only the duplicate plugin", result.Errors)
+	}
+}
+
+func TestFinalizerValidation(t *testing.T) {
+	const lb = "service.kubernetes.io/load-balancer-cleanup"
+	tests := []struct {
+		name       string
+		finalizers FinalizerRemovalConfig
+		valid      bool
+	}{
+		{"default", DefaultConfig().Remediation.Finalizers, true},
+		{"approval", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Name: lb, Kinds: []string{"Service"}}}}, true},
+		{"automatic", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Name: lb, Kinds: []string{"Service"}, Mode: "automatic"}}}, true},
+		{"without stuckAfter", FinalizerRemovalConfig{Allowed: []AllowedFinalizerConfig{{Name: lb, Kinds: []string{"Service"}}}}, false},
+		{"without name", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Kinds: []string{"Service"}}}}, false},
+		{"without kinds", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Name: lb}}}, false},
+		{"unsupported kind", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Name: lb, Kinds: []string{"Namespace"}}}}, false},
+		{"unknown mode", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{{Name: lb, Kinds: []string{"Service"}, Mode: "manual"}}}, false},
+		{"duplicate", FinalizerRemovalConfig{StuckAfter: time.Hour, Allowed: []AllowedFinalizerConfig{
+			{Name: lb, Kinds: []string{"Service"}}, {Name: lb, Kinds: []string{"PersistentVolumeClaim"}},
+		}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Remediation.Finalizers = tt.finalizers
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestEscalationValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []EscalationStepConfig
+		valid bool
+	}{
+		{"default", DefaultConfig().Detection.Escalation.Steps, true},
+		{"single step", []EscalationStepConfig{{After: 30 * time.Minute, Severity: "critical"}}, true},
+		{"no steps", nil, false},
+		{"invalid severity", []EscalationStepConfig{{After: time.Hour, Severity: "urgent"}}, false},
+		{"zero duration", []EscalationStepConfig{{Severity: "high"}}, false},
+		{"decreasing severity", []EscalationStepConfig{{After: time.Hour, Severity: "critical"}, {After: 2 * time.Hour, Severity: "high"}}, false},
+		{"decreasing duration", []EscalationStepConfig{{After: 2 * time.Hour, Severity: "high"}, {After: time.Hour, Severity: "critical"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Escalation.Enabled = true
+			config.Detection.Escalation.Steps = tt.steps
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.Notification.Slack.Enabled = true
+	config.Notification.Slack.Token = "xoxb-token"
+	config.Notification.Slack.SeverityChannels = map[string]string{"critical": "#incidents"}
+	if result := config.Validate(); !result.Valid {
+		t.Errorf("unexpected validation errors: %v", result.Errors)
+	}
+	config.Notification.Slack.SeverityChannels["urgent"] = "#incidents"
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for an unknown severity channel")
+	}
+}
+
+func TestDigestValidation(t *testing.T) {
+	daily := "0 9 * * *"
+	tests := []struct {
+		name    string
+		channel DigestChannelConfig
+		valid   bool
+	}{
+		{"webhook", DigestChannelConfig{Name: "ops", Type: "webhook", Cron: daily, Timezone: "Europe/Berlin", URL: "https://hooks.example.com/digest"}, true},
+		{"email", DigestChannelConfig{Name: "ops", Type: "email", Cron: daily, To: []string{"ops@example.com"}}, true},
+		{"email without recipients", DigestChannelConfig{Name: "ops", Type: "email", Cron: daily}, false},
+		{"slack without slack notifications", DigestChannelConfig{Name: "ops", Type: "slack", Cron: daily}, false},
+		{"invalid cron", DigestChannelConfig{Name: "ops", Type: "webhook", Cron: "daily", URL: "https://hooks.example.com/digest"}, false},
+		{"invalid timezone", DigestChannelConfig{Name: "ops", Type: "webhook", Cron: daily, Timezone: "Mars/Olympus", URL: "https://hooks.example.com/digest"}, false},
+		{"missing name", DigestChannelConfig{Type: "webhook", Cron: daily, URL: "https://hooks.example.com/digest"}, false},
+		{"unknown type", DigestChannelConfig{Name: "ops", Type: "pager", Cron: daily}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Notification.Digest.Enabled = true
+			config.Notification.Digest.SMTP.Host = "smtp.example.com"
+			config.Notification.Digest.SMTP.From = "kubeguardian@example.com"
+			config.Notification.Digest.Channels = []DigestChannelConfig{tt.channel}
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.Notification.Digest.Enabled = true
+	channel := DigestChannelConfig{Name: "ops", Type: "webhook", Cron: daily, URL: "https://hooks.example.com/digest"}
+	config.Notification.Digest.Channels = []DigestChannelConfig{channel, channel}
+	if result := config.Validate(); result.Valid {
+		t.Error("expected validation error for duplicate digest channel names")
+	}
+}
+
+func TestHygieneValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*HygieneConfig)
+		valid  bool
+	}{
+		{"default", func(*HygieneConfig) {}, true},
+		{"selected checks", func(h *HygieneConfig) { h.Checks = []string{"single-replica", "missing-limits"} }, true},
+		{"unknown check", func(h *HygieneConfig) { h.Checks = []string{"missing-pdb"} }, false},
+		{"invalid cron", func(h *HygieneConfig) { h.Cron = "every monday" }, false},
+		{"invalid timezone", func(h *HygieneConfig) { h.Timezone = "Mars/Olympus" }, false},
+		{"invalid namespace pattern", func(h *HygieneConfig) { h.ProductionNamespaces = []string{"prod-["} }, false},
+		{"invalid slack channel", func(h *HygieneConfig) { h.SlackChannel = "platform" }, false},
+		{"no findings listed", func(h *HygieneConfig) { h.MaxFindings = 0 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Hygiene.Enabled = true
+			tt.modify(&config.Detection.Hygiene)
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestPrometheusValidation(t *testing.T) {
+	rule := QueryRuleConfig{
+		Name:      "checkout-error-rate",
+		Query:     `sum by (namespace, deployment) (rate(http_requests_total{code=~"5.."}[5m]))`,
+		Resource:  "Deployment",
+		Operator:  "greater_than",
+		Threshold: 0.05,
+		For:       5 * time.Minute,
+		Severity:  "high",
+		Actions:   []string{"rollback-deployment"},
+	}
+	tests := []struct {
+		name   string
+		modify func(*PrometheusConfig)
+		valid  bool
+	}{
+		{"valid", func(*PrometheusConfig) {}, true},
+		{"rule endpoint without server", func(p *PrometheusConfig) { p.URL = ""; p.Rules[0].Endpoint = "https://thanos.example.com" }, true},
+		{"no server", func(p *PrometheusConfig) { p.URL = "" }, false},
+		{"invalid URL", func(p *PrometheusConfig) { p.URL = "prometheus:9090" }, false},
+		{"missing query", func(p *PrometheusConfig) { p.Rules[0].Query = "" }, false},
+		{"unsupported resource", func(p *PrometheusConfig) { p.Rules[0].Resource = "Service" }, false},
+		{"unknown operator", func(p *PrometheusConfig) { p.Rules[0].Operator = ">" }, false},
+		{"missing severity", func(p *PrometheusConfig) { p.Rules[0].Severity = "" }, false},
+		{"duplicate name", func(p *PrometheusConfig) { p.Rules = append(p.Rules, p.Rules[0]) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Prometheus.URL = "http://prometheus.monitoring:9090"
+			config.Detection.Prometheus.Rules = []QueryRuleConfig{rule}
+			tt.modify(&config.Detection.Prometheus)
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestRuleMetadataParsing(t *testing.T) {
+	data := `
+detection:
+  rules:
+    crash-loop-backoff:
+      owner: sre
+      lastModified: 2024-05-02
+    oom-kill-detected:
+      lastModified: 2024-05-02T10:15:00Z
+`
+	config := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(data), config); err != nil {
+		t.Fatalf("failed to parse rule metadata: %v", err)
+	}
+	crashLoop := config.Detection.Rules["crash-loop-backoff"]
+	if crashLoop.Owner != "sre" || !crashLoop.LastModified.Equal(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected crash-loop-backoff metadata: %+v", crashLoop)
+	}
+	if oom := config.Detection.Rules["oom-kill-detected"]; !oom.LastModified.Equal(time.Date(2024, 5, 2, 10, 15, 0, 0, time.UTC)) {
+		t.Errorf("unexpected oom-kill-detected metadata: %+v", oom)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	hash := func(config *Config, ignore ...string) string {
+		t.Helper()
+		sum, err := config.Hash(ignore)
+		if err != nil {
+			t.Fatalf("Hash() error = %v", err)
+		}
+		return sum
+	}
+
+	base := DefaultConfig()
+	other := DefaultConfig()
+	other.Detection.ConfigDrift.Instance = "kubeguardian-1"
+	other.Detection.ConfigDrift.Cluster = "eu-west"
+	other.Detection.ConfigDrift.Peers = []string{"https://kubeguardian.us-east.example.com"}
+	if hash(base) != hash(other) {
+		t.Error("the identity of an instance must not change the hash")
+	}
+	other.Detection.ConfigDrift.PeerTokenFile = "/etc/kubeguardian/peer-token/token"
+	other.Detection.ConfigDrift.PeerCAFile = "/etc/kubeguardian/peer-token/ca.crt"
+	if hash(base) != hash(other) {
+		t.Error("the peer credential paths of an instance must not change the hash")
+	}
+
+	other.Remediation.CooldownSeconds = 60
+	if hash(base) == hash(other) {
+		t.Error("a different cooldown must change the hash")
+	}
+	if hash(base, "remediation.cooldownSeconds") != hash(other, "remediation.cooldownSeconds") {
+		t.Error("ignored settings must not change the hash")
+	}
+}
+
+func TestConfigDriftValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ConfigDriftConfig)
+		valid  bool
+	}{
+		{"defaults", func(d *ConfigDriftConfig) {}, true},
+		{"peers", func(d *ConfigDriftConfig) { d.Peers = []string{"https://kubeguardian.eu-west.example.com"} }, true},
+		{"invalid peer", func(d *ConfigDriftConfig) { d.Peers = []string{"kubeguardian:8082"} }, false},
+		{"invalid ignore path", func(d *ConfigDriftConfig) { d.Ignore = []string{"notification..token"} }, false},
+		{"missing configmap", func(d *ConfigDriftConfig) { d.ConfigMapName = "" }, false},
+		{"zero staleAfter", func(d *ConfigDriftConfig) { d.StaleAfter = 0 }, false},
+		{"invalid severity", func(d *ConfigDriftConfig) { d.Severity = "urgent" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.ConfigDrift.Enabled = true
+			tt.modify(&config.Detection.ConfigDrift)
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestScopeIncludes(t *testing.T) {
+	scope := ScopeConfig{
+		IncludeNamespaces:  []string{"team-*"},
+		NamespaceSelectors: []string{"owner in (payments,shop)"},
+		ExcludeNamespaces:  []string{"kube-system", "team-sandbox"},
+	}
+	tests := []struct {
+		namespace string
+		labels    map[string]string
+		want      bool
+	}{
+		{"team-a", nil, true},
+		{"checkout", map[string]string{"owner": "shop"}, true},
+		{"checkout", map[string]string{"owner": "search"}, false},
+		{"default", nil, false},
+		{"team-sandbox", nil, false},
+		{"kube-system", map[string]string{"owner": "payments"}, false},
+	}
+	for _, tt := range tests {
+		if got := scope.Includes(tt.namespace, tt.labels); got != tt.want {
+			t.Errorf("Includes(%q, %v) = %v, want %v", tt.namespace, tt.labels, got, tt.want)
+		}
+	}
+
+	excludeOnly := ScopeConfig{ExcludeNamespaces: []string{"kube-*"}}
+	if !excludeOnly.Enabled() || excludeOnly.Restricted() || !excludeOnly.Includes("default", nil) || excludeOnly.Includes("kube-public", nil) {
+		t.Error("a scope with only exclusions must include every namespace but the excluded ones")
+	}
+	if (ScopeConfig{}).Enabled() {
+		t.Error("an empty scope must be disabled")
+	}
+}
+
+func TestScopeValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope ScopeConfig
+		valid bool
+	}{
+		{"empty", ScopeConfig{}, true},
+		{"valid", ScopeConfig{IncludeNamespaces: []string{"team-*"}, NamespaceSelectors: []string{"team=payments,env!=dev"}, ExcludeNamespaces: []string{"kube-system"}}, true},
+		{"invalid pattern", ScopeConfig{ExcludeNamespaces: []string{"team-["}}, false},
+		{"invalid selector", ScopeConfig{NamespaceSelectors: []string{"team in payments"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Detection.Scope = tt.scope
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestSecretProvidersValidation(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	vault := VaultSecretProviderConfig{Address: "https://vault.example.com:8200", AuthMethod: "kubernetes", Role: "kubeguardian"}
+	tests := []struct {
+		name      string
+		token     string
+		providers SecretProvidersConfig
+		valid     bool
+	}{
+		{"plain token", "xoxb-token", SecretProvidersConfig{RefreshInterval: time.Minute}, true},
+		{"vault", "vault://kv/data/kubeguardian#slack-token", SecretProvidersConfig{RefreshInterval: time.Minute, Vault: vault}, true},
+		{"vault without address", "vault://kv/data/kubeguardian#slack-token", SecretProvidersConfig{RefreshInterval: time.Minute, Vault: VaultSecretProviderConfig{Role: "kubeguardian"}}, false},
+		{"vault without role", "vault://kv/data/kubeguardian#slack-token", SecretProvidersConfig{RefreshInterval: time.Minute, Vault: VaultSecretProviderConfig{Address: vault.Address}}, false},
+		{"vault token without file", "vault://kv/data/kubeguardian#slack-token", SecretProvidersConfig{RefreshInterval: time.Minute, Vault: VaultSecretProviderConfig{Address: vault.Address, AuthMethod: "token"}}, false},
+		{"reference without path", "vault://#slack-token", SecretProvidersConfig{RefreshInterval: time.Minute, Vault: vault}, false},
+		{"aws", "aws-sm://prod/kubeguardian#slackToken", SecretProvidersConfig{RefreshInterval: time.Minute, AWS: AWSSecretProviderConfig{Region: "eu-west-1"}}, true},
+		{"aws without region", "aws-sm://prod/kubeguardian#slackToken", SecretProvidersConfig{RefreshInterval: time.Minute}, false},
+		{"gcp", "gcp-sm://projects/prod/secrets/slack-token", SecretProvidersConfig{RefreshInterval: time.Minute}, true},
+		{"gcp without project", "gcp-sm://slack-token", SecretProvidersConfig{RefreshInterval: time.Minute}, false},
+		{"negative refresh interval", "xoxb-token", SecretProvidersConfig{RefreshInterval: -time.Minute}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Notification.Slack.Enabled = true
+			config.Notification.Slack.Token = tt.token
+			config.Notification.Slack.Channel = "#alerts"
+			config.SecretProviders = tt.providers
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestAPITimeoutsValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts APITimeoutsConfig
+		valid    bool
+	}{
+		{"default", DefaultConfig().Runtime.APITimeouts, true},
+		{"unbounded", APITimeoutsConfig{}, true},
+		{"negative list", APITimeoutsConfig{List: -time.Second, Mutate: time.Second}, false},
+		{"negative mutate", APITimeoutsConfig{List: time.Second, Mutate: -time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Runtime.APITimeouts = tt.timeouts
+			if result := config.Validate(); result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v: %v", result.Valid, tt.valid, result.Errors)
+			}
+		})
+	}
+}
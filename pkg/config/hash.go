@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// instanceSettings identify an instance and its peers to the config drift
+// detector, or locate its credentials for them; they differ between instances
+// by design and are never hashed
+var instanceSettings = []string{
+	"detection.configDrift.instance",
+	"detection.configDrift.cluster",
+	"detection.configDrift.peers",
+	"detection.configDrift.peerTokenFile",
+	"detection.configDrift.peerCAFile",
+}
+
+// Hash returns a SHA-256 hash of the configuration without the settings at the
+// ignored dotted paths, e.g. notification.slack.channel. Instances with equal
+// hashes apply the same policy.
+func (c *Config) Hash(ignore []string) (string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return "", fmt.Errorf("failed to unmarshal configuration: %w", err)
+	}
+
+	for _, path := range append(append([]string{}, instanceSettings...), ignore...) {
+		deletePath(tree, strings.Split(path, "."))
+	}
+
+	// JSON sorts map keys, so equal configurations encode identically
+	canonical, err := json.Marshal(tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// deletePath removes the setting at a path of keys from a configuration tree
+func deletePath(tree map[string]interface{}, keys []string) {
+	if len(keys) == 1 {
+		delete(tree, keys[0])
+		return
+	}
+	if child, ok := tree[keys[0]].(map[string]interface{}); ok {
+		deletePath(child, keys[1:])
+	}
+}
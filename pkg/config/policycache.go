@@ -0,0 +1,114 @@
+package config
+
+import "sync"
+
+// PolicyCache caches the namespace selector matches of namespaces by name and a
+// hash of their labels, so the expanded configuration is only rebuilt when a
+// namespace is created, deleted or relabelled, or the configuration is replaced.
+// Matching every selector against every namespace on each cycle is what makes
+// namespace policies expensive on clusters with hundreds of namespaces.
+type PolicyCache struct {
+	mu       sync.Mutex
+	config   *Config
+	entries  map[string]cachedPolicy // Key: namespace
+	expanded *Config
+	hits     uint64
+	misses   uint64
+}
+
+// cachedPolicy is the selector match of a namespace with the labels it was resolved for
+type cachedPolicy struct {
+	labels   uint64
+	selected *NamespaceConfig // nil if no selector matches
+}
+
+// NewPolicyCache creates an empty policy cache
+func NewPolicyCache() *PolicyCache {
+	return &PolicyCache{entries: make(map[string]cachedPolicy)}
+}
+
+// Expand is cfg.ExpandNamespaceSelectors(namespaces) for the given namespaces
+// (name to labels). It returns the previous expanded configuration and false if
+// no namespace was added, removed or relabelled since the last call with the same
+// configuration; a different configuration invalidates the cache.
+func (p *PolicyCache) Expand(cfg *Config, namespaces map[string]map[string]string) (*Config, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cfg != p.config {
+		p.config = cfg
+		p.entries = make(map[string]cachedPolicy, len(namespaces))
+		p.expanded = nil
+	}
+	if len(cfg.NamespaceSelectors) == 0 {
+		p.expanded = cfg
+		return cfg, false
+	}
+
+	changed := p.expanded == nil
+	for namespace, labels := range namespaces {
+		hash := LabelsHash(labels)
+		if entry, exists := p.entries[namespace]; exists && entry.labels == hash {
+			p.hits++
+			continue
+		}
+		p.misses++
+		changed = true
+
+		entry := cachedPolicy{labels: hash}
+		if selector := cfg.NamespaceSelectorFor(namespace, labels); selector != nil {
+			selected := selector.NamespaceConfig
+			entry.selected = &selected
+		}
+		p.entries[namespace] = entry
+	}
+	for namespace := range p.entries {
+		if _, exists := namespaces[namespace]; !exists {
+			delete(p.entries, namespace)
+			changed = true
+		}
+	}
+	if !changed {
+		return p.expanded, false
+	}
+
+	selected := make(map[string]NamespaceConfig)
+	for namespace, entry := range p.entries {
+		if entry.selected != nil {
+			selected[namespace] = *entry.selected
+		}
+	}
+	p.expanded = cfg.withSelectedNamespaces(selected)
+	return p.expanded, true
+}
+
+// Stats returns the number of namespaces resolved from the cache and resolved again
+func (p *PolicyCache) Stats() (hits, misses uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hits, p.misses
+}
+
+// LabelsHash returns a hash of a label set that does not depend on map order. Each
+// label is hashed with FNV-1a and the hashes are added up, so no sorting or
+// allocation is needed.
+func LabelsHash(labels map[string]string) uint64 {
+	const (
+		offset = 14695981039346656037
+		prime  = 1099511628211
+	)
+
+	var sum uint64
+	for key, value := range labels {
+		h := uint64(offset)
+		for i := 0; i < len(key); i++ {
+			h = (h ^ uint64(key[i])) * prime
+		}
+		h *= prime // A zero byte separates the key from the value
+		for i := 0; i < len(value); i++ {
+			h = (h ^ uint64(value[i])) * prime
+		}
+		sum += h
+	}
+	return sum
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func selectorConfig() *Config {
+	config := DefaultConfig()
+	config.NamespaceSelectors = []NamespaceSelectorConfig{
+		{Names: []string{"team-*"}, MatchLabels: map[string]string{"tier": "prod"}, NamespaceConfig: NamespaceConfig{CrashLoop: CrashLoopConfig{RestartLimit: 10}}},
+		{Names: []string{"team-*"}, NamespaceConfig: NamespaceConfig{CrashLoop: CrashLoopConfig{RestartLimit: 5}}},
+	}
+	return config
+}
+
+func TestPolicyCacheExpand(t *testing.T) {
+	config := selectorConfig()
+	cache := NewPolicyCache()
+	namespaces := map[string]map[string]string{
+		"team-payments": {"tier": "prod"},
+		"team-sandbox":  {},
+		"kube-system":   nil,
+	}
+
+	expanded, changed := cache.Expand(config, namespaces)
+	if !changed || expanded.Namespaces["team-payments"].CrashLoop.RestartLimit != 10 || expanded.Namespaces["team-sandbox"].CrashLoop.RestartLimit != 5 {
+		t.Fatalf("expected selectors to be expanded, got %+v", expanded.Namespaces)
+	}
+	if _, exists := expanded.Namespaces["kube-system"]; exists {
+		t.Error("kube-system should not match any selector")
+	}
+
+	// Same labels in a different map are served from the cache
+	again, changed := cache.Expand(config, map[string]map[string]string{
+		"team-payments": {"tier": "prod"},
+		"team-sandbox":  {},
+		"kube-system":   {},
+	})
+	if changed || again != expanded {
+		t.Error("expected the cached expansion for unchanged namespaces")
+	}
+	if hits, misses := cache.Stats(); hits != 3 || misses != 3 {
+		t.Errorf("Stats() = %d hits, %d misses, want 3 and 3", hits, misses)
+	}
+
+	// Relabelled namespaces are resolved again
+	namespaces["team-sandbox"] = map[string]string{"tier": "prod"}
+	expanded, changed = cache.Expand(config, namespaces)
+	if !changed || expanded.Namespaces["team-sandbox"].CrashLoop.RestartLimit != 10 {
+		t.Errorf("expected the relabelled namespace to match the first selector, got %+v", expanded.Namespaces["team-sandbox"])
+	}
+
+	// Deleted namespaces are dropped
+	delete(namespaces, "team-payments")
+	expanded, changed = cache.Expand(config, namespaces)
+	if _, exists := expanded.Namespaces["team-payments"]; !changed || exists {
+		t.Error("expected the deleted namespace to be dropped")
+	}
+
+	// A new configuration invalidates the cache
+	replaced := selectorConfig()
+	replaced.NamespaceSelectors[0].CrashLoop.RestartLimit = 20
+	expanded, changed = cache.Expand(replaced, namespaces)
+	if !changed || expanded.Namespaces["team-sandbox"].CrashLoop.RestartLimit != 20 {
+		t.Errorf("expected the new configuration to be applied, got %+v", expanded.Namespaces["team-sandbox"])
+	}
+}
+
+func TestLabelsHash(t *testing.T) {
+	if LabelsHash(map[string]string{"a": "b", "c": "d"}) != LabelsHash(map[string]string{"c": "d", "a": "b"}) {
+		t.Error("hash should not depend on map order")
+	}
+	if LabelsHash(map[string]string{"a": "bc"}) == LabelsHash(map[string]string{"ab": "c"}) {
+		t.Error("hash should separate keys and values")
+	}
+	if LabelsHash(nil) != LabelsHash(map[string]string{}) {
+		t.Error("nil and empty labels should hash the same")
+	}
+}
+
+func benchmarkNamespaces(n int) map[string]map[string]string {
+	namespaces := make(map[string]map[string]string, n)
+	for i := 0; i < n; i++ {
+		namespaces[fmt.Sprintf("team-%d", i)] = map[string]string{"tier": "prod", "team": fmt.Sprintf("team-%d", i), "app.kubernetes.io/part-of": "platform"}
+	}
+	return namespaces
+}
+
+func BenchmarkExpandNamespaceSelectors(b *testing.B) {
+	config := selectorConfig()
+	namespaces := benchmarkNamespaces(500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		config.ExpandNamespaceSelectors(namespaces)
+	}
+}
+
+func BenchmarkPolicyCacheExpand(b *testing.B) {
+	config := selectorConfig()
+	namespaces := benchmarkNamespaces(500)
+	cache := NewPolicyCache()
+	cache.Expand(config, namespaces)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Expand(config, namespaces)
+	}
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+)
+
+// ErrFeatureDisabled is returned for actions whose feature flag is disabled
+var ErrFeatureDisabled = errors.New("action is disabled by a feature flag")
+
+// ErrObserveMode is returned when an action is requested while running in observe mode
+var ErrObserveMode = errors.New("remediation is not available in observe mode")
+
+// ActionRequest represents a manually requested remediation action
+type ActionRequest struct {
+	Action    string                `json:"action"`
+	Namespace string                `json:"namespace"`
+	Kind      string                `json:"kind"`
+	Name      string                `json:"name"`
+	Requester remediation.Requester `json:"-"`
+	// Container optionally targets a single container of a pod
+	Container string `json:"container,omitempty"`
+}
+
+// Validate checks that the request is complete
+func (r ActionRequest) Validate() error {
+	// Nodes are the only cluster-scoped resources actions apply to
+	if r.Action == "" || (r.Namespace == "" && r.Kind != "Node") || r.Kind == "" || r.Name == "" {
+		return fmt.Errorf("action, namespace, kind and name are required")
+	}
+	if r.Namespace != "" && r.Kind == "Node" {
+		return fmt.Errorf("nodes are not namespaced")
+	}
+	if r.Requester.User == "" {
+		return fmt.Errorf("requester identity is required")
+	}
+	return nil
+}
+
+// TriggerAction executes a manually requested action on behalf of the requester
+func (c *Controller) TriggerAction(ctx context.Context, req ActionRequest) (*remediation.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.remediator == nil {
+		return nil, ErrObserveMode
+	}
+	if flag, gated := c.actionGated(req.Action); gated {
+		return nil, fmt.Errorf("%w: %s requires the %s feature", ErrFeatureDisabled, req.Action, flag)
+	}
+
+	resource, err := c.getResource(ctx, req.Kind, req.Namespace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	issue := detection.Issue{
+		RuleName:    "manual",
+		Description: fmt.Sprintf("Action %s requested by %s via %s", req.Action, req.Requester.User, req.Requester.Source),
+		Severity:    "low",
+		Resource:    resource,
+		Namespace:   req.Namespace,
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Actions:     []string{req.Action},
+		DetectedAt:  time.Now(),
+		Container:   req.Container,
+	}
+
+	logger.Info("Executing manually requested action",
+		"action", req.Action,
+		"resource", req.Name,
+		"namespace", req.Namespace,
+		"requestedBy", req.Requester.User,
+		"source", req.Requester.Source)
+
+	start := time.Now()
+	ctx = remediation.WithContainer(remediation.WithRequester(ctx, req.Requester), req.Container)
+	result, err := c.remediator.ExecuteAction(ctx, req.Action, resource, req.Namespace)
+	if err != nil {
+		c.metrics.RecordRemediation(ctx, req.Action, "error", req.Namespace, time.Since(start))
+		return result, err
+	}
+
+	for _, check := range result.PreChecks {
+		c.metrics.RecordPreCheck(req.Action, check.Name, check.Outcome)
+	}
+	status := "success"
+	switch {
+	case result.Skipped:
+		status = "skipped"
+	case !result.Success:
+		status = "failed"
+	}
+	c.metrics.RecordRemediation(ctx, req.Action, status, req.Namespace, time.Since(start))
+
+	if c.slackNotifier != nil {
+		if err := c.slackNotifier.SendRemediationNotification(ctx, issue, *result); err != nil {
+			logger.Error(err, "Failed to send remediation notification")
+			c.metrics.RecordNotification("remediation", "failed")
+		} else {
+			c.metrics.RecordNotification("remediation", "success")
+		}
+	}
+	c.rollouts.follow(ctx, issue, *result)
+
+	return result, nil
+}
+
+// getResource fetches the object targeted by a manual action
+func (c *Controller) getResource(ctx context.Context, kind, namespace, name string) (runtime.Object, error) {
+	switch kind {
+	case "Pod":
+		pod, err := c.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		return pod, nil
+	case "Deployment":
+		deployment, err := c.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		return deployment, nil
+	case "Service":
+		service, err := c.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service: %w", err)
+		}
+		return service, nil
+	case "Node":
+		node, err := c.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+		return node, nil
+	case "PersistentVolumeClaim":
+		claim, err := c.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get persistent volume claim: %w", err)
+		}
+		return claim, nil
+	case "Namespace":
+		ns, err := c.client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace: %w", err)
+		}
+		return ns, nil
+	default:
+		return nil, fmt.Errorf("unsupported resource kind: %s", kind)
+	}
+}
+
+// impersonatingClientFactory returns a factory creating clients that impersonate
+// the requester. Only the groups of the requester listed in groups are
+// impersonated, and no extra attributes, so the ClusterRole need not grant
+// impersonation of arbitrary groups.
+func impersonatingClientFactory(restConfig *rest.Config, groups []string) remediation.ClientFactory {
+	return func(requester remediation.Requester) (kubernetes.Interface, error) {
+		impersonated := rest.CopyConfig(restConfig)
+		impersonated.Impersonate = rest.ImpersonationConfig{UserName: requester.User}
+		for _, group := range requester.Groups {
+			if slices.Contains(groups, group) {
+				impersonated.Impersonate.Groups = append(impersonated.Impersonate.Groups, group)
+			}
+		}
+		return kubernetes.NewForConfig(impersonated)
+	}
+}
+
+// UndoAction restores the state recorded before a reversible action on behalf of the requester
+func (c *Controller) UndoAction(ctx context.Context, id string, requester remediation.Requester) (*remediation.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if requester.User == "" {
+		return nil, fmt.Errorf("requester identity is required")
+	}
+
+	if c.remediator == nil {
+		return nil, ErrObserveMode
+	}
+
+	logger.Info("Undoing remediation action", "id", id, "requestedBy", requester.User, "source", requester.Source)
+
+	start := time.Now()
+	result, err := c.remediator.Undo(remediation.WithRequester(ctx, requester), id)
+	if result == nil {
+		return nil, err
+	}
+
+	status := "success"
+	if err != nil {
+		status = "error"
+	} else if !result.Success {
+		status = "failed"
+	}
+	c.metrics.RecordRemediation(ctx, "undo", status, result.Namespace, time.Since(start))
+
+	if c.slackNotifier != nil {
+		issue := detection.Issue{
+			RuleName:    "manual",
+			Description: fmt.Sprintf("Undo of action %s requested by %s via %s", id, requester.User, requester.Source),
+			Severity:    "low",
+			Namespace:   result.Namespace,
+			Name:        result.Resource,
+			Kind:        "Deployment",
+			Actions:     []string{"undo"},
+			DetectedAt:  time.Now(),
+		}
+		if err := c.slackNotifier.SendRemediationNotification(ctx, issue, *result); err != nil {
+			logger.Error(err, "Failed to send remediation notification")
+			c.metrics.RecordNotification("remediation", "failed")
+		} else {
+			c.metrics.RecordNotification("remediation", "success")
+		}
+	}
+
+	return result, err
+}
+
+// UndoRecords returns the records of reversible actions that can be undone
+func (c *Controller) UndoRecords() []remediation.UndoRecord {
+	if c.remediator == nil {
+		return nil
+	}
+	return c.remediator.UndoRecords()
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+)
+
+// analyzeIssues diagnoses issues from their events, container states and logs.
+// Known failure signatures are matched if heuristics are enabled; issues of at
+// least the configured severity get a root-cause hypothesis while the aiAnalysis
+// feature is enabled. Failed analyses leave the issue unchanged.
+func (c *Controller) analyzeIssues(ctx context.Context, issues []detection.Issue) {
+	heuristics := c.config.Analysis.Heuristics.Enabled
+	ai := c.analyzer != nil && c.FeatureEnabled(features.AIAnalysis)
+	if !heuristics && !ai {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	minSeverity := config.SeverityRank(c.config.Analysis.MinSeverity)
+	for i := range issues {
+		issue := &issues[i]
+		analysisCtx := analysis.Context{
+			RuleName:    issue.RuleName,
+			Description: issue.Description,
+			Severity:    issue.Severity,
+			Namespace:   issue.Namespace,
+			Kind:        issue.Kind,
+			Name:        issue.Name,
+			Reason:      issue.Reason,
+		}
+		if issue.Change != nil {
+			analysisCtx.Change = issue.Change.Summary(time.Now())
+		}
+		c.collector.Collect(ctx, &analysisCtx, issue.Resource)
+
+		if heuristics {
+			issue.Diagnoses = analysis.Diagnose(analysisCtx, c.config.Analysis.Heuristics.Runbooks)
+		}
+
+		if !ai || config.SeverityRank(issue.Severity) < minSeverity {
+			continue
+		}
+
+		start := time.Now()
+		callCtx, cancel := context.WithTimeout(ctx, c.config.Analysis.Timeout)
+		result, err := c.analyzer.Analyze(callCtx, analysisCtx)
+		cancel()
+		if err != nil {
+			logger.Error(err, "Root cause analysis failed", "rule", issue.RuleName, "namespace", issue.Namespace, "resource", issue.Name)
+			c.metrics.RecordAnalysis("failed", time.Since(start))
+			continue
+		}
+		c.metrics.RecordAnalysis("success", time.Since(start))
+		issue.Analysis = result
+	}
+}
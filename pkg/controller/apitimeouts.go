@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+)
+
+// API request budgets
+const (
+	apiBudgetList   = "list"
+	apiBudgetMutate = "mutate"
+)
+
+// apiTimeouts bounds each Kubernetes API request by the timeout of its budget:
+// get and list requests by the list timeout, other requests by the mutate
+// timeout. The timeout covers reading the response body. Watches, followed logs
+// and upgraded connections are not bounded.
+type apiTimeouts struct {
+	next    http.RoundTripper
+	list    time.Duration
+	mutate  time.Duration
+	metrics *metrics.Metrics
+}
+
+// newAPITimeouts returns the wrapper of the transport of Kubernetes clients
+// applying the configured timeouts
+func newAPITimeouts(cfg config.APITimeoutsConfig, metricsCollector *metrics.Metrics) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &apiTimeouts{next: next, list: cfg.List, mutate: cfg.Mutate, metrics: metricsCollector}
+	}
+}
+
+// RoundTrip sends a request, failing it when its budget is exceeded
+func (t *apiTimeouts) RoundTrip(req *http.Request) (*http.Response, error) {
+	budget, timeout := apiBudgetList, t.list
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		budget, timeout = apiBudgetMutate, t.mutate
+	}
+	query := req.URL.Query()
+	if timeout <= 0 || query.Get("watch") == "true" || query.Get("follow") == "true" || req.Header.Get("Upgrade") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	timedOut := func() bool {
+		return errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil
+	}
+	record := sync.OnceFunc(func() {
+		if t.metrics != nil {
+			t.metrics.RecordAPITimeout(budget, apiResource(req.URL.Path))
+		}
+	})
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if timedOut() {
+			record()
+			return nil, fmt.Errorf("kubernetes API %s request exceeded its %s timeout: %w", budget, timeout, err)
+		}
+		return nil, err
+	}
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, cancel: cancel, timedOut: timedOut, record: record}
+	return resp, nil
+}
+
+// timeoutBody releases the timeout of a request once its response body is
+// closed, and records a timeout while reading it
+type timeoutBody struct {
+	io.ReadCloser
+	cancel   context.CancelFunc
+	timedOut func() bool
+	record   func()
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.timedOut() {
+		b.record()
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// apiResource returns the resource of a Kubernetes API path, e.g. pods for
+// /api/v1/namespaces/default/pods/web-1 and deployments for
+// /apis/apps/v1/deployments
+func apiResource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	i := 2 // /api/v1/...
+	if len(parts) > 0 && parts[0] == "apis" {
+		i = 3 // /apis/group/version/...
+	}
+	if len(parts) > i+2 && parts[i] == "namespaces" {
+		i += 2
+	}
+	if len(parts) <= i {
+		return "unknown"
+	}
+	return parts[i]
+}
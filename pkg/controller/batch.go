@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// batchKey groups the pod issues of a rule by Deployment
+type batchKey struct {
+	rule       string
+	namespace  string
+	deployment string
+}
+
+// batchIssues collapses the actionable pod issues of a rule into a single issue
+// of their Deployment when all of its pods are affected, so one workload-level
+// action replaces an action per pod. Affected pods are counted in all detected
+// issues, including issues that are not actionable in this cycle.
+func (c *Controller) batchIssues(ctx context.Context, detected, actionable []detection.Issue) []detection.Issue {
+	batch := c.config.Remediation.Batch
+	if !batch.Enabled || c.remediator == nil {
+		return actionable
+	}
+
+	failing := make(map[batchKey]map[string]bool)
+	for _, issue := range detected {
+		if key, ok := batchKeyOf(issue); ok {
+			if failing[key] == nil {
+				failing[key] = make(map[string]bool)
+			}
+			failing[key][issue.Name] = true
+		}
+	}
+
+	batched := make(map[batchKey]bool)
+	result := make([]detection.Issue, 0, len(actionable))
+	for _, issue := range actionable {
+		key, ok := batchKeyOf(issue)
+		if !ok || len(failing[key]) < batch.MinPods {
+			result = append(result, issue)
+			continue
+		}
+		if done, checked := batched[key]; checked {
+			if !done {
+				result = append(result, issue)
+			}
+			continue
+		}
+
+		workload, ok := c.workloadIssue(ctx, key, issue, len(failing[key]))
+		batched[key] = ok
+		if !ok {
+			result = append(result, issue)
+			continue
+		}
+		result = append(result, workload)
+	}
+	return result
+}
+
+// batchKeyOf returns the batch group of a pod issue with actions owned by a Deployment
+func batchKeyOf(issue detection.Issue) (batchKey, bool) {
+	if issue.Kind != "Pod" || len(issue.Actions) == 0 {
+		return batchKey{}, false
+	}
+	kind, name := detection.WorkloadOf(issue)
+	if kind != "Deployment" {
+		return batchKey{}, false
+	}
+	return batchKey{rule: issue.RuleName, namespace: issue.Namespace, deployment: name}, true
+}
+
+// workloadIssue returns the Deployment issue replacing the pod issues of a batch
+// group, or false if not all pods of the Deployment are affected
+func (c *Controller) workloadIssue(ctx context.Context, key batchKey, issue detection.Issue, pods int) (detection.Issue, bool) {
+	logger := log.FromContext(ctx)
+
+	deployment, err := c.client.AppsV1().Deployments(key.namespace).Get(ctx, key.deployment, metav1.GetOptions{})
+	if err != nil {
+		logger.V(1).Info("Not batching pod issues: failed to get deployment", "deployment", key.deployment, "namespace", key.namespace, "error", err.Error())
+		return detection.Issue{}, false
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if int32(pods) < replicas {
+		return detection.Issue{}, false
+	}
+
+	action := c.config.Remediation.Batch.Action
+	logger.Info("All pods of deployment affected: batching pod issues into a workload action",
+		"rule", key.rule,
+		"deployment", key.deployment,
+		"namespace", key.namespace,
+		"pods", pods,
+		"action", action)
+
+	issue.Description = fmt.Sprintf("All %d pods of deployment %s are affected: %s", pods, key.deployment, issue.Description)
+	issue.Resource = deployment
+	issue.Kind = "Deployment"
+	issue.Name = key.deployment
+	issue.Container = ""
+	issue.Actions = []string{action}
+	return issue, true
+}
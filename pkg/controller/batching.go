@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/adaptive"
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// Batch size kinds reported in metrics
+const (
+	batchListPageSize       = "listPageSize"
+	batchRemediationWorkers = "remediationWorkers"
+)
+
+// cycleBatching sizes the list pages and remediation workers of detection cycles
+// to their latency. Cycles start with small pages and one worker, and grow both
+// while they take longer than the target.
+type cycleBatching struct {
+	pages   *adaptive.Sizer
+	workers *adaptive.Sizer
+}
+
+// newCycleBatching creates the batching of detection cycles, or nil if disabled
+func newCycleBatching(cfg *config.Config) *cycleBatching {
+	batching := cfg.Runtime.Batching
+	if !batching.Enabled {
+		return nil
+	}
+	target := time.Duration(float64(cfg.Detection.EvaluationInterval) * batching.TargetRatio)
+	return &cycleBatching{
+		pages:   adaptive.NewSizer(batching.MinPageSize, batching.MinPageSize, batching.MaxPageSize, target),
+		workers: adaptive.NewSizer(1, 1, batching.MaxWorkers, target),
+	}
+}
+
+// applyBatching sets the list page size of the detector for the next cycle
+func (c *Controller) applyBatching() {
+	if c.batching == nil {
+		return
+	}
+	c.detector.SetPageSize(c.batching.pages.Size())
+}
+
+// observeCycle adapts the batch sizes to the latency of a full detection cycle
+func (c *Controller) observeCycle(ctx context.Context, latency time.Duration) {
+	if c.batching == nil {
+		return
+	}
+	pageSize := c.batching.pages.Observe(latency)
+	workers := c.batching.workers.Observe(latency)
+	c.metrics.SetBatchSize(batchListPageSize, pageSize)
+	c.metrics.SetBatchSize(batchRemediationWorkers, workers)
+	log.FromContext(ctx).V(1).Info("Adapted batch sizes to cycle latency", "latency", latency, "pageSize", pageSize, "workers", workers)
+}
+
+// processIssues processes issues with the current number of workers. Workers take
+// issues in order, so higher priority issues still start first.
+func (c *Controller) processIssues(ctx context.Context, issues []detection.Issue) {
+	logger := log.FromContext(ctx)
+	c.stats.pending.Store(int64(len(issues)))
+	process := func(issue detection.Issue) {
+		defer c.stats.pending.Add(-1)
+		if err := c.processIssue(ctx, issue); err != nil {
+			logger.Error(err, "Failed to process issue", "rule", issue.RuleName, "resource", issue.Name)
+		}
+	}
+
+	workers := 1
+	if c.batching != nil {
+		workers = min(c.batching.workers.Size(), len(issues))
+	}
+	if workers <= 1 {
+		for _, issue := range issues {
+			process(issue)
+		}
+		return
+	}
+
+	queue := make(chan detection.Issue)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for issue := range queue {
+				process(issue)
+			}
+		}()
+	}
+	for _, issue := range issues {
+		queue <- issue
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overlay"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// StateBundleVersion is the version of the state bundle format; bundles of other
+// versions are rejected on import
+const StateBundleVersion = 1
+
+// ErrBundleVersion is returned when importing a bundle of an unsupported version
+var ErrBundleVersion = errors.New("unsupported state bundle version")
+
+// StateBundle is the operational state of an instance, exported to migrate it to
+// another cluster or to restore it after losing the state backend
+type StateBundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// Rules and Policies are the loaded rules and the namespace policies of the
+	// exporting instance. Both are defined by configuration, so importing only
+	// compares them with the importing instance.
+	Rules    []detection.RuleSummary           `json:"rules"`
+	Policies map[string]config.NamespaceConfig `json:"policies"`
+	// Silences are the rules disabled at runtime and their audit trail
+	Silences overlay.State `json:"silences"`
+	// Features are the feature flags changed at runtime
+	Features []features.Status `json:"features"`
+	// Cooldowns are the latest remediation actions of resources
+	Cooldowns []remediation.CooldownEntry `json:"cooldowns"`
+	// Conditions are the first-seen times of duration-based conditions
+	Conditions map[string]detection.ConditionState `json:"conditions"`
+	// Remediations are the idempotency keys of executed remediation actions
+	Remediations map[string]detection.ConditionState `json:"remediations"`
+	// Issues are the active issues of the issue tracker
+	Issues []tracker.Record `json:"issues"`
+}
+
+// ImportResult tells how much of a bundle was imported
+type ImportResult struct {
+	Silences     int `json:"silences"`
+	Features     int `json:"features"`
+	Cooldowns    int `json:"cooldowns"`
+	Conditions   int `json:"conditions"`
+	Remediations int `json:"remediations"`
+	Issues       int `json:"issues"`
+	// Warnings list the rules and policies of the bundle that differ from this
+	// instance, and the state that could not be imported
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// loadStores loads the persisted state so exports include it and imports do not
+// overwrite it
+func (c *Controller) loadStores(ctx context.Context) error {
+	if err := c.ruleOverlay.Load(ctx); err != nil {
+		c.metrics.RecordStateStoreError("load")
+		return fmt.Errorf("failed to load rules disabled at runtime: %w", err)
+	}
+	if err := c.detector.State().Load(ctx); err != nil {
+		c.metrics.RecordStateStoreError("load")
+		return fmt.Errorf("failed to load rule evaluation state: %w", err)
+	}
+	if err := c.remediations.Load(ctx); err != nil {
+		c.metrics.RecordStateStoreError("load")
+		return fmt.Errorf("failed to load remediation idempotency keys: %w", err)
+	}
+	return nil
+}
+
+// ExportState returns the operational state of the instance as a bundle
+func (c *Controller) ExportState(ctx context.Context) (*StateBundle, error) {
+	if err := c.loadStores(ctx); err != nil {
+		return nil, err
+	}
+
+	bundle := &StateBundle{
+		Version:      StateBundleVersion,
+		ExportedAt:   time.Now().UTC(),
+		Policies:     c.config.NamespacePolicies(),
+		Silences:     c.ruleOverlay.State(),
+		Features:     []features.Status{},
+		Cooldowns:    []remediation.CooldownEntry{},
+		Conditions:   c.detector.State().Snapshot(),
+		Remediations: c.remediations.Snapshot(),
+		Issues:       c.ActiveIssues(),
+	}
+	for _, rule := range c.detector.Rules() {
+		bundle.Rules = append(bundle.Rules, rule.Summary())
+	}
+	for _, status := range c.features.List() {
+		if status.Source == features.SourceAPI {
+			bundle.Features = append(bundle.Features, status)
+		}
+	}
+	if c.remediator != nil {
+		bundle.Cooldowns = c.remediator.Cooldowns()
+	}
+	return bundle, nil
+}
+
+// ImportState merges the operational state of a bundle into the instance on
+// behalf of the requester. State already held by the instance takes precedence:
+// conditions keep their earliest first-seen time, cooldowns their latest action,
+// and active issues their record. The merged state is persisted with the state
+// backend.
+func (c *Controller) ImportState(ctx context.Context, bundle *StateBundle, requester remediation.Requester) (*ImportResult, error) {
+	if bundle.Version != StateBundleVersion {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrBundleVersion, bundle.Version, StateBundleVersion)
+	}
+	if err := c.loadStores(ctx); err != nil {
+		return nil, err
+	}
+	logger := log.FromContext(ctx)
+	result := &ImportResult{}
+
+	c.detector.State().Merge(bundle.Conditions)
+	result.Conditions = len(bundle.Conditions)
+	if err := c.detector.State().Flush(ctx); err != nil {
+		c.metrics.RecordStateStoreError("save")
+		return nil, fmt.Errorf("failed to save rule evaluation state: %w", err)
+	}
+	c.remediations.Merge(bundle.Remediations)
+	result.Remediations = len(bundle.Remediations)
+	if err := c.remediations.Flush(ctx); err != nil {
+		c.metrics.RecordStateStoreError("save")
+		return nil, fmt.Errorf("failed to save remediation idempotency keys: %w", err)
+	}
+
+	c.ruleOverlay.Merge(bundle.Silences)
+	if err := c.ruleOverlay.Flush(ctx); err != nil {
+		c.metrics.RecordStateStoreError("save")
+		return nil, fmt.Errorf("failed to save rules disabled at runtime: %w", err)
+	}
+	c.applyRuleOverlay(ctx)
+	result.Silences = len(bundle.Silences.Disabled)
+
+	for _, status := range bundle.Features {
+		if _, err := c.features.Set(status.Name, status.Enabled); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("feature %s: %v", status.Name, err))
+			continue
+		}
+		c.metrics.RecordFeatureEnabled(status.Name, status.Enabled)
+		result.Features++
+	}
+
+	if c.remediator != nil {
+		c.remediator.MergeCooldowns(bundle.Cooldowns)
+		result.Cooldowns = len(bundle.Cooldowns)
+	} else if len(bundle.Cooldowns) > 0 {
+		result.Warnings = append(result.Warnings, "cooldowns: remediation is disabled on this instance")
+	}
+	result.Issues = c.tracker.Merge(bundle.Issues)
+
+	result.Warnings = append(result.Warnings, c.compareBundle(bundle)...)
+	logger.Info("Imported state bundle", "exportedAt", bundle.ExportedAt, "conditions", result.Conditions,
+		"silences", result.Silences, "issues", result.Issues, "warnings", len(result.Warnings), "requestedBy", requester.User)
+	return result, nil
+}
+
+// compareBundle lists the rules and namespace policies of a bundle that differ
+// from the configuration of the instance
+func (c *Controller) compareBundle(bundle *StateBundle) []string {
+	var warnings []string
+	for _, rule := range bundle.Rules {
+		if _, found := c.rule(rule.Name); !found {
+			warnings = append(warnings, fmt.Sprintf("rule %s is not loaded by this instance", rule.Name))
+		}
+	}
+
+	policies := c.config.NamespacePolicies()
+	namespaces := make([]string, 0, len(bundle.Policies))
+	for namespace := range bundle.Policies {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		policy, exists := policies[namespace]
+		switch {
+		case !exists:
+			warnings = append(warnings, fmt.Sprintf("namespace %s has no policy on this instance", namespace))
+		case !samePolicy(policy, bundle.Policies[namespace]):
+			warnings = append(warnings, fmt.Sprintf("namespace %s has a different policy on this instance", namespace))
+		}
+	}
+	return warnings
+}
+
+// samePolicy compares namespace policies by their encoding, so a policy read
+// from a bundle equals the configured one it was exported from
+func samePolicy(a, b config.NamespaceConfig) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/capabilities"
+)
+
+// CheckCapabilities probes the cluster version and served APIs. Rules needing a
+// missing capability, such as the CRDs of an optional integration, are disabled,
+// and so are actions the cluster cannot execute, with a warning instead of
+// failing every cycle.
+func (c *Controller) CheckCapabilities(ctx context.Context) (*capabilities.Report, error) {
+	logger := log.FromContext(ctx)
+
+	report, err := capabilities.NewProber(c.client.Discovery()).Probe()
+	if err != nil {
+		return nil, err
+	}
+
+	for capability, available := range report.Capabilities {
+		c.metrics.RecordClusterCapability(capability, available)
+	}
+
+	disabled := make(map[string][]string) // Key: missing capability, value: disabled rules
+	for _, rule := range c.detector.Rules() {
+		if !rule.Enabled {
+			continue
+		}
+		for _, capability := range rule.Requires {
+			if !report.Has(capability) {
+				c.detector.DisableRule(rule.Name)
+				disabled[capability] = append(disabled[capability], rule.Name)
+				break
+			}
+		}
+	}
+	for capability, rules := range disabled {
+		logger.Info("Disabling rules whose integration is not installed", "capability", capability, "rules", rules)
+	}
+	if c.config.Detection.MetricsServer.Enabled && !report.Has(capabilities.MetricsServer) {
+		logger.Info("Metrics server is not installed, CPU and memory rules use restart heuristics")
+	}
+
+	if !c.appliesActions() {
+		return report, nil
+	}
+
+	actions := c.enabledActions()
+	for action, missing := range report.MissingActions(actions) {
+		logger.Info("Disabling action not supported by the cluster", "action", action, "missing", missing, "version", report.Version)
+		c.remediator.DisableAction(action, fmt.Sprintf("not supported by Kubernetes %s: %s", report.Version, strings.Join(missing, ", ")))
+	}
+
+	if slices.Contains(actions, "restart-container") && !report.Has(capabilities.SidecarContainers) {
+		logger.Info("Sidecar containers are not supported by the cluster, restart-container only restarts regular containers", "version", report.Version)
+	}
+
+	return report, nil
+}
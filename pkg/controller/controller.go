@@ -2,20 +2,42 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
+	"github.com/NotHarshhaa/kubeguardian/pkg/catalog"
 	"github.com/NotHarshhaa/kubeguardian/pkg/config"
 	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/export"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
 	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
 	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overlay"
+	"github.com/NotHarshhaa/kubeguardian/pkg/permissions"
+	"github.com/NotHarshhaa/kubeguardian/pkg/queue"
 	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/secrets"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+	"github.com/NotHarshhaa/kubeguardian/pkg/version"
 )
 
 // Controller represents the main KubeGuardian controller
@@ -25,7 +47,36 @@ type Controller struct {
 	detector      *detection.Detector
 	remediator    *remediation.Engine
 	slackNotifier *notification.SlackNotifier
+	notifications *notification.Deduplicator
+	tracker       *tracker.Tracker
+	remediations  *detection.StateStore // Idempotency keys of executed remediation actions
+	queue         *queue.Queue          // Remediation actions waiting to be executed, nil executes them during cycles
+	lifecycle     runLifecycle          // Start and uptime of this run, persisted across restarts
+	ruleOverlay   *overlay.Overlay      // Rules disabled at runtime, persisted across restarts
+	activity      *digest.Stats         // Activity reported in digests, nil if digests are disabled
+	digest        *digest.Reporter      // Sends scheduled digests, nil if digests are disabled
+	hygiene       *hygiene.Reporter     // Sends scheduled hygiene reports, nil if they are disabled
+	resyncs       chan resyncCall       // Forced detection cycles, served by Run
+	watch         *objectWatch          // Watch events of pods and deployments, nil if disabled
+	rollouts      *rolloutWatcher       // Follows rollouts started by actions, nil if disabled
+	running       atomic.Bool
+	exclusions    workloadExclusions
+	features      *features.Flags
+	analyzer      analysis.Analyzer // nil if no analysis endpoint is configured
+	collector     *analysis.Collector
+	exporter      *export.Exporter  // nil if document export is disabled
+	secrets       *secrets.Resolver // Keeps credentials read from secret stores up to date
 	metrics       *metrics.Metrics
+	stats         cycleStats
+
+	batching  *cycleBatching // List page size and remediation workers, nil if disabled
+	budget    *actionBudget  // Remediation actions left in the current cycle, nil is unlimited
+	incidents *incidentMode  // Incidents relaxing cooldowns and the action budget
+	drift     *driftDetector // Configuration comparison with other instances, nil if disabled
+	policy    *config.Config // Configuration with namespace selectors expanded, nil until the first cycle
+	policies  *config.PolicyCache
+
+	scopeResolved bool // Detection scope resolved at least once
 }
 
 // NewController creates a new controller instance
@@ -35,60 +86,328 @@ func NewController(cfg *config.Config, metricsCollector *metrics.Metrics) (*Cont
 	if err != nil {
 		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
 	}
+	if cfg.Runtime.APIQPS > 0 {
+		config.QPS = cfg.Runtime.APIQPS
+	}
+	if cfg.Runtime.APIBurst > 0 {
+		config.Burst = cfg.Runtime.APIBurst
+	}
+	// Bound every API request, so a hung connection cannot stall a cycle
+	config.Wrap(newAPITimeouts(cfg.Runtime.APITimeouts, metricsCollector))
 
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 
+	ctrl, err := NewControllerWithClient(client, cfg, metricsCollector)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the resources of rules file rules with an apiVersion
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	ctrl.detector.SetDynamicClient(dynamicClient)
+
+	// Execute manually requested actions as the requesting user
+	if cfg.Remediation.ImpersonateRequester && ctrl.remediator != nil {
+		ctrl.remediator.SetImpersonation(impersonatingClientFactory(config, cfg.Remediation.ImpersonateGroups))
+	}
+
+	// Compare actual container usage against the CPU and memory thresholds
+	if cfg.Detection.MetricsServer.Enabled {
+		metricsClient, err := metricsclientset.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metrics client: %w", err)
+		}
+		ctrl.detector.SetMetricsClient(metricsClient, cfg.Detection.MetricsServer.RetryInterval)
+	}
+
+	return ctrl, nil
+}
+
+// NewControllerWithClient creates a new controller instance using the given Kubernetes client
+func NewControllerWithClient(client kubernetes.Interface, cfg *config.Config, metricsCollector *metrics.Metrics) (*Controller, error) {
+	schedules, err := cfg.ScheduleWindows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedules: %w", err)
+	}
+
+	flags, err := features.New(cfg.Features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feature flags: %w", err)
+	}
+	for _, status := range flags.List() {
+		metricsCollector.RecordFeatureEnabled(status.Name, status.Enabled)
+	}
+
+	state, err := newStateStore(client, stateConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	remediations, err := newIdempotencyStore(client, stateConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	lifecycle, err := newLifecycleStore(client, stateConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	ruleOverlay, err := newRuleOverlay(client, stateConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	activity, err := newDigestStats(client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	secretResolver := cfg.SecretResolver()
+	credentials, err := resolveCredentials(secretResolver, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions, err := newWorkloadExclusions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := newContainerRules(cfg.Detection.Containers)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create detector
 	detectionConfig := detection.DetectionConfig{
-		RulesFile:                 cfg.Detection.RulesFile,
-		EvaluationInterval:        cfg.Detection.EvaluationInterval,
-		CrashLoopThreshold:        cfg.Detection.CrashLoopThreshold,
-		FailedDeploymentThreshold: cfg.Detection.FailedDeploymentThreshold,
-		CPUThresholdPercent:       cfg.Detection.CPUThresholdPercent,
-		MemoryThresholdPercent:    cfg.Detection.MemoryThresholdPercent,
-		OOMKillThreshold:          cfg.Detection.OOMKillThreshold,
-		Namespaces:                convertConfigNamespaces(cfg.Detection.Namespaces),
+		RulesFile:                     cfg.Detection.RulesFile,
+		EvaluationInterval:            cfg.Detection.EvaluationInterval,
+		CrashLoopThreshold:            cfg.Detection.CrashLoopThreshold,
+		FailedDeploymentThreshold:     cfg.Detection.FailedDeploymentThreshold,
+		CPUThresholdPercent:           cfg.Detection.CPUThresholdPercent,
+		MemoryThresholdPercent:        cfg.Detection.MemoryThresholdPercent,
+		OOMKillThreshold:              cfg.Detection.OOMKillThreshold,
+		Namespaces:                    convertConfigNamespaces(cfg.NamespacePolicies()),
+		Schedules:                     schedules,
+		State:                         state,
+		Overrides:                     cfg.OverrideBounds(),
+		ChangeWindow:                  cfg.Detection.ChangeWindow,
+		Runbooks:                      cfg.Detection.Runbooks,
+		RuleMetadata:                  convertRuleMetadata(cfg.Detection.Rules),
+		Containers:                    containers,
+		EphemeralContainerMaxAge:      cfg.Detection.EphemeralContainerMaxAge,
+		StuckRolloutAfter:             cfg.Detection.StuckRolloutAfter,
+		StuckTerminatingAfter:         cfg.Detection.StuckTerminatingAfter,
+		SchedulingConflictAfter:       cfg.Detection.SchedulingConflictAfter,
+		ServiceWithoutEndpointsAfter:  cfg.Detection.ServiceWithoutEndpointsAfter,
+		ResourceQuotaThresholdPercent: cfg.Detection.ResourceQuota.ThresholdPercent,
+		Flapping:                      detection.FlappingConfig{RestartLimit: cfg.Detection.Flapping.RestartLimit, Window: cfg.Detection.Flapping.Window},
+		EvictedPods:                   detection.EvictedPods{Threshold: cfg.Detection.EvictedPods.Threshold, MinAge: cfg.Detection.EvictedPods.MinAge},
+		Finalizers:                    convertDetectionFinalizers(cfg.Remediation.Finalizers.Allowed),
+		StuckFinalizerAfter:           cfg.Remediation.Finalizers.StuckAfter,
+		NodeProblems:                  convertNodeProblems(cfg.Detection.NodeProblems),
+		NodeConditions:                convertNodeConditions(cfg.Detection.NodeConditions),
+		EventReasons:                  convertEventReasons(cfg.Detection.WarningEvents),
+		DefaultActions:                convertDefaultActions(cfg.Remediation.ActionDefaults.BySeverity),
+		PluginActions:                 pluginActions(cfg.Remediation.Plugins),
+		QueryRules:                    convertQueryRules(cfg.Detection.Prometheus.Rules),
+		DecisionLog: detection.DecisionLog{
+			Enabled:    cfg.Detection.DecisionLog.Enabled,
+			SampleRate: cfg.Detection.DecisionLog.SampleRate,
+			Size:       cfg.Detection.DecisionLog.Size,
+		},
+	}
+	if len(detectionConfig.QueryRules) > 0 {
+		prometheus := cfg.Detection.Prometheus
+		detectionConfig.Prometheus = detection.NewPrometheusClient(prometheus.URL, credentials["detection.prometheus.bearerToken"], prometheus.Timeout)
 	}
 	detector := detection.NewDetector(client, detectionConfig)
 	if err := detector.LoadRules(); err != nil {
 		return nil, fmt.Errorf("failed to load detection rules: %w", err)
 	}
+	for _, rule := range detector.Rules() {
+		metricsCollector.RecordRule(rule.Name, rule.Metadata.Owner, rule.Metadata.Source, rule.Metadata.LastModified)
+	}
 
-	// Create remediation engine
-	remediationConfig := remediation.RemediationConfig{
-		Enabled:             cfg.Remediation.Enabled,
-		MaxRetries:          cfg.Remediation.MaxRetries,
-		RetryInterval:       cfg.Remediation.RetryInterval,
-		DryRun:              cfg.Remediation.DryRun,
-		AutoRollbackEnabled: cfg.Remediation.AutoRollbackEnabled,
-		AutoScaleEnabled:    cfg.Remediation.AutoScaleEnabled,
-		CooldownSeconds:     cfg.Remediation.CooldownSeconds,
-		Namespaces:          convertRemediationNamespaces(cfg.Remediation.Namespaces),
+	// Create remediation engine. In observe mode the engine is never constructed
+	// so no write operations can reach the cluster.
+	var remediator *remediation.Engine
+	var webhookExecutor *remediation.WebhookExecutor
+	if !cfg.IsObserveMode() {
+		remediationConfig := remediation.RemediationConfig{
+			Enabled:             cfg.Remediation.Enabled,
+			MaxRetries:          cfg.Remediation.MaxRetries,
+			RetryInterval:       cfg.Remediation.RetryInterval,
+			DryRun:              cfg.Remediation.DryRun,
+			AutoRollbackEnabled: cfg.Remediation.AutoRollbackEnabled,
+			AutoScaleEnabled:    cfg.Remediation.AutoScaleEnabled,
+			CooldownSeconds:     cfg.Remediation.CooldownSeconds,
+			Namespaces:          convertRemediationNamespaces(namespaceRemediation(cfg.NamespacePolicies())),
+			FailureDomainAware:  cfg.Remediation.FailureDomainAware,
+			TopologyKey:         cfg.Remediation.TopologyKey,
+			Overrides:           cfg.OverrideBounds(),
+
+			ContainerRestartImage:   cfg.Remediation.ContainerRestartImage,
+			ContainerRestartTimeout: cfg.Remediation.ContainerRestartTimeout,
+			RollingRestartTimeout:   cfg.Remediation.RollingRestart.ReadyTimeout,
+			RollingRestartInterval:  cfg.Remediation.RollingRestart.PollInterval,
+			RestartMethod:           cfg.Remediation.PodRestart.Method,
+			EvictionTimeout:         cfg.Remediation.PodRestart.EvictionTimeout,
+			EvictionRetryInterval:   cfg.Remediation.PodRestart.RetryInterval,
+			EvictionFallback:        cfg.Remediation.PodRestart.FallbackToDelete,
+			DrainTimeout:            cfg.Remediation.NodeDrain.Timeout,
+			DrainGracePeriod:        cfg.Remediation.NodeDrain.GracePeriod,
+			DrainMaxNodesPerHour:    cfg.Remediation.NodeDrain.MaxNodesPerHour,
+			CleanupMaxPodsPerCycle:  cfg.Remediation.EvictedPodCleanup.MaxPodsPerCycle,
+			CleanupMinAge:           cfg.Detection.EvictedPods.MinAge,
+			PreChecks:               cfg.Remediation.PreChecks.Actions,
+			MaxAffectedPods:         cfg.Remediation.PreChecks.MaxAffectedPods,
+			HandshakeEnabled:        cfg.Remediation.PreRemediationHook.Enabled,
+			HandshakeAnnotation:     cfg.Remediation.PreRemediationHook.Annotation,
+			HandshakeGracePeriod:    cfg.Remediation.PreRemediationHook.GracePeriod,
+			Finalizers:              convertRemediationFinalizers(cfg.Remediation.Finalizers.Allowed),
+			Plugins:                 convertPlugins(cfg.Remediation.Plugins),
+			StrippedActions:         cfg.Remediation.ActionDefaults.Strip,
+		}
+		// The drain budget is kept with the state backend so restarts and leader
+		// failovers do not reset it
+		drainBackend, err := newStateBackend[map[string]int](client, stateConfig(cfg), remediation.DrainsConfigMapKey, "drains")
+		if err != nil {
+			return nil, err
+		}
+		remediationConfig.DrainBackend = drainBackend
+		remediator = remediation.NewEngine(client, remediationConfig)
+
+		switch cfg.Remediation.Executor.Type {
+		case remediation.ExecutorRecord:
+			remediator.SetExecutor(remediation.NewRecordExecutor())
+		case remediation.ExecutorWebhook:
+			webhookExecutor = remediation.NewWebhookExecutor(cfg.Remediation.Executor.URL, cfg.Remediation.Executor.Timeout, cfg.Remediation.DryRun)
+			webhookExecutor.SetSecret(credentials["remediation.executor.secret"])
+			remediator.SetExecutor(webhookExecutor)
+		}
+	}
+
+	var remediationQueue *queue.Queue
+	if remediator != nil {
+		if remediationQueue, err = newRemediationQueue(client, cfg); err != nil {
+			return nil, err
+		}
 	}
-	remediator := remediation.NewEngine(client, remediationConfig)
 
 	// Create Slack notifier if enabled
 	var slackNotifier *notification.SlackNotifier
 	if cfg.Notification.Slack.Enabled {
+		catalogConfig := cfg.Notification.Catalog
+		catalogConfig.BackstageToken = credentials["notification.catalog.backstageToken"]
+		resolver, err := newCatalog(client, catalogConfig)
+		if err != nil {
+			return nil, err
+		}
+
 		slackConfig := notification.SlackConfig{
-			Token:     cfg.Notification.Slack.Token,
-			Channel:   cfg.Notification.Slack.Channel,
-			Username:  cfg.Notification.Slack.Username,
-			IconEmoji: cfg.Notification.Slack.IconEmoji,
+			Enabled:          cfg.Notification.Slack.Enabled,
+			Token:            credentials["notification.slack.token"],
+			Channel:          cfg.Notification.Slack.Channel,
+			Username:         cfg.Notification.Slack.Username,
+			IconEmoji:        cfg.Notification.Slack.IconEmoji,
+			MinInterval:      cfg.Notification.Slack.MinInterval,
+			TeamChannels:     cfg.Notification.Slack.TeamChannels,
+			SeverityChannels: cfg.Notification.Slack.SeverityChannels,
+			Queue: notification.QueueConfig{
+				Size:           cfg.Notification.Queue.Size,
+				MaxRetries:     cfg.Notification.Queue.MaxRetries,
+				InitialBackoff: cfg.Notification.Queue.InitialBackoff,
+				MaxBackoff:     cfg.Notification.Queue.MaxBackoff,
+			},
+			Batch: notification.BatchConfig{
+				Enabled:      cfg.Notification.Batch.Enabled,
+				Window:       cfg.Notification.Batch.Window,
+				MinIssues:    cfg.Notification.Batch.MinIssues,
+				DashboardURL: cfg.Notification.Batch.DashboardURL,
+			},
+			Catalog: resolver,
+			Metrics: metricsCollector,
 		}
 		slackNotifier = notification.NewSlackNotifier(slackConfig)
 	}
 
+	var analyzer analysis.Analyzer
+	if cfg.Analysis.Endpoint != "" {
+		analyzer = analysis.NewLLMAnalyzer(analysis.LLMConfig{
+			Endpoint:  cfg.Analysis.Endpoint,
+			Model:     cfg.Analysis.Model,
+			APIKey:    credentials["analysis.apiKey"],
+			MaxTokens: cfg.Analysis.MaxTokens,
+		}, nil)
+	}
+
+	var exporter *export.Exporter
+	if cfg.Export.Enabled {
+		exporter = export.NewExporter(export.Config{
+			URL:           cfg.Export.URL,
+			Username:      credentials["export.username"],
+			Password:      credentials["export.password"],
+			APIKey:        credentials["export.apiKey"],
+			IndexPrefix:   cfg.Export.IndexPrefix,
+			BatchSize:     cfg.Export.BatchSize,
+			FlushInterval: cfg.Export.FlushInterval,
+			BufferSize:    cfg.Export.BufferSize,
+			Timeout:       cfg.Export.Timeout,
+		}, metricsCollector)
+	}
+	followRotations(secretResolver, cfg, slackNotifier, exporter, webhookExecutor)
+
+	digestConfig := cfg.Notification.Digest
+	digestConfig.SMTP.Password = credentials["notification.digest.smtp.password"]
+	digestReporter, err := newDigestReporter(activity, digestConfig, slackNotifier, metricsCollector)
+	if err != nil {
+		return nil, err
+	}
+	hygieneReporter, err := newHygieneReporter(client, cfg.Detection.Hygiene, slackNotifier, metricsCollector)
+	if err != nil {
+		return nil, err
+	}
+	watch, err := newObjectWatch(client, detector, cfg.Detection.Watch, metricsCollector)
+	if err != nil {
+		return nil, err
+	}
+	drift, err := newDriftDetector(client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Controller{
 		client:        client,
 		config:        cfg,
 		detector:      detector,
 		remediator:    remediator,
 		slackNotifier: slackNotifier,
+		notifications: notification.NewDeduplicator(cfg.Notification.RepeatInterval),
+		tracker:       newIssueTracker(cfg.Detection.Escalation),
+		remediations:  remediations,
+		queue:         remediationQueue,
+		lifecycle:     runLifecycle{store: lifecycle, minUptime: cfg.Notification.Lifecycle.MinUptime},
+		ruleOverlay:   ruleOverlay,
+		activity:      activity,
+		digest:        digestReporter,
+		hygiene:       hygieneReporter,
+		exclusions:    exclusions,
+		resyncs:       make(chan resyncCall),
+		watch:         watch,
+		rollouts:      newRolloutWatcher(client, cfg),
+		features:      flags,
+		analyzer:      analyzer,
+		collector:     analysis.NewCollector(client, cfg.Analysis.MaxEvents, int64(cfg.Analysis.LogLines)),
+		exporter:      exporter,
+		secrets:       secretResolver,
 		metrics:       metricsCollector,
+		batching:      newCycleBatching(cfg),
+		incidents:     newIncidentMode(),
+		drift:         drift,
+		policies:      config.NewPolicyCache(),
 	}, nil
 }
 
@@ -96,18 +415,65 @@ func NewController(cfg *config.Config, metricsCollector *metrics.Metrics) (*Cont
 func (c *Controller) Run(ctx context.Context) error {
 	logger := log.FromContext(ctx)
 
+	// Deliver queued notifications in the background
+	go c.slackNotifier.Run(ctx)
+
+	// Index issue and remediation documents in the background
+	go c.exporter.Run(ctx)
+
+	// Re-read credentials from secret stores to pick up rotations
+	go c.secrets.Run(ctx)
+
+	// Execute queued remediation actions in the background
+	go c.runQueue(ctx)
+
+	// Send scheduled digests in the background
+	go c.digest.Run(ctx)
+
+	// Send scheduled hygiene reports in the background
+	go c.hygiene.Run(ctx)
+
+	// Follow the rollouts started by rollback and restart actions
+	go c.rollouts.Run(ctx, c.reportRollout)
+
+	// Re-evaluate pods and deployments on their watch events
+	if c.watch != nil {
+		go c.watch.Run(ctx)
+	}
+
+	// Record this run; the startup is not notified while KubeGuardian is flapping
+	notifyStartup := c.startRun(ctx)
+	defer c.stopRun(ctx)
+
 	// Test Slack connection if enabled
 	if c.slackNotifier != nil {
 		if err := c.slackNotifier.TestConnection(ctx); err != nil {
 			logger.Error(err, "Slack connection test failed, continuing without Slack notifications")
-		} else {
+		} else if notifyStartup {
 			// Send startup notification
-			if err := c.slackNotifier.SendStartupNotification(ctx, "v1.0.0"); err != nil {
+			if err := c.slackNotifier.SendStartupNotification(ctx, version.Version); err != nil {
 				logger.Error(err, "Failed to send startup notification")
 			}
 		}
 	}
 
+	// Verify RBAC permissions before acting on the cluster
+	if report, err := c.CheckPermissions(ctx); err != nil {
+		logger.Error(err, "Permission preflight failed, continuing without it")
+	} else {
+		if !report.DetectionAllowed() {
+			logger.Info("Missing permissions required for detection, some rules will fail", "missing", requirementNames(report.MissingDetection()))
+		}
+		for feature, missing := range report.MissingFeatures() {
+			logger.Info("Missing permissions required by an enabled feature, it will fail", "feature", feature, "missing", requirementNames(missing))
+		}
+	}
+
+	// Disable actions the cluster version or its APIs do not support
+	if _, err := c.CheckCapabilities(ctx); err != nil {
+		logger.Error(err, "Capability probe failed, continuing without it")
+	}
+
 	// Start the main detection loop
 	ticker := time.NewTicker(c.config.Detection.EvaluationInterval)
 	defer ticker.Stop()
@@ -116,7 +482,10 @@ func (c *Controller) Run(ctx context.Context) error {
 	cleanupTicker := time.NewTicker(10 * time.Minute)
 	defer cleanupTicker.Stop()
 
-	logger.Info("KubeGuardian started", "evaluationInterval", c.config.Detection.EvaluationInterval)
+	c.running.Store(true)
+	defer c.running.Store(false)
+
+	logger.Info("KubeGuardian started", "evaluationInterval", c.config.Detection.EvaluationInterval, "observeMode", c.remediator == nil)
 
 	for {
 		select {
@@ -124,51 +493,322 @@ func (c *Controller) Run(ctx context.Context) error {
 			logger.Info("KubeGuardian stopping")
 			return nil
 		case <-ticker.C:
-			if err := c.runDetectionCycle(ctx); err != nil {
+			if _, err := c.runDetectionCycle(ctx, cycleScope{}); err != nil {
 				logger.Error(err, "Detection cycle failed")
 			}
+			c.establishRun(ctx)
+		case call := <-c.resyncs:
+			call.done <- c.resync(ctx, call.req)
+		case event := <-c.objectEvents():
+			c.detectObject(ctx, event)
 		case <-cleanupTicker.C:
-			c.remediator.CleanupCooldowns()
+			if c.remediator != nil {
+				c.remediator.CleanupCooldowns()
+			}
 		}
 	}
 }
 
-// runDetectionCycle runs a single detection and remediation cycle
-func (c *Controller) runDetectionCycle(ctx context.Context) error {
+// runDetectionCycle runs a single detection and remediation cycle limited to a scope
+func (c *Controller) runDetectionCycle(ctx context.Context, scope cycleScope) (cycleSummary, error) {
+	// Correlate the logs and metric exemplars of the cycle, and the actions it
+	// queues, with a trace ID
+	if c.config.Tracing.Enabled {
+		ctx = tracing.Start(ctx)
+	}
 	logger := log.FromContext(ctx)
 	start := time.Now()
-	logger.Info("Starting detection cycle")
+	if scope.object() {
+		logger.V(1).Info("Starting object detection cycle", "kind", scope.Kind, "namespace", scope.Namespace, "name", scope.Name)
+	} else {
+		logger.Info("Starting detection cycle", "namespace", scope.Namespace, "rule", scope.Rule)
+	}
+
+	// Size the next cycles to the latency of full cycles, and report them on the
+	// health endpoint
+	issueCount := 0
+	if scope.all() {
+		defer func() {
+			c.observeCycle(ctx, time.Since(start))
+			c.stats.recordCycle(start, time.Since(start), issueCount)
+		}()
+	}
+
+	// Apply namespace selectors and the detection scope to the namespaces that
+	// currently exist, and revert expired incidents
+	if !scope.object() {
+		c.expandNamespaceSelectors(ctx)
+		c.expireIncidents(ctx)
+		if err := c.resolveNamespaceScope(ctx); err != nil {
+			return cycleSummary{}, err
+		}
+	}
+
+	// Restore first-seen times of duration-based conditions persisted before a restart
+	if err := c.detector.State().Load(ctx); err != nil {
+		logger.Error(err, "Failed to load rule evaluation state")
+		c.metrics.RecordStateStoreError("load")
+	}
+	if err := c.remediations.Load(ctx); err != nil {
+		logger.Error(err, "Failed to load remediation idempotency keys")
+		c.metrics.RecordStateStoreError("load")
+	}
+	if c.queue != nil {
+		if err := c.queue.Load(ctx); err != nil {
+			logger.Error(err, "Failed to load remediation queue")
+			c.metrics.RecordStateStoreError("load")
+		}
+	}
+
+	// Keep rules disabled at runtime before a restart disabled
+	c.applyRuleOverlay(ctx)
 
 	// Detect issues
-	issues, err := c.detector.DetectIssues(ctx)
+	c.applyBatching()
+	var issues []detection.Issue
+	var err error
+	if scope.object() {
+		issues, err = c.detector.DetectObject(ctx, scope.Kind, scope.Namespace, scope.Name)
+	} else {
+		issues, err = c.detector.DetectIssues(ctx)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to detect issues: %w", err)
+		return cycleSummary{}, fmt.Errorf("failed to detect issues: %w", err)
 	}
+	if !scope.object() {
+		for _, count := range c.detector.DecisionCounts() {
+			c.metrics.RecordRuleDecisions(count.Rule, count.Outcome, count.Condition, count.Count)
+		}
+	}
+	// Object cycles leave persisting the state and comparing configurations to
+	// the full cycles, so a burst of watch events does not write them for every object
+	if !scope.object() {
+		c.syncState(ctx)
+		issues = append(issues, c.detectConfigDrift(ctx)...)
+	}
+	issues = scope.filter(c.excludeIssues(ctx, issues))
+	issueCount = len(issues)
 
 	// Record detection metrics
 	c.metrics.UpdateLastDetectionTime()
-	c.metrics.RecordDetectionDuration("detection_cycle", time.Since(start))
+	if scope.object() {
+		c.metrics.RecordDetectionDuration(ctx, "object_cycle", time.Since(start))
+	} else {
+		c.metrics.RecordDetectionDuration(ctx, "detection_cycle", time.Since(start))
+	}
+
+	// Forget notifications for issues that are no longer detected
+	if scope.all() {
+		active := make(map[string]bool, len(issues))
+		for _, issue := range issues {
+			active[issue.Fingerprint()] = true
+		}
+		c.notifications.Prune(active)
+	}
+
+	// Classify issues by transition so only new issues and active issues due for
+	// re-verification are processed; forced cycles re-verify all active issues
+	reverifyInterval := c.config.Detection.ReverifyInterval
+	if scope.Forced {
+		reverifyInterval = 0
+	}
+	transitions := c.tracker.DiffWithin(issues, time.Now(), reverifyInterval, scope.includes)
+	summary := cycleSummary{Issues: len(issues), New: len(transitions.New), Resolved: len(transitions.Resolved)}
+	c.metrics.RecordIssueTransitions(tracker.TransitionNew, len(transitions.New))
+	c.metrics.RecordIssueTransitions(tracker.TransitionActive, len(transitions.Reverify)+transitions.Unchanged)
+	c.metrics.RecordIssueTransitions(tracker.TransitionResolved, len(transitions.Resolved))
+	c.escalateIssues(ctx, transitions.Escalated)
+	c.metrics.RecordInterventionRequired(c.tracker.InterventionRequired())
+
+	// Record lifecycle metrics and announce issues that are no longer detected
+	c.recordResolvedActivity(transitions.Resolved)
+	for _, record := range transitions.Resolved {
+		logger.Info("Issue resolved",
+			"rule", record.RuleName,
+			"resource", record.Name,
+			"namespace", record.Namespace,
+			"outcome", record.Outcome(),
+			"duration", record.TimeToResolution())
+		c.metrics.RecordIssueResolved(ctx, record.RuleName, record.Namespace, record.Outcome(), record.TimeToResolution())
+		c.exporter.ExportResolved(ctx, record)
+
+		if c.slackNotifier != nil {
+			if err := c.slackNotifier.SendResolvedNotification(ctx, record); err != nil {
+				logger.Error(err, "Failed to send resolved notification")
+				c.metrics.RecordNotification("resolved", "failed")
+			} else {
+				c.metrics.RecordNotification("resolved", "success")
+			}
+		}
+	}
 
 	if len(issues) == 0 {
 		logger.Info("No issues detected")
-		return nil
+		return summary, nil
 	}
 
-	logger.Info("Issues detected", "count", len(issues))
+	logger.Info("Issues detected",
+		"count", len(issues),
+		"new", len(transitions.New),
+		"reverify", len(transitions.Reverify),
+		"unchanged", transitions.Unchanged)
 
-	// Record metrics for each detected issue
-	for _, issue := range issues {
+	// Record metrics for newly detected issues
+	for _, issue := range transitions.New {
 		c.metrics.RecordIssueDetected(issue.RuleName, issue.Severity, issue.Namespace)
 	}
+	c.recordDetectedActivity(transitions.New)
+
+	detected := issues
+	issues = transitions.Actionable()
+	if len(issues) == 0 {
+		return summary, nil
+	}
+
+	// Recent rollouts are the usual cause of new failures
+	c.detector.CorrelateChanges(ctx, issues)
 
-	// Process each issue
-	for _, issue := range issues {
-		if err := c.processIssue(ctx, issue); err != nil {
-			logger.Error(err, "Failed to process issue", "rule", issue.RuleName, "resource", issue.Name)
+	// Diagnose new issues only; they come first in the actionable issues
+	c.analyzeIssues(ctx, issues[:len(transitions.New)])
+	for _, issue := range issues[:len(transitions.New)] {
+		c.exporter.ExportIssue(ctx, issue)
+	}
+
+	// Remediate critical workloads first, within the per-cycle action budget, with
+	// one workload action for Deployments whose pods all fail
+	if c.remediator != nil {
+		issues = c.prioritizeIssues(ctx, c.batchIssues(ctx, detected, issues))
+		c.budget = newActionBudget(c.config.Remediation.Priority.MaxActionsPerCycle)
+		c.remediator.ResetCleanupBudget()
+	}
+
+	// Process each issue, concurrently once cycles run slow
+	c.processIssues(ctx, issues)
+
+	return summary, nil
+}
+
+// expandNamespaceSelectors resolves namespace selectors against the cluster's namespaces
+// and updates the namespace settings of the detector and remediation engine. The
+// settings are only rebuilt when a namespace was created, deleted or relabelled.
+func (c *Controller) expandNamespaceSelectors(ctx context.Context) {
+	if len(c.config.NamespaceSelectors) == 0 {
+		return
+	}
+
+	namespaces, err := c.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list namespaces for namespace selectors, keeping previous settings")
+		return
+	}
+
+	labels := make(map[string]map[string]string, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		labels[ns.Name] = ns.Labels
+	}
+
+	policy, changed := c.policies.Expand(c.config, labels)
+	if !changed {
+		return
+	}
+	log.FromContext(ctx).V(1).Info("Namespace policies changed, updating namespace settings", "namespaces", len(labels))
+
+	c.policy = policy
+	policies := c.policy.NamespacePolicies()
+	c.detector.SetNamespaces(convertConfigNamespaces(policies))
+	if c.remediator != nil {
+		c.remediator.SetNamespaces(convertRemediationNamespaces(namespaceRemediation(policies)))
+	}
+}
+
+// namespacePolicy returns the configuration used for namespace-specific decisions
+func (c *Controller) namespacePolicy() *config.Config {
+	if c.policy != nil {
+		return c.policy
+	}
+	return c.config
+}
+
+// syncState expires conditions that are no longer observed and persists the rest
+func (c *Controller) syncState(ctx context.Context) {
+	state := c.detector.State()
+
+	expired := 0
+	if ttl := c.config.Detection.State.TTL; ttl > 0 {
+		expired = state.Expire(time.Now().Add(-ttl))
+	}
+	c.metrics.RecordConditionStates(state.Len(), expired)
+
+	if err := state.Flush(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to save rule evaluation state")
+		c.metrics.RecordStateStoreError("save")
+	}
+
+	if c.remediations.Expire(time.Now().Add(-idempotencyRetention)) > 0 {
+		if err := c.remediations.Flush(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to save remediation idempotency keys")
+			c.metrics.RecordStateStoreError("save")
 		}
 	}
+}
 
-	return nil
+// newStateStore creates the store for rule evaluation state from configuration
+func newStateStore(client kubernetes.Interface, cfg config.StateConfig) (*detection.StateStore, error) {
+	backend, err := newStateBackend[map[string]detection.ConditionState](client, cfg, detection.StateConfigMapKey, "")
+	if err != nil {
+		return nil, err
+	}
+	return detection.NewStateStore(backend), nil
+}
+
+// stateConfig returns the state backend of the stores. Observe mode makes no
+// write operations against the cluster, so its stores are kept in memory instead
+// of the state ConfigMap; the file backend is still used.
+func stateConfig(cfg *config.Config) config.StateConfig {
+	state := cfg.Detection.State
+	if cfg.IsObserveMode() && state.Backend == detection.StateBackendConfigMap {
+		state.Backend = detection.StateBackendMemory
+	}
+	return state
+}
+
+// newStateBackend creates the backend persisting a store with the configured
+// state backend: under key in the state ConfigMap, or in a file next to the
+// state file with suffix appended to its name. It returns nil for the memory
+// backend, which keeps the store in memory only.
+func newStateBackend[T any](client kubernetes.Interface, cfg config.StateConfig, key, suffix string) (detection.Backend[T], error) {
+	switch cfg.Backend {
+	case "", detection.StateBackendMemory:
+		return nil, nil
+	case detection.StateBackendConfigMap:
+		return detection.NewConfigMapValueBackend[T](client, cfg.ConfigMapNamespace, cfg.ConfigMapName, key), nil
+	case detection.StateBackendFile:
+		path := cfg.Path
+		if suffix != "" {
+			ext := filepath.Ext(path)
+			path = strings.TrimSuffix(path, ext) + "-" + suffix + ext
+		}
+		return detection.NewFileValueBackend[T](path), nil
+	default:
+		return nil, fmt.Errorf("unknown state backend: %s", cfg.Backend)
+	}
+}
+
+// newCatalog creates the cached service catalog resolver from configuration, or
+// nil if catalog lookups are disabled
+func newCatalog(client kubernetes.Interface, cfg config.CatalogConfig) (catalog.Resolver, error) {
+	var resolver catalog.Resolver
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case catalog.BackendConfigMap:
+		resolver = catalog.NewConfigMapResolver(client, cfg.ConfigMapNamespace, cfg.ConfigMapName)
+	case catalog.BackendBackstage:
+		resolver = catalog.NewBackstageResolver(cfg.BackstageURL, cfg.BackstageToken, &http.Client{Timeout: cfg.Timeout})
+	default:
+		return nil, fmt.Errorf("unknown catalog backend: %s", cfg.Backend)
+	}
+	return catalog.NewCache(resolver, cfg.TTL), nil
 }
 
 // processIssue processes a single detected issue
@@ -176,53 +816,544 @@ func (c *Controller) processIssue(ctx context.Context, issue detection.Issue) er
 	logger := log.FromContext(ctx)
 	logger.Info("Processing issue", "rule", issue.RuleName, "resource", issue.Name, "severity", issue.Severity)
 
-	// Send issue notification
-	if c.slackNotifier != nil {
+	// Send issue notification, at most once per repeat interval for an unresolved issue
+	if c.slackNotifier != nil && c.shouldNotify(issue) {
 		if err := c.slackNotifier.SendIssueNotification(ctx, issue); err != nil {
 			logger.Error(err, "Failed to send issue notification")
 			c.metrics.RecordNotification("issue", "failed")
+			c.notifications.Forget(issue.Fingerprint())
 		} else {
 			c.metrics.RecordNotification("issue", "success")
+			c.recordNotificationActivity(issue)
 		}
 	}
 
-	// Execute remediation actions
+	// Skip remediation entirely in observe mode
+	if c.remediator == nil {
+		logger.Info("Observe mode: skipping remediation actions", "actions", issue.Actions, "resource", issue.Name)
+		return nil
+	}
+
+	// Issues below the severity floor are only notified
+	if policy := c.namespacePolicy(); !policy.MeetsRemediationSeverity(issue.Namespace, issue.Severity) {
+		logger.Info("Issue severity below remediation floor: skipping remediation actions",
+			"severity", issue.Severity,
+			"minSeverity", policy.RemediationSeverityFloor(issue.Namespace),
+			"actions", issue.Actions,
+			"resource", issue.Name)
+		return nil
+	}
+
+	// Excluded workloads are only notified
+	if c.excluded(ctx, stageRemediation, c.exclusions.remediation, issue) {
+		return nil
+	}
+
+	// Remediation that failed is retried with exponential backoff, and not at
+	// all once it failed more often than the retries allow
+	nsConfig := c.remediator.GetNamespaceConfig(issue.Namespace)
+	if record, exists := c.tracker.Get(issue.Fingerprint()); exists {
+		if record.InterventionRequired {
+			logger.Info("Remediation failed repeatedly and requires human intervention: skipping remediation actions",
+				"failures", record.ConsecutiveFailures,
+				"actions", issue.Actions,
+				"resource", issue.Name)
+			return nil
+		}
+		if next := record.NextRetry(nsConfig.RetryInterval); time.Now().Before(next) {
+			logger.V(1).Info("Remediation failed: backing off before retrying",
+				"failures", record.ConsecutiveFailures,
+				"retryAt", next,
+				"resource", issue.Name)
+			return nil
+		}
+	}
+
+	// Execute remediation actions; during an incident the incident's cooldown
+	// applies and the per-cycle action budget does not
+	cooldown := time.Duration(nsConfig.CooldownSeconds) * time.Second
+	incident, relaxed := c.incidents.active(issue, time.Now())
+	if relaxed {
+		cooldown = time.Duration(incident.CooldownSeconds) * time.Second
+	}
 	for _, action := range issue.Actions {
-		logger.Info("Executing remediation action", "action", action, "resource", issue.Name)
-		start := time.Now()
+		if c.remediator.IsActionStripped(action) {
+			logger.Info("Remediation action stripped by the action policy: skipping",
+				"action", action,
+				"resource", issue.Name)
+			continue
+		}
+		if flag, gated := c.actionGated(action); gated {
+			logger.Info("Remediation action requires a disabled feature: skipping",
+				"action", action,
+				"feature", flag,
+				"resource", issue.Name)
+			continue
+		}
+		if action == remediation.ActionForceDeletePod && !nsConfig.ForceDeleteEnabled {
+			logger.Info("Namespace has not opted in to force deleting pods: skipping",
+				"action", action,
+				"resource", issue.Name,
+				"namespace", issue.Namespace)
+			continue
+		}
 
-		result, err := c.remediator.ExecuteAction(ctx, action, issue.Resource, issue.Namespace)
-		if err != nil {
-			logger.Error(err, "Failed to execute remediation action", "action", action)
-			c.metrics.RecordRemediation(action, "error", issue.Namespace, time.Since(start))
-			// Continue with other actions even if one fails
+		// Never execute the same action for the same issue and generation twice within
+		// the cooldown, even across restarts and leader failovers
+		key := idempotencyKey(issue, action)
+		if !c.claimRemediation(ctx, key, cooldown) {
+			logger.Info("Remediation action already executed for this issue: skipping",
+				"action", action,
+				"resource", issue.Name,
+				"namespace", issue.Namespace,
+				"key", key)
 			continue
 		}
 
-		// Only send notification if result is not nil
-		if result != nil {
-			// Record remediation metrics
-			status := "success"
-			if !result.Success {
-				status = "failed"
+		if !relaxed && !c.budget.take() {
+			c.releaseRemediation(ctx, key)
+			logger.Info("Remediation budget exhausted for this cycle: deferring actions",
+				"actions", issue.Actions,
+				"resource", issue.Name,
+				"namespace", issue.Namespace)
+			return nil
+		}
+
+		// Queued actions are executed by the queue worker, and retried there
+		if c.queue != nil {
+			c.enqueueRemediation(ctx, issue, action, key)
+			continue
+		}
+
+		// Continue with other actions even if one fails
+		if err := c.executeRemediation(ctx, issue, action); err != nil {
+			c.releaseRemediation(ctx, key)
+		}
+	}
+
+	return nil
+}
+
+// errRemediationSkipped is returned by executeRemediation for actions the engine
+// deliberately did not apply, e.g. during their cooldown
+var errRemediationSkipped = errors.New("remediation action skipped")
+
+// executeRemediation executes a remediation action for an issue, then records and
+// notifies its result. It returns an error if the action failed, did not succeed
+// or was skipped; only failures count towards the retries of the issue.
+func (c *Controller) executeRemediation(ctx context.Context, issue detection.Issue, action string) error {
+	logger := log.FromContext(ctx)
+	logger.Info("Executing remediation action", "action", action, "resource", issue.Name)
+	start := time.Now()
+
+	ctx = remediation.WithContainer(ctx, issue.Container)
+	if incident, relaxed := c.incidents.active(issue, start); relaxed {
+		ctx = remediation.WithCooldown(ctx, incident.CooldownSeconds)
+	}
+	result, err := c.remediator.ExecuteAction(ctx, action, issue.Resource, issue.Namespace)
+	if err != nil {
+		logger.Error(err, "Failed to execute remediation action", "action", action)
+		c.metrics.RecordRemediation(ctx, action, "error", issue.Namespace, time.Since(start))
+		c.tracker.RecordRemediation(issue.Fingerprint(), false, time.Now())
+		c.recordRemediationActivity(issue, false)
+		c.recordRemediationFailure(ctx, issue, action, err.Error())
+		return err
+	}
+
+	// Only send notification if result is not nil
+	if result == nil {
+		return nil
+	}
+	for _, check := range result.PreChecks {
+		c.metrics.RecordPreCheck(action, check.Name, check.Outcome)
+	}
+
+	// Actions held for approval keep their idempotency key, so the approval is
+	// asked for once per cooldown
+	if result.PendingApproval {
+		c.metrics.RecordRemediation(ctx, action, "pending_approval", issue.Namespace, time.Since(start))
+		c.notifyRemediation(ctx, issue, *result)
+		logger.Info("Remediation action awaits approval", "action", action, "message", result.Message)
+		return nil
+	}
+
+	// Skipped actions are not failures: they neither back off the retries of the
+	// issue nor escalate it to a human
+	if result.Skipped {
+		c.metrics.RecordRemediation(ctx, action, "skipped", issue.Namespace, time.Since(start))
+		logger.Info("Remediation action skipped", "action", action, "reason", result.Message)
+		return fmt.Errorf("%w: %s", errRemediationSkipped, result.Message)
+	}
+
+	// Record remediation metrics
+	status := "success"
+	if !result.Success {
+		status = "failed"
+	}
+	c.metrics.RecordRemediation(ctx, action, status, issue.Namespace, time.Since(start))
+	c.recordRemediationActivity(issue, result.Success)
+
+	if record, first := c.tracker.RecordRemediation(issue.Fingerprint(), result.Success, time.Now()); first {
+		c.metrics.RecordIssueRemediated(ctx, issue.RuleName, issue.Namespace, record.TimeToRemediation())
+	}
+	c.exporter.ExportRemediation(ctx, issue, *result)
+	if result.Success {
+		c.notifyRemediation(ctx, issue, *result)
+		c.rollouts.follow(ctx, issue, *result)
+	} else {
+		c.recordRemediationFailure(ctx, issue, action, result.Message)
+	}
+
+	logger.Info("Remediation action completed", "action", action, "success", result.Success, "message", result.Message)
+	if !result.Success {
+		return fmt.Errorf("remediation action %s did not succeed: %s", action, result.Message)
+	}
+	return nil
+}
+
+// notifyRemediation sends the remediation notification of an action
+func (c *Controller) notifyRemediation(ctx context.Context, issue detection.Issue, result remediation.Result) {
+	if c.slackNotifier == nil {
+		return
+	}
+	if err := c.slackNotifier.SendRemediationNotification(ctx, issue, result); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send remediation notification")
+		c.metrics.RecordNotification("remediation", "failed")
+	} else {
+		c.metrics.RecordNotification("remediation", "success")
+		c.recordNotificationActivity(issue)
+	}
+}
+
+// recordRemediationFailure records a failed remediation attempt in the tracker
+// and notifies it with the failures so far and when remediation is retried, or
+// that the issue requires human intervention once the retries are used up
+func (c *Controller) recordRemediationFailure(ctx context.Context, issue detection.Issue, action, message string) {
+	logger := log.FromContext(ctx)
+	nsConfig := c.remediator.GetNamespaceConfig(issue.Namespace)
+
+	now := time.Now()
+	record, escalated := c.tracker.RecordFailure(issue.Fingerprint(), tracker.Failure{Action: action, Message: message, At: now}, nsConfig.MaxRetries)
+	failure := notification.RemediationFailure{
+		Action:               action,
+		Message:              message,
+		Attempts:             max(record.ConsecutiveFailures, 1),
+		MaxRetries:           nsConfig.MaxRetries,
+		History:              record.Failures,
+		InterventionRequired: record.InterventionRequired,
+	}
+	if !record.InterventionRequired {
+		failure.NextRetry = record.NextRetry(nsConfig.RetryInterval)
+	}
+	if escalated {
+		logger.Info("Remediation failed more often than the retries allow: issue requires human intervention",
+			"failures", record.ConsecutiveFailures,
+			"maxRetries", nsConfig.MaxRetries,
+			"resource", issue.Name,
+			"namespace", issue.Namespace)
+	}
+
+	if c.slackNotifier == nil {
+		return
+	}
+	if err := c.slackNotifier.SendRemediationFailure(ctx, issue, failure); err != nil {
+		logger.Error(err, "Failed to send remediation failure notification")
+		c.metrics.RecordNotification("remediation", "failed")
+	} else {
+		c.metrics.RecordNotification("remediation", "success")
+		c.recordNotificationActivity(issue)
+	}
+}
+
+// shouldNotify returns true if a notification for the issue is due
+func (c *Controller) shouldNotify(issue detection.Issue) bool {
+	if !c.notifications.ShouldNotify(issue.Fingerprint(), time.Now()) {
+		c.metrics.RecordNotification("issue", "suppressed")
+		return false
+	}
+	return true
+}
+
+// CheckPermissions verifies the RBAC permissions needed for detection and for the
+// actions of all enabled rules. Actions with missing permissions are disabled in the
+// remediation engine instead of failing at remediation time.
+func (c *Controller) CheckPermissions(ctx context.Context) (*permissions.Report, error) {
+	logger := log.FromContext(ctx)
+
+	report, err := permissions.NewChecker(c.client).Check(ctx, c.enabledActions(), c.enabledFeatures())
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.appliesActions() {
+		return report, nil
+	}
+
+	for action, missing := range report.MissingActions() {
+		names := requirementNames(missing)
+		logger.Info("Disabling action due to missing permissions", "action", action, "missing", names)
+		c.remediator.DisableAction(action, fmt.Sprintf("missing permissions: %s", strings.Join(names, ", ")))
+	}
+
+	return report, nil
+}
+
+// appliesActions returns true if remediation actions are applied through the
+// Kubernetes API, rather than not at all in observe mode or by another system
+func (c *Controller) appliesActions() bool {
+	executor := c.config.Remediation.Executor.Type
+	return c.remediator != nil && (executor == "" || executor == remediation.ExecutorClient)
+}
+
+// enabledActions returns the distinct actions referenced by enabled detection rules,
+// including the actions of their container rules and the batch action; stripped
+// actions are left out since they never run
+func (c *Controller) enabledActions() []string {
+	seen := make(map[string]bool)
+	var actions []string
+	add := func(names []string) {
+		for _, action := range names {
+			if !seen[action] && !slices.Contains(c.config.Remediation.ActionDefaults.Strip, action) {
+				seen[action] = true
+				actions = append(actions, action)
 			}
-			c.metrics.RecordRemediation(action, status, issue.Namespace, time.Since(start))
-
-			// Send remediation notification
-			if c.slackNotifier != nil {
-				if err := c.slackNotifier.SendRemediationNotification(ctx, issue, *result); err != nil {
-					logger.Error(err, "Failed to send remediation notification")
-					c.metrics.RecordNotification("remediation", "failed")
-				} else {
-					c.metrics.RecordNotification("remediation", "success")
+		}
+	}
+
+	for _, rule := range c.detector.Rules() {
+		if !rule.Enabled {
+			continue
+		}
+		add(rule.Actions)
+		for _, container := range rule.Containers {
+			add(container.Actions)
+		}
+	}
+	if c.config.Remediation.Batch.Enabled {
+		add([]string{c.config.Remediation.Batch.Action})
+	}
+	problems := convertNodeProblems(c.config.Detection.NodeProblems)
+	conditions := convertNodeConditions(c.config.Detection.NodeConditions)
+	for _, problem := range slices.Concat(problems.Conditions, problems.Events, conditions) {
+		add(problem.Actions)
+	}
+	for _, reason := range convertEventReasons(c.config.Detection.WarningEvents) {
+		add(reason.Actions)
+	}
+	return actions
+}
+
+// enabledFeatures returns the permission requirements of the enabled features
+// beyond detection and the actions themselves: rules reading further resources,
+// the pre-checks of the enabled actions, the pre-remediation handshake and the
+// ConfigMaps kept by the state backend and configuration drift detection
+func (c *Controller) enabledFeatures() map[string][]permissions.Requirement {
+	features := make(map[string][]permissions.Requirement)
+	for _, rule := range c.detector.Rules() {
+		if reqs, exists := permissions.RuleRequirements[rule.Name]; exists && rule.Enabled {
+			features["rule "+rule.Name] = reqs
+		}
+	}
+
+	if c.appliesActions() {
+		for _, action := range c.enabledActions() {
+			for _, name := range c.remediator.PreChecks(action) {
+				if reqs, exists := permissions.PreCheckRequirements[name]; exists {
+					features["pre-check "+name] = reqs
 				}
 			}
+		}
+		handshake := c.config.Remediation.PreRemediationHook.Enabled
+		for _, nsConfig := range c.config.NamespacePolicies() {
+			handshake = handshake || nsConfig.Remediation.HandshakeEnabled
+		}
+		if handshake {
+			features["pre-remediation hook"] = permissions.HandshakeRequirements
+		}
+	}
+
+	if state := stateConfig(c.config); state.Backend == detection.StateBackendConfigMap {
+		features["state"] = permissions.ConfigMapRequirements(state.ConfigMapNamespace)
+	}
+	if drift := c.config.Detection.ConfigDrift; drift.Enabled && !c.config.IsObserveMode() {
+		features["config drift"] = permissions.ConfigMapRequirements(drift.ConfigMapNamespace)
+	}
+	return features
+}
+
+// requirementNames converts permission requirements to their string form
+func requirementNames(reqs []permissions.Requirement) []string {
+	names := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		names = append(names, req.String())
+	}
+	return names
+}
+
+// convertRuleMetadata converts configured rule metadata to detection rule metadata
+func convertRuleMetadata(rules map[string]config.RuleMetadataConfig) map[string]detection.RuleMetadata {
+	metadata := make(map[string]detection.RuleMetadata, len(rules))
+	for name, rule := range rules {
+		metadata[name] = detection.RuleMetadata{Owner: rule.Owner, LastModified: rule.LastModified}
+	}
+	return metadata
+}
+
+// convertDetectionFinalizers converts the allowed finalizers for the stuck-finalizer rule
+func convertDetectionFinalizers(finalizers []config.AllowedFinalizerConfig) []detection.AllowedFinalizer {
+	result := make([]detection.AllowedFinalizer, 0, len(finalizers))
+	for _, finalizer := range finalizers {
+		result = append(result, detection.AllowedFinalizer{Name: finalizer.Name, Kinds: finalizer.Kinds})
+	}
+	return result
+}
+
+// convertRemediationFinalizers converts the allowed finalizers for the
+// remove-finalizer action; finalizers without a mode need approval
+func convertRemediationFinalizers(finalizers []config.AllowedFinalizerConfig) []remediation.AllowedFinalizer {
+	result := make([]remediation.AllowedFinalizer, 0, len(finalizers))
+	for _, finalizer := range finalizers {
+		result = append(result, remediation.AllowedFinalizer{
+			Name:      finalizer.Name,
+			Kinds:     finalizer.Kinds,
+			Automatic: finalizer.Mode == remediation.FinalizerModeAutomatic,
+		})
+	}
+	return result
+}
+
+// convertPlugins converts the remediation actions implemented by external programs
+func convertPlugins(plugins []config.PluginConfig) []remediation.Plugin {
+	result := make([]remediation.Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		result = append(result, remediation.Plugin{
+			Name:           plugin.Name,
+			Command:        plugin.Command,
+			Kinds:          plugin.Kinds,
+			Env:            plugin.Env,
+			Timeout:        plugin.Timeout,
+			MaxOutputBytes: plugin.MaxOutputBytes,
+			MaxMemoryBytes: int64(plugin.MaxMemoryMB) << 20,
+			MaxCPUTime:     plugin.MaxCPUTime,
+		})
+	}
+	return result
+}
+
+// pluginActions returns the names of the actions implemented by plugins
+func pluginActions(plugins []config.PluginConfig) []string {
+	names := make([]string, 0, len(plugins))
+	for _, plugin := range plugins {
+		names = append(names, plugin.Name)
+	}
+	return names
+}
+
+// convertNodeProblems converts the node problems of the node problem rule
+func convertNodeProblems(cfg config.NodeProblemsConfig) detection.NodeProblems {
+	var problems detection.NodeProblems
+	if !cfg.Enabled {
+		return problems
+	}
+	problems.Conditions = convertNodeProblemEntries(cfg.Conditions)
+	problems.Events = convertNodeProblemEntries(cfg.Events)
+	return problems
+}
+
+// convertNodeConditions converts the kubelet conditions of the node condition rule
+func convertNodeConditions(cfg config.NodeConditionsConfig) []detection.NodeProblem {
+	if !cfg.Enabled {
+		return nil
+	}
+	return convertNodeProblemEntries(cfg.Conditions)
+}
+
+// convertNodeProblemEntries converts node condition types or event reasons;
+// notify-only actions are dropped
+func convertNodeProblemEntries(entries []config.NodeProblemConfig) []detection.NodeProblem {
+	result := make([]detection.NodeProblem, 0, len(entries))
+	for _, entry := range entries {
+		var actions []string
+		for _, action := range entry.Actions {
+			if action != detection.NotifyOnlyAction {
+				actions = append(actions, action)
+			}
+		}
+		result = append(result, detection.NodeProblem{Type: entry.Type, Severity: entry.Severity, Actions: actions, For: entry.For})
+	}
+	return result
+}
+
+// convertDefaultActions converts the default actions by severity; notify-only
+// leaves rules of the severity without actions
+func convertDefaultActions(bySeverity map[string][]string) map[string][]string {
+	if len(bySeverity) == 0 {
+		return nil
+	}
+	result := make(map[string][]string, len(bySeverity))
+	for severity, actions := range bySeverity {
+		result[severity] = []string{}
+		for _, action := range actions {
+			if action != detection.NotifyOnlyAction {
+				result[severity] = append(result[severity], action)
+			}
+		}
+	}
+	return result
+}
 
-			logger.Info("Remediation action completed", "action", action, "success", result.Success, "message", result.Message)
+// convertEventReasons converts the event reasons of the warning event rule;
+// notify-only actions are dropped
+func convertEventReasons(cfg config.WarningEventsConfig) []detection.EventReason {
+	if !cfg.Enabled {
+		return nil
+	}
+	result := make([]detection.EventReason, 0, len(cfg.Reasons))
+	for _, entry := range cfg.Reasons {
+		var actions []string
+		for _, action := range entry.Actions {
+			if action != detection.NotifyOnlyAction {
+				actions = append(actions, action)
+			}
 		}
+		result = append(result, detection.EventReason{
+			Reason:   entry.Reason,
+			Severity: entry.Severity,
+			Actions:  actions,
+			MinCount: entry.MinCount,
+			Within:   entry.Within,
+		})
 	}
+	return result
+}
 
-	return nil
+// convertQueryRules converts the configured query rules to detection rules with a
+// single PromQL condition
+func convertQueryRules(rules []config.QueryRuleConfig) []detection.Rule {
+	result := make([]detection.Rule, 0, len(rules))
+	for _, rule := range rules {
+		description := rule.Description
+		if description == "" {
+			description = fmt.Sprintf("Query rule %s", rule.Name)
+		}
+		result = append(result, detection.Rule{
+			Name:        rule.Name,
+			Description: description,
+			Enabled:     true,
+			Severity:    rule.Severity,
+			Actions:     rule.Actions,
+			Conditions: []detection.RuleCondition{{
+				Resource:  rule.Resource,
+				Query:     rule.Query,
+				Endpoint:  rule.Endpoint,
+				NameLabel: rule.NameLabel,
+				Operator:  rule.Operator,
+				Value:     rule.Threshold,
+				Duration:  &metav1.Duration{Duration: rule.For},
+			}},
+		})
+	}
+	return result
 }
 
 // convertConfigNamespaces converts config namespace configs to detection namespace configs
@@ -251,11 +1382,27 @@ func convertConfigNamespaces(configNs map[string]config.NamespaceConfig) map[str
 				CheckDuration:    ns.Memory.CheckDuration,
 				Enabled:          ns.Memory.Enabled,
 			},
+			ResourceQuota: detection.ResourceQuotaConfig{
+				ThresholdPercent: ns.ResourceQuota.ThresholdPercent,
+			},
+			Flapping: detection.FlappingConfig{
+				RestartLimit: ns.Flapping.RestartLimit,
+				Window:       ns.Flapping.Window,
+			},
 		}
 	}
 	return result
 }
 
+// namespaceRemediation returns the remediation settings of namespace configs
+func namespaceRemediation(configNs map[string]config.NamespaceConfig) map[string]config.NamespaceRemediationConfig {
+	result := make(map[string]config.NamespaceRemediationConfig, len(configNs))
+	for name, ns := range configNs {
+		result[name] = ns.Remediation
+	}
+	return result
+}
+
 // convertRemediationNamespaces converts config namespace configs to remediation namespace configs
 func convertRemediationNamespaces(configNs map[string]config.NamespaceRemediationConfig) map[string]remediation.NamespaceRemediationConfig {
 	result := make(map[string]remediation.NamespaceRemediationConfig)
@@ -267,6 +1414,8 @@ func convertRemediationNamespaces(configNs map[string]config.NamespaceRemediatio
 			MaxRetries:          ns.MaxRetries,
 			RetryInterval:       ns.RetryInterval,
 			CooldownSeconds:     ns.CooldownSeconds,
+			HandshakeEnabled:    ns.HandshakeEnabled,
+			ForceDeleteEnabled:  ns.ForceDeleteEnabled,
 		}
 	}
 	return result
@@ -2,18 +2,39 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
 	"github.com/NotHarshhaa/kubeguardian/pkg/config"
 	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
 	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
+	"github.com/NotHarshhaa/kubeguardian/pkg/permissions"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // MockKubernetesClient extends fake client with additional mock capabilities
@@ -167,6 +188,152 @@ func TestControllerProcessIssue(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestControllerObserveModeSkipsRemediation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+
+	client := NewMockKubernetesClient(pod)
+
+	// In observe mode no remediation engine is constructed
+	ctrl := &Controller{
+		client:  client,
+		config:  &config.Config{Mode: config.ModeObserve},
+		metrics: metrics.NewMetrics(),
+	}
+
+	issue := detection.Issue{
+		RuleName:   "test-rule",
+		Severity:   "high",
+		Resource:   pod,
+		Namespace:  "default",
+		Name:       "test-pod",
+		Kind:       "Pod",
+		Actions:    []string{"restart-pod"},
+		DetectedAt: time.Now(),
+	}
+
+	err := ctrl.processIssue(context.Background(), issue)
+	assert.NoError(t, err)
+
+	// The pod must still exist since no action was executed
+	_, err = client.CoreV1().Pods("default").Get(context.Background(), "test-pod", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestControllerCheckPermissionsDisablesActions(t *testing.T) {
+	client := NewMockKubernetesClient()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		// Deny only pod deletion
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "delete"
+		return true, review, nil
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Remediation.DryRun = true
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	report, err := ctrl.CheckPermissions(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.DetectionAllowed())
+	assert.Contains(t, report.MissingActions(), "restart-pod")
+
+	disabled, reason := ctrl.remediator.IsActionDisabled("restart-pod")
+	assert.True(t, disabled)
+	assert.Contains(t, reason, "delete pods")
+
+	disabled, _ = ctrl.remediator.IsActionDisabled("rollback-deployment")
+	assert.False(t, disabled)
+}
+
+func TestControllerCheckPermissionsCoversFeatures(t *testing.T) {
+	client := NewMockKubernetesClient()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		// Deny only writing ConfigMaps
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource != "configmaps" || review.Spec.ResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Remediation.DryRun = true
+	cfg.Remediation.PreRemediationHook.Enabled = true
+	cfg.Detection.State.Backend = detection.StateBackendConfigMap
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	report, err := ctrl.CheckPermissions(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, report.Features, "pre-check conflict")
+	assert.Contains(t, report.Features, "pre-remediation hook")
+	missing := report.MissingFeatures()
+	assert.Len(t, missing, 1)
+	assert.Contains(t, missing, "state")
+	assert.Contains(t, report.Summary(), "[FAIL] state: update configmaps in kubeguardian")
+}
+
+func TestControllerCheckCapabilitiesDisablesActions(t *testing.T) {
+	client := NewMockKubernetesClient()
+
+	cfg := config.DefaultConfig()
+	cfg.Remediation.DryRun = true
+	cfg.Detection.Containers = []config.ContainerRuleConfig{{Name: "istio-proxy", Actions: []string{"restart-container"}}}
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	// The fake discovery client serves no APIs, like a cluster without ephemeral containers
+	report, err := ctrl.CheckCapabilities(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, report.Has("ephemeral-containers"))
+
+	disabled, reason := ctrl.remediator.IsActionDisabled("restart-container")
+	assert.True(t, disabled)
+	assert.Contains(t, reason, "ephemeral-containers")
+
+	disabled, _ = ctrl.remediator.IsActionDisabled("restart-pod")
+	assert.False(t, disabled)
+}
+
+func TestControllerPreflight(t *testing.T) {
+	denyDelete := false
+	client := NewMockKubernetesClient()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = !denyDelete || review.Spec.ResourceAttributes.Verb != "delete"
+		return true, review, nil
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Remediation.DryRun = true
+	// Only restart-pod is checked, so denying deletion misses one permission
+	for action := range permissions.ActionRequirements {
+		if action != "restart-pod" {
+			cfg.Remediation.ActionDefaults.Strip = append(cfg.Remediation.ActionDefaults.Strip, action)
+		}
+	}
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	// A missing metrics server is only a warning
+	report := ctrl.Preflight(context.Background())
+	assert.True(t, report.Passed(), report.Summary())
+	assert.Contains(t, report.Summary(), "[WARN] metrics-server")
+
+	denyDelete = true
+	report = ctrl.Preflight(context.Background())
+	assert.False(t, report.Passed())
+	assert.Contains(t, report.Summary(), "[FAIL] rbac: 1 missing permissions (restart-pod: delete pods)")
+}
+
 func TestControllerGetClient(t *testing.T) {
 	client := NewMockKubernetesClient()
 
@@ -337,3 +504,1201 @@ func TestControllerGracefulShutdown(t *testing.T) {
 func int32Ptr(i int32) *int32 {
 	return &i
 }
+
+func TestControllerTriggerAction(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(pod), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	req := ActionRequest{
+		Action:    "restart-pod",
+		Namespace: "default",
+		Kind:      "Pod",
+		Name:      "test-pod",
+		Requester: remediation.Requester{User: "alice", Source: "api"},
+	}
+
+	result, err := ctrl.TriggerAction(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "alice", result.RequestedBy)
+
+	// Missing requester identity is rejected
+	req.Requester = remediation.Requester{}
+	_, err = ctrl.TriggerAction(context.Background(), req)
+	assert.Error(t, err)
+
+	// Observe mode has no remediation engine
+	observe := &Controller{config: &config.Config{Mode: config.ModeObserve}, metrics: metrics.NewMetrics()}
+	req.Requester = remediation.Requester{User: "alice", Source: "api"}
+	_, err = observe.TriggerAction(context.Background(), req)
+	assert.ErrorIs(t, err, ErrObserveMode)
+}
+
+func TestControllerTriggerNodeAction(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	client := NewMockKubernetesClient(node)
+
+	ctrl, err := NewControllerWithClient(client, config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	req := ActionRequest{
+		Action:    remediation.ActionCordonNode,
+		Kind:      "Node",
+		Name:      "node-1",
+		Requester: remediation.Requester{User: "alice", Source: "api"},
+	}
+
+	// Node actions are gated by the nodeActions feature flag
+	_, err = ctrl.TriggerAction(context.Background(), req)
+	assert.ErrorIs(t, err, ErrFeatureDisabled)
+
+	_, err = ctrl.SetFeature(context.Background(), features.NodeActions, true, req.Requester)
+	assert.NoError(t, err)
+	result, err := ctrl.TriggerAction(context.Background(), req)
+	assert.NoError(t, err)
+	assert.True(t, result.Success)
+
+	cordoned, err := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, cordoned.Spec.Unschedulable)
+
+	// Nodes are not namespaced
+	req.Namespace = "default"
+	assert.Error(t, req.Validate())
+}
+
+func TestControllerSeverityFloorSkipsRemediation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+
+	client := NewMockKubernetesClient(pod)
+	cfg := config.DefaultConfig()
+	cfg.Remediation.MinSeverity = "critical"
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	issue := detection.Issue{
+		RuleName:   "test-rule",
+		Severity:   "high",
+		Resource:   pod,
+		Namespace:  "default",
+		Name:       "test-pod",
+		Kind:       "Pod",
+		Actions:    []string{"restart-pod"},
+		DetectedAt: time.Now(),
+	}
+
+	err = ctrl.processIssue(context.Background(), issue)
+	assert.NoError(t, err)
+
+	// The pod must still exist since the issue is below the severity floor
+	_, err = client.CoreV1().Pods("default").Get(context.Background(), "test-pod", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestControllerBatchIssues(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	controller := true
+	podIssue := func(name string, actions ...string) detection.Issue {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			Labels:          map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "7d9f"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f", Controller: &controller}},
+		}}
+		return detection.Issue{RuleName: "crash-loop-backoff", Resource: pod, Namespace: "default", Name: name, Kind: "Pod", Actions: actions}
+	}
+
+	client := NewMockKubernetesClient(deployment)
+	cfg := config.DefaultConfig()
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	// All pods fail: one workload action replaces the pod actions
+	issues := []detection.Issue{podIssue("web-7d9f-a", "restart-pod"), podIssue("web-7d9f-b", "restart-pod")}
+	batched := ctrl.batchIssues(context.Background(), issues, issues)
+	assert.Len(t, batched, 1)
+	assert.Equal(t, "Deployment", batched[0].Kind)
+	assert.Equal(t, "web", batched[0].Name)
+	assert.Equal(t, []string{"restart-deployment"}, batched[0].Actions)
+
+	// Affected pods are counted in all detected issues, not only the actionable ones
+	batched = ctrl.batchIssues(context.Background(), issues, issues[1:])
+	assert.Len(t, batched, 1)
+	assert.Equal(t, "Deployment", batched[0].Kind)
+
+	// Some pods are healthy
+	replicas = 3
+	_, err = client.AppsV1().Deployments("default").Update(context.Background(), deployment, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	batched = ctrl.batchIssues(context.Background(), issues, issues)
+	assert.Equal(t, issues, batched)
+
+	// Notify-only issues are never batched
+	notifyOnly := []detection.Issue{podIssue("web-7d9f-a"), podIssue("web-7d9f-b")}
+	assert.Equal(t, notifyOnly, ctrl.batchIssues(context.Background(), notifyOnly, notifyOnly))
+}
+
+func TestControllerPrioritizeIssues(t *testing.T) {
+	highPriority := int32(1000)
+	critical := &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "business-critical"},
+		Value:      100000,
+	}
+
+	client := NewMockKubernetesClient(critical)
+	cfg := config.DefaultConfig()
+	cfg.Remediation.Priority.NamespaceTiers = map[string]int{"production": 0, "batch": 3}
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	newIssue := func(name, namespace, severity string, spec corev1.PodSpec) detection.Issue {
+		return detection.Issue{
+			Name:      name,
+			Namespace: namespace,
+			Severity:  severity,
+			Kind:      "Pod",
+			Resource: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       spec,
+			},
+		}
+	}
+
+	issues := []detection.Issue{
+		newIssue("batch-job", "batch", "critical", corev1.PodSpec{}),
+		newIssue("default-low", "default", "low", corev1.PodSpec{}),
+		newIssue("default-high", "default", "high", corev1.PodSpec{}),
+		newIssue("default-priority", "default", "low", corev1.PodSpec{Priority: &highPriority}),
+		newIssue("default-class", "default", "low", corev1.PodSpec{PriorityClassName: "business-critical"}),
+		newIssue("production", "production", "low", corev1.PodSpec{}),
+	}
+
+	sorted := ctrl.prioritizeIssues(context.Background(), issues)
+
+	var names []string
+	for _, issue := range sorted {
+		names = append(names, issue.Name)
+	}
+	assert.Equal(t, []string{"production", "default-class", "default-priority", "default-high", "default-low", "batch-job"}, names)
+}
+
+func TestControllerActionBudget(t *testing.T) {
+	budget := newActionBudget(2)
+	assert.True(t, budget.take())
+	assert.True(t, budget.take())
+	assert.False(t, budget.take())
+
+	// A zero maximum is unlimited
+	unlimited := newActionBudget(0)
+	for i := 0; i < 5; i++ {
+		assert.True(t, unlimited.take())
+	}
+}
+
+func TestControllerNamespaceSelectors(t *testing.T) {
+	payments := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-payments", Labels: map[string]string{"tier": "prod"}}}
+	sandbox := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-sandbox"}}
+
+	client := NewMockKubernetesClient(payments, sandbox)
+	cfg := config.DefaultConfig()
+	cfg.NamespaceSelectors = []config.NamespaceSelectorConfig{
+		{
+			Names:       []string{"team-*"},
+			MatchLabels: map[string]string{"tier": "prod"},
+			NamespaceConfig: config.NamespaceConfig{
+				CrashLoop:   config.CrashLoopConfig{RestartLimit: 10, CheckDuration: time.Minute, Enabled: true},
+				Remediation: config.NamespaceRemediationConfig{Enabled: true, CooldownSeconds: 900, MinSeverity: "critical"},
+			},
+		},
+	}
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	ctrl.expandNamespaceSelectors(context.Background())
+
+	assert.Equal(t, 10, ctrl.detector.GetNamespaceConfig("team-payments").CrashLoop.RestartLimit)
+	assert.Equal(t, 900, ctrl.remediator.GetNamespaceConfig("team-payments").CooldownSeconds)
+	assert.False(t, ctrl.namespacePolicy().MeetsRemediationSeverity("team-payments", "high"))
+
+	// Namespaces without the label fall back to the defaults
+	assert.Equal(t, 300, ctrl.remediator.GetNamespaceConfig("team-sandbox").CooldownSeconds)
+	assert.True(t, ctrl.namespacePolicy().MeetsRemediationSeverity("team-sandbox", "high"))
+
+	// Unchanged namespaces are resolved from the cache, relabelled ones again
+	ctrl.expandNamespaceSelectors(context.Background())
+	hits, misses := ctrl.policies.Stats()
+	assert.Equal(t, uint64(2), hits)
+	assert.Equal(t, uint64(2), misses)
+
+	sandbox.Labels = map[string]string{"tier": "prod"}
+	_, err = client.CoreV1().Namespaces().Update(context.Background(), sandbox, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	ctrl.expandNamespaceSelectors(context.Background())
+	assert.Equal(t, 900, ctrl.remediator.GetNamespaceConfig("team-sandbox").CooldownSeconds)
+}
+
+type stubAnalyzer struct {
+	analyzed []analysis.Context
+}
+
+func (a *stubAnalyzer) Analyze(ctx context.Context, issue analysis.Context) (*analysis.Result, error) {
+	a.analyzed = append(a.analyzed, issue)
+	return &analysis.Result{Hypothesis: "bad config"}, nil
+}
+
+func TestControllerAnalyzeIssues(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	analyzer := &stubAnalyzer{}
+	ctrl.analyzer = analyzer
+	issues := []detection.Issue{
+		{RuleName: "crash-loop-backoff", Severity: "high", Namespace: "default", Kind: "Pod", Name: "web"},
+		{RuleName: "high-cpu-usage", Severity: "medium", Namespace: "default", Kind: "Pod", Name: "api"},
+	}
+
+	// Analysis is gated by the aiAnalysis feature flag
+	ctrl.analyzeIssues(context.Background(), issues)
+	assert.Empty(t, analyzer.analyzed)
+	assert.Nil(t, issues[0].Analysis)
+
+	_, err = ctrl.SetFeature(context.Background(), features.AIAnalysis, true, remediation.Requester{User: "test"})
+	assert.NoError(t, err)
+	ctrl.analyzeIssues(context.Background(), issues)
+
+	// Issues below the minimum severity are not analyzed
+	assert.Len(t, analyzer.analyzed, 1)
+	assert.Equal(t, "web", analyzer.analyzed[0].Name)
+	if assert.NotNil(t, issues[0].Analysis) {
+		assert.Equal(t, "bad config", issues[0].Analysis.Hypothesis)
+	}
+	assert.Nil(t, issues[1].Analysis)
+}
+
+func TestControllerIdempotencyKeysSurviveRestart(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := NewMockKubernetesClient(pod)
+
+	// restart-pod evicts the pod, or deletes it when eviction is not available
+	removals := 0
+	client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		removals++
+		return true, nil, nil
+	})
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		removals++
+		return true, nil, nil
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Detection.State.Backend = detection.StateBackendConfigMap
+
+	issue := detection.Issue{
+		RuleName:  "crash-loop-backoff",
+		Severity:  "critical",
+		Resource:  pod,
+		Namespace: "default",
+		Name:      "web-1",
+		Kind:      "Pod",
+		Actions:   []string{"restart-pod"},
+	}
+
+	// Each controller stands for a process; the second one has no in-memory cooldowns
+	for i := 0; i < 2; i++ {
+		ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+		assert.NoError(t, err)
+		assert.NoError(t, ctrl.remediations.Load(context.Background()))
+		assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	}
+	assert.Equal(t, 1, removals, "the action must not be executed twice within the cooldown")
+
+	// A new generation of the resource can be remediated again
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.NoError(t, ctrl.remediations.Load(context.Background()))
+	changed := pod.DeepCopy()
+	changed.Generation = 2
+	issue.Resource = changed
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, 2, removals)
+}
+
+func TestControllerObserveModeKeepsStateInMemory(t *testing.T) {
+	client := NewMockKubernetesClient()
+	cfg := config.DefaultConfig()
+	cfg.Mode = config.ModeObserve
+	cfg.Detection.State.Backend = detection.StateBackendConfigMap
+
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	ctrl.detector.State().Observe("crash-loop-backoff/default/web-1", time.Now())
+	assert.NoError(t, ctrl.detector.State().Flush(context.Background()))
+	ctrl.remediations.Observe("restart-pod/default/web-1", time.Now())
+	assert.NoError(t, ctrl.remediations.Flush(context.Background()))
+
+	for _, action := range client.Actions() {
+		assert.NotEqual(t, "configmaps", action.GetResource().Resource, "observe mode must not touch the state ConfigMap")
+	}
+}
+
+func TestControllerStartupNotificationSuppressedWhileFlapping(t *testing.T) {
+	client := NewMockKubernetesClient()
+	cfg := config.DefaultConfig()
+	cfg.Detection.State.Backend = detection.StateBackendConfigMap
+	cfg.Notification.Lifecycle.MinUptime = time.Hour
+	ctx := context.Background()
+
+	// Each controller stands for a process
+	first, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.True(t, first.startRun(ctx), "the first start should be notified")
+
+	// The first run crashed right away
+	second, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.False(t, second.startRun(ctx), "a start after a short run should not be notified")
+
+	// The second run lasted the minimum uptime before it crashed
+	second.lifecycle.started = time.Now().Add(-2 * time.Hour)
+	second.lifecycle.store.Forget(runKey)
+	second.lifecycle.store.Observe(runKey, second.lifecycle.started)
+	second.establishRun(ctx)
+	assert.True(t, second.lifecycle.established)
+
+	third, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.True(t, third.startRun(ctx), "a start after a long run should be notified")
+}
+
+func TestControllerRulesDisabledAtRuntimeSurviveRestart(t *testing.T) {
+	client := NewMockKubernetesClient()
+	cfg := config.DefaultConfig()
+	cfg.Detection.State.Backend = detection.StateBackendConfigMap
+	cfg.Detection.EphemeralContainerMaxAge = 0
+	ctx := context.Background()
+	alice := remediation.Requester{User: "alice", Source: "api"}
+
+	first, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	status, err := first.SetRuleEnabled(ctx, "high-cpu-usage", false, "noisy during incident", alice)
+	assert.NoError(t, err)
+	assert.False(t, status.Enabled)
+	if assert.NotNil(t, status.DisabledAtRuntime) {
+		assert.Equal(t, "alice", status.DisabledAtRuntime.DisabledBy)
+	}
+	assert.True(t, first.detector.Silenced("high-cpu-usage"))
+
+	_, err = first.SetRuleEnabled(ctx, "teleport", false, "", alice)
+	assert.ErrorIs(t, err, ErrUnknownRule)
+	// Ephemeral container detection is disabled without a maximum age
+	_, err = first.SetRuleEnabled(ctx, "ephemeral-container-age", true, "", alice)
+	assert.ErrorIs(t, err, ErrRuleUnavailable)
+
+	// The rule stays disabled after a restart until it is enabled again
+	second, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	second.applyRuleOverlay(ctx)
+	assert.True(t, second.detector.Silenced("high-cpu-usage"))
+
+	_, err = second.SetRuleEnabled(ctx, "high-cpu-usage", true, "incident resolved", remediation.Requester{User: "bob", Source: "cli"})
+	assert.NoError(t, err)
+	assert.False(t, second.detector.Silenced("high-cpu-usage"))
+
+	audit, err := second.RuleAudit(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, audit, 2) {
+		assert.Equal(t, "bob", audit[0].User)
+		assert.True(t, audit[0].Enabled)
+		assert.Equal(t, "noisy during incident", audit[1].Reason)
+	}
+}
+
+func TestControllerStateBundle(t *testing.T) {
+	ctx := context.Background()
+	alice := remediation.Requester{User: "alice", Source: "api"}
+	now := time.Now()
+
+	source, err := NewControllerWithClient(NewMockKubernetesClient(), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	_, err = source.SetRuleEnabled(ctx, "high-cpu-usage", false, "noisy during incident", alice)
+	assert.NoError(t, err)
+	_, err = source.SetFeature(ctx, features.AIAnalysis, true, alice)
+	assert.NoError(t, err)
+	source.detector.State().Observe("crash-loop-backoff/shop/web-1", now.Add(-time.Hour))
+	source.remediator.MergeCooldowns([]remediation.CooldownEntry{{ResourceKey: "shop:web-1:restart-pod", LastAction: now}})
+	issue := detection.Issue{RuleName: "crash-loop-backoff", Namespace: "shop", Kind: "Pod", Name: "web-1", Severity: "high"}
+	source.tracker.Diff([]detection.Issue{issue}, now.Add(-time.Hour), 0)
+
+	bundle, err := source.ExportState(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, StateBundleVersion, bundle.Version)
+	assert.Len(t, bundle.Features, 1)
+	assert.Len(t, bundle.Issues, 1)
+
+	// The bundle is imported into an instance in another cluster
+	data, err := json.Marshal(bundle)
+	assert.NoError(t, err)
+	var decoded StateBundle
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	decoded.Policies = map[string]config.NamespaceConfig{"shop": config.DefaultConfig().DefaultNamespaceConfig()}
+
+	target, err := NewControllerWithClient(NewMockKubernetesClient(), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	result, err := target.ImportState(ctx, &decoded, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Silences)
+	assert.Equal(t, 1, result.Features)
+	assert.Equal(t, 1, result.Issues)
+	assert.Equal(t, []string{"namespace shop has no policy on this instance"}, result.Warnings)
+
+	assert.True(t, target.detector.Silenced("high-cpu-usage"))
+	assert.True(t, target.features.Enabled(features.AIAnalysis))
+	state, found := target.detector.State().Get("crash-loop-backoff/shop/web-1")
+	assert.True(t, found)
+	assert.WithinDuration(t, now.Add(-time.Hour), state.FirstSeen, time.Second)
+	assert.Len(t, target.remediator.Cooldowns(), 1)
+	if records := target.ActiveIssues(); assert.Len(t, records, 1) {
+		assert.Equal(t, issue.Fingerprint(), records[0].Fingerprint)
+		assert.WithinDuration(t, now.Add(-time.Hour), records[0].FirstDetected, time.Second)
+	}
+
+	decoded.Version = 2
+	_, err = target.ImportState(ctx, &decoded, alice)
+	assert.ErrorIs(t, err, ErrBundleVersion)
+}
+
+func TestControllerDigestRecordsActivity(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Notification.Digest.Enabled = true
+	cfg.Notification.Digest.Channels = []config.DigestChannelConfig{{Name: "ops", Type: "webhook", Cron: "0 9 * * *", URL: "https://hooks.example.com/digest"}}
+
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.NotNil(t, ctrl.digest)
+
+	start := time.Now()
+	issue := detection.Issue{Kind: "Deployment", Name: "api", Namespace: "prod", Owner: detection.Owner{Team: "payments"}}
+	ctrl.recordDetectedActivity([]detection.Issue{issue, issue})
+	ctrl.recordRemediationActivity(issue, true)
+	ctrl.recordNotificationActivity(issue)
+	ctrl.recordResolvedActivity([]tracker.Record{{RemediatedAt: start}, {}})
+
+	summary := ctrl.activity.Summarize(start, start.Add(2*time.Hour), 5, cfg.Notification.Digest.ToilPerIssue)
+	assert.Equal(t, 2, summary.Detected)
+	assert.Equal(t, 1, summary.Remediations)
+	assert.Equal(t, 1, summary.AutoResolved)
+	assert.Equal(t, 1, summary.Escalated)
+	assert.Equal(t, 15*time.Minute, summary.ToilSaved)
+	if assert.Len(t, summary.TopWorkloads, 1) {
+		assert.Equal(t, "api", summary.TopWorkloads[0].Name)
+	}
+
+	report, err := ctrl.UsageReport(context.Background(), start)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Teams, 1) {
+		assert.Equal(t, "payments", report.Teams[0].Team)
+		assert.Equal(t, digest.Usage{Issues: 2, Remediations: 1, Notifications: 1}, report.Teams[0].Usage)
+	}
+
+	// Slack digests require Slack notifications
+	cfg.Notification.Digest.Channels[0].Type = "slack"
+	_, err = NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.Error(t, err)
+
+	cfg.Notification.Digest.Enabled = false
+	ctrl, err = NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	_, err = ctrl.UsageReport(context.Background(), start)
+	assert.ErrorIs(t, err, ErrDigestDisabled)
+}
+
+func TestControllerQueryRules(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Detection.Prometheus.URL = "http://prometheus.monitoring:9090"
+	cfg.Detection.Prometheus.Rules = []config.QueryRuleConfig{{
+		Name:      "checkout-error-rate",
+		Query:     `sum by (namespace, deployment) (rate(http_requests_total{code=~"5.."}[5m]))`,
+		Resource:  "Deployment",
+		Operator:  "greater_than",
+		Threshold: 0.05,
+		For:       5 * time.Minute,
+		Severity:  "high",
+		Actions:   []string{"rollback-deployment"},
+	}}
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	rules := ctrl.detector.Rules()
+	rule := rules[len(rules)-1]
+	assert.Equal(t, "checkout-error-rate", rule.Name)
+	assert.True(t, rule.Enabled)
+	assert.Equal(t, detection.RuleSourceFile, rule.Metadata.Source)
+	assert.Equal(t, "Query rule checkout-error-rate", rule.Description)
+	assert.Equal(t, 0.05, rule.Conditions[0].Value)
+}
+
+func TestControllerHygieneReport(t *testing.T) {
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	_, err = ctrl.HygieneReport(context.Background())
+	assert.ErrorIs(t, err, ErrHygieneDisabled)
+
+	replicas := int32(1)
+	client := NewMockKubernetesClient(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}},
+		},
+	})
+	cfg := config.DefaultConfig()
+	cfg.Detection.Hygiene.Enabled = true
+	cfg.Detection.Hygiene.Checks = []string{"single-replica", "missing-readiness-probe"}
+	cfg.Detection.Hygiene.ProductionNamespaces = []string{"prod"}
+	ctrl, err = NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	report, err := ctrl.HygieneReport(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Workloads)
+	assert.Equal(t, map[string]int{"single-replica": 1, "missing-readiness-probe": 1}, report.Counts)
+}
+
+func TestControllerResyncRequiresRunningLoop(t *testing.T) {
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.NoError(t, ctrl.detector.LoadRules())
+
+	_, err = ctrl.Resync(context.Background(), ResyncRequest{Rule: "teleport"})
+	assert.ErrorIs(t, err, ErrUnknownRule)
+
+	_, err = ctrl.Resync(context.Background(), ResyncRequest{Rule: "crash-loop-backoff"})
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	// The detection loop serves resyncs
+	ctrl.running.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		call := <-ctrl.resyncs
+		call.done <- ctrl.resync(ctx, call.req)
+	}()
+	result, err := ctrl.Resync(ctx, ResyncRequest{Namespace: "default"})
+	assert.NoError(t, err)
+	assert.Equal(t, "default", result.Namespace)
+}
+
+func TestControllerStats(t *testing.T) {
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	stats := ctrl.Stats()
+	assert.True(t, stats.LastCycleAt.IsZero(), "no cycle has run yet")
+
+	summary, err := ctrl.runDetectionCycle(context.Background(), cycleScope{})
+	assert.NoError(t, err)
+
+	stats = ctrl.Stats()
+	assert.False(t, stats.LastCycleAt.IsZero())
+	assert.Positive(t, stats.LastCycleDuration)
+	assert.Equal(t, summary.Issues, stats.IssuesDetected)
+	assert.Zero(t, stats.RemediationQueueDepth)
+	assert.Len(t, stats.CircuitBreakers, 3)
+	for name, breaker := range stats.CircuitBreakers {
+		assert.Equal(t, "closed", breaker.State, name)
+	}
+
+	// Scoped cycles do not replace the statistics of the last full cycle
+	last := stats.LastCycleAt
+	_, err = ctrl.runDetectionCycle(context.Background(), cycleScope{Namespace: "default"})
+	assert.NoError(t, err)
+	assert.Equal(t, last, ctrl.Stats().LastCycleAt)
+}
+
+func TestObjectCycleScope(t *testing.T) {
+	scope := cycleScope{Namespace: "default", Kind: "Pod", Name: "web-1", Rules: map[string]bool{"crash-loop-backoff": true}}
+	assert.False(t, scope.all())
+
+	record := tracker.Record{RuleName: "crash-loop-backoff", Namespace: "default", Kind: "Pod", Name: "web-1"}
+	assert.True(t, scope.includes(record))
+
+	// Issues of other rules and objects are left to the full cycles
+	otherRule := record
+	otherRule.RuleName = "pod-pending"
+	assert.False(t, scope.includes(otherRule))
+	otherPod := record
+	otherPod.Name = "web-2"
+	assert.False(t, scope.includes(otherPod))
+	otherKind := record
+	otherKind.Kind = "Deployment"
+	assert.False(t, scope.includes(otherKind))
+}
+
+func TestObjectWatchQueuesChanges(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := fake.NewSimpleClientset(pod)
+	detector := detection.NewDetector(client, detection.DetectionConfig{})
+	watch, err := newObjectWatch(client, detector, config.WatchConfig{Enabled: true}, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watch.Run(ctx)
+
+	next := func() objectEvent {
+		select {
+		case event := <-watch.events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no object event")
+			return objectEvent{}
+		}
+	}
+	assert.Equal(t, objectEvent{Kind: "Pod", Namespace: "default", Name: "web-1"}, next())
+
+	pod.Labels = map[string]string{"app": "web"}
+	pod.ResourceVersion = "2"
+	_, err = client.CoreV1().Pods("default").Update(ctx, pod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, objectEvent{Kind: "Pod", Namespace: "default", Name: "web-1"}, next())
+
+	assert.NoError(t, client.CoreV1().Pods("default").Delete(ctx, "web-1", metav1.DeleteOptions{}))
+	assert.Equal(t, objectEvent{Kind: "Pod", Namespace: "default", Name: "web-1"}, next())
+
+	// The detector reads from the synced caches
+	issues, err := detector.DetectObject(ctx, "Pod", "default", "web-1")
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestObjectWatchCacheHealth(t *testing.T) {
+	written := metav1.NewTime(time.Now().Add(-3 * time.Second))
+	client := fake.NewSimpleClientset()
+	detector := detection.NewDetector(client, detection.DetectionConfig{})
+	watch, err := newObjectWatch(client, detector, config.WatchConfig{Enabled: true, StaleAfter: time.Minute}, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	err = watch.cacheHealth()
+	assert.EqualError(t, err, "the Deployment cache has not synced; the Pod cache has not synced")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watch.Run(ctx)
+	assert.Eventually(t, func() bool { return watch.cacheHealth() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	// Watch events of objects written since the caches synced count as progress
+	watch.mu.Lock()
+	watch.caches[detection.KindPod].progressAt = time.Now().Add(-time.Hour)
+	watch.mu.Unlock()
+	assert.ErrorContains(t, watch.cacheHealth(), "the Pod watch made no progress for 1h0m0s")
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-1", Namespace: "default",
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl", Time: &written}},
+	}}
+	_, err = client.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return watch.cacheHealth() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	at, ok := lastWrite(pod)
+	assert.True(t, ok)
+	assert.Equal(t, written.Time, at)
+
+	ctrl := &Controller{watch: watch}
+	assert.Equal(t, "informer-cache", ctrl.CacheCheck().Name())
+	assert.Nil(t, (&Controller{}).CacheCheck())
+}
+
+func TestNewControllerWithoutWatch(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Detection.Watch.Enabled = false
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.Nil(t, ctrl.watch)
+	assert.Nil(t, ctrl.objectEvents())
+}
+
+func TestControllerIncidentMode(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := NewMockKubernetesClient(pod)
+	removals := 0
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		removals++
+		return true, nil, nil
+	})
+
+	ctrl, err := NewControllerWithClient(client, config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	requester := remediation.Requester{User: "alice"}
+
+	_, err = ctrl.StartIncident(context.Background(), IncidentRequest{Duration: "30m"}, requester)
+	assert.ErrorIs(t, err, ErrInvalidIncident, "an incident needs a scope")
+	_, err = ctrl.StartIncident(context.Background(), IncidentRequest{Namespace: "default", Duration: "48h"}, requester)
+	assert.ErrorIs(t, err, ErrInvalidIncident, "an incident must not exceed the maximum duration")
+	_, err = ctrl.StartIncident(context.Background(), IncidentRequest{Rules: []string{"no-such-rule"}, Duration: "30m"}, requester)
+	assert.ErrorIs(t, err, ErrUnknownRule)
+
+	issue := detection.Issue{
+		RuleName:  "crash-loop-backoff",
+		Severity:  "critical",
+		Resource:  pod,
+		Namespace: "default",
+		Name:      "web-1",
+		Kind:      "Pod",
+		Actions:   []string{"restart-pod"},
+	}
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, 1, removals, "the cooldown applies outside of incidents")
+
+	incident, err := ctrl.StartIncident(context.Background(), IncidentRequest{Namespace: "default", Rules: []string{"crash-loop-backoff"}, Duration: "30m", Reason: "outage"}, requester)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", incident.StartedBy)
+	assert.Len(t, ctrl.Incidents(), 1)
+
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, 3, removals, "incident mode without a cooldown lets actions repeat")
+
+	other := issue
+	other.Namespace = "payments"
+	_, active := ctrl.incidents.active(other, time.Now())
+	assert.False(t, active, "incidents only relax issues in their scope")
+
+	// Incidents revert on their own once expired
+	_, active = ctrl.incidents.active(issue, incident.ExpiresAt)
+	assert.False(t, active)
+	assert.Empty(t, ctrl.incidents.expire(incident.ExpiresAt.Add(-time.Second)))
+
+	ended, err := ctrl.EndIncident(context.Background(), incident.ID, requester)
+	assert.NoError(t, err)
+	assert.Equal(t, incident.ID, ended.ID)
+	assert.Empty(t, ctrl.Incidents())
+	_, err = ctrl.EndIncident(context.Background(), incident.ID, requester)
+	assert.ErrorIs(t, err, ErrIncidentNotFound)
+
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, 3, removals, "the cooldown applies again after the incident")
+}
+
+func TestControllerConfigDrift(t *testing.T) {
+	client := NewMockKubernetesClient()
+	newInstance := func(name string, cooldown int) *Controller {
+		cfg := config.DefaultConfig()
+		cfg.Detection.ConfigDrift.Enabled = true
+		cfg.Detection.ConfigDrift.Instance = name
+		cfg.Remediation.CooldownSeconds = cooldown
+		ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+		assert.NoError(t, err)
+		return ctrl
+	}
+	first := newInstance("kubeguardian-0", 300)
+	second := newInstance("kubeguardian-1", 300)
+	drifted := newInstance("kubeguardian-2", 60)
+
+	assert.Empty(t, first.detectConfigDrift(context.Background()))
+	assert.Empty(t, second.detectConfigDrift(context.Background()), "instances with the same configuration do not drift")
+	report, err := second.ConfigDrift(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, report.Instances, 2)
+	assert.False(t, report.Drifted)
+
+	issues := drifted.detectConfigDrift(context.Background())
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, ConfigDriftRule, issues[0].RuleName)
+		assert.Equal(t, "high", issues[0].Severity)
+		assert.Equal(t, "kubeguardian-config-hashes", issues[0].Name)
+		// Groups are listed largest first
+		hash := func(ctrl *Controller) string { return ctrl.drift.self.Hash[:driftHashLength] }
+		assert.Equal(t, fmt.Sprintf("KubeGuardian instances run different configurations: %s on kubeguardian-0, kubeguardian-1; %s on kubeguardian-2",
+			hash(first), hash(drifted)), issues[0].Description)
+	}
+	assert.Len(t, first.detectConfigDrift(context.Background()), 1, "every instance reports the drift")
+
+	// Instances of other clusters are compared through their action API
+	peer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, driftPeerPath, r.URL.Path)
+		assert.Equal(t, "Bearer peer-token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewEncoder(w).Encode(ConfigDriftReport{Instance: ConfigInstance{Instance: "kubeguardian-0", Cluster: "eu-west", Hash: first.drift.self.Hash}}))
+	}))
+	defer peer.Close()
+	cfg := config.DefaultConfig()
+	cfg.Detection.ConfigDrift.Enabled = true
+	cfg.Detection.ConfigDrift.Instance = "kubeguardian-0"
+	cfg.Detection.ConfigDrift.Peers = []string{peer.URL}
+	cfg.Detection.ConfigDrift.PeerTokenFile = filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(cfg.Detection.ConfigDrift.PeerTokenFile, []byte("peer-token\n"), 0o600))
+	cfg.Detection.ConfigDrift.PeerCAFile = filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: peer.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(cfg.Detection.ConfigDrift.PeerCAFile, ca, 0o600))
+	remote, err := NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+	assert.Empty(t, remote.detectConfigDrift(context.Background()), "the peers and peer credentials of an instance are not part of the hash")
+	report, err = remote.ConfigDrift(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west", report.Instances[0].Cluster)
+
+	// Instances that stop publishing drop out of the comparison
+	assert.Len(t, first.drift.compare(context.Background(), time.Now().Add(time.Hour)), 1)
+
+	disabled, err := NewControllerWithClient(client, config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	_, err = disabled.ConfigDrift(context.Background())
+	assert.ErrorIs(t, err, ErrConfigDriftDisabled)
+}
+
+func TestControllerRemediationFailuresRequireIntervention(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := NewMockKubernetesClient(pod)
+	attempts := 0
+	fail := func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		return true, nil, errors.New("admission webhook denied the request")
+	}
+	client.PrependReactor("create", "pods", fail)
+	client.PrependReactor("delete", "pods", fail)
+
+	ctrl, err := NewControllerWithClient(client, config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	ctrl.remediator.SetNamespaces(map[string]remediation.NamespaceRemediationConfig{
+		"default": {Enabled: true, MaxRetries: 1, RetryInterval: time.Hour},
+	})
+
+	issue := detection.Issue{
+		RuleName:  "crash-loop-backoff",
+		Severity:  "critical",
+		Resource:  pod,
+		Namespace: "default",
+		Name:      "web-1",
+		Kind:      "Pod",
+		Actions:   []string{"restart-pod"},
+	}
+	ctrl.tracker.Observe([]detection.Issue{issue}, time.Now())
+
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	record, _ := ctrl.tracker.Get(issue.Fingerprint())
+	assert.Equal(t, 1, record.ConsecutiveFailures)
+	assert.Len(t, record.Failures, 1)
+	assert.False(t, record.InterventionRequired)
+
+	failed := attempts
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, failed, attempts, "failed remediation is not retried before the backoff elapsed")
+
+	ctrl.remediator.SetNamespaces(map[string]remediation.NamespaceRemediationConfig{
+		"default": {Enabled: true, MaxRetries: 1},
+	})
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	record, _ = ctrl.tracker.Get(issue.Fingerprint())
+	assert.Equal(t, 2, record.ConsecutiveFailures)
+	assert.True(t, record.InterventionRequired, "remediation failing beyond maxRetries requires intervention")
+	assert.Equal(t, 1, ctrl.tracker.InterventionRequired())
+
+	failed = attempts
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	assert.Equal(t, failed, attempts, "issues requiring intervention are not remediated automatically")
+}
+
+func TestControllerSkippedRemediationIsNoFailure(t *testing.T) {
+	optedOut := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web-1",
+		Namespace:   "default",
+		Annotations: map[string]string{overrides.AnnotationDisable: "true"},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}}
+	ctrl, err := NewControllerWithClient(NewMockKubernetesClient(optedOut, pod), config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	ctrl.remediator.SetNamespaces(map[string]remediation.NamespaceRemediationConfig{
+		"default": {Enabled: true, MaxRetries: 1, CooldownSeconds: 3600},
+	})
+
+	issueFor := func(pod *corev1.Pod) detection.Issue {
+		return detection.Issue{
+			RuleName:  "crash-loop-backoff",
+			Severity:  "critical",
+			Resource:  pod,
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Kind:      "Pod",
+			Actions:   []string{"restart-pod"},
+		}
+	}
+
+	// Opted out workload
+	issue := issueFor(optedOut)
+	ctrl.tracker.Observe([]detection.Issue{issue}, time.Now())
+	err = ctrl.executeRemediation(context.Background(), issue, "restart-pod")
+	assert.ErrorIs(t, err, errRemediationSkipped)
+	record, _ := ctrl.tracker.Get(issue.Fingerprint())
+	assert.Zero(t, record.ConsecutiveFailures)
+	assert.Empty(t, record.Failures)
+
+	// Action in cooldown after it succeeded
+	issue = issueFor(pod)
+	ctrl.tracker.Observe([]detection.Issue{issue}, time.Now())
+	assert.NoError(t, ctrl.executeRemediation(context.Background(), issue, "restart-pod"))
+	err = ctrl.executeRemediation(context.Background(), issue, "restart-pod")
+	assert.ErrorIs(t, err, errRemediationSkipped)
+	record, _ = ctrl.tracker.Get(issue.Fingerprint())
+	assert.Zero(t, record.ConsecutiveFailures)
+	assert.Empty(t, record.Failures)
+	assert.False(t, record.InterventionRequired)
+}
+
+func TestControllerForceDeleteRequiresOptIn(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "web-1",
+		Namespace:         "default",
+		DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+	}}
+	client := NewMockKubernetesClient(pod)
+
+	ctrl, err := NewControllerWithClient(client, config.DefaultConfig(), metrics.NewMetrics())
+	assert.NoError(t, err)
+	issue := detection.Issue{
+		RuleName:  detection.StuckTerminatingRule,
+		Severity:  "critical",
+		Resource:  pod,
+		Namespace: "default",
+		Name:      "web-1",
+		Kind:      "Pod",
+		Actions:   []string{detection.ForceDeletePodAction},
+	}
+
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	_, err = client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{})
+	assert.NoError(t, err, "pods of namespaces without the opt-in are not force deleted")
+
+	ctrl.remediator.SetNamespaces(map[string]remediation.NamespaceRemediationConfig{
+		"default": {Enabled: true, ForceDeleteEnabled: true},
+	})
+	assert.NoError(t, ctrl.processIssue(context.Background(), issue))
+	_, err = client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the pod is force deleted once the namespace opted in")
+}
+
+func TestRolloutState(t *testing.T) {
+	replicas := int32(3)
+	deployment := func(generation, observed int64, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: observed, Replicas: status.Replicas, UpdatedReplicas: status.UpdatedReplicas, AvailableReplicas: status.AvailableReplicas, Conditions: status.Conditions},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		state      string
+	}{
+		{"spec not observed", deployment(2, 1, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}), ""},
+		{"replicas not updated", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3}), ""},
+		{"old replicas terminating", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3}), ""},
+		{"updated replicas unavailable", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2}), ""},
+		{"complete", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}), notification.RolloutComplete},
+		{"progress deadline exceeded", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3, Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+		}}), notification.RolloutFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, message := rolloutState(tt.deployment)
+			assert.Equal(t, tt.state, state)
+			assert.NotEmpty(t, message)
+		})
+	}
+}
+
+func TestRolloutWatcher(t *testing.T) {
+	replicas := int32(2)
+	complete := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	stuck := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Generation: 3},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Remediation.RolloutWatch = config.RolloutWatchConfig{Enabled: true, Timeout: 200 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	watcher := newRolloutWatcher(NewMockKubernetesClient(complete, stuck), cfg)
+	assert.NotNil(t, watcher)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outcomes := make(chan notification.RolloutOutcome, 4)
+	go watcher.Run(ctx, func(_ context.Context, _ detection.Issue, outcome notification.RolloutOutcome) {
+		outcomes <- outcome
+	})
+
+	issue := detection.Issue{RuleName: "crash-loop", Kind: "Deployment", Namespace: "default"}
+	restarted := remediation.Result{Action: "restart-deployment", Success: true, Resource: "web", Namespace: "default"}
+	watcher.follow(ctx, issue, restarted)
+	watcher.follow(ctx, issue, restarted)
+	watcher.follow(ctx, issue, remediation.Result{Action: "rollback-deployment", Success: true, Resource: "api", Namespace: "default"})
+	watcher.follow(ctx, issue, remediation.Result{Action: "rollback-deployment", Success: true, Resource: "gone", Namespace: "default"})
+	watcher.follow(ctx, issue, remediation.Result{Action: "restart-deployment", Success: false, Resource: "failed", Namespace: "default"})
+	watcher.follow(ctx, issue, remediation.Result{Action: "scale-deployment", Success: true, Resource: "scaled", Namespace: "default"})
+	watcher.follow(ctx, issue, remediation.Result{Action: "restart-deployment", Success: true, Resource: "dry", Namespace: "default", Patch: "{}"})
+
+	states := make(map[string]string)
+	for range 3 {
+		select {
+		case outcome := <-outcomes:
+			states[outcome.Deployment] = outcome.State
+		case <-time.After(5 * time.Second):
+			t.Fatal("rollout was not reported")
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"web":  notification.RolloutComplete,
+		"api":  notification.RolloutTimedOut,
+		"gone": notification.RolloutDeleted,
+	}, states, "only successful rollback and restart actions are followed, once per deployment")
+
+	select {
+	case outcome := <-outcomes:
+		t.Fatalf("unexpected rollout reported: %+v", outcome)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cfg.Remediation.DryRun = true
+	assert.Nil(t, newRolloutWatcher(NewMockKubernetesClient(), cfg), "dry-run actions start no rollout")
+}
+
+func TestResolveNamespaceScope(t *testing.T) {
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	client := NewMockKubernetesClient(
+		namespace("team-a", nil),
+		namespace("checkout", map[string]string{"owner": "shop"}),
+		namespace("default", nil),
+		namespace("kube-system", map[string]string{"owner": "shop"}),
+	)
+	listFails := true
+	client.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if listFails {
+			return true, nil, errors.New("connection refused")
+		}
+		return false, nil, nil
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Detection.Scope = config.ScopeConfig{
+		IncludeNamespaces:  []string{"team-*"},
+		NamespaceSelectors: []string{"owner=shop"},
+		ExcludeNamespaces:  []string{"kube-system"},
+	}
+	ctrl, err := NewControllerWithClient(client, cfg, metrics.NewMetrics())
+	assert.NoError(t, err)
+
+	assert.Error(t, ctrl.resolveNamespaceScope(context.Background()), "detection must not run before the scope is resolved")
+
+	listFails = false
+	assert.NoError(t, ctrl.resolveNamespaceScope(context.Background()))
+	assert.True(t, ctrl.detector.InScope("team-a"))
+	assert.True(t, ctrl.detector.InScope("checkout"))
+	assert.False(t, ctrl.detector.InScope("default"))
+	assert.False(t, ctrl.detector.InScope("kube-system"), "excluded namespaces stay out of scope even when selected")
+
+	listFails = true
+	assert.NoError(t, ctrl.resolveNamespaceScope(context.Background()), "later failures keep the previous scope")
+	assert.True(t, ctrl.detector.InScope("team-a"))
+}
+
+func TestResolveCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "vault-token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("vault-token"), 0o600))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" || r.URL.Path != "/v1/kv/data/kubeguardian" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"slack":"xoxb-1","password":"hunter2"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.SecretProviders.Vault = config.VaultSecretProviderConfig{Address: server.URL, AuthMethod: "token", TokenFile: tokenFile}
+	cfg.Notification.Slack.Token = "vault://kv/data/kubeguardian#slack"
+	cfg.Export.Username = "elastic"
+	cfg.Export.Password = "vault://kv/data/kubeguardian#password"
+
+	credentials, err := resolveCredentials(cfg.SecretResolver(), cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "xoxb-1", credentials["notification.slack.token"])
+	assert.Equal(t, "elastic", credentials["export.username"])
+	assert.Equal(t, "hunter2", credentials["export.password"])
+
+	// KubeGuardian does not start with a credential that cannot be resolved
+	cfg.Export.APIKey = "vault://kv/data/kubeguardian#apiKey"
+	_, err = resolveCredentials(cfg.SecretResolver(), cfg)
+	assert.ErrorContains(t, err, "export.apiKey")
+	_, err = NewControllerWithClient(NewMockKubernetesClient(), cfg, metrics.NewMetrics())
+	assert.Error(t, err)
+}
+
+func TestAPITimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	timeouts := config.APITimeoutsConfig{List: 20 * time.Millisecond, Mutate: time.Second}
+	client := &http.Client{Transport: newAPITimeouts(timeouts, metrics.NewMetrics())(http.DefaultTransport)}
+	do := func(method, path string) error {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+		return err
+	}
+
+	assert.ErrorContains(t, do(http.MethodGet, "/api/v1/namespaces/default/pods"), "list request exceeded its 20ms timeout")
+	assert.NoError(t, do(http.MethodPatch, "/apis/apps/v1/namespaces/default/deployments/web"), "mutations have their own budget")
+	assert.NoError(t, do(http.MethodGet, "/api/v1/pods?watch=true"), "watches are not bounded")
+
+	timeouts.List = 0
+	client.Transport = newAPITimeouts(timeouts, metrics.NewMetrics())(http.DefaultTransport)
+	assert.NoError(t, do(http.MethodGet, "/api/v1/pods"), "a zero timeout is unbounded")
+
+	assert.Equal(t, "pods", apiResource("/api/v1/namespaces/default/pods/web-1"))
+	assert.Equal(t, "deployments", apiResource("/apis/apps/v1/deployments"))
+	assert.Equal(t, "namespaces", apiResource("/api/v1/namespaces/default"))
+	assert.Equal(t, "nodes", apiResource("/api/v1/nodes"))
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// ErrDigestDisabled is returned when a usage report is requested while digests are disabled
+var ErrDigestDisabled = errors.New("digests are not enabled")
+
+// digestWebhookTimeout bounds a single digest webhook request
+const digestWebhookTimeout = 10 * time.Second
+
+// newDigestStats creates the activity stats of digests, or nil if digests are
+// disabled. They use the state backend, so a restart does not lose the activity
+// of the current period.
+func newDigestStats(client kubernetes.Interface, cfg *config.Config) (*digest.Stats, error) {
+	if !cfg.Notification.Digest.Enabled {
+		return nil, nil
+	}
+
+	backend, err := newStateBackend[digest.State](client, stateConfig(cfg), digest.ConfigMapKey, "digest")
+	if err != nil {
+		return nil, err
+	}
+	return digest.NewStats(backend), nil
+}
+
+// newDigestReporter creates the reporter sending digests of the activity in stats
+// to the configured channels, or nil if digests are disabled
+func newDigestReporter(stats *digest.Stats, cfg config.DigestConfig, slackNotifier *notification.SlackNotifier, metricsCollector *metrics.Metrics) (*digest.Reporter, error) {
+	if stats == nil {
+		return nil, nil
+	}
+
+	channels := make([]digest.Channel, 0, len(cfg.Channels))
+	for _, ch := range cfg.Channels {
+		sched, err := schedule.Parse(ch.Cron, ch.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule of digest channel %s: %w", ch.Name, err)
+		}
+
+		var sender digest.Sender
+		switch ch.Type {
+		case digest.ChannelSlack:
+			if slackNotifier == nil {
+				return nil, fmt.Errorf("digest channel %s requires slack notifications", ch.Name)
+			}
+			sender = slackDigestSender{notifier: slackNotifier, channel: ch.SlackChannel}
+		case digest.ChannelEmail:
+			sender = digest.NewEmailSender(digest.SMTPConfig{
+				Host:     cfg.SMTP.Host,
+				Port:     cfg.SMTP.Port,
+				Username: cfg.SMTP.Username,
+				Password: cfg.SMTP.Password,
+				From:     cfg.SMTP.From,
+			}, ch.To)
+		case digest.ChannelWebhook:
+			sender = digest.NewWebhookSender(ch.URL, digestWebhookTimeout)
+		default:
+			return nil, fmt.Errorf("unknown type of digest channel %s: %s", ch.Name, ch.Type)
+		}
+		channels = append(channels, digest.Channel{Name: ch.Name, Schedule: sched, Sender: sender, Usage: ch.Usage})
+	}
+
+	return digest.NewReporter(stats, digest.Config{
+		Channels:     channels,
+		TopWorkloads: cfg.TopWorkloads,
+		ToilPerIssue: cfg.ToilPerIssue,
+		Sent: func(_ string, err error) {
+			if err != nil {
+				metricsCollector.RecordNotification("digest", "failed")
+			} else {
+				metricsCollector.RecordNotification("digest", "success")
+			}
+		},
+	}), nil
+}
+
+// slackDigestSender posts digests to a Slack channel
+type slackDigestSender struct {
+	notifier *notification.SlackNotifier
+	channel  string
+}
+
+// Send posts the digest
+func (s slackDigestSender) Send(ctx context.Context, summary digest.Summary) error {
+	return s.notifier.SendDigest(ctx, s.channel, summary)
+}
+
+// recordDetectedActivity counts newly detected issues for digests by workload and
+// for usage reports by team
+func (c *Controller) recordDetectedActivity(issues []detection.Issue) {
+	if c.activity == nil {
+		return
+	}
+	now := time.Now()
+	for _, issue := range issues {
+		kind, name := detection.WorkloadOf(issue)
+		c.activity.RecordDetected(issue.Namespace, kind, name, now)
+		c.activity.RecordUsage(issue.Owner.Team, issue.Namespace, digest.Usage{Issues: 1}, now)
+	}
+}
+
+// recordResolvedActivity counts resolved issues for digests by outcome
+func (c *Controller) recordResolvedActivity(records []tracker.Record) {
+	if c.activity == nil {
+		return
+	}
+	now := time.Now()
+	for _, record := range records {
+		c.activity.RecordResolved(record.Outcome() == tracker.OutcomeAutoResolved, now)
+	}
+}
+
+// recordRemediationActivity counts an executed remediation action for digests and
+// for usage reports by the team of the issue
+func (c *Controller) recordRemediationActivity(issue detection.Issue, success bool) {
+	if c.activity == nil {
+		return
+	}
+	now := time.Now()
+	c.activity.RecordRemediation(success, now)
+	usage := digest.Usage{Remediations: 1}
+	if !success {
+		usage.FailedRemediations = 1
+	}
+	c.activity.RecordUsage(issue.Owner.Team, issue.Namespace, usage, now)
+}
+
+// recordNotificationActivity counts a notification sent about an issue for usage
+// reports by the team of the issue
+func (c *Controller) recordNotificationActivity(issue detection.Issue) {
+	if c.activity != nil {
+		c.activity.RecordUsage(issue.Owner.Team, issue.Namespace, digest.Usage{Notifications: 1}, time.Now())
+	}
+}
+
+// UsageReport returns the remediations, notifications and issues of each team in
+// the month of t
+func (c *Controller) UsageReport(ctx context.Context, t time.Time) (*digest.UsageReport, error) {
+	if c.activity == nil {
+		return nil, ErrDigestDisabled
+	}
+	if err := c.activity.Load(ctx); err != nil {
+		return nil, err
+	}
+	report := c.activity.UsageReport(t)
+	return &report, nil
+}
@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/version"
+)
+
+// ConfigDriftRule is the rule of issues reporting instances whose configurations diverge
+const ConfigDriftRule = "config-drift"
+
+// ErrConfigDriftDisabled is returned when the configuration hashes of the
+// instances are requested while the drift detector is disabled
+var ErrConfigDriftDisabled = errors.New("config drift detection is not enabled")
+
+const (
+	// driftPeerTimeout bounds asking an instance of another cluster for its hash
+	driftPeerTimeout = 10 * time.Second
+	// driftPeerTokenFile is the default bearer token sent to peers, the token of
+	// the service account of the pod
+	driftPeerTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// driftPeerPath is the action API endpoint reporting the hash of a peer
+	driftPeerPath = "/api/v1/config/drift"
+	// driftHashLength is how much of a hash identifies it in issue descriptions
+	driftHashLength = 12
+)
+
+// ConfigInstance is the configuration hash published by a KubeGuardian instance
+type ConfigInstance struct {
+	Instance  string    `json:"instance"`
+	Cluster   string    `json:"cluster,omitempty"`
+	Hash      string    `json:"hash"`
+	Version   string    `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// name identifies the instance in drift reports, e.g. eu-west/kubeguardian-0
+func (i ConfigInstance) name() string {
+	if i.Cluster == "" {
+		return i.Instance
+	}
+	return i.Cluster + "/" + i.Instance
+}
+
+// ConfigDriftReport lists the configuration hashes of this instance and of the
+// live instances it compared them with at the last detection cycle
+type ConfigDriftReport struct {
+	Instance  ConfigInstance   `json:"instance"`
+	Instances []ConfigInstance `json:"instances"`
+	Drifted   bool             `json:"drifted"`
+}
+
+// driftDetector publishes the configuration hash of this instance to the
+// ConfigMap shared by the instances of a cluster, and compares it with the
+// hashes of the other instances and of the peers in other clusters
+type driftDetector struct {
+	client    kubernetes.Interface
+	config    config.ConfigDriftConfig
+	self      ConfigInstance
+	http      *http.Client
+	tokenFile string // Bearer token authenticating this instance to peers
+
+	mu        sync.Mutex
+	instances []ConfigInstance // Live instances of the last comparison
+}
+
+// newDriftDetector hashes the configuration of this instance, or returns nil if
+// the drift detector is disabled
+func newDriftDetector(client kubernetes.Interface, cfg *config.Config) (*driftDetector, error) {
+	settings := cfg.Detection.ConfigDrift
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	hash, err := cfg.Hash(settings.Ignore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash configuration: %w", err)
+	}
+	instance := settings.Instance
+	if instance == "" {
+		instance = os.Getenv("POD_NAME")
+	}
+	if instance == "" {
+		if instance, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("failed to name instance: %w", err)
+		}
+	}
+
+	peerClient := &http.Client{Timeout: driftPeerTimeout}
+	if settings.PeerCAFile != "" {
+		data, err := os.ReadFile(settings.PeerCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read peer CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in peer CA file %s", settings.PeerCAFile)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		peerClient.Transport = transport
+	}
+	tokenFile := settings.PeerTokenFile
+	if tokenFile == "" {
+		tokenFile = driftPeerTokenFile
+	}
+
+	return &driftDetector{
+		client:    client,
+		config:    settings,
+		self:      ConfigInstance{Instance: instance, Cluster: settings.Cluster, Hash: hash, Version: version.Version},
+		http:      peerClient,
+		tokenFile: tokenFile,
+	}, nil
+}
+
+// publish writes the hash of this instance to the shared ConfigMap, dropping
+// the entries of instances that went stale
+func (d *driftDetector) publish(ctx context.Context, now time.Time) error {
+	self := d.self
+	self.UpdatedAt = now
+	entry, err := json.Marshal(self)
+	if err != nil {
+		return err
+	}
+
+	configMaps := d.client.CoreV1().ConfigMaps(d.config.ConfigMapNamespace)
+	cm, err := configMaps.Get(ctx, d.config.ConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: d.config.ConfigMapName, Namespace: d.config.ConfigMapNamespace},
+			Data:       map[string]string{self.Instance: string(entry)},
+		}
+		_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	for key, value := range cm.Data {
+		var instance ConfigInstance
+		if json.Unmarshal([]byte(value), &instance) != nil || now.Sub(instance.UpdatedAt) > d.config.StaleAfter {
+			delete(cm.Data, key)
+		}
+	}
+	cm.Data[self.Instance] = string(entry)
+	// A conflict with another instance publishing at the same time is retried
+	// on the next cycle
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// compare reads the hashes of the live instances of the cluster and of the
+// peers; instances that cannot be read are left out. This instance is always
+// included, even if it could not publish its hash.
+func (d *driftDetector) compare(ctx context.Context, now time.Time) []ConfigInstance {
+	logger := log.FromContext(ctx)
+	self := d.self
+	self.UpdatedAt = now
+	instances := []ConfigInstance{self}
+
+	cm, err := d.client.CoreV1().ConfigMaps(d.config.ConfigMapNamespace).Get(ctx, d.config.ConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Failed to read configuration hashes", "configMap", d.config.ConfigMapNamespace+"/"+d.config.ConfigMapName)
+	}
+	if err == nil {
+		for key, value := range cm.Data {
+			var instance ConfigInstance
+			if err := json.Unmarshal([]byte(value), &instance); err != nil {
+				logger.Error(err, "Ignoring invalid configuration hash", "instance", key)
+				continue
+			}
+			if instance.Instance != self.Instance && now.Sub(instance.UpdatedAt) <= d.config.StaleAfter {
+				instances = append(instances, instance)
+			}
+		}
+	}
+
+	for _, peer := range d.config.Peers {
+		instance, err := d.fetchPeer(ctx, peer)
+		if err != nil {
+			logger.Error(err, "Failed to read configuration hash of peer", "peer", peer)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].name() < instances[j].name()
+	})
+	d.mu.Lock()
+	d.instances = instances
+	d.mu.Unlock()
+	return instances
+}
+
+// fetchPeer asks the action API of an instance in another cluster for its hash
+func (d *driftDetector) fetchPeer(ctx context.Context, peer string) (ConfigInstance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(peer, "/")+driftPeerPath, nil)
+	if err != nil {
+		return ConfigInstance{}, err
+	}
+	// The token is read for every request, so rotated tokens are picked up
+	token, err := os.ReadFile(d.tokenFile)
+	if err != nil {
+		return ConfigInstance{}, fmt.Errorf("failed to read peer token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return ConfigInstance{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ConfigInstance{}, fmt.Errorf("peer returned %s", resp.Status)
+	}
+
+	var report ConfigDriftReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return ConfigInstance{}, fmt.Errorf("invalid response: %w", err)
+	}
+	if report.Instance.Hash == "" {
+		return ConfigInstance{}, fmt.Errorf("peer reported no hash")
+	}
+	return report.Instance, nil
+}
+
+// report returns the hashes of the last comparison
+func (d *driftDetector) report() ConfigDriftReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	instances := append([]ConfigInstance{}, d.instances...)
+	return ConfigDriftReport{Instance: d.self, Instances: instances, Drifted: len(groupByHash(instances)) > 1}
+}
+
+// groupByHash returns the names of the instances by configuration hash
+func groupByHash(instances []ConfigInstance) map[string][]string {
+	groups := make(map[string][]string)
+	for _, instance := range instances {
+		groups[instance.Hash] = append(groups[instance.Hash], instance.name())
+	}
+	return groups
+}
+
+// detectConfigDrift publishes the configuration hash of this instance and
+// returns a config-drift issue while the live instances run different
+// configurations. In observe mode the hash is not published, so only peers are
+// compared.
+func (c *Controller) detectConfigDrift(ctx context.Context) []detection.Issue {
+	if c.drift == nil {
+		return nil
+	}
+	settings := c.drift.config
+	now := time.Now()
+
+	if !c.config.IsObserveMode() {
+		if err := c.drift.publish(ctx, now); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to publish configuration hash")
+		}
+	}
+
+	groups := groupByHash(c.drift.compare(ctx, now))
+	c.metrics.RecordConfigDrift(len(groups))
+	if len(groups) < 2 {
+		return nil
+	}
+
+	// The largest group comes first, usually the intended configuration
+	hashes := make([]string, 0, len(groups))
+	for hash := range groups {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		if len(groups[hashes[i]]) != len(groups[hashes[j]]) {
+			return len(groups[hashes[i]]) > len(groups[hashes[j]])
+		}
+		return hashes[i] < hashes[j]
+	})
+	parts := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		parts = append(parts, fmt.Sprintf("%s on %s", hash[:min(len(hash), driftHashLength)], strings.Join(groups[hash], ", ")))
+	}
+
+	severity := settings.Severity
+	if severity == "" {
+		severity = "high"
+	}
+	return []detection.Issue{{
+		RuleName:    ConfigDriftRule,
+		Description: "KubeGuardian instances run different configurations: " + strings.Join(parts, "; "),
+		Severity:    severity,
+		Resource: &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: settings.ConfigMapName, Namespace: settings.ConfigMapNamespace},
+		},
+		Namespace:  settings.ConfigMapNamespace,
+		Name:       settings.ConfigMapName,
+		Kind:       "ConfigMap",
+		Reason:     "ConfigDrift",
+		DetectedAt: now,
+	}}
+}
+
+// ConfigDrift returns the configuration hash of this instance and the hashes of
+// the live instances compared at the last detection cycle
+func (c *Controller) ConfigDrift(ctx context.Context) (ConfigDriftReport, error) {
+	if c.drift == nil {
+		return ConfigDriftReport{}, ErrConfigDriftDisabled
+	}
+	return c.drift.report(), nil
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// newIssueTracker creates the issue tracker, raising the severity of issues that
+// stay unresolved if escalation is enabled
+func newIssueTracker(cfg config.EscalationConfig) *tracker.Tracker {
+	t := tracker.NewTracker()
+	if !cfg.Enabled {
+		return t
+	}
+
+	steps := make([]tracker.EscalationStep, 0, len(cfg.Steps))
+	for _, step := range cfg.Steps {
+		steps = append(steps, tracker.EscalationStep{After: step.After, Severity: step.Severity})
+	}
+	t.SetEscalation(tracker.EscalationPolicy{Steps: steps, Hysteresis: cfg.Hysteresis})
+	return t
+}
+
+// escalateIssues records issues whose severity was raised in this cycle and
+// notifies them again without waiting for the repeat interval, so the
+// notification reaches the channel of the new severity
+func (c *Controller) escalateIssues(ctx context.Context, issues []detection.Issue) {
+	logger := log.FromContext(ctx)
+	for _, issue := range issues {
+		logger.Info("Issue severity escalated",
+			"rule", issue.RuleName,
+			"resource", issue.Name,
+			"namespace", issue.Namespace,
+			"from", issue.EscalatedFrom,
+			"severity", issue.Severity)
+		c.metrics.RecordIssueSeverityEscalated(issue.RuleName, issue.Severity, issue.Namespace)
+		c.notifications.Forget(issue.Fingerprint())
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// Stages at which workloads are excluded
+const (
+	stageDetection   = "detection"
+	stageRemediation = "remediation"
+)
+
+// workloadExclusions are the workloads whose issues are dropped at detection, or
+// detected but never remediated automatically
+type workloadExclusions struct {
+	detection   []detection.Exclusion
+	remediation []detection.Exclusion
+}
+
+// newWorkloadExclusions converts the configured workload exclusions
+func newWorkloadExclusions(cfg *config.Config) (workloadExclusions, error) {
+	var exclusions workloadExclusions
+	var err error
+	if exclusions.detection, err = newExclusions(cfg.Detection.Exclude); err != nil {
+		return exclusions, err
+	}
+	exclusions.remediation, err = newExclusions(cfg.Remediation.Exclude)
+	return exclusions, err
+}
+
+// newExclusions converts configured workload exclusions; patterns must match the
+// whole workload name
+func newExclusions(configs []config.ExclusionConfig) ([]detection.Exclusion, error) {
+	exclusions := make([]detection.Exclusion, 0, len(configs))
+	for _, cfg := range configs {
+		exclusion := detection.Exclusion{Namespace: cfg.Namespace, Kind: cfg.Kind, Name: cfg.Name}
+		if cfg.Pattern != "" {
+			pattern, err := regexp.Compile("^(?:" + cfg.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclusion pattern %q: %w", cfg.Pattern, err)
+			}
+			exclusion.Pattern = pattern
+		}
+		exclusions = append(exclusions, exclusion)
+	}
+	return exclusions, nil
+}
+
+// excludeIssues drops the issues of workloads excluded from detection
+func (c *Controller) excludeIssues(ctx context.Context, issues []detection.Issue) []detection.Issue {
+	if len(c.exclusions.detection) == 0 {
+		return issues
+	}
+
+	kept := issues[:0]
+	for _, issue := range issues {
+		if c.excluded(ctx, stageDetection, c.exclusions.detection, issue) {
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// excluded returns true if an exclusion of the stage matches the workload of issue
+func (c *Controller) excluded(ctx context.Context, stage string, exclusions []detection.Exclusion, issue detection.Issue) bool {
+	i := detection.Excluded(exclusions, issue)
+	if i < 0 {
+		return false
+	}
+
+	kind, name := detection.WorkloadOf(issue)
+	log.FromContext(ctx).V(1).Info("Skipping issue of excluded workload",
+		"stage", stage,
+		"rule", issue.RuleName,
+		"namespace", issue.Namespace,
+		"workload", kind+"/"+name,
+		"exclusion", i)
+	c.metrics.RecordIssueExcluded(stage, issue.RuleName)
+	return true
+}
+
+// newContainerRules converts the configured container rules; patterns must match
+// the whole container name
+func newContainerRules(configs []config.ContainerRuleConfig) ([]detection.ContainerRule, error) {
+	containers := make([]detection.ContainerRule, 0, len(configs))
+	for _, cfg := range configs {
+		container := detection.ContainerRule{
+			Rules:      cfg.Rules,
+			Name:       cfg.Name,
+			Ignore:     cfg.Ignore,
+			NotifyOnly: cfg.NotifyOnly,
+			Actions:    cfg.Actions,
+		}
+		if cfg.Pattern != "" {
+			pattern, err := regexp.Compile("^(?:" + cfg.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid container pattern %q: %w", cfg.Pattern, err)
+			}
+			container.Pattern = pattern
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/features"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+)
+
+// FeatureEnabled returns true if the feature flag is enabled
+func (c *Controller) FeatureEnabled(name string) bool {
+	return c.features.Enabled(name)
+}
+
+// gatedActions maps remediation actions to the feature flag that enables them
+var gatedActions = map[string]string{
+	remediation.ActionCordonNode:      features.NodeActions,
+	remediation.ActionCordonDrainNode: features.NodeActions,
+}
+
+// actionGated returns the feature flag of an action and true if the flag is disabled
+func (c *Controller) actionGated(action string) (string, bool) {
+	flag, gated := gatedActions[action]
+	return flag, gated && !c.FeatureEnabled(flag)
+}
+
+// Features returns the status of all feature flags
+func (c *Controller) Features() []features.Status {
+	return c.features.List()
+}
+
+// SetFeature enables or disables a feature flag at runtime on behalf of the requester.
+// The change is not persisted and is reset to the configuration on restart.
+func (c *Controller) SetFeature(ctx context.Context, name string, enabled bool, requester remediation.Requester) (features.Status, error) {
+	status, err := c.features.Set(name, enabled)
+	if err != nil {
+		return features.Status{}, err
+	}
+
+	c.metrics.RecordFeatureEnabled(name, enabled)
+	log.FromContext(ctx).Info("Feature flag changed", "feature", name, "enabled", enabled, "requestedBy", requester.User)
+	return status, nil
+}
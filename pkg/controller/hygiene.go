@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+)
+
+// ErrHygieneDisabled is returned when a hygiene report is requested while disabled
+var ErrHygieneDisabled = errors.New("hygiene reports are not enabled")
+
+// hygienePageSize bounds the workloads listed per request by hygiene scans
+const hygienePageSize = 500
+
+// newHygieneReporter creates the reporter of advisory workload findings, or nil if
+// hygiene reports are disabled. Reports are posted to Slack if it is enabled;
+// the findings of each scan are recorded in metrics either way.
+func newHygieneReporter(client kubernetes.Interface, cfg config.HygieneConfig, slackNotifier *notification.SlackNotifier, metricsCollector *metrics.Metrics) (*hygiene.Reporter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	sched, err := schedule.Parse(cfg.Cron, cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hygiene schedule: %w", err)
+	}
+	scanner := hygiene.NewScanner(client, hygiene.Config{
+		Checks:               cfg.Checks,
+		ProductionNamespaces: cfg.ProductionNamespaces,
+		ExcludeNamespaces:    cfg.ExcludeNamespaces,
+		PageSize:             hygienePageSize,
+	})
+
+	reporterConfig := hygiene.ReporterConfig{
+		Schedule: sched,
+		Scanned: func(report *hygiene.Report) {
+			metricsCollector.RecordHygieneFindings(report.ByNamespace())
+		},
+	}
+	if slackNotifier != nil {
+		reporterConfig.Send = func(ctx context.Context, report *hygiene.Report) error {
+			err := slackNotifier.SendHygieneReport(ctx, cfg.SlackChannel, report, cfg.MaxFindings)
+			if err != nil {
+				metricsCollector.RecordNotification("hygiene", "failed")
+			} else {
+				metricsCollector.RecordNotification("hygiene", "success")
+			}
+			return err
+		}
+	}
+	return hygiene.NewReporter(scanner, reporterConfig), nil
+}
+
+// HygieneReport scans the workloads of the cluster now and returns the advisory findings
+func (c *Controller) HygieneReport(ctx context.Context) (*hygiene.Report, error) {
+	if c.hygiene == nil {
+		return nil, ErrHygieneDisabled
+	}
+	return c.hygiene.Scan(ctx)
+}
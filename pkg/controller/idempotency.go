@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// idempotencyRetention is how long idempotency keys are kept; it bounds the
+// cooldown windows they can enforce
+const idempotencyRetention = 24 * time.Hour
+
+// newIdempotencyStore creates the store for idempotency keys of remediation actions.
+// It uses the state backend, so keys survive restarts and leader failovers.
+func newIdempotencyStore(client kubernetes.Interface, cfg config.StateConfig) (*detection.StateStore, error) {
+	backend, err := newStateBackend[map[string]detection.ConditionState](client, cfg, detection.RemediationsConfigMapKey, "remediations")
+	if err != nil {
+		return nil, err
+	}
+	return detection.NewStateStore(backend), nil
+}
+
+// idempotencyKey identifies a remediation action for an issue at the current
+// generation of the resource, so a changed spec can be remediated again
+func idempotencyKey(issue detection.Issue, action string) string {
+	var generation int64
+	if obj, err := meta.Accessor(issue.Resource); err == nil {
+		generation = obj.GetGeneration()
+	}
+	return fmt.Sprintf("%s/%s/%d", issue.Fingerprint(), action, generation)
+}
+
+// claimRemediation returns true unless the action was already executed for the
+// issue within the cooldown window. The claim is persisted before the action runs.
+func (c *Controller) claimRemediation(ctx context.Context, key string, window time.Duration) bool {
+	if !c.remediations.Claim(key, time.Now(), window) {
+		return false
+	}
+	if err := c.remediations.Flush(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to persist remediation idempotency key", "key", key)
+		c.metrics.RecordStateStoreError("save")
+	}
+	return true
+}
+
+// releaseRemediation removes the claim of an action that did not succeed, so it can be retried
+func (c *Controller) releaseRemediation(ctx context.Context, key string) {
+	c.remediations.Forget(key)
+	if err := c.remediations.Flush(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to persist remediation idempotency key", "key", key)
+		c.metrics.RecordStateStoreError("save")
+	}
+}
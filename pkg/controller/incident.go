@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+)
+
+// Errors of incident mode requests
+var (
+	// ErrInvalidIncident is returned for an incident without a scope or a valid duration
+	ErrInvalidIncident = errors.New("invalid incident")
+	// ErrIncidentNotFound is returned when ending an incident that is not active
+	ErrIncidentNotFound = errors.New("incident not found")
+)
+
+// defaultIncidentMaxDuration bounds incidents without a configured maximum duration
+const defaultIncidentMaxDuration = 4 * time.Hour
+
+// IncidentRequest starts incident mode for the issues of a namespace, of a set of
+// rules, or of a set of rules in a namespace
+type IncidentRequest struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Rules     []string `json:"rules,omitempty"`
+	// Duration is how long the relaxation lasts, e.g. 30m, at most the configured maximum
+	Duration string `json:"duration"`
+	// CooldownSeconds replaces the cooldown of the actions of matching issues;
+	// zero lets actions repeat on every cycle
+	CooldownSeconds int    `json:"cooldownSeconds"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// Incident is an active incident mode: until it expires, remediation of matching
+// issues uses its cooldown and is not limited by the per-cycle action budget
+type Incident struct {
+	ID              string    `json:"id"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Rules           []string  `json:"rules,omitempty"`
+	CooldownSeconds int       `json:"cooldownSeconds"`
+	Reason          string    `json:"reason,omitempty"`
+	StartedBy       string    `json:"startedBy"`
+	StartedAt       time.Time `json:"startedAt"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
+// matches returns true if the incident relaxes the remediation of an issue
+func (i *Incident) matches(issue detection.Issue) bool {
+	return (i.Namespace == "" || i.Namespace == issue.Namespace) &&
+		(len(i.Rules) == 0 || slices.Contains(i.Rules, issue.RuleName))
+}
+
+// scope describes the issues the incident applies to
+func (i *Incident) scope() string {
+	var parts []string
+	if i.Namespace != "" {
+		parts = append(parts, "namespace "+i.Namespace)
+	}
+	if len(i.Rules) > 0 {
+		parts = append(parts, "rules "+strings.Join(i.Rules, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// incidentMode holds the active incidents; incidents past their expiry no longer
+// apply and are removed on the next cycle
+type incidentMode struct {
+	mu        sync.Mutex
+	incidents map[string]*Incident
+}
+
+// newIncidentMode creates an incident mode without active incidents
+func newIncidentMode() *incidentMode {
+	return &incidentMode{incidents: make(map[string]*Incident)}
+}
+
+// active returns the incident relaxing the remediation of an issue at now; of
+// several matching incidents, the one with the shortest cooldown applies
+func (m *incidentMode) active(issue detection.Issue, now time.Time) (Incident, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var match *Incident
+	for _, incident := range m.incidents {
+		if now.Before(incident.ExpiresAt) && incident.matches(issue) && (match == nil || incident.CooldownSeconds < match.CooldownSeconds) {
+			match = incident
+		}
+	}
+	if match == nil {
+		return Incident{}, false
+	}
+	return *match, true
+}
+
+// list returns the incidents active at now, the earliest expiring first
+func (m *incidentMode) list(now time.Time) []Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	incidents := []Incident{}
+	for _, incident := range m.incidents {
+		if now.Before(incident.ExpiresAt) {
+			incidents = append(incidents, *incident)
+		}
+	}
+	sort.Slice(incidents, func(i, j int) bool {
+		if !incidents[i].ExpiresAt.Equal(incidents[j].ExpiresAt) {
+			return incidents[i].ExpiresAt.Before(incidents[j].ExpiresAt)
+		}
+		return incidents[i].ID < incidents[j].ID
+	})
+	return incidents
+}
+
+// add starts an incident
+func (m *incidentMode) add(incident Incident) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.incidents[incident.ID] = &incident
+}
+
+// remove ends an incident and returns it, or false if it is not active at now
+func (m *incidentMode) remove(id string, now time.Time) (Incident, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	incident, exists := m.incidents[id]
+	if !exists || !now.Before(incident.ExpiresAt) {
+		return Incident{}, false
+	}
+	delete(m.incidents, id)
+	return *incident, true
+}
+
+// expire removes and returns the incidents that expired by now
+func (m *incidentMode) expire(now time.Time) []Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expired []Incident
+	for id, incident := range m.incidents {
+		if !now.Before(incident.ExpiresAt) {
+			expired = append(expired, *incident)
+			delete(m.incidents, id)
+		}
+	}
+	return expired
+}
+
+// newIncidentID returns a random identifier for an incident
+func newIncidentID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// StartIncident starts incident mode on behalf of the requester: until the
+// duration has passed, remediation actions of the issues in the namespace or of
+// the rules of the request use its cooldown and bypass the per-cycle action
+// budget. Incidents are not persisted; a restart ends them.
+func (c *Controller) StartIncident(ctx context.Context, req IncidentRequest, requester remediation.Requester) (Incident, error) {
+	if c.remediator == nil {
+		return Incident{}, fmt.Errorf("%w: remediation is disabled", ErrInvalidIncident)
+	}
+	if req.Namespace == "" && len(req.Rules) == 0 {
+		return Incident{}, fmt.Errorf("%w: namespace or rules are required", ErrInvalidIncident)
+	}
+	for _, name := range req.Rules {
+		if _, found := c.rule(name); !found {
+			return Incident{}, fmt.Errorf("%w: %s", ErrUnknownRule, name)
+		}
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		return Incident{}, fmt.Errorf("%w: duration must be positive, e.g. 30m", ErrInvalidIncident)
+	}
+	maximum := c.config.Remediation.IncidentMode.MaxDuration
+	if maximum <= 0 {
+		maximum = defaultIncidentMaxDuration
+	}
+	if duration > maximum {
+		return Incident{}, fmt.Errorf("%w: duration must not exceed %s", ErrInvalidIncident, maximum)
+	}
+	if req.CooldownSeconds < 0 {
+		return Incident{}, fmt.Errorf("%w: cooldownSeconds must not be negative", ErrInvalidIncident)
+	}
+
+	now := time.Now()
+	incident := Incident{
+		ID:              newIncidentID(),
+		Namespace:       req.Namespace,
+		Rules:           req.Rules,
+		CooldownSeconds: req.CooldownSeconds,
+		Reason:          req.Reason,
+		StartedBy:       requester.User,
+		StartedAt:       now,
+		ExpiresAt:       now.Add(duration),
+	}
+	c.incidents.add(incident)
+	c.metrics.RecordIncidentMode(len(c.incidents.list(now)))
+
+	log.FromContext(ctx).Info("Incident mode started",
+		"incident", incident.ID,
+		"scope", incident.scope(),
+		"cooldownSeconds", incident.CooldownSeconds,
+		"expiresAt", incident.ExpiresAt,
+		"reason", incident.Reason,
+		"requestedBy", requester.User)
+	c.announceIncident(ctx, incident, "")
+	return incident, nil
+}
+
+// EndIncident ends an active incident on behalf of the requester
+func (c *Controller) EndIncident(ctx context.Context, id string, requester remediation.Requester) (Incident, error) {
+	now := time.Now()
+	incident, found := c.incidents.remove(id, now)
+	if !found {
+		return Incident{}, fmt.Errorf("%w: %s", ErrIncidentNotFound, id)
+	}
+	c.metrics.RecordIncidentMode(len(c.incidents.list(now)))
+
+	log.FromContext(ctx).Info("Incident mode ended", "incident", id, "scope", incident.scope(), "requestedBy", requester.User)
+	c.announceIncident(ctx, incident, "ended by "+requester.User)
+	return incident, nil
+}
+
+// Incidents returns the active incidents
+func (c *Controller) Incidents() []Incident {
+	return c.incidents.list(time.Now())
+}
+
+// expireIncidents reverts the incidents that expired since the previous cycle
+func (c *Controller) expireIncidents(ctx context.Context) {
+	now := time.Now()
+	expired := c.incidents.expire(now)
+	if len(expired) == 0 {
+		return
+	}
+	c.metrics.RecordIncidentMode(len(c.incidents.list(now)))
+	for _, incident := range expired {
+		log.FromContext(ctx).Info("Incident mode expired", "incident", incident.ID, "scope", incident.scope())
+		c.announceIncident(ctx, incident, "expired")
+	}
+}
+
+// announceIncident notifies Slack that incident mode started, or ended for the
+// given reason, so the relaxation happens under the eyes of the operators
+func (c *Controller) announceIncident(ctx context.Context, incident Incident, ended string) {
+	if c.slackNotifier == nil {
+		return
+	}
+	err := c.slackNotifier.SendIncidentMode(ctx, notification.IncidentMode{
+		ID:              incident.ID,
+		Scope:           incident.scope(),
+		CooldownSeconds: incident.CooldownSeconds,
+		Reason:          incident.Reason,
+		StartedBy:       incident.StartedBy,
+		ExpiresAt:       incident.ExpiresAt,
+		Ended:           ended,
+	})
+	if err != nil {
+		c.metrics.RecordNotification("incident", "failed")
+	} else {
+		c.metrics.RecordNotification("incident", "success")
+	}
+}
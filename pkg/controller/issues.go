@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"sort"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// ActiveIssues returns the records of the currently active issues, oldest first
+func (c *Controller) ActiveIssues() []tracker.Record {
+	records := c.tracker.Active()
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].FirstDetected.Equal(records[j].FirstDetected) {
+			return records[i].FirstDetected.Before(records[j].FirstDetected)
+		}
+		return records[i].Fingerprint < records[j].Fingerprint
+	})
+	return records
+}
+
+// Rules returns the loaded detection rules with their owner, source and last
+// change, and whether they were disabled at runtime
+func (c *Controller) Rules() []RuleStatus {
+	rules := c.detector.Rules()
+	statuses := make([]RuleStatus, 0, len(rules))
+	for _, rule := range rules {
+		statuses = append(statuses, c.ruleStatus(rule))
+	}
+	return statuses
+}
+
+// ruleMetadata returns the metadata of a loaded rule, or none for unknown rules
+// such as manual actions
+func (c *Controller) ruleMetadata(name string) detection.RuleMetadata {
+	rule, _ := c.rule(name)
+	return rule.Metadata
+}
+
+// TraceRule evaluates a single rule against a single resource and returns the
+// condition-by-condition evaluation trace, e.g. to debug why a rule did not fire
+func (c *Controller) TraceRule(ctx context.Context, rule, namespace, name string) (*detection.Trace, error) {
+	return c.detector.TraceRule(ctx, rule, namespace, name)
+}
+
+// Decisions returns the decision counts of the last detection cycle and the
+// sampled decisions matching a query
+func (c *Controller) Decisions(query detection.DecisionQuery) (detection.DecisionReport, error) {
+	return c.detector.Decisions(query)
+}
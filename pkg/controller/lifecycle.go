@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/version"
+)
+
+// runKey is the lifecycle state of the latest run: FirstSeen is when it started
+// and LastSeen when it was last known to be running
+const runKey = "run"
+
+// shutdownNotificationTimeout bounds the shutdown notification, which is sent
+// after the controller context was cancelled
+const shutdownNotificationTimeout = 5 * time.Second
+
+// runLifecycle tracks the current run so startup and shutdown notifications can
+// be suppressed while KubeGuardian is flapping
+type runLifecycle struct {
+	store       *detection.StateStore
+	minUptime   time.Duration
+	started     time.Time
+	established bool // The run lasted minUptime and this was persisted
+}
+
+// newLifecycleStore creates the store for the lifecycle state of runs. It uses
+// the state backend, so the previous run is known after a restart.
+func newLifecycleStore(client kubernetes.Interface, cfg config.StateConfig) (*detection.StateStore, error) {
+	backend, err := newStateBackend[map[string]detection.ConditionState](client, cfg, detection.LifecycleConfigMapKey, "lifecycle")
+	if err != nil {
+		return nil, err
+	}
+	return detection.NewStateStore(backend), nil
+}
+
+// lastedMinUptime returns true if a run lasted long enough to be notified about
+func lastedMinUptime(run detection.ConditionState, minUptime time.Duration) bool {
+	return run.LastSeen.Sub(run.FirstSeen) >= minUptime
+}
+
+// startRun records the start of this run and returns true if the startup should
+// be notified: on the first start, and after a previous run that lasted minUptime
+func (c *Controller) startRun(ctx context.Context) bool {
+	logger := log.FromContext(ctx)
+	now := time.Now()
+	c.lifecycle.started = now
+
+	notify := true
+	if err := c.lifecycle.store.Load(ctx); err != nil {
+		logger.Error(err, "Failed to load lifecycle state")
+		c.metrics.RecordStateStoreError("load")
+	} else if previous, found := c.lifecycle.store.Get(runKey); found && !lastedMinUptime(previous, c.lifecycle.minUptime) {
+		logger.Info("Previous run stopped before the minimum uptime: suppressing startup notification",
+			"previousStart", previous.FirstSeen,
+			"previousUptime", previous.LastSeen.Sub(previous.FirstSeen).Round(time.Second),
+			"minUptime", c.lifecycle.minUptime)
+		notify = false
+	}
+
+	c.lifecycle.store.Forget(runKey)
+	c.lifecycle.store.Observe(runKey, now)
+	c.flushLifecycle(ctx)
+	return notify
+}
+
+// establishRun records once that this run lasted minUptime, so a later crash
+// does not suppress the next startup notification
+func (c *Controller) establishRun(ctx context.Context) {
+	if c.lifecycle.established || time.Since(c.lifecycle.started) < c.lifecycle.minUptime {
+		return
+	}
+	c.lifecycle.store.Observe(runKey, time.Now())
+	c.flushLifecycle(ctx)
+	c.lifecycle.established = true
+}
+
+// stopRun records the end of this run and sends the shutdown notification
+// unless it is disabled or the run did not last minUptime
+func (c *Controller) stopRun(ctx context.Context) {
+	// The controller context is already cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownNotificationTimeout)
+	defer cancel()
+
+	run := c.lifecycle.store.Observe(runKey, time.Now())
+	c.flushLifecycle(ctx)
+
+	if c.slackNotifier == nil || !c.config.Notification.Lifecycle.Shutdown {
+		return
+	}
+	if !lastedMinUptime(run, c.lifecycle.minUptime) {
+		log.FromContext(ctx).Info("Run stopped before the minimum uptime: suppressing shutdown notification", "uptime", run.LastSeen.Sub(run.FirstSeen).Round(time.Second))
+		return
+	}
+	if err := c.slackNotifier.SendShutdownNotification(ctx, version.Version, run.LastSeen.Sub(run.FirstSeen)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send shutdown notification")
+	}
+}
+
+// flushLifecycle persists the lifecycle state
+func (c *Controller) flushLifecycle(ctx context.Context) {
+	if err := c.lifecycle.store.Flush(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to save lifecycle state")
+		c.metrics.RecordStateStoreError("save")
+	}
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/capabilities"
+	"github.com/NotHarshhaa/kubeguardian/pkg/permissions"
+)
+
+// PreflightCheck is the outcome of a single preflight check
+type PreflightCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Warning marks a failed check that KubeGuardian can run without
+	Warning bool   `json:"warning,omitempty"`
+	Message string `json:"message"`
+}
+
+// PreflightReport is the outcome of all preflight checks
+type PreflightReport struct {
+	Checks []PreflightCheck `json:"checks"`
+}
+
+// add appends a check to the report
+func (r *PreflightReport) add(name string, passed, warning bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{
+		Name:    name,
+		Passed:  passed,
+		Warning: !passed && warning,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Passed returns true if no check failed; failed checks that are only warnings pass
+func (r *PreflightReport) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed && !check.Warning {
+			return false
+		}
+	}
+	return true
+}
+
+// Summary returns a human-readable pass/fail report
+func (r *PreflightReport) Summary() string {
+	var b strings.Builder
+	for _, check := range r.Checks {
+		status := "[FAIL]"
+		switch {
+		case check.Passed:
+			status = "[PASS]"
+		case check.Warning:
+			status = "[WARN]"
+		}
+		fmt.Fprintf(&b, "%s %s: %s\n", status, check.Name, check.Message)
+	}
+	return b.String()
+}
+
+// Preflight checks that KubeGuardian can run with its configuration: API
+// connectivity, RBAC permissions, cluster capabilities, notifier credentials,
+// rules and configuration consistency
+func (c *Controller) Preflight(ctx context.Context) *PreflightReport {
+	report := &PreflightReport{}
+
+	validation := c.config.Validate()
+	report.add("config", validation.Valid, false, "%d errors, %d warnings%s",
+		len(validation.Errors), len(validation.Warnings), listSuffix(append(validation.Errors, validation.Warnings...)))
+
+	c.preflightRules(report)
+
+	info, err := c.client.Discovery().ServerVersion()
+	if err != nil {
+		report.add("api connectivity", false, false, "%v", err)
+		return report
+	}
+	report.add("api connectivity", true, false, "Kubernetes %s", info.GitVersion)
+
+	if permissionReport, err := c.CheckPermissions(ctx); err != nil {
+		report.add("rbac", false, false, "%v", err)
+	} else {
+		var missing []string
+		for _, result := range permissionReport.Detection {
+			if !result.Allowed {
+				missing = append(missing, "detection: "+result.Requirement.String())
+			}
+		}
+		for feature, reqs := range permissionReport.MissingFeatures() {
+			missing = append(missing, fmt.Sprintf("%s: %s", feature, strings.Join(requirementNames(reqs), ", ")))
+		}
+		// Another system applies the actions of a record or webhook executor
+		if c.appliesActions() {
+			for action, reqs := range permissionReport.MissingActions() {
+				missing = append(missing, fmt.Sprintf("%s: %s", action, strings.Join(requirementNames(reqs), ", ")))
+			}
+		}
+		sort.Strings(missing)
+		report.add("rbac", len(missing) == 0, false, "%d missing permissions%s", len(missing), listSuffix(missing))
+	}
+
+	if capabilityReport, err := c.CheckCapabilities(ctx); err != nil {
+		report.add("capabilities", false, true, "%v", err)
+	} else {
+		var unsupported []string
+		if c.appliesActions() {
+			for action, missing := range capabilityReport.MissingActions(c.enabledActions()) {
+				unsupported = append(unsupported, fmt.Sprintf("%s: %s", action, strings.Join(missing, ", ")))
+			}
+		}
+		sort.Strings(unsupported)
+		report.add("capabilities", len(unsupported) == 0, true, "%d unsupported actions%s", len(unsupported), listSuffix(unsupported))
+
+		available := capabilityReport.Has(capabilities.MetricsServer)
+		report.add("metrics-server", available, true, "metrics.k8s.io served: %t", available)
+	}
+
+	if c.slackNotifier == nil {
+		report.add("slack", true, false, "disabled")
+	} else if err := c.slackNotifier.TestConnection(ctx); err != nil {
+		report.add("slack", false, false, "%v", err)
+	} else {
+		report.add("slack", true, false, "credentials valid")
+	}
+
+	return report
+}
+
+// preflightRules checks that the enabled rules and their container rules only use
+// built-in actions and the actions of plugins
+func (c *Controller) preflightRules(report *PreflightReport) {
+	plugins := pluginActions(c.config.Remediation.Plugins)
+	rules := c.detector.Rules()
+	enabled := 0
+	var unknown []string
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		enabled++
+
+		actions := append([]string{}, rule.Actions...)
+		for _, container := range rule.Containers {
+			actions = append(actions, container.Actions...)
+		}
+		for _, action := range actions {
+			if _, known := permissions.ActionRequirements[action]; !known && !slices.Contains(plugins, action) {
+				unknown = append(unknown, fmt.Sprintf("%s: unknown action %s", rule.Name, action))
+			}
+		}
+	}
+	report.add("rules", len(unknown) == 0, false, "%d rules loaded, %d enabled%s", len(rules), enabled, listSuffix(unknown))
+}
+
+// listSuffix formats details appended to a check message
+func listSuffix(details []string) string {
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, "; ") + ")"
+}
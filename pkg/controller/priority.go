@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// actionBudget limits the number of remediation actions executed in one cycle.
+// A nil budget is unlimited.
+type actionBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// newActionBudget creates a budget for one cycle, or nil if max is not positive
+func newActionBudget(max int) *actionBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &actionBudget{remaining: max}
+}
+
+// take consumes one action from the budget and returns false if it is exhausted
+func (b *actionBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// issuePriority is the sort key of an issue
+type issuePriority struct {
+	tier     int
+	priority int32
+	severity int
+}
+
+// prioritizeIssues orders issues so that critical workloads are remediated first:
+// by namespace tier (lower first), PriorityClass value (higher first), then severity
+func (c *Controller) prioritizeIssues(ctx context.Context, issues []detection.Issue) []detection.Issue {
+	classes := make(map[string]int32) // Key: PriorityClass name, cached for the cycle
+	keys := make([]issuePriority, len(issues))
+	for i, issue := range issues {
+		keys[i] = issuePriority{
+			tier:     c.config.NamespaceTier(issue.Namespace),
+			priority: c.workloadPriority(ctx, issue, classes),
+			severity: config.SeverityRank(issue.Severity),
+		}
+	}
+
+	order := make([]int, len(issues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ka, kb := keys[order[a]], keys[order[b]]
+		if ka.tier != kb.tier {
+			return ka.tier < kb.tier
+		}
+		if ka.priority != kb.priority {
+			return ka.priority > kb.priority
+		}
+		return ka.severity > kb.severity
+	})
+
+	sorted := make([]detection.Issue, len(issues))
+	for i, idx := range order {
+		sorted[i] = issues[idx]
+	}
+	return sorted
+}
+
+// workloadPriority returns the scheduling priority of the workload affected by an issue
+func (c *Controller) workloadPriority(ctx context.Context, issue detection.Issue, classes map[string]int32) int32 {
+	var spec *corev1.PodSpec
+	switch r := issue.Resource.(type) {
+	case *corev1.Pod:
+		spec = &r.Spec
+	case *appsv1.Deployment:
+		spec = &r.Spec.Template.Spec
+	default:
+		return 0
+	}
+
+	// Pods have the resolved priority set by admission
+	if spec.Priority != nil {
+		return *spec.Priority
+	}
+	if spec.PriorityClassName == "" {
+		return 0
+	}
+
+	if value, exists := classes[spec.PriorityClassName]; exists {
+		return value
+	}
+
+	var value int32
+	class, err := c.client.SchedulingV1().PriorityClasses().Get(ctx, spec.PriorityClassName, metav1.GetOptions{})
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to get priority class", "priorityClass", spec.PriorityClassName, "error", err.Error())
+	} else {
+		value = class.Value
+	}
+	classes[spec.PriorityClassName] = value
+	return value
+}
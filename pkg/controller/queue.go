@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/queue"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+)
+
+// ErrQueueDisabled is returned when the remediation queue is inspected while disabled
+var ErrQueueDisabled = errors.New("remediation queue is not enabled")
+
+// Outcomes of remediation queue items reported in metrics
+const (
+	queueEnqueued = "enqueued"
+	queueDone     = "done"
+	queueRetried  = "retried"
+	queueDropped  = "dropped"
+	queueDrained  = "drained"
+)
+
+// newRemediationQueue creates the remediation queue from configuration, or nil if
+// disabled. It uses the state backend, so queued actions survive restarts.
+func newRemediationQueue(client kubernetes.Interface, cfg *config.Config) (*queue.Queue, error) {
+	if !cfg.Remediation.Queue.Enabled {
+		return nil, nil
+	}
+
+	backend, err := newStateBackend[[]queue.Item](client, stateConfig(cfg), queue.ConfigMapKey, "queue")
+	if err != nil {
+		return nil, err
+	}
+
+	return queue.New(queue.Config{
+		Backend:        backend,
+		Rate:           cfg.Remediation.Queue.RatePerSecond,
+		Burst:          cfg.Remediation.Queue.Burst,
+		MaxAttempts:    cfg.Remediation.Queue.MaxAttempts,
+		InitialBackoff: cfg.Remediation.Queue.InitialBackoff,
+		MaxBackoff:     cfg.Remediation.Queue.MaxBackoff,
+	}), nil
+}
+
+// enqueueRemediation queues a remediation action for an issue under its idempotency key
+func (c *Controller) enqueueRemediation(ctx context.Context, issue detection.Issue, action, key string) {
+	traceID, _ := tracing.TraceIDFromContext(ctx)
+	added := c.queue.Add(queue.Item{
+		Key:         key,
+		Action:      action,
+		Rule:        issue.RuleName,
+		Severity:    issue.Severity,
+		Namespace:   issue.Namespace,
+		Kind:        issue.Kind,
+		Name:        issue.Name,
+		Container:   issue.Container,
+		Reason:      issue.Reason,
+		Description: issue.Description,
+		TraceID:     traceID,
+	})
+	if !added {
+		return
+	}
+	log.FromContext(ctx).Info("Queued remediation action", "action", action, "resource", issue.Name, "namespace", issue.Namespace, "key", key)
+	c.metrics.RecordRemediationQueue(queueEnqueued, 1, c.queue.Len())
+	c.flushQueue(ctx)
+}
+
+// runQueue executes queued remediation actions as they become due until the
+// context is cancelled
+func (c *Controller) runQueue(ctx context.Context) {
+	if c.queue == nil {
+		return
+	}
+
+	ticker := time.NewTicker(c.config.Remediation.Queue.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.processQueue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processQueue executes the queued actions that are due, within the rate limit
+func (c *Controller) processQueue(ctx context.Context) {
+	// Restore actions queued before a restart
+	if err := c.queue.Load(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to load remediation queue")
+		c.metrics.RecordStateStoreError("load")
+		return
+	}
+
+	for ctx.Err() == nil {
+		item, ok := c.queue.Next()
+		if !ok {
+			return
+		}
+		// Persist the attempt before executing it
+		c.flushQueue(ctx)
+		c.executeQueued(ctx, item)
+	}
+}
+
+// executeQueued executes a queued action against the current state of its
+// resource. Failed actions are retried until they use up their attempts; then
+// their idempotency key is released so a later detection cycle can queue them again.
+// Actions of issues that require human intervention are dropped instead.
+func (c *Controller) executeQueued(ctx context.Context, item queue.Item) {
+	// Continue the trace of the detection cycle that queued the action
+	if item.TraceID != "" {
+		ctx = tracing.WithTraceID(ctx, item.TraceID)
+	}
+	logger := log.FromContext(ctx).WithValues("action", item.Action, "resource", item.Name, "namespace", item.Namespace, "attempt", item.Attempts)
+	defer c.flushQueue(ctx)
+
+	resource, err := c.getResource(ctx, item.Kind, item.Namespace, item.Name)
+	if apierrors.IsNotFound(err) {
+		logger.Info("Resource of queued remediation action no longer exists: dropping action")
+		c.queue.Done(item.Key)
+		c.metrics.RecordRemediationQueue(queueDropped, 1, c.queue.Len())
+		return
+	}
+	if err == nil {
+		issue := detection.Issue{
+			RuleName:     item.Rule,
+			Severity:     item.Severity,
+			Namespace:    item.Namespace,
+			Kind:         item.Kind,
+			Name:         item.Name,
+			Container:    item.Container,
+			Reason:       item.Reason,
+			Description:  item.Description,
+			Resource:     resource,
+			Actions:      []string{item.Action},
+			DetectedAt:   item.EnqueuedAt,
+			RuleMetadata: c.ruleMetadata(item.Rule),
+		}
+		if err = c.executeRemediation(ctx, issue, item.Action); err == nil {
+			c.queue.Done(item.Key)
+			c.metrics.RecordRemediationQueue(queueDone, 1, c.queue.Len())
+			return
+		}
+		// Skipped actions are not retried; a later detection cycle queues them again
+		if errors.Is(err, errRemediationSkipped) {
+			logger.Info("Queued remediation action skipped: dropping action", "reason", err.Error())
+			c.queue.Done(item.Key)
+			c.metrics.RecordRemediationQueue(queueDropped, 1, c.queue.Len())
+			c.releaseRemediation(ctx, item.Key)
+			return
+		}
+		// Issues requiring human intervention are no longer remediated automatically
+		if record, exists := c.tracker.Get(issue.Fingerprint()); exists && record.InterventionRequired {
+			logger.Info("Remediation failed more often than the retries allow: dropping action", "error", err.Error())
+			c.queue.Done(item.Key)
+			c.metrics.RecordRemediationQueue(queueDropped, 1, c.queue.Len())
+			return
+		}
+	}
+
+	if next, ok := c.queue.Retry(item.Key, err); ok {
+		logger.Info("Queued remediation action failed: retrying", "error", err.Error(), "nextAttempt", next.NextAttempt)
+		c.metrics.RecordRemediationQueue(queueRetried, 1, c.queue.Len())
+		return
+	}
+	logger.Info("Queued remediation action failed on its last attempt: dropping action", "error", err.Error())
+	c.metrics.RecordRemediationQueue(queueDropped, 1, c.queue.Len())
+	c.releaseRemediation(ctx, item.Key)
+}
+
+// flushQueue persists the remediation queue
+func (c *Controller) flushQueue(ctx context.Context) {
+	if err := c.queue.Flush(ctx); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to save remediation queue")
+		c.metrics.RecordStateStoreError("save")
+	}
+}
+
+// RemediationQueue returns the queued remediation actions
+func (c *Controller) RemediationQueue() ([]queue.Item, error) {
+	if c.queue == nil {
+		return nil, ErrQueueDisabled
+	}
+	return c.queue.Items(), nil
+}
+
+// DrainRemediationQueue removes the queued remediation actions that are not being
+// executed, on behalf of the requester. Drained actions keep their idempotency
+// keys, so they are not queued again for the same issue within the cooldown.
+func (c *Controller) DrainRemediationQueue(ctx context.Context, requester remediation.Requester) ([]queue.Item, error) {
+	if c.queue == nil {
+		return nil, ErrQueueDisabled
+	}
+	if err := c.queue.Load(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load remediation queue: %w", err)
+	}
+
+	drained := c.queue.Drain()
+	if err := c.queue.Flush(ctx); err != nil {
+		c.metrics.RecordStateStoreError("save")
+		return nil, fmt.Errorf("failed to save remediation queue: %w", err)
+	}
+	c.metrics.RecordRemediationQueue(queueDrained, len(drained), c.queue.Len())
+	log.FromContext(ctx).Info("Drained remediation queue", "actions", len(drained), "requestedBy", requester.User)
+	return drained, nil
+}
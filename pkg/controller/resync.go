@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// ErrNotRunning is returned when a resync is requested from a controller that is
+// not running its detection loop, e.g. a standby replica
+var ErrNotRunning = errors.New("detection loop is not running")
+
+// ErrUnknownRule is returned when a resync or trace names a rule that does not exist
+var ErrUnknownRule = detection.ErrUnknownRule
+
+// ResyncRequest forces an immediate detection cycle, optionally limited to the
+// issues of a namespace and/or rule
+type ResyncRequest struct {
+	Namespace string                `json:"namespace,omitempty"`
+	Rule      string                `json:"rule,omitempty"`
+	Requester remediation.Requester `json:"-"`
+}
+
+// ResyncResult summarizes a forced detection cycle
+type ResyncResult struct {
+	Namespace string        `json:"namespace,omitempty"`
+	Rule      string        `json:"rule,omitempty"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	// Issues is the number of issues detected in scope, New and Resolved the
+	// issues that appeared or disappeared since the previous cycle
+	Issues   int `json:"issues"`
+	New      int `json:"new"`
+	Resolved int `json:"resolved"`
+}
+
+// resyncCall is a resync waiting to be run by the detection loop
+type resyncCall struct {
+	req  ResyncRequest
+	done chan resyncReply
+}
+
+type resyncReply struct {
+	result *ResyncResult
+	err    error
+}
+
+// cycleScope limits a detection cycle to the issues of a namespace and/or rule,
+// or to the issues of a single object found by the rules evaluated per object
+type cycleScope struct {
+	Namespace string
+	Rule      string
+	// Forced cycles re-verify all active issues in scope
+	Forced bool
+	// Kind and Name select a pod or deployment in Namespace, re-evaluated after a
+	// watch event with Rules
+	Kind  string
+	Name  string
+	Rules map[string]bool
+}
+
+// cycleSummary counts the issues of a detection cycle
+type cycleSummary struct {
+	Issues   int
+	New      int
+	Resolved int
+}
+
+// all returns true if the scope includes every issue
+func (s cycleScope) all() bool {
+	return s.Namespace == "" && s.Rule == "" && !s.object()
+}
+
+// object returns true if the scope is a single object
+func (s cycleScope) object() bool {
+	return s.Name != ""
+}
+
+func (s cycleScope) matches(namespace, kind, name, rule string) bool {
+	if s.object() && (s.Kind != kind || s.Name != name || !s.Rules[rule]) {
+		return false
+	}
+	return (s.Namespace == "" || s.Namespace == namespace) && (s.Rule == "" || s.Rule == rule)
+}
+
+// includes returns true if a tracked issue is in scope
+func (s cycleScope) includes(record tracker.Record) bool {
+	return s.matches(record.Namespace, record.Kind, record.Name, record.RuleName)
+}
+
+// filter returns the issues in scope
+func (s cycleScope) filter(issues []detection.Issue) []detection.Issue {
+	if s.all() {
+		return issues
+	}
+	var scoped []detection.Issue
+	for _, issue := range issues {
+		if s.matches(issue.Namespace, issue.Kind, issue.Name, issue.RuleName) {
+			scoped = append(scoped, issue)
+		}
+	}
+	return scoped
+}
+
+// Resync runs a detection cycle immediately instead of waiting for the next
+// evaluation tick, e.g. to verify a fix or a configuration change. The cycle
+// runs in the detection loop, so it never overlaps a scheduled cycle.
+func (c *Controller) Resync(ctx context.Context, req ResyncRequest) (*ResyncResult, error) {
+	if req.Rule != "" && !c.hasRule(req.Rule) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRule, req.Rule)
+	}
+	if !c.running.Load() {
+		return nil, ErrNotRunning
+	}
+
+	log.FromContext(ctx).Info("Resync requested",
+		"namespace", req.Namespace,
+		"rule", req.Rule,
+		"requestedBy", req.Requester.User)
+
+	call := resyncCall{req: req, done: make(chan resyncReply, 1)}
+	select {
+	case c.resyncs <- call:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case reply := <-call.done:
+		return reply.result, reply.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resync runs a forced detection cycle for a resync request
+func (c *Controller) resync(ctx context.Context, req ResyncRequest) resyncReply {
+	start := time.Now()
+	summary, err := c.runDetectionCycle(ctx, cycleScope{Namespace: req.Namespace, Rule: req.Rule, Forced: true})
+	if err != nil {
+		return resyncReply{err: err}
+	}
+	return resyncReply{result: &ResyncResult{
+		Namespace: req.Namespace,
+		Rule:      req.Rule,
+		StartedAt: start,
+		Duration:  time.Since(start),
+		Issues:    summary.Issues,
+		New:       summary.New,
+		Resolved:  summary.Resolved,
+	}}
+}
+
+// hasRule returns true if a detection rule with the name exists
+func (c *Controller) hasRule(name string) bool {
+	for _, rule := range c.detector.Rules() {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
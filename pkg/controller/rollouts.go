@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+)
+
+const (
+	defaultRolloutWatchTimeout  = 10 * time.Minute
+	defaultRolloutWatchInterval = 10 * time.Second
+
+	// rolloutWatchBacklog bounds the rollouts waiting to be followed; further
+	// actions are not followed until the watcher catches up
+	rolloutWatchBacklog = 64
+)
+
+// followedActions are the actions that start a Deployment rollout
+var followedActions = map[string]bool{
+	"rollback-deployment": true,
+	"restart-deployment":  true,
+}
+
+// rolloutRequest is a rollout to follow after a successful action
+type rolloutRequest struct {
+	issue     detection.Issue
+	action    string
+	namespace string
+	name      string
+}
+
+// rolloutWatcher follows the rollouts started by rollback and restart actions
+// until they complete, fail or time out, and reports their final state
+type rolloutWatcher struct {
+	client   kubernetes.Interface
+	timeout  time.Duration
+	interval time.Duration
+	requests chan rolloutRequest
+
+	mu        sync.Mutex
+	following map[string]bool // Key: namespace/name of the Deployment
+}
+
+// newRolloutWatcher creates the rollout watcher; nil if it is disabled, or if
+// actions are never applied through the Kubernetes API by this instance
+func newRolloutWatcher(client kubernetes.Interface, cfg *config.Config) *rolloutWatcher {
+	watch := cfg.Remediation.RolloutWatch
+	executor := cfg.Remediation.Executor.Type
+	if !watch.Enabled || cfg.IsObserveMode() || cfg.Remediation.DryRun || (executor != "" && executor != remediation.ExecutorClient) {
+		return nil
+	}
+
+	w := &rolloutWatcher{
+		client:    client,
+		timeout:   watch.Timeout,
+		interval:  watch.PollInterval,
+		requests:  make(chan rolloutRequest, rolloutWatchBacklog),
+		following: make(map[string]bool),
+	}
+	if w.timeout <= 0 {
+		w.timeout = defaultRolloutWatchTimeout
+	}
+	if w.interval <= 0 {
+		w.interval = defaultRolloutWatchInterval
+	}
+	return w
+}
+
+// follow starts following the rollout of a successful rollback or restart
+// action; other actions and Deployments already followed are ignored
+func (w *rolloutWatcher) follow(ctx context.Context, issue detection.Issue, result remediation.Result) {
+	if w == nil || !result.Success || result.PendingApproval || result.Patch != "" || !followedActions[result.Action] {
+		return
+	}
+
+	key := result.Namespace + "/" + result.Resource
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.following[key] {
+		return
+	}
+
+	select {
+	case w.requests <- rolloutRequest{issue: issue, action: result.Action, namespace: result.Namespace, name: result.Resource}:
+		w.following[key] = true
+	default:
+		log.FromContext(ctx).Info("Too many rollouts followed: not following rollout", "action", result.Action, "deployment", result.Resource, "namespace", result.Namespace)
+	}
+}
+
+// Run follows the requested rollouts until ctx is done, reporting the final
+// state of each
+func (w *rolloutWatcher) Run(ctx context.Context, report func(context.Context, detection.Issue, notification.RolloutOutcome)) {
+	if w == nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-w.requests:
+			go func() {
+				defer w.done(req)
+				if outcome, ok := w.watch(ctx, req); ok {
+					report(ctx, req.issue, outcome)
+				}
+			}()
+		}
+	}
+}
+
+// done stops following the rollout of a request
+func (w *rolloutWatcher) done(req rolloutRequest) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.following, req.namespace+"/"+req.name)
+}
+
+// watch polls the Deployment of a request until its rollout completes, fails,
+// the Deployment is deleted or the timeout expires. It returns false if ctx is
+// done before then.
+func (w *rolloutWatcher) watch(ctx context.Context, req rolloutRequest) (notification.RolloutOutcome, bool) {
+	logger := log.FromContext(ctx)
+	start := time.Now()
+	outcome := notification.RolloutOutcome{Action: req.action, Namespace: req.namespace, Deployment: req.name}
+
+	timeout := time.NewTimer(w.timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return outcome, false
+		case <-timeout.C:
+			outcome.State = notification.RolloutTimedOut
+			outcome.Message = fmt.Sprintf("Rollout did not finish within %s: %s", w.timeout, outcome.Message)
+			outcome.Duration = time.Since(start)
+			return outcome, true
+		case <-ticker.C:
+		}
+
+		deployment, err := w.client.AppsV1().Deployments(req.namespace).Get(ctx, req.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			outcome.State = notification.RolloutDeleted
+			outcome.Message = "Deployment was deleted during the rollout"
+			outcome.Duration = time.Since(start)
+			return outcome, true
+		}
+		if err != nil {
+			logger.Error(err, "Failed to get deployment of followed rollout", "deployment", req.name, "namespace", req.namespace)
+			continue
+		}
+
+		outcome.State, outcome.Message = rolloutState(deployment)
+		outcome.Replicas = desiredReplicas(deployment)
+		outcome.UpdatedReplicas = deployment.Status.UpdatedReplicas
+		outcome.AvailableReplicas = deployment.Status.AvailableReplicas
+		if outcome.State != "" {
+			outcome.Duration = time.Since(start)
+			return outcome, true
+		}
+	}
+}
+
+// rolloutState returns the state of the rollout of a Deployment and why, like
+// kubectl rollout status; the state is empty while the rollout is in progress
+func rolloutState(deployment *appsv1.Deployment) (string, string) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return "", "Waiting for the deployment spec update to be observed"
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return notification.RolloutFailed, fmt.Sprintf("Deployment exceeded its progress deadline: %s", condition.Message)
+		}
+	}
+
+	replicas, status := desiredReplicas(deployment), deployment.Status
+	switch {
+	case status.UpdatedReplicas < replicas:
+		return "", fmt.Sprintf("%d of %d new replicas have been updated", status.UpdatedReplicas, replicas)
+	case status.Replicas > status.UpdatedReplicas:
+		return "", fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		return "", fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas)
+	}
+	return notification.RolloutComplete, fmt.Sprintf("Deployment %s successfully rolled out", deployment.Name)
+}
+
+// desiredReplicas returns the replicas a Deployment asks for, 1 if unset
+func desiredReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+// reportRollout records and notifies the final state of a followed rollout
+func (c *Controller) reportRollout(ctx context.Context, issue detection.Issue, outcome notification.RolloutOutcome) {
+	log.FromContext(ctx).Info("Followed rollout finished",
+		"action", outcome.Action,
+		"deployment", outcome.Deployment,
+		"namespace", outcome.Namespace,
+		"state", outcome.State,
+		"message", outcome.Message,
+		"duration", outcome.Duration)
+	c.metrics.RecordRolloutWatch(outcome.Action, outcome.State)
+
+	if c.slackNotifier == nil {
+		return
+	}
+	if err := c.slackNotifier.SendRolloutNotification(ctx, issue, outcome); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send rollout notification")
+		c.metrics.RecordNotification("rollout", "failed")
+	} else {
+		c.metrics.RecordNotification("rollout", "success")
+	}
+}
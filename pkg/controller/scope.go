@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// resolveNamespaceScope limits detection to the existing namespaces in the
+// configured detection scope. Detection does not run until the scope was
+// resolved once, so excluded namespaces are never inspected; later failures
+// keep the previous scope.
+func (c *Controller) resolveNamespaceScope(ctx context.Context) error {
+	scope := c.config.Detection.Scope
+	if !scope.Enabled() {
+		return nil
+	}
+
+	namespaces, err := c.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if c.scopeResolved {
+			log.FromContext(ctx).Error(err, "Failed to list namespaces for the detection scope, keeping previous scope")
+			return nil
+		}
+		return fmt.Errorf("failed to list namespaces for the detection scope: %w", err)
+	}
+
+	inScope := make([]string, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		if scope.Includes(ns.Name, ns.Labels) {
+			inScope = append(inScope, ns.Name)
+		}
+	}
+	c.detector.SetNamespaceScope(inScope, scope.Restricted())
+	c.scopeResolved = true
+	log.FromContext(ctx).V(1).Info("Resolved detection scope", "namespaces", len(inScope), "of", len(namespaces.Items))
+	return nil
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/export"
+	"github.com/NotHarshhaa/kubeguardian/pkg/notification"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/secrets"
+)
+
+// secretResolveTimeout bounds resolving the credentials that reference secret
+// stores on startup
+const secretResolveTimeout = 30 * time.Second
+
+// resolveCredentials returns the value of each credential of the configuration
+// by its path, reading the credentials that reference a secret store. It fails if
+// any reference cannot be resolved, so KubeGuardian does not start without them.
+func resolveCredentials(resolver *secrets.Resolver, cfg *config.Config) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+
+	credentials := cfg.Credentials()
+	names := make([]string, 0, len(credentials))
+	for name := range credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := make(map[string]string, len(credentials))
+	for _, name := range names {
+		value, err := resolver.Resolve(ctx, credentials[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// followRotations updates the Slack, export and webhook executor clients when
+// their credentials are rotated in the secret store. The other credentials are
+// only read on startup.
+func followRotations(resolver *secrets.Resolver, cfg *config.Config, slackNotifier *notification.SlackNotifier, exporter *export.Exporter, executor *remediation.WebhookExecutor) {
+	if slackNotifier != nil {
+		resolver.OnRotate(cfg.Notification.Slack.Token, slackNotifier.SetToken)
+	}
+	if exporter != nil {
+		rotate := func(string) {
+			exporter.SetCredentials(resolver.Current(cfg.Export.Username), resolver.Current(cfg.Export.Password), resolver.Current(cfg.Export.APIKey))
+		}
+		for _, value := range []string{cfg.Export.Username, cfg.Export.Password, cfg.Export.APIKey} {
+			resolver.OnRotate(value, rotate)
+		}
+	}
+	if executor != nil {
+		resolver.OnRotate(cfg.Remediation.Executor.Secret, executor.SetSecret)
+	}
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/health"
+)
+
+// cycleStats records the last full detection cycle and the issues waiting to be
+// processed, for the health endpoint
+type cycleStats struct {
+	mu       sync.Mutex
+	started  time.Time
+	duration time.Duration
+	issues   int
+
+	pending atomic.Int64
+}
+
+// recordCycle records a full detection cycle
+func (s *cycleStats) recordCycle(started time.Time, duration time.Duration, issues int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = started
+	s.duration = duration
+	s.issues = issues
+}
+
+// Stats returns an operational snapshot of the controller for the health endpoint
+func (c *Controller) Stats() health.ControllerStats {
+	c.stats.mu.Lock()
+	stats := health.ControllerStats{
+		LastCycleAt:           c.stats.started,
+		LastCycleDuration:     c.stats.duration,
+		IssuesDetected:        c.stats.issues,
+		RemediationQueueDepth: int(c.stats.pending.Load()),
+	}
+	c.stats.mu.Unlock()
+	if c.queue != nil {
+		stats.RemediationQueueDepth += c.queue.Len()
+	}
+
+	if c.remediator != nil {
+		stats.CircuitBreakers = make(map[string]health.CircuitBreakerStats)
+		for _, breaker := range c.remediator.CircuitBreakers() {
+			counts := breaker.Counts()
+			stats.CircuitBreakers[breaker.Name()] = health.CircuitBreakerStats{
+				State:               breaker.State().String(),
+				ConsecutiveFailures: counts.ConsecutiveFailures,
+				TotalFailures:       counts.TotalFailures,
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/health"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+)
+
+// cacheMonitorInterval is how often the state of the informer caches is recorded
+const cacheMonitorInterval = 15 * time.Second
+
+// objectEvent asks the detection loop to re-evaluate a changed pod or deployment
+type objectEvent struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// objectWatch turns the watch events of pods and deployments into object events.
+// Events are keyed by object and delayed by the debounce interval in a work
+// queue, so a burst of updates of an object is evaluated once.
+type objectWatch struct {
+	factory    informers.SharedInformerFactory
+	queue      workqueue.TypedDelayingInterface[string]
+	debounce   time.Duration
+	events     chan objectEvent
+	metrics    *metrics.Metrics
+	staleAfter time.Duration
+
+	mu     sync.Mutex
+	caches map[string]*watchedCache // Key: kind
+}
+
+// watchedCache is the informer cache of a kind with the progress of its watch
+type watchedCache struct {
+	informer cache.SharedIndexInformer
+	// progressAt is when the watch last delivered an event or advanced its
+	// resource version, e.g. on a bookmark of a quiet watch
+	progressAt      time.Time
+	resourceVersion string
+}
+
+// newObjectWatch creates the shared informers of pods and deployments and makes
+// the detector read from their caches; nil if event-driven detection is disabled
+func newObjectWatch(client kubernetes.Interface, detector *detection.Detector, cfg config.WatchConfig, metricsCollector *metrics.Metrics) (*objectWatch, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	w := &objectWatch{
+		factory: informers.NewSharedInformerFactory(client, 0),
+		queue: workqueue.NewTypedDelayingQueueWithConfig(workqueue.TypedDelayingQueueConfig[string]{
+			Name: "kubeguardian-objects",
+		}),
+		debounce:   cfg.Debounce,
+		events:     make(chan objectEvent),
+		metrics:    metricsCollector,
+		staleAfter: cfg.StaleAfter,
+		caches:     make(map[string]*watchedCache),
+	}
+	detector.SetInformers(w.factory)
+
+	for kind, informer := range map[string]cache.SharedIndexInformer{
+		detection.KindPod:        w.factory.Core().V1().Pods().Informer(),
+		detection.KindDeployment: w.factory.Apps().V1().Deployments().Informer(),
+	} {
+		w.caches[kind] = &watchedCache{informer: informer, progressAt: time.Now()}
+		if _, err := informer.AddEventHandler(w.handler(kind)); err != nil {
+			return nil, fmt.Errorf("failed to watch %s resources: %w", kind, err)
+		}
+	}
+	return w, nil
+}
+
+// handler queues the objects of a kind that were added, changed or deleted.
+// Updates that did not change the object, e.g. informer resyncs, are ignored.
+// Every event counts as progress of the watch, and the lag of objects written
+// since the caches synced is recorded.
+func (w *objectWatch) handler(kind string) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			return
+		}
+		w.queue.AddAfter(kind+"/"+key, w.debounce)
+	}
+	progress := func(obj interface{}, written bool) {
+		now := time.Now()
+		w.mu.Lock()
+		if watched := w.caches[kind]; watched != nil {
+			watched.progressAt = now
+		}
+		w.mu.Unlock()
+		if !written {
+			return
+		}
+		if at, ok := lastWrite(obj); ok {
+			w.metrics.RecordWatchLag(kind, max(now.Sub(at), 0))
+		}
+	}
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			progress(obj, !isInInitialList)
+			enqueue(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, errOld := apimeta.Accessor(oldObj)
+			newMeta, errNew := apimeta.Accessor(newObj)
+			if errOld == nil && errNew == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				return
+			}
+			progress(newObj, true)
+			enqueue(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			progress(obj, false)
+			enqueue(obj)
+		},
+	}
+}
+
+// lastWrite returns when an object was last written according to its managed
+// fields, which the API server stamps with a precision of one second
+func lastWrite(obj interface{}) (time.Time, bool) {
+	meta, err := apimeta.Accessor(obj)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var written time.Time
+	for _, entry := range meta.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(written) {
+			written = entry.Time.Time
+		}
+	}
+	return written, !written.IsZero()
+}
+
+// monitorCaches records the state of the informer caches until ctx is done
+func (w *objectWatch) monitorCaches(ctx context.Context) {
+	ticker := time.NewTicker(cacheMonitorInterval)
+	defer ticker.Stop()
+	for {
+		w.recordCaches()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// recordCaches records whether the informer caches synced, the objects they
+// hold and how long ago their watches progressed. A changed resource version
+// counts as progress, since a watch without events still advances it on
+// bookmarks.
+func (w *objectWatch) recordCaches() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	for kind, watched := range w.caches {
+		if version := watched.informer.LastSyncResourceVersion(); version != watched.resourceVersion {
+			watched.resourceVersion = version
+			watched.progressAt = now
+		}
+		w.metrics.RecordInformerCache(kind, watched.informer.HasSynced(), len(watched.informer.GetStore().ListKeys()), now.Sub(watched.progressAt))
+	}
+}
+
+// cacheHealth returns an error describing the informer caches that stopped,
+// did not sync or made no progress for the stale threshold, nil if all are healthy
+func (w *objectWatch) cacheHealth() error {
+	w.recordCaches()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var problems []string
+	for _, kind := range slices.Sorted(maps.Keys(w.caches)) {
+		watched := w.caches[kind]
+		switch since := time.Since(watched.progressAt); {
+		case watched.informer.IsStopped():
+			problems = append(problems, fmt.Sprintf("the %s watch stopped", kind))
+		case !watched.informer.HasSynced():
+			problems = append(problems, fmt.Sprintf("the %s cache has not synced", kind))
+		case w.staleAfter > 0 && since > w.staleAfter:
+			problems = append(problems, fmt.Sprintf("the %s watch made no progress for %s", kind, since.Round(time.Second)))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// cacheCheck is the health check of the informer caches of event-driven detection
+type cacheCheck struct {
+	watch *objectWatch
+}
+
+func (c cacheCheck) Name() string {
+	return "informer-cache"
+}
+
+func (c cacheCheck) Check(ctx context.Context) error {
+	return c.watch.cacheHealth()
+}
+
+// CacheCheck returns the health check reporting stale or unsynced informer
+// caches, nil if event-driven detection is disabled
+func (c *Controller) CacheCheck() health.Checker {
+	if c.watch == nil {
+		return nil
+	}
+	return cacheCheck{watch: c.watch}
+}
+
+// Run starts the informers, waits for their caches to sync and delivers the
+// queued objects as events until ctx is done
+func (w *objectWatch) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	defer w.queue.ShutDown()
+
+	w.factory.Start(ctx.Done())
+	go w.monitorCaches(ctx)
+	for informer, synced := range w.factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Info("Informer cache did not sync, listing from the API server", "informer", informer.String())
+			return
+		}
+	}
+	logger.Info("Watching pods and deployments", "debounce", w.debounce)
+
+	go func() {
+		<-ctx.Done()
+		w.queue.ShutDown()
+	}()
+	for {
+		key, shutdown := w.queue.Get()
+		if shutdown {
+			return
+		}
+		event, err := parseObjectKey(key)
+		if err != nil {
+			logger.Error(err, "Ignoring invalid object key")
+			w.queue.Done(key)
+			continue
+		}
+		select {
+		case w.events <- event:
+		case <-ctx.Done():
+		}
+		w.queue.Done(key)
+	}
+}
+
+// parseObjectKey splits a queue key of the form Kind/namespace/name
+func parseObjectKey(key string) (objectEvent, error) {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		return objectEvent{}, fmt.Errorf("invalid object key %q", key)
+	}
+	return objectEvent{Kind: parts[0], Namespace: parts[1], Name: parts[2]}, nil
+}
+
+// objectEvents returns the channel of object events, nil if event-driven
+// detection is disabled
+func (c *Controller) objectEvents() <-chan objectEvent {
+	if c.watch == nil {
+		return nil
+	}
+	return c.watch.events
+}
+
+// detectObject runs a detection cycle for a changed pod or deployment. The cycle
+// is limited to the rules evaluated per object, so issues of other rules on the
+// object are left to the full cycles.
+func (c *Controller) detectObject(ctx context.Context, event objectEvent) {
+	rules := make(map[string]bool)
+	for _, rule := range c.detector.ObjectRules(event.Kind) {
+		rules[rule] = true
+	}
+	scope := cycleScope{Namespace: event.Namespace, Kind: event.Kind, Name: event.Name, Rules: rules}
+	if _, err := c.runDetectionCycle(ctx, scope); err != nil {
+		log.FromContext(ctx).Error(err, "Object detection failed", "kind", event.Kind, "namespace", event.Namespace, "name", event.Name)
+	}
+}
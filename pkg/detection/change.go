@@ -0,0 +1,210 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// annotationRevision is set on Deployments and ReplicaSets by the deployment controller
+const annotationRevision = "deployment.kubernetes.io/revision"
+
+// ImageChange is a container image changed by a rollout
+type ImageChange struct {
+	Container string `yaml:"container" json:"container"`
+	From      string `yaml:"from" json:"from"`
+	To        string `yaml:"to" json:"to"`
+}
+
+// Change is the most recent rollout of the deployment owning an affected resource
+type Change struct {
+	Deployment string        `yaml:"deployment" json:"deployment"`
+	Revision   int64         `yaml:"revision" json:"revision"`
+	ChangedAt  time.Time     `yaml:"changedAt" json:"changedAt"`
+	Images     []ImageChange `yaml:"images,omitempty" json:"images,omitempty"`
+}
+
+// Summary describes the change, e.g. "changed 7 minutes ago: image v1.2.3 → v1.2.4"
+func (c *Change) Summary(now time.Time) string {
+	summary := fmt.Sprintf("changed %s ago", formatAge(now.Sub(c.ChangedAt)))
+	if len(c.Images) == 0 {
+		return fmt.Sprintf("%s (revision %d)", summary, c.Revision)
+	}
+
+	images := make([]string, 0, len(c.Images))
+	for _, image := range c.Images {
+		from, to := shortenImages(image.From, image.To)
+		if len(c.Images) == 1 {
+			images = append(images, fmt.Sprintf("%s → %s", from, to))
+		} else {
+			images = append(images, fmt.Sprintf("%s %s → %s", image.Container, from, to))
+		}
+	}
+	return fmt.Sprintf("%s: image %s", summary, strings.Join(images, ", "))
+}
+
+// CorrelateChanges attaches the most recent rollout of the owning Deployment to
+// Deployment and Pod issues if it happened within the change window
+func (d *Detector) CorrelateChanges(ctx context.Context, issues []Issue) {
+	if d.config.ChangeWindow <= 0 {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	replicaSets := make(map[string][]appsv1.ReplicaSet) // Key: namespace
+	changes := make(map[string]*Change)                 // Key: namespace/deployment
+	now := d.clock.Now()
+
+	for i := range issues {
+		deployment := d.owningDeployment(ctx, issues[i])
+		if deployment == "" {
+			continue
+		}
+
+		key := issues[i].Namespace + "/" + deployment
+		change, exists := changes[key]
+		if !exists {
+			rs, listed := replicaSets[issues[i].Namespace]
+			if !listed {
+				list, err := d.client.AppsV1().ReplicaSets(issues[i].Namespace).List(ctx, metav1.ListOptions{})
+				if err != nil {
+					logger.Error(err, "Failed to list replicasets for change correlation", "namespace", issues[i].Namespace)
+					continue
+				}
+				rs = list.Items
+				replicaSets[issues[i].Namespace] = rs
+			}
+			change = latestChange(deployment, rs)
+			changes[key] = change
+		}
+
+		if change != nil && now.Sub(change.ChangedAt) <= d.config.ChangeWindow {
+			issues[i].Change = change
+		}
+	}
+}
+
+// owningDeployment returns the name of the Deployment an issue refers to or whose pod it affects
+func (d *Detector) owningDeployment(ctx context.Context, issue Issue) string {
+	switch resource := issue.Resource.(type) {
+	case *appsv1.Deployment:
+		return resource.Name
+	case *corev1.Pod:
+		owner := metav1.GetControllerOf(resource)
+		if owner == nil || owner.Kind != "ReplicaSet" {
+			return ""
+		}
+		rs, err := d.client.AppsV1().ReplicaSets(resource.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			log.FromContext(ctx).V(1).Info("Failed to get replicaset for change correlation", "namespace", resource.Namespace, "name", owner.Name, "error", err.Error())
+			return ""
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == "Deployment" {
+			return rsOwner.Name
+		}
+	}
+	return ""
+}
+
+// latestChange compares the two most recent revisions of a Deployment's ReplicaSets
+func latestChange(deployment string, replicaSets []appsv1.ReplicaSet) *Change {
+	type revision struct {
+		number int64
+		rs     *appsv1.ReplicaSet
+	}
+
+	var revisions []revision
+	for i := range replicaSets {
+		owner := metav1.GetControllerOf(&replicaSets[i])
+		if owner == nil || owner.Kind != "Deployment" || owner.Name != deployment {
+			continue
+		}
+		number, err := strconv.ParseInt(replicaSets[i].Annotations[annotationRevision], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, revision{number: number, rs: &replicaSets[i]})
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].number > revisions[j].number
+	})
+
+	// A ReplicaSet that is rolled back to is reused with a new revision and keeps
+	// its creation time; the change happened no earlier than the previous revision
+	current := revisions[0].rs
+	change := &Change{
+		Deployment: deployment,
+		Revision:   revisions[0].number,
+		ChangedAt:  current.CreationTimestamp.Time,
+	}
+	if len(revisions) == 1 {
+		return change
+	}
+
+	previous := revisions[1].rs
+	if previous.CreationTimestamp.After(change.ChangedAt) {
+		change.ChangedAt = previous.CreationTimestamp.Time
+	}
+	previousImages := make(map[string]string)
+	for _, container := range previous.Spec.Template.Spec.Containers {
+		previousImages[container.Name] = container.Image
+	}
+	for _, container := range current.Spec.Template.Spec.Containers {
+		if from, exists := previousImages[container.Name]; exists && from != container.Image {
+			change.Images = append(change.Images, ImageChange{Container: container.Name, From: from, To: container.Image})
+		}
+	}
+	return change
+}
+
+// shortenImages drops the repository shared by both images, leaving the tags
+func shortenImages(from, to string) (string, string) {
+	fromRepo, fromTag, fromOK := splitImage(from)
+	toRepo, toTag, toOK := splitImage(to)
+	if fromOK && toOK && fromRepo == toRepo {
+		return fromTag, toTag
+	}
+	return from, to
+}
+
+// splitImage splits an image reference into repository and tag or digest
+func splitImage(image string) (string, string, bool) {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[:i], image[i+1:], true
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:], true
+	}
+	return image, "", false
+}
+
+// formatAge formats a duration for humans, e.g. "7 minutes"
+func formatAge(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return plural(int(d/time.Second), "second")
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
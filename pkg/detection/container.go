@@ -0,0 +1,96 @@
+package detection
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ContainerRule selects containers of multi-container pods and the actions for
+// their issues, e.g. to only notify about a crash-looping sidecar
+type ContainerRule struct {
+	// Rules limits the container rule to these rules; empty applies to all rules
+	Rules []string
+	// Name is the exact container name, Pattern a regular expression matching
+	// the whole container name
+	Name    string
+	Pattern *regexp.Regexp
+	// Ignore drops the issues of matching containers
+	Ignore bool
+	// NotifyOnly removes all actions from the issues of matching containers;
+	// otherwise non-empty Actions replace the actions of the rule
+	NotifyOnly bool
+	Actions    []string
+}
+
+// appliesTo returns true if the container rule applies to the named rule
+func (c ContainerRule) appliesTo(rule string) bool {
+	if len(c.Rules) == 0 {
+		return true
+	}
+	for _, name := range c.Rules {
+		if name == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if the container rule matches the container
+func (c ContainerRule) Matches(container string) bool {
+	if c.Name != "" && c.Name != container {
+		return false
+	}
+	return c.Pattern == nil || c.Pattern.MatchString(container)
+}
+
+// describe summarizes the effect of the container rule for traces
+func (c ContainerRule) describe(rule Rule) string {
+	switch {
+	case c.Ignore:
+		return "ignored"
+	case c.NotifyOnly:
+		return "notify only"
+	case len(c.Actions) > 0:
+		return "actions: " + strings.Join(c.Actions, ", ")
+	default:
+		return "actions: " + strings.Join(rule.Actions, ", ")
+	}
+}
+
+// containerRule returns the first container rule matching a container
+func containerRule(containers []ContainerRule, name string) (ContainerRule, bool) {
+	for _, container := range containers {
+		if container.Matches(name) {
+			return container, true
+		}
+	}
+	return ContainerRule{}, false
+}
+
+// applyContainerRules applies the first matching container rule of a rule to each
+// of its container issues and drops the issues of ignored containers
+func applyContainerRules(rule Rule, issues []Issue, trace *Trace) []Issue {
+	if len(rule.Containers) == 0 {
+		return issues
+	}
+
+	kept := issues[:0]
+	for _, issue := range issues {
+		container, matched := containerRule(rule.Containers, issue.Container)
+		if issue.Container == "" || !matched {
+			kept = append(kept, issue)
+			continue
+		}
+
+		if !trace.check("container rule", issue.Container, container.describe(rule), "not ignored", !container.Ignore) {
+			continue
+		}
+		if container.NotifyOnly {
+			issue.Actions = nil
+		} else if len(container.Actions) > 0 {
+			issue.Actions = container.Actions
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
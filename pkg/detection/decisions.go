@@ -0,0 +1,253 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrDecisionLogDisabled is returned when decisions are requested while the decision log is disabled
+var ErrDecisionLogDisabled = errors.New("decision log is not enabled")
+
+// Outcomes of evaluating a rule against a resource
+const (
+	DecisionFired   = "fired"
+	DecisionSkipped = "skipped"
+)
+
+// DefaultDecisionLogSize bounds the decisions the decision log keeps
+const DefaultDecisionLogSize = 1000
+
+// DecisionLog configures the decision log, which records the outcome of every
+// rule evaluated against every resource in a detection cycle. All decisions are
+// counted; SampleRate of them, between 0 and 1, are kept for inspection, at most
+// Size.
+type DecisionLog struct {
+	Enabled    bool
+	SampleRate float64
+	Size       int
+}
+
+// Decision is the outcome of evaluating a rule against a single resource
+type Decision struct {
+	Rule      string `json:"rule"`
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Outcome   string `json:"outcome"`
+	// Reasons are the reasons of the issues of a fired rule
+	Reasons []string `json:"reasons,omitempty"`
+	// Condition is the first unmatched check of a skipped rule, the one that kept
+	// it from firing, with the value seen and the value it requires
+	Condition   string    `json:"condition,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	Value       string    `json:"value,omitempty"`
+	Expected    string    `json:"expected,omitempty"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+}
+
+// DecisionCount counts the decisions of a rule with the same outcome and
+// deciding condition in the last detection cycle
+type DecisionCount struct {
+	Rule      string `json:"rule"`
+	Outcome   string `json:"outcome"`
+	Condition string `json:"condition,omitempty"`
+	Count     int    `json:"count"`
+}
+
+// DecisionQuery selects kept decisions; empty fields match all decisions and a
+// non-positive Limit returns all of them
+type DecisionQuery struct {
+	Rule      string
+	Namespace string
+	Outcome   string
+	Limit     int
+}
+
+// DecisionReport holds the decision counts of the last detection cycle and the
+// kept decisions matching a query, the most recent first
+type DecisionReport struct {
+	SampleRate float64         `json:"sampleRate"`
+	Counts     []DecisionCount `json:"counts"`
+	Decisions  []Decision      `json:"decisions"`
+}
+
+// decisionKey groups decisions for counting
+type decisionKey struct {
+	rule, outcome, condition string
+}
+
+// decisionLog keeps a sample of recent decisions in a ring buffer and counts
+// the decisions of the current and the last detection cycle
+type decisionLog struct {
+	config DecisionLog
+	sample func() float64
+
+	mu      sync.Mutex
+	entries []Decision // Ring buffer of kept decisions, next is the oldest once full
+	next    int
+	cycle   map[decisionKey]int // Decisions of the running cycle
+	counts  map[decisionKey]int // Decisions of the last completed cycle
+}
+
+// newDecisionLog creates the decision log, or returns nil if it is disabled
+func newDecisionLog(config DecisionLog) *decisionLog {
+	if !config.Enabled {
+		return nil
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.Size <= 0 {
+		config.Size = DefaultDecisionLogSize
+	}
+	return &decisionLog{
+		config: config,
+		sample: rand.Float64,
+		cycle:  make(map[decisionKey]int),
+		counts: make(map[decisionKey]int),
+	}
+}
+
+// startCycle starts counting the decisions of a detection cycle
+func (l *decisionLog) startCycle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cycle = make(map[decisionKey]int)
+}
+
+// endCycle publishes the counts of the completed detection cycle
+func (l *decisionLog) endCycle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.counts = l.cycle
+	l.cycle = make(map[decisionKey]int)
+}
+
+// record counts a decision and keeps it if it is sampled
+func (l *decisionLog) record(decision Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cycle[decisionKey{decision.Rule, decision.Outcome, decision.Condition}]++
+	if l.config.SampleRate < 1 && l.sample() >= l.config.SampleRate {
+		return
+	}
+	if len(l.entries) < l.config.Size {
+		l.entries = append(l.entries, decision)
+		return
+	}
+	l.entries[l.next] = decision
+	l.next = (l.next + 1) % l.config.Size
+}
+
+// countsOf returns the decision counts of the last completed cycle, sorted
+func (l *decisionLog) countsOf() []DecisionCount {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	counts := make([]DecisionCount, 0, len(l.counts))
+	for key, count := range l.counts {
+		counts = append(counts, DecisionCount{Rule: key.rule, Outcome: key.outcome, Condition: key.condition, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Rule != counts[j].Rule {
+			return counts[i].Rule < counts[j].Rule
+		}
+		if counts[i].Outcome != counts[j].Outcome {
+			return counts[i].Outcome < counts[j].Outcome
+		}
+		return counts[i].Condition < counts[j].Condition
+	})
+	return counts
+}
+
+// query returns the kept decisions matching a query, the most recent first
+func (l *decisionLog) query(query DecisionQuery) []Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	decisions := []Decision{}
+	for i := 1; i <= len(l.entries); i++ {
+		decision := l.entries[(l.next-i+len(l.entries))%len(l.entries)]
+		if (query.Rule != "" && decision.Rule != query.Rule) ||
+			(query.Namespace != "" && decision.Namespace != query.Namespace) ||
+			(query.Outcome != "" && decision.Outcome != query.Outcome) {
+			continue
+		}
+		decisions = append(decisions, decision)
+		if query.Limit > 0 && len(decisions) == query.Limit {
+			break
+		}
+	}
+	return decisions
+}
+
+// decide evaluates a rule against a single resource with check and records the
+// outcome in the decision log, if it is enabled
+func (d *Detector) decide(ctx context.Context, rule Rule, kind string, obj runtime.Object, check func(*Trace) []Issue) []Issue {
+	if d.decisions == nil {
+		return check(nil)
+	}
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return check(nil)
+	}
+
+	trace := &Trace{Rule: rule.Name, Namespace: object.GetNamespace(), Kind: kind, Name: object.GetName(), decision: true}
+	issues := check(trace)
+	decision := Decision{
+		Rule:        rule.Name,
+		Namespace:   trace.Namespace,
+		Kind:        kind,
+		Name:        trace.Name,
+		Outcome:     DecisionSkipped,
+		EvaluatedAt: d.clock.Now(),
+	}
+	if len(issues) > 0 {
+		decision.Outcome = DecisionFired
+		for _, issue := range issues {
+			decision.Reasons = append(decision.Reasons, issue.Reason)
+		}
+	} else {
+		for _, step := range trace.Steps {
+			if !step.Matched {
+				decision.Condition, decision.Container = step.Condition, step.Container
+				decision.Value, decision.Expected = step.Value, step.Expected
+				break
+			}
+		}
+	}
+
+	log.FromContext(ctx).V(1).Info("Rule decision", "rule", decision.Rule, "kind", kind,
+		"namespace", decision.Namespace, "name", decision.Name, "outcome", decision.Outcome,
+		"condition", decision.Condition, "value", decision.Value, "expected", decision.Expected)
+	d.decisions.record(decision)
+	return issues
+}
+
+// DecisionCounts returns the decision counts of the last detection cycle by
+// rule, outcome and deciding condition, or nil while the decision log is disabled
+func (d *Detector) DecisionCounts() []DecisionCount {
+	if d.decisions == nil {
+		return nil
+	}
+	return d.decisions.countsOf()
+}
+
+// Decisions returns the decision counts of the last detection cycle and the
+// kept decisions matching a query
+func (d *Detector) Decisions(query DecisionQuery) (DecisionReport, error) {
+	if d.decisions == nil {
+		return DecisionReport{}, ErrDecisionLogDisabled
+	}
+	return DecisionReport{
+		SampleRate: d.decisions.config.SampleRate,
+		Counts:     d.decisions.countsOf(),
+		Decisions:  d.decisions.query(query),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrNoDynamicClient is returned when a field rule selects a resource by its
+// apiVersion and no dynamic client is set
+var ErrNoDynamicClient = errors.New("no dynamic client set")
+
+// SetDynamicClient sets the client field rules read resources other than pods,
+// deployments and nodes with, e.g. the custom resources of operators. Rules on
+// such resources fail to evaluate while no client is set.
+func (d *Detector) SetDynamicClient(client dynamic.Interface) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dynamic = client
+}
+
+// dynamicResource returns the client of the resources of a kind in a group
+// version and whether they are namespaced, finding their plural name with
+// discovery
+func (d *Detector) dynamicResource(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	d.mu.RLock()
+	client := d.dynamic
+	d.mu.RUnlock()
+	if client == nil {
+		return nil, false, ErrNoDynamicClient
+	}
+
+	groupVersion, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid apiVersion %q: %w", apiVersion, err)
+	}
+	resources, err := d.client.Discovery().ServerResourcesForGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to discover the resources of %s: %w", apiVersion, err)
+	}
+	for _, resource := range resources.APIResources {
+		// Subresources like status share the kind of their resource
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return client.Resource(groupVersion.WithResource(resource.Name)), resource.Namespaced, nil
+		}
+	}
+	return nil, false, fmt.Errorf("kind %s is not served by %s", kind, apiVersion)
+}
+
+// listDynamic lists the resources of a kind in a group version in all namespaces
+func (d *Detector) listDynamic(ctx context.Context, apiVersion, kind string) ([]runtime.Object, error) {
+	resource, _, err := d.dynamicResource(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []runtime.Object
+	err = d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return resource.List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	return objects, err
+}
+
+// getFieldResource gets the resource a field rule is traced against
+func (d *Detector) getFieldResource(ctx context.Context, condition RuleCondition, namespace, name string) (runtime.Object, error) {
+	if condition.APIVersion == "" {
+		return d.getResource(ctx, condition.Resource, namespace, name)
+	}
+
+	resource, namespaced, err := d.dynamicResource(condition.APIVersion, condition.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		return resource.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return resource.Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,217 @@
+package detection
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// WarningEventRule is the rule reporting objects with recurring Warning events
+// of selected reasons
+const WarningEventRule = "warning-event"
+
+// EventReason selects a Warning event reason reported by the warning event
+// rule, and the severity and actions of its issues
+type EventReason struct {
+	// Reason is the event reason, e.g. BackOff or FailedMount
+	Reason   string
+	Severity string
+	// Actions are executed for the pod of pod events; events of other objects
+	// only notify
+	Actions []string
+	// MinCount is how many times the event must have been seen; zero reports
+	// any occurrence
+	MinCount int32
+	// Within is how recent counted events must be; zero counts all events
+	Within time.Duration
+}
+
+// eventSubject is the object Warning events are about
+type eventSubject struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// compare orders event subjects by kind, namespace and name
+func (s eventSubject) compare(other eventSubject) int {
+	return cmp.Or(cmp.Compare(s.kind, other.kind), cmp.Compare(s.namespace, other.namespace), cmp.Compare(s.name, other.name))
+}
+
+// detectWarningEvents reports the objects of all namespaces with Warning events
+// of the selected reasons. Events of a pod that no longer exists, or of an
+// earlier pod of the same name, are ignored.
+func (d *Detector) detectWarningEvents(ctx context.Context, rule Rule) ([]Issue, error) {
+	events, err := d.warningEvents(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var pods map[string]*corev1.Pod // Key: namespace/name
+	var issues []Issue
+	for _, subject := range slices.SortedFunc(maps.Keys(events), eventSubject.compare) {
+		if subject.kind != "Pod" {
+			meta := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: subject.namespace, Name: subject.name}}
+			issues = append(issues, d.decide(ctx, rule, subject.kind, meta, func(trace *Trace) []Issue {
+				return d.checkWarningEvents(rule, subject, nil, events[subject], trace)
+			})...)
+			continue
+		}
+
+		if pods == nil {
+			list, err := d.listPods(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list pods: %w", err)
+			}
+			pods = make(map[string]*corev1.Pod, len(list.Items))
+			for i := range list.Items {
+				pods[list.Items[i].Namespace+"/"+list.Items[i].Name] = &list.Items[i]
+			}
+		}
+		pod, exists := pods[subject.namespace+"/"+subject.name]
+		if !exists {
+			continue
+		}
+		issues = append(issues, d.decide(ctx, rule, "Pod", pod, func(trace *Trace) []Issue {
+			return d.checkWarningEvents(rule, subject, pod, events[subject], trace)
+		})...)
+	}
+	return issues, nil
+}
+
+// warningEvents returns the Warning events of the selected reasons by the
+// object they are about, of all namespaces if namespace is empty and of all
+// objects if name is empty; nothing is listed unless reasons are selected
+func (d *Detector) warningEvents(ctx context.Context, namespace, name string) (map[eventSubject][]corev1.Event, error) {
+	events := make(map[eventSubject][]corev1.Event)
+	if len(d.config.EventReasons) == 0 {
+		return events, nil
+	}
+	reasons := make(map[string]bool, len(d.config.EventReasons))
+	for _, reason := range d.config.EventReasons {
+		reasons[reason.Reason] = true
+	}
+
+	selector := "type=" + corev1.EventTypeWarning
+	if name != "" {
+		selector += ",involvedObject.name=" + name
+	}
+	err := d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		opts.FieldSelector = selector
+		return d.client.CoreV1().Events(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		event, ok := obj.(*corev1.Event)
+		if !ok || event.Type != corev1.EventTypeWarning || !reasons[event.Reason] || (name != "" && event.InvolvedObject.Name != name) {
+			return nil
+		}
+		subject := eventSubject{kind: event.InvolvedObject.Kind, namespace: event.InvolvedObject.Namespace, name: event.InvolvedObject.Name}
+		events[subject] = append(events[subject], *event)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warning events: %w", err)
+	}
+	return events, nil
+}
+
+// checkWarningEvents reports the selected reasons of the Warning events of an
+// object seen at least their minimum count within their window. Each event
+// counts as many times as the kubelet or controller aggregated it. pod is the
+// pod of pod events, whose events of an earlier pod of the same name are
+// ignored, and nil for other objects.
+func (d *Detector) checkWarningEvents(rule Rule, subject eventSubject, pod *corev1.Pod, events []corev1.Event, trace *Trace) []Issue {
+	var issues []Issue
+	for _, reason := range d.config.EventReasons {
+		var count int32
+		var latest *corev1.Event
+		for i := range events {
+			event := &events[i]
+			if event.Reason != reason.Reason || (pod != nil && event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pod.UID) {
+				continue
+			}
+			seen := eventTime(event)
+			if reason.Within > 0 && d.clock.Since(seen) > reason.Within {
+				continue
+			}
+			count += max(event.Count, 1)
+			if latest == nil || seen.After(eventTime(latest)) {
+				latest = event
+			}
+		}
+		minCount := max(reason.MinCount, 1)
+		window := "any time"
+		if reason.Within > 0 {
+			window = fmt.Sprintf("within %s", reason.Within)
+		}
+		if !trace.check(fmt.Sprintf("events[reason=%s]", reason.Reason), "", count, fmt.Sprintf(">= %d %s", minCount, window), latest != nil && count >= minCount) {
+			continue
+		}
+		issues = append(issues, d.warningEventIssue(rule, subject, pod, reason, latest, count, window))
+	}
+	return issues
+}
+
+// warningEventIssue returns the issue of a reason of the Warning events of an
+// object, with the severity of the reason; only issues of pods have actions
+func (d *Detector) warningEventIssue(rule Rule, subject eventSubject, pod *corev1.Pod, reason EventReason, latest *corev1.Event, count int32, window string) Issue {
+	severity := reason.Severity
+	if severity == "" {
+		severity = rule.Severity
+	}
+	description := fmt.Sprintf("%s (%s event %s seen %d times %s", rule.Description, subject.kind, reason.Reason, count, window)
+	if message := strings.TrimSpace(latest.Message); message != "" {
+		description += ": " + message
+	}
+	issue := Issue{
+		RuleName:    rule.Name,
+		Description: description + ")",
+		Severity:    severity,
+		Resource:    latest.DeepCopy(),
+		Namespace:   subject.namespace,
+		Name:        subject.name,
+		Kind:        subject.kind,
+		Reason:      reason.Reason,
+		Container:   eventContainer(latest.InvolvedObject.FieldPath),
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}
+	if pod != nil {
+		issue.Resource = pod.DeepCopy()
+		issue.Actions = reason.Actions
+	}
+	return issue
+}
+
+// eventContainer returns the container an event is about from the field path of
+// its involved object, e.g. spec.containers{app}, or "" for the whole object
+func eventContainer(fieldPath string) string {
+	for _, prefix := range []string{"spec.containers{", "spec.initContainers{", "spec.ephemeralContainers{"} {
+		if name, ok := strings.CutPrefix(fieldPath, prefix); ok {
+			return strings.TrimSuffix(name, "}")
+		}
+	}
+	return ""
+}
+
+// traceWarningEvents traces the warning event rule against a pod
+func (d *Detector) traceWarningEvents(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "Pod"
+	pod, err := d.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	events, err := d.warningEvents(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	subject := eventSubject{kind: "Pod", namespace: namespace, name: name}
+	return d.checkWarningEvents(rule, subject, pod, events[subject], trace), nil
+}
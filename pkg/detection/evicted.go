@@ -0,0 +1,141 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EvictedPodsRule is the rule reporting namespaces where evicted and failed pods accumulate
+const EvictedPodsRule = "evicted-pods"
+
+// CleanupEvictedPodsAction deletes the evicted and failed pods of a namespace
+const CleanupEvictedPodsAction = "cleanup-evicted-pods"
+
+// reasonEvicted is the status reason of pods evicted by the kubelet
+const reasonEvicted = "Evicted"
+
+// EvictedPods configures the evicted pods rule: a namespace is reported once it
+// holds Threshold failed pods that failed at least MinAge ago
+type EvictedPods struct {
+	Threshold int
+	MinAge    time.Duration
+}
+
+// failedPod returns true if a pod has failed and is kept only for its status.
+// Pods of Jobs are left to the Job, which counts them towards its backoff limit.
+func failedPod(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodFailed || pod.DeletionTimestamp != nil {
+		return false
+	}
+	owner := metav1.GetControllerOf(pod)
+	return owner == nil || owner.Kind != "Job"
+}
+
+// failedAt returns when a failed pod failed: when its last container terminated,
+// or when it started if no container terminated, e.g. when it was evicted
+// before its containers started
+func failedAt(pod *corev1.Pod) time.Time {
+	var failed time.Time
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(failed) {
+			failed = terminated.FinishedAt.Time
+		}
+	}
+	if failed.IsZero() && pod.Status.StartTime != nil {
+		failed = pod.Status.StartTime.Time
+	}
+	if failed.IsZero() {
+		failed = pod.CreationTimestamp.Time
+	}
+	return failed
+}
+
+// detectEvictedPods reports the namespaces holding at least the threshold of
+// failed pods
+func (d *Detector) detectEvictedPods(ctx context.Context, rule Rule) ([]Issue, error) {
+	pods, err := d.listPods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	byNamespace := make(map[string][]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		byNamespace[pod.Namespace] = append(byNamespace[pod.Namespace], pod)
+	}
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var issues []Issue
+	for _, namespace := range namespaces {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+		issues = append(issues, d.decide(ctx, rule, "Namespace", ns, func(trace *Trace) []Issue {
+			return d.checkEvictedPods(rule, namespace, byNamespace[namespace], trace)
+		})...)
+	}
+	return issues, nil
+}
+
+// checkEvictedPods evaluates the evicted pods rule against the pods of a namespace
+func (d *Detector) checkEvictedPods(rule Rule, namespace string, pods []*corev1.Pod, trace *Trace) []Issue {
+	failed, evicted := 0, 0
+	var oldest time.Time
+	for _, pod := range pods {
+		if !failedPod(pod) || d.clock.Since(failedAt(pod)) < d.config.EvictedPods.MinAge {
+			continue
+		}
+		failed++
+		if pod.Status.Reason == reasonEvicted {
+			evicted++
+		}
+		if at := failedAt(pod); oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+	}
+
+	threshold := d.config.EvictedPods.Threshold
+	if !trace.check(fmt.Sprintf("pods failed for >= %s", d.config.EvictedPods.MinAge), "", failed, fmt.Sprintf(">= %d", threshold), failed >= threshold) {
+		return nil
+	}
+
+	return []Issue{{
+		RuleName: rule.Name,
+		Description: fmt.Sprintf("%s: %d failed pods, %d of them evicted, the oldest failed %s ago",
+			rule.Description, failed, evicted, formatAge(d.clock.Since(oldest))),
+		Severity: rule.Severity,
+		Resource: &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+		},
+		Namespace:  namespace,
+		Name:       namespace,
+		Kind:       "Namespace",
+		Reason:     "FailedPodsAccumulating",
+		Actions:    rule.Actions,
+		Labels:     rule.Labels,
+		DetectedAt: d.clock.Now(),
+	}}
+}
+
+// traceEvictedPods traces the evicted pods rule against a namespace
+func (d *Detector) traceEvictedPods(ctx context.Context, rule Rule, namespace string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "Namespace"
+	trace.Name = namespace
+	list, err := d.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(list.Items))
+	for i := range list.Items {
+		pods = append(pods, &list.Items[i])
+	}
+	return d.checkEvictedPods(rule, namespace, pods, trace), nil
+}
@@ -0,0 +1,69 @@
+package detection
+
+import (
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Exclusion matches the workloads whose issues are excluded from detection or
+// remediation. Empty fields match everything.
+type Exclusion struct {
+	Namespace string
+	Kind      string
+	// Name is the exact workload name, Pattern a regular expression matching the
+	// whole workload name
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Matches returns true if the exclusion matches the workload affected by issue
+func (e Exclusion) Matches(issue Issue) bool {
+	if e.Namespace != "" && e.Namespace != issue.Namespace {
+		return false
+	}
+
+	kind, name := WorkloadOf(issue)
+	if e.Kind != "" && e.Kind != kind {
+		return false
+	}
+	if e.Name != "" && e.Name != name {
+		return false
+	}
+	return e.Pattern == nil || e.Pattern.MatchString(name)
+}
+
+// Excluded returns the index of the first exclusion matching issue, or -1
+func Excluded(exclusions []Exclusion, issue Issue) int {
+	for i, exclusion := range exclusions {
+		if exclusion.Matches(issue) {
+			return i
+		}
+	}
+	return -1
+}
+
+// WorkloadOf returns the kind and name of the workload affected by an issue: the
+// Deployment, StatefulSet, DaemonSet or Job controlling a pod, or the resource itself.
+// The Deployment of a pod is derived from its ReplicaSet without API calls.
+func WorkloadOf(issue Issue) (kind, name string) {
+	pod, ok := issue.Resource.(*corev1.Pod)
+	if !ok {
+		return issue.Kind, issue.Name
+	}
+
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		if hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ref.Kind == "ReplicaSet" && hash != "" {
+			if deployment, found := strings.CutSuffix(ref.Name, "-"+hash); found {
+				return "Deployment", deployment
+			}
+		}
+		return ref.Kind, ref.Name
+	}
+	return issue.Kind, issue.Name
+}
@@ -0,0 +1,131 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// exprCostLimit bounds the evaluation cost of an expression, so an expression
+// iterating large lists cannot stall detection
+const exprCostLimit = 1000000
+
+// celReserved are identifiers CEL reserves; kinds lowercasing to them are only
+// available as object
+var celReserved = []string{"as", "break", "const", "continue", "else", "for", "function", "if", "import", "let", "loop", "package", "namespace", "return", "var", "void", "while"}
+
+// exprPrograms caches the compiled expressions of rules by kind and expression
+var exprPrograms sync.Map
+
+// exprVariable returns the name an expression refers to resources of a kind by,
+// e.g. pod for Pod; empty if the kind can only be referred to as object
+func exprVariable(kind string) string {
+	name := strings.ToLower(kind)
+	if name == "object" || name == "metrics" || slices.Contains(celReserved, name) {
+		return ""
+	}
+	return name
+}
+
+// compileExpr compiles a CEL expression evaluated against resources of a kind.
+// The resource is available as object and under its lowercase kind, e.g. pod,
+// and its metrics as metrics.
+func compileExpr(kind, expr string) (cel.Program, error) {
+	key := kind + "\n" + expr
+	if program, cached := exprPrograms.Load(key); cached {
+		return program.(cel.Program), nil
+	}
+
+	options := []cel.EnvOption{
+		cel.Variable("object", cel.DynType),
+		cel.Variable("metrics", cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+		ext.Strings(),
+	}
+	if variable := exprVariable(kind); variable != "" {
+		options = append(options, cel.Variable(variable, cel.DynType))
+	}
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expression environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression returns %s, must return a bool", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(exprCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	exprPrograms.Store(key, program)
+	return program, nil
+}
+
+// exprVariables returns the variables expressions are evaluated with for a
+// resource. The metrics of a pod hold the usage of its containers reported by
+// metrics-server, e.g. metrics.containers['app'].cpu in cores and
+// metrics.containers['app'].memory in bytes, and are empty for other resources
+// or while metrics are unavailable.
+func (d *Detector) exprVariables(ctx context.Context, kind string, object map[string]interface{}, obj interface{}) map[string]interface{} {
+	metrics := map[string]interface{}{}
+	if pod, ok := obj.(*corev1.Pod); ok {
+		if usage, available := d.podUsage(ctx, pod); available {
+			containers := make(map[string]interface{}, len(usage))
+			for name, resources := range usage {
+				container := make(map[string]interface{}, len(resources))
+				for resource, quantity := range resources {
+					container[string(resource)] = quantity.AsApproximateFloat64()
+				}
+				containers[name] = container
+			}
+			metrics["containers"] = containers
+		}
+	}
+
+	variables := map[string]interface{}{"object": object, "metrics": metrics}
+	if variable := exprVariable(kind); variable != "" {
+		variables[variable] = object
+	}
+	return variables
+}
+
+// hasExprs returns true if any condition is an expression
+func hasExprs(conditions []fieldCondition) bool {
+	return slices.ContainsFunc(conditions, func(condition fieldCondition) bool {
+		return condition.program != nil
+	})
+}
+
+// matchExprs evaluates the expression conditions of a field rule. An expression
+// that fails to evaluate, e.g. because it reads a missing field, does not match.
+func matchExprs(conditions []fieldCondition, variables map[string]interface{}, trace *Trace) bool {
+	for _, condition := range conditions {
+		if condition.program == nil {
+			continue
+		}
+
+		var value interface{}
+		matched := false
+		result, _, err := condition.program.Eval(variables)
+		if err != nil {
+			value = fmt.Sprintf("error: %v", err)
+		} else {
+			value = result.Value()
+			matched = value == true
+		}
+		if !trace.check("expr: "+condition.Expr, "", value, "true", matched) {
+			return false
+		}
+	}
+	return true
+}
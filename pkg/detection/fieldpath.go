@@ -0,0 +1,147 @@
+package detection
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a field of a field path, optionally indexed: each selects every
+// element of a list, index a single one
+type pathSegment struct {
+	name  string
+	index int // -1 if not indexed
+	each  bool
+}
+
+// fieldPath is a parsed field path like status.containerStatuses[*].state.waiting.reason
+type fieldPath []pathSegment
+
+// parseFieldPath parses a dotted field path. A field may be followed by [*] for
+// every element of a list or [N] for the element at index N, and fields whose
+// name contains dots are quoted in brackets, e.g. metadata.labels['app.kubernetes.io/name'].
+// JSONPath notation like {.status.phase} is accepted as well.
+func parseFieldPath(path string) (fieldPath, error) {
+	trimmed := strings.TrimSpace(path)
+	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		trimmed = trimmed[1 : len(trimmed)-1]
+	}
+	trimmed = strings.TrimPrefix(trimmed, ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty field path")
+	}
+
+	var segments fieldPath
+	for i := 0; i < len(trimmed); {
+		switch trimmed[i] {
+		case '.':
+			i++
+			if i == len(trimmed) || trimmed[i] == '.' || trimmed[i] == '[' {
+				return nil, fmt.Errorf("empty field at offset %d of %q", i, path)
+			}
+		case '[':
+			end := strings.IndexByte(trimmed[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in %q", path)
+			}
+			selector := trimmed[i+1 : i+end]
+			i += end + 1
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				segments = append(segments, pathSegment{name: selector[1 : len(selector)-1], index: -1})
+				continue
+			}
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("index [%s] without a field in %q", selector, path)
+			}
+			last := &segments[len(segments)-1]
+			if last.each || last.index >= 0 {
+				return nil, fmt.Errorf("field %s indexed twice in %q", last.name, path)
+			}
+			if selector == "*" {
+				last.each = true
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index [%s] in %q, must be * or a non-negative number", selector, path)
+			}
+			last.index = index
+		default:
+			end := strings.IndexAny(trimmed[i:], ".[")
+			if end < 0 {
+				end = len(trimmed) - i
+			}
+			segments = append(segments, pathSegment{name: trimmed[i : i+end], index: -1})
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+// String formats the path, quoting fields whose name contains dots
+func (p fieldPath) String() string {
+	var b strings.Builder
+	for i, segment := range p {
+		if strings.ContainsAny(segment.name, ".[]") {
+			fmt.Fprintf(&b, "['%s']", segment.name)
+		} else {
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(segment.name)
+		}
+		switch {
+		case segment.each:
+			b.WriteString("[*]")
+		case segment.index >= 0:
+			fmt.Fprintf(&b, "[%d]", segment.index)
+		}
+	}
+	return b.String()
+}
+
+// splitList splits the path at its first [*] into the path of the list and the
+// path relative to its elements; a path without [*] has no list
+func (p fieldPath) splitList() (list, element fieldPath) {
+	for i, segment := range p {
+		if segment.each {
+			return p[:i+1], p[i+1:]
+		}
+	}
+	return nil, p
+}
+
+// values resolves the path against an unstructured value. A [*] segment expands
+// to every element of the list, a missing field or index to no values, and an
+// empty path to the value itself.
+func (p fieldPath) values(value interface{}) []interface{} {
+	values := []interface{}{value}
+	for _, segment := range p {
+		var next []interface{}
+		for _, current := range values {
+			fields, ok := current.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			field, exists := fields[segment.name]
+			if !exists {
+				continue
+			}
+			if !segment.each && segment.index < 0 {
+				next = append(next, field)
+				continue
+			}
+			list, ok := field.([]interface{})
+			switch {
+			case !ok:
+			case segment.each:
+				next = append(next, list...)
+			case segment.index < len(list):
+				next = append(next, list[segment.index])
+			}
+		}
+		values = next
+	}
+	return values
+}
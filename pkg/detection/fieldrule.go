@@ -0,0 +1,335 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Operators of field conditions, besides the threshold operators
+const (
+	OperatorNotEquals = "not_equals"
+	OperatorContains  = "contains"
+	OperatorRegex     = "regex"
+)
+
+// FieldOperators are the operators the conditions of field rules compare field values with
+var FieldOperators = []string{OperatorEquals, OperatorNotEquals, OperatorGreaterThan, OperatorLessThan, OperatorContains, OperatorRegex}
+
+// FieldResources are the kinds of resources field rules read with the typed
+// clients and can remediate. Field rules on other kinds set the apiVersion of
+// their conditions, are read with the dynamic client and only notify.
+var FieldResources = []string{"Pod", "Deployment", "Node"}
+
+// isFieldRule returns true for the custom rules of the rules file, whose
+// conditions compare fields of the resource
+func (r Rule) isFieldRule() bool {
+	_, isQueryRule := r.queryCondition()
+	return !isQueryRule && len(r.Conditions) > 0
+}
+
+// fieldCondition is a condition of a field rule with its field path parsed. A
+// condition on the elements of a list has the path up to the first [*] as list
+// and the path relative to the elements as path.
+type fieldCondition struct {
+	RuleCondition
+	list    fieldPath
+	path    fieldPath
+	pattern *regexp.Regexp // Compiled value of regex conditions
+	program cel.Program    // Compiled expression of expression conditions
+}
+
+// compileFieldConditions checks that the conditions of a field rule can be
+// evaluated and parses their field paths
+func compileFieldConditions(rule Rule) ([]fieldCondition, error) {
+	first := rule.Conditions[0]
+	kind := first.Resource
+	if kind == "" {
+		return nil, fmt.Errorf("condition on %s: no resource", first.Field)
+	}
+	switch {
+	case first.APIVersion == "" && !slices.Contains(FieldResources, kind):
+		return nil, fmt.Errorf("resource %q needs an apiVersion, only %v can omit it", kind, FieldResources)
+	case first.APIVersion != "" && len(rule.Actions) > 0:
+		return nil, fmt.Errorf("rules on resources with an apiVersion cannot have actions, use %s", NotifyOnlyAction)
+	case kind == "Node" && len(rule.Actions) > 0:
+		return nil, fmt.Errorf("rules on nodes cannot have actions, use %s", NotifyOnlyAction)
+	}
+
+	conditions := make([]fieldCondition, 0, len(rule.Conditions))
+	for _, condition := range rule.Conditions {
+		if condition.Resource != kind || condition.APIVersion != first.APIVersion {
+			return nil, fmt.Errorf("condition on %s: all conditions must select the same resource, got %s and %s", condition.Field, kind, condition.Resource)
+		}
+		if condition.Expr != "" {
+			if condition.Field != "" || condition.Operator != "" || condition.Value != nil {
+				return nil, fmt.Errorf("condition %q: expressions cannot have a field, operator or value", condition.Expr)
+			}
+			program, err := compileExpr(kind, condition.Expr)
+			if err != nil {
+				return nil, fmt.Errorf("condition %q: %w", condition.Expr, err)
+			}
+			conditions = append(conditions, fieldCondition{RuleCondition: condition, program: program})
+			continue
+		}
+		if condition.Field == "" {
+			return nil, fmt.Errorf("condition on %s: no field", kind)
+		}
+		if condition.Value == nil {
+			return nil, fmt.Errorf("condition on %s: no value", condition.Field)
+		}
+		path, err := parseFieldPath(condition.Field)
+		if err != nil {
+			return nil, fmt.Errorf("condition on %s: %w", condition.Field, err)
+		}
+
+		compiled := fieldCondition{RuleCondition: condition}
+		compiled.list, compiled.path = path.splitList()
+		switch condition.Operator {
+		case OperatorEquals, OperatorNotEquals, OperatorContains:
+		case OperatorRegex:
+			pattern, err := regexp.Compile(fmt.Sprint(condition.Value))
+			if err != nil {
+				return nil, fmt.Errorf("condition on %s: invalid regex: %w", condition.Field, err)
+			}
+			compiled.pattern = pattern
+		case OperatorGreaterThan, OperatorLessThan:
+			if _, err := thresholdValue(condition.Value); err != nil {
+				return nil, fmt.Errorf("condition on %s: %s needs a number: %w", condition.Field, condition.Operator, err)
+			}
+		default:
+			return nil, fmt.Errorf("condition on %s: unsupported operator %q, must be one of %v", condition.Field, condition.Operator, FieldOperators)
+		}
+		conditions = append(conditions, compiled)
+	}
+	return conditions, nil
+}
+
+// fieldRuleDuration returns the longest duration of the conditions of a field rule
+func fieldRuleDuration(rule Rule) time.Duration {
+	var duration time.Duration
+	for _, condition := range rule.Conditions {
+		if condition.Duration != nil && condition.Duration.Duration > duration {
+			duration = condition.Duration.Duration
+		}
+	}
+	return duration
+}
+
+// detectFields evaluates a field rule against all resources of its kind
+func (d *Detector) detectFields(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	conditions, err := compileFieldConditions(rule)
+	if err != nil {
+		return issues, err
+	}
+	kind := rule.Conditions[0].Resource
+	objects, err := d.listResources(ctx, rule.Conditions[0].APIVersion, kind)
+	if err != nil {
+		return issues, fmt.Errorf("failed to list %s resources: %w", kind, err)
+	}
+
+	for _, obj := range objects {
+		issues = append(issues, d.decide(ctx, rule, kind, obj, func(trace *Trace) []Issue {
+			return d.checkFields(ctx, rule, conditions, obj, trace)
+		})...)
+	}
+
+	return issues, nil
+}
+
+// checkFields evaluates a field rule against a resource. The rule fires once all
+// of its conditions have matched for the longest of their durations.
+func (d *Detector) checkFields(ctx context.Context, rule Rule, conditions []fieldCondition, obj runtime.Object, trace *Trace) []Issue {
+	logger := log.FromContext(ctx)
+
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		logger.Error(err, "Failed to access resource metadata", "rule", rule.Name)
+		return nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		logger.Error(err, "Failed to convert resource", "rule", rule.Name, "namespace", object.GetNamespace(), "name", object.GetName())
+		return nil
+	}
+
+	if !matchFields(content, conditions, trace) {
+		return nil
+	}
+	if hasExprs(conditions) && !matchExprs(conditions, d.exprVariables(ctx, rule.Conditions[0].Resource, content, obj), trace) {
+		return nil
+	}
+
+	duration := fieldRuleDuration(rule)
+	held := d.conditionHeld(conditionKey(rule.Name, object.GetNamespace(), object.GetName()), trace)
+	if !trace.check("conditions held for", "", held, fmt.Sprintf(">= %s", duration), held >= duration) {
+		return nil
+	}
+
+	return []Issue{{
+		RuleName:    rule.Name,
+		Description: rule.Description,
+		Severity:    rule.Severity,
+		Resource:    obj,
+		Namespace:   object.GetNamespace(),
+		Name:        object.GetName(),
+		Kind:        rule.Conditions[0].Resource,
+		Reason:      "FieldConditions",
+		Actions:     rule.Actions,
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}}
+}
+
+// matchFields evaluates the field conditions of a field rule against an
+// unstructured object. A [*] segment of a field matches any element of a list, and the
+// conditions on the same list, e.g. status.conditions[*].type and
+// status.conditions[*].status, must match the same element.
+func matchFields(object map[string]interface{}, conditions []fieldCondition, trace *Trace) bool {
+	var lists []string
+	listPaths := make(map[string]fieldPath)
+	elementConditions := make(map[string][]fieldCondition)
+	for _, condition := range conditions {
+		if condition.program != nil {
+			continue
+		}
+		if condition.list != nil {
+			list := condition.list.String()
+			if _, exists := elementConditions[list]; !exists {
+				lists = append(lists, list)
+				listPaths[list] = condition.list
+			}
+			elementConditions[list] = append(elementConditions[list], condition)
+			continue
+		}
+
+		values := condition.path.values(object)
+		if !trace.check(condition.Field, "", formatValues(values), condition.expected(), condition.matches(values)) {
+			return false
+		}
+	}
+
+	for _, list := range lists {
+		elements := listPaths[list].values(object)
+		matching := 0
+		for _, element := range elements {
+			if matchElement(element, elementConditions[list]) {
+				matching++
+			}
+		}
+
+		var expected []string
+		for _, condition := range elementConditions[list] {
+			expected = append(expected, condition.path.String()+" "+condition.expected())
+		}
+		if !trace.check(list, "", fmt.Sprintf("%d of %d elements", matching, len(elements)), "an element with "+strings.Join(expected, ", "), matching > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchElement returns true if all conditions on a list match the element
+func matchElement(element interface{}, conditions []fieldCondition) bool {
+	for _, condition := range conditions {
+		if !condition.matches(condition.path.values(element)) {
+			return false
+		}
+	}
+	return true
+}
+
+// expected describes the values matching the condition for traces
+func (c fieldCondition) expected() string {
+	return fmt.Sprintf("%s %v", c.Operator, c.Value)
+}
+
+// matches returns true if the resolved values of the field match the condition.
+// not_equals matches if no value equals the expected one, including a missing
+// field; the other operators match if any value does.
+func (c fieldCondition) matches(values []interface{}) bool {
+	if c.Operator == OperatorNotEquals {
+		return !slices.ContainsFunc(values, c.equals)
+	}
+	return slices.ContainsFunc(values, c.matchesValue)
+}
+
+// equals compares a field value with the value of the condition by their text
+func (c fieldCondition) equals(value interface{}) bool {
+	return fmt.Sprint(value) == fmt.Sprint(c.Value)
+}
+
+// matchesValue compares a field value with the value of the condition: contains
+// matches a substring of a text or an element of a list, regex matches texts and
+// scalars, and the threshold operators compare numeric values
+func (c fieldCondition) matchesValue(value interface{}) bool {
+	switch c.Operator {
+	case OperatorEquals:
+		return c.equals(value)
+	case OperatorContains:
+		switch v := value.(type) {
+		case string:
+			return strings.Contains(v, fmt.Sprint(c.Value))
+		case []interface{}:
+			return slices.ContainsFunc(v, c.equals)
+		default:
+			return false
+		}
+	case OperatorRegex:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return false
+		default:
+			return c.pattern.MatchString(fmt.Sprint(value))
+		}
+	}
+
+	actual, ok := numericField(value)
+	if !ok {
+		return false
+	}
+	threshold, err := thresholdValue(c.Value)
+	if err != nil {
+		return false
+	}
+	matched, err := compareThreshold(actual, c.Operator, threshold)
+	return err == nil && matched
+}
+
+// numericField converts an unstructured field value to a number
+func numericField(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// formatValues formats the resolved values of a field for traces
+func formatValues(values []interface{}) string {
+	switch len(values) {
+	case 0:
+		return "<unset>"
+	case 1:
+		return fmt.Sprint(values[0])
+	default:
+		return fmt.Sprint(values)
+	}
+}
@@ -0,0 +1,147 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StuckFinalizerRule is the rule reporting resources stuck deleting on an
+// allow-listed finalizer
+const StuckFinalizerRule = "stuck-finalizer"
+
+// RemoveFinalizerAction removes an allow-listed finalizer from a resource stuck deleting
+const RemoveFinalizerAction = "remove-finalizer"
+
+// FinalizerKinds are the kinds of resources the stuck finalizer rule checks
+var FinalizerKinds = []string{"Pod", "Deployment", "Service", "PersistentVolumeClaim"}
+
+// AllowedFinalizer is a finalizer that may be removed from resources of Kinds
+// stuck deleting, e.g. the load balancer cleanup finalizer of Services whose
+// cloud controller is gone
+type AllowedFinalizer struct {
+	Name  string
+	Kinds []string
+}
+
+// finalizerKinds returns the kinds with allowed finalizers, in the order of FinalizerKinds
+func (d *Detector) finalizerKinds() []string {
+	var kinds []string
+	for _, kind := range FinalizerKinds {
+		if slices.ContainsFunc(d.config.Finalizers, func(allowed AllowedFinalizer) bool { return slices.Contains(allowed.Kinds, kind) }) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// allowedFinalizers returns the finalizers of a resource of a kind that may be removed
+func (d *Detector) allowedFinalizers(kind string, finalizers []string) []string {
+	var allowed []string
+	for _, finalizer := range finalizers {
+		if slices.ContainsFunc(d.config.Finalizers, func(entry AllowedFinalizer) bool {
+			return entry.Name == finalizer && slices.Contains(entry.Kinds, kind)
+		}) {
+			allowed = append(allowed, finalizer)
+		}
+	}
+	return allowed
+}
+
+// detectStuckFinalizers reports the resources of the kinds with allowed
+// finalizers that have been deleting for longer than the configured threshold
+func (d *Detector) detectStuckFinalizers(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	for _, kind := range d.finalizerKinds() {
+		objects, err := d.listResources(ctx, "", kind)
+		if err != nil {
+			return issues, fmt.Errorf("failed to list %s resources: %w", kind, err)
+		}
+		for _, obj := range objects {
+			issues = append(issues, d.decide(ctx, rule, kind, obj, func(trace *Trace) []Issue {
+				return d.checkStuckFinalizer(rule, kind, obj, trace)
+			})...)
+		}
+	}
+
+	return issues, nil
+}
+
+// checkStuckFinalizer evaluates the stuck finalizer rule against a resource. Only
+// resources waiting on an allowed finalizer are reported; the other finalizers
+// are listed in the description but never removed.
+func (d *Detector) checkStuckFinalizer(rule Rule, kind string, obj runtime.Object, trace *Trace) []Issue {
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+
+	deleting := object.GetDeletionTimestamp()
+	if !trace.check("metadata.deletionTimestamp", "", deleting, "set", deleting != nil) {
+		return nil
+	}
+	age := d.clock.Since(deleting.Time).Round(time.Second)
+	if !trace.check("deleting for", "", age, fmt.Sprintf(">= %s", d.config.StuckFinalizerAfter), age >= d.config.StuckFinalizerAfter) {
+		return nil
+	}
+	allowed := d.allowedFinalizers(kind, object.GetFinalizers())
+	if !trace.check("metadata.finalizers", "", object.GetFinalizers(), "an allow-listed finalizer", len(allowed) > 0) {
+		return nil
+	}
+
+	return []Issue{{
+		RuleName: rule.Name,
+		Description: fmt.Sprintf("%s: deleting for %s, waiting on finalizers %s; allow-listed for removal: %s",
+			rule.Description, formatAge(age), strings.Join(object.GetFinalizers(), ", "), strings.Join(allowed, ", ")),
+		Severity:   rule.Severity,
+		Resource:   obj.DeepCopyObject(),
+		Namespace:  object.GetNamespace(),
+		Name:       object.GetName(),
+		Kind:       kind,
+		Reason:     "StuckFinalizer",
+		Actions:    rule.Actions,
+		Labels:     rule.Labels,
+		DetectedAt: d.clock.Now(),
+	}}
+}
+
+// traceStuckFinalizer traces the stuck finalizer rule against the first
+// resource with the name among the kinds with allowed finalizers
+func (d *Detector) traceStuckFinalizer(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	for _, kind := range d.finalizerKinds() {
+		obj, err := d.getFinalizerResource(ctx, kind, namespace, name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		trace.Kind = kind
+		return d.checkStuckFinalizer(rule, kind, obj, trace), nil
+	}
+	return nil, fmt.Errorf("no %s %s/%s found", strings.Join(d.finalizerKinds(), " or "), namespace, name)
+}
+
+// getFinalizerResource gets a resource of a kind the stuck finalizer rule checks
+func (d *Detector) getFinalizerResource(ctx context.Context, kind, namespace, name string) (runtime.Object, error) {
+	switch kind {
+	case "Pod":
+		return d.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Deployment":
+		return d.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Service":
+		return d.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "PersistentVolumeClaim":
+		return d.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, fmt.Errorf("unsupported resource %q", kind)
+	}
+}
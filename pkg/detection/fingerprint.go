@@ -0,0 +1,20 @@
+package detection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Fingerprint returns a stable identifier for an issue, derived from the rule, the
+// affected resource, the reason and the container, if any. The same unresolved
+// issue keeps its fingerprint across evaluation cycles.
+func (i Issue) Fingerprint() string {
+	parts := []string{i.RuleName, i.Namespace, i.Kind, i.Name, i.Reason}
+	if i.Container != "" {
+		parts = append(parts, i.Container)
+	}
+	key := strings.Join(parts, "/")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,164 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodFlappingRule is the rule reporting containers that restarted too often
+// within a recent window, regardless of how often they restarted before
+const PodFlappingRule = "pod-flapping"
+
+// reasonFlapping is the reason of pod flapping issues
+const reasonFlapping = "Flapping"
+
+// FlappingConfig contains pod flapping detection settings for a namespace
+type FlappingConfig struct {
+	// RestartLimit is how many restarts within Window are reported; zero uses
+	// the global limit
+	RestartLimit int `yaml:"restartLimit"`
+	// Window is how far back restarts are counted; zero uses the global window
+	Window time.Duration `yaml:"window"`
+}
+
+// restartSample is the restart count of a container since a point in time
+type restartSample struct {
+	at    time.Time
+	count int32
+}
+
+// restartHistory tracks the restart counts of containers over time, so the
+// restarts within a window can be told apart from the lifetime RestartCount
+type restartHistory struct {
+	mu      sync.Mutex
+	samples map[string][]restartSample // Restart count changes, oldest first
+	seen    map[string]time.Time       // Last observation of each container
+}
+
+func newRestartHistory() *restartHistory {
+	return &restartHistory{
+		samples: make(map[string][]restartSample),
+		seen:    make(map[string]time.Time),
+	}
+}
+
+// restarts records the restart count of a container and returns how often it
+// restarted within the window before now. Containers seen for the first time
+// count all restarts if their pod started within the window, and none
+// otherwise, since when the earlier restarts happened is unknown. record false
+// only reads the history.
+func (h *restartHistory) restarts(key string, now time.Time, window time.Duration, count int32, started time.Time, record bool) int32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := slices.Clone(h.samples[key])
+	// A lower count is a recreated container, whose history starts over
+	if len(samples) > 0 && count < samples[len(samples)-1].count {
+		samples = nil
+	}
+	if len(samples) == 0 && !started.IsZero() && now.Sub(started) <= window {
+		samples = append(samples, restartSample{at: started})
+	}
+	if len(samples) == 0 || samples[len(samples)-1].count != count {
+		samples = append(samples, restartSample{at: now, count: count})
+	}
+
+	// The latest change before the window is the count at its start
+	start := now.Add(-window)
+	first := 0
+	for i, sample := range samples {
+		if sample.at.After(start) {
+			break
+		}
+		first = i
+	}
+	samples = samples[first:]
+
+	if record {
+		h.samples[key] = samples
+		h.seen[key] = now
+	}
+	return count - samples[0].count
+}
+
+// forget drops the history of containers not observed since a time, e.g. of
+// deleted pods
+func (h *restartHistory) forget(before time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, seen := range h.seen {
+		if seen.Before(before) {
+			delete(h.samples, key)
+			delete(h.seen, key)
+		}
+	}
+}
+
+// flappingConfig returns the restart limit and window of a namespace, falling
+// back to the global settings for unset values
+func (d *Detector) flappingConfig(namespace string) FlappingConfig {
+	config := d.GetNamespaceConfig(namespace).Flapping
+	if config.RestartLimit <= 0 {
+		config.RestartLimit = d.config.Flapping.RestartLimit
+	}
+	if config.Window <= 0 {
+		config.Window = d.config.Flapping.Window
+	}
+	return config
+}
+
+// detectPodFlapping reports the containers of all pods that restarted at least
+// the restart limit of their namespace within its window, then forgets the
+// restart history of pods that are gone
+func (d *Detector) detectPodFlapping(ctx context.Context, rule Rule) ([]Issue, error) {
+	start := d.clock.Now()
+	issues, err := d.detectPods(ctx, rule, (*Detector).checkPodFlapping)
+	if err != nil {
+		return issues, err
+	}
+	d.restarts.forget(start)
+	return issues, nil
+}
+
+// checkPodFlapping evaluates the pod flapping rule against a pod. Traces only
+// read the restart history.
+func (d *Detector) checkPodFlapping(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	config := d.flappingConfig(pod.Namespace)
+	var started time.Time
+	if pod.Status.StartTime != nil {
+		started = pod.Status.StartTime.Time
+	}
+
+	var issues []Issue
+	now := d.clock.Now()
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		key := conditionKey(rule.Name, pod.Namespace, pod.Name, string(pod.UID), containerStatus.Name)
+		restarts := d.restarts.restarts(key, now, config.Window, containerStatus.RestartCount, started, !trace.readOnly())
+		if !trace.check("restarts within window", containerStatus.Name, restarts,
+			fmt.Sprintf(">= %d within %s", config.RestartLimit, config.Window), int(restarts) >= config.RestartLimit) {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (%d restarts within %s, %d in total, restart limit: %d)", rule.Description, restarts, config.Window, containerStatus.RestartCount, config.RestartLimit),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      reasonFlapping,
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  now,
+		})
+	}
+	return issues
+}
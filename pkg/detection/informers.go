@@ -0,0 +1,212 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Kinds of the objects re-evaluated on their own by DetectObject
+const (
+	KindPod        = "Pod"
+	KindDeployment = "Deployment"
+)
+
+// informedListers read pods and deployments from the caches of shared informers
+type informedListers struct {
+	pods        corelisters.PodLister
+	deployments appslisters.DeploymentLister
+	synced      []cache.InformerSynced
+}
+
+// SetInformers makes the rules read pods and deployments from the caches of the
+// shared informers of factory instead of listing them from the API server. The
+// informers are registered with the factory, which must be started afterwards;
+// until their caches have synced the rules keep listing from the API server.
+func (d *Detector) SetInformers(factory informers.SharedInformerFactory) {
+	pods := factory.Core().V1().Pods()
+	deployments := factory.Apps().V1().Deployments()
+	informed := &informedListers{
+		pods:        pods.Lister(),
+		deployments: deployments.Lister(),
+		synced:      []cache.InformerSynced{pods.Informer().HasSynced, deployments.Informer().HasSynced},
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.informed = informed
+}
+
+// listers returns the informer listers once their caches have synced, nil otherwise
+func (d *Detector) listers() *informedListers {
+	d.mu.RLock()
+	informed := d.informed
+	d.mu.RUnlock()
+	if informed == nil {
+		return nil
+	}
+	for _, synced := range informed.synced {
+		if !synced() {
+			return nil
+		}
+	}
+	return informed
+}
+
+// ObjectRules returns the names of the loaded rules DetectObject evaluates for
+// objects of a kind: the built-in pod or deployment rules and the field rules
+// on the kind
+func (d *Detector) ObjectRules(kind string) []string {
+	var names []string
+	for _, rule := range d.rules {
+		if objectRule(rule, kind) {
+			names = append(names, rule.Name)
+		}
+	}
+	return names
+}
+
+// objectRule returns true if a rule is evaluated against single objects of a kind
+func objectRule(rule Rule, kind string) bool {
+	_, isPodRule := podChecks[rule.Name]
+	_, isDeploymentRule := deploymentChecks[rule.Name]
+	switch {
+	case isPodRule || isDeploymentRule:
+		return isPodRule && kind == KindPod || isDeploymentRule && kind == KindDeployment
+	case kind != KindPod && kind != KindDeployment:
+		return false
+	}
+	return rule.StatusConditions == nil && rule.isFieldRule() &&
+		rule.Conditions[0].Resource == kind && rule.Conditions[0].APIVersion == ""
+}
+
+// DetectObject evaluates the active rules of a pod or deployment against the
+// cached object, e.g. after a watch event reported a change of it. A deleted
+// object, one in a namespace out of scope, or one opted out with its annotation
+// has no issues.
+func (d *Detector) DetectObject(ctx context.Context, kind, namespace, name string) ([]Issue, error) {
+	if !slices.Contains([]string{KindPod, KindDeployment}, kind) {
+		return nil, fmt.Errorf("unsupported object kind %q", kind)
+	}
+	if !d.InScope(namespace) {
+		return nil, nil
+	}
+	informed := d.listers()
+	if informed == nil {
+		return nil, fmt.Errorf("informer caches of %s are not synced", kind)
+	}
+
+	var obj runtime.Object
+	if kind == KindPod {
+		pod, err := informed.pods.Pods(namespace).Get(name)
+		if err != nil {
+			return objectNotFound(kind, namespace, name, err)
+		}
+		obj = pod.DeepCopy()
+	} else {
+		deployment, err := informed.deployments.Deployments(namespace).Get(name)
+		if err != nil {
+			return objectNotFound(kind, namespace, name, err)
+		}
+		obj = deployment.DeepCopy()
+	}
+
+	var issues []Issue
+	for _, rule := range d.rules {
+		if !objectRule(rule, kind) || !d.ruleActive(ctx, rule) {
+			continue
+		}
+
+		podCheck, isPodRule := podChecks[rule.Name]
+		deploymentCheck, isDeploymentRule := deploymentChecks[rule.Name]
+		var ruleIssues []Issue
+		switch {
+		case isPodRule:
+			ruleIssues = podCheck(d, ctx, rule, obj.(*corev1.Pod), nil)
+		case isDeploymentRule:
+			ruleIssues = deploymentCheck(d, ctx, rule, obj.(*appsv1.Deployment), nil)
+		default:
+			conditions, err := compileFieldConditions(rule)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+			ruleIssues = d.checkFields(ctx, rule, conditions, obj, nil)
+		}
+
+		ruleIssues = applyContainerRules(rule, ruleIssues, nil)
+		enrichOwners(ruleIssues)
+		setRunbooks(rule, ruleIssues)
+		setRuleMetadata(rule, ruleIssues)
+		issues = append(issues, ruleIssues...)
+	}
+	return d.optOutIssues(ctx, issues), nil
+}
+
+// objectNotFound returns no issues for a deleted object and the error otherwise
+func objectNotFound(kind, namespace, name string, err error) ([]Issue, error) {
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, err)
+}
+
+// cachedPods returns the pods of the informer cache in namespaces in scope
+func (l *informedListers) cachedPods(inScope func(string) bool) (*corev1.PodList, error) {
+	pods, err := l.pods.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	list := &corev1.PodList{Items: make([]corev1.Pod, 0, len(pods))}
+	for _, pod := range pods {
+		if inScope(pod.Namespace) {
+			list.Items = append(list.Items, *pod.DeepCopy())
+		}
+	}
+	return list, nil
+}
+
+// cachedObjects returns the cached pods or deployments in namespaces in scope as objects
+func (l *informedListers) cachedObjects(kind string, inScope func(string) bool) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	if kind == KindPod {
+		pods, err := l.pods.List(labels.Everything())
+		for _, pod := range pods {
+			if inScope(pod.Namespace) {
+				objects = append(objects, pod.DeepCopy())
+			}
+		}
+		return objects, err
+	}
+	deployments, err := l.deployments.List(labels.Everything())
+	for _, deployment := range deployments {
+		if inScope(deployment.Namespace) {
+			objects = append(objects, deployment.DeepCopy())
+		}
+	}
+	return objects, err
+}
+
+// cachedDeployments returns the deployments of the informer cache in namespaces in scope
+func (l *informedListers) cachedDeployments(inScope func(string) bool) (*appsv1.DeploymentList, error) {
+	deployments, err := l.deployments.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	list := &appsv1.DeploymentList{Items: make([]appsv1.Deployment, 0, len(deployments))}
+	for _, deployment := range deployments {
+		if inScope(deployment.Namespace) {
+			list.Items = append(list.Items, *deployment.DeepCopy())
+		}
+	}
+	return list, nil
+}
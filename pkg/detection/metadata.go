@@ -0,0 +1,65 @@
+package detection
+
+import "time"
+
+// Rule sources
+const (
+	RuleSourceBuiltin = "builtin"
+	RuleSourceFile    = "file"
+	RuleSourceCRD     = "crd"
+)
+
+// DefaultRuleOwner owns the built-in rules unless another owner is configured
+const DefaultRuleOwner = "kubeguardian"
+
+// RuleMetadata tells whose rule it is, where it is defined and when it last
+// changed, so the people receiving its issues know whom to ask about it
+type RuleMetadata struct {
+	Owner string `yaml:"owner" json:"owner,omitempty"`
+	// Source is builtin, file or crd
+	Source string `yaml:"source" json:"source"`
+	// LastModified is when the rule definition last changed; zero if unknown
+	LastModified time.Time `yaml:"lastModified" json:"lastModified,omitzero"`
+}
+
+// RuleSummary describes a loaded rule for listings
+type RuleSummary struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Severity    string   `json:"severity"`
+	Actions     []string `json:"actions"`
+	Runbook     string   `json:"runbook,omitempty"`
+	RuleMetadata
+}
+
+// Summary returns the listing of the rule
+func (r Rule) Summary() RuleSummary {
+	return RuleSummary{
+		Name:         r.Name,
+		Description:  r.Description,
+		Enabled:      r.Enabled,
+		Severity:     r.Severity,
+		Actions:      r.Actions,
+		Runbook:      r.Runbook,
+		RuleMetadata: r.Metadata,
+	}
+}
+
+// applyMetadata overrides the owner and last modification time of a rule with
+// the configured values, if set. The source cannot be overridden.
+func applyMetadata(rule *Rule, configured RuleMetadata) {
+	if configured.Owner != "" {
+		rule.Metadata.Owner = configured.Owner
+	}
+	if !configured.LastModified.IsZero() {
+		rule.Metadata.LastModified = configured.LastModified
+	}
+}
+
+// setRuleMetadata attaches the metadata of a rule to its issues
+func setRuleMetadata(rule Rule, issues []Issue) {
+	for i := range issues {
+		issues[i].RuleMetadata = rule.Metadata
+	}
+}
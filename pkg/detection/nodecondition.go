@@ -0,0 +1,98 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeConditionRule is the rule reporting nodes whose kubelet conditions stay unhealthy
+const NodeConditionRule = "node-condition"
+
+// detectNodeConditions reports the unhealthy conditions of all nodes
+func (d *Detector) detectNodeConditions(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	nodes, err := d.listResources(ctx, "", "Node")
+	if err != nil {
+		return issues, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	for _, obj := range nodes {
+		if node, ok := obj.(*corev1.Node); ok {
+			issues = append(issues, d.decide(ctx, rule, "Node", node, func(trace *Trace) []Issue {
+				return d.checkNodeConditions(rule, node, trace)
+			})...)
+		}
+	}
+
+	return issues, nil
+}
+
+// checkNodeConditions reports the selected conditions of a node that have been
+// unhealthy for their duration: Ready is unhealthy unless it is True, which
+// includes Unknown once the kubelet stops posting status, and any other
+// condition, e.g. DiskPressure, while it is True. How long a condition has been
+// unhealthy is taken from its lastTransitionTime, or from when it was first
+// observed unhealthy if it is not set. Nodes without a condition never fire.
+func (d *Detector) checkNodeConditions(rule Rule, node *corev1.Node, trace *Trace) []Issue {
+	var issues []Issue
+
+	byType := make(map[string]corev1.NodeCondition, len(node.Status.Conditions))
+	for _, condition := range node.Status.Conditions {
+		byType[string(condition.Type)] = condition
+	}
+	for _, problem := range d.config.NodeConditions {
+		key := conditionKey(rule.Name, node.Name, problem.Type)
+		condition, exists := byType[problem.Type]
+		unhealthy, expected := condition.Status == corev1.ConditionTrue, "True"
+		if problem.Type == string(corev1.NodeReady) {
+			unhealthy, expected = condition.Status != corev1.ConditionTrue, "not True"
+		}
+		if !trace.check(fmt.Sprintf("status.conditions[type=%s].status", problem.Type), "", condition.Status, expected, exists && unhealthy) {
+			if !trace.readOnly() {
+				d.config.State.Forget(key)
+			}
+			continue
+		}
+
+		var held time.Duration
+		if !condition.LastTransitionTime.IsZero() {
+			held = d.clock.Since(condition.LastTransitionTime.Time)
+		} else {
+			held = d.conditionHeld(key, trace)
+		}
+		if !trace.check(problem.Type+" unhealthy for", "", held.Round(time.Second), fmt.Sprintf(">= %s", problem.For), held >= problem.For) {
+			continue
+		}
+
+		reason := problem.Type
+		if problem.Type == string(corev1.NodeReady) {
+			reason = "NotReady"
+		}
+		description := fmt.Sprintf("%s (Node %s %s is %s for %s", rule.Description, node.Name, problem.Type, condition.Status, formatAge(held))
+		for _, value := range []string{condition.Reason, condition.Message} {
+			if strings.TrimSpace(value) != "" {
+				description += ": " + strings.TrimSpace(value)
+			}
+		}
+		issue := d.nodeProblemIssue(rule, node, problem, description+")")
+		issue.Reason = reason
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// traceNodeConditions traces the node condition rule against a node
+func (d *Detector) traceNodeConditions(ctx context.Context, rule Rule, name string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "Node"
+	node, err := d.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return d.checkNodeConditions(rule, node, trace), nil
+}
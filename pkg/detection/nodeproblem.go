@@ -0,0 +1,213 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NodeProblemRule is the rule reporting the node problems of Node Problem Detector
+const NodeProblemRule = "node-problem"
+
+// CordonNodeAction marks a node unschedulable
+const CordonNodeAction = "cordon-node"
+
+// NodeProblem selects a problem Node Problem Detector reports on nodes, either a
+// permanent problem as a node condition or a temporary problem as a node event,
+// and the severity and actions of its issues
+type NodeProblem struct {
+	// Type is the condition type, e.g. KernelDeadlock, or the event reason, e.g. KernelOops
+	Type     string
+	Severity string
+	// Actions are executed for the node; none only notifies
+	Actions []string
+	// For is how long a condition must be True, or how recent an event must be;
+	// zero reports any True condition or any event
+	For time.Duration
+}
+
+// NodeProblems are the node conditions and events reported by the node problem rule
+type NodeProblems struct {
+	Conditions []NodeProblem
+	Events     []NodeProblem
+}
+
+// configured returns true if any problem is selected
+func (p NodeProblems) configured() bool {
+	return len(p.Conditions) > 0 || len(p.Events) > 0
+}
+
+// detectNodeProblems reports the selected conditions and events of all nodes
+func (d *Detector) detectNodeProblems(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	nodes, err := d.listResources(ctx, "", "Node")
+	if err != nil {
+		return issues, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	events, err := d.nodeEvents(ctx, "")
+	if err != nil {
+		return issues, err
+	}
+
+	for _, obj := range nodes {
+		if node, ok := obj.(*corev1.Node); ok {
+			issues = append(issues, d.decide(ctx, rule, "Node", node, func(trace *Trace) []Issue {
+				return d.checkNodeProblems(rule, node, events[node.Name], trace)
+			})...)
+		}
+	}
+
+	return issues, nil
+}
+
+// nodeEvents returns the events of nodes by node name, of all nodes if name is
+// empty; nothing is listed unless node events are selected
+func (d *Detector) nodeEvents(ctx context.Context, name string) (map[string][]corev1.Event, error) {
+	events := make(map[string][]corev1.Event)
+	if len(d.config.NodeProblems.Events) == 0 {
+		return events, nil
+	}
+
+	selector := "involvedObject.kind=Node"
+	if name != "" {
+		selector += ",involvedObject.name=" + name
+	}
+	err := d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		opts.FieldSelector = selector
+		return d.client.CoreV1().Events("").List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		if event, ok := obj.(*corev1.Event); ok && event.InvolvedObject.Kind == "Node" && (name == "" || event.InvolvedObject.Name == name) {
+			events[event.InvolvedObject.Name] = append(events[event.InvolvedObject.Name], *event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node events: %w", err)
+	}
+	return events, nil
+}
+
+// checkNodeProblems reports the selected conditions of a node that are True and
+// the selected events of the node that are recent enough. How long a condition
+// has been True is taken from its lastTransitionTime, or from when it was first
+// observed True if it is not set.
+func (d *Detector) checkNodeProblems(rule Rule, node *corev1.Node, events []corev1.Event, trace *Trace) []Issue {
+	var issues []Issue
+
+	byType := make(map[string]corev1.NodeCondition, len(node.Status.Conditions))
+	for _, condition := range node.Status.Conditions {
+		byType[string(condition.Type)] = condition
+	}
+	for _, problem := range d.config.NodeProblems.Conditions {
+		condition, exists := byType[problem.Type]
+		if !trace.check(fmt.Sprintf("status.conditions[type=%s].status", problem.Type), "", condition.Status, "True", exists && condition.Status == corev1.ConditionTrue) {
+			continue
+		}
+
+		var held time.Duration
+		if !condition.LastTransitionTime.IsZero() {
+			held = d.clock.Since(condition.LastTransitionTime.Time)
+		} else {
+			held = d.conditionHeld(conditionKey(rule.Name, node.Name, problem.Type), trace)
+		}
+		if !trace.check(problem.Type+" True for", "", held.Round(time.Second), fmt.Sprintf(">= %s", problem.For), held >= problem.For) {
+			continue
+		}
+
+		description := fmt.Sprintf("%s (Node %s is True", rule.Description, problem.Type)
+		for _, value := range []string{condition.Reason, condition.Message} {
+			if strings.TrimSpace(value) != "" {
+				description += ": " + strings.TrimSpace(value)
+			}
+		}
+		issues = append(issues, d.nodeProblemIssue(rule, node, problem, description+")"))
+	}
+
+	for _, problem := range d.config.NodeProblems.Events {
+		var count int32
+		var latest *corev1.Event
+		for i := range events {
+			event := &events[i]
+			if event.Reason != problem.Type {
+				continue
+			}
+			seen := eventTime(event)
+			if problem.For > 0 && d.clock.Since(seen) > problem.For {
+				continue
+			}
+			count += max(event.Count, 1)
+			if latest == nil || seen.After(eventTime(latest)) {
+				latest = event
+			}
+		}
+		window := "any time"
+		if problem.For > 0 {
+			window = fmt.Sprintf("within %s", problem.For)
+		}
+		if !trace.check(fmt.Sprintf("events[reason=%s]", problem.Type), "", count, "seen "+window, latest != nil) {
+			continue
+		}
+
+		description := fmt.Sprintf("%s (Node event %s seen %d times", rule.Description, problem.Type, count)
+		if message := strings.TrimSpace(latest.Message); message != "" {
+			description += ": " + message
+		}
+		issues = append(issues, d.nodeProblemIssue(rule, node, problem, description+")"))
+	}
+	return issues
+}
+
+// nodeProblemIssue returns the issue of a problem of a node, with the severity
+// and actions of the problem
+func (d *Detector) nodeProblemIssue(rule Rule, node *corev1.Node, problem NodeProblem, description string) Issue {
+	severity := problem.Severity
+	if severity == "" {
+		severity = rule.Severity
+	}
+	return Issue{
+		RuleName:    rule.Name,
+		Description: description,
+		Severity:    severity,
+		Resource:    node.DeepCopy(),
+		Name:        node.Name,
+		Kind:        "Node",
+		Reason:      problem.Type,
+		Actions:     problem.Actions,
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}
+}
+
+// traceNodeProblems traces the node problem rule against a node
+func (d *Detector) traceNodeProblems(ctx context.Context, rule Rule, name string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "Node"
+	node, err := d.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	events, err := d.nodeEvents(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return d.checkNodeProblems(rule, node, events[name], trace), nil
+}
+
+// eventTime returns when an event was last seen
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,65 @@
+package detection
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Well-known labels and annotations identifying the owner of a workload
+const (
+	// AnnotationTeam names the owning team and takes precedence over labels
+	AnnotationTeam = "kubeguardian.io/team"
+	// AnnotationOwner names a contact, e.g. a Slack handle or an email address
+	AnnotationOwner = "kubeguardian.io/owner"
+	// LabelTeam is the conventional team label
+	LabelTeam = "team"
+	// AnnotationOwnerShort is the conventional owner annotation
+	AnnotationOwnerShort = "owner"
+	// LabelPartOf names the application a workload belongs to; used as the team
+	// if no team is set
+	LabelPartOf = "app.kubernetes.io/part-of"
+)
+
+// Owner identifies who is responsible for an affected resource
+type Owner struct {
+	Team    string `yaml:"team,omitempty" json:"team,omitempty"`
+	Contact string `yaml:"contact,omitempty" json:"contact,omitempty"`
+}
+
+// IsZero returns true if no owner is known
+func (o Owner) IsZero() bool {
+	return o.Team == "" && o.Contact == ""
+}
+
+// OwnerOf reads the owner of a resource from its well-known labels and annotations
+func OwnerOf(obj metav1.Object) Owner {
+	labels := obj.GetLabels()
+	annotations := obj.GetAnnotations()
+	return Owner{
+		Team:    firstNonEmpty(annotations[AnnotationTeam], labels[LabelTeam], labels[LabelPartOf]),
+		Contact: firstNonEmpty(annotations[AnnotationOwner], annotations[AnnotationOwnerShort]),
+	}
+}
+
+// enrichOwners sets the owner of issues whose resource carries owner metadata
+func enrichOwners(issues []Issue) {
+	for i := range issues {
+		if issues[i].Resource == nil || !issues[i].Owner.IsZero() {
+			continue
+		}
+		obj, err := meta.Accessor(issues[i].Resource)
+		if err != nil {
+			continue
+		}
+		issues[i].Owner = OwnerOf(obj)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+// SetPageSize sets the number of objects listed per request by the rules that
+// list all pods or deployments of the cluster; zero lists them in one request
+func (d *Detector) SetPageSize(size int) {
+	d.pageSize.Store(int64(size))
+}
+
+// PageSize returns the number of objects listed per request
+func (d *Detector) PageSize() int {
+	return int(d.pageSize.Load())
+}
+
+// listPods lists the pods of all namespaces in scope from the informer cache, or in pages
+func (d *Detector) listPods(ctx context.Context) (*corev1.PodList, error) {
+	if informed := d.listers(); informed != nil {
+		return informed.cachedPods(d.InScope)
+	}
+	list := &corev1.PodList{}
+	err := d.eachNamespacedListItem(ctx, func(ctx context.Context, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+		return d.client.CoreV1().Pods(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		list.Items = append(list.Items, *obj.(*corev1.Pod))
+		return nil
+	})
+	return list, err
+}
+
+// listDeployments lists the deployments of all namespaces in scope from the
+// informer cache, or in pages
+func (d *Detector) listDeployments(ctx context.Context) (*appsv1.DeploymentList, error) {
+	if informed := d.listers(); informed != nil {
+		return informed.cachedDeployments(d.InScope)
+	}
+	list := &appsv1.DeploymentList{}
+	err := d.eachNamespacedListItem(ctx, func(ctx context.Context, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+		return d.client.AppsV1().Deployments(namespace).List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		list.Items = append(list.Items, *obj.(*appsv1.Deployment))
+		return nil
+	})
+	return list, err
+}
+
+// listResources lists the pods, deployments, nodes, services or persistent volume
+// claims of the cluster in scope, or the resources of a kind in a group version
+// with the dynamic client
+func (d *Detector) listResources(ctx context.Context, apiVersion, kind string) ([]runtime.Object, error) {
+	if apiVersion != "" {
+		return d.listDynamic(ctx, apiVersion, kind)
+	}
+	if informed := d.listers(); informed != nil && (kind == KindPod || kind == KindDeployment) {
+		return informed.cachedObjects(kind, d.InScope)
+	}
+
+	var objects []runtime.Object
+	collect := func(obj runtime.Object) error {
+		objects = append(objects, obj)
+		return nil
+	}
+	if kind == "Node" {
+		err := d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return d.client.CoreV1().Nodes().List(ctx, opts)
+		}, collect)
+		return objects, err
+	}
+	err := d.eachNamespacedListItem(ctx, func(ctx context.Context, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+		switch kind {
+		case "Pod":
+			return d.client.CoreV1().Pods(namespace).List(ctx, opts)
+		case "Deployment":
+			return d.client.AppsV1().Deployments(namespace).List(ctx, opts)
+		case "Service":
+			return d.client.CoreV1().Services(namespace).List(ctx, opts)
+		case "PersistentVolumeClaim":
+			return d.client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+		default:
+			return nil, fmt.Errorf("unsupported resource %q", kind)
+		}
+	}, collect)
+	return objects, err
+}
+
+// eachListItem calls fn for the objects of a paged list; an expired continue
+// token falls back to a full list
+func (d *Detector) eachListItem(ctx context.Context, page pager.ListPageFunc, fn func(runtime.Object) error) error {
+	p := pager.New(page)
+	p.PageSize = d.pageSize.Load()
+	return p.EachListItem(ctx, metav1.ListOptions{}, fn)
+}
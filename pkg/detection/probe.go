@@ -0,0 +1,203 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Types of probes
+const (
+	// ProbeDNS resolves a host name
+	ProbeDNS = "dns"
+	// ProbeTCP opens a TCP connection to host:port
+	ProbeTCP = "tcp"
+	// ProbeHTTP requests a URL and expects a status below 400
+	ProbeHTTP = "http"
+)
+
+// ProbeTypes are the supported types of probes
+var ProbeTypes = []string{ProbeDNS, ProbeTCP, ProbeHTTP}
+
+// DefaultProbeTimeout bounds a probe without a timeout
+const DefaultProbeTimeout = 5 * time.Second
+
+// ProbeCheck actively probes a target from within the cluster, e.g. whether the
+// cluster DNS resolves kubernetes.default.svc or a key Service accepts
+// connections. An issue is reported once the probe has failed on every
+// evaluation for the duration; a successful probe starts over.
+type ProbeCheck struct {
+	Type     string           `yaml:"type"`
+	Target   string           `yaml:"target"` // Host name, host:port or URL
+	Timeout  *metav1.Duration `yaml:"timeout"`
+	Duration *metav1.Duration `yaml:"duration"`
+}
+
+// timeout returns how long a single probe may take
+func (c *ProbeCheck) timeout() time.Duration {
+	if c.Timeout == nil || c.Timeout.Duration <= 0 {
+		return DefaultProbeTimeout
+	}
+	return c.Timeout.Duration
+}
+
+// duration returns how long the probe must fail to report an issue
+func (c *ProbeCheck) duration() time.Duration {
+	if c.Duration == nil {
+		return 0
+	}
+	return c.Duration.Duration
+}
+
+// validate checks that the target suits the type of the probe
+func (c *ProbeCheck) validate() error {
+	if !slices.Contains(ProbeTypes, c.Type) {
+		return fmt.Errorf("unsupported probe type %q, must be one of %v", c.Type, ProbeTypes)
+	}
+	if c.Target == "" {
+		return fmt.Errorf("probe without a target")
+	}
+	switch c.Type {
+	case ProbeTCP:
+		if _, _, err := net.SplitHostPort(c.Target); err != nil {
+			return fmt.Errorf("tcp target %q must be host:port: %w", c.Target, err)
+		}
+	case ProbeHTTP:
+		u, err := url.Parse(c.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http target %q must be an http or https URL", c.Target)
+		}
+	}
+	return nil
+}
+
+// host returns the host name the probe connects to
+func (c *ProbeCheck) host() string {
+	switch c.Type {
+	case ProbeTCP:
+		host, _, _ := net.SplitHostPort(c.Target)
+		return host
+	case ProbeHTTP:
+		if u, err := url.Parse(c.Target); err == nil {
+			return u.Hostname()
+		}
+	}
+	return c.Target
+}
+
+// Prober runs the probes of probe rules
+type Prober interface {
+	// Probe returns an error if the target of the check cannot be reached
+	Probe(ctx context.Context, check ProbeCheck) error
+}
+
+// networkProber probes targets over the network of the KubeGuardian pod
+type networkProber struct{}
+
+// Probe resolves, connects to or requests the target of the check
+func (networkProber) Probe(ctx context.Context, check ProbeCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout())
+	defer cancel()
+
+	switch check.Type {
+	case ProbeDNS:
+		addresses, err := net.DefaultResolver.LookupHost(ctx, check.Target)
+		if err != nil {
+			return err
+		}
+		if len(addresses) == 0 {
+			return errors.New("no addresses")
+		}
+		return nil
+	case ProbeTCP:
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", check.Target)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case ProbeHTTP:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.Target, nil)
+		if err != nil {
+			return err
+		}
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported probe type %q", check.Type)
+	}
+}
+
+// detectProbe runs the probe of a probe rule
+func (d *Detector) detectProbe(ctx context.Context, rule Rule) ([]Issue, error) {
+	return d.checkProbe(ctx, rule, nil), nil
+}
+
+// checkProbe runs the probe of a rule and reports an issue if it has failed for
+// the duration of the check. Traces run the probe but neither start nor reset
+// the failure duration.
+func (d *Detector) checkProbe(ctx context.Context, rule Rule, trace *Trace) []Issue {
+	check := rule.Probe
+	key := conditionKey(rule.Name, "probe")
+
+	err := d.config.Prober.Probe(ctx, *check)
+	result := "ok"
+	if err != nil {
+		result = err.Error()
+	}
+	if !trace.check(fmt.Sprintf("%s probe of %s", check.Type, check.Target), "", result, "failure", err != nil) {
+		if !trace.readOnly() {
+			d.config.State.Forget(key)
+		}
+		return nil
+	}
+
+	failing := d.conditionHeld(key, trace)
+	if !trace.check("failing for", "", failing.Round(time.Second), fmt.Sprintf(">= %s", check.duration()), failing >= check.duration()) {
+		return nil
+	}
+
+	namespace, name, kind := probeSubject(check.host())
+	description := fmt.Sprintf("%s (%s probe of %s failed", rule.Description, strings.ToUpper(check.Type), check.Target)
+	if failing > 0 {
+		description += " for " + formatAge(failing)
+	}
+	return []Issue{{
+		RuleName:    rule.Name,
+		Description: fmt.Sprintf("%s: %v)", description, err),
+		Severity:    rule.Severity,
+		Namespace:   namespace,
+		Name:        name,
+		Kind:        kind,
+		Reason:      "ProbeFailed",
+		Actions:     rule.Actions,
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}}
+}
+
+// probeSubject returns the Service a probed host name refers to, e.g.
+// payments/api for api.payments.svc.cluster.local, or the host itself
+func probeSubject(host string) (namespace, name, kind string) {
+	parts := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(parts) >= 3 && parts[2] == "svc" {
+		return parts[1], parts[0], "Service"
+	}
+	return "", host, "Host"
+}
@@ -0,0 +1,271 @@
+package detection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrNoPrometheus is returned when a query rule has no endpoint and no Prometheus server is configured
+var ErrNoPrometheus = errors.New("no Prometheus server configured")
+
+// Threshold operators of query conditions
+const (
+	OperatorGreaterThan = "greater_than"
+	OperatorLessThan    = "less_than"
+	OperatorEquals      = "equals"
+)
+
+// QueryOperators are the operators query conditions compare sample values with
+var QueryOperators = []string{OperatorGreaterThan, OperatorLessThan, OperatorEquals}
+
+// QueryResources are the kinds of resources query rules report issues for
+var QueryResources = []string{"Deployment", "Pod"}
+
+// Sample is a series of an instant vector returned by a query
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// PrometheusClient evaluates instant queries with the Prometheus HTTP API
+type PrometheusClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewPrometheusClient creates a client of the Prometheus server at baseURL; an
+// empty baseURL requires every query rule to set its own endpoint
+func NewPrometheusClient(baseURL, token string, timeout time.Duration) *PrometheusClient {
+	return &PrometheusClient{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// prometheusResponse is the subset of a query response that is used
+type prometheusResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Query evaluates an instant query at the given time against endpoint, or the
+// configured server if endpoint is empty. The query must return a vector.
+func (c *PrometheusClient) Query(ctx context.Context, endpoint, query string, at time.Time) ([]Sample, error) {
+	base := strings.TrimSuffix(endpoint, "/")
+	if base == "" {
+		base = c.url
+	}
+	if base == "" {
+		return nil, ErrNoPrometheus
+	}
+
+	params := url.Values{"query": {query}, "time": {strconv.FormatFloat(float64(at.UnixMilli())/1000, 'f', 3, 64)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/v1/query?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode query response (status %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query failed with status %d: %s: %s", resp.StatusCode, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("query returned a %s, expected a vector", result.Data.ResultType)
+	}
+
+	samples := make([]Sample, 0, len(result.Data.Result))
+	for _, series := range result.Data.Result {
+		raw, ok := series.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample value %v", series.Value[1])
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample value %q: %w", raw, err)
+		}
+		samples = append(samples, Sample{Labels: series.Metric, Value: value})
+	}
+	return samples, nil
+}
+
+// queryCondition returns the PromQL condition of a rule, if any
+func (r Rule) queryCondition() (RuleCondition, bool) {
+	for _, condition := range r.Conditions {
+		if condition.Query != "" {
+			return condition, true
+		}
+	}
+	return RuleCondition{}, false
+}
+
+// nameLabel returns the label of the series holding the name of the resource
+func (c RuleCondition) nameLabel() string {
+	if c.NameLabel != "" {
+		return c.NameLabel
+	}
+	return strings.ToLower(c.Resource)
+}
+
+// compareThreshold compares a sample value with the threshold of a query condition
+func compareThreshold(value float64, operator string, threshold float64) (bool, error) {
+	switch operator {
+	case OperatorGreaterThan:
+		return value > threshold, nil
+	case OperatorLessThan:
+		return value < threshold, nil
+	case OperatorEquals:
+		return value == threshold, nil
+	default:
+		return false, fmt.Errorf("unsupported query operator %q", operator)
+	}
+}
+
+// thresholdValue converts the value of a query condition to a number
+func thresholdValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("invalid threshold %v", value)
+	}
+}
+
+// detectQuery evaluates a rule with a PromQL condition. Each series of the result
+// is mapped to a resource by its namespace label and the name label of the
+// condition; series crossing the threshold for the condition's duration report
+// an issue for the resource.
+func (d *Detector) detectQuery(ctx context.Context, rule Rule) ([]Issue, error) {
+	return d.checkQuery(ctx, rule, "", "", nil)
+}
+
+// checkQuery evaluates a query rule, limited to a single resource if name is set
+func (d *Detector) checkQuery(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	logger := log.FromContext(ctx)
+	var issues []Issue
+
+	condition, _ := rule.queryCondition()
+	threshold, err := thresholdValue(condition.Value)
+	if err != nil {
+		return nil, err
+	}
+	if d.config.Prometheus == nil {
+		return nil, ErrNoPrometheus
+	}
+	samples, err := d.config.Prometheus.Query(ctx, condition.Endpoint, condition.Query, d.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	nameLabel := condition.nameLabel()
+	found := false
+	for _, sample := range samples {
+		sampleNamespace, sampleName := sample.Labels["namespace"], sample.Labels[nameLabel]
+		if sampleNamespace == "" || sampleName == "" {
+			logger.V(1).Info("Ignoring series without namespace and name labels", "rule", rule.Name, "nameLabel", nameLabel, "labels", sample.Labels)
+			continue
+		}
+		if name != "" && (sampleNamespace != namespace || sampleName != name) {
+			continue
+		}
+		found = true
+
+		matched, err := compareThreshold(sample.Value, condition.Operator, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if !trace.check("query value", "", sample.Value, fmt.Sprintf("%s %g", condition.Operator, threshold), matched) {
+			continue
+		}
+
+		// Check if the threshold has been crossed for the required duration
+		var duration time.Duration
+		if condition.Duration != nil {
+			duration = condition.Duration.Duration
+		}
+		held := d.conditionHeld(conditionKey(rule.Name, sampleNamespace, sampleName), trace)
+		if !trace.check("threshold crossed for", "", held, fmt.Sprintf(">= %s", duration), held >= duration) {
+			continue
+		}
+
+		resource, err := d.getResource(ctx, condition.Resource, sampleNamespace, sampleName)
+		if apierrors.IsNotFound(err) {
+			trace.check(strings.ToLower(condition.Resource)+" exists", "", false, "true", false)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (value: %g, threshold: %s %g)", rule.Description, sample.Value, condition.Operator, threshold),
+			Severity:    rule.Severity,
+			Resource:    resource,
+			Namespace:   sampleNamespace,
+			Name:        sampleName,
+			Kind:        condition.Resource,
+			Reason:      "QueryThreshold",
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		})
+	}
+
+	if name != "" && !found {
+		trace.check("series returned by query", "", false, "true", false)
+	}
+	return issues, nil
+}
+
+// getResource gets a pod, deployment or node by name; nodes ignore the namespace
+func (d *Detector) getResource(ctx context.Context, kind, namespace, name string) (runtime.Object, error) {
+	switch kind {
+	case "Deployment":
+		return d.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Pod":
+		return d.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	case "Node":
+		return d.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, fmt.Errorf("unsupported resource %q", kind)
+	}
+}
@@ -0,0 +1,139 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceQuotaRule is the rule reporting ResourceQuotas whose usage of a
+// resource approaches its hard limit
+const ResourceQuotaRule = "resource-quota-exhaustion"
+
+// Reasons of resource quota issues
+const (
+	reasonQuotaNearlyExhausted = "QuotaNearlyExhausted"
+	reasonQuotaExhausted       = "QuotaExhausted"
+)
+
+// ResourceQuotaConfig contains resource quota detection settings for a namespace
+type ResourceQuotaConfig struct {
+	// ThresholdPercent is the used/hard ratio reported; zero uses the global threshold
+	ThresholdPercent float64 `yaml:"thresholdPercent"`
+}
+
+// quotaUsage is the usage of a resource of a ResourceQuota
+type quotaUsage struct {
+	resource corev1.ResourceName
+	used     string
+	hard     string
+	percent  float64
+}
+
+// String formats a usage as e.g. "requests.cpu 9500m/10 (95%)"
+func (u quotaUsage) String() string {
+	return fmt.Sprintf("%s %s/%s (%.0f%%)", u.resource, u.used, u.hard, u.percent)
+}
+
+// detectResourceQuotas reports the ResourceQuotas of all namespaces whose usage
+// of a resource reached the threshold of their namespace
+func (d *Detector) detectResourceQuotas(ctx context.Context, rule Rule) ([]Issue, error) {
+	quotas, err := d.client.CoreV1().ResourceQuotas("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resourcequotas: %w", err)
+	}
+
+	var issues []Issue
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		issues = append(issues, d.decide(ctx, rule, "ResourceQuota", quota, func(trace *Trace) []Issue {
+			return d.checkResourceQuota(rule, quota, trace)
+		})...)
+	}
+	return issues, nil
+}
+
+// quotaThreshold returns the used/hard percentage reported in a namespace
+func (d *Detector) quotaThreshold(namespace string) float64 {
+	if threshold := d.GetNamespaceConfig(namespace).ResourceQuota.ThresholdPercent; threshold > 0 {
+		return threshold
+	}
+	return d.config.ResourceQuotaThresholdPercent
+}
+
+// checkResourceQuota evaluates the resource quota rule against a ResourceQuota.
+// One issue lists all resources at or above the threshold, the fullest first;
+// a low or medium severity is raised to high once a resource is used up, since
+// new pods requesting it are then rejected.
+func (d *Detector) checkResourceQuota(rule Rule, quota *corev1.ResourceQuota, trace *Trace) []Issue {
+	threshold := d.quotaThreshold(quota.Namespace)
+
+	var usages []quotaUsage
+	exhausted := false
+	for _, resource := range slices.Sorted(maps.Keys(quota.Status.Hard)) {
+		hard := quota.Status.Hard[resource]
+		used, exists := quota.Status.Used[resource]
+		// A hard limit of zero forbids the resource rather than bounding it
+		if !exists || hard.IsZero() {
+			continue
+		}
+		percent := used.AsApproximateFloat64() / hard.AsApproximateFloat64() * 100
+		if !trace.check(fmt.Sprintf("status.used[%s]", resource), "", fmt.Sprintf("%.0f%%", percent), fmt.Sprintf(">= %.0f%%", threshold), percent >= threshold) {
+			continue
+		}
+		usages = append(usages, quotaUsage{resource: resource, used: used.String(), hard: hard.String(), percent: percent})
+		if used.Cmp(hard) >= 0 {
+			exhausted = true
+		}
+	}
+	if len(usages) == 0 {
+		return nil
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].percent != usages[j].percent {
+			return usages[i].percent > usages[j].percent
+		}
+		return usages[i].resource < usages[j].resource
+	})
+
+	parts := make([]string, 0, len(usages))
+	for _, usage := range usages {
+		parts = append(parts, usage.String())
+	}
+	issue := Issue{
+		RuleName:    rule.Name,
+		Description: fmt.Sprintf("%s: quota %s uses %s, threshold %.0f%%", rule.Description, quota.Name, strings.Join(parts, ", "), threshold),
+		Severity:    rule.Severity,
+		Resource:    quota.DeepCopy(),
+		Namespace:   quota.Namespace,
+		Name:        quota.Name,
+		Kind:        "ResourceQuota",
+		Reason:      reasonQuotaNearlyExhausted,
+		Actions:     rule.Actions,
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}
+	if exhausted {
+		issue.Reason = reasonQuotaExhausted
+		if issue.Severity == "low" || issue.Severity == "medium" {
+			issue.Severity = "high"
+		}
+	}
+	return []Issue{issue}
+}
+
+// traceResourceQuota traces the resource quota rule against a ResourceQuota
+func (d *Detector) traceResourceQuota(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "ResourceQuota"
+	quota, err := d.client.CoreV1().ResourceQuotas(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return d.checkResourceQuota(rule, quota, trace), nil
+}
@@ -0,0 +1,195 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// imagePullReasons are the waiting reasons of containers whose image cannot be pulled
+var imagePullReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+	"InvalidImageName": true,
+}
+
+// detectStuckRollouts detects rollouts that are paused or not progressing
+func (d *Detector) detectStuckRollouts(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	deployments, err := d.listDeployments(ctx)
+	if err != nil {
+		return issues, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindDeployment, deployment, func(trace *Trace) []Issue {
+			return d.checkStuckRollout(ctx, rule, deployment, trace)
+		})...)
+	}
+
+	return issues, nil
+}
+
+// checkStuckRollout evaluates the stuck rollout rule against a deployment. A paused
+// rollout is only notified so its owner can resume it; a rollout that stopped
+// progressing gets the actions of the rule, with the failure of its new
+// ReplicaSet as the issue reason.
+func (d *Detector) checkStuckRollout(ctx context.Context, rule Rule, deployment *appsv1.Deployment, trace *Trace) []Issue {
+	var issues []Issue
+
+	nsConfig := d.GetNamespaceConfig(deployment.Namespace)
+	if !trace.check("namespace deployment failure detection enabled", "", nsConfig.Deployment.Enabled, "true", nsConfig.Deployment.Enabled) {
+		return issues
+	}
+
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	progress := fmt.Sprintf("%d/%d replicas updated", status.UpdatedReplicas, desired)
+	inProgress := status.ObservedGeneration < deployment.Generation || status.UpdatedReplicas < desired ||
+		status.Replicas > status.UpdatedReplicas || status.AvailableReplicas < status.UpdatedReplicas
+	if !trace.check("rollout in progress", "", progress, "rollout not complete", inProgress) {
+		return issues
+	}
+
+	replicaSets, err := d.client.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		trace.check("list replicasets", "", err, "no error", false)
+		return issues
+	}
+	newRS := newReplicaSet(deployment, replicaSets.Items)
+
+	// The rollout started when its ReplicaSet was created, or when the deployment
+	// was paused if the new ReplicaSet is not created yet
+	var started time.Time
+	if change := latestChange(deployment.Name, replicaSets.Items); change != nil && newRS != nil {
+		started = change.ChangedAt
+	} else if progressing := deploymentCondition(deployment, appsv1.DeploymentProgressing); progressing != nil {
+		started = progressing.LastUpdateTime.Time
+	}
+	if !trace.check("rollout started", "", started, "known", !started.IsZero()) {
+		return issues
+	}
+	age := d.clock.Since(started).Round(time.Second)
+	if !trace.check("rollout age", "", age, fmt.Sprintf(">= %s", d.config.StuckRolloutAfter), age >= d.config.StuckRolloutAfter) {
+		return issues
+	}
+
+	issue := Issue{
+		RuleName:   rule.Name,
+		Severity:   rule.Severity,
+		Resource:   deployment.DeepCopyObject(),
+		Namespace:  deployment.Namespace,
+		Name:       deployment.Name,
+		Kind:       "Deployment",
+		Actions:    rule.Actions,
+		Labels:     rule.Labels,
+		DetectedAt: d.clock.Now(),
+	}
+
+	if deployment.Spec.Paused {
+		issue.Reason = "RolloutPaused"
+		issue.Severity = "low"
+		issue.Actions = []string{}
+		issue.Description = fmt.Sprintf("%s: rollout paused for %s (%s); resume it with kubectl rollout resume deployment/%s",
+			rule.Description, formatAge(age), progress, deployment.Name)
+	} else {
+		var detail string
+		issue.Reason, detail = d.stuckRolloutReason(ctx, deployment, newRS)
+		issue.Description = fmt.Sprintf("%s: rollout not progressing for %s (%s): %s", rule.Description, formatAge(age), progress, detail)
+	}
+
+	return append(issues, issue)
+}
+
+// stuckRolloutReason explains why the new ReplicaSet of a rollout is not progressing
+func (d *Detector) stuckRolloutReason(ctx context.Context, deployment *appsv1.Deployment, newRS *appsv1.ReplicaSet) (string, string) {
+	// Pods rejected by a ResourceQuota are never created, so the failure is only
+	// reported in the ReplicaFailure condition
+	var failures []string
+	if condition := deploymentCondition(deployment, appsv1.DeploymentReplicaFailure); condition != nil && condition.Status == corev1.ConditionTrue {
+		failures = append(failures, condition.Message)
+	}
+	if newRS != nil {
+		for _, condition := range newRS.Status.Conditions {
+			if condition.Type == appsv1.ReplicaSetReplicaFailure && condition.Status == corev1.ConditionTrue {
+				failures = append(failures, condition.Message)
+			}
+		}
+	}
+	for _, message := range failures {
+		if strings.Contains(message, "exceeded quota") {
+			return "InsufficientQuota", message
+		}
+	}
+	if len(failures) > 0 {
+		return "ReplicaFailure", failures[0]
+	}
+	if newRS == nil {
+		return "RolloutStalled", "new replicaset not created"
+	}
+
+	opts := metav1.ListOptions{}
+	if selector, err := metav1.LabelSelectorAsSelector(newRS.Spec.Selector); err == nil {
+		opts.LabelSelector = selector.String()
+	}
+	pods, err := d.client.CoreV1().Pods(deployment.Namespace).List(ctx, opts)
+	if err != nil {
+		return "RolloutStalled", fmt.Sprintf("failed to list pods of replicaset %s: %v", newRS.Name, err)
+	}
+	notReady := ""
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, newRS) {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			reason := waitingReason(status)
+			switch {
+			case imagePullReasons[reason]:
+				return "ImagePullFailure", fmt.Sprintf("pod %s of replicaset %s cannot pull image %s (%s)", pod.Name, newRS.Name, status.Image, reason)
+			case reason == "CrashLoopBackOff":
+				return "CrashLoopBackOff", fmt.Sprintf("container %s of pod %s of replicaset %s is crash looping", status.Name, pod.Name, newRS.Name)
+			case status.State.Running != nil && !status.Ready && notReady == "":
+				notReady = fmt.Sprintf("container %s of pod %s of replicaset %s is running but not ready", status.Name, pod.Name, newRS.Name)
+			}
+		}
+	}
+	if notReady != "" {
+		return "FailingProbes", notReady
+	}
+	return "RolloutStalled", fmt.Sprintf("replicaset %s has %d/%d ready replicas", newRS.Name, newRS.Status.ReadyReplicas, newRS.Status.Replicas)
+}
+
+// newReplicaSet returns the ReplicaSet of the current revision of a deployment
+func newReplicaSet(deployment *appsv1.Deployment, replicaSets []appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	revision, exists := deployment.Annotations[annotationRevision]
+	if !exists {
+		return nil
+	}
+	for i := range replicaSets {
+		if metav1.IsControlledBy(&replicaSets[i], deployment) && replicaSets[i].Annotations[annotationRevision] == revision {
+			return &replicaSets[i]
+		}
+	}
+	return nil
+}
+
+// deploymentCondition returns a condition of a deployment, or nil
+func deploymentCondition(deployment *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == conditionType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -3,14 +3,24 @@ package detection
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
 )
 
 // Rule represents a detection rule
@@ -22,16 +32,45 @@ type Rule struct {
 	Actions     []string          `yaml:"actions"`
 	Severity    string            `yaml:"severity"`
 	Labels      map[string]string `yaml:"labels"`
+	// Schedule names a configured time window; the rule is only evaluated while it is open
+	Schedule string `yaml:"schedule"`
+	// Runbook is a URL template linked from issues of the rule; see RenderRunbook
+	Runbook string `yaml:"runbook"`
+	// Containers select the containers of pod rules and the actions for their
+	// issues; the first matching container rule applies
+	Containers []ContainerRule `yaml:"-"`
+	// StatusConditions makes the rule check the status conditions of the selected
+	// resources instead of evaluating Conditions
+	StatusConditions *StatusConditionsCheck `yaml:"statusConditions"`
+	// Probe makes the rule actively probe a target instead of evaluating Conditions
+	Probe *ProbeCheck `yaml:"probe"`
+	// Requires lists the cluster capabilities the rule needs, e.g. the CRDs of an
+	// optional integration; rules are disabled at startup when one is missing
+	Requires []string `yaml:"requires"`
+	// Metadata tells who owns the rule, where it is defined and when it changed
+	Metadata RuleMetadata `yaml:"metadata"`
 }
 
 // RuleCondition represents a condition in a rule
 type RuleCondition struct {
-	Resource  string                 `yaml:"resource"`
-	Field     string                 `yaml:"field"`
+	Resource string `yaml:"resource"`
+	// APIVersion is the group version of a Resource not read with the typed
+	// clients, e.g. cert-manager.io/v1 for Certificate
+	APIVersion string `yaml:"apiVersion"`
+	Field      string `yaml:"field"`
+	// Expr is a CEL expression over the resource, matching when it returns true;
+	// it is used instead of Field, Operator and Value
+	Expr      string                 `yaml:"expr"`
 	Operator  string                 `yaml:"operator"`
 	Value     interface{}            `yaml:"value"`
 	Duration  *metav1.Duration       `yaml:"duration"`
 	MatchExpr map[string]interface{} `yaml:"matchExpr"`
+	// Query is a PromQL query whose series are compared against Value with
+	// Operator; each series is mapped to a Resource by its namespace label and
+	// NameLabel, which defaults to the lowercase kind (e.g. deployment)
+	Query     string `yaml:"query"`
+	Endpoint  string `yaml:"endpoint"` // Prometheus server of the query, overrides the configured one
+	NameLabel string `yaml:"nameLabel"`
 }
 
 // Issue represents a detected issue
@@ -43,28 +82,128 @@ type Issue struct {
 	Namespace   string            `yaml:"namespace"`
 	Name        string            `yaml:"name"`
 	Kind        string            `yaml:"kind"`
+	Reason      string            `yaml:"reason"`
 	Actions     []string          `yaml:"actions"`
 	Labels      map[string]string `yaml:"labels"`
 	DetectedAt  time.Time         `yaml:"detectedAt"`
+	// Container is the container of a pod the issue was detected in, if any
+	Container string `yaml:"container,omitempty"`
+	// Runbook is the rendered runbook URL of the rule, if any
+	Runbook string `yaml:"runbook,omitempty"`
+	// Owner is read from the well-known owner labels and annotations of the resource
+	Owner Owner `yaml:"owner"`
+	// Change is the recent rollout of the owning Deployment, if any
+	Change *Change `yaml:"change,omitempty"`
+	// RuleMetadata is the owner, source and last change of the rule that fired
+	RuleMetadata RuleMetadata `yaml:"ruleMetadata"`
+	// Diagnoses are the known failure signatures matched by the issue
+	Diagnoses []analysis.Diagnosis `yaml:"diagnoses,omitempty"`
+	// Analysis is the root-cause hypothesis of the aiAnalysis feature, if any
+	Analysis *analysis.Result `yaml:"analysis,omitempty"`
+	// EscalatedFrom is the severity of the rule if the issue was escalated to
+	// Severity for staying unresolved
+	EscalatedFrom string `yaml:"escalatedFrom,omitempty"`
 }
 
 // Detector represents the detection engine
 type Detector struct {
-	client kubernetes.Interface
-	rules  []Rule
-	config DetectionConfig
+	client   kubernetes.Interface
+	rules    []Rule
+	config   DetectionConfig
+	clock    clock.PassiveClock
+	pageSize atomic.Int64 // Objects per list request, zero is unpaged
+
+	mu       sync.RWMutex
+	silenced map[string]bool   // Rules disabled at runtime on top of their configuration
+	usage    *usageSource      // Pod metrics of the CPU and memory rules, nil uses heuristics
+	dynamic  dynamic.Interface // Client of the resources of field rules with an apiVersion
+	informed *informedListers  // Caches of pods and deployments, nil lists them from the API
+
+	decisions *decisionLog    // Outcomes of the rules per resource, nil while disabled
+	restarts  *restartHistory // Restart counts of containers over time, for the pod flapping rule
+	scope     *namespaceScope // Namespaces detection runs in, nil is all of them
 }
 
 // DetectionConfig contains detection configuration
 type DetectionConfig struct {
-	RulesFile                 string                     `yaml:"rulesFile"`
-	EvaluationInterval        time.Duration              `yaml:"evaluationInterval"`
-	CrashLoopThreshold        int                        `yaml:"crashLoopThreshold"`
-	FailedDeploymentThreshold int                        `yaml:"failedDeploymentThreshold"`
-	CPUThresholdPercent       float64                    `yaml:"cpuThresholdPercent"`
-	MemoryThresholdPercent    float64                    `yaml:"memoryThresholdPercent"`
-	OOMKillThreshold          int                        `yaml:"oomKillThreshold"`
-	Namespaces                map[string]NamespaceConfig `yaml:"namespaces"`
+	RulesFile                 string                      `yaml:"rulesFile"`
+	EvaluationInterval        time.Duration               `yaml:"evaluationInterval"`
+	CrashLoopThreshold        int                         `yaml:"crashLoopThreshold"`
+	FailedDeploymentThreshold int                         `yaml:"failedDeploymentThreshold"`
+	CPUThresholdPercent       float64                     `yaml:"cpuThresholdPercent"`
+	MemoryThresholdPercent    float64                     `yaml:"memoryThresholdPercent"`
+	OOMKillThreshold          int                         `yaml:"oomKillThreshold"`
+	Namespaces                map[string]NamespaceConfig  `yaml:"namespaces"`
+	Schedules                 map[string]*schedule.Window `yaml:"-"`
+	// State tracks first-seen times of duration-based conditions, in memory if nil
+	State *StateStore `yaml:"-"`
+	// Overrides bounds workload annotation overrides; nil ignores the annotations
+	Overrides *overrides.Bounds `yaml:"-"`
+	// ChangeWindow is how recent a rollout must be to be correlated with an issue;
+	// zero disables change correlation
+	ChangeWindow time.Duration `yaml:"-"`
+	// Runbooks sets the runbook URL templates of rules by rule name
+	Runbooks map[string]string `yaml:"-"`
+	// RuleMetadata sets the owner and last modification time of rules by rule name
+	RuleMetadata map[string]RuleMetadata `yaml:"-"`
+	// Containers are the container rules, assigned to the rules they apply to
+	Containers []ContainerRule `yaml:"-"`
+	// EphemeralContainerMaxAge is how long an ephemeral debug container may run
+	// before it is reported; zero disables the rule
+	EphemeralContainerMaxAge time.Duration `yaml:"-"`
+	// StuckRolloutAfter is how long a rollout may be paused or not progressing
+	// before it is reported; zero disables the rule
+	StuckRolloutAfter time.Duration `yaml:"-"`
+	// StuckTerminatingAfter is how long a pod may keep terminating beyond its
+	// grace period before it is reported; zero disables the rule
+	StuckTerminatingAfter time.Duration `yaml:"-"`
+	// SchedulingConflictAfter is how long pods may be unschedulable because of
+	// their anti-affinity or topology spread constraints before their workload
+	// is reported; zero disables the rule
+	SchedulingConflictAfter time.Duration `yaml:"-"`
+	// ServiceWithoutEndpointsAfter is how long a Service with a selector may
+	// have no ready endpoints before it is reported; zero disables the rule
+	ServiceWithoutEndpointsAfter time.Duration `yaml:"-"`
+	// ResourceQuotaThresholdPercent is the used/hard ratio of a ResourceQuota
+	// resource reported unless a namespace overrides it; zero disables the rule
+	ResourceQuotaThresholdPercent float64 `yaml:"-"`
+	// Flapping is the restart limit and window of the pod flapping rule unless a
+	// namespace overrides them; a zero limit or window disables the rule
+	Flapping FlappingConfig `yaml:"-"`
+	// Finalizers are the finalizers the stuck finalizer rule reports once a
+	// resource has been deleting for StuckFinalizerAfter; without any the rule is
+	// disabled
+	Finalizers          []AllowedFinalizer `yaml:"-"`
+	StuckFinalizerAfter time.Duration      `yaml:"-"`
+	// NodeProblems are the Node Problem Detector conditions and events the node
+	// problem rule reports; without any the rule is disabled
+	NodeProblems NodeProblems `yaml:"-"`
+	// NodeConditions are the kubelet conditions, e.g. Ready or DiskPressure, the
+	// node condition rule reports; without any the rule is disabled
+	NodeConditions []NodeProblem `yaml:"-"`
+	// EventReasons are the Warning event reasons the warning event rule
+	// reports; without any the rule is disabled
+	EventReasons []EventReason `yaml:"-"`
+	// EvictedPods reports namespaces where failed pods accumulate; a zero
+	// threshold disables the rule
+	EvictedPods EvictedPods `yaml:"-"`
+	// DecisionLog records the outcome of every rule evaluated against every
+	// resource, so thresholds can be tuned on near misses
+	DecisionLog DecisionLog `yaml:"-"`
+	// DefaultActions are the actions, by severity, of custom rules and query
+	// rules that omit actions; rules that can only notify keep none
+	DefaultActions map[string][]string `yaml:"-"`
+	// PluginActions are the actions implemented by remediation plugins, which
+	// rules may use in addition to the built-in actions
+	PluginActions []string `yaml:"-"`
+	// Clock tells the time of detections and condition durations; nil is the real clock
+	Clock clock.PassiveClock `yaml:"-"`
+	// Prober runs the probes of probe rules; nil probes over the network of the pod
+	Prober Prober `yaml:"-"`
+	// Prometheus evaluates the queries of query rules
+	Prometheus *PrometheusClient `yaml:"-"`
+	// QueryRules are rules with a PromQL condition, loaded after the built-in rules
+	QueryRules []Rule `yaml:"-"`
 }
 
 // NamespaceConfig contains namespace-specific detection settings
@@ -73,6 +212,10 @@ type NamespaceConfig struct {
 	Deployment DeploymentConfig `yaml:"deployment"`
 	CPU        CPUConfig        `yaml:"cpu"`
 	Memory     MemoryConfig     `yaml:"memory"`
+	// ResourceQuota overrides the resource quota threshold in the namespace
+	ResourceQuota ResourceQuotaConfig `yaml:"resourceQuota"`
+	// Flapping overrides the pod flapping restart limit and window in the namespace
+	Flapping FlappingConfig `yaml:"flapping"`
 }
 
 // CrashLoopConfig contains crash loop detection settings for a namespace
@@ -106,13 +249,34 @@ type MemoryConfig struct {
 
 // NewDetector creates a new detector instance
 func NewDetector(client kubernetes.Interface, config DetectionConfig) *Detector {
+	if config.State == nil {
+		config.State = NewStateStore(nil)
+	}
+	if config.Clock == nil {
+		config.Clock = clock.RealClock{}
+	}
+	if config.Prober == nil {
+		config.Prober = networkProber{}
+	}
+
 	return &Detector{
 		client: client,
 		config: config,
+		clock:  config.Clock,
 		rules:  []Rule{},
+
+		silenced:  make(map[string]bool),
+		decisions: newDecisionLog(config.DecisionLog),
+		restarts:  newRestartHistory(),
 	}
 }
 
+// SetNamespaces replaces the namespace-specific configuration, e.g. after namespace
+// selectors have been expanded
+func (d *Detector) SetNamespaces(namespaces map[string]NamespaceConfig) {
+	d.config.Namespaces = namespaces
+}
+
 // GetNamespaceConfig returns the namespace-specific configuration, falling back to defaults
 func (d *Detector) GetNamespaceConfig(namespace string) NamespaceConfig {
 	if nsConfig, exists := d.config.Namespaces[namespace]; exists {
@@ -142,13 +306,16 @@ func (d *Detector) GetNamespaceConfig(namespace string) NamespaceConfig {
 			OOMKillThreshold: d.config.OOMKillThreshold,
 			Enabled:          true,
 		},
+		ResourceQuota: ResourceQuotaConfig{
+			ThresholdPercent: d.config.ResourceQuotaThresholdPercent,
+		},
+		Flapping: d.config.Flapping,
 	}
 }
 
-// LoadRules loads detection rules from configuration
+// LoadRules loads the built-in rules, modified and extended by the rules of the
+// rules file, followed by the query rules
 func (d *Detector) LoadRules() error {
-	// For now, use built-in rules. In a real implementation,
-	// this would load from a YAML file
 	d.rules = []Rule{
 		{
 			Name:        "crash-loop-backoff",
@@ -241,17 +408,280 @@ func (d *Detector) LoadRules() error {
 			Actions:  []string{"restart-pod", "scale-replicas"},
 			Severity: "critical",
 		},
+		{
+			// Restarting the pod reruns the failing init step, so init failures
+			// are only notified
+			Name:        "init-container-failure",
+			Description: "Detect pods stuck on a failing init container",
+			Enabled:     true,
+			Conditions: []RuleCondition{
+				{
+					Resource: "Pod",
+					Field:    "status.initContainerStatuses[*].state.waiting.reason",
+					Operator: "equals",
+					Value:    "CrashLoopBackOff",
+					Duration: &metav1.Duration{Duration: 1 * time.Minute},
+				},
+			},
+			Actions:  []string{},
+			Severity: "high",
+		},
+		{
+			// Ephemeral containers cannot be removed from a pod, so long-running
+			// debug sessions are only notified
+			Name:        "ephemeral-container-age",
+			Description: "Detect long-running ephemeral debug containers",
+			Enabled:     d.config.EphemeralContainerMaxAge > 0,
+			Conditions: []RuleCondition{
+				{
+					Resource: "Pod",
+					Field:    "status.ephemeralContainerStatuses[*].state.running.startedAt",
+					Operator: "older_than",
+					Value:    d.config.EphemeralContainerMaxAge.String(),
+				},
+			},
+			Actions:  []string{},
+			Severity: "low",
+		},
+		{
+			// Paused rollouts are only notified; the issues of rollouts that
+			// stopped progressing get the actions
+			Name:        "stuck-rollout",
+			Description: "Detect paused or stuck deployment rollouts",
+			Enabled:     d.config.StuckRolloutAfter > 0,
+			Conditions: []RuleCondition{
+				{
+					Resource: "Deployment",
+					Field:    "status.updatedReplicas",
+					Operator: "less_than",
+					Value:    "spec.replicas",
+					Duration: &metav1.Duration{Duration: d.config.StuckRolloutAfter},
+				},
+			},
+			Actions:  []string{"rollback-deployment"},
+			Severity: "high",
+		},
+		{
+			// Only finalizers allow-listed in the configuration are reported and
+			// removed, by default after approval
+			Name:        StuckFinalizerRule,
+			Description: "Detect resources stuck deleting on an allow-listed finalizer",
+			Enabled:     len(d.config.Finalizers) > 0 && d.config.StuckFinalizerAfter > 0,
+			Actions:     []string{RemoveFinalizerAction},
+			Severity:    "medium",
+		},
+		{
+			// Severity and actions are set per condition type and event reason
+			Name:        NodeProblemRule,
+			Description: "Detect node problems reported by Node Problem Detector",
+			Enabled:     d.config.NodeProblems.configured(),
+			Severity:    "high",
+		},
+		{
+			// Severity and actions are set per condition type
+			Name:        NodeConditionRule,
+			Description: "Detect nodes with unhealthy conditions",
+			Enabled:     len(d.config.NodeConditions) > 0,
+			Severity:    "high",
+		},
+		{
+			// force-delete-pod only runs in namespaces that opted in to it
+			Name:        StuckTerminatingRule,
+			Description: "Detect pods stuck terminating beyond their grace period",
+			Enabled:     d.config.StuckTerminatingAfter > 0,
+			Conditions: []RuleCondition{
+				{
+					Resource: "Pod",
+					Field:    "metadata.deletionTimestamp",
+					Operator: "older_than",
+					Value:    d.config.StuckTerminatingAfter.String(),
+				},
+			},
+			Actions:  []string{ForceDeletePodAction},
+			Severity: "medium",
+		},
+		{
+			// Pods that cannot be placed are only notified, with the nodes
+			// their constraints allow compared to the replicas
+			Name:        SchedulingConflictRule,
+			Description: "Detect workloads whose pods cannot be scheduled because of anti-affinity or topology spread constraints",
+			Enabled:     d.config.SchedulingConflictAfter > 0,
+			Actions:     []string{},
+			Severity:    "medium",
+		},
+		{
+			// Failed pods are kept only for their status until they are
+			// garbage collected, which happens late on most clusters
+			Name:        EvictedPodsRule,
+			Description: "Detect evicted and failed pods accumulating in a namespace",
+			Enabled:     d.config.EvictedPods.Threshold > 0,
+			Actions:     []string{CleanupEvictedPodsAction},
+			Severity:    "low",
+		},
+		{
+			// Actions only apply when the not ready backends are run by a
+			// single Deployment; a selector matching no pods is only notified
+			Name:        ServiceWithoutEndpointsRule,
+			Description: "Detect Services without ready endpoints",
+			Enabled:     d.config.ServiceWithoutEndpointsAfter > 0,
+			Actions:     []string{"rolling-restart-pods", "rollback-deployment"},
+			Severity:    "high",
+		},
+		{
+			// Teams are notified before new pods are rejected; the severity is
+			// raised to high once a resource is used up
+			Name:        ResourceQuotaRule,
+			Description: "Detect ResourceQuotas close to exhaustion",
+			Enabled:     d.config.ResourceQuotaThresholdPercent > 0,
+			Actions:     []string{},
+			Severity:    "medium",
+		},
+		{
+			// Severity and actions are set per event reason; only events of
+			// pods have actions
+			Name:        WarningEventRule,
+			Description: "Detect recurring Warning events",
+			Enabled:     len(d.config.EventReasons) > 0,
+			Severity:    "medium",
+		},
+		{
+			// Restarts are counted within a recent window, so a pod restarting
+			// now is told apart from one that restarted over months; restarting
+			// it again would not help, so it is only notified
+			Name:        PodFlappingRule,
+			Description: "Detect pods restarting repeatedly within a short window",
+			Enabled:     d.config.Flapping.RestartLimit > 0 && d.config.Flapping.Window > 0,
+			Actions:     []string{},
+			Severity:    "medium",
+		},
+	}
+	builtin := len(d.rules)
+
+	fileRules, err := readRulesFile(d.config.RulesFile)
+	if err != nil {
+		return err
+	}
+	for _, fileRule := range fileRules {
+		if index := slices.IndexFunc(d.rules[:builtin], func(loaded Rule) bool { return loaded.Name == fileRule.Name }); index >= 0 {
+			if err := fileRule.override(&d.rules[index], d.config.PluginActions); err != nil {
+				return fmt.Errorf("rules file: rule %s: %w", fileRule.Name, err)
+			}
+			continue
+		}
+		rule, err := fileRule.rule(d.config.PluginActions)
+		if err != nil {
+			return fmt.Errorf("rules file: rule %s: %w", fileRule.Name, err)
+		}
+		if fileRule.Actions == nil {
+			d.applyDefaultActions(&rule)
+		}
+		d.rules = append(d.rules, rule)
+	}
+
+	for _, rule := range d.config.QueryRules {
+		if slices.ContainsFunc(d.rules, func(loaded Rule) bool { return loaded.Name == rule.Name }) {
+			return fmt.Errorf("query rule %s: a rule with that name is already loaded", rule.Name)
+		}
+		if _, isQueryRule := rule.queryCondition(); !isQueryRule {
+			return fmt.Errorf("query rule %s: no query condition", rule.Name)
+		}
+		if rule.Actions == nil {
+			d.applyDefaultActions(&rule)
+		}
+		d.rules = append(d.rules, rule)
+	}
+
+	for i := range d.rules {
+		d.rules[i].Metadata = RuleMetadata{Owner: DefaultRuleOwner, Source: RuleSourceBuiltin}
+		if i >= builtin {
+			d.rules[i].Metadata.Source = RuleSourceFile
+		}
+		applyMetadata(&d.rules[i], d.config.RuleMetadata[d.rules[i].Name])
+		if runbook, exists := d.config.Runbooks[d.rules[i].Name]; exists {
+			d.rules[i].Runbook = runbook
+		}
+		for _, container := range d.config.Containers {
+			if container.appliesTo(d.rules[i].Name) {
+				d.rules[i].Containers = append(d.rules[i].Containers, container)
+			}
+		}
 	}
 	return nil
 }
 
+// applyDefaultActions sets the default actions of its severity on a custom rule
+// that omits actions. Probe and status condition rules, and field rules on nodes
+// or on resources with an apiVersion, can only notify and keep none.
+func (d *Detector) applyDefaultActions(rule *Rule) {
+	if rule.Probe != nil || rule.StatusConditions != nil || len(rule.Conditions) == 0 {
+		return
+	}
+	if first := rule.Conditions[0]; first.Query == "" && (first.APIVersion != "" || first.Resource == "Node") {
+		return
+	}
+	if actions, exists := d.config.DefaultActions[rule.Severity]; exists {
+		rule.Actions = slices.Clone(actions)
+	}
+}
+
+// DisableRule disables a loaded rule and returns false if it is unknown
+func (d *Detector) DisableRule(name string) bool {
+	for i := range d.rules {
+		if d.rules[i].Name == name {
+			d.rules[i].Enabled = false
+			return true
+		}
+	}
+	return false
+}
+
+// SilenceRule disables or re-enables a loaded rule at runtime, independently of
+// its configured Enabled flag, e.g. to silence a noisy rule during an incident
+func (d *Detector) SilenceRule(name string, silenced bool) error {
+	if !slices.ContainsFunc(d.rules, func(rule Rule) bool { return rule.Name == name }) {
+		return fmt.Errorf("%w: %s", ErrUnknownRule, name)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if silenced {
+		d.silenced[name] = true
+	} else {
+		delete(d.silenced, name)
+	}
+	return nil
+}
+
+// Silenced returns true if the rule was disabled at runtime
+func (d *Detector) Silenced(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.silenced[name]
+}
+
+// Rules returns the loaded detection rules
+func (d *Detector) Rules() []Rule {
+	rules := make([]Rule, len(d.rules))
+	copy(rules, d.rules)
+	return rules
+}
+
+// State returns the store tracking duration-based conditions
+func (d *Detector) State() *StateStore {
+	return d.config.State
+}
+
 // DetectIssues runs detection rules and returns detected issues
 func (d *Detector) DetectIssues(ctx context.Context) ([]Issue, error) {
 	logger := log.FromContext(ctx)
 	var issues []Issue
 
+	if d.decisions != nil {
+		d.decisions.startCycle()
+		defer d.decisions.endCycle()
+	}
 	for _, rule := range d.rules {
-		if !rule.Enabled {
+		if !d.ruleActive(ctx, rule) {
 			continue
 		}
 
@@ -262,10 +692,40 @@ func (d *Detector) DetectIssues(ctx context.Context) ([]Issue, error) {
 			continue
 		}
 
+		ruleIssues = applyContainerRules(rule, ruleIssues, nil)
+		enrichOwners(ruleIssues)
+		setRunbooks(rule, ruleIssues)
+		setRuleMetadata(rule, ruleIssues)
 		issues = append(issues, ruleIssues...)
 	}
 
-	return issues, nil
+	return d.optOutIssues(ctx, d.scopeIssues(issues)), nil
+}
+
+// ruleActive returns true if a rule is enabled, not disabled at runtime and
+// within its schedule
+func (d *Detector) ruleActive(ctx context.Context, rule Rule) bool {
+	logger := log.FromContext(ctx)
+	if !rule.Enabled {
+		return false
+	}
+	if d.Silenced(rule.Name) {
+		logger.V(1).Info("Skipping rule disabled at runtime", "rule", rule.Name)
+		return false
+	}
+
+	if rule.Schedule != "" {
+		window, exists := d.config.Schedules[rule.Schedule]
+		if !exists {
+			logger.Error(fmt.Errorf("unknown schedule: %s", rule.Schedule), "Skipping rule", "rule", rule.Name)
+			return false
+		}
+		if !window.Active(d.clock.Now()) {
+			logger.V(1).Info("Skipping rule outside of its schedule", "rule", rule.Name, "schedule", rule.Schedule)
+			return false
+		}
+	}
+	return true
 }
 
 // evaluateRule evaluates a single rule
@@ -277,14 +737,48 @@ func (d *Detector) evaluateRule(ctx context.Context, rule Rule) ([]Issue, error)
 		return d.detectCrashLoopBackOff(ctx, rule)
 	case "failed-deployment":
 		return d.detectFailedDeployment(ctx, rule)
+	case "stuck-rollout":
+		return d.detectStuckRollouts(ctx, rule)
 	case "high-cpu-usage":
 		return d.detectHighCPUUsage(ctx, rule)
 	case "high-memory-usage":
 		return d.detectHighMemoryUsage(ctx, rule)
 	case "oom-kill-detected":
 		return d.detectOOMKilled(ctx, rule)
+	case "init-container-failure", "ephemeral-container-age", StuckTerminatingRule:
+		return d.detectPods(ctx, rule, podChecks[rule.Name])
+	case StuckFinalizerRule:
+		return d.detectStuckFinalizers(ctx, rule)
+	case NodeProblemRule:
+		return d.detectNodeProblems(ctx, rule)
+	case NodeConditionRule:
+		return d.detectNodeConditions(ctx, rule)
+	case EvictedPodsRule:
+		return d.detectEvictedPods(ctx, rule)
+	case SchedulingConflictRule:
+		return d.detectSchedulingConflicts(ctx, rule)
+	case ServiceWithoutEndpointsRule:
+		return d.detectServicesWithoutEndpoints(ctx, rule)
+	case ResourceQuotaRule:
+		return d.detectResourceQuotas(ctx, rule)
+	case PodFlappingRule:
+		return d.detectPodFlapping(ctx, rule)
+	case WarningEventRule:
+		return d.detectWarningEvents(ctx, rule)
 	default:
-		return issues, fmt.Errorf("unknown rule: %s", rule.Name)
+		if rule.StatusConditions != nil {
+			return d.detectStatusConditions(ctx, rule)
+		}
+		if rule.Probe != nil {
+			return d.detectProbe(ctx, rule)
+		}
+		if _, isQueryRule := rule.queryCondition(); isQueryRule {
+			return d.detectQuery(ctx, rule)
+		}
+		if rule.isFieldRule() {
+			return d.detectFields(ctx, rule)
+		}
+		return issues, fmt.Errorf("%w: %s", ErrUnknownRule, rule.Name)
 	}
 }
 
@@ -292,254 +786,514 @@ func (d *Detector) evaluateRule(ctx context.Context, rule Rule) ([]Issue, error)
 func (d *Detector) detectCrashLoopBackOff(ctx context.Context, rule Rule) ([]Issue, error) {
 	var issues []Issue
 
-	pods, err := d.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := d.listPods(ctx)
 	if err != nil {
 		return issues, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	for _, pod := range pods.Items {
-		// Get namespace-specific configuration
-		nsConfig := d.GetNamespaceConfig(pod.Namespace)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindPod, pod, func(trace *Trace) []Issue {
+			return d.checkCrashLoopBackOff(ctx, rule, pod, trace)
+		})...)
+	}
+
+	return issues, nil
+}
 
-		// Skip if crash loop detection is disabled for this namespace
-		if !nsConfig.CrashLoop.Enabled {
+// checkCrashLoopBackOff evaluates the crash loop rule against a pod
+func (d *Detector) checkCrashLoopBackOff(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Get namespace-specific configuration
+	nsConfig := d.GetNamespaceConfig(pod.Namespace)
+
+	// Skip if crash loop detection is disabled for this namespace
+	if !trace.check("namespace crash loop detection enabled", "", nsConfig.CrashLoop.Enabled, "true", nsConfig.CrashLoop.Enabled) {
+		return issues
+	}
+	nsConfig = d.workloadConfig(ctx, pod, nsConfig)
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		reason := waitingReason(containerStatus)
+		if !trace.check("status.containerStatuses[*].state.waiting.reason", containerStatus.Name, reason, "CrashLoopBackOff", reason == "CrashLoopBackOff") {
 			continue
 		}
 
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			if containerStatus.State.Waiting != nil &&
-				containerStatus.State.Waiting.Reason == "CrashLoopBackOff" {
-
-				// Use namespace-specific restart limit
-				if int(containerStatus.RestartCount) >= nsConfig.CrashLoop.RestartLimit {
-					// Check if the condition has been met for the required duration
-					// For CrashLoopBackOff, we check the waiting state duration, not termination
-					if d.meetsWaitingDurationCondition(containerStatus.State.Waiting, &metav1.Duration{Duration: nsConfig.CrashLoop.CheckDuration}) {
-						issue := Issue{
-							RuleName:    rule.Name,
-							Description: fmt.Sprintf("%s (restart limit: %d)", rule.Description, nsConfig.CrashLoop.RestartLimit),
-							Severity:    rule.Severity,
-							Resource:    pod.DeepCopyObject(),
-							Namespace:   pod.Namespace,
-							Name:        pod.Name,
-							Kind:        "Pod",
-							Actions:     rule.Actions,
-							Labels:      rule.Labels,
-							DetectedAt:  time.Now(),
-						}
-						issues = append(issues, issue)
-					}
-				}
-			}
+		// Use namespace-specific restart limit
+		if !trace.check("status.containerStatuses[*].restartCount", containerStatus.Name, containerStatus.RestartCount,
+			fmt.Sprintf(">= %d", nsConfig.CrashLoop.RestartLimit), int(containerStatus.RestartCount) >= nsConfig.CrashLoop.RestartLimit) {
+			continue
 		}
+
+		// The waiting state has no timestamp, so track how long the condition has held
+		key := conditionKey(rule.Name, pod.Namespace, pod.Name, containerStatus.Name)
+		held := d.conditionHeld(key, trace)
+		if !trace.check("condition held for", containerStatus.Name, held, fmt.Sprintf(">= %s", nsConfig.CrashLoop.CheckDuration), held >= nsConfig.CrashLoop.CheckDuration) {
+			continue
+		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (restart limit: %d)", rule.Description, nsConfig.CrashLoop.RestartLimit),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "CrashLoopBackOff",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
 	}
 
-	return issues, nil
+	return issues
 }
 
 // detectFailedDeployment detects failed deployments
 func (d *Detector) detectFailedDeployment(ctx context.Context, rule Rule) ([]Issue, error) {
 	var issues []Issue
 
-	deployments, err := d.client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	deployments, err := d.listDeployments(ctx)
 	if err != nil {
 		return issues, fmt.Errorf("failed to list deployments: %w", err)
 	}
 
-	for _, deployment := range deployments.Items {
-		// Get namespace-specific configuration
-		nsConfig := d.GetNamespaceConfig(deployment.Namespace)
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindDeployment, deployment, func(trace *Trace) []Issue {
+			return d.checkFailedDeployment(ctx, rule, deployment, trace)
+		})...)
+	}
+
+	return issues, nil
+}
+
+// checkFailedDeployment evaluates the failed deployment rule against a deployment
+func (d *Detector) checkFailedDeployment(ctx context.Context, rule Rule, deployment *appsv1.Deployment, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Get namespace-specific configuration
+	nsConfig := d.GetNamespaceConfig(deployment.Namespace)
+
+	// Skip if deployment failure detection is disabled for this namespace
+	if !trace.check("namespace deployment failure detection enabled", "", nsConfig.Deployment.Enabled, "true", nsConfig.Deployment.Enabled) {
+		return issues
+	}
+	nsConfig = d.workloadConfig(ctx, deployment, nsConfig)
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type != appsv1.DeploymentProgressing {
+			continue
+		}
 
-		// Skip if deployment failure detection is disabled for this namespace
-		if !nsConfig.Deployment.Enabled {
+		if !trace.check("status.conditions[Progressing].status", "", condition.Status, string(corev1.ConditionFalse), condition.Status == corev1.ConditionFalse) ||
+			!trace.check("status.conditions[Progressing].reason", "", condition.Reason, "ProgressDeadlineExceeded", condition.Reason == "ProgressDeadlineExceeded") {
 			continue
 		}
 
-		for _, condition := range deployment.Status.Conditions {
-			if condition.Type == appsv1.DeploymentProgressing &&
-				condition.Status == corev1.ConditionFalse &&
-				condition.Reason == "ProgressDeadlineExceeded" {
-
-				// Check if the condition has been met for the required duration
-				if d.meetsDurationCondition(nil, &metav1.Duration{Duration: nsConfig.Deployment.CheckDuration}) {
-					issue := Issue{
-						RuleName:    rule.Name,
-						Description: fmt.Sprintf("%s (failure threshold: %d)", rule.Description, nsConfig.Deployment.FailureThreshold),
-						Severity:    rule.Severity,
-						Resource:    deployment.DeepCopyObject(),
-						Namespace:   deployment.Namespace,
-						Name:        deployment.Name,
-						Kind:        "Deployment",
-						Actions:     rule.Actions,
-						Labels:      rule.Labels,
-						DetectedAt:  time.Now(),
-					}
-					issues = append(issues, issue)
-				}
-			}
+		// Check if the condition has been met for the required duration
+		key := conditionKey(rule.Name, deployment.Namespace, deployment.Name)
+		held := d.conditionHeld(key, trace)
+		if !trace.check("condition held for", "", held, fmt.Sprintf(">= %s", nsConfig.Deployment.CheckDuration), held >= nsConfig.Deployment.CheckDuration) {
+			continue
 		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (failure threshold: %d)", rule.Description, nsConfig.Deployment.FailureThreshold),
+			Severity:    rule.Severity,
+			Resource:    deployment.DeepCopyObject(),
+			Namespace:   deployment.Namespace,
+			Name:        deployment.Name,
+			Kind:        "Deployment",
+			Reason:      "ProgressDeadlineExceeded",
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
 	}
 
-	return issues, nil
+	return issues
 }
 
-// detectHighCPUUsage detects high CPU usage (simplified implementation)
+// detectHighCPUUsage detects high CPU usage
 func (d *Detector) detectHighCPUUsage(ctx context.Context, rule Rule) ([]Issue, error) {
 	var issues []Issue
 
-	// This is a simplified implementation. In a real scenario,
-	// you would use metrics server or Prometheus to get actual CPU metrics
-
-	pods, err := d.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := d.listPods(ctx)
 	if err != nil {
 		return issues, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	for _, pod := range pods.Items {
-		// Get namespace-specific configuration
-		nsConfig := d.GetNamespaceConfig(pod.Namespace)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindPod, pod, func(trace *Trace) []Issue {
+			return d.checkHighCPUUsage(ctx, rule, pod, trace)
+		})...)
+	}
+
+	return issues, nil
+}
+
+// checkHighCPUUsage evaluates the high CPU rule against a pod
+func (d *Detector) checkHighCPUUsage(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Get namespace-specific configuration
+	nsConfig := d.GetNamespaceConfig(pod.Namespace)
+
+	// Skip if CPU monitoring is disabled for this namespace
+	if !trace.check("namespace CPU monitoring enabled", "", nsConfig.CPU.Enabled, "true", nsConfig.CPU.Enabled) {
+		return issues
+	}
+
+	usage, measured := d.podUsage(ctx, pod)
+	trace.check("usage source", "", usageSourceName(measured), "metrics-server or restart heuristics", true)
+	if measured {
+		return d.checkMeasuredUsage(rule, pod, usage, corev1.ResourceCPU, nsConfig.CPU.ThresholdPercent, nsConfig.CPU.CheckDuration, "HighCPU", trace)
+	}
+
+	// Without pod metrics, approximate high CPU usage by the restart count
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		// Use a more realistic heuristic for high CPU simulation
+		// High restart count could indicate resource pressure including CPU
+		restartThreshold := int32(nsConfig.CPU.ThresholdPercent / 10) // Convert percentage to restart count threshold
+		if restartThreshold < 1 {
+			restartThreshold = 1
+		}
 
-		// Skip if CPU monitoring is disabled for this namespace
-		if !nsConfig.CPU.Enabled {
+		if !trace.check("status.containerStatuses[*].restartCount", containerStatus.Name, containerStatus.RestartCount,
+			fmt.Sprintf(">= %d", restartThreshold), containerStatus.RestartCount >= restartThreshold) {
 			continue
 		}
 
-		// Simulate high CPU detection based on restart count and container status
-		// This is still a placeholder - in reality you'd query metrics server
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			// Use a more realistic heuristic for high CPU simulation
-			// High restart count could indicate resource pressure including CPU
-			restartThreshold := int32(nsConfig.CPU.ThresholdPercent / 10) // Convert percentage to restart count threshold
-			if restartThreshold < 1 {
-				restartThreshold = 1
-			}
+		// Check if the condition has been met for the required duration
+		if !d.traceDurationCondition(trace, containerStatus, nsConfig.CPU.CheckDuration) {
+			continue
+		}
 
-			if containerStatus.RestartCount >= restartThreshold {
-				// Check if the condition has been met for the required duration
-				if d.meetsDurationCondition(containerStatus.LastTerminationState.Terminated, &metav1.Duration{Duration: nsConfig.CPU.CheckDuration}) {
-					issue := Issue{
-						RuleName:    rule.Name,
-						Description: fmt.Sprintf("%s (threshold: %.1f%%, restarts: %d)", rule.Description, nsConfig.CPU.ThresholdPercent, containerStatus.RestartCount),
-						Severity:    rule.Severity,
-						Resource:    pod.DeepCopyObject(),
-						Namespace:   pod.Namespace,
-						Name:        pod.Name,
-						Kind:        "Pod",
-						Actions:     rule.Actions,
-						Labels:      rule.Labels,
-						DetectedAt:  time.Now(),
-					}
-					issues = append(issues, issue)
-				}
-			}
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (threshold: %.1f%%, restarts: %d)", rule.Description, nsConfig.CPU.ThresholdPercent, containerStatus.RestartCount),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "HighCPU",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
 		}
+		issues = append(issues, issue)
 	}
 
-	return issues, nil
+	return issues
 }
 
 // detectHighMemoryUsage detects high memory usage in pods
 func (d *Detector) detectHighMemoryUsage(ctx context.Context, rule Rule) ([]Issue, error) {
 	var issues []Issue
 
-	pods, err := d.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := d.listPods(ctx)
 	if err != nil {
 		return issues, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	for _, pod := range pods.Items {
-		// Get namespace-specific configuration
-		nsConfig := d.GetNamespaceConfig(pod.Namespace)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindPod, pod, func(trace *Trace) []Issue {
+			return d.checkHighMemoryUsage(ctx, rule, pod, trace)
+		})...)
+	}
+
+	return issues, nil
+}
+
+// checkHighMemoryUsage evaluates the high memory rule against a pod
+func (d *Detector) checkHighMemoryUsage(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Get namespace-specific configuration
+	nsConfig := d.GetNamespaceConfig(pod.Namespace)
+
+	// Skip if memory monitoring is disabled for this namespace
+	if !trace.check("namespace memory monitoring enabled", "", nsConfig.Memory.Enabled, "true", nsConfig.Memory.Enabled) {
+		return issues
+	}
+
+	usage, measured := d.podUsage(ctx, pod)
+	trace.check("usage source", "", usageSourceName(measured), "metrics-server or restart heuristics", true)
+	if measured {
+		return d.checkMeasuredUsage(rule, pod, usage, corev1.ResourceMemory, nsConfig.Memory.ThresholdPercent, nsConfig.Memory.CheckDuration, "HighMemory", trace)
+	}
 
-		// Skip if memory monitoring is disabled for this namespace
-		if !nsConfig.Memory.Enabled {
+	// Without pod metrics, approximate high memory usage by memory pressure indicators
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		// Check for memory pressure indicators
+		reason := waitingReason(containerStatus)
+		pressure := containerStatus.RestartCount > 3 || reason == "CrashLoopBackOff" || reason == "ContainerCreating"
+		if !trace.check("memory pressure indicators", containerStatus.Name,
+			fmt.Sprintf("restartCount=%d waiting.reason=%s", containerStatus.RestartCount, reason),
+			"restartCount > 3 or waiting.reason in (CrashLoopBackOff, ContainerCreating)", pressure) {
 			continue
 		}
 
-		// Simulate high memory detection based on restart count and container status
-		// In reality, you'd query metrics server or Prometheus for actual memory usage
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			// Check for memory pressure indicators
-			if containerStatus.RestartCount > 3 ||
-				(containerStatus.State.Waiting != nil &&
-					(containerStatus.State.Waiting.Reason == "CrashLoopBackOff" ||
-						containerStatus.State.Waiting.Reason == "ContainerCreating")) {
-
-				// Check if the condition has been met for the required duration
-				if d.meetsDurationCondition(containerStatus.LastTerminationState.Terminated, &metav1.Duration{Duration: nsConfig.Memory.CheckDuration}) {
-					issue := Issue{
-						RuleName:    rule.Name,
-						Description: fmt.Sprintf("%s (threshold: %.1f%%)", rule.Description, nsConfig.Memory.ThresholdPercent),
-						Severity:    rule.Severity,
-						Resource:    pod.DeepCopyObject(),
-						Namespace:   pod.Namespace,
-						Name:        pod.Name,
-						Kind:        "Pod",
-						Actions:     rule.Actions,
-						Labels:      rule.Labels,
-						DetectedAt:  time.Now(),
-					}
-					issues = append(issues, issue)
-				}
-			}
+		// Check if the condition has been met for the required duration
+		if !d.traceDurationCondition(trace, containerStatus, nsConfig.Memory.CheckDuration) {
+			continue
 		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (threshold: %.1f%%)", rule.Description, nsConfig.Memory.ThresholdPercent),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "HighMemory",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
 	}
 
-	return issues, nil
+	return issues
 }
 
 // detectOOMKilled detects pods that have been OOMKilled
 func (d *Detector) detectOOMKilled(ctx context.Context, rule Rule) ([]Issue, error) {
 	var issues []Issue
 
-	pods, err := d.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	pods, err := d.listPods(ctx)
 	if err != nil {
 		return issues, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	for _, pod := range pods.Items {
-		// Get namespace-specific configuration
-		nsConfig := d.GetNamespaceConfig(pod.Namespace)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindPod, pod, func(trace *Trace) []Issue {
+			return d.checkOOMKilled(ctx, rule, pod, trace)
+		})...)
+	}
 
-		// Skip if memory monitoring is disabled for this namespace
-		if !nsConfig.Memory.Enabled {
+	return issues, nil
+}
+
+// checkOOMKilled evaluates the OOMKill rule against a pod
+func (d *Detector) checkOOMKilled(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Get namespace-specific configuration
+	nsConfig := d.GetNamespaceConfig(pod.Namespace)
+
+	// Skip if memory monitoring is disabled for this namespace
+	if !trace.check("namespace memory monitoring enabled", "", nsConfig.Memory.Enabled, "true", nsConfig.Memory.Enabled) {
+		return issues
+	}
+	nsConfig = d.workloadConfig(ctx, pod, nsConfig)
+
+	// Check for OOMKilled containers
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		reason := ""
+		if containerStatus.State.Terminated != nil {
+			reason = containerStatus.State.Terminated.Reason
+		}
+		if !trace.check("status.containerStatuses[*].state.terminated.reason", containerStatus.Name, reason, "OOMKilled", reason == "OOMKilled") {
 			continue
 		}
 
-		// Check for OOMKilled containers
-		for _, containerStatus := range pod.Status.ContainerStatuses {
-			if containerStatus.State.Terminated != nil &&
-				containerStatus.State.Terminated.Reason == "OOMKilled" {
-
-				// Count OOMKills for this container
-				oomKillCount := 0
-				if containerStatus.RestartCount > 0 {
-					oomKillCount = int(containerStatus.RestartCount)
-				}
-
-				// Check if OOMKill threshold is exceeded
-				if oomKillCount >= nsConfig.Memory.OOMKillThreshold {
-					issue := Issue{
-						RuleName:    rule.Name,
-						Description: fmt.Sprintf("%s (OOMKills: %d, threshold: %d)", rule.Description, oomKillCount, nsConfig.Memory.OOMKillThreshold),
-						Severity:    rule.Severity,
-						Resource:    pod.DeepCopyObject(),
-						Namespace:   pod.Namespace,
-						Name:        pod.Name,
-						Kind:        "Pod",
-						Actions:     rule.Actions,
-						Labels:      rule.Labels,
-						DetectedAt:  time.Now(),
-					}
-					issues = append(issues, issue)
-				}
-			}
+		// Count OOMKills for this container
+		oomKillCount := 0
+		if containerStatus.RestartCount > 0 {
+			oomKillCount = int(containerStatus.RestartCount)
+		}
+
+		// Check if OOMKill threshold is exceeded
+		if !trace.check("OOMKill count", containerStatus.Name, oomKillCount,
+			fmt.Sprintf(">= %d", nsConfig.Memory.OOMKillThreshold), oomKillCount >= nsConfig.Memory.OOMKillThreshold) {
+			continue
 		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (OOMKills: %d, threshold: %d)", rule.Description, oomKillCount, nsConfig.Memory.OOMKillThreshold),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "OOMKilled",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// detectPods evaluates a pod rule against all pods
+func (d *Detector) detectPods(ctx context.Context, rule Rule, check func(*Detector, context.Context, Rule, *corev1.Pod, *Trace) []Issue) ([]Issue, error) {
+	var issues []Issue
+
+	pods, err := d.listPods(ctx)
+	if err != nil {
+		return issues, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		issues = append(issues, d.decide(ctx, rule, KindPod, pod, func(trace *Trace) []Issue {
+			return check(d, ctx, rule, pod, trace)
+		})...)
 	}
 
 	return issues, nil
 }
 
+// checkInitContainerFailure evaluates the init container rule against a pod. Init
+// containers run in order, so only the first one that has not completed is checked.
+func (d *Detector) checkInitContainerFailure(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	// Init container crash loops follow the crash loop settings of the namespace
+	nsConfig := d.GetNamespaceConfig(pod.Namespace)
+	if !trace.check("namespace crash loop detection enabled", "", nsConfig.CrashLoop.Enabled, "true", nsConfig.CrashLoop.Enabled) {
+		return issues
+	}
+	nsConfig = d.workloadConfig(ctx, pod, nsConfig)
+
+	statuses := pod.Status.InitContainerStatuses
+	for step, containerStatus := range statuses {
+		if containerStatus.Ready {
+			continue
+		}
+
+		failure := initContainerFailure(containerStatus)
+		if !trace.check("status.initContainerStatuses[*] failing", containerStatus.Name, failure, "CrashLoopBackOff or non-zero exit code", failure != "") {
+			break
+		}
+
+		key := conditionKey(rule.Name, pod.Namespace, pod.Name, containerStatus.Name)
+		held := d.conditionHeld(key, trace)
+		if !trace.check("condition held for", containerStatus.Name, held, fmt.Sprintf(">= %s", nsConfig.CrashLoop.CheckDuration), held >= nsConfig.CrashLoop.CheckDuration) {
+			break
+		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s: init container %d/%d %q failed: %s (restarts: %d)", rule.Description, step+1, len(statuses), containerStatus.Name, failure, containerStatus.RestartCount),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "InitContainerFailed",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
+		break
+	}
+
+	return issues
+}
+
+// initContainerFailure describes why an init container is failing, or returns an
+// empty string. Crash-looping init containers alternate between waiting in
+// CrashLoopBackOff and terminating with an error.
+func initContainerFailure(status corev1.ContainerStatus) string {
+	terminated := status.State.Terminated
+	if terminated == nil && status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+		terminated = status.LastTerminationState.Terminated
+		if terminated == nil {
+			return "CrashLoopBackOff"
+		}
+	}
+	if terminated == nil || terminated.ExitCode == 0 {
+		return ""
+	}
+
+	failure := fmt.Sprintf("exit code %d", terminated.ExitCode)
+	if terminated.Reason != "" {
+		failure += fmt.Sprintf(" (%s)", terminated.Reason)
+	}
+	return failure
+}
+
+// checkEphemeralContainerAge evaluates the ephemeral container rule against a pod
+func (d *Detector) checkEphemeralContainerAge(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	var issues []Issue
+
+	maxAge := d.config.EphemeralContainerMaxAge
+	for _, containerStatus := range pod.Status.EphemeralContainerStatuses {
+		running := containerStatus.State.Running
+		if !trace.check("status.ephemeralContainerStatuses[*].state.running", containerStatus.Name, running != nil, "true", running != nil) {
+			continue
+		}
+
+		age := d.clock.Since(running.StartedAt.Time).Round(time.Second)
+		if !trace.check("running for", containerStatus.Name, age, fmt.Sprintf(">= %s", maxAge), age >= maxAge) {
+			continue
+		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s: %q running for %s (max age: %s)", rule.Description, containerStatus.Name, age, maxAge),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      "EphemeralContainerRunning",
+			Container:   containerStatus.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// waitingReason returns the reason a container is waiting, or an empty string
+func waitingReason(status corev1.ContainerStatus) string {
+	if status.State.Waiting == nil {
+		return ""
+	}
+	return status.State.Waiting.Reason
+}
+
+// traceDurationCondition checks if the last termination of a container is at least
+// duration ago
+func (d *Detector) traceDurationCondition(trace *Trace, status corev1.ContainerStatus, duration time.Duration) bool {
+	matched := d.meetsDurationCondition(status.LastTerminationState.Terminated, &metav1.Duration{Duration: duration})
+	if trace == nil {
+		return matched
+	}
+
+	finishedAgo := "never terminated"
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && !terminated.FinishedAt.IsZero() {
+		finishedAgo = d.clock.Since(terminated.FinishedAt.Time).Round(time.Second).String()
+	}
+	return trace.check("lastState.terminated.finishedAt age", status.Name, finishedAgo, fmt.Sprintf(">= %s", duration), matched)
+}
+
 // meetsDurationCondition checks if a condition has been met for the required duration
 func (d *Detector) meetsDurationCondition(terminated *corev1.ContainerStateTerminated, duration *metav1.Duration) bool {
 	if duration == nil || duration.Duration == 0 {
@@ -558,27 +1312,107 @@ func (d *Detector) meetsDurationCondition(terminated *corev1.ContainerStateTermi
 		return false
 	}
 
-	return time.Since(terminated.FinishedAt.Time) >= duration.Duration
+	return d.clock.Since(terminated.FinishedAt.Time) >= duration.Duration
 }
 
-// meetsWaitingDurationCondition checks if a waiting condition has been met for the required duration
-func (d *Detector) meetsWaitingDurationCondition(waiting *corev1.ContainerStateWaiting, duration *metav1.Duration) bool {
-	if duration == nil || duration.Duration == 0 {
-		// No duration requirement, condition is met immediately
-		return true
+// workloadConfig applies the annotation overrides of a workload to its namespace
+// configuration. Values are clamped to the configured bounds; invalid values are ignored.
+func (d *Detector) workloadConfig(ctx context.Context, obj metav1.Object, nsConfig NamespaceConfig) NamespaceConfig {
+	bounds := d.config.Overrides
+	annotations := obj.GetAnnotations()
+	if bounds == nil || len(annotations) == 0 {
+		return nsConfig
 	}
 
-	if waiting == nil {
-		// No waiting state
-		return false
+	logger := log.FromContext(ctx)
+	ignore := func(err error) {
+		logger.Error(err, "Ignoring workload override", "namespace", obj.GetNamespace(), "name", obj.GetName())
 	}
 
-	// For CrashLoopBackOff, we check how long the container has been waiting
-	// Since we don't have a direct "waiting since" timestamp, we'll use a heuristic:
-	// If the container has restart count > 0 and is in waiting state, assume it's been waiting
-	// This is a simplification - in a production environment, you might want to track this more precisely
-	
-	// For now, if we have a restart count and we're in CrashLoopBackOff, consider the condition met
-	// This is reasonable because CrashLoopBackOff inherently implies a time-based backoff
-	return true
+	if limit, ok, err := overrides.Int(annotations, overrides.AnnotationCrashLoopRestartLimit, bounds.RestartLimit); err != nil {
+		ignore(err)
+	} else if ok {
+		nsConfig.CrashLoop.RestartLimit = limit
+	}
+
+	if duration, ok, err := overrides.Duration(annotations, overrides.AnnotationCrashLoopCheckDuration, bounds.CheckDuration); err != nil {
+		ignore(err)
+	} else if ok {
+		nsConfig.CrashLoop.CheckDuration = duration
+	}
+
+	if duration, ok, err := overrides.Duration(annotations, overrides.AnnotationDeploymentCheckDuration, bounds.CheckDuration); err != nil {
+		ignore(err)
+	} else if ok {
+		nsConfig.Deployment.CheckDuration = duration
+	}
+
+	if threshold, ok, err := overrides.Int(annotations, overrides.AnnotationOOMKillThreshold, bounds.OOMKillThreshold); err != nil {
+		ignore(err)
+	} else if ok {
+		nsConfig.Memory.OOMKillThreshold = threshold
+	}
+
+	return nsConfig
+}
+
+// optOutIssues drops the issues of workloads opted out with their disable
+// annotation, and limits the actions of the others to the ones allowed by their
+// actions annotation. Invalid annotations are ignored.
+func (d *Detector) optOutIssues(ctx context.Context, issues []Issue) []Issue {
+	if d.config.Overrides == nil || !d.config.Overrides.OptOut {
+		return issues
+	}
+
+	logger := log.FromContext(ctx)
+	kept := issues[:0]
+	for _, issue := range issues {
+		obj, ok := issue.Resource.(metav1.Object)
+		if !ok {
+			kept = append(kept, issue)
+			continue
+		}
+
+		annotations := obj.GetAnnotations()
+		if disabled, err := overrides.Disabled(annotations); err != nil {
+			logger.Error(err, "Ignoring workload override", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		} else if disabled {
+			logger.V(1).Info("Issue dropped because the workload opted out", "rule", issue.RuleName, "kind", issue.Kind, "namespace", issue.Namespace, "name", issue.Name)
+			continue
+		}
+		if allowed, ok := overrides.Actions(annotations); ok {
+			issue.Actions = slices.DeleteFunc(slices.Clone(issue.Actions), func(action string) bool {
+				return action != NotifyOnlyAction && !slices.Contains(allowed, action)
+			})
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+// conditionHeldFor records that a condition holds and checks if it has held
+// for the required duration since it was first observed
+func (d *Detector) conditionHeldFor(key string, duration time.Duration) bool {
+	return d.conditionHeld(key, nil) >= duration
+}
+
+// conditionHeld records that a condition holds and returns how long it has held
+// since it was first observed. Traces only read the state, so tracing a rule does
+// not start or extend a condition.
+func (d *Detector) conditionHeld(key string, trace *Trace) time.Duration {
+	if trace.readOnly() {
+		state, exists := d.config.State.Get(key)
+		if !exists {
+			return 0
+		}
+		return d.clock.Since(state.FirstSeen)
+	}
+
+	state := d.config.State.Observe(key, d.clock.Now())
+	return state.LastSeen.Sub(state.FirstSeen)
+}
+
+// conditionKey identifies a condition of a resource in the state store
+func conditionKey(parts ...string) string {
+	return strings.Join(parts, "/")
 }
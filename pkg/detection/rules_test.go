@@ -0,0 +1,2524 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
+)
+
+func TestWorkloadConfigOverrides(t *testing.T) {
+	bounds := &overrides.Bounds{
+		RestartLimit:     overrides.IntBounds{Min: 1, Max: 20},
+		OOMKillThreshold: overrides.IntBounds{Min: 1, Max: 5},
+		CheckDuration:    overrides.DurationBounds{Max: time.Hour},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{Overrides: bounds})
+	nsConfig := detector.GetNamespaceConfig("default")
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web-1",
+		Namespace: "default",
+		Annotations: map[string]string{
+			overrides.AnnotationCrashLoopRestartLimit:  "10",
+			overrides.AnnotationCrashLoopCheckDuration: "3h",
+			overrides.AnnotationOOMKillThreshold:       "many",
+		},
+	}}
+
+	got := detector.workloadConfig(context.Background(), pod, nsConfig)
+	if got.CrashLoop.RestartLimit != 10 {
+		t.Errorf("RestartLimit = %d, want 10", got.CrashLoop.RestartLimit)
+	}
+	if got.CrashLoop.CheckDuration != time.Hour {
+		t.Errorf("CheckDuration = %v, want clamped to 1h", got.CrashLoop.CheckDuration)
+	}
+	if got.Memory.OOMKillThreshold != nsConfig.Memory.OOMKillThreshold {
+		t.Errorf("invalid override should be ignored, got %d", got.Memory.OOMKillThreshold)
+	}
+
+	// Overrides are ignored when disabled
+	detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{})
+	if got := detector.workloadConfig(context.Background(), pod, nsConfig); got.CrashLoop.RestartLimit != nsConfig.CrashLoop.RestartLimit {
+		t.Errorf("overrides applied while disabled: %d", got.CrashLoop.RestartLimit)
+	}
+}
+
+func TestOptOutIssues(t *testing.T) {
+	pod := func(name string, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations}}
+	}
+	issues := []Issue{
+		{Name: "web-1", Resource: pod("web-1", nil), Actions: []string{"restart-pod"}},
+		{Name: "web-2", Resource: pod("web-2", map[string]string{overrides.AnnotationDisable: "true"}), Actions: []string{"restart-pod"}},
+		{Name: "web-3", Resource: pod("web-3", map[string]string{overrides.AnnotationActions: "rollback-deployment"}), Actions: []string{"restart-pod", NotifyOnlyAction}},
+		{Name: "web-4", Resource: pod("web-4", map[string]string{overrides.AnnotationDisable: "maybe"}), Actions: []string{"restart-pod"}},
+	}
+
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{Overrides: &overrides.Bounds{OptOut: true}})
+	got := detector.optOutIssues(context.Background(), slices.Clone(issues))
+	if len(got) != 3 || got[0].Name != "web-1" || got[1].Name != "web-3" || got[2].Name != "web-4" {
+		t.Fatalf("unexpected issues: %v", got)
+	}
+	if !slices.Equal(got[1].Actions, []string{NotifyOnlyAction}) {
+		t.Errorf("actions = %v, want only %s", got[1].Actions, NotifyOnlyAction)
+	}
+	if !slices.Equal(issues[2].Actions, []string{"restart-pod", NotifyOnlyAction}) {
+		t.Errorf("actions of the input issue were modified: %v", issues[2].Actions)
+	}
+
+	// Annotations are ignored when opt-out is disabled
+	detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{Overrides: &overrides.Bounds{}})
+	if got := detector.optOutIssues(context.Background(), slices.Clone(issues)); len(got) != 4 {
+		t.Errorf("issues dropped while opt-out is disabled: %v", got)
+	}
+}
+
+func TestOwnerEnrichment(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        Owner
+	}{
+		{
+			name: "no owner metadata",
+		},
+		{
+			name:   "part-of label",
+			labels: map[string]string{LabelPartOf: "checkout"},
+			want:   Owner{Team: "checkout"},
+		},
+		{
+			name:        "team label and owner annotation",
+			labels:      map[string]string{LabelTeam: "payments", LabelPartOf: "checkout"},
+			annotations: map[string]string{AnnotationOwnerShort: "alice@example.com"},
+			want:        Owner{Team: "payments", Contact: "alice@example.com"},
+		},
+		{
+			name:        "kubeguardian annotations take precedence",
+			labels:      map[string]string{LabelTeam: "payments"},
+			annotations: map[string]string{AnnotationTeam: "sre", AnnotationOwner: "@oncall", AnnotationOwnerShort: "alice"},
+			want:        Owner{Team: "sre", Contact: "@oncall"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Labels: tt.labels, Annotations: tt.annotations}}
+			issues := []Issue{{Resource: pod}}
+			enrichOwners(issues)
+			if issues[0].Owner != tt.want {
+				t.Errorf("Owner = %+v, want %+v", issues[0].Owner, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorrelateChanges(t *testing.T) {
+	now := time.Now()
+	controller := true
+	replicaSet := func(name string, revision string, created time.Time, image string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "shop",
+				Annotations:       map[string]string{annotationRevision: revision},
+				CreationTimestamp: metav1.NewTime(created),
+				OwnerReferences:   []metav1.OwnerReference{{Kind: "Deployment", Name: "checkout", Controller: &controller}},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: image}, {Name: "proxy", Image: "envoy:1.29"}},
+			}}},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		replicaSet("checkout-1", "1", now.Add(-48*time.Hour), "registry.example.com/checkout:v1.2.3"),
+		replicaSet("checkout-2", "2", now.Add(-7*time.Minute-10*time.Second), "registry.example.com/checkout:v1.2.4"),
+	)
+	detector := NewDetector(client, DetectionConfig{ChangeWindow: time.Hour})
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "checkout-2-x7k2p",
+		Namespace:       "shop",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "checkout-2", Controller: &controller}},
+	}}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"}}
+	unowned := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "shop"}}
+	issues := []Issue{
+		{Namespace: "shop", Kind: "Pod", Name: pod.Name, Resource: pod},
+		{Namespace: "shop", Kind: "Deployment", Name: deployment.Name, Resource: deployment},
+		{Namespace: "shop", Kind: "Pod", Name: unowned.Name, Resource: unowned},
+	}
+	detector.CorrelateChanges(context.Background(), issues)
+
+	for _, issue := range issues[:2] {
+		if issue.Change == nil {
+			t.Fatalf("expected a change for %s/%s", issue.Kind, issue.Name)
+		}
+		if got, want := issue.Change.Summary(now), "changed 7 minutes ago: image v1.2.3 → v1.2.4"; got != want {
+			t.Errorf("Summary() = %q, want %q", got, want)
+		}
+	}
+	if issues[2].Change != nil {
+		t.Errorf("pod without a deployment should have no change, got %+v", issues[2].Change)
+	}
+
+	// Rollouts outside of the window are not correlated
+	detector = NewDetector(client, DetectionConfig{ChangeWindow: 5 * time.Minute})
+	issues = []Issue{{Namespace: "shop", Kind: "Deployment", Name: deployment.Name, Resource: deployment}}
+	detector.CorrelateChanges(context.Background(), issues)
+	if issues[0].Change != nil {
+		t.Errorf("change outside of the window should be ignored, got %+v", issues[0].Change)
+	}
+}
+
+func TestRunbooks(t *testing.T) {
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{
+		Runbooks: map[string]string{"crash-loop-backoff": "https://wiki.example.com/{rule}/{namespace}/{name}?team={team}"},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	var rule Rule
+	for _, r := range detector.Rules() {
+		if r.Name == "crash-loop-backoff" {
+			rule = r
+		}
+	}
+	issues := []Issue{{RuleName: rule.Name, Namespace: "default", Kind: "Pod", Name: "web 1", Owner: Owner{Team: "payments"}}}
+	setRunbooks(rule, issues)
+
+	want := "https://wiki.example.com/crash-loop-backoff/default/web%201?team=payments"
+	if issues[0].Runbook != want {
+		t.Errorf("Runbook = %q, want %q", issues[0].Runbook, want)
+	}
+	if got := RenderRunbook("", issues[0]); got != "" {
+		t.Errorf("RenderRunbook() without a template = %q, want empty", got)
+	}
+}
+
+func TestRuleMetadata(t *testing.T) {
+	changed := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{
+		RuleMetadata: map[string]RuleMetadata{"crash-loop-backoff": {Owner: "sre", Source: RuleSourceCRD, LastModified: changed}},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	for _, rule := range detector.Rules() {
+		want := RuleMetadata{Owner: DefaultRuleOwner, Source: RuleSourceBuiltin}
+		if rule.Name == "crash-loop-backoff" {
+			// The source of a rule cannot be configured
+			want = RuleMetadata{Owner: "sre", Source: RuleSourceBuiltin, LastModified: changed}
+		}
+		if rule.Metadata != want {
+			t.Errorf("%s metadata = %+v, want %+v", rule.Name, rule.Metadata, want)
+		}
+
+		issues := []Issue{{RuleName: rule.Name}}
+		setRuleMetadata(rule, issues)
+		if issues[0].RuleMetadata != want {
+			t.Errorf("%s issue metadata = %+v, want %+v", rule.Name, issues[0].RuleMetadata, want)
+		}
+	}
+}
+
+func TestTraceRule(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: 2,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{CrashLoopThreshold: 5})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), "crash-loop-backoff", "default", "web-1")
+	if err != nil {
+		t.Fatalf("TraceRule() error = %v", err)
+	}
+	if trace.Fired || trace.Kind != "Pod" {
+		t.Errorf("trace = %+v, want a pod trace that did not fire", trace)
+	}
+
+	// Evaluation stops at the restart count below the limit
+	last := trace.Steps[len(trace.Steps)-1]
+	if last.Condition != "status.containerStatuses[*].restartCount" || last.Value != "2" || last.Expected != ">= 5" || last.Matched {
+		t.Errorf("last step = %+v, want the unmatched restart count", last)
+	}
+
+	// Tracing does not start duration-based conditions
+	if detector.State().Len() != 0 {
+		t.Errorf("tracing recorded %d conditions", detector.State().Len())
+	}
+
+	if _, err := detector.TraceRule(context.Background(), "teleport", "default", "web-1"); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("TraceRule() of an unknown rule error = %v, want ErrUnknownRule", err)
+	}
+}
+
+func TestSilenceRule(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: 10,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{CrashLoopThreshold: 5})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	if err := detector.SilenceRule("crash-loop-backoff", true); err != nil {
+		t.Fatalf("SilenceRule() error = %v", err)
+	}
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	for _, issue := range issues {
+		if issue.RuleName == "crash-loop-backoff" {
+			t.Errorf("silenced rule reported issue %+v", issue)
+		}
+	}
+	trace, err := detector.TraceRule(context.Background(), "crash-loop-backoff", "default", "web-1")
+	if err != nil {
+		t.Fatalf("TraceRule() error = %v", err)
+	}
+	if trace.Fired {
+		t.Error("trace of a silenced rule fired")
+	}
+
+	if err := detector.SilenceRule("crash-loop-backoff", false); err != nil {
+		t.Fatalf("SilenceRule() error = %v", err)
+	}
+	if detector.Silenced("crash-loop-backoff") {
+		t.Error("rule still silenced after re-enabling it")
+	}
+
+	if err := detector.SilenceRule("teleport", true); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("SilenceRule() of an unknown rule error = %v, want ErrUnknownRule", err)
+	}
+}
+
+func TestExclusions(t *testing.T) {
+	controller := true
+	canaryPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "checkout-canary-6b7c9-x2k4p",
+		Namespace:       "shop",
+		Labels:          map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: "6b7c9"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "checkout-canary-6b7c9", Controller: &controller}},
+	}}
+	jobPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "nightly-8xq2z",
+		Namespace:       "shop",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "nightly", Controller: &controller}},
+	}}
+	canary := Issue{Namespace: "shop", Kind: "Pod", Name: canaryPod.Name, Resource: canaryPod}
+	job := Issue{Namespace: "shop", Kind: "Pod", Name: jobPod.Name, Resource: jobPod}
+	deployment := Issue{Namespace: "shop", Kind: "Deployment", Name: "checkout", Resource: &appsv1.Deployment{}}
+
+	if kind, name := WorkloadOf(canary); kind != "Deployment" || name != "checkout-canary" {
+		t.Errorf("WorkloadOf() = %s/%s, want Deployment/checkout-canary", kind, name)
+	}
+	if kind, name := WorkloadOf(job); kind != "Job" || name != "nightly" {
+		t.Errorf("WorkloadOf() = %s/%s, want Job/nightly", kind, name)
+	}
+
+	exclusions := []Exclusion{
+		{Kind: "Deployment", Pattern: regexp.MustCompile(`^(?:.*-canary)$`)},
+		{Namespace: "batch", Name: "nightly"},
+	}
+	tests := []struct {
+		issue Issue
+		want  int
+	}{
+		{canary, 0},
+		{deployment, -1},
+		{job, -1},
+	}
+	for _, tt := range tests {
+		if got := Excluded(exclusions, tt.issue); got != tt.want {
+			t.Errorf("Excluded(%s) = %d, want %d", tt.issue.Name, got, tt.want)
+		}
+	}
+}
+
+func TestContainerRules(t *testing.T) {
+	oomKilled := func(name string) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:         name,
+			RestartCount: 2,
+			State:        corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			oomKilled("app"), oomKilled("istio-proxy"), oomKilled("log-shipper"),
+		}},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{
+		OOMKillThreshold: 1,
+		Containers: []ContainerRule{
+			{Name: "istio-proxy", NotifyOnly: true},
+			{Rules: []string{"crash-loop-backoff"}, Name: "app", Ignore: true},
+			{Rules: []string{"oom-kill-detected"}, Name: "app", Actions: []string{"restart-pod"}},
+			{Pattern: regexp.MustCompile(`^(?:log-.*)$`), Ignore: true},
+		},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	actions := make(map[string][]string)
+	fingerprints := make(map[string]bool)
+	for _, issue := range issues {
+		if issue.RuleName == "oom-kill-detected" {
+			actions[issue.Container] = issue.Actions
+			fingerprints[issue.Fingerprint()] = true
+		}
+	}
+	if len(actions) != 2 || len(fingerprints) != 2 {
+		t.Fatalf("issues by container = %v, want app and istio-proxy with distinct fingerprints", actions)
+	}
+	if got := actions["app"]; len(got) != 1 || got[0] != "restart-pod" {
+		t.Errorf("app actions = %v, want [restart-pod]", got)
+	}
+	if got := actions["istio-proxy"]; len(got) != 0 {
+		t.Errorf("istio-proxy actions = %v, want none", got)
+	}
+}
+
+func TestInitAndEphemeralContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "wait-for-db", Ready: true, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+				{
+					Name:                 "migrate",
+					RestartCount:         4,
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+				},
+				{Name: "warm-cache", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+			},
+			EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "debugger-old", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-2 * time.Hour))}}},
+				{Name: "debugger-new", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now())}}},
+			},
+		},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{
+		Namespaces:               map[string]NamespaceConfig{"default": {CrashLoop: CrashLoopConfig{Enabled: true}}},
+		EphemeralContainerMaxAge: time.Hour,
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		found[issue.RuleName+"/"+issue.Container] = issue
+	}
+	if len(found) != 2 {
+		t.Fatalf("issues = %v, want migrate and debugger-old", found)
+	}
+	initIssue, exists := found["init-container-failure/migrate"]
+	if !exists {
+		t.Fatalf("missing init container issue: %v", found)
+	}
+	if !strings.Contains(initIssue.Description, `init container 2/3 "migrate" failed: exit code 1 (Error)`) {
+		t.Errorf("Description = %q, want the failed init step", initIssue.Description)
+	}
+	if len(initIssue.Actions) != 0 {
+		t.Errorf("Actions = %v, want notify only", initIssue.Actions)
+	}
+	if _, exists := found["ephemeral-container-age/debugger-old"]; !exists {
+		t.Errorf("missing ephemeral container issue: %v", found)
+	}
+}
+
+func TestStuckRollouts(t *testing.T) {
+	replicas := int32(2)
+	hourAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+	deployment := func(name string, paused bool, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				UID:         types.UID(name),
+				Annotations: map[string]string{annotationRevision: "2"},
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas, Paused: paused},
+			Status: appsv1.DeploymentStatus{
+				Replicas:        2,
+				UpdatedReplicas: 1,
+				Conditions:      conditions,
+			},
+		}
+	}
+	replicaSet := func(owner *appsv1.Deployment) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              owner.Name + "-2",
+				Namespace:         "default",
+				UID:               types.UID(owner.Name + "-2"),
+				CreationTimestamp: hourAgo,
+				Annotations:       map[string]string{annotationRevision: "2"},
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+			},
+			Spec: appsv1.ReplicaSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": owner.Name}}},
+		}
+	}
+
+	paused := deployment("paused", true, appsv1.DeploymentCondition{
+		Type: appsv1.DeploymentProgressing, Status: corev1.ConditionUnknown, Reason: "DeploymentPaused", LastUpdateTime: hourAgo,
+	})
+	imagePull := deployment("image-pull", false)
+	imagePullRS := replicaSet(imagePull)
+	imagePullPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "image-pull-2-abc",
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "image-pull"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(imagePullRS, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "app",
+			Image: "web:v2",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		}}},
+	}
+	quota := deployment("quota", false, appsv1.DeploymentCondition{
+		Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Reason: "FailedCreate",
+		Message: `pods "quota-2-xyz" is forbidden: exceeded quota: compute, requested: cpu=1, used: cpu=4, limited: cpu=4`,
+	})
+	complete := deployment("complete", false)
+	complete.Status.UpdatedReplicas = 2
+	complete.Status.AvailableReplicas = 2
+
+	client := fake.NewSimpleClientset(paused, imagePull, imagePullRS, imagePullPod, quota, replicaSet(quota), complete, replicaSet(complete))
+	detector := NewDetector(client, DetectionConfig{
+		Namespaces:        map[string]NamespaceConfig{"default": {Deployment: DeploymentConfig{Enabled: true}}},
+		StuckRolloutAfter: 15 * time.Minute,
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.RuleName == "stuck-rollout" {
+			found[issue.Name] = issue
+		}
+	}
+	if len(found) != 3 {
+		t.Fatalf("stuck rollouts = %v, want paused, image-pull and quota", found)
+	}
+
+	tests := []struct {
+		name        string
+		wantReason  string
+		wantActions int
+		wantText    string
+	}{
+		{"paused", "RolloutPaused", 0, "kubectl rollout resume deployment/paused"},
+		{"image-pull", "ImagePullFailure", 1, "cannot pull image web:v2"},
+		{"quota", "InsufficientQuota", 1, "exceeded quota"},
+	}
+	for _, tt := range tests {
+		issue := found[tt.name]
+		if issue.Reason != tt.wantReason || len(issue.Actions) != tt.wantActions {
+			t.Errorf("%s: reason = %s, actions = %v, want %s with %d actions", tt.name, issue.Reason, issue.Actions, tt.wantReason, tt.wantActions)
+		}
+		if !strings.Contains(issue.Description, tt.wantText) || !strings.Contains(issue.Description, "for 1 hour") {
+			t.Errorf("%s: Description = %q, want %q and the rollout age", tt.name, issue.Description, tt.wantText)
+		}
+	}
+}
+
+func TestStuckFinalizers(t *testing.T) {
+	const lbFinalizer = "service.kubernetes.io/load-balancer-cleanup"
+	service := func(name string, deletedAgo time.Duration, finalizers ...string) *corev1.Service {
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Finalizers: finalizers}}
+		if deletedAgo > 0 {
+			deleted := metav1.NewTime(time.Now().Add(-deletedAgo))
+			svc.DeletionTimestamp = &deleted
+		}
+		return svc
+	}
+
+	client := fake.NewSimpleClientset(
+		service("stuck", time.Hour, "example.com/protect", lbFinalizer),
+		service("recent", time.Minute, lbFinalizer),
+		service("other", time.Hour, "example.com/protect"),
+		service("live", 0, lbFinalizer),
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: "claim", Namespace: "default", Finalizers: []string{lbFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+		}},
+	)
+	detector := NewDetector(client, DetectionConfig{
+		Finalizers:          []AllowedFinalizer{{Name: lbFinalizer, Kinds: []string{"Service"}}},
+		StuckFinalizerAfter: 30 * time.Minute,
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	var stuck []Issue
+	for _, issue := range issues {
+		if issue.RuleName == StuckFinalizerRule {
+			stuck = append(stuck, issue)
+		}
+	}
+	if len(stuck) != 1 || stuck[0].Name != "stuck" || stuck[0].Kind != "Service" {
+		t.Fatalf("stuck finalizer issues = %v, want Service stuck", stuck)
+	}
+	issue := stuck[0]
+	if issue.Reason != "StuckFinalizer" || len(issue.Actions) != 1 || issue.Actions[0] != RemoveFinalizerAction {
+		t.Errorf("reason = %s, actions = %v, want StuckFinalizer with %s", issue.Reason, issue.Actions, RemoveFinalizerAction)
+	}
+	if !strings.Contains(issue.Description, "allow-listed for removal: "+lbFinalizer) || !strings.Contains(issue.Description, "for 1 hour") {
+		t.Errorf("Description = %q, want the allowed finalizer and the deletion age", issue.Description)
+	}
+}
+
+func TestNodeProblems(t *testing.T) {
+	now := time.Now()
+	condition := func(conditionType string, status corev1.ConditionStatus, since time.Duration) corev1.NodeCondition {
+		return corev1.NodeCondition{
+			Type:               corev1.NodeConditionType(conditionType),
+			Status:             status,
+			Reason:             conditionType,
+			Message:            "reported by node-problem-detector",
+			LastTransitionTime: metav1.NewTime(now.Add(-since)),
+		}
+	}
+	node := func(name string, conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{Conditions: conditions}}
+	}
+	event := func(name, node, reason string, ago time.Duration, count int32) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: node},
+			Reason:         reason,
+			Message:        "kernel: BUG: unable to handle page fault",
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(now.Add(-ago)),
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		node("deadlocked", condition("KernelDeadlock", corev1.ConditionTrue, time.Minute)),
+		node("restarting", condition("FrequentKubeletRestart", corev1.ConditionTrue, time.Minute)),
+		node("healthy", condition("KernelDeadlock", corev1.ConditionFalse, time.Hour)),
+		event("oops-1", "healthy", "KernelOops", 10*time.Minute, 2),
+		event("oops-2", "restarting", "KernelOops", 2*time.Hour, 1),
+	)
+	detector := NewDetector(client, DetectionConfig{
+		NodeProblems: NodeProblems{
+			Conditions: []NodeProblem{
+				{Type: "KernelDeadlock", Severity: "critical", Actions: []string{CordonNodeAction}},
+				{Type: "FrequentKubeletRestart", For: 10 * time.Minute},
+			},
+			Events: []NodeProblem{{Type: "KernelOops", Severity: "medium", For: time.Hour}},
+		},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.RuleName == NodeProblemRule {
+			found[issue.Name+"/"+issue.Reason] = issue
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("node problems = %v, want deadlocked/KernelDeadlock and healthy/KernelOops", found)
+	}
+
+	deadlock := found["deadlocked/KernelDeadlock"]
+	if deadlock.Kind != "Node" || deadlock.Severity != "critical" || len(deadlock.Actions) != 1 || deadlock.Actions[0] != CordonNodeAction {
+		t.Errorf("KernelDeadlock issue = %+v, want a critical Node issue with %s", deadlock, CordonNodeAction)
+	}
+	oops := found["healthy/KernelOops"]
+	if oops.Severity != "medium" || len(oops.Actions) != 0 || !strings.Contains(oops.Description, "seen 2 times") {
+		t.Errorf("KernelOops issue = %+v, want a medium notify-only issue seen 2 times", oops)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), NodeProblemRule, "", "restarting")
+	if err != nil {
+		t.Fatalf("TraceRule() error = %v", err)
+	}
+	if trace.Fired || trace.Kind != "Node" {
+		t.Errorf("trace of restarting = %+v, want a Node trace that does not fire before 10m", trace)
+	}
+}
+
+func TestNodeConditions(t *testing.T) {
+	now := time.Now()
+	condition := func(conditionType corev1.NodeConditionType, status corev1.ConditionStatus, since time.Duration) corev1.NodeCondition {
+		return corev1.NodeCondition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             "KubeletReport",
+			Message:            "reported by the kubelet",
+			LastTransitionTime: metav1.NewTime(now.Add(-since)),
+		}
+	}
+	node := func(name string, conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{Conditions: conditions}}
+	}
+
+	client := fake.NewSimpleClientset(
+		node("lost", condition(corev1.NodeReady, corev1.ConditionUnknown, 10*time.Minute)),
+		node("flapping", condition(corev1.NodeReady, corev1.ConditionFalse, time.Minute)),
+		node("full", condition(corev1.NodeReady, corev1.ConditionTrue, time.Hour), condition(corev1.NodeDiskPressure, corev1.ConditionTrue, 6*time.Minute)),
+		node("healthy", condition(corev1.NodeReady, corev1.ConditionTrue, time.Hour), condition(corev1.NodeMemoryPressure, corev1.ConditionFalse, time.Hour)),
+	)
+	detector := NewDetector(client, DetectionConfig{
+		NodeConditions: []NodeProblem{
+			{Type: "Ready", Severity: "critical", Actions: []string{CordonNodeAction}, For: 5 * time.Minute},
+			{Type: "DiskPressure", For: 5 * time.Minute},
+			{Type: "MemoryPressure", For: 5 * time.Minute},
+		},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.RuleName == NodeConditionRule {
+			found[issue.Name+"/"+issue.Reason] = issue
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("node conditions = %v, want lost/NotReady and full/DiskPressure", found)
+	}
+
+	lost := found["lost/NotReady"]
+	if lost.Kind != "Node" || lost.Severity != "critical" || len(lost.Actions) != 1 || !strings.Contains(lost.Description, "Ready is Unknown for 10 minutes") {
+		t.Errorf("NotReady issue = %+v, want a critical Node issue unknown for 10 minutes", lost)
+	}
+	if full := found["full/DiskPressure"]; full.Severity != "high" || len(full.Actions) != 0 {
+		t.Errorf("DiskPressure issue = %+v, want a notify-only issue with the rule's severity", full)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), NodeConditionRule, "", "flapping")
+	if err != nil {
+		t.Fatalf("TraceRule() error = %v", err)
+	}
+	if trace.Fired || trace.Kind != "Node" {
+		t.Errorf("trace of flapping = %+v, want a Node trace that does not fire before 5m", trace)
+	}
+}
+
+func TestListPodsPaged(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	client := fake.NewSimpleClientset()
+	var limits []int64
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		opts := action.(k8stesting.ListActionImpl).GetListOptions()
+		limits = append(limits, opts.Limit)
+
+		start := 0
+		if opts.Continue != "" {
+			start = int(opts.Continue[0] - '0')
+		}
+		end := len(names)
+		if opts.Limit > 0 {
+			end = min(start+int(opts.Limit), len(names))
+		}
+		list := &corev1.PodList{}
+		for _, name := range names[start:end] {
+			list.Items = append(list.Items, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		if end < len(names) {
+			list.Continue = string(rune('0' + end))
+		}
+		return true, list, nil
+	})
+
+	tests := []struct {
+		pageSize  int
+		wantPages int
+	}{
+		{pageSize: 0, wantPages: 1},
+		{pageSize: 2, wantPages: 3},
+		{pageSize: 5, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		limits = nil
+		detector := NewDetector(client, DetectionConfig{})
+		detector.SetPageSize(tt.pageSize)
+
+		pods, err := detector.listPods(context.Background())
+		if err != nil {
+			t.Fatalf("page size %d: listPods() error = %v", tt.pageSize, err)
+		}
+		if len(pods.Items) != len(names) {
+			t.Errorf("page size %d: listed %d pods, want %d", tt.pageSize, len(pods.Items), len(names))
+		}
+		if len(limits) != tt.wantPages {
+			t.Errorf("page size %d: listed %d pages, want %d", tt.pageSize, len(limits), tt.wantPages)
+		}
+		for _, limit := range limits {
+			if limit != int64(tt.pageSize) {
+				t.Errorf("page size %d: list limit = %d", tt.pageSize, limit)
+			}
+		}
+	}
+}
+
+func TestDetectObjectFromInformers(t *testing.T) {
+	crashing := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: 7,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+	healthy := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}}
+	client := fake.NewSimpleClientset(crashing, healthy)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector := NewDetector(client, DetectionConfig{CrashLoopThreshold: 5, Clock: clock})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	rules := detector.ObjectRules(KindPod)
+	if !slices.Contains(rules, "crash-loop-backoff") || slices.Contains(rules, "failed-deployment") {
+		t.Errorf("ObjectRules(Pod) = %v", rules)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	factory := informers.NewSharedInformerFactory(client, 0)
+	detector.SetInformers(factory)
+	if _, err := detector.DetectObject(ctx, KindPod, "default", "web-1"); err == nil {
+		t.Error("DetectObject() before the caches synced succeeded")
+	}
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+
+	// Pods are listed from the cache once it has synced
+	listed := 0
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		listed++
+		return false, nil, nil
+	})
+	pods, err := detector.listPods(ctx)
+	if err != nil {
+		t.Fatalf("listPods() error = %v", err)
+	}
+	if len(pods.Items) != 2 || listed != 0 {
+		t.Errorf("listPods() listed %d pods with %d requests, want 2 from the cache", len(pods.Items), listed)
+	}
+
+	// The crash loop must hold for the default check duration of one minute
+	for _, step := range []struct {
+		advance time.Duration
+		want    int
+	}{
+		{advance: 0, want: 0},
+		{advance: time.Minute, want: 1},
+	} {
+		clock.SetTime(clock.Now().Add(step.advance))
+		issues, err := detector.DetectObject(ctx, KindPod, "default", "web-1")
+		if err != nil {
+			t.Fatalf("DetectObject() error = %v", err)
+		}
+		if len(issues) != step.want {
+			t.Fatalf("after %s: %d issues, want %d", step.advance, len(issues), step.want)
+		}
+		if step.want > 0 && (issues[0].RuleName != "crash-loop-backoff" || issues[0].Name != "web-1") {
+			t.Errorf("DetectObject() issue = %+v", issues[0])
+		}
+	}
+
+	for _, name := range []string{"web-2", "deleted"} {
+		issues, err := detector.DetectObject(ctx, KindPod, "default", name)
+		if err != nil || len(issues) != 0 {
+			t.Errorf("DetectObject(%s) = %v, %v, want no issues", name, issues, err)
+		}
+	}
+	if _, err := detector.DetectObject(ctx, "Node", "", "node-1"); err == nil {
+		t.Error("DetectObject() of a node succeeded")
+	}
+}
+
+func TestCrashLoopCheckDuration(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "app",
+			RestartCount: 7,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{CrashLoopThreshold: 5, Clock: clock})
+	rule := Rule{Name: "crash-loop-backoff", Severity: "high", Enabled: true}
+
+	// The condition must hold for the default check duration of one minute
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{advance: 0, want: 0},
+		{advance: 59 * time.Second, want: 0},
+		{advance: time.Second, want: 1},
+	}
+	for _, step := range steps {
+		clock.SetTime(clock.Now().Add(step.advance))
+		issues := detector.checkCrashLoopBackOff(context.Background(), rule, pod, nil)
+		if len(issues) != step.want {
+			t.Errorf("after %s: %d issues, want %d", step.advance, len(issues), step.want)
+		}
+	}
+}
+
+func TestMetricsServerUsage(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}},
+			{Name: "proxy", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+			}},
+		}},
+		// Restarts that trigger the heuristics if metrics-server is unavailable
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:                 "app",
+			RestartCount:         10,
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(time.Now().Add(-time.Hour))}},
+		}}},
+	}
+	podMetrics := &metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("950m"), corev1.ResourceMemory: resource.MustParse("256Mi")}},
+			{Name: "proxy", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("20m"), corev1.ResourceMemory: resource.MustParse("60Mi")}},
+		},
+	}
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	nsConfig := NamespaceConfig{
+		CPU:    CPUConfig{ThresholdPercent: 80, CheckDuration: time.Minute, Enabled: true},
+		Memory: MemoryConfig{ThresholdPercent: 90, CheckDuration: time.Minute, Enabled: true},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(pod), DetectionConfig{Clock: clock, Namespaces: map[string]NamespaceConfig{"default": nsConfig}})
+	// The object tracker of the fake clientset does not map PodMetrics to the
+	// pods resource of metrics.k8s.io, so lists are served by a reactor
+	var listErr error
+	lists := 0
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		if listErr != nil {
+			return true, nil, listErr
+		}
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{*podMetrics}}, nil
+	})
+	detector.SetMetricsClient(metricsClient, time.Minute)
+	cpuRule := Rule{Name: "high-cpu-usage", Severity: "medium", Enabled: true}
+	memoryRule := Rule{Name: "high-memory-usage", Severity: "medium", Enabled: true}
+
+	// Usage must stay above the threshold for the check duration
+	if issues := detector.checkHighCPUUsage(context.Background(), cpuRule, pod, nil); len(issues) != 0 {
+		t.Fatalf("expected no issue before the check duration, got %+v", issues)
+	}
+	clock.SetTime(clock.Now().Add(time.Minute))
+	issues := detector.checkHighCPUUsage(context.Background(), cpuRule, pod, nil)
+	if len(issues) != 1 || issues[0].Container != "app" || issues[0].Reason != "HighCPU" {
+		t.Fatalf("expected high CPU of app against its limit, got %+v", issues)
+	}
+	if !strings.Contains(issues[0].Description, "95.0% of limit 1") {
+		t.Errorf("description = %q, want the usage of the limit", issues[0].Description)
+	}
+
+	// proxy has no memory limit, its usage is compared against its request
+	clock.SetTime(clock.Now().Add(usageMaxAge))
+	detector.checkHighMemoryUsage(context.Background(), memoryRule, pod, nil)
+	clock.SetTime(clock.Now().Add(time.Minute))
+	issues = detector.checkHighMemoryUsage(context.Background(), memoryRule, pod, nil)
+	if len(issues) != 1 || issues[0].Container != "proxy" || issues[0].Reason != "HighMemory" {
+		t.Fatalf("expected high memory of proxy against its request, got %+v", issues)
+	}
+
+	trace := &Trace{}
+	detector.checkHighCPUUsage(context.Background(), cpuRule, pod, trace)
+	if trace.Steps[1].Value != "metrics-server" {
+		t.Errorf("usage source = %q, want metrics-server", trace.Steps[1].Value)
+	}
+
+	// Without metrics-server the rules fall back to the heuristics and retry later
+	listErr = errors.New("the server could not find the requested resource")
+	lists = 0
+	clock.SetTime(clock.Now().Add(usageMaxAge))
+	issues = detector.checkHighCPUUsage(context.Background(), cpuRule, pod, nil)
+	if len(issues) != 1 || !strings.Contains(issues[0].Description, "restarts: 10") {
+		t.Errorf("expected the restart heuristic, got %+v", issues)
+	}
+	detector.checkHighMemoryUsage(context.Background(), memoryRule, pod, nil)
+	if lists != 1 {
+		t.Errorf("metrics listed %d times, want one until the retry interval", lists)
+	}
+	clock.SetTime(clock.Now().Add(time.Minute))
+	detector.checkHighCPUUsage(context.Background(), cpuRule, pod, nil)
+	if lists != 2 {
+		t.Errorf("metrics listed %d times, want a retry after the retry interval", lists)
+	}
+}
+
+func TestQueryRules(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		if r.URL.Path != "/api/v1/query" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected request"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"shop","deployment":"checkout"},"value":[1700000000,"0.08"]},
+			{"metric":{"namespace":"shop","deployment":"cart"},"value":[1700000000,"0.01"]},
+			{"metric":{"namespace":"shop","deployment":"deleted"},"value":[1700000000,"0.5"]},
+			{"metric":{"deployment":"unlabelled"},"value":[1700000000,"0.9"]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "shop"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"}},
+	)
+	rule := Rule{
+		Name:        "checkout-error-rate",
+		Description: "Error rate above SLO",
+		Enabled:     true,
+		Severity:    "high",
+		Actions:     []string{"rollback-deployment"},
+		Conditions: []RuleCondition{{
+			Resource: "Deployment",
+			Query:    `sum by (namespace, deployment) (rate(http_requests_total{code=~"5.."}[5m]))`,
+			Operator: OperatorGreaterThan,
+			Value:    0.05,
+			Duration: &metav1.Duration{Duration: 2 * time.Minute},
+		}},
+	}
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector := NewDetector(client, DetectionConfig{
+		Clock:      clock,
+		Prometheus: NewPrometheusClient(server.URL, "secret", time.Second),
+		QueryRules: []Rule{rule},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+
+	// The threshold must be crossed for the condition duration
+	issues, err := detector.evaluateRule(context.Background(), rule)
+	if err != nil || len(issues) != 0 {
+		t.Fatalf("expected no issue before the duration, got %+v (%v)", issues, err)
+	}
+	clock.SetTime(clock.Now().Add(2 * time.Minute))
+	issues, err = detector.evaluateRule(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "checkout" || issues[0].Kind != "Deployment" || issues[0].Reason != "QueryThreshold" {
+		t.Fatalf("expected an issue for checkout, got %+v", issues)
+	}
+	if _, ok := issues[0].Resource.(*appsv1.Deployment); !ok {
+		t.Errorf("resource = %T, want the deployment for remediation", issues[0].Resource)
+	}
+	if !strings.Contains(issues[0].Description, "value: 0.08, threshold: greater_than 0.05") {
+		t.Errorf("description = %q", issues[0].Description)
+	}
+	if queries[0] != rule.Conditions[0].Query {
+		t.Errorf("query = %q", queries[0])
+	}
+
+	trace, err := detector.TraceRule(context.Background(), rule.Name, "shop", "cart")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if trace.Fired || trace.Kind != "Deployment" || trace.Steps[len(trace.Steps)-1].Value != "0.01" {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	// A rule's own endpoint overrides the configured server
+	rule.Conditions[0].Endpoint = server.URL + "/unknown"
+	if _, err := detector.evaluateRule(context.Background(), rule); err == nil || !strings.Contains(err.Error(), "bad_data") {
+		t.Errorf("expected the error of the endpoint, got %v", err)
+	}
+
+	builtin := rule
+	builtin.Name = "crash-loop-backoff"
+	detector = NewDetector(client, DetectionConfig{QueryRules: []Rule{builtin}})
+	if err := detector.LoadRules(); err == nil {
+		t.Errorf("expected a query rule named like a built-in rule to be rejected")
+	}
+}
+
+func TestRulesFile(t *testing.T) {
+	// The shipped rules file loads
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: "../../configs/rules.yaml"})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+	rules := make(map[string]Rule)
+	for _, rule := range detector.Rules() {
+		rules[rule.Name] = rule
+	}
+	if rule := rules["crash-loop-backoff"]; rule.Labels["category"] != "pod-health" || rule.Metadata.Source != RuleSourceBuiltin {
+		t.Errorf("built-in rule not modified by the file: %+v", rule)
+	}
+	if rule := rules["node-not-ready"]; !rule.isFieldRule() || len(rule.Actions) != 0 || rule.Metadata.Source != RuleSourceFile {
+		t.Errorf("custom rule not loaded: %+v", rule)
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`rules:
+  - name: crash-loop-backoff
+    enabled: false
+  - name: ephemeral-container-age
+    enabled: true
+  - name: node-not-ready
+    severity: high
+    actions: [notify-only]
+    conditions:
+      - {resource: Node, field: "status.conditions[*].type", operator: equals, value: Ready}
+      - {resource: Node, field: "status.conditions[*].status", operator: equals, value: "False", duration: 5m}
+  - name: restarting
+    severity: medium
+    actions: [restart-pod]
+    conditions:
+      - {resource: Pod, field: status.phase, operator: equals, value: Running}
+      - {resource: Pod, field: "status.containerStatuses[*].restartCount", operator: greater_than, value: 5}
+`)
+
+	notReady := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		}},
+	}
+	// A False condition other than Ready must not match the Ready condition
+	ready := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	restarting := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "sidecar", RestartCount: 0},
+			{Name: "app", RestartCount: 7},
+		}},
+	}
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector = NewDetector(fake.NewSimpleClientset(notReady, ready, restarting), DetectionConfig{RulesFile: path, Clock: clock})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+	rules = make(map[string]Rule)
+	for _, rule := range detector.Rules() {
+		rules[rule.Name] = rule
+	}
+	if rules["crash-loop-backoff"].Enabled || rules["ephemeral-container-age"].Enabled {
+		t.Errorf("expected crash-loop-backoff disabled and ephemeral-container-age to stay disabled without a maximum age")
+	}
+
+	issues, err := detector.evaluateRule(context.Background(), rules["restarting"])
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "web-1" || issues[0].Reason != "FieldConditions" || issues[0].Actions[0] != "restart-pod" {
+		t.Fatalf("expected an issue for web-1, got %+v", issues)
+	}
+	if _, ok := issues[0].Resource.(*corev1.Pod); !ok {
+		t.Errorf("resource = %T, want the pod for remediation", issues[0].Resource)
+	}
+
+	// Node conditions must hold for their duration
+	if issues, _ := detector.evaluateRule(context.Background(), rules["node-not-ready"]); len(issues) != 0 {
+		t.Fatalf("expected no issue before the duration, got %+v", issues)
+	}
+	clock.SetTime(clock.Now().Add(5 * time.Minute))
+	issues, err = detector.evaluateRule(context.Background(), rules["node-not-ready"])
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "node-a" || issues[0].Kind != "Node" {
+		t.Fatalf("expected an issue for node-a, got %+v", issues)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), "node-not-ready", "", "node-b")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if trace.Fired || len(trace.Steps) != 3 || trace.Steps[2].Value != "0 of 2 elements" {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	for content, want := range map[string]string{
+		"rules:\n  - name: x\n    severity: low\n    actions: [reboot]\n    conditions: [{resource: Pod, field: status.phase, operator: equals, value: Failed}]\n":                              "unknown action",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Pod, field: status.phase, operator: like, value: Failed}]\n":                                                       "unsupported operator",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Pod, field: a, operator: equals, value: b}, {resource: Node, field: a, operator: equals, value: b}]\n":             "same resource",
+		"rules:\n  - name: x\n    severity: low\n    actions: [restart-pod]\n    conditions: [{resource: Node, field: a, operator: equals, value: b}]\n":                                        "cannot have actions",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Pod, field: a, operator: greater_than, value: many}]\n":                                                            "needs a number",
+		"rules:\n  - name: x\n    severity: low\n    condition: []\n":                                                                                                                           "field condition not found",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Pod, field: metadata.name, operator: regex, value: \"web-(\"}]\n":                                                  "invalid regex",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Pod, field: \"spec.containers[first].image\", operator: equals, value: b}]\n":                                      "invalid index",
+		"rules:\n  - name: x\n    severity: low\n    conditions: [{resource: Certificate, field: a, operator: equals, value: b}]\n":                                                             "needs an apiVersion",
+		"rules:\n  - name: x\n    severity: low\n    actions: [restart-pod]\n    conditions: [{resource: Certificate, apiVersion: cert-manager.io/v1, field: a, operator: equals, value: b}]\n": "cannot have actions",
+	} {
+		write(content)
+		detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: path})
+		if err := detector.LoadRules(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadRules(%q) error = %v, want %q", content, err, want)
+		}
+	}
+
+	// Without a rules file only the built-in rules are loaded: the pod and
+	// deployment rules, the stuck finalizer rule, the node problem and node
+	// condition rules, the scheduling conflict rule and the evicted pods rule
+	detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: filepath.Join(t.TempDir(), "missing.yaml")})
+	if err := detector.LoadRules(); err != nil || len(detector.Rules()) != len(podChecks)+len(deploymentChecks)+8 {
+		t.Errorf("LoadRules() without a rules file loaded %d rules (%v)", len(detector.Rules()), err)
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	for path, want := range map[string]string{
+		"status.phase":                              "status.phase",
+		"{.status.containerStatuses[*].ready}":      "status.containerStatuses[*].ready",
+		".spec.containers[0].image":                 "spec.containers[0].image",
+		"metadata.labels['app.kubernetes.io/name']": "metadata.labels['app.kubernetes.io/name']",
+		"status..phase":                             "empty field",
+		"status.conditions[*":                       "unterminated",
+		"spec.containers[-1]":                       "invalid index",
+		"spec.containers[*][0]":                     "indexed twice",
+		"[*].name":                                  "without a field",
+	} {
+		parsed, err := parseFieldPath(path)
+		if err != nil {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("parseFieldPath(%q) error = %v, want %q", path, err, want)
+			}
+			continue
+		}
+		if parsed.String() != want {
+			t.Errorf("parseFieldPath(%q) = %s, want %s", path, parsed, want)
+		}
+	}
+
+	pod := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "web-7d4b9",
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "registry.example.com/web:1.2", "args": []interface{}{"--debug", "--port=8080"}},
+				map[string]interface{}{"name": "proxy", "image": "envoy:latest"},
+			},
+		},
+		"status": map[string]interface{}{
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "restartCount": int64(3), "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}},
+				map[string]interface{}{"name": "proxy", "restartCount": int64(0), "state": map[string]interface{}{"running": map[string]interface{}{}}},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		field    string
+		operator string
+		value    interface{}
+		want     bool
+	}{
+		{"status.containerStatuses[*].state.waiting.reason", OperatorEquals, "CrashLoopBackOff", true},
+		{"status.containerStatuses[1].state.waiting.reason", OperatorEquals, "CrashLoopBackOff", false},
+		{"metadata.labels['app.kubernetes.io/name']", OperatorEquals, "web", true},
+		{"metadata.labels['app.kubernetes.io/name']", OperatorNotEquals, "web", false},
+		{"metadata.labels.team", OperatorNotEquals, "payments", true},
+		{"spec.containers[*].image", OperatorNotEquals, "envoy:latest", true},
+		{"spec.containers[1].image", OperatorNotEquals, "envoy:latest", false},
+		{"spec.containers[*].image", OperatorContains, "registry.example.com/", true},
+		{"spec.containers[0].args", OperatorContains, "--debug", true},
+		{"spec.containers[0].args", OperatorContains, "--deb", false},
+		{"spec.containers[*].image", OperatorRegex, ":latest$", true},
+		{"metadata.name", OperatorRegex, "^api-", false},
+		{"status.containerStatuses[*].restartCount", OperatorGreaterThan, 2, true},
+		{"status.containerStatuses[*].restartCount", OperatorLessThan, 0, false},
+	} {
+		rule := Rule{Name: "x", Conditions: []RuleCondition{{Resource: "Pod", Field: tc.field, Operator: tc.operator, Value: tc.value}}}
+		conditions, err := compileFieldConditions(rule)
+		if err != nil {
+			t.Fatalf("compileFieldConditions(%s %s) failed: %v", tc.field, tc.operator, err)
+		}
+		if got := matchFields(pod, conditions, nil); got != tc.want {
+			t.Errorf("%s %s %v = %v, want %v", tc.field, tc.operator, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestFieldRuleDynamicResource(t *testing.T) {
+	certificate := func(name, ready string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "shop"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": ready, "reason": "Expired"},
+				},
+			},
+		}}
+	}
+	gvr := schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CertificateList"},
+		certificate("web-tls", "False"), certificate("api-tls", "True"))
+
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "certificates", Kind: "Certificate", Namespaced: true},
+			{Name: "certificates/status", Kind: "Certificate", Namespaced: true},
+		},
+	}}
+	rule := Rule{
+		Name:     "certificate-not-ready",
+		Enabled:  true,
+		Severity: "high",
+		Conditions: []RuleCondition{
+			{Resource: "Certificate", APIVersion: "cert-manager.io/v1", Field: "status.conditions[*].type", Operator: OperatorEquals, Value: "Ready"},
+			{Resource: "Certificate", APIVersion: "cert-manager.io/v1", Field: "status.conditions[*].status", Operator: OperatorNotEquals, Value: "True"},
+		},
+	}
+
+	detector := NewDetector(client, DetectionConfig{})
+	if _, err := detector.evaluateRule(context.Background(), rule); !errors.Is(err, ErrNoDynamicClient) {
+		t.Fatalf("evaluateRule() without a dynamic client error = %v, want %v", err, ErrNoDynamicClient)
+	}
+
+	detector.SetDynamicClient(dynamicClient)
+	issues, err := detector.evaluateRule(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "web-tls" || issues[0].Namespace != "shop" || issues[0].Kind != "Certificate" {
+		t.Fatalf("expected an issue for web-tls, got %+v", issues)
+	}
+
+	detector.rules = append(detector.rules, rule)
+	trace, err := detector.TraceRule(context.Background(), rule.Name, "shop", "api-tls")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if trace.Fired || len(trace.Steps) != 3 || trace.Steps[2].Value != "0 of 1 elements" {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	rule.Conditions[0].APIVersion, rule.Conditions[1].APIVersion = "example.com/v1", "example.com/v1"
+	if _, err := detector.evaluateRule(context.Background(), rule); err == nil || !strings.Contains(err.Error(), "example.com/v1") {
+		t.Errorf("evaluateRule() with an unknown apiVersion error = %v", err)
+	}
+}
+
+func TestExprConditions(t *testing.T) {
+	newPod := func(name string, labels map[string]string, restarts int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", RestartCount: restarts},
+			}},
+		}
+	}
+	critical := newPod("checkout-1", map[string]string{"tier": "critical"}, 7)
+	batch := newPod("report-1", map[string]string{"tier": "batch"}, 7)
+	unlabeled := newPod("debug-1", nil, 7)
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `rules:
+  - name: critical-restarts
+    severity: high
+    actions: [restart-pod]
+    conditions:
+      - resource: Pod
+        expr: "pod.status.containerStatuses.exists(c, c.restartCount > 5) && pod.metadata.labels['tier'] == 'critical'"
+  - name: memory-hungry
+    severity: medium
+    conditions:
+      - resource: Pod
+        field: status.phase
+        operator: equals
+        value: Running
+      - resource: Pod
+        expr: "has(metrics.containers) && metrics.containers['app'].memory > 209715200"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	detector := NewDetector(fake.NewSimpleClientset(critical, batch, unlabeled), DetectionConfig{RulesFile: path})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+	rules := make(map[string]Rule)
+	for _, rule := range detector.Rules() {
+		rules[rule.Name] = rule
+	}
+
+	// A missing label fails the expression of the unlabeled pod without failing the rule
+	issues, err := detector.evaluateRule(context.Background(), rules["critical-restarts"])
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "checkout-1" || issues[0].Actions[0] != "restart-pod" {
+		t.Fatalf("expected an issue for checkout-1, got %+v", issues)
+	}
+	trace, err := detector.TraceRule(context.Background(), "critical-restarts", "shop", "debug-1")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if last := trace.Steps[len(trace.Steps)-1]; trace.Fired || last.Matched || !strings.Contains(fmt.Sprint(last.Value), "no such key") {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	// Without metrics-server the metrics are empty
+	if issues, err := detector.evaluateRule(context.Background(), rules["memory-hungry"]); err != nil || len(issues) != 0 {
+		t.Fatalf("expected no issues without metrics, got %+v (%v)", issues, err)
+	}
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "report-1", Namespace: "shop"},
+				Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "checkout-1", Namespace: "shop"},
+				Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}}},
+			},
+		}}, nil
+	})
+	detector.SetMetricsClient(metricsClient, time.Minute)
+	issues, err = detector.evaluateRule(context.Background(), rules["memory-hungry"])
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Name != "report-1" {
+		t.Fatalf("expected an issue for report-1, got %+v", issues)
+	}
+
+	for expr, want := range map[string]string{
+		"pod.metadata.name ==":                "invalid expression",
+		"pod.status.containerStatuses.size()": "must return a bool",
+		"deployment.spec.replicas > 1":        "undeclared reference",
+		"object.metadata.name == 'web'x":      "invalid expression",
+	} {
+		rule := Rule{Name: "x", Conditions: []RuleCondition{{Resource: "Pod", Expr: expr}}}
+		if _, err := compileFieldConditions(rule); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("compileFieldConditions(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+	rule := Rule{Name: "x", Conditions: []RuleCondition{{Resource: "Pod", Expr: "true", Field: "status.phase"}}}
+	if _, err := compileFieldConditions(rule); err == nil || !strings.Contains(err.Error(), "cannot have a field") {
+		t.Errorf("compileFieldConditions() with an expression and a field error = %v", err)
+	}
+}
+
+func TestStatusConditionsRule(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	kafka := func(name, team string, conditions ...map[string]interface{}) *unstructured.Unstructured {
+		list := make([]interface{}, 0, len(conditions))
+		for _, condition := range conditions {
+			list = append(list, condition)
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kafka.strimzi.io/v1beta2",
+			"kind":       "Kafka",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "streaming", "labels": map[string]interface{}{"team": team}},
+			"status":     map[string]interface{}{"conditions": list},
+		}}
+	}
+	falseSince := func(conditionType string, since time.Duration) map[string]interface{} {
+		return map[string]interface{}{
+			"type":               conditionType,
+			"status":             "False",
+			"reason":             "BrokerUnavailable",
+			"message":            "2 of 3 brokers are not ready",
+			"lastTransitionTime": clock.Now().Add(-since).UTC().Format(time.RFC3339),
+		}
+	}
+	gvr := schema.GroupVersionResource{Group: "kafka.strimzi.io", Version: "v1beta2", Resource: "kafkas"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "KafkaList"},
+		kafka("orders", "payments", falseSince("Ready", 30*time.Minute)),
+		kafka("refunds", "payments", falseSince("Ready", time.Minute)),
+		kafka("ledger", "payments", map[string]interface{}{"type": "Ready", "status": "True"}),
+		kafka("audit", "payments", map[string]interface{}{"type": "Available", "status": "False"}),
+		kafka("search", "search", falseSince("Ready", time.Hour)),
+	)
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "kafka.strimzi.io/v1beta2",
+		APIResources: []metav1.APIResource{{Name: "kafkas", Kind: "Kafka", Namespaced: true}},
+	}}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`rules:
+  - name: kafka-unhealthy
+    description: Kafka cluster unhealthy
+    severity: high
+    actions: [notify-only]
+    statusConditions:
+      apiVersion: kafka.strimzi.io/v1beta2
+      kind: Kafka
+      selector: team=payments
+      duration: 10m
+`)
+	detector := NewDetector(client, DetectionConfig{RulesFile: path, Clock: clock})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+	detector.SetDynamicClient(dynamicClient)
+	rule := detector.Rules()[len(detector.Rules())-1]
+	if rule.StatusConditions == nil || len(rule.Actions) != 0 || rule.Metadata.Source != RuleSourceFile {
+		t.Fatalf("status conditions rule not loaded: %+v", rule)
+	}
+
+	// Conditions with a lastTransitionTime are judged by it, others from when they
+	// were first observed
+	names := func(issues []Issue) []string {
+		var names []string
+		for _, issue := range issues {
+			names = append(names, issue.Name+"/"+issue.Reason)
+		}
+		return names
+	}
+	issues, err := detector.evaluateRule(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("evaluateRule() failed: %v", err)
+	}
+	if got := names(issues); len(got) != 1 || got[0] != "orders/ReadyFalse" {
+		t.Fatalf("issues = %v, want [orders/ReadyFalse]", got)
+	}
+	if !strings.Contains(issues[0].Description, "BrokerUnavailable: 2 of 3 brokers are not ready") || issues[0].Kind != "Kafka" {
+		t.Errorf("unexpected issue: %+v", issues[0])
+	}
+	clock.SetTime(clock.Now().Add(10 * time.Minute))
+	issues, _ = detector.evaluateRule(context.Background(), rule)
+	if got := names(issues); len(got) != 3 || got[0] != "audit/AvailableFalse" {
+		t.Errorf("issues after 10m = %v, want audit, orders and refunds", got)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), "kafka-unhealthy", "streaming", "search")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if trace.Fired || trace.Steps[len(trace.Steps)-1].Condition != "labels" {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	for content, want := range map[string]string{
+		"rules:\n  - name: x\n    severity: low\n    statusConditions: {kind: Kafka}\n":                                                                  "apiVersion and a kind",
+		"rules:\n  - name: x\n    severity: low\n    statusConditions: {apiVersion: v1, kind: Kafka, selector: \"team in (a\"}\n":                        "invalid selector",
+		"rules:\n  - name: x\n    severity: low\n    actions: [restart-pod]\n    statusConditions: {apiVersion: v1, kind: Kafka}\n":                      "cannot have actions",
+		"rules:\n  - name: x\n    severity: low\n    statusConditions: {apiVersion: v1, kind: Kafka, duration: soon}\n":                                  "invalid duration",
+		"rules:\n  - name: x\n    severity: low\n    statusConditions: {apiVersion: v1, kind: Kafka}\n    conditions: [{resource: Pod, expr: 'true'}]\n": "cannot be combined",
+	} {
+		write(content)
+		detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: path})
+		if err := detector.LoadRules(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadRules(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+// fakeProber fails the probes of the targets in failing
+type fakeProber struct {
+	failing map[string]bool
+	probed  int
+}
+
+func (p *fakeProber) Probe(_ context.Context, check ProbeCheck) error {
+	p.probed++
+	if p.failing[check.Target] {
+		return fmt.Errorf("lookup %s: no such host", check.Target)
+	}
+	return nil
+}
+
+func TestProbeRule(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	prober := &fakeProber{failing: map[string]bool{"api.payments.svc.cluster.local": true}}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`rules:
+  - name: payments-dns
+    description: Payments API not resolvable
+    severity: critical
+    actions: [notify-only]
+    probe:
+      type: dns
+      target: api.payments.svc.cluster.local
+      timeout: 2s
+      duration: 2m
+`)
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: path, Clock: clock, Prober: prober})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() failed: %v", err)
+	}
+	rule := detector.Rules()[len(detector.Rules())-1]
+	if rule.Probe == nil || rule.Probe.timeout() != 2*time.Second || len(rule.Actions) != 0 {
+		t.Fatalf("probe rule not loaded: %+v", rule)
+	}
+
+	// Failures are only reported once they are sustained for the duration
+	issues, err := detector.evaluateRule(context.Background(), rule)
+	if err != nil || len(issues) != 0 {
+		t.Fatalf("evaluateRule() = %v, %v, want no issues on the first failure", issues, err)
+	}
+	clock.SetTime(clock.Now().Add(2 * time.Minute))
+	issues, _ = detector.evaluateRule(context.Background(), rule)
+	if len(issues) != 1 {
+		t.Fatalf("issues after 2m = %v, want one", issues)
+	}
+	issue := issues[0]
+	if issue.Kind != "Service" || issue.Namespace != "payments" || issue.Name != "api" || issue.Reason != "ProbeFailed" {
+		t.Errorf("issue = %+v, want a ProbeFailed issue of Service payments/api", issue)
+	}
+	if !strings.Contains(issue.Description, "no such host") || !strings.Contains(issue.Description, "for 2 minutes") {
+		t.Errorf("Description = %q, want the probe error and how long it failed", issue.Description)
+	}
+
+	// A successful probe starts over
+	prober.failing = nil
+	if issues, _ := detector.evaluateRule(context.Background(), rule); len(issues) != 0 {
+		t.Errorf("issues after recovery = %v, want none", issues)
+	}
+	prober.failing = map[string]bool{"api.payments.svc.cluster.local": true}
+	clock.SetTime(clock.Now().Add(time.Minute))
+	if issues, _ := detector.evaluateRule(context.Background(), rule); len(issues) != 0 {
+		t.Errorf("issues after a new failure = %v, want none before 2m", issues)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), "payments-dns", "", "")
+	if err != nil {
+		t.Fatalf("TraceRule() failed: %v", err)
+	}
+	if trace.Fired || trace.Kind != "Service" || trace.Steps[len(trace.Steps)-1].Condition != "failing for" {
+		t.Errorf("unexpected trace: %+v", trace)
+	}
+
+	for content, want := range map[string]string{
+		"rules:\n  - name: x\n    severity: low\n    probe: {type: ping, target: db}\n":                                                 "unsupported probe type",
+		"rules:\n  - name: x\n    severity: low\n    probe: {type: tcp, target: db.shop.svc}\n":                                         "must be host:port",
+		"rules:\n  - name: x\n    severity: low\n    probe: {type: http, target: db.shop.svc}\n":                                        "http or https URL",
+		"rules:\n  - name: x\n    severity: low\n    actions: [restart-pod]\n    probe: {type: dns, target: db}\n":                      "cannot have actions",
+		"rules:\n  - name: x\n    severity: low\n    probe: {type: dns, target: db, duration: soon}\n":                                  "invalid duration",
+		"rules:\n  - name: x\n    severity: low\n    probe: {type: dns, target: db}\n    conditions: [{resource: Pod, expr: 'true'}]\n": "cannot be combined",
+	} {
+		write(content)
+		detector = NewDetector(fake.NewSimpleClientset(), DetectionConfig{RulesFile: path})
+		if err := detector.LoadRules(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadRules(%q) error = %v, want %q", content, err, want)
+		}
+	}
+}
+
+func TestNetworkProber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		check   ProbeCheck
+		wantErr bool
+	}{
+		{ProbeCheck{Type: ProbeHTTP, Target: server.URL + "/healthz"}, false},
+		{ProbeCheck{Type: ProbeHTTP, Target: server.URL + "/broken"}, true},
+		{ProbeCheck{Type: ProbeTCP, Target: server.Listener.Addr().String()}, false},
+		{ProbeCheck{Type: ProbeTCP, Target: closed.Listener.Addr().String()}, true},
+		{ProbeCheck{Type: ProbeDNS, Target: "localhost"}, false},
+	}
+	for _, tt := range tests {
+		if err := (networkProber{}).Probe(context.Background(), tt.check); (err != nil) != tt.wantErr {
+			t.Errorf("Probe(%s %s) error = %v, want error %v", tt.check.Type, tt.check.Target, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEvictedPods(t *testing.T) {
+	controller := true
+	failed := func(namespace, name, reason string, age time.Duration, owner string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(name)},
+			Status: corev1.PodStatus{
+				Phase:     corev1.PodFailed,
+				Reason:    reason,
+				StartTime: &metav1.Time{Time: time.Now().Add(-age)},
+			},
+		}
+		if owner != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name, Controller: &controller}}
+		}
+		return pod
+	}
+	client := fake.NewSimpleClientset(
+		failed("shop", "web-1", reasonEvicted, 3*time.Hour, "ReplicaSet"),
+		failed("shop", "web-2", reasonEvicted, 2*time.Hour, "ReplicaSet"),
+		failed("shop", "api-1", "", 2*time.Hour, ""),
+		failed("shop", "web-3", reasonEvicted, time.Minute, "ReplicaSet"),
+		failed("shop", "migrate-1", "", 2*time.Hour, "Job"),
+		failed("payments", "web-1", reasonEvicted, 2*time.Hour, "ReplicaSet"),
+	)
+	detector := NewDetector(client, DetectionConfig{EvictedPods: EvictedPods{Threshold: 3, MinAge: time.Hour}})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	var found []Issue
+	for _, issue := range issues {
+		if issue.RuleName == EvictedPodsRule {
+			found = append(found, issue)
+		}
+	}
+	if len(found) != 1 || found[0].Kind != "Namespace" || found[0].Name != "shop" {
+		t.Fatalf("issues = %+v, want one for namespace shop", found)
+	}
+	if !strings.Contains(found[0].Description, "3 failed pods, 2 of them evicted, the oldest failed 3 hours ago") {
+		t.Errorf("Description = %q, want the failed pods aged over an hour without the Job pod", found[0].Description)
+	}
+	if len(found[0].Actions) != 1 || found[0].Actions[0] != CleanupEvictedPodsAction {
+		t.Errorf("Actions = %v, want %s", found[0].Actions, CleanupEvictedPodsAction)
+	}
+
+	disabled := NewDetector(client, DetectionConfig{})
+	if err := disabled.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	for _, rule := range disabled.Rules() {
+		if rule.Name == EvictedPodsRule && rule.Enabled {
+			t.Error("evicted-pods rule enabled without a threshold")
+		}
+	}
+}
+
+func TestDecisionLog(t *testing.T) {
+	pod := func(name string, restarts int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				RestartCount: restarts,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+		}
+	}
+	client := fake.NewSimpleClientset(pod("web-1", 7), pod("web-2", 2), pod("web-3", 3))
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector := NewDetector(client, DetectionConfig{
+		CrashLoopThreshold: 5,
+		DecisionLog:        DecisionLog{Enabled: true},
+		Clock:              clock,
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	// Unlike traces, decisions start duration-based conditions, so web-1 fires
+	// once the condition held for the check duration of one minute
+	for _, want := range []int{0, 1} {
+		issues, err := detector.DetectIssues(context.Background())
+		if err != nil || len(issues) != want {
+			t.Fatalf("DetectIssues() = %d issues, %v, want %d", len(issues), err, want)
+		}
+		clock.SetTime(clock.Now().Add(time.Minute))
+	}
+
+	report, err := detector.Decisions(DecisionQuery{Rule: "crash-loop-backoff"})
+	if err != nil {
+		t.Fatalf("Decisions() error = %v", err)
+	}
+	counts := make(map[string]int)
+	for _, count := range report.Counts {
+		counts[count.Outcome+"/"+count.Condition] = count.Count
+	}
+	if counts["fired/"] != 1 || counts["skipped/status.containerStatuses[*].restartCount"] != 2 {
+		t.Errorf("counts = %v, want 1 fired and 2 skipped on the restart count", counts)
+	}
+	// Decisions of both cycles are kept, the most recent first
+	if len(report.Decisions) != 6 || report.Decisions[0].EvaluatedAt.Before(report.Decisions[5].EvaluatedAt) {
+		t.Fatalf("decisions = %+v, want 6, the most recent first", report.Decisions)
+	}
+
+	skipped, _ := detector.Decisions(DecisionQuery{Rule: "crash-loop-backoff", Outcome: DecisionSkipped, Limit: 1})
+	if len(skipped.Decisions) != 1 || skipped.Decisions[0].Value == "" || skipped.Decisions[0].Expected != ">= 5" {
+		t.Errorf("skipped decisions = %+v, want the restart count of a near miss", skipped.Decisions)
+	}
+
+	// The log keeps the latest Size decisions
+	small := newDecisionLog(DecisionLog{Enabled: true, Size: 2})
+	for _, name := range []string{"web-1", "web-2", "web-3"} {
+		small.record(Decision{Rule: "crash-loop-backoff", Name: name, Outcome: DecisionSkipped})
+	}
+	if kept := small.query(DecisionQuery{}); len(kept) != 2 || kept[0].Name != "web-3" || kept[1].Name != "web-2" {
+		t.Errorf("kept decisions = %+v, want web-3 and web-2", kept)
+	}
+
+	disabled := NewDetector(client, DetectionConfig{})
+	if _, err := disabled.Decisions(DecisionQuery{}); !errors.Is(err, ErrDecisionLogDisabled) {
+		t.Errorf("Decisions() error = %v, want ErrDecisionLogDisabled", err)
+	}
+}
+
+func TestStuckTerminatingPods(t *testing.T) {
+	terminating := func(name, node string, overdue time.Duration, finalizers ...string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "shop",
+				DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-overdue)},
+				Finalizers:        finalizers,
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	ready := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+	}
+	notReady := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}}},
+	}
+	client := fake.NewSimpleClientset(ready, notReady,
+		terminating("web-1", "node-b", 20*time.Minute),
+		terminating("web-2", "node-gone", 20*time.Minute),
+		terminating("web-3", "node-a", 20*time.Minute, "example.com/cleanup"),
+		terminating("web-4", "node-a", time.Minute),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-5", Namespace: "shop"}},
+	)
+	detector := NewDetector(client, DetectionConfig{StuckTerminatingAfter: 10 * time.Minute})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	reasons := map[string]string{}
+	for _, issue := range issues {
+		if issue.RuleName == StuckTerminatingRule {
+			reasons[issue.Name] = issue.Reason
+			if len(issue.Actions) != 1 || issue.Actions[0] != ForceDeletePodAction {
+				t.Errorf("Actions = %v, want %s", issue.Actions, ForceDeletePodAction)
+			}
+		}
+	}
+	want := map[string]string{"web-1": "NodeLost", "web-2": "NodeLost", "web-3": "FinalizerPending"}
+	if len(reasons) != len(want) {
+		t.Fatalf("reported pods = %v, want %v", reasons, want)
+	}
+	for name, reason := range want {
+		if reasons[name] != reason {
+			t.Errorf("reason of %s = %q, want %q", name, reasons[name], reason)
+		}
+	}
+
+	trace, err := detector.TraceRule(context.Background(), StuckTerminatingRule, "shop", "web-4")
+	if err != nil || trace.Fired {
+		t.Fatalf("TraceRule() = %+v, %v, want no issue within the threshold", trace, err)
+	}
+}
+
+func TestSchedulingConflicts(t *testing.T) {
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/hostname": name, "topology.kubernetes.io/zone": zone}},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+		}
+	}
+	tainted := node("node-c", "zone-b")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+
+	replicas := int32(4)
+	controller := true
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d8f", Namespace: "shop",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}},
+	}}
+	pending := func(name, message string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f", Controller: &controller}},
+			},
+			Spec: corev1.PodSpec{Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					Message:            message,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-age)},
+				}},
+			},
+		}
+	}
+	antiAffinity := "0/3 nodes are available: 1 node(s) had untolerated taint {dedicated: gpu}, 2 node(s) didn't match pod anti-affinity rules."
+	resources := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "shop"},
+		Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+			Message:            "0/3 nodes are available: 3 Insufficient cpu.",
+			LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)},
+		}}},
+	}
+	client := fake.NewSimpleClientset(node("node-a", "zone-a"), node("node-b", "zone-a"), tainted, deployment, rs,
+		pending("web-5d8f-1", antiAffinity, time.Hour),
+		pending("web-5d8f-2", antiAffinity, time.Hour),
+		pending("web-5d8f-3", antiAffinity, time.Minute),
+		resources,
+	)
+	detector := NewDetector(client, DetectionConfig{SchedulingConflictAfter: 10 * time.Minute})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	var found []Issue
+	for _, issue := range issues {
+		if issue.RuleName == SchedulingConflictRule {
+			found = append(found, issue)
+		}
+	}
+	if len(found) != 1 || found[0].Kind != KindDeployment || found[0].Name != "web" || found[0].Reason != reasonAntiAffinityConflict {
+		t.Fatalf("issues = %+v, want one for deployment web", found)
+	}
+	want := "2 pods of deployment web pending for 1 hour: required anti-affinity allows one pod per kubernetes.io/hostname, but 4 replicas exceed the 2 eligible kubernetes.io/hostname domains (2 of 3 nodes eligible)"
+	if !strings.Contains(found[0].Description, want) {
+		t.Errorf("Description = %q, want %q", found[0].Description, want)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), SchedulingConflictRule, "shop", "batch-1")
+	if err != nil || trace.Fired {
+		t.Fatalf("TraceRule() = %+v, %v, want no issue for pods lacking resources", trace, err)
+	}
+}
+
+func TestServicesWithoutEndpoints(t *testing.T) {
+	controller := true
+	notReady := false
+	service := func(name string, selector map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Selector: selector},
+		}
+	}
+	endpoints := func(service string, ready ...bool) *discoveryv1.EndpointSlice {
+		slice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+			Name: service + "-abcde", Namespace: "shop", Labels: map[string]string{discoveryv1.LabelServiceName: service},
+		}}
+		for i := range ready {
+			slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{Conditions: discoveryv1.EndpointConditions{Ready: &ready[i]}})
+		}
+		return slice
+	}
+	crashing := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "shop", Labels: map[string]string{"app": "web"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f", Controller: &controller}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "web",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				}},
+			},
+		}
+	}
+	external := service("search", nil)
+	external.Spec.Type = corev1.ServiceTypeExternalName
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d8f", Namespace: "shop",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", Controller: &controller}},
+		}},
+		crashing("web-5d8f-1"), crashing("web-5d8f-2"),
+		service("web", map[string]string{"app": "web"}), endpoints("web", notReady, notReady),
+		service("api", map[string]string{"app": "api"}),
+		service("cart", map[string]string{"app": "cart"}), endpoints("cart", notReady, !notReady),
+		service("legacy", nil), external,
+	)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	detector := NewDetector(client, DetectionConfig{ServiceWithoutEndpointsAfter: 5 * time.Minute, Clock: clock})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	detect := func() []Issue {
+		issues, err := detector.DetectIssues(context.Background())
+		if err != nil {
+			t.Fatalf("DetectIssues() error = %v", err)
+		}
+		var found []Issue
+		for _, issue := range issues {
+			if issue.RuleName == ServiceWithoutEndpointsRule {
+				found = append(found, issue)
+			}
+		}
+		return found
+	}
+
+	if found := detect(); len(found) != 0 {
+		t.Fatalf("issues = %+v, want none before the threshold", found)
+	}
+	clock.SetTime(clock.Now().Add(6 * time.Minute))
+	found := detect()
+	if len(found) != 2 {
+		t.Fatalf("issues = %+v, want one for service api and one for deployment web", found)
+	}
+	byReason := map[string]Issue{}
+	for _, issue := range found {
+		byReason[issue.Reason] = issue
+	}
+
+	mismatch := byReason[reasonSelectorMismatch]
+	if mismatch.Kind != "Service" || mismatch.Name != "api" || len(mismatch.Actions) != 0 {
+		t.Errorf("selector mismatch issue = %+v, want service api without actions", mismatch)
+	}
+	if !strings.Contains(mismatch.Description, "its selector app=api matches no running pods") {
+		t.Errorf("Description = %q", mismatch.Description)
+	}
+
+	backends := byReason[reasonBackendsNotReady]
+	if backends.Kind != KindDeployment || backends.Name != "web" {
+		t.Errorf("backends issue = %+v, want deployment web", backends)
+	}
+	if !slices.Equal(backends.Actions, []string{"rolling-restart-pods", "rollback-deployment"}) {
+		t.Errorf("Actions = %v", backends.Actions)
+	}
+	if !strings.Contains(backends.Description, "none of its 2 pods is ready (web-5d8f-1 CrashLoopBackOff, web-5d8f-2 CrashLoopBackOff), run by deployment web") {
+		t.Errorf("Description = %q", backends.Description)
+	}
+
+	disabled := NewDetector(client, DetectionConfig{})
+	if err := disabled.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	for _, rule := range disabled.Rules() {
+		if rule.Name == ServiceWithoutEndpointsRule && rule.Enabled {
+			t.Error("service-without-endpoints rule enabled without a threshold")
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	replicas := int32(3)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", ResourceVersion: "42", Generation: 5},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "migrate", Image: "web:1.4"}},
+				Containers:     []corev1.Container{{Name: "web", Image: "web:1.4"}, {Name: "proxy", Image: "envoy:1.30"}},
+			}},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 3, ReadyReplicas: 1, UpdatedReplicas: 3, AvailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse}},
+		},
+	}
+
+	snapshot := NewSnapshot(deployment)
+	if snapshot == nil || snapshot.ResourceVersion != "42" || snapshot.Generation != 5 || len(snapshot.SpecHash) != 16 {
+		t.Fatalf("NewSnapshot() = %+v", snapshot)
+	}
+	if !slices.Equal(snapshot.Images, []string{"web:1.4", "envoy:1.30"}) {
+		t.Errorf("Images = %v, want the distinct images of the pod template", snapshot.Images)
+	}
+	if want := (ReplicaCounts{Desired: 3, Current: 3, Ready: 1, Updated: 3, Available: 1}); snapshot.Replicas == nil || *snapshot.Replicas != want {
+		t.Errorf("Replicas = %+v, want %+v", snapshot.Replicas, want)
+	}
+	if !slices.Equal(snapshot.Conditions, []string{"Available=False"}) {
+		t.Errorf("Conditions = %v", snapshot.Conditions)
+	}
+
+	changed := deployment.DeepCopy()
+	changed.Status.ReadyReplicas = 3
+	if NewSnapshot(changed).SpecHash != snapshot.SpecHash {
+		t.Error("SpecHash changed with the status")
+	}
+	changed.Spec.Template.Spec.Containers[0].Image = "web:1.5"
+	if NewSnapshot(changed).SpecHash == snapshot.SpecHash {
+		t.Error("SpecHash unchanged with the spec")
+	}
+
+	pod := NewSnapshot(&corev1.Pod{
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "web:1.4"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+	})
+	if pod.Phase != "Failed" || pod.Reason != "Evicted" || pod.Replicas != nil || !slices.Equal(pod.Images, []string{"web:1.4"}) {
+		t.Errorf("pod snapshot = %+v", pod)
+	}
+
+	if NewSnapshot(nil) != nil {
+		t.Error("NewSnapshot(nil) != nil")
+	}
+}
+
+func TestResourceQuotas(t *testing.T) {
+	quota := func(namespace, name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		quota("shop", "compute",
+			corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10"), corev1.ResourcePods: resource.MustParse("50"), corev1.ResourceRequestsMemory: resource.MustParse("20Gi")},
+			corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("9500m"), corev1.ResourcePods: resource.MustParse("48"), corev1.ResourceRequestsMemory: resource.MustParse("4Gi")}),
+		quota("payments", "compute",
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10"), corev1.ResourceServicesLoadBalancers: resource.MustParse("0")},
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10"), corev1.ResourceServicesLoadBalancers: resource.MustParse("0")}),
+		quota("batch", "compute",
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("100")},
+			corev1.ResourceList{corev1.ResourcePods: resource.MustParse("92")}),
+	)
+	detector := NewDetector(client, DetectionConfig{
+		ResourceQuotaThresholdPercent: 90,
+		Namespaces:                    map[string]NamespaceConfig{"batch": {ResourceQuota: ResourceQuotaConfig{ThresholdPercent: 95}}},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.RuleName == ResourceQuotaRule {
+			found[issue.Namespace] = issue
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("issues = %+v, want shop and payments; batch is below its namespace threshold", found)
+	}
+
+	shop := found["shop"]
+	if shop.Kind != "ResourceQuota" || shop.Name != "compute" || shop.Reason != reasonQuotaNearlyExhausted || shop.Severity != "medium" {
+		t.Errorf("shop issue = %+v", shop)
+	}
+	if !strings.Contains(shop.Description, "quota compute uses pods 48/50 (96%), requests.cpu 9500m/10 (95%), threshold 90%") {
+		t.Errorf("Description = %q, want the resources above the threshold, the fullest first", shop.Description)
+	}
+
+	payments := found["payments"]
+	if payments.Reason != reasonQuotaExhausted || payments.Severity != "high" {
+		t.Errorf("payments issue = %+v, want an exhausted quota with high severity", payments)
+	}
+	if strings.Contains(payments.Description, "loadbalancers") {
+		t.Errorf("Description = %q, want resources forbidden with a zero hard limit skipped", payments.Description)
+	}
+}
+
+func TestWarningEvents(t *testing.T) {
+	now := time.Now()
+	pod := func(name string, uid types.UID) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: uid}}
+	}
+	event := func(name string, object corev1.ObjectReference, eventType, reason string, ago time.Duration, count int32) *corev1.Event {
+		object.Namespace = "default"
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: object,
+			Type:           eventType,
+			Reason:         reason,
+			Message:        reason + " happened",
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(now.Add(-ago)),
+		}
+	}
+	web := corev1.ObjectReference{Kind: "Pod", Name: "web", UID: "web-2", FieldPath: "spec.containers{app}"}
+
+	client := fake.NewSimpleClientset(
+		pod("web", "web-2"),
+		pod("db", "db-1"),
+		event("backoff", web, corev1.EventTypeWarning, "BackOff", time.Minute, 6),
+		// Events of an earlier pod named web are ignored
+		event("backoff-old", corev1.ObjectReference{Kind: "Pod", Name: "web", UID: "web-1"}, corev1.EventTypeWarning, "FailedMount", time.Minute, 5),
+		event("unhealthy", corev1.ObjectReference{Kind: "Pod", Name: "db", UID: "db-1"}, corev1.EventTypeWarning, "Unhealthy", time.Minute, 2),
+		event("unhealthy-old", corev1.ObjectReference{Kind: "Pod", Name: "db", UID: "db-1"}, corev1.EventTypeWarning, "Unhealthy", time.Hour, 20),
+		event("gone", corev1.ObjectReference{Kind: "Pod", Name: "gone"}, corev1.EventTypeWarning, "BackOff", time.Minute, 9),
+		event("claim", corev1.ObjectReference{Kind: "PersistentVolumeClaim", Name: "data"}, corev1.EventTypeWarning, "FailedMount", time.Minute, 3),
+		event("scheduled", web, corev1.EventTypeNormal, "FailedMount", time.Minute, 9),
+	)
+	detector := NewDetector(client, DetectionConfig{
+		EventReasons: []EventReason{
+			{Reason: "BackOff", Severity: "high", Actions: []string{"restart-pod"}, MinCount: 5, Within: 10 * time.Minute},
+			{Reason: "FailedMount", Actions: []string{"restart-pod"}, MinCount: 3, Within: 10 * time.Minute},
+			{Reason: "Unhealthy", MinCount: 10, Within: 10 * time.Minute},
+		},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	issues, err := detector.DetectIssues(context.Background())
+	if err != nil {
+		t.Fatalf("DetectIssues() error = %v", err)
+	}
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.RuleName == WarningEventRule {
+			found[issue.Kind+"/"+issue.Name+"/"+issue.Reason] = issue
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("warning event issues = %v, want Pod/web/BackOff and PersistentVolumeClaim/data/FailedMount", found)
+	}
+
+	backoff := found["Pod/web/BackOff"]
+	if backoff.Severity != "high" || backoff.Container != "app" || len(backoff.Actions) != 1 || !strings.Contains(backoff.Description, "seen 6 times within 10m0s: BackOff happened") {
+		t.Errorf("BackOff issue = %+v, want a high issue on container app seen 6 times with restart-pod", backoff)
+	}
+	if _, ok := backoff.Resource.(*corev1.Pod); !ok {
+		t.Errorf("BackOff issue resource = %T, want the pod", backoff.Resource)
+	}
+	mount := found["PersistentVolumeClaim/data/FailedMount"]
+	if mount.Severity != "medium" || len(mount.Actions) != 0 {
+		t.Errorf("FailedMount issue = %+v, want a medium notify-only issue", mount)
+	}
+
+	trace, err := detector.TraceRule(context.Background(), WarningEventRule, "default", "db")
+	if err != nil {
+		t.Fatalf("TraceRule() error = %v", err)
+	}
+	if trace.Fired || trace.Kind != "Pod" {
+		t.Errorf("trace of db = %+v, want a Pod trace that does not fire on 2 recent Unhealthy events", trace)
+	}
+}
+
+func TestDefaultActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `rules:
+  - name: omitted
+    severity: high
+    conditions: [{resource: Pod, field: status.phase, operator: equals, value: Failed}]
+  - name: notify
+    severity: high
+    actions: [notify-only]
+    conditions: [{resource: Pod, field: status.phase, operator: equals, value: Failed}]
+  - name: nodes
+    severity: high
+    conditions: [{resource: Node, field: spec.unschedulable, operator: equals, value: true}]
+  - name: low
+    severity: low
+    conditions: [{resource: Pod, field: status.phase, operator: equals, value: Failed}]
+  - name: crash-loop-backoff
+    severity: critical
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	query := Rule{
+		Name:       "query",
+		Enabled:    true,
+		Severity:   "critical",
+		Conditions: []RuleCondition{{Resource: "Deployment", Query: "up", Operator: "less_than", Value: 1.0}},
+	}
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{
+		RulesFile:  path,
+		QueryRules: []Rule{query},
+		DefaultActions: map[string][]string{
+			"high":     {"restart-pod"},
+			"critical": {"rollback-deployment"},
+		},
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"omitted": {"restart-pod"},
+		"notify":  {},
+		"nodes":   {},
+		"low":     {},
+		"query":   {"rollback-deployment"},
+		// A built-in rule keeps its own actions
+		"crash-loop-backoff": {"restart-pod"},
+	}
+	for _, rule := range detector.Rules() {
+		actions, exists := want[rule.Name]
+		if !exists {
+			continue
+		}
+		if !slices.Equal(rule.Actions, actions) {
+			t.Errorf("actions of %s = %v, want %v", rule.Name, rule.Actions, actions)
+		}
+	}
+}
+
+func TestPodFlapping(t *testing.T) {
+	now := time.Now()
+	pod := func(namespace, name string, started time.Time, restarts int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID(namespace + "-" + name)},
+			Status: corev1.PodStatus{
+				StartTime:         &metav1.Time{Time: started},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}},
+			},
+		}
+	}
+	client := fake.NewSimpleClientset(
+		pod("default", "old", now.Add(-90*24*time.Hour), 40),
+		pod("default", "new", now.Add(-2*time.Minute), 5),
+		pod("batch", "job", now.Add(-90*24*time.Hour), 10),
+	)
+	clock := clocktesting.NewFakePassiveClock(now)
+	detector := NewDetector(client, DetectionConfig{
+		Flapping:   FlappingConfig{RestartLimit: 5, Window: 10 * time.Minute},
+		Namespaces: map[string]NamespaceConfig{"batch": {Flapping: FlappingConfig{RestartLimit: 2}}},
+		Clock:      clock,
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	var rule *Rule
+	for _, r := range detector.Rules() {
+		if r.Name == PodFlappingRule && r.Enabled {
+			rule = &r
+		}
+	}
+	if rule == nil {
+		t.Fatalf("rule %s is not enabled", PodFlappingRule)
+	}
+	flapping := func() []string {
+		t.Helper()
+		issues, err := detector.evaluateRule(context.Background(), *rule)
+		if err != nil {
+			t.Fatalf("evaluateRule() error = %v", err)
+		}
+		var names []string
+		for _, issue := range issues {
+			names = append(names, issue.Namespace+"/"+issue.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	restart := func(namespace, name string, restarts int32) {
+		t.Helper()
+		p, err := client.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Status.ContainerStatuses[0].RestartCount = restarts
+		if _, err := client.CoreV1().Pods(namespace).Update(context.Background(), p, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := flapping(); !slices.Equal(got, []string{"default/new"}) {
+		t.Errorf("first evaluation = %v, want only the pod that started within the window; older restarts of long-running pods are not counted", got)
+	}
+
+	clock.SetTime(now.Add(5 * time.Minute))
+	restart("default", "old", 46)
+	restart("batch", "job", 12)
+	if got := flapping(); !slices.Equal(got, []string{"batch/job", "default/new", "default/old"}) {
+		t.Errorf("evaluation after restarts = %v, want old with 6 restarts and job at the limit of its namespace", got)
+	}
+
+	clock.SetTime(now.Add(16 * time.Minute))
+	if got := flapping(); len(got) != 0 {
+		t.Errorf("evaluation once the restarts left the window = %v, want none", got)
+	}
+}
+
+func TestNamespaceScope(t *testing.T) {
+	now := time.Now()
+	pod := func(namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Status: corev1.PodStatus{
+				StartTime:         &metav1.Time{Time: now.Add(-time.Minute)},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: 6}},
+			},
+		}
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	client := fake.NewSimpleClientset(pod("team-a"), pod("team-b"), pod("kube-system"), node)
+	detector := NewDetector(client, DetectionConfig{
+		Flapping: FlappingConfig{RestartLimit: 5, Window: 10 * time.Minute},
+		Clock:    clocktesting.NewFakePassiveClock(now),
+	})
+	if err := detector.LoadRules(); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	namespaces := func() []string {
+		t.Helper()
+		issues, err := detector.DetectIssues(context.Background())
+		if err != nil {
+			t.Fatalf("DetectIssues() error = %v", err)
+		}
+		var found []string
+		for _, issue := range issues {
+			if issue.RuleName == PodFlappingRule {
+				found = append(found, issue.Namespace)
+			}
+		}
+		slices.Sort(found)
+		return found
+	}
+
+	if got := namespaces(); !slices.Equal(got, []string{"kube-system", "team-a", "team-b"}) {
+		t.Errorf("issues without a scope = %v, want all namespaces", got)
+	}
+
+	detector.SetNamespaceScope([]string{"team-a", "team-b"}, false)
+	if got := namespaces(); !slices.Equal(got, []string{"team-a", "team-b"}) {
+		t.Errorf("issues with a cluster-wide listed scope = %v, want the namespaces in scope", got)
+	}
+
+	client.ClearActions()
+	detector.SetNamespaceScope([]string{"team-a"}, true)
+	if got := namespaces(); !slices.Equal(got, []string{"team-a"}) {
+		t.Errorf("issues with a per-namespace listed scope = %v, want team-a", got)
+	}
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "pods" && action.GetNamespace() != "team-a" {
+			t.Errorf("pods were listed in namespace %q, want only team-a", action.GetNamespace())
+		}
+	}
+	if !detector.InScope("") || detector.InScope("kube-system") {
+		t.Error("cluster-scoped resources must stay in scope and other namespaces out of it")
+	}
+}
@@ -0,0 +1,302 @@
+package detection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/permissions"
+)
+
+// NotifyOnlyAction may be listed as the only action of a rules file rule to
+// notify about its issues without remediating them
+const NotifyOnlyAction = "notify-only"
+
+// Severities are the severities of rules, from least to most severe
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// rulesFile is the content of the rules file
+type rulesFile struct {
+	Rules []fileRule `yaml:"rules"`
+}
+
+// fileRule is a rule of the rules file. A rule named like a built-in rule
+// modifies it: its set fields override the built-in ones and its conditions and
+// status conditions are ignored, since built-in rules are evaluated by their own
+// checks.
+type fileRule struct {
+	Name        string          `yaml:"name"`
+	Description string          `yaml:"description"`
+	Enabled     *bool           `yaml:"enabled"` // Default: true
+	Conditions  []fileCondition `yaml:"conditions"`
+	// StatusConditions makes a custom rule check the status conditions of
+	// resources instead of evaluating conditions
+	StatusConditions *fileStatusConditions `yaml:"statusConditions"`
+	// Probe makes a custom rule actively probe a target from within the cluster
+	Probe    *fileProbe        `yaml:"probe"`
+	Actions  []string          `yaml:"actions"`
+	Severity string            `yaml:"severity"`
+	Labels   map[string]string `yaml:"labels"`
+	Schedule string            `yaml:"schedule"`
+	Runbook  string            `yaml:"runbook"`
+}
+
+// fileCondition is a condition of a rules file rule
+type fileCondition struct {
+	Resource   string      `yaml:"resource"`
+	APIVersion string      `yaml:"apiVersion"`
+	Field      string      `yaml:"field"`
+	Expr       string      `yaml:"expr"`
+	Operator   string      `yaml:"operator"`
+	Value      interface{} `yaml:"value"`
+	Duration   string      `yaml:"duration"` // e.g. 5m
+}
+
+// fileStatusConditions selects the resources of a status conditions rule
+type fileStatusConditions struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Selector   string   `yaml:"selector"`
+	Types      []string `yaml:"types"`
+	Duration   string   `yaml:"duration"` // e.g. 10m
+}
+
+// fileProbe is the probe of a probe rule
+type fileProbe struct {
+	Type     string `yaml:"type"`
+	Target   string `yaml:"target"`
+	Timeout  string `yaml:"timeout"`  // e.g. 2s
+	Duration string `yaml:"duration"` // e.g. 2m
+}
+
+// readRulesFile reads the rules of a rules file; a missing file has no rules
+func readRulesFile(path string) ([]fileRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file rulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range file.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rules file %s: rule without a name", path)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("rules file %s: duplicate rule %s", path, rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return file.Rules, nil
+}
+
+// actions returns the actions of the rule, with notify-only removed
+func (r fileRule) actions() []string {
+	actions := []string{}
+	for _, action := range r.Actions {
+		if action != NotifyOnlyAction {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// validate checks the severity, actions and condition operators of the rule;
+// plugins are the actions implemented by plugins
+func (r fileRule) validate(plugins []string) error {
+	if r.Severity != "" && !slices.Contains(Severities, r.Severity) {
+		return fmt.Errorf("invalid severity %q, must be one of %v", r.Severity, Severities)
+	}
+	for _, action := range r.Actions {
+		if _, known := permissions.ActionRequirements[action]; !known && action != NotifyOnlyAction && !slices.Contains(plugins, action) {
+			return fmt.Errorf("unknown action %q", action)
+		}
+	}
+	if slices.Contains(r.Actions, NotifyOnlyAction) && len(r.Actions) > 1 {
+		return fmt.Errorf("%s cannot be combined with other actions", NotifyOnlyAction)
+	}
+	for _, condition := range r.Conditions {
+		if condition.Expr == "" && !slices.Contains(FieldOperators, condition.Operator) {
+			return fmt.Errorf("condition on %s: unsupported operator %q, must be one of %v", condition.Field, condition.Operator, FieldOperators)
+		}
+	}
+	return nil
+}
+
+// override applies the set fields of the rule to a built-in rule. Enabling a
+// built-in rule that is disabled by its settings, e.g. without a maximum age of
+// ephemeral containers, keeps it disabled.
+func (r fileRule) override(rule *Rule, plugins []string) error {
+	if err := r.validate(plugins); err != nil {
+		return err
+	}
+
+	if r.Enabled != nil {
+		rule.Enabled = rule.Enabled && *r.Enabled
+	}
+	if r.Description != "" {
+		rule.Description = r.Description
+	}
+	if r.Actions != nil {
+		rule.Actions = r.actions()
+	}
+	if r.Severity != "" {
+		rule.Severity = r.Severity
+	}
+	if r.Labels != nil {
+		rule.Labels = r.Labels
+	}
+	if r.Schedule != "" {
+		rule.Schedule = r.Schedule
+	}
+	if r.Runbook != "" {
+		rule.Runbook = r.Runbook
+	}
+	return nil
+}
+
+// rule converts a custom rule of the rules file, whose conditions are evaluated
+// by the field rule evaluator, or whose status conditions are checked
+func (r fileRule) rule(plugins []string) (Rule, error) {
+	if err := r.validate(plugins); err != nil {
+		return Rule{}, err
+	}
+	if r.Severity == "" {
+		return Rule{}, fmt.Errorf("no severity")
+	}
+
+	rule := Rule{
+		Name:        r.Name,
+		Description: r.Description,
+		Enabled:     r.Enabled == nil || *r.Enabled,
+		Actions:     r.actions(),
+		Severity:    r.Severity,
+		Labels:      r.Labels,
+		Schedule:    r.Schedule,
+		Runbook:     r.Runbook,
+	}
+	if rule.Description == "" {
+		rule.Description = "Rule " + r.Name
+	}
+	if r.Probe != nil {
+		check, err := r.probe()
+		if err != nil {
+			return Rule{}, fmt.Errorf("probe: %w", err)
+		}
+		rule.Probe = check
+		return rule, nil
+	}
+	if r.StatusConditions != nil {
+		check, err := r.statusConditions()
+		if err != nil {
+			return Rule{}, fmt.Errorf("statusConditions: %w", err)
+		}
+		rule.StatusConditions = check
+		return rule, nil
+	}
+	if len(r.Conditions) == 0 {
+		return Rule{}, fmt.Errorf("no conditions")
+	}
+
+	for _, condition := range r.Conditions {
+		converted := RuleCondition{
+			Resource:   condition.Resource,
+			APIVersion: condition.APIVersion,
+			Field:      condition.Field,
+			Expr:       condition.Expr,
+			Operator:   condition.Operator,
+			Value:      condition.Value,
+		}
+		if condition.Duration != "" {
+			duration, err := time.ParseDuration(condition.Duration)
+			if err != nil {
+				return Rule{}, fmt.Errorf("condition on %s: invalid duration: %w", condition.Field, err)
+			}
+			converted.Duration = &metav1.Duration{Duration: duration}
+		}
+		rule.Conditions = append(rule.Conditions, converted)
+	}
+	if _, err := compileFieldConditions(rule); err != nil {
+		return Rule{}, err
+	}
+	return rule, nil
+}
+
+// statusConditions converts the status conditions of a custom rule. They select
+// resources only read with the dynamic client, so the rule can only notify.
+func (r fileRule) statusConditions() (*StatusConditionsCheck, error) {
+	if len(r.Conditions) > 0 {
+		return nil, fmt.Errorf("cannot be combined with conditions")
+	}
+	if len(r.actions()) > 0 {
+		return nil, fmt.Errorf("rules on status conditions cannot have actions, use %s", NotifyOnlyAction)
+	}
+
+	check := &StatusConditionsCheck{
+		APIVersion: r.StatusConditions.APIVersion,
+		Kind:       r.StatusConditions.Kind,
+		Selector:   r.StatusConditions.Selector,
+		Types:      r.StatusConditions.Types,
+	}
+	if r.StatusConditions.Duration != "" {
+		duration, err := time.ParseDuration(r.StatusConditions.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration: %w", err)
+		}
+		check.Duration = &metav1.Duration{Duration: duration}
+	}
+	if err := check.validate(); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// probe converts the probe of a custom rule. Its issues are not about a resource
+// read by the rule, so the rule can only notify.
+func (r fileRule) probe() (*ProbeCheck, error) {
+	if len(r.Conditions) > 0 || r.StatusConditions != nil {
+		return nil, fmt.Errorf("cannot be combined with conditions or status conditions")
+	}
+	if len(r.actions()) > 0 {
+		return nil, fmt.Errorf("probe rules cannot have actions, use %s", NotifyOnlyAction)
+	}
+
+	check := &ProbeCheck{Type: r.Probe.Type, Target: r.Probe.Target}
+	for _, field := range []struct {
+		name  string
+		value string
+		into  **metav1.Duration
+	}{{"timeout", r.Probe.Timeout, &check.Timeout}, {"duration", r.Probe.Duration, &check.Duration}} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+		*field.into = &metav1.Duration{Duration: duration}
+	}
+	if err := check.validate(); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
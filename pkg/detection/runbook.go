@@ -0,0 +1,32 @@
+package detection
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RunbookPlaceholders are substituted in runbook URL templates with the
+// URL-escaped values of an issue
+var RunbookPlaceholders = []string{"{rule}", "{namespace}", "{kind}", "{name}", "{team}"}
+
+// RenderRunbook substitutes the placeholders of a runbook URL template, e.g.
+// https://wiki.example.com/runbooks/{rule}?namespace={namespace}
+func RenderRunbook(template string, issue Issue) string {
+	if template == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{rule}", url.PathEscape(issue.RuleName),
+		"{namespace}", url.PathEscape(issue.Namespace),
+		"{kind}", url.PathEscape(issue.Kind),
+		"{name}", url.PathEscape(issue.Name),
+		"{team}", url.PathEscape(issue.Owner.Team),
+	).Replace(template)
+}
+
+// setRunbooks renders the runbook of a rule for its issues
+func setRunbooks(rule Rule, issues []Issue) {
+	for i := range issues {
+		issues[i].Runbook = RenderRunbook(rule.Runbook, issues[i])
+	}
+}
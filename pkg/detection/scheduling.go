@@ -0,0 +1,357 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SchedulingConflictRule is the rule reporting workloads whose pods cannot be
+// scheduled because of their pod anti-affinity or topology spread constraints
+const SchedulingConflictRule = "scheduling-constraint-conflict"
+
+// Reasons of scheduling conflict issues
+const (
+	reasonAntiAffinityConflict   = "AntiAffinityConflict"
+	reasonTopologySpreadConflict = "TopologySpreadConflict"
+)
+
+// schedulingConflict is a pending pod whose scheduling constraints the
+// scheduler cannot satisfy
+type schedulingConflict struct {
+	pod    *corev1.Pod
+	reason string
+	// message is the explanation of the scheduler
+	message string
+}
+
+// workloadConflicts are the scheduling conflicts of the pods of a workload
+type workloadConflicts struct {
+	kind, name string
+	resource   runtime.Object
+	replicas   int32
+	conflicts  []schedulingConflict
+}
+
+// detectSchedulingConflicts reports the workloads with pods that have been
+// unschedulable for longer than the threshold because of their pod
+// anti-affinity or topology spread constraints, with one issue per workload
+func (d *Detector) detectSchedulingConflicts(ctx context.Context, rule Rule) ([]Issue, error) {
+	pods, err := d.listPods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	byWorkload := make(map[string]*workloadConflicts)
+	var keys []string
+	var nodes []corev1.Node
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !unschedulable(pod) {
+			continue
+		}
+		if nodes == nil {
+			list, err := d.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list nodes: %w", err)
+			}
+			nodes = append([]corev1.Node{}, list.Items...)
+		}
+		workload := d.podWorkload(ctx, pod)
+		key := fmt.Sprintf("%s/%s/%s", pod.Namespace, workload.kind, workload.name)
+		if existing, exists := byWorkload[key]; exists {
+			workload = existing
+		} else {
+			byWorkload[key] = workload
+			keys = append(keys, key)
+		}
+		workload.conflicts = append(workload.conflicts, schedulingConflict{pod: pod})
+	}
+	sort.Strings(keys)
+
+	var issues []Issue
+	for _, key := range keys {
+		workload := byWorkload[key]
+		issues = append(issues, d.decide(ctx, rule, workload.kind, workload.resource, func(trace *Trace) []Issue {
+			return d.checkSchedulingConflicts(rule, workload, nodes, trace)
+		})...)
+	}
+	return issues, nil
+}
+
+// unschedulable returns true if the scheduler found no node for a pending pod
+func unschedulable(pod *corev1.Pod) bool {
+	condition := podScheduledCondition(pod)
+	return pod.Status.Phase == corev1.PodPending && pod.Spec.NodeName == "" &&
+		condition != nil && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable
+}
+
+// podScheduledCondition returns the PodScheduled condition of a pod, or nil
+func podScheduledCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodScheduled {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// checkSchedulingConflicts evaluates the scheduling conflict rule against the
+// unschedulable pods of a workload and reports the workload once one of them
+// has been unschedulable for the threshold because of its constraints
+func (d *Detector) checkSchedulingConflicts(rule Rule, workload *workloadConflicts, nodes []corev1.Node, trace *Trace) []Issue {
+	var conflicts []schedulingConflict
+	for _, conflict := range workload.conflicts {
+		if conflict, ok := d.checkSchedulingConflict(conflict.pod, trace); ok {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	first := conflicts[0]
+	explanation := explainSchedulingConflict(first.pod, nodes, workload.replicas)
+	return []Issue{{
+		RuleName: rule.Name,
+		Description: fmt.Sprintf("%s: %d pods of %s %s pending for %s: %s; scheduler: %s",
+			rule.Description, len(conflicts), strings.ToLower(workload.kind), workload.name,
+			formatAge(d.clock.Since(podScheduledCondition(first.pod).LastTransitionTime.Time)), explanation, first.message),
+		Severity:   rule.Severity,
+		Resource:   workload.resource.DeepCopyObject(),
+		Namespace:  first.pod.Namespace,
+		Name:       workload.name,
+		Kind:       workload.kind,
+		Reason:     first.reason,
+		Actions:    rule.Actions,
+		Labels:     rule.Labels,
+		DetectedAt: d.clock.Now(),
+	}}
+}
+
+// checkSchedulingConflict checks if a pod has been unschedulable for the
+// threshold and the scheduler blames its anti-affinity or topology spread
+// constraints rather than, e.g., insufficient resources
+func (d *Detector) checkSchedulingConflict(pod *corev1.Pod, trace *Trace) (schedulingConflict, bool) {
+	condition := podScheduledCondition(pod)
+	if !trace.check("status.conditions[PodScheduled].reason", "", conditionReason(condition), corev1.PodReasonUnschedulable, unschedulable(pod)) {
+		return schedulingConflict{}, false
+	}
+	pending := d.clock.Since(condition.LastTransitionTime.Time)
+	after := d.config.SchedulingConflictAfter
+	if !trace.check("unschedulable for", "", formatAge(pending), fmt.Sprintf(">= %s", after), pending >= after) {
+		return schedulingConflict{}, false
+	}
+
+	reason := ""
+	switch {
+	case strings.Contains(condition.Message, "anti-affinity"):
+		reason = reasonAntiAffinityConflict
+	case strings.Contains(condition.Message, "topology spread"):
+		reason = reasonTopologySpreadConflict
+	}
+	if !trace.check("status.conditions[PodScheduled].message", "", condition.Message, "blames anti-affinity or topology spread constraints", reason != "") {
+		return schedulingConflict{}, false
+	}
+	return schedulingConflict{pod: pod, reason: reason, message: condition.Message}, true
+}
+
+// conditionReason returns the reason of a condition, or an empty string
+func conditionReason(condition *corev1.PodCondition) string {
+	if condition == nil {
+		return ""
+	}
+	return condition.Reason
+}
+
+// explainSchedulingConflict compares the anti-affinity and topology spread
+// constraints of a pod with the topology domains of the nodes it may run on,
+// e.g. that one pod per kubernetes.io/hostname cannot place 5 replicas on 3 nodes
+func explainSchedulingConflict(pod *corev1.Pod, nodes []corev1.Node, replicas int32) string {
+	eligible := eligibleNodes(pod, nodes)
+	var explanations []string
+
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.PodAntiAffinity != nil {
+		for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if !selectsPod(term.LabelSelector, pod) {
+				continue
+			}
+			domains := topologyDomains(eligible, term.TopologyKey)
+			if replicas > int32(domains) {
+				explanations = append(explanations, fmt.Sprintf("required anti-affinity allows one pod per %s, but %d replicas exceed the %d eligible %s domains",
+					term.TopologyKey, replicas, domains, term.TopologyKey))
+			} else {
+				explanations = append(explanations, fmt.Sprintf("required anti-affinity allows one pod per %s on %d eligible %s domains for %d replicas",
+					term.TopologyKey, domains, term.TopologyKey, replicas))
+			}
+		}
+	}
+
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		domains := topologyDomains(eligible, constraint.TopologyKey)
+		switch {
+		case domains == 0:
+			explanations = append(explanations, fmt.Sprintf("topology spread over %s, but no eligible node has the label", constraint.TopologyKey))
+		case constraint.MinDomains != nil && int32(domains) < *constraint.MinDomains:
+			explanations = append(explanations, fmt.Sprintf("topology spread requires %d %s domains, but only %d eligible domains exist",
+				*constraint.MinDomains, constraint.TopologyKey, domains))
+		default:
+			explanations = append(explanations, fmt.Sprintf("topology spread with maxSkew %d over %d eligible %s domains",
+				constraint.MaxSkew, domains, constraint.TopologyKey))
+		}
+	}
+
+	if len(explanations) == 0 {
+		return fmt.Sprintf("%d of %d nodes are eligible", len(eligible), len(nodes))
+	}
+	return fmt.Sprintf("%s (%d of %d nodes eligible)", strings.Join(explanations, "; "), len(eligible), len(nodes))
+}
+
+// eligibleNodes returns the ready, schedulable nodes matching the node
+// selector of a pod whose NoSchedule and NoExecute taints the pod tolerates
+func eligibleNodes(pod *corev1.Pod, nodes []corev1.Node) []corev1.Node {
+	var eligible []corev1.Node
+	for _, node := range nodes {
+		if node.Spec.Unschedulable || !nodeReady(&node) {
+			continue
+		}
+		if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if !toleratesTaints(pod, node.Spec.Taints) {
+			continue
+		}
+		eligible = append(eligible, node)
+	}
+	return eligible
+}
+
+// nodeReady returns true if the Ready condition of a node is True
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// toleratesTaints returns true if a pod tolerates the taints that keep pods off a node
+func toleratesTaints(pod *corev1.Pod, taints []corev1.Taint) bool {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !slices.ContainsFunc(pod.Spec.Tolerations, func(toleration corev1.Toleration) bool { return tolerates(toleration, taint) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// tolerates returns true if a toleration matches a taint: an empty key with
+// operator Exists matches all taints, an empty effect all effects
+func tolerates(toleration corev1.Toleration, taint *corev1.Taint) bool {
+	if toleration.Effect != "" && toleration.Effect != taint.Effect {
+		return false
+	}
+	if toleration.Key == "" {
+		return toleration.Operator == corev1.TolerationOpExists
+	}
+	if toleration.Key != taint.Key {
+		return false
+	}
+	return toleration.Operator == corev1.TolerationOpExists || toleration.Value == taint.Value
+}
+
+// topologyDomains counts the distinct values of a topology label among nodes
+func topologyDomains(nodes []corev1.Node, key string) int {
+	domains := make(map[string]bool)
+	for _, node := range nodes {
+		if value, exists := node.Labels[key]; exists {
+			domains[value] = true
+		}
+	}
+	return len(domains)
+}
+
+// selectsPod returns true if a label selector of an anti-affinity term selects
+// the pod itself, i.e. the term spreads the pods of the workload apart
+func selectsPod(selector *metav1.LabelSelector, pod *corev1.Pod) bool {
+	if selector == nil {
+		return false
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	return err == nil && s.Matches(labels.Set(pod.Labels))
+}
+
+// podWorkload returns the Deployment or StatefulSet that controls a pod with
+// its desired replicas, or the pod itself if it has no such controller
+func (d *Detector) podWorkload(ctx context.Context, pod *corev1.Pod) *workloadConflicts {
+	self := &workloadConflicts{kind: KindPod, name: pod.Name, resource: pod, replicas: 1}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return self
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := d.client.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return self
+		}
+		rsOwner := metav1.GetControllerOf(rs)
+		if rsOwner == nil || rsOwner.Kind != KindDeployment {
+			return &workloadConflicts{kind: "ReplicaSet", name: rs.Name, resource: rs, replicas: replicasOf(rs.Spec.Replicas)}
+		}
+		deployment, err := d.client.AppsV1().Deployments(pod.Namespace).Get(ctx, rsOwner.Name, metav1.GetOptions{})
+		if err != nil {
+			return self
+		}
+		return &workloadConflicts{kind: KindDeployment, name: deployment.Name, resource: deployment, replicas: replicasOf(deployment.Spec.Replicas)}
+	case "StatefulSet":
+		statefulSet, err := d.client.AppsV1().StatefulSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return self
+		}
+		return &workloadConflicts{kind: "StatefulSet", name: statefulSet.Name, resource: statefulSet, replicas: replicasOf(statefulSet.Spec.Replicas)}
+	default:
+		return self
+	}
+}
+
+// replicasOf returns the desired replicas of a workload, which default to one
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// traceSchedulingConflict traces the scheduling conflict rule against a pod
+// and the other unschedulable pods of its workload
+func (d *Detector) traceSchedulingConflict(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	pod, err := d.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := d.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	workload := d.podWorkload(ctx, pod)
+	workload.conflicts = []schedulingConflict{{pod: pod}}
+	trace.Kind = KindPod
+	return d.checkSchedulingConflicts(rule, workload, nodes.Items, trace), nil
+}
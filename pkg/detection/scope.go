@@ -0,0 +1,91 @@
+package detection
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// namespaceScope is the set of namespaces detection runs in
+type namespaceScope struct {
+	namespaces map[string]bool
+	// perNamespace lists namespaced resources namespace by namespace instead of
+	// across the cluster
+	perNamespace bool
+}
+
+// SetNamespaceScope limits detection to namespaces; nil runs it in all of them.
+// perNamespace lists pods, deployments, services and persistent volume claims
+// in each namespace of the scope rather than across the cluster, which pays off
+// when the scope is a small part of the cluster. Issues of cluster-scoped
+// resources such as nodes are always reported.
+func (d *Detector) SetNamespaceScope(namespaces []string, perNamespace bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if namespaces == nil {
+		d.scope = nil
+		return
+	}
+	scope := &namespaceScope{namespaces: make(map[string]bool, len(namespaces)), perNamespace: perNamespace}
+	for _, namespace := range namespaces {
+		scope.namespaces[namespace] = true
+	}
+	d.scope = scope
+}
+
+// InScope returns true if detection runs in a namespace; the empty namespace of
+// cluster-scoped resources is always in scope
+func (d *Detector) InScope(namespace string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.scope == nil || namespace == "" || d.scope.namespaces[namespace]
+}
+
+// scopeIssues drops the issues of namespaces out of the scope
+func (d *Detector) scopeIssues(issues []Issue) []Issue {
+	return slices.DeleteFunc(issues, func(issue Issue) bool {
+		return !d.InScope(issue.Namespace)
+	})
+}
+
+// listNamespaces returns the namespaces namespaced resources are listed in,
+// the sorted scope if it is listed namespace by namespace and otherwise all
+// namespaces at once
+func (d *Detector) listNamespaces() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.scope == nil || !d.scope.perNamespace {
+		return []string{metav1.NamespaceAll}
+	}
+	namespaces := make([]string, 0, len(d.scope.namespaces))
+	for namespace := range d.scope.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
+// eachNamespacedListItem calls fn for the objects in scope of a paged list of
+// namespaced resources, listed in each namespace of the scope or across the
+// cluster
+func (d *Detector) eachNamespacedListItem(ctx context.Context, page func(ctx context.Context, namespace string, opts metav1.ListOptions) (runtime.Object, error), fn func(runtime.Object) error) error {
+	for _, namespace := range d.listNamespaces() {
+		err := d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return page(ctx, namespace, opts)
+		}, func(obj runtime.Object) error {
+			if object, err := meta.Accessor(obj); err == nil && !d.InScope(object.GetNamespace()) {
+				return nil
+			}
+			return fn(obj)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
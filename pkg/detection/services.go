@@ -0,0 +1,211 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ServiceWithoutEndpointsRule is the rule reporting Services that have had no
+// ready endpoints for longer than the threshold
+const ServiceWithoutEndpointsRule = "service-without-endpoints"
+
+// Reasons of service without endpoints issues
+const (
+	reasonSelectorMismatch = "SelectorMismatch"
+	reasonBackendsNotReady = "BackendsNotReady"
+)
+
+// maxListedBackends bounds the pods named in the description of an issue
+const maxListedBackends = 3
+
+// detectServicesWithoutEndpoints reports the Services with a selector whose
+// EndpointSlices have had no ready endpoint for the threshold. Pods are only
+// listed once a Service without ready endpoints is found.
+func (d *Detector) detectServicesWithoutEndpoints(ctx context.Context, rule Rule) ([]Issue, error) {
+	services, err := d.client.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	endpointSlices, err := d.client.DiscoveryV1().EndpointSlices("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpointslices: %w", err)
+	}
+	ready := readyEndpoints(endpointSlices.Items)
+
+	var pods []corev1.Pod
+	var issues []Issue
+	for i := range services.Items {
+		service := &services.Items[i]
+		if !selectsPods(service) {
+			continue
+		}
+		key := service.Namespace + "/" + service.Name
+		if ready[key] == 0 && pods == nil {
+			list, err := d.listPods(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list pods: %w", err)
+			}
+			pods = append([]corev1.Pod{}, list.Items...)
+		}
+		issues = append(issues, d.decide(ctx, rule, "Service", service, func(trace *Trace) []Issue {
+			return d.checkServiceEndpoints(ctx, rule, service, ready[key], pods, trace)
+		})...)
+	}
+	return issues, nil
+}
+
+// selectsPods returns true if the endpoints of a Service are managed from its
+// pod selector; Services without a selector have their endpoints set manually
+func selectsPods(service *corev1.Service) bool {
+	return len(service.Spec.Selector) > 0 && service.Spec.Type != corev1.ServiceTypeExternalName
+}
+
+// readyEndpoints counts the ready endpoints of EndpointSlices per Service, by
+// namespace/name. An endpoint whose readiness is unknown counts as ready.
+func readyEndpoints(endpointSlices []discoveryv1.EndpointSlice) map[string]int {
+	ready := make(map[string]int)
+	for _, slice := range endpointSlices {
+		service := slice.Labels[discoveryv1.LabelServiceName]
+		if service == "" {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready[slice.Namespace+"/"+service]++
+			}
+		}
+	}
+	return ready
+}
+
+// checkServiceEndpoints evaluates the service without endpoints rule against a
+// Service. The description tells a selector matching no pods apart from pods
+// that are all not ready; in the latter case the issue is raised on the
+// Deployment running the pods, if any, so the rule's actions apply to it.
+func (d *Detector) checkServiceEndpoints(ctx context.Context, rule Rule, service *corev1.Service, ready int, pods []corev1.Pod, trace *Trace) []Issue {
+	key := conditionKey(rule.Name, service.Namespace, service.Name)
+	if !trace.check("ready endpoints", "", ready, "0", ready == 0) {
+		if !trace.readOnly() {
+			d.config.State.Forget(key)
+		}
+		return nil
+	}
+	held := d.conditionHeld(key, trace)
+	after := d.config.ServiceWithoutEndpointsAfter
+	if !trace.check("without ready endpoints for", "", held.Round(time.Second), fmt.Sprintf(">= %s", after), held >= after) {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	var backends []*corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Namespace == service.Namespace && pod.DeletionTimestamp == nil &&
+			pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed &&
+			selector.Matches(labels.Set(pod.Labels)) {
+			backends = append(backends, pod)
+		}
+	}
+
+	issue := Issue{
+		RuleName:   rule.Name,
+		Severity:   rule.Severity,
+		Resource:   service.DeepCopy(),
+		Namespace:  service.Namespace,
+		Name:       service.Name,
+		Kind:       "Service",
+		Labels:     rule.Labels,
+		DetectedAt: d.clock.Now(),
+	}
+	if len(backends) == 0 {
+		issue.Reason = reasonSelectorMismatch
+		issue.Description = fmt.Sprintf("%s: service %s has had no ready endpoints for %s: its selector %s matches no running pods",
+			rule.Description, service.Name, formatAge(held), selector)
+		return []Issue{issue}
+	}
+
+	issue.Reason = reasonBackendsNotReady
+	issue.Description = fmt.Sprintf("%s: service %s has had no ready endpoints for %s: none of its %d pods is ready (%s)",
+		rule.Description, service.Name, formatAge(held), len(backends), describeBackends(backends))
+	if deployment := d.backendDeployment(ctx, backends); deployment != nil {
+		issue.Resource = deployment.resource.DeepCopyObject()
+		issue.Kind = KindDeployment
+		issue.Name = deployment.name
+		issue.Actions = rule.Actions
+		issue.Description += fmt.Sprintf(", run by deployment %s", deployment.name)
+	}
+	return []Issue{issue}
+}
+
+// backendDeployment returns the Deployment running all backend pods of a
+// Service, or nil if they are not run by a single Deployment
+func (d *Detector) backendDeployment(ctx context.Context, backends []*corev1.Pod) *workloadConflicts {
+	var deployment *workloadConflicts
+	resolved := make(map[string]bool) // Key: kind/name of the controller of a pod
+	for _, pod := range backends {
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			return nil
+		}
+		if resolved[owner.Kind+"/"+owner.Name] {
+			continue
+		}
+		resolved[owner.Kind+"/"+owner.Name] = true
+
+		workload := d.podWorkload(ctx, pod)
+		if workload.kind != KindDeployment || (deployment != nil && workload.name != deployment.name) {
+			return nil
+		}
+		deployment = workload
+	}
+	return deployment
+}
+
+// describeBackends names the first backend pods of a Service with why they are
+// not ready: the waiting reason of a container, or their phase
+func describeBackends(backends []*corev1.Pod) string {
+	var parts []string
+	for _, pod := range backends[:min(len(backends), maxListedBackends)] {
+		state := string(pod.Status.Phase)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+				state = status.State.Waiting.Reason
+				break
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", pod.Name, state))
+	}
+	if more := len(backends) - maxListedBackends; more > 0 {
+		parts = append(parts, fmt.Sprintf("%d more", more))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// traceServiceWithoutEndpoints traces the service without endpoints rule
+// against a Service
+func (d *Detector) traceServiceWithoutEndpoints(ctx context.Context, rule Rule, namespace, name string, trace *Trace) ([]Issue, error) {
+	trace.Kind = "Service"
+	service, err := d.client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	endpointSlices, err := d.client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{discoveryv1.LabelServiceName: name}.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := d.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ready := readyEndpoints(endpointSlices.Items)[namespace+"/"+name]
+	return d.checkServiceEndpoints(ctx, rule, service, ready, pods.Items, trace), nil
+}
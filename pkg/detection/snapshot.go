@@ -0,0 +1,126 @@
+package detection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maxSnapshotImages bounds the images recorded in a snapshot
+const maxSnapshotImages = 10
+
+// Snapshot is a trimmed manifest of the resource of an issue at detection time:
+// enough to tell what it looked like after it changed, without the full object
+type Snapshot struct {
+	ResourceVersion string `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty"`
+	Generation      int64  `json:"generation,omitempty" yaml:"generation,omitempty"`
+	// SpecHash is a SHA-256 prefix of the spec, to tell whether the spec changed
+	// since the issue was detected
+	SpecHash string `json:"specHash,omitempty" yaml:"specHash,omitempty"`
+	// Images are the images of the containers and init containers of a pod or
+	// pod template
+	Images []string `json:"images,omitempty" yaml:"images,omitempty"`
+	// Replicas are the replica counts of a scalable workload
+	Replicas *ReplicaCounts `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+	Phase    string         `json:"phase,omitempty" yaml:"phase,omitempty"`
+	Reason   string         `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// Conditions are the status conditions as Type=Status, e.g. Ready=False
+	Conditions []string `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// ReplicaCounts are the desired and observed replicas of a workload
+type ReplicaCounts struct {
+	Desired   int64 `json:"desired" yaml:"desired"`
+	Current   int64 `json:"current" yaml:"current"`
+	Ready     int64 `json:"ready" yaml:"ready"`
+	Updated   int64 `json:"updated" yaml:"updated"`
+	Available int64 `json:"available" yaml:"available"`
+}
+
+// NewSnapshot returns the snapshot of a resource, nil if there is none. The
+// fields are read from the unstructured form of the object, so resources of any
+// kind are snapshotted alike.
+func NewSnapshot(obj runtime.Object) *Snapshot {
+	if obj == nil {
+		return nil
+	}
+	var u *unstructured.Unstructured
+	if typed, ok := obj.(*unstructured.Unstructured); ok {
+		u = typed
+	} else {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil
+		}
+		u = &unstructured.Unstructured{Object: content}
+	}
+
+	snapshot := &Snapshot{
+		ResourceVersion: u.GetResourceVersion(),
+		Generation:      u.GetGeneration(),
+		SpecHash:        specHash(u.Object["spec"]),
+		Images:          snapshotImages(u.Object),
+	}
+	if desired, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas"); found {
+		count := func(field string) int64 {
+			value, _, _ := unstructured.NestedInt64(u.Object, "status", field)
+			return value
+		}
+		snapshot.Replicas = &ReplicaCounts{
+			Desired:   desired,
+			Current:   count("replicas"),
+			Ready:     count("readyReplicas"),
+			Updated:   count("updatedReplicas"),
+			Available: count("availableReplicas"),
+		}
+	}
+	snapshot.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+	snapshot.Reason, _, _ = unstructured.NestedString(u.Object, "status", "reason")
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, condition := range conditions {
+		if fields, ok := condition.(map[string]interface{}); ok {
+			snapshot.Conditions = append(snapshot.Conditions, fmt.Sprintf("%v=%v", fields["type"], fields["status"]))
+		}
+	}
+	return snapshot
+}
+
+// specHash returns the first 16 hex digits of the SHA-256 of a spec, empty
+// without a spec
+func specHash(spec interface{}) string {
+	if spec == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// snapshotImages returns the distinct images of the pod spec of a pod, or of
+// the pod template of a workload
+func snapshotImages(content map[string]interface{}) []string {
+	var images []string
+	for _, podSpec := range [][]string{{"spec"}, {"spec", "template", "spec"}, {"spec", "jobTemplate", "spec", "template", "spec"}} {
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _, _ := unstructured.NestedSlice(content, append(slices.Clone(podSpec), field)...)
+			for _, container := range containers {
+				fields, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if image, ok := fields["image"].(string); ok && image != "" && !slices.Contains(images, image) && len(images) < maxSnapshotImages {
+					images = append(images, image)
+				}
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,376 @@
+package detection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// State backends
+const (
+	StateBackendMemory    = "memory"
+	StateBackendConfigMap = "configmap"
+	StateBackendFile      = "file"
+)
+
+// StateConfigMapKey is the ConfigMap data key holding the condition state
+const StateConfigMapKey = "state.json"
+
+// RemediationsConfigMapKey is the ConfigMap data key holding the idempotency keys
+// of executed remediation actions, next to the condition state
+const RemediationsConfigMapKey = "remediations.json"
+
+// LifecycleConfigMapKey is the ConfigMap data key holding the start and last
+// uptime of the latest controller run, next to the condition state
+const LifecycleConfigMapKey = "lifecycle.json"
+
+// ConditionState records when a condition was first and last observed to hold
+type ConditionState struct {
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Backend persists the value of a store, encoded as JSON, so it survives
+// restarts. Besides condition state it persists the other stores sharing the
+// state backend, such as the remediation queue.
+type Backend[T any] interface {
+	Load(ctx context.Context) (T, error)
+	Save(ctx context.Context, value T) error
+}
+
+// StateBackend persists condition state so it survives restarts
+type StateBackend = Backend[map[string]ConditionState]
+
+// StateStore tracks when duration-based conditions were first observed. State
+// is kept in memory and written to the backend, if any, by Flush.
+type StateStore struct {
+	mu      sync.Mutex
+	backend StateBackend
+	states  map[string]ConditionState
+	loaded  bool
+	dirty   bool
+}
+
+// NewStateStore creates a state store persisted by the given backend; a nil
+// backend keeps state in memory only
+func NewStateStore(backend StateBackend) *StateStore {
+	return &StateStore{
+		backend: backend,
+		states:  make(map[string]ConditionState),
+		loaded:  backend == nil,
+	}
+}
+
+// Load reads persisted state from the backend. Conditions observed before
+// loading keep their earlier first-seen time.
+func (s *StateStore) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded {
+		return nil
+	}
+
+	states, err := s.backend.Load(ctx)
+	if err != nil {
+		return err
+	}
+	for key, state := range states {
+		if current, exists := s.states[key]; exists {
+			if state.FirstSeen.Before(current.FirstSeen) {
+				current.FirstSeen = state.FirstSeen
+			}
+			state = current
+		}
+		s.states[key] = state
+	}
+	s.loaded = true
+	s.dirty = true
+	return nil
+}
+
+// Observe records that a condition holds at now and returns its state
+func (s *StateStore) Observe(key string, now time.Time) ConditionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.states[key]
+	if !exists {
+		state.FirstSeen = now
+	}
+	state.LastSeen = now
+	s.states[key] = state
+	s.dirty = true
+	return state
+}
+
+// Get returns the state of a condition without observing it
+func (s *StateStore) Get(key string) (ConditionState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, exists := s.states[key]
+	return state, exists
+}
+
+// Claim records key at now unless it was already recorded within window, and
+// returns true if the caller may proceed. Claims older than window are replaced.
+func (s *StateStore) Claim(key string, now time.Time, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state, exists := s.states[key]; exists && now.Sub(state.FirstSeen) < window {
+		return false
+	}
+	s.states[key] = ConditionState{FirstSeen: now, LastSeen: now}
+	s.dirty = true
+	return true
+}
+
+// Forget removes key, e.g. to release a claim
+func (s *StateStore) Forget(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.states[key]; exists {
+		delete(s.states, key)
+		s.dirty = true
+	}
+}
+
+// Expire removes conditions last observed before cutoff and returns the number removed
+func (s *StateStore) Expire(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for key, state := range s.states {
+		if state.LastSeen.Before(cutoff) {
+			delete(s.states, key)
+			expired++
+		}
+	}
+	if expired > 0 {
+		s.dirty = true
+	}
+	return expired
+}
+
+// Snapshot returns a copy of the tracked conditions
+func (s *StateStore) Snapshot() map[string]ConditionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[string]ConditionState, len(s.states))
+	for key, state := range s.states {
+		states[key] = state
+	}
+	return states
+}
+
+// Merge adds conditions, e.g. imported from another instance. Conditions that
+// are already tracked keep the earlier first-seen and the later last-seen time.
+func (s *StateStore) Merge(states map[string]ConditionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, state := range states {
+		if current, exists := s.states[key]; exists {
+			if current.FirstSeen.Before(state.FirstSeen) {
+				state.FirstSeen = current.FirstSeen
+			}
+			if current.LastSeen.After(state.LastSeen) {
+				state.LastSeen = current.LastSeen
+			}
+		}
+		s.states[key] = state
+	}
+	if len(states) > 0 {
+		s.dirty = true
+	}
+}
+
+// Len returns the number of tracked conditions
+func (s *StateStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.states)
+}
+
+// Flush writes changed state to the backend. State is not written before it
+// has been loaded so a failed load cannot overwrite persisted state.
+func (s *StateStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	if s.backend == nil || !s.loaded || !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	states := make(map[string]ConditionState, len(s.states))
+	for key, state := range s.states {
+		states[key] = state
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := s.backend.Save(ctx, states); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// configMapBackend stores a value under a data key of a ConfigMap
+type configMapBackend[T any] struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	key       string
+}
+
+// NewConfigMapBackend creates a backend storing state in the named ConfigMap
+func NewConfigMapBackend(client kubernetes.Interface, namespace, name string) StateBackend {
+	return NewConfigMapKeyBackend(client, namespace, name, StateConfigMapKey)
+}
+
+// NewConfigMapKeyBackend creates a backend storing state under a data key of the
+// named ConfigMap, so several stores can share one ConfigMap
+func NewConfigMapKeyBackend(client kubernetes.Interface, namespace, name, key string) StateBackend {
+	return NewConfigMapValueBackend[map[string]ConditionState](client, namespace, name, key)
+}
+
+// NewConfigMapValueBackend creates a backend storing the value of any store, such
+// as the remediation queue, under a data key of the named ConfigMap
+func NewConfigMapValueBackend[T any](client kubernetes.Interface, namespace, name, key string) Backend[T] {
+	return &configMapBackend[T]{client: client, namespace: namespace, name: name, key: key}
+}
+
+// Load reads the value from the ConfigMap; a missing ConfigMap is the zero value
+func (b *configMapBackend[T]) Load(ctx context.Context) (T, error) {
+	var zero T
+	cm, err := b.client.CoreV1().ConfigMaps(b.namespace).Get(ctx, b.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return zero, nil
+	}
+	if err != nil {
+		return zero, fmt.Errorf("failed to get state configmap: %w", err)
+	}
+	return decodeValue[T]([]byte(cm.Data[b.key]))
+}
+
+// Save writes the value to the ConfigMap, creating it if needed
+func (b *configMapBackend[T]) Save(ctx context.Context, value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	configMaps := b.client.CoreV1().ConfigMaps(b.namespace)
+	cm, err := configMaps.Get(ctx, b.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      b.name,
+				Namespace: b.namespace,
+				Labels:    map[string]string{"app.kubernetes.io/name": "kubeguardian"},
+			},
+			Data: map[string]string{b.key: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create state configmap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get state configmap: %w", err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[b.key] = string(data)
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update state configmap: %w", err)
+	}
+	return nil
+}
+
+// fileBackend stores a value in a file, e.g. on a persistent volume
+type fileBackend[T any] struct {
+	path string
+}
+
+// NewFileBackend creates a backend storing state in the file at path
+func NewFileBackend(path string) StateBackend {
+	return NewFileValueBackend[map[string]ConditionState](path)
+}
+
+// NewFileValueBackend creates a backend storing the value of any store in the
+// file at path
+func NewFileValueBackend[T any](path string) Backend[T] {
+	return &fileBackend[T]{path: path}
+}
+
+// Load reads the value from the file; a missing file is the zero value
+func (b *fileBackend[T]) Load(ctx context.Context) (T, error) {
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		var zero T
+		return zero, nil
+	}
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to read state file: %w", err)
+	}
+	return decodeValue[T](data)
+}
+
+// Save atomically replaces the file
+func (b *fileBackend[T]) Save(ctx context.Context, value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.path), ".state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), b.path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
+
+// decodeValue parses a serialized value; empty data is the zero value
+func decodeValue[T any](data []byte) (T, error) {
+	var value T
+	if len(data) == 0 {
+		return value, nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to decode state: %w", err)
+	}
+	return value, nil
+}
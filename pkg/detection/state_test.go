@@ -0,0 +1,152 @@
+package detection
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStateStoreObserveAndExpire(t *testing.T) {
+	store := NewStateStore(nil)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store.Observe("a", start)
+	state := store.Observe("a", start.Add(10*time.Minute))
+	if !state.FirstSeen.Equal(start) || !state.LastSeen.Equal(start.Add(10*time.Minute)) {
+		t.Errorf("unexpected state: %+v", state)
+	}
+
+	store.Observe("b", start.Add(2*time.Minute))
+	if expired := store.Expire(start.Add(5 * time.Minute)); expired != 1 {
+		t.Errorf("Expire() = %d, want 1", expired)
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", store.Len())
+	}
+}
+
+func TestStateStoreMerge(t *testing.T) {
+	store := NewStateStore(nil)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.Observe("a", start.Add(time.Minute))
+
+	store.Merge(map[string]ConditionState{
+		"a": {FirstSeen: start, LastSeen: start},
+		"b": {FirstSeen: start, LastSeen: start},
+	})
+	if state, _ := store.Get("a"); !state.FirstSeen.Equal(start) || !state.LastSeen.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the earlier first-seen and later last-seen time, got %+v", state)
+	}
+	if snapshot := store.Snapshot(); len(snapshot) != 2 {
+		t.Errorf("Snapshot() = %v, want 2 conditions", snapshot)
+	}
+}
+
+func TestStateStoreBackends(t *testing.T) {
+	backends := map[string]func() StateBackend{
+		"configmap": func() StateBackend {
+			return NewConfigMapBackend(fake.NewSimpleClientset(), "kubeguardian", "kubeguardian-state")
+		},
+		"file": func() StateBackend {
+			return NewFileBackend(filepath.Join(t.TempDir(), "state.json"))
+		},
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			backend := newBackend()
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+			// Empty state when nothing was persisted yet
+			store := NewStateStore(backend)
+			if err := store.Load(ctx); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			store.Observe("crash-loop-backoff/default/web/app", start)
+			if err := store.Flush(ctx); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			// Second flush updates the existing object
+			store.Observe("crash-loop-backoff/default/web/app", start.Add(time.Minute))
+			if err := store.Flush(ctx); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			// A restarted store keeps the original first-seen time
+			restarted := NewStateStore(backend)
+			restarted.Observe("crash-loop-backoff/default/web/app", start.Add(5*time.Minute))
+			if err := restarted.Load(ctx); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			state := restarted.Observe("crash-loop-backoff/default/web/app", start.Add(6*time.Minute))
+			if !state.FirstSeen.Equal(start) {
+				t.Errorf("FirstSeen = %v, want %v", state.FirstSeen, start)
+			}
+		})
+	}
+}
+
+func TestConfigMapValueBackendSharesConfigMap(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	states := NewConfigMapBackend(client, "kubeguardian", "kubeguardian-state")
+	values := NewConfigMapValueBackend[[]string](client, "kubeguardian", "kubeguardian-state", "values.json")
+
+	if got, err := values.Load(ctx); err != nil || got != nil {
+		t.Fatalf("Load() = %v, %v, want the zero value", got, err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := states.Save(ctx, map[string]ConditionState{"a": {FirstSeen: start, LastSeen: start}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := values.Save(ctx, []string{"x", "y"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := values.Load(ctx)
+	if err != nil || len(got) != 2 || got[1] != "y" {
+		t.Errorf("Load() = %v, %v, want [x y]", got, err)
+	}
+	if saved, err := states.Load(ctx); err != nil || len(saved) != 1 {
+		t.Errorf("condition state was not kept next to the value: %v, %v", saved, err)
+	}
+}
+
+func TestStateStoreFlushBeforeLoad(t *testing.T) {
+	backend := NewFileBackend(filepath.Join(t.TempDir(), "state.json"))
+	ctx := context.Background()
+
+	store := NewStateStore(backend)
+	store.Observe("a", time.Now())
+	if err := store.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	states, err := backend.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("state written before it was loaded: %v", states)
+	}
+}
+
+func TestConditionHeldFor(t *testing.T) {
+	detector := NewDetector(fake.NewSimpleClientset(), DetectionConfig{})
+
+	if !detector.conditionHeldFor("a", 0) {
+		t.Error("condition without duration should hold immediately")
+	}
+	if detector.conditionHeldFor("b", time.Minute) {
+		t.Error("newly observed condition should not hold for a minute")
+	}
+
+	detector.State().Observe("c", time.Now().Add(-2*time.Minute))
+	if !detector.conditionHeldFor("c", time.Minute) {
+		t.Error("condition observed two minutes ago should hold for a minute")
+	}
+}
@@ -0,0 +1,166 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultStatusConditionTypes are the condition types a status conditions rule
+// checks unless it lists its own
+var DefaultStatusConditionTypes = []string{"Ready", "Available", "Healthy"}
+
+// StatusConditionsCheck selects resources, typically the custom resources of an
+// operator, whose status.conditions follow the Kubernetes convention. An issue is
+// reported for every condition of one of the types that has been False for the
+// duration.
+type StatusConditionsCheck struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Selector   string           `yaml:"selector"` // Label selector, e.g. app.kubernetes.io/part-of=payments
+	Types      []string         `yaml:"types"`    // Default: DefaultStatusConditionTypes
+	Duration   *metav1.Duration `yaml:"duration"`
+}
+
+// conditionTypes returns the condition types of the check
+func (c *StatusConditionsCheck) conditionTypes() []string {
+	if len(c.Types) > 0 {
+		return c.Types
+	}
+	return DefaultStatusConditionTypes
+}
+
+// duration returns how long a condition must be False to report an issue
+func (c *StatusConditionsCheck) duration() time.Duration {
+	if c.Duration == nil {
+		return 0
+	}
+	return c.Duration.Duration
+}
+
+// validate checks that the resources of the check can be selected
+func (c *StatusConditionsCheck) validate() error {
+	if c.APIVersion == "" || c.Kind == "" {
+		return fmt.Errorf("status conditions need an apiVersion and a kind")
+	}
+	if _, err := schema.ParseGroupVersion(c.APIVersion); err != nil {
+		return fmt.Errorf("invalid apiVersion %q: %w", c.APIVersion, err)
+	}
+	if _, err := labels.Parse(c.Selector); err != nil {
+		return fmt.Errorf("invalid selector %q: %w", c.Selector, err)
+	}
+	if slices.Contains(c.Types, "") {
+		return fmt.Errorf("empty condition type")
+	}
+	return nil
+}
+
+// detectStatusConditions evaluates a status conditions rule against the selected resources
+func (d *Detector) detectStatusConditions(ctx context.Context, rule Rule) ([]Issue, error) {
+	var issues []Issue
+
+	check := rule.StatusConditions
+	resource, _, err := d.dynamicResource(check.APIVersion, check.Kind)
+	if err != nil {
+		return issues, err
+	}
+	err = d.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		opts.LabelSelector = check.Selector
+		return resource.List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		issues = append(issues, d.decide(ctx, rule, check.Kind, obj, func(trace *Trace) []Issue {
+			return d.checkStatusConditions(rule, obj, trace)
+		})...)
+		return nil
+	})
+	if err != nil {
+		return issues, fmt.Errorf("failed to list %s resources: %w", check.Kind, err)
+	}
+
+	return issues, nil
+}
+
+// checkStatusConditions reports the conditions of a resource that are False. How
+// long a condition has been False is taken from its lastTransitionTime, or from
+// when it was first observed False if the resource does not set it.
+func (d *Detector) checkStatusConditions(rule Rule, obj runtime.Object, trace *Trace) []Issue {
+	var issues []Issue
+
+	check := rule.StatusConditions
+	object, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return issues
+	}
+	if check.Selector != "" {
+		selector, err := labels.Parse(check.Selector)
+		matched := err == nil && selector.Matches(labels.Set(object.GetLabels()))
+		if !trace.check("labels", "", labels.Set(object.GetLabels()), check.Selector, matched) {
+			return issues
+		}
+	}
+	conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+	if !trace.check("status.conditions", "", len(conditions), "reported", len(conditions) > 0) {
+		return issues
+	}
+
+	byType := make(map[string]map[string]interface{}, len(conditions))
+	for _, condition := range conditions {
+		if fields, ok := condition.(map[string]interface{}); ok {
+			if conditionType, ok := fields["type"].(string); ok {
+				byType[conditionType] = fields
+			}
+		}
+	}
+
+	duration := check.duration()
+	for _, conditionType := range check.conditionTypes() {
+		condition, exists := byType[conditionType]
+		if !exists {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		if !trace.check(fmt.Sprintf("status.conditions[type=%s].status", conditionType), "", status, "False", status == "False") {
+			continue
+		}
+
+		var held time.Duration
+		transitioned, err := time.Parse(time.RFC3339, fmt.Sprint(condition["lastTransitionTime"]))
+		if err == nil {
+			held = d.clock.Since(transitioned)
+		} else {
+			held = d.conditionHeld(conditionKey(rule.Name, object.GetNamespace(), object.GetName(), conditionType), trace)
+		}
+		if !trace.check(conditionType+" False for", "", held.Round(time.Second), fmt.Sprintf(">= %s", duration), held >= duration) {
+			continue
+		}
+
+		description := fmt.Sprintf("%s (%s %s is False", rule.Description, check.Kind, conditionType)
+		for _, key := range []string{"reason", "message"} {
+			if value, _ := condition[key].(string); strings.TrimSpace(value) != "" {
+				description += ": " + strings.TrimSpace(value)
+			}
+		}
+		issues = append(issues, Issue{
+			RuleName:    rule.Name,
+			Description: description + ")",
+			Severity:    rule.Severity,
+			Resource:    obj,
+			Namespace:   object.GetNamespace(),
+			Name:        object.GetName(),
+			Kind:        check.Kind,
+			Reason:      conditionType + "False",
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		})
+	}
+	return issues
+}
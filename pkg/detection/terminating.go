@@ -0,0 +1,77 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StuckTerminatingRule is the rule reporting pods stuck terminating beyond their grace period
+const StuckTerminatingRule = "stuck-terminating-pod"
+
+// ForceDeletePodAction deletes a pod stuck terminating without waiting for its kubelet
+const ForceDeletePodAction = "force-delete-pod"
+
+// checkStuckTerminating evaluates the stuck terminating rule against a pod. The
+// deletion timestamp of a pod is the end of its grace period, so the pod is
+// reported once it is still there StuckTerminatingAfter later. The description
+// tells the usual causes apart: pending finalizers or a node that is gone or
+// not ready, whose kubelet never confirms the deletion.
+func (d *Detector) checkStuckTerminating(ctx context.Context, rule Rule, pod *corev1.Pod, trace *Trace) []Issue {
+	deleting := pod.DeletionTimestamp
+	if !trace.check("metadata.deletionTimestamp", "", deleting, "set", deleting != nil) {
+		return nil
+	}
+	overdue := d.clock.Since(deleting.Time).Round(time.Second)
+	after := d.config.StuckTerminatingAfter
+	if !trace.check("terminating beyond grace period for", "", overdue, fmt.Sprintf(">= %s", after), overdue >= after) {
+		return nil
+	}
+
+	reason, cause := "StuckTerminating", "its kubelet has not confirmed the deletion"
+	if node := d.unavailableNode(ctx, pod.Spec.NodeName); node != "" {
+		reason, cause = "NodeLost", node
+	} else if len(pod.Finalizers) > 0 {
+		reason, cause = "FinalizerPending", fmt.Sprintf("waiting on finalizers %s", strings.Join(pod.Finalizers, ", "))
+	}
+
+	return []Issue{{
+		RuleName:    rule.Name,
+		Description: fmt.Sprintf("%s: terminating %s beyond its grace period, %s", rule.Description, formatAge(overdue), cause),
+		Severity:    rule.Severity,
+		Resource:    pod.DeepCopyObject(),
+		Namespace:   pod.Namespace,
+		Name:        pod.Name,
+		Kind:        KindPod,
+		Reason:      reason,
+		Actions:     rule.Actions,
+		Labels:      rule.Labels,
+		DetectedAt:  d.clock.Now(),
+	}}
+}
+
+// unavailableNode describes why the node of a pod cannot confirm its deletion,
+// or returns an empty string if the node is ready or cannot be read
+func (d *Detector) unavailableNode(ctx context.Context, name string) string {
+	if name == "" {
+		return ""
+	}
+	node, err := d.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Sprintf("node %s no longer exists", name)
+	}
+	if err != nil {
+		return ""
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue {
+			return fmt.Sprintf("node %s is not ready", name)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package detection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrUnknownRule is returned for a rule name that is not loaded
+var ErrUnknownRule = errors.New("unknown rule")
+
+// podChecks evaluate the pod rules against a single pod
+var podChecks = map[string]func(*Detector, context.Context, Rule, *corev1.Pod, *Trace) []Issue{
+	"crash-loop-backoff": (*Detector).checkCrashLoopBackOff,
+	"high-cpu-usage":     (*Detector).checkHighCPUUsage,
+	"high-memory-usage":  (*Detector).checkHighMemoryUsage,
+	"oom-kill-detected":  (*Detector).checkOOMKilled,
+
+	"init-container-failure":  (*Detector).checkInitContainerFailure,
+	"ephemeral-container-age": (*Detector).checkEphemeralContainerAge,
+	StuckTerminatingRule:      (*Detector).checkStuckTerminating,
+	PodFlappingRule:           (*Detector).checkPodFlapping,
+}
+
+// deploymentChecks evaluate the deployment rules against a single deployment
+var deploymentChecks = map[string]func(*Detector, context.Context, Rule, *appsv1.Deployment, *Trace) []Issue{
+	"failed-deployment": (*Detector).checkFailedDeployment,
+	"stuck-rollout":     (*Detector).checkStuckRollout,
+}
+
+// TraceStep is a single check made while evaluating a rule
+type TraceStep struct {
+	// Condition is the checked field or setting
+	Condition string `json:"condition"`
+	// Container is set for checks of a single container
+	Container string `json:"container,omitempty"`
+	// Value is the value seen, Expected the value the check requires
+	Value    string `json:"value"`
+	Expected string `json:"expected"`
+	Matched  bool   `json:"matched"`
+}
+
+// Trace is the condition-by-condition evaluation of a rule against a single resource
+type Trace struct {
+	Rule      string `json:"rule"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	// Fired is true if the rule reports an issue for the resource
+	Fired bool `json:"fired"`
+	// Reasons are the reasons of the issues the rule reports
+	Reasons []string `json:"reasons,omitempty"`
+	// Steps are the checks in evaluation order; evaluation of a container stops
+	// at its first unmatched check
+	Steps []TraceStep `json:"steps"`
+
+	// decision is set on traces of the decision log, which record a detection
+	// cycle and so update the state of duration-based conditions
+	decision bool
+}
+
+// readOnly returns true if evaluating with the trace must not change the state
+// of duration-based conditions, i.e. for traces requested through TraceRule
+func (t *Trace) readOnly() bool {
+	return t != nil && !t.decision
+}
+
+// check records a check in the trace and returns matched; it is a no-op on a nil trace
+func (t *Trace) check(condition, container string, value interface{}, expected string, matched bool) bool {
+	if t != nil {
+		t.Steps = append(t.Steps, TraceStep{
+			Condition: condition,
+			Container: container,
+			Value:     fmt.Sprint(value),
+			Expected:  expected,
+			Matched:   matched,
+		})
+	}
+	return matched
+}
+
+// TraceRule evaluates a single rule against a single resource and returns the
+// evaluation trace. Tracing does not update the state of duration-based conditions.
+func (d *Detector) TraceRule(ctx context.Context, ruleName, namespace, name string) (*Trace, error) {
+	var rule *Rule
+	for i := range d.rules {
+		if d.rules[i].Name == ruleName {
+			rule = &d.rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRule, ruleName)
+	}
+
+	trace := &Trace{Rule: rule.Name, Namespace: namespace, Name: name, Steps: []TraceStep{}}
+	active := trace.check("rule enabled", "", rule.Enabled, "true", rule.Enabled)
+	silenced := d.Silenced(rule.Name)
+	active = trace.check("rule not disabled at runtime", "", !silenced, "true", !silenced) && active
+	if rule.Schedule != "" {
+		window, exists := d.config.Schedules[rule.Schedule]
+		open := exists && window.Active(d.clock.Now())
+		active = trace.check("schedule "+rule.Schedule+" open", "", open, "true", open) && active
+	}
+
+	var issues []Issue
+	podCheck, isPodRule := podChecks[rule.Name]
+	deploymentCheck, isDeploymentRule := deploymentChecks[rule.Name]
+	query, isQueryRule := rule.queryCondition()
+	switch {
+	case rule.StatusConditions != nil:
+		trace.Kind = rule.StatusConditions.Kind
+		obj, err := d.getFieldResource(ctx, RuleCondition{Resource: rule.StatusConditions.Kind, APIVersion: rule.StatusConditions.APIVersion}, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		issues = d.checkStatusConditions(*rule, obj, trace)
+	case rule.Probe != nil:
+		trace.Namespace, trace.Name, trace.Kind = probeSubject(rule.Probe.host())
+		issues = d.checkProbe(ctx, *rule, trace)
+	case rule.Name == StuckFinalizerRule:
+		var err error
+		if issues, err = d.traceStuckFinalizer(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == NodeProblemRule:
+		var err error
+		if issues, err = d.traceNodeProblems(ctx, *rule, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == NodeConditionRule:
+		var err error
+		if issues, err = d.traceNodeConditions(ctx, *rule, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == SchedulingConflictRule:
+		var err error
+		if issues, err = d.traceSchedulingConflict(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == ServiceWithoutEndpointsRule:
+		var err error
+		if issues, err = d.traceServiceWithoutEndpoints(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == ResourceQuotaRule:
+		var err error
+		if issues, err = d.traceResourceQuota(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == WarningEventRule:
+		var err error
+		if issues, err = d.traceWarningEvents(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case rule.Name == EvictedPodsRule:
+		if namespace == "" {
+			namespace = name
+		}
+		var err error
+		if issues, err = d.traceEvictedPods(ctx, *rule, namespace, trace); err != nil {
+			return nil, err
+		}
+	case isQueryRule:
+		trace.Kind = query.Resource
+		var err error
+		if issues, err = d.checkQuery(ctx, *rule, namespace, name, trace); err != nil {
+			return nil, err
+		}
+	case isPodRule:
+		trace.Kind = "Pod"
+		pod, err := d.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		issues = podCheck(d, ctx, *rule, pod, trace)
+	case isDeploymentRule:
+		trace.Kind = "Deployment"
+		deployment, err := d.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		issues = deploymentCheck(d, ctx, *rule, deployment, trace)
+	case rule.isFieldRule():
+		trace.Kind = rule.Conditions[0].Resource
+		conditions, err := compileFieldConditions(*rule)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := d.getFieldResource(ctx, rule.Conditions[0], namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		issues = d.checkFields(ctx, *rule, conditions, obj, trace)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRule, rule.Name)
+	}
+
+	issues = applyContainerRules(*rule, issues, trace)
+	trace.Fired = active && len(issues) > 0
+	if trace.Fired {
+		for _, issue := range issues {
+			trace.Reasons = append(trace.Reasons, issue.Reason)
+		}
+	}
+	return trace, nil
+}
@@ -0,0 +1,148 @@
+package detection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// usageMaxAge is how long listed pod metrics are reused, so the CPU and memory
+// rules of a detection cycle share one list request
+const usageMaxAge = 10 * time.Second
+
+// containerUsage is the resource usage of the containers of a pod, by container name
+type containerUsage map[string]corev1.ResourceList
+
+// usageSource lists the resource usage of pods from the metrics.k8s.io API
+// served by metrics-server
+type usageSource struct {
+	client        metricsclientset.Interface
+	retryInterval time.Duration
+
+	mu               sync.Mutex
+	pods             map[string]containerUsage // Key: namespace/name
+	listedAt         time.Time
+	unavailableUntil time.Time
+}
+
+// SetMetricsClient sets the client of the metrics.k8s.io API the CPU and memory
+// rules compare actual container usage against their thresholds with. While the
+// API cannot be listed, e.g. because metrics-server is not installed, the rules
+// fall back to restart heuristics and the API is retried after retryInterval.
+// A nil client always uses the heuristics.
+func (d *Detector) SetMetricsClient(client metricsclientset.Interface, retryInterval time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if client == nil {
+		d.usage = nil
+		return
+	}
+	d.usage = &usageSource{client: client, retryInterval: retryInterval}
+}
+
+// podUsage returns the container usage of a pod and true if pod metrics are
+// available. A pod missing from available metrics, e.g. because it just started,
+// has no container usage.
+func (d *Detector) podUsage(ctx context.Context, pod *corev1.Pod) (containerUsage, bool) {
+	d.mu.RLock()
+	source := d.usage
+	d.mu.RUnlock()
+	if source == nil {
+		return nil, false
+	}
+
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	now := d.clock.Now()
+	if now.Before(source.unavailableUntil) {
+		return nil, false
+	}
+	if source.pods == nil || now.Sub(source.listedAt) >= usageMaxAge {
+		list, err := source.client.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			log.FromContext(ctx).Info("Pod metrics unavailable, CPU and memory rules fall back to restart heuristics",
+				"error", err.Error(), "retryIn", source.retryInterval)
+			source.pods = nil
+			source.unavailableUntil = now.Add(source.retryInterval)
+			return nil, false
+		}
+
+		source.pods = make(map[string]containerUsage, len(list.Items))
+		for _, podMetrics := range list.Items {
+			usage := make(containerUsage, len(podMetrics.Containers))
+			for _, container := range podMetrics.Containers {
+				usage[container.Name] = container.Usage
+			}
+			source.pods[podMetrics.Namespace+"/"+podMetrics.Name] = usage
+		}
+		source.listedAt = now
+	}
+	return source.pods[pod.Namespace+"/"+pod.Name], true
+}
+
+// checkMeasuredUsage reports the containers of a pod whose usage of resource is at
+// least thresholdPercent of their limit, or of their request if they have no
+// limit, for at least duration
+func (d *Detector) checkMeasuredUsage(rule Rule, pod *corev1.Pod, usage containerUsage, resource corev1.ResourceName, thresholdPercent float64, duration time.Duration, reason string, trace *Trace) []Issue {
+	var issues []Issue
+
+	for _, container := range pod.Spec.Containers {
+		used, reported := usage[container.Name][resource]
+		if !trace.check(fmt.Sprintf("metrics.containers[*].usage.%s", resource), container.Name, used.String(), "reported", reported) {
+			continue
+		}
+
+		capacity, basis := container.Resources.Limits[resource], "limit"
+		if capacity.IsZero() {
+			capacity, basis = container.Resources.Requests[resource], "request"
+		}
+		if !trace.check(fmt.Sprintf("resources.limits.%[1]s or resources.requests.%[1]s", resource), container.Name, capacity.String(), "set", !capacity.IsZero()) {
+			continue
+		}
+
+		percent := used.AsApproximateFloat64() / capacity.AsApproximateFloat64() * 100
+		if !trace.check(fmt.Sprintf("%s usage of %s", resource, basis), container.Name, fmt.Sprintf("%.1f%%", percent),
+			fmt.Sprintf(">= %.1f%%", thresholdPercent), percent >= thresholdPercent) {
+			continue
+		}
+
+		// Check if the usage has been high for the required duration
+		held := d.conditionHeld(conditionKey(rule.Name, pod.Namespace, pod.Name, container.Name), trace)
+		if !trace.check("usage high for", container.Name, held, fmt.Sprintf(">= %s", duration), held >= duration) {
+			continue
+		}
+
+		issue := Issue{
+			RuleName:    rule.Name,
+			Description: fmt.Sprintf("%s (threshold: %.1f%%, usage: %.1f%% of %s %s)", rule.Description, thresholdPercent, percent, basis, capacity.String()),
+			Severity:    rule.Severity,
+			Resource:    pod.DeepCopyObject(),
+			Namespace:   pod.Namespace,
+			Name:        pod.Name,
+			Kind:        "Pod",
+			Reason:      reason,
+			Container:   container.Name,
+			Actions:     rule.Actions,
+			Labels:      rule.Labels,
+			DetectedAt:  d.clock.Now(),
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// usageSourceName names where the CPU and memory rules take usage from in traces
+func usageSourceName(measured bool) string {
+	if measured {
+		return "metrics-server"
+	}
+	return "restart heuristics"
+}
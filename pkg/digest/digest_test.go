@@ -0,0 +1,268 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+)
+
+type fakeSender struct {
+	sent []Summary
+	err  error
+}
+
+func (f *fakeSender) Send(_ context.Context, summary Summary) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, summary)
+	return nil
+}
+
+func TestStatsSummarize(t *testing.T) {
+	s := NewStats(nil)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	s.RecordDetected("prod", "Deployment", "api", start.Add(10*time.Minute))
+	s.RecordDetected("prod", "Deployment", "api", start.Add(2*time.Hour))
+	s.RecordDetected("prod", "StatefulSet", "db", start.Add(3*time.Hour))
+	s.RecordDetected("dev", "Deployment", "web", start.Add(4*time.Hour))
+	s.RecordResolved(true, start.Add(time.Hour))
+	s.RecordResolved(true, start.Add(2*time.Hour))
+	s.RecordResolved(false, start.Add(3*time.Hour))
+	s.RecordRemediation(true, start.Add(time.Hour))
+	s.RecordRemediation(false, start.Add(time.Hour))
+	// Outside the period
+	s.RecordDetected("prod", "Deployment", "api", start.Add(-time.Minute))
+	s.RecordDetected("prod", "Deployment", "api", start.Add(24*time.Hour))
+
+	summary := s.Summarize(start.Add(30*time.Minute), start.Add(24*time.Hour+30*time.Minute), 2, 15*time.Minute)
+	if summary.Detected != 4 || summary.AutoResolved != 2 || summary.Escalated != 1 {
+		t.Errorf("expected 4 detected, 2 auto-resolved and 1 escalated, got %+v", summary)
+	}
+	if summary.Remediations != 2 || summary.FailedRemediations != 1 {
+		t.Errorf("expected 2 remediations with 1 failure, got %+v", summary)
+	}
+	if summary.ToilSaved != 30*time.Minute {
+		t.Errorf("expected 30m toil saved, got %s", summary.ToilSaved)
+	}
+	if len(summary.TopWorkloads) != 2 || summary.TopWorkloads[0].Name != "api" || summary.TopWorkloads[0].Issues != 2 || summary.TopWorkloads[1].Name != "web" {
+		t.Errorf("expected api then web as top workloads, got %+v", summary.TopWorkloads)
+	}
+}
+
+func TestStatsPersistAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	backend := detection.NewFileValueBackend[State](filepath.Join(t.TempDir(), "digest.json"))
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	s := NewStats(backend)
+	if err := s.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	s.RecordDetected("prod", "Deployment", "api", now)
+	s.MarkSent("ops", now.Add(-time.Hour))
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	restarted := NewStats(backend)
+	// Activity recorded before the load is added to the persisted activity
+	restarted.RecordDetected("prod", "Deployment", "api", now)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if summary := restarted.Summarize(now, now.Add(time.Hour), 5, 0); summary.Detected != 2 {
+		t.Errorf("expected 2 issues detected after the restart, got %d", summary.Detected)
+	}
+	if sent, found := restarted.LastSent("ops"); !found || !sent.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected last digest time to be restored, got %v (%v)", sent, found)
+	}
+
+	restarted.Prune(now.Add(Retention + time.Hour))
+	if summary := restarted.Summarize(now, now.Add(time.Hour), 5, 0); summary.Detected != 0 {
+		t.Errorf("expected activity older than the retention to be pruned, got %d", summary.Detected)
+	}
+}
+
+func TestReporterSendDue(t *testing.T) {
+	ctx := context.Background()
+	sched, err := schedule.Parse("0 9 * * *", "Europe/Berlin")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	// 07:30 UTC is 08:30 in Berlin
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 1, 7, 30, 0, 0, time.UTC))
+	sender := &fakeSender{}
+	var attempts []error
+	stats := NewStats(nil)
+	r := NewReporter(stats, Config{
+		Channels:     []Channel{{Name: "ops", Schedule: sched, Sender: sender}},
+		ToilPerIssue: 10 * time.Minute,
+		Clock:        clock,
+		Sent:         func(_ string, err error) { attempts = append(attempts, err) },
+	})
+
+	// The first run starts the period without sending
+	r.SendDue(ctx)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no digest on the first run, got %d", len(sender.sent))
+	}
+
+	stats.RecordDetected("prod", "Deployment", "api", clock.Now().Add(5*time.Minute))
+	stats.RecordResolved(true, clock.Now().Add(10*time.Minute))
+
+	clock.SetTime(time.Date(2026, 3, 1, 7, 59, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no digest before 9:00 Berlin time, got %d", len(sender.sent))
+	}
+
+	sender.err = errors.New("unavailable")
+	clock.SetTime(time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	sender.err = nil
+	clock.SetTime(time.Date(2026, 3, 1, 8, 1, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected the failed digest to be retried, got %d digests", len(sender.sent))
+	}
+	if len(attempts) != 2 || attempts[0] == nil || attempts[1] != nil {
+		t.Errorf("expected a failed then a successful attempt, got %v", attempts)
+	}
+
+	summary := sender.sent[0]
+	if summary.Channel != "ops" || summary.Detected != 1 || summary.AutoResolved != 1 || summary.ToilSaved != 10*time.Minute {
+		t.Errorf("unexpected digest: %+v", summary)
+	}
+	if summary.To.Location().String() != "Europe/Berlin" || summary.To.Hour() != 9 {
+		t.Errorf("expected the period to end at 9:00 Berlin time, got %v", summary.To)
+	}
+
+	clock.SetTime(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	if len(sender.sent) != 1 {
+		t.Errorf("expected one digest per day, got %d", len(sender.sent))
+	}
+}
+
+func TestEmailSender(t *testing.T) {
+	var addr, from string
+	var to []string
+	var msg []byte
+	e := NewEmailSender(SMTPConfig{Host: "smtp.example.com", Port: 587, From: "kubeguardian@example.com"}, []string{"ops@example.com", "sre@example.com"})
+	e.sendMail = func(a string, _ smtp.Auth, f string, t []string, m []byte) error {
+		addr, from, to, msg = a, f, t, m
+		return nil
+	}
+
+	summary := Summary{
+		From:         time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		To:           time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		Detected:     3,
+		TopWorkloads: []WorkloadCount{{Namespace: "prod", Kind: "Deployment", Name: "api", Issues: 3}},
+	}
+	if err := e.Send(context.Background(), summary); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if addr != "smtp.example.com:587" || from != "kubeguardian@example.com" || len(to) != 2 {
+		t.Errorf("unexpected envelope: %s %s %v", addr, from, to)
+	}
+	body := string(msg)
+	for _, want := range []string{"To: ops@example.com, sre@example.com\r\n", "Subject: KubeGuardian digest 2026-03-01 09:00", "Issues detected: 3\r\n", "- prod/api Deployment: 3 issues"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestStatsUsageReport(t *testing.T) {
+	ctx := context.Background()
+	backend := detection.NewFileValueBackend[State](filepath.Join(t.TempDir(), "digest.json"))
+	march := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	s := NewStats(backend)
+	if err := s.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	s.RecordUsage("payments", "prod", Usage{Issues: 1, Notifications: 2}, march)
+	s.RecordUsage("payments", "prod", Usage{Remediations: 3, FailedRemediations: 1}, march.Add(time.Hour))
+	s.RecordUsage("payments", "staging", Usage{Remediations: 1}, march)
+	s.RecordUsage("", "dev", Usage{Issues: 2, Remediations: 1, Notifications: 1}, march)
+	s.RecordUsage("", "sandbox", Usage{Notifications: 1}, march)
+	// Another month
+	s.RecordUsage("search", "prod", Usage{Remediations: 10}, march.AddDate(0, 1, 0))
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	restarted := NewStats(backend)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	report := restarted.UsageReport(march)
+	if report.Month != "2026-03" || report.Total != (Usage{Issues: 3, Remediations: 5, FailedRemediations: 1, Notifications: 4}) {
+		t.Errorf("unexpected total: %+v", report)
+	}
+	if len(report.Teams) != 3 {
+		t.Fatalf("expected payments and the namespaces without a team, got %+v", report.Teams)
+	}
+	payments := report.Teams[0]
+	if payments.Team != "payments" || strings.Join(payments.Namespaces, ",") != "prod,staging" || payments.Remediations != 4 || payments.RemediationShare != 80 {
+		t.Errorf("expected payments first with 80%% of the remediations, got %+v", payments)
+	}
+	if report.Teams[1].Name() != "no team in dev" || report.Teams[2].Name() != "no team in sandbox" {
+		t.Errorf("expected dev then sandbox without a team, got %+v", report.Teams[1:])
+	}
+	if line := payments.Line(); line != "payments: 4 remediations (1 failed, 80% of all), 2 notifications, 1 issues" {
+		t.Errorf("unexpected line: %s", line)
+	}
+
+	restarted.Prune(march.AddDate(0, UsageMonths, 0))
+	if report := restarted.UsageReport(march); len(report.Teams) != 0 {
+		t.Errorf("expected usage older than %d months to be pruned, got %+v", UsageMonths, report.Teams)
+	}
+	if report := restarted.UsageReport(march.AddDate(0, 1, 0)); len(report.Teams) != 1 {
+		t.Errorf("expected the usage of April to be kept, got %+v", report.Teams)
+	}
+}
+
+func TestReporterSendsUsage(t *testing.T) {
+	sched, err := schedule.Parse("0 9 1 * *", "")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	sender := &fakeSender{}
+	stats := NewStats(nil)
+	r := NewReporter(stats, Config{
+		Channels: []Channel{{Name: "platform", Schedule: sched, Sender: sender, Usage: true}},
+		Clock:    clock,
+	})
+
+	r.SendDue(context.Background())
+	stats.RecordUsage("payments", "prod", Usage{Remediations: 2}, time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+	stats.RecordUsage("payments", "prod", Usage{Remediations: 5}, time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC))
+
+	clock.SetTime(time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC))
+	r.SendDue(context.Background())
+	if len(sender.sent) != 1 || sender.sent[0].Usage == nil {
+		t.Fatalf("expected a digest with usage, got %+v", sender.sent)
+	}
+	usage := sender.sent[0].Usage
+	if usage.Month != "2026-03" || len(usage.Teams) != 1 || usage.Teams[0].Remediations != 2 {
+		t.Errorf("expected the usage of March, got %+v", usage)
+	}
+	if text := sender.sent[0].Text(); !strings.Contains(text, "Usage by team in 2026-03:\n- payments: 2 remediations") {
+		t.Errorf("expected the usage in the text, got:\n%s", text)
+	}
+}
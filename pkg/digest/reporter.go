@@ -0,0 +1,132 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+)
+
+// DefaultTopWorkloads is the default number of workloads listed in a digest
+const DefaultTopWorkloads = 5
+
+// topTeams is the number of teams listed in the usage of a digest
+const topTeams = 10
+
+// pollInterval is how often the reporter checks for due digests
+const pollInterval = time.Minute
+
+// Channel receives digests on a schedule, e.g. daily at 9:00 in its time zone
+type Channel struct {
+	Name     string
+	Schedule *schedule.Schedule
+	Sender   Sender
+	// Usage adds the usage of teams in the month the digest period starts in,
+	// e.g. the previous month for a monthly digest
+	Usage bool
+}
+
+// Config configures a reporter
+type Config struct {
+	Channels []Channel
+	// TopWorkloads is the number of workloads with the most issues listed
+	TopWorkloads int
+	// ToilPerIssue is the manual effort an automatically resolved issue saves
+	ToilPerIssue time.Duration
+	// Clock is the time source; nil uses the real clock
+	Clock clock.PassiveClock
+	// Sent is called after each attempt to send a digest, e.g. to record metrics
+	Sent func(channel string, err error)
+}
+
+// Reporter sends digests of the recorded activity to its channels when due. A
+// digest covers the activity since the previous digest of the channel; digests
+// missed while KubeGuardian was down are sent once it runs again.
+type Reporter struct {
+	stats  *Stats
+	config Config
+}
+
+// NewReporter creates a reporter of the activity recorded in stats
+func NewReporter(stats *Stats, cfg Config) *Reporter {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.RealClock{}
+	}
+	if cfg.TopWorkloads <= 0 {
+		cfg.TopWorkloads = DefaultTopWorkloads
+	}
+	return &Reporter{stats: stats, config: cfg}
+}
+
+// Run sends due digests until the context is cancelled
+func (r *Reporter) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	r.SendDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.SendDue(ctx)
+		}
+	}
+}
+
+// SendDue sends the digests whose schedule activated since their previous digest.
+// A channel without a previous digest starts its first period now.
+func (r *Reporter) SendDue(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	if err := r.stats.Load(ctx); err != nil {
+		logger.Error(err, "Failed to load digest activity")
+		return
+	}
+
+	now := r.config.Clock.Now()
+	r.stats.Prune(now)
+	for _, channel := range r.config.Channels {
+		last, found := r.stats.LastSent(channel.Name)
+		if !found {
+			r.stats.MarkSent(channel.Name, now)
+			continue
+		}
+		if now.Before(channel.Schedule.Next(last)) {
+			continue
+		}
+
+		summary := r.stats.Summarize(last, now, r.config.TopWorkloads, r.config.ToilPerIssue)
+		summary.Channel = channel.Name
+		if channel.Usage {
+			usage := r.stats.UsageReport(last)
+			if len(usage.Teams) > topTeams {
+				usage.Teams = usage.Teams[:topTeams]
+			}
+			summary.Usage = &usage
+		}
+		summary.From = summary.From.In(channel.Schedule.Location())
+		summary.To = summary.To.In(channel.Schedule.Location())
+		err := channel.Sender.Send(ctx, summary)
+		if r.config.Sent != nil {
+			r.config.Sent(channel.Name, err)
+		}
+		if err != nil {
+			// Retried on the next poll
+			logger.Error(err, "Failed to send digest", "channel", channel.Name)
+			continue
+		}
+		r.stats.MarkSent(channel.Name, now)
+		logger.Info("Sent digest", "channel", channel.Name, "from", summary.From, "to", summary.To, "issues", summary.Detected)
+	}
+
+	if err := r.stats.Flush(ctx); err != nil {
+		logger.Error(err, "Failed to save digest activity")
+	}
+}
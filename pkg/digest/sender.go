@@ -0,0 +1,177 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Channel types
+const (
+	ChannelSlack   = "slack"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Summary is the activity of KubeGuardian in a period, sent as a digest
+type Summary struct {
+	Channel            string          `json:"channel"`
+	From               time.Time       `json:"from"`
+	To                 time.Time       `json:"to"`
+	Detected           int             `json:"detected"`
+	AutoResolved       int             `json:"autoResolved"`
+	Escalated          int             `json:"escalated"`
+	Remediations       int             `json:"remediations"`
+	FailedRemediations int             `json:"failedRemediations"`
+	TopWorkloads       []WorkloadCount `json:"topWorkloads"`
+	// Usage is the usage of the teams with the most remediations in the month
+	// the digest period starts in, if the channel receives usage reports
+	Usage *UsageReport `json:"usage,omitempty"`
+	// ToilSaved estimates the manual effort saved by automatically resolved issues
+	ToilSaved time.Duration `json:"-"`
+}
+
+// MarshalJSON encodes the summary with the toil saved in minutes
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type summary Summary
+	return json.Marshal(struct {
+		summary
+		ToilSavedMinutes int `json:"toilSavedMinutes"`
+	}{summary(s), int(s.ToilSaved.Minutes())})
+}
+
+// Title returns the headline of the digest
+func (s Summary) Title() string {
+	return fmt.Sprintf("KubeGuardian digest %s – %s", s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04 MST"))
+}
+
+// Text renders the digest as plain text
+func (s Summary) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", s.Title())
+	fmt.Fprintf(&b, "Issues detected: %d\n", s.Detected)
+	fmt.Fprintf(&b, "Auto-resolved: %d\n", s.AutoResolved)
+	fmt.Fprintf(&b, "Escalated to humans: %d\n", s.Escalated)
+	fmt.Fprintf(&b, "Remediation actions: %d (%d failed)\n", s.Remediations, s.FailedRemediations)
+	fmt.Fprintf(&b, "Estimated toil saved: %s\n", s.ToilSaved.Round(time.Minute))
+	if len(s.TopWorkloads) > 0 {
+		b.WriteString("\nNoisiest workloads:\n")
+		for _, w := range s.TopWorkloads {
+			fmt.Fprintf(&b, "- %s/%s %s: %d issues\n", w.Namespace, w.Name, w.Kind, w.Issues)
+		}
+	}
+	if s.Usage != nil && len(s.Usage.Teams) > 0 {
+		fmt.Fprintf(&b, "\nUsage by team in %s:\n", s.Usage.Month)
+		for _, team := range s.Usage.Teams {
+			fmt.Fprintf(&b, "- %s\n", team.Line())
+		}
+	}
+	return b.String()
+}
+
+// Name returns the team, or the namespaces of workloads without an owning team
+func (t TeamUsage) Name() string {
+	if t.Team != "" {
+		return t.Team
+	}
+	return "no team in " + strings.Join(t.Namespaces, ", ")
+}
+
+// Line renders the usage of a team as a single line
+func (t TeamUsage) Line() string {
+	return fmt.Sprintf("%s: %d remediations (%d failed, %.0f%% of all), %d notifications, %d issues",
+		t.Name(), t.Remediations, t.FailedRemediations, t.RemediationShare, t.Notifications, t.Issues)
+}
+
+// Sender delivers digests to a channel
+type Sender interface {
+	Send(ctx context.Context, summary Summary) error
+}
+
+// WebhookSender posts digests as JSON
+type WebhookSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSender creates a sender posting digests to url
+func NewWebhookSender(url string, timeout time.Duration) *WebhookSender {
+	return &WebhookSender{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Send posts the digest; any status other than 2xx is an error
+func (w *WebhookSender) Send(ctx context.Context, summary Summary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode digest: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post digest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("digest webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPConfig configures the mail server digests are sent through
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password authenticate with PLAIN auth if set
+	Username string
+	Password string
+	From     string
+}
+
+// EmailSender mails digests as plain text
+type EmailSender struct {
+	config SMTPConfig
+	to     []string
+	// sendMail is smtp.SendMail, replaced in tests
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailSender creates a sender mailing digests to the recipients
+func NewEmailSender(config SMTPConfig, to []string) *EmailSender {
+	return &EmailSender{config: config, to: to, sendMail: smtp.SendMail}
+}
+
+// Send mails the digest. The SMTP client does not support contexts; the send is
+// bounded by the server's timeouts.
+func (e *EmailSender) Send(ctx context.Context, summary Summary) error {
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", summary.Title())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(summary.Text(), "\n", "\r\n"))
+
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	if err := e.sendMail(addr, auth, e.config.From, e.to, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to mail digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package digest
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// ConfigMapKey is the data key of the state ConfigMap holding the digest
+// activity, next to the condition state
+const ConfigMapKey = "digest.json"
+
+// Retention is how long activity is kept for digests; longer digest periods only
+// cover the last Retention
+const Retention = 8 * 24 * time.Hour
+
+// Bucket counts the activity of one hour
+type Bucket struct {
+	Start              time.Time `json:"start"`
+	Detected           int       `json:"detected"`
+	AutoResolved       int       `json:"autoResolved"`
+	Escalated          int       `json:"escalated"`
+	Remediations       int       `json:"remediations"`
+	FailedRemediations int       `json:"failedRemediations"`
+	// Workloads counts the issues detected by workload, keyed by namespace/kind/name
+	Workloads map[string]int `json:"workloads,omitempty"`
+}
+
+// State is the persisted activity, the monthly usage of teams and the time each
+// channel was last sent a digest
+type State struct {
+	Buckets  []Bucket             `json:"buckets"`
+	Usage    []UsageRecord        `json:"usage,omitempty"`
+	LastSent map[string]time.Time `json:"lastSent"`
+}
+
+// Backend persists the activity with the state backend so it survives restarts
+type Backend = detection.Backend[State]
+
+// WorkloadCount is the number of issues detected for a workload
+type WorkloadCount struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Issues    int    `json:"issues"`
+}
+
+// Stats records the activity of KubeGuardian in hourly buckets for digests. It is
+// persisted, so a restart does not lose the activity of the current period.
+type Stats struct {
+	mu       sync.Mutex
+	backend  Backend
+	buckets  map[int64]*Bucket // Key: Unix time of the start of the hour
+	usage    map[usageKey]*Usage
+	lastSent map[string]time.Time
+	loaded   bool
+	dirty    bool
+}
+
+// NewStats creates activity stats; a nil backend keeps them in memory only
+func NewStats(backend Backend) *Stats {
+	return &Stats{
+		backend:  backend,
+		buckets:  make(map[int64]*Bucket),
+		usage:    make(map[usageKey]*Usage),
+		lastSent: make(map[string]time.Time),
+		loaded:   backend == nil,
+	}
+}
+
+// Load restores the activity from the backend. It is a no-op once loaded; activity
+// recorded before the load is added to the persisted activity.
+func (s *Stats) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded {
+		return nil
+	}
+
+	state, err := s.backend.Load(ctx)
+	if err != nil {
+		return err
+	}
+	for _, persisted := range state.Buckets {
+		b := s.bucket(persisted.Start)
+		b.Detected += persisted.Detected
+		b.AutoResolved += persisted.AutoResolved
+		b.Escalated += persisted.Escalated
+		b.Remediations += persisted.Remediations
+		b.FailedRemediations += persisted.FailedRemediations
+		for workload, issues := range persisted.Workloads {
+			if b.Workloads == nil {
+				b.Workloads = make(map[string]int)
+			}
+			b.Workloads[workload] += issues
+		}
+	}
+	for _, persisted := range state.Usage {
+		s.usageOf(usageKey{month: persisted.Month, team: persisted.Team, namespace: persisted.Namespace}).add(persisted.Usage)
+	}
+	for channel, sent := range state.LastSent {
+		if _, exists := s.lastSent[channel]; !exists {
+			s.lastSent[channel] = sent
+		}
+	}
+	s.loaded = true
+	s.dirty = true
+	return nil
+}
+
+// RecordDetected records a newly detected issue of a workload
+func (s *Stats) RecordDetected(namespace, kind, name string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bucket(now)
+	b.Detected++
+	if b.Workloads == nil {
+		b.Workloads = make(map[string]int)
+	}
+	b.Workloads[namespace+"/"+kind+"/"+name]++
+	s.dirty = true
+}
+
+// RecordResolved records a resolved issue, resolved automatically or escalated to humans
+func (s *Stats) RecordResolved(autoResolved bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if autoResolved {
+		s.bucket(now).AutoResolved++
+	} else {
+		s.bucket(now).Escalated++
+	}
+	s.dirty = true
+}
+
+// RecordRemediation records an executed remediation action
+func (s *Stats) RecordRemediation(success bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.bucket(now)
+	b.Remediations++
+	if !success {
+		b.FailedRemediations++
+	}
+	s.dirty = true
+}
+
+// Summarize adds up the activity of the hours from the hour of from up to the hour
+// of to, so consecutive digests never count an hour twice. It lists the topWorkloads
+// workloads with the most issues and estimates the toil saved as toilPerIssue for
+// each automatically resolved issue.
+func (s *Stats) Summarize(from, to time.Time, topWorkloads int, toilPerIssue time.Duration) Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := Summary{From: from.Truncate(time.Hour), To: to.Truncate(time.Hour), TopWorkloads: []WorkloadCount{}}
+	workloads := make(map[string]int)
+	for _, b := range s.buckets {
+		if b.Start.Before(summary.From) || !b.Start.Before(summary.To) {
+			continue
+		}
+		summary.Detected += b.Detected
+		summary.AutoResolved += b.AutoResolved
+		summary.Escalated += b.Escalated
+		summary.Remediations += b.Remediations
+		summary.FailedRemediations += b.FailedRemediations
+		for workload, issues := range b.Workloads {
+			workloads[workload] += issues
+		}
+	}
+	summary.ToilSaved = time.Duration(summary.AutoResolved) * toilPerIssue
+
+	for workload, issues := range workloads {
+		parts := strings.SplitN(workload, "/", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		summary.TopWorkloads = append(summary.TopWorkloads, WorkloadCount{Namespace: parts[0], Kind: parts[1], Name: parts[2], Issues: issues})
+	}
+	sort.Slice(summary.TopWorkloads, func(i, j int) bool {
+		a, b := summary.TopWorkloads[i], summary.TopWorkloads[j]
+		if a.Issues != b.Issues {
+			return a.Issues > b.Issues
+		}
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+	if len(summary.TopWorkloads) > topWorkloads {
+		summary.TopWorkloads = summary.TopWorkloads[:topWorkloads]
+	}
+	return summary
+}
+
+// LastSent returns when a channel was last sent a digest
+func (s *Stats) LastSent(channel string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent, found := s.lastSent[channel]
+	return sent, found
+}
+
+// MarkSent records that a channel was sent a digest
+func (s *Stats) MarkSent(channel string, sent time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastSent[channel] = sent
+	s.dirty = true
+}
+
+// Prune drops the activity older than Retention and the usage of months older
+// than UsageMonths
+func (s *Stats) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		if now.Sub(b.Start) > Retention {
+			delete(s.buckets, key)
+			s.dirty = true
+		}
+	}
+	oldest := oldestUsageMonth(now)
+	for key := range s.usage {
+		if key.month < oldest {
+			delete(s.usage, key)
+			s.dirty = true
+		}
+	}
+}
+
+// Flush persists the activity if it changed. It is never written before it has
+// been loaded so a failed load cannot overwrite it.
+func (s *Stats) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	if s.backend == nil || !s.loaded || !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	state := State{Buckets: make([]Bucket, 0, len(s.buckets)), LastSent: make(map[string]time.Time, len(s.lastSent))}
+	for _, b := range s.buckets {
+		copied := *b
+		copied.Workloads = make(map[string]int, len(b.Workloads))
+		for workload, issues := range b.Workloads {
+			copied.Workloads[workload] = issues
+		}
+		state.Buckets = append(state.Buckets, copied)
+	}
+	sort.Slice(state.Buckets, func(i, j int) bool { return state.Buckets[i].Start.Before(state.Buckets[j].Start) })
+	for key, usage := range s.usage {
+		state.Usage = append(state.Usage, UsageRecord{Month: key.month, Team: key.team, Namespace: key.namespace, Usage: *usage})
+	}
+	sort.Slice(state.Usage, func(i, j int) bool {
+		a, b := state.Usage[i], state.Usage[j]
+		if a.Month != b.Month {
+			return a.Month < b.Month
+		}
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		return a.Namespace < b.Namespace
+	})
+	for channel, sent := range s.lastSent {
+		state.LastSent[channel] = sent
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := s.backend.Save(ctx, state); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// bucket returns the bucket of the hour of t, creating it if needed; the caller holds the lock
+func (s *Stats) bucket(t time.Time) *Bucket {
+	start := t.Truncate(time.Hour).UTC()
+	b, exists := s.buckets[start.Unix()]
+	if !exists {
+		b = &Bucket{Start: start}
+		s.buckets[start.Unix()] = b
+	}
+	return b
+}
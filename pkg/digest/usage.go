@@ -0,0 +1,150 @@
+package digest
+
+import (
+	"sort"
+	"time"
+)
+
+// UsageMonths is how many months of usage are kept for usage reports, including
+// the current month
+const UsageMonths = 13
+
+// monthLayout formats the months of usage, e.g. 2026-03
+const monthLayout = "2006-01"
+
+// Usage counts the effort KubeGuardian spent on workloads
+type Usage struct {
+	Issues             int `json:"issues"`
+	Remediations       int `json:"remediations"`
+	FailedRemediations int `json:"failedRemediations"`
+	Notifications      int `json:"notifications"`
+}
+
+// add adds the counts of other
+func (u *Usage) add(other Usage) {
+	u.Issues += other.Issues
+	u.Remediations += other.Remediations
+	u.FailedRemediations += other.FailedRemediations
+	u.Notifications += other.Notifications
+}
+
+// UsageRecord is the usage of the workloads of a team in a namespace in a month
+type UsageRecord struct {
+	Month     string `json:"month"`
+	Team      string `json:"team,omitempty"`
+	Namespace string `json:"namespace"`
+	Usage
+}
+
+// usageKey identifies the usage of a team in a namespace in a month
+type usageKey struct {
+	month     string
+	team      string
+	namespace string
+}
+
+// TeamUsage is the usage of the workloads of a team in a month. Workloads
+// without an owning team are reported by namespace, with an empty team.
+type TeamUsage struct {
+	Team       string   `json:"team,omitempty"`
+	Namespaces []string `json:"namespaces"`
+	Usage
+	// RemediationShare is the percentage of all remediations of the month spent on the team
+	RemediationShare float64 `json:"remediationShare"`
+}
+
+// UsageReport is the usage of all teams in a month, the teams with the most
+// remediations first
+type UsageReport struct {
+	Month string      `json:"month"`
+	Total Usage       `json:"total"`
+	Teams []TeamUsage `json:"teams"`
+}
+
+// MonthOf returns the month of t in usage reports, e.g. 2026-03
+func MonthOf(t time.Time) string {
+	return t.UTC().Format(monthLayout)
+}
+
+// ParseMonth parses a month of usage reports, e.g. 2026-03
+func ParseMonth(month string) (time.Time, error) {
+	return time.Parse(monthLayout, month)
+}
+
+// RecordUsage adds usage of the workloads of a team in a namespace; an empty team
+// is the usage of workloads without an owning team
+func (s *Stats) RecordUsage(team, namespace string, usage Usage, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usageOf(usageKey{month: MonthOf(now), team: team, namespace: namespace}).add(usage)
+	s.dirty = true
+}
+
+// UsageReport adds up the usage of the month of t by team
+func (s *Stats) UsageReport(t time.Time) UsageReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := UsageReport{Month: MonthOf(t), Teams: []TeamUsage{}}
+	teams := make(map[string]*TeamUsage)
+	for key, usage := range s.usage {
+		if key.month != report.Month {
+			continue
+		}
+		report.Total.add(*usage)
+
+		group := "team/" + key.team
+		if key.team == "" {
+			group = "namespace/" + key.namespace
+		}
+		team, exists := teams[group]
+		if !exists {
+			team = &TeamUsage{Team: key.team}
+			teams[group] = team
+		}
+		team.add(*usage)
+		team.Namespaces = append(team.Namespaces, key.namespace)
+	}
+
+	for _, team := range teams {
+		sort.Strings(team.Namespaces)
+		if report.Total.Remediations > 0 {
+			team.RemediationShare = float64(team.Remediations) * 100 / float64(report.Total.Remediations)
+		}
+		report.Teams = append(report.Teams, *team)
+	}
+	sort.Slice(report.Teams, func(i, j int) bool {
+		a, b := report.Teams[i], report.Teams[j]
+		if a.Remediations != b.Remediations {
+			return a.Remediations > b.Remediations
+		}
+		if a.Notifications != b.Notifications {
+			return a.Notifications > b.Notifications
+		}
+		if a.Issues != b.Issues {
+			return a.Issues > b.Issues
+		}
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		return a.Namespaces[0] < b.Namespaces[0]
+	})
+	return report
+}
+
+// usageOf returns the usage of a key, creating it if needed; the caller holds the lock
+func (s *Stats) usageOf(key usageKey) *Usage {
+	usage, exists := s.usage[key]
+	if !exists {
+		usage = &Usage{}
+		s.usage[key] = usage
+	}
+	return usage
+}
+
+// oldestUsageMonth returns the oldest month of usage kept at now
+func oldestUsageMonth(now time.Time) string {
+	now = now.UTC()
+	return MonthOf(time.Date(now.Year(), now.Month()-(UsageMonths-1), 1, 0, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,310 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+// MappingVersion is the version of the document mappings. It is part of the index
+// names, so a new version is written to new indices next to the old ones.
+const MappingVersion = 1
+
+// Kinds of exported documents, each written to its own indices
+const (
+	KindIssues       = "issues"
+	KindRemediations = "remediations"
+)
+
+// Issue events
+const (
+	EventDetected = "detected"
+	EventResolved = "resolved"
+)
+
+// Results of exported documents
+const (
+	ResultIndexed = "indexed"
+	ResultFailed  = "failed"
+	ResultDropped = "dropped"
+)
+
+// IssueDocument is an issue detection or resolution
+type IssueDocument struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Timestamp     time.Time         `json:"@timestamp"`
+	Event         string            `json:"event"`
+	Fingerprint   string            `json:"fingerprint"`
+	Rule          string            `json:"rule"`
+	Severity      string            `json:"severity,omitempty"`
+	Namespace     string            `json:"namespace"`
+	Kind          string            `json:"kind"`
+	Name          string            `json:"name"`
+	Reason        string            `json:"reason,omitempty"`
+	Team          string            `json:"team,omitempty"`
+	Contact       string            `json:"contact,omitempty"`
+	Runbook       string            `json:"runbook,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Change        string            `json:"change,omitempty"`
+	Diagnoses     []string          `json:"diagnoses,omitempty"`
+	Hypothesis    string            `json:"hypothesis,omitempty"`
+	Outcome       string            `json:"outcome,omitempty"`
+	Attempts      int               `json:"attempts,omitempty"`
+	// DurationSeconds is the time from first detection to resolution
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+}
+
+// RemediationDocument is an executed remediation action
+type RemediationDocument struct {
+	SchemaVersion   int       `json:"schemaVersion"`
+	Timestamp       time.Time `json:"@timestamp"`
+	Fingerprint     string    `json:"fingerprint"`
+	Rule            string    `json:"rule"`
+	Namespace       string    `json:"namespace"`
+	Kind            string    `json:"kind"`
+	Name            string    `json:"name"`
+	Team            string    `json:"team,omitempty"`
+	Action          string    `json:"action"`
+	Success         bool      `json:"success"`
+	Message         string    `json:"message,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	RequestedBy     string    `json:"requestedBy,omitempty"`
+	UndoID          string    `json:"undoID,omitempty"`
+	// Method is how restart-pod removed the pod: evict or delete
+	Method string `json:"method,omitempty"`
+}
+
+// document is a document waiting to be indexed
+type document struct {
+	kind      string
+	timestamp time.Time
+	body      interface{}
+}
+
+// Config configures the document exporter
+type Config struct {
+	// URL is the Elasticsearch or OpenSearch endpoint
+	URL string
+	// Username and Password authenticate with basic auth
+	Username string
+	Password string
+	// APIKey authenticates with an Elasticsearch API key instead
+	APIKey string
+	// IndexPrefix prefixes the daily indices and index templates
+	IndexPrefix string
+	// BatchSize is the number of documents sent in one bulk request
+	BatchSize int
+	// FlushInterval is the longest time a document is buffered
+	FlushInterval time.Duration
+	// BufferSize is the number of buffered documents; further documents are dropped
+	BufferSize int
+	// Timeout bounds each request
+	Timeout time.Duration
+}
+
+// Exporter indexes issue and remediation documents in the background. A nil
+// exporter exports nothing.
+type Exporter struct {
+	config    Config
+	client    *client
+	documents chan document
+	metrics   *metrics.Metrics
+}
+
+// NewExporter creates a new document exporter
+func NewExporter(config Config, metricsCollector *metrics.Metrics) *Exporter {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	return &Exporter{
+		config:    config,
+		client:    newClient(config),
+		documents: make(chan document, config.BufferSize),
+		metrics:   metricsCollector,
+	}
+}
+
+// SetCredentials replaces the credentials documents are indexed with, e.g.
+// after they were rotated in the secret store they are read from
+func (e *Exporter) SetCredentials(username, password, apiKey string) {
+	if e == nil {
+		return
+	}
+	e.client.setCredentials(username, password, apiKey)
+}
+
+// ExportIssue exports the detection of a new issue
+func (e *Exporter) ExportIssue(ctx context.Context, issue detection.Issue) {
+	if e == nil {
+		return
+	}
+
+	doc := IssueDocument{
+		SchemaVersion: MappingVersion,
+		Timestamp:     issue.DetectedAt,
+		Event:         EventDetected,
+		Fingerprint:   issue.Fingerprint(),
+		Rule:          issue.RuleName,
+		Severity:      issue.Severity,
+		Namespace:     issue.Namespace,
+		Kind:          issue.Kind,
+		Name:          issue.Name,
+		Reason:        issue.Reason,
+		Team:          issue.Owner.Team,
+		Contact:       issue.Owner.Contact,
+		Runbook:       issue.Runbook,
+		Labels:        issue.Labels,
+	}
+	if doc.Timestamp.IsZero() {
+		doc.Timestamp = time.Now()
+	}
+	if issue.Change != nil {
+		doc.Change = issue.Change.Summary(doc.Timestamp)
+	}
+	for _, diagnosis := range issue.Diagnoses {
+		doc.Diagnoses = append(doc.Diagnoses, diagnosis.Signature)
+	}
+	if issue.Analysis != nil {
+		doc.Hypothesis = issue.Analysis.Hypothesis
+	}
+	e.enqueue(ctx, document{kind: KindIssues, timestamp: doc.Timestamp, body: doc})
+}
+
+// ExportResolved exports the resolution of an issue
+func (e *Exporter) ExportResolved(ctx context.Context, record tracker.Record) {
+	if e == nil {
+		return
+	}
+
+	doc := IssueDocument{
+		SchemaVersion:   MappingVersion,
+		Timestamp:       record.ResolvedAt,
+		Event:           EventResolved,
+		Fingerprint:     record.Fingerprint,
+		Rule:            record.RuleName,
+		Namespace:       record.Namespace,
+		Kind:            record.Kind,
+		Name:            record.Name,
+		Team:            record.Team,
+		Runbook:         record.Runbook,
+		Outcome:         record.Outcome(),
+		Attempts:        record.Attempts,
+		DurationSeconds: record.TimeToResolution().Seconds(),
+	}
+	e.enqueue(ctx, document{kind: KindIssues, timestamp: doc.Timestamp, body: doc})
+}
+
+// ExportRemediation exports a remediation action executed for an issue
+func (e *Exporter) ExportRemediation(ctx context.Context, issue detection.Issue, result remediation.Result) {
+	if e == nil {
+		return
+	}
+
+	doc := RemediationDocument{
+		SchemaVersion:   MappingVersion,
+		Timestamp:       result.ExecutedAt,
+		Fingerprint:     issue.Fingerprint(),
+		Rule:            issue.RuleName,
+		Namespace:       issue.Namespace,
+		Kind:            issue.Kind,
+		Name:            issue.Name,
+		Team:            issue.Owner.Team,
+		Action:          result.Action,
+		Success:         result.Success,
+		Message:         result.Message,
+		DurationSeconds: result.Duration.Seconds(),
+		RequestedBy:     result.RequestedBy,
+		UndoID:          result.UndoID,
+		Method:          result.Method,
+	}
+	if doc.Timestamp.IsZero() {
+		doc.Timestamp = time.Now()
+	}
+	e.enqueue(ctx, document{kind: KindRemediations, timestamp: doc.Timestamp, body: doc})
+}
+
+// enqueue buffers a document without blocking; the document is dropped if the buffer is full
+func (e *Exporter) enqueue(ctx context.Context, doc document) {
+	select {
+	case e.documents <- doc:
+	default:
+		log.FromContext(ctx).Info("Export buffer full, dropping document", "index", doc.kind)
+		e.record(doc.kind, ResultDropped, 1)
+	}
+}
+
+// Run installs the index templates and indexes buffered documents in batches until
+// the context is cancelled. Documents still buffered on shutdown are flushed.
+func (e *Exporter) Run(ctx context.Context) {
+	if e == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	if err := e.client.putTemplates(ctx, e.config.IndexPrefix); err != nil {
+		logger.Error(err, "Failed to install export index templates, indexing with existing mappings")
+	}
+
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]document, 0, e.config.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			for drained := false; !drained; {
+				select {
+				case doc := <-e.documents:
+					batch = append(batch, doc)
+				default:
+					drained = true
+				}
+			}
+			// The context is done; give the final flush its own deadline
+			flushCtx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
+			e.flush(log.IntoContext(flushCtx, logger), batch)
+			cancel()
+			return
+		case doc := <-e.documents:
+			batch = append(batch, doc)
+			if len(batch) >= e.config.BatchSize {
+				e.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			e.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush indexes a batch of documents
+func (e *Exporter) flush(ctx context.Context, batch []document) {
+	if len(batch) == 0 {
+		return
+	}
+
+	failed, err := e.client.bulk(ctx, e.config.IndexPrefix, batch)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to export documents", "count", len(batch))
+	}
+	for i, doc := range batch {
+		if err != nil || failed[i] {
+			e.record(doc.kind, ResultFailed, 1)
+		} else {
+			e.record(doc.kind, ResultIndexed, 1)
+		}
+	}
+}
+
+func (e *Exporter) record(kind, result string, count int) {
+	if e.metrics != nil {
+		e.metrics.RecordExportedDocuments(kind, result, count)
+	}
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+func TestExporterIndexesDocuments(t *testing.T) {
+	var mu sync.Mutex
+	templates := map[string]bool{}
+	indices := map[string]int{}
+	bulked := make(chan struct{}, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "elastic" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_index_template/"):
+			templates[strings.TrimPrefix(r.URL.Path, "/_index_template/")] = true
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			items := 0
+			for line := 0; scanner.Scan(); line++ {
+				if line%2 == 1 {
+					continue
+				}
+				var action map[string]map[string]string
+				if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+					t.Errorf("invalid bulk action: %v", err)
+				}
+				indices[action["index"]["_index"]]++
+				items++
+			}
+			w.Write([]byte(`{"errors":false,"items":[` + strings.TrimSuffix(strings.Repeat(`{"index":{"status":201}},`, items), ",") + `]}`))
+			bulked <- struct{}{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exporter := NewExporter(Config{
+		URL:           server.URL,
+		Username:      "elastic",
+		Password:      "secret",
+		IndexPrefix:   "kg",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		BufferSize:    10,
+		Timeout:       time.Second,
+	}, nil)
+
+	detectedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	issue := detection.Issue{RuleName: "crash-loop-backoff", Namespace: "default", Kind: "Pod", Name: "web-1", DetectedAt: detectedAt}
+	exporter.ExportIssue(context.Background(), issue)
+	exporter.ExportRemediation(context.Background(), issue, remediation.Result{Action: "restart-pod", Success: true, ExecutedAt: detectedAt})
+	exporter.ExportResolved(context.Background(), tracker.Record{RuleName: "crash-loop-backoff", FirstDetected: detectedAt, ResolvedAt: detectedAt.Add(time.Minute)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx)
+		close(done)
+	}()
+
+	// The first batch is full, the remaining document is flushed on shutdown
+	select {
+	case <-bulked:
+	case <-time.After(time.Second):
+		t.Fatal("batch was not indexed")
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range []string{"kg-issues-v1", "kg-remediations-v1"} {
+		if !templates[name] {
+			t.Errorf("index template %s was not installed", name)
+		}
+	}
+	if indices["kg-issues-v1-2026.10.16"] != 2 || indices["kg-remediations-v1-2026.10.16"] != 1 {
+		t.Errorf("unexpected documents per index: %v", indices)
+	}
+}
+
+func TestBulkReportsFailedDocuments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400}}]}`))
+	}))
+	defer server.Close()
+
+	c := newClient(Config{URL: server.URL, Timeout: time.Second})
+	documents := []document{
+		{kind: KindIssues, timestamp: time.Now(), body: IssueDocument{}},
+		{kind: KindIssues, timestamp: time.Now(), body: IssueDocument{}},
+	}
+	failed, err := c.bulk(context.Background(), "kg", documents)
+	if err != nil {
+		t.Fatalf("bulk() error = %v", err)
+	}
+	if failed[0] || !failed[1] {
+		t.Errorf("failed = %v, want only the second document", failed)
+	}
+}
+
+func TestClientRotatedCredentials(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := newClient(Config{URL: server.URL, Username: "elastic", Password: "old", Timeout: time.Second})
+	if _, err := c.do(context.Background(), http.MethodGet, "/", "application/json", nil); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+	c.setCredentials("", "", "rotated-key")
+	if _, err := c.do(context.Background(), http.MethodGet, "/", "application/json", nil); err != nil {
+		t.Fatalf("do() error = %v", err)
+	}
+
+	if len(authorization) != 2 || !strings.HasPrefix(authorization[0], "Basic ") || authorization[1] != "ApiKey rotated-key" {
+		t.Errorf("Authorization headers = %v, want basic auth then the rotated API key", authorization)
+	}
+}
+
+func TestHistoryReport(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/kg-issues-v*/_search":
+			w.Write([]byte(`{"_scroll_id":"issues","hits":{"hits":[{"_source":{"@timestamp":"2026-10-16T12:00:00Z","event":"detected","rule":"crash-loop-backoff","namespace":"default","kind":"Pod","name":"web-1","reason":"back-off\nrestarting"}}]}}`))
+		case r.URL.Path == "/kg-remediations-v*/_search":
+			w.Write([]byte(`{"_scroll_id":"remediations","hits":{"hits":[{"_source":{"@timestamp":"2026-10-16T12:01:00Z","rule":"crash-loop-backoff","namespace":"default","kind":"Pod","name":"web-1","action":"restart-pod","success":true}}]}}`))
+		case r.URL.Path == "/_search/scroll":
+			w.Write([]byte(`{"hits":{"hits":[]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	history := NewHistory(Config{URL: server.URL, IndexPrefix: "kg", Timeout: time.Second})
+	issues, err := history.Issues(context.Background(), at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Issues() error = %v", err)
+	}
+	remediations, err := history.Remediations(context.Background(), at.Add(-time.Hour), at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Remediations() error = %v", err)
+	}
+
+	var out strings.Builder
+	if err := NewReport(at.Add(-time.Hour), at.Add(time.Hour), issues, remediations).WriteCSV(&out); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "timestamp,type,fingerprint,rule,severity,namespace,kind,name,team,action,success,requestedBy,outcome,details\n" +
+		"2026-10-16T12:00:00Z,detected,,crash-loop-backoff,,default,Pod,web-1,,,,,,back-off restarting\n" +
+		"2026-10-16T12:01:00Z,remediation,,crash-loop-backoff,,default,Pod,web-1,,restart-pod,true,,,\n"
+	if out.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
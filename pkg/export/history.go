@@ -0,0 +1,122 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// historyPageSize is the number of documents fetched per scroll request
+const historyPageSize = 500
+
+// History reads exported documents back from Elasticsearch or OpenSearch
+type History struct {
+	prefix string
+	client *client
+}
+
+// NewHistory creates a reader for the documents exported with the given configuration
+func NewHistory(config Config) *History {
+	return &History{prefix: config.IndexPrefix, client: newClient(config)}
+}
+
+// Issues returns the issue documents with a timestamp in [from, to), oldest first
+func (h *History) Issues(ctx context.Context, from, to time.Time) ([]IssueDocument, error) {
+	var documents []IssueDocument
+	err := h.scroll(ctx, KindIssues, from, to, func(source json.RawMessage) error {
+		var doc IssueDocument
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return err
+		}
+		documents = append(documents, doc)
+		return nil
+	})
+	return documents, err
+}
+
+// Remediations returns the remediation documents with a timestamp in [from, to), oldest first
+func (h *History) Remediations(ctx context.Context, from, to time.Time) ([]RemediationDocument, error) {
+	var documents []RemediationDocument
+	err := h.scroll(ctx, KindRemediations, from, to, func(source json.RawMessage) error {
+		var doc RemediationDocument
+		if err := json.Unmarshal(source, &doc); err != nil {
+			return err
+		}
+		documents = append(documents, doc)
+		return nil
+	})
+	return documents, err
+}
+
+// searchResponse is the part of a search or scroll response needed to page through hits
+type searchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			Source json.RawMessage `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// scroll pages through all documents of a kind in a time range, across all mapping versions
+func (h *History) scroll(ctx context.Context, kind string, from, to time.Time, visit func(source json.RawMessage) error) error {
+	query := map[string]interface{}{
+		"size": historyPageSize,
+		"sort": []map[string]string{{"@timestamp": "asc"}},
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"@timestamp": map[string]string{
+					"gte": from.UTC().Format(time.RFC3339Nano),
+					"lt":  to.UTC().Format(time.RFC3339Nano),
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+
+	pattern := fmt.Sprintf("%s-%s-v*", h.prefix, kind)
+	data, err := h.client.do(ctx, http.MethodPost, "/"+pattern+"/_search?scroll=1m&ignore_unavailable=true", "application/json", body)
+	if err != nil {
+		return fmt.Errorf("failed to search %s: %w", pattern, err)
+	}
+
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			release, _ := json.Marshal(map[string]string{"scroll_id": scrollID})
+			_, _ = h.client.do(ctx, http.MethodDelete, "/_search/scroll", "application/json", release)
+		}
+	}()
+
+	for {
+		var response searchResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return fmt.Errorf("failed to decode search response: %w", err)
+		}
+		scrollID = response.ScrollID
+		if len(response.Hits.Hits) == 0 {
+			return nil
+		}
+		for _, hit := range response.Hits.Hits {
+			if err := visit(hit.Source); err != nil {
+				return fmt.Errorf("failed to decode document: %w", err)
+			}
+		}
+		if scrollID == "" {
+			return nil
+		}
+
+		next, err := json.Marshal(map[string]string{"scroll": "1m", "scroll_id": scrollID})
+		if err != nil {
+			return err
+		}
+		if data, err = h.client.do(ctx, http.MethodPost, "/_search/scroll", "application/json", next); err != nil {
+			return fmt.Errorf("failed to scroll %s: %w", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// client talks to the Elasticsearch-compatible REST API shared by Elasticsearch
+// 7.8+ and OpenSearch
+type client struct {
+	url        string
+	httpClient *http.Client
+
+	mu       sync.RWMutex
+	username string
+	password string
+	apiKey   string
+}
+
+func newClient(config Config) *client {
+	return &client{
+		url:        strings.TrimSuffix(config.URL, "/"),
+		username:   config.Username,
+		password:   config.Password,
+		apiKey:     config.APIKey,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// setCredentials replaces the credentials requests are authenticated with
+func (c *client) setCredentials(username, password, apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.username, c.password, c.apiKey = username, password, apiKey
+}
+
+// IndexName returns the daily index of a document kind, e.g.
+// kubeguardian-issues-v1-2026.10.16
+func IndexName(prefix, kind string, day time.Time) string {
+	return fmt.Sprintf("%s-%s-v%d-%s", prefix, kind, MappingVersion, day.Format("2006.01.02"))
+}
+
+// templateName returns the index template covering the daily indices of a document kind
+func templateName(prefix, kind string) string {
+	return fmt.Sprintf("%s-%s-v%d", prefix, kind, MappingVersion)
+}
+
+// mappings are the field mappings of each document kind. Unknown fields other than
+// labels are not indexed, so changing a mapping requires a new MappingVersion.
+var mappings = map[string]map[string]interface{}{
+	KindIssues: {
+		"schemaVersion":   map[string]string{"type": "integer"},
+		"@timestamp":      map[string]string{"type": "date"},
+		"event":           map[string]string{"type": "keyword"},
+		"fingerprint":     map[string]string{"type": "keyword"},
+		"rule":            map[string]string{"type": "keyword"},
+		"severity":        map[string]string{"type": "keyword"},
+		"namespace":       map[string]string{"type": "keyword"},
+		"kind":            map[string]string{"type": "keyword"},
+		"name":            map[string]string{"type": "keyword"},
+		"reason":          map[string]string{"type": "text"},
+		"team":            map[string]string{"type": "keyword"},
+		"contact":         map[string]string{"type": "keyword"},
+		"runbook":         map[string]string{"type": "keyword", "index": "false"},
+		"labels":          map[string]interface{}{"type": "object", "dynamic": true},
+		"change":          map[string]string{"type": "text"},
+		"diagnoses":       map[string]string{"type": "keyword"},
+		"hypothesis":      map[string]string{"type": "text"},
+		"outcome":         map[string]string{"type": "keyword"},
+		"attempts":        map[string]string{"type": "integer"},
+		"durationSeconds": map[string]string{"type": "double"},
+	},
+	KindRemediations: {
+		"schemaVersion":   map[string]string{"type": "integer"},
+		"@timestamp":      map[string]string{"type": "date"},
+		"fingerprint":     map[string]string{"type": "keyword"},
+		"rule":            map[string]string{"type": "keyword"},
+		"namespace":       map[string]string{"type": "keyword"},
+		"kind":            map[string]string{"type": "keyword"},
+		"name":            map[string]string{"type": "keyword"},
+		"team":            map[string]string{"type": "keyword"},
+		"action":          map[string]string{"type": "keyword"},
+		"success":         map[string]string{"type": "boolean"},
+		"message":         map[string]string{"type": "text"},
+		"durationSeconds": map[string]string{"type": "double"},
+		"requestedBy":     map[string]string{"type": "keyword"},
+		"undoID":          map[string]string{"type": "keyword"},
+		"method":          map[string]string{"type": "keyword"},
+	},
+}
+
+// putTemplates installs the versioned index templates of all document kinds
+func (c *client) putTemplates(ctx context.Context, prefix string) error {
+	for _, kind := range []string{KindIssues, KindRemediations} {
+		template := map[string]interface{}{
+			"index_patterns": []string{templateName(prefix, kind) + "-*"},
+			"template": map[string]interface{}{
+				"mappings": map[string]interface{}{
+					"_meta":      map[string]int{"version": MappingVersion},
+					"dynamic":    false,
+					"properties": mappings[kind],
+				},
+			},
+			"_meta": map[string]int{"version": MappingVersion},
+		}
+		body, err := json.Marshal(template)
+		if err != nil {
+			return err
+		}
+		if _, err := c.do(ctx, http.MethodPut, "/_index_template/"+templateName(prefix, kind), "application/json", body); err != nil {
+			return fmt.Errorf("failed to install index template %s: %w", templateName(prefix, kind), err)
+		}
+	}
+	return nil
+}
+
+// bulkResponse is the part of a bulk API response needed to find failed documents
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+	} `json:"items"`
+}
+
+// bulk indexes documents into their daily indices and reports which of them failed
+func (c *client) bulk(ctx context.Context, prefix string, documents []document) ([]bool, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range documents {
+		action := map[string]map[string]string{"index": {"_index": IndexName(prefix, doc.kind, doc.timestamp.UTC())}}
+		if err := encoder.Encode(action); err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(doc.body); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := c.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var response bulkResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+
+	failed := make([]bool, len(documents))
+	if !response.Errors {
+		return failed, nil
+	}
+	for i, item := range response.Items {
+		for _, result := range item {
+			if i < len(failed) && result.Status >= 300 {
+				failed[i] = true
+			}
+		}
+	}
+	return failed, nil
+}
+
+// do sends an authenticated request and returns the response body
+func (c *client) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	c.mu.RLock()
+	switch {
+	case c.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	c.mu.RUnlock()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
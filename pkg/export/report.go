@@ -0,0 +1,142 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Report entry types
+const (
+	EntryDetected    = EventDetected
+	EntryResolved    = EventResolved
+	EntryRemediation = "remediation"
+)
+
+// Report lists all detections, resolutions and remediation actions of a period
+// in chronological order, as evidence for change management
+type Report struct {
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Entries     []ReportEntry `json:"entries"`
+}
+
+// ReportEntry is a single detection, resolution or remediation action
+type ReportEntry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Type        string    `json:"type"`
+	Fingerprint string    `json:"fingerprint"`
+	Rule        string    `json:"rule"`
+	Severity    string    `json:"severity,omitempty"`
+	Namespace   string    `json:"namespace"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Team        string    `json:"team,omitempty"`
+	// Action and Success are set for remediation actions
+	Action  string `json:"action,omitempty"`
+	Success *bool  `json:"success,omitempty"`
+	// RequestedBy is set for manually requested actions
+	RequestedBy string `json:"requestedBy,omitempty"`
+	// Outcome is set for resolutions
+	Outcome string `json:"outcome,omitempty"`
+	// Details is the reason of a detection or the message of an action
+	Details string `json:"details,omitempty"`
+}
+
+// reportColumns are the CSV columns in order
+var reportColumns = []string{"timestamp", "type", "fingerprint", "rule", "severity", "namespace", "kind", "name", "team", "action", "success", "requestedBy", "outcome", "details"}
+
+// NewReport builds the report of a period from exported documents
+func NewReport(from, to time.Time, issues []IssueDocument, remediations []RemediationDocument) *Report {
+	report := &Report{From: from, To: to, GeneratedAt: time.Now().UTC()}
+	for _, doc := range issues {
+		report.Entries = append(report.Entries, ReportEntry{
+			Timestamp:   doc.Timestamp,
+			Type:        doc.Event,
+			Fingerprint: doc.Fingerprint,
+			Rule:        doc.Rule,
+			Severity:    doc.Severity,
+			Namespace:   doc.Namespace,
+			Kind:        doc.Kind,
+			Name:        doc.Name,
+			Team:        doc.Team,
+			Outcome:     doc.Outcome,
+			Details:     doc.Reason,
+		})
+	}
+	for _, doc := range remediations {
+		success := doc.Success
+		report.Entries = append(report.Entries, ReportEntry{
+			Timestamp:   doc.Timestamp,
+			Type:        EntryRemediation,
+			Fingerprint: doc.Fingerprint,
+			Rule:        doc.Rule,
+			Namespace:   doc.Namespace,
+			Kind:        doc.Kind,
+			Name:        doc.Name,
+			Team:        doc.Team,
+			Action:      doc.Action,
+			Success:     &success,
+			RequestedBy: doc.RequestedBy,
+			Details:     doc.Message,
+		})
+	}
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].Timestamp.Before(report.Entries[j].Timestamp)
+	})
+	return report
+}
+
+// WriteJSON writes the report as an indented JSON document
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the report entries as CSV with a header row
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(reportColumns); err != nil {
+		return err
+	}
+	for _, entry := range r.Entries {
+		success := ""
+		if entry.Success != nil {
+			success = strconv.FormatBool(*entry.Success)
+		}
+		record := []string{
+			entry.Timestamp.UTC().Format(time.RFC3339),
+			entry.Type,
+			entry.Fingerprint,
+			entry.Rule,
+			entry.Severity,
+			entry.Namespace,
+			entry.Kind,
+			entry.Name,
+			entry.Team,
+			entry.Action,
+			success,
+			entry.RequestedBy,
+			entry.Outcome,
+			// Keep each entry on a single line
+			strings.Join(strings.Fields(entry.Details), " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,161 @@
+package features
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Feature flags gating experimental subsystems
+const (
+	// AIAnalysis enables AI-assisted root cause analysis of detected issues
+	AIAnalysis = "aiAnalysis"
+	// NodeActions enables remediation actions on nodes such as cordon and drain
+	NodeActions = "nodeActions"
+	// MultiCluster enables watching and remediating more than one cluster
+	MultiCluster = "multiCluster"
+)
+
+// Sources of a flag value
+const (
+	SourceDefault = "default"
+	SourceConfig  = "config"
+	SourceAPI     = "api"
+)
+
+// ErrUnknownFeature is returned for flag names that are not defined
+var ErrUnknownFeature = errors.New("unknown feature")
+
+// Definition describes a feature flag
+type Definition struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// definitions lists all feature flags; experimental subsystems ship disabled
+var definitions = []Definition{
+	{Name: AIAnalysis, Description: "AI-assisted root cause analysis of detected issues"},
+	{Name: NodeActions, Description: "Remediation actions on nodes such as cordon and drain"},
+	{Name: MultiCluster, Description: "Watching and remediating more than one cluster"},
+}
+
+// Definitions returns all feature flag definitions
+func Definitions() []Definition {
+	return append([]Definition(nil), definitions...)
+}
+
+// Known returns true if a feature flag with the given name is defined
+func Known(name string) bool {
+	_, ok := lookup(name)
+	return ok
+}
+
+func lookup(name string) (Definition, bool) {
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Status is the current value of a feature flag
+type Status struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	// Source is default, config or api
+	Source string `json:"source"`
+}
+
+// Flags holds the feature flag values of a running instance. Values set at
+// runtime are kept in memory and reset to the configuration on restart.
+type Flags struct {
+	mu     sync.RWMutex
+	values map[string]Status
+}
+
+// New creates feature flags with the given configured values applied on top of
+// the defaults
+func New(configured map[string]bool) (*Flags, error) {
+	f := &Flags{values: make(map[string]Status, len(definitions))}
+	for _, definition := range definitions {
+		f.values[definition.Name] = Status{
+			Name:        definition.Name,
+			Description: definition.Description,
+			Enabled:     definition.Default,
+			Source:      SourceDefault,
+		}
+	}
+
+	for name, enabled := range configured {
+		if err := f.set(name, enabled, SourceConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// Enabled returns true if the feature is enabled. A nil Flags reports the defaults.
+func (f *Flags) Enabled(name string) bool {
+	if f == nil {
+		definition, _ := lookup(name)
+		return definition.Default
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.values[name].Enabled
+}
+
+// Set enables or disables a feature at runtime
+func (f *Flags) Set(name string, enabled bool) (Status, error) {
+	if err := f.set(name, enabled, SourceAPI); err != nil {
+		return Status{}, err
+	}
+	return f.Get(name)
+}
+
+// Get returns the status of a feature flag
+func (f *Flags) Get(name string) (Status, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	status, exists := f.values[name]
+	if !exists {
+		return Status{}, fmt.Errorf("%w: %s", ErrUnknownFeature, name)
+	}
+	return status, nil
+}
+
+// List returns the status of all feature flags sorted by name
+func (f *Flags) List() []Status {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(f.values))
+	for _, status := range f.values {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+func (f *Flags) set(name string, enabled bool, source string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status, exists := f.values[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownFeature, name)
+	}
+	status.Enabled = enabled
+	status.Source = source
+	f.values[name] = status
+	return nil
+}
@@ -0,0 +1,53 @@
+package features
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	flags, err := New(map[string]bool{NodeActions: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if flags.Enabled(AIAnalysis) {
+		t.Error("aiAnalysis should be disabled by default")
+	}
+	if !flags.Enabled(NodeActions) {
+		t.Error("nodeActions should be enabled by the configuration")
+	}
+
+	status, err := flags.Set(AIAnalysis, true)
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !status.Enabled || status.Source != SourceAPI {
+		t.Errorf("unexpected status after Set(): %+v", status)
+	}
+	if !flags.Enabled(AIAnalysis) {
+		t.Error("aiAnalysis should be enabled after Set()")
+	}
+
+	statuses := flags.List()
+	if len(statuses) != len(Definitions()) {
+		t.Fatalf("List() returned %d flags, want %d", len(statuses), len(Definitions()))
+	}
+	for i := 1; i < len(statuses); i++ {
+		if statuses[i-1].Name > statuses[i].Name {
+			t.Errorf("List() is not sorted: %v", statuses)
+		}
+	}
+
+	if _, err := flags.Set("teleport", true); !errors.Is(err, ErrUnknownFeature) {
+		t.Errorf("Set() of unknown flag error = %v, want ErrUnknownFeature", err)
+	}
+	if _, err := New(map[string]bool{"teleport": true}); !errors.Is(err, ErrUnknownFeature) {
+		t.Errorf("New() with unknown flag error = %v, want ErrUnknownFeature", err)
+	}
+
+	var nilFlags *Flags
+	if nilFlags.Enabled(MultiCluster) {
+		t.Error("nil flags should report the default")
+	}
+}
@@ -3,7 +3,10 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -20,6 +23,9 @@ const (
 	StatusUnknown   Status = "unknown"
 )
 
+// TagCritical tags the checks readiness depends on
+const TagCritical = "critical"
+
 // Check represents a health check
 type Check struct {
 	Name        string            `json:"name"`
@@ -38,6 +44,29 @@ type HealthResponse struct {
 	Checks    map[string]Check `json:"checks"`
 	Uptime    time.Duration    `json:"uptime"`
 	Version   string           `json:"version"`
+	// Controller is a snapshot of the detection cycles, nil until a source is set
+	Controller *ControllerStats `json:"controller,omitempty"`
+}
+
+// ControllerStats is an operational snapshot of the controller
+type ControllerStats struct {
+	// LastCycleAt is when the last full detection cycle started; zero before the first
+	LastCycleAt time.Time `json:"lastCycleAt"`
+	// LastCycleDuration is how long the last full detection cycle took
+	LastCycleDuration time.Duration `json:"lastCycleDuration"`
+	// IssuesDetected is the number of issues detected in the last full cycle
+	IssuesDetected int `json:"issuesDetected"`
+	// RemediationQueueDepth is the number of issues waiting to be processed
+	RemediationQueueDepth int `json:"remediationQueueDepth"`
+	// CircuitBreakers are the circuit breakers of the remediation engine by name
+	CircuitBreakers map[string]CircuitBreakerStats `json:"circuitBreakers,omitempty"`
+}
+
+// CircuitBreakerStats summarizes a circuit breaker
+type CircuitBreakerStats struct {
+	State               string `json:"state"`
+	ConsecutiveFailures uint32 `json:"consecutiveFailures"`
+	TotalFailures       uint32 `json:"totalFailures"`
 }
 
 // Checker interface for health checks
@@ -50,54 +79,81 @@ type Checker interface {
 type HealthCheck struct {
 	mu        sync.RWMutex
 	checks    map[string]Checker
+	tags      map[string][]string // Key: check name
 	results   map[string]Check
 	startTime time.Time
 	version   string
 	client    kubernetes.Interface
+	stats     func() ControllerStats // nil if no controller statistics are reported
 }
 
 // NewHealthCheck creates a new health check manager
 func NewHealthCheck(version string, client kubernetes.Interface) *HealthCheck {
 	hc := &HealthCheck{
 		checks:    make(map[string]Checker),
+		tags:      make(map[string][]string),
 		results:   make(map[string]Check),
 		startTime: time.Now(),
 		version:   version,
 		client:    client,
 	}
 
-	// Register built-in checks
-	hc.RegisterCheck(NewKubernetesAPICheck(client))
+	// Register built-in checks; readiness only depends on the Kubernetes API
+	hc.RegisterCheck(NewKubernetesAPICheck(client), TagCritical)
 	hc.RegisterCheck(NewMemoryCheck(80.0))    // 80% memory threshold
 	hc.RegisterCheck(NewDiskCheck("/", 85.0)) // 85% disk threshold
 
 	return hc
 }
 
-// RegisterCheck registers a health check
-func (h *HealthCheck) RegisterCheck(checker Checker) {
+// RegisterCheck registers a health check with tags, e.g. TagCritical for a
+// check readiness depends on
+func (h *HealthCheck) RegisterCheck(checker Checker, tags ...string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	h.checks[checker.Name()] = checker
+	h.tags[checker.Name()] = tags
+}
+
+// SetControllerStats sets the source of the controller statistics of the health response
+func (h *HealthCheck) SetControllerStats(stats func() ControllerStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stats = stats
 }
 
 // RunChecks runs all registered health checks
 func (h *HealthCheck) RunChecks(ctx context.Context) {
+	h.RunTaggedChecks(ctx, "")
+}
+
+// RunTaggedChecks runs the registered health checks with a tag, all of them if
+// tag is empty
+func (h *HealthCheck) RunTaggedChecks(ctx context.Context, tag string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	for name, checker := range h.checks {
+		if tag != "" && !slices.Contains(h.tags[name], tag) {
+			continue
+		}
 		start := time.Now()
 
 		result := Check{
 			Name:        name,
 			LastChecked: start,
 			Duration:    0,
+			Tags:        h.tags[name],
 		}
 
 		err := checker.Check(ctx)
 		result.Duration = time.Since(start)
+		// A check that outlasted the deadline of the run did not answer in time
+		if err == nil {
+			err = ctx.Err()
+		}
 
 		if err != nil {
 			result.Status = StatusUnhealthy
@@ -113,11 +169,24 @@ func (h *HealthCheck) RunChecks(ctx context.Context) {
 
 // GetHealth returns the current health status
 func (h *HealthCheck) GetHealth() HealthResponse {
+	return h.GetTaggedHealth("")
+}
+
+// GetTaggedHealth returns the current health status of the checks with a tag,
+// of all checks if tag is empty
+func (h *HealthCheck) GetTaggedHealth(tag string) HealthResponse {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	checks := make(map[string]Check, len(h.results))
+	for name, result := range h.results {
+		if tag == "" || slices.Contains(result.Tags, tag) {
+			checks[name] = result
+		}
+	}
+
 	overallStatus := StatusHealthy
-	for _, result := range h.results {
+	for _, result := range checks {
 		if result.Status == StatusUnhealthy {
 			overallStatus = StatusUnhealthy
 			break
@@ -126,13 +195,18 @@ func (h *HealthCheck) GetHealth() HealthResponse {
 		}
 	}
 
-	return HealthResponse{
+	response := HealthResponse{
 		Status:    overallStatus,
 		Timestamp: time.Now(),
-		Checks:    h.results,
+		Checks:    checks,
 		Uptime:    time.Since(h.startTime),
 		Version:   h.version,
 	}
+	if h.stats != nil {
+		stats := h.stats()
+		response.Controller = &stats
+	}
+	return response
 }
 
 // IsHealthy returns true if all checks are healthy
@@ -141,16 +215,18 @@ func (h *HealthCheck) IsHealthy() bool {
 	return health.Status == StatusHealthy
 }
 
-// HTTPHandler returns an HTTP handler for health checks
+// HTTPHandler returns an HTTP handler for health checks reporting all checks,
+// or the checks with the tag of the tag query parameter
 func (h *HealthCheck) HTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Run checks on demand
 		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 		defer cancel()
 
-		h.RunChecks(ctx)
+		tag := r.URL.Query().Get("tag")
+		h.RunTaggedChecks(ctx, tag)
 
-		health := h.GetHealth()
+		health := h.GetTaggedHealth(tag)
 
 		w.Header().Set("Content-Type", "application/json")
 
@@ -167,10 +243,15 @@ func (h *HealthCheck) HTTPHandler() http.HandlerFunc {
 	}
 }
 
-// ReadinessHandler returns a readiness probe handler
+// ReadinessHandler returns a readiness probe handler, which runs and only
+// considers the checks tagged critical
 func (h *HealthCheck) ReadinessHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		health := h.GetHealth()
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		h.RunTaggedChecks(ctx, TagCritical)
+		health := h.GetTaggedHealth(TagCritical)
 
 		if health.Status == StatusHealthy {
 			w.WriteHeader(http.StatusOK)
@@ -273,3 +354,52 @@ func (d *DiskCheck) Check(ctx context.Context) error {
 		return nil
 	}
 }
+
+// URLCheck checks that a URL answers a GET request, e.g. a dependency of the
+// environment KubeGuardian runs in
+type URLCheck struct {
+	name           string
+	url            string
+	expectedStatus int
+	client         *http.Client
+}
+
+// NewURLCheck creates a check of a URL, healthy if it answers with
+// expectedStatus, or with any status below 400 if expectedStatus is zero. A zero
+// timeout defaults to 5 seconds.
+func NewURLCheck(name, url string, timeout time.Duration, expectedStatus int) *URLCheck {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &URLCheck{
+		name:           name,
+		url:            url,
+		expectedStatus: expectedStatus,
+		client:         &http.Client{Timeout: timeout},
+	}
+}
+
+func (u *URLCheck) Name() string {
+	return u.name
+}
+
+func (u *URLCheck) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case u.expectedStatus != 0 && resp.StatusCode != u.expectedStatus:
+		return fmt.Errorf("GET %s returned %d, want %d", u.url, resp.StatusCode, u.expectedStatus)
+	case u.expectedStatus == 0 && resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("GET %s returned %d", u.url, resp.StatusCode)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package health
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -227,6 +230,68 @@ func TestHTTPHandler(t *testing.T) {
 	}
 }
 
+func TestTaggedChecks(t *testing.T) {
+	hc := NewHealthCheck("v1.0.0", fake.NewSimpleClientset())
+	failing := func(name string) *MockCheck {
+		return &MockCheck{name: name, checkFunc: func(ctx context.Context) error { return errors.New("down") }}
+	}
+	serve := func(handler http.HandlerFunc, target string) int {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder.Code
+	}
+
+	// A failing check without the critical tag only fails the full report
+	hc.RegisterCheck(failing("search"), "dependencies")
+	if code := serve(hc.ReadinessHandler(), "/readyz"); code != http.StatusOK {
+		t.Errorf("readiness = %d, want %d with a failing non-critical check", code, http.StatusOK)
+	}
+	if code := serve(hc.HTTPHandler(), "/health"); code != http.StatusServiceUnavailable {
+		t.Errorf("full health = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code := serve(hc.HTTPHandler(), "/health?tag=critical"); code != http.StatusOK {
+		t.Errorf("critical health = %d, want %d", code, http.StatusOK)
+	}
+	health := hc.GetTaggedHealth("dependencies")
+	if len(health.Checks) != 1 || health.Checks["search"].Tags[0] != "dependencies" {
+		t.Errorf("dependencies checks = %v, want only search", health.Checks)
+	}
+
+	hc.RegisterCheck(failing("database"), TagCritical)
+	if code := serve(hc.ReadinessHandler(), "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness = %d, want %d with a failing critical check", code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestURLCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		expected int
+		healthy  bool
+	}{
+		{"/ok", 0, true},
+		{"/ok", http.StatusNoContent, true},
+		{"/ok", http.StatusOK, false},
+		{"/missing", 0, false},
+		{"/missing", http.StatusNotFound, true},
+	}
+	for _, tt := range tests {
+		check := NewURLCheck("dependency", server.URL+tt.path, time.Second, tt.expected)
+		if err := check.Check(context.Background()); (err == nil) != tt.healthy {
+			t.Errorf("Check(%s, expecting %d) = %v, want healthy %v", tt.path, tt.expected, err, tt.healthy)
+		}
+	}
+}
+
 // MockCheck implements the Checker interface for testing
 type MockCheck struct {
 	name      string
@@ -259,3 +324,32 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHealthControllerStats(t *testing.T) {
+	hc := NewHealthCheck("v1.0.0", fake.NewSimpleClientset())
+	if health := hc.GetHealth(); health.Controller != nil {
+		t.Errorf("controller stats = %+v without a source, want none", health.Controller)
+	}
+
+	hc.SetControllerStats(func() ControllerStats {
+		return ControllerStats{
+			LastCycleDuration:     2 * time.Second,
+			IssuesDetected:        3,
+			RemediationQueueDepth: 1,
+			CircuitBreakers: map[string]CircuitBreakerStats{
+				"pods-api": {State: "open", ConsecutiveFailures: 6, TotalFailures: 6},
+			},
+		}
+	})
+
+	health := hc.GetHealth()
+	if health.Controller == nil {
+		t.Fatal("controller stats missing")
+	}
+	if health.Controller.IssuesDetected != 3 || health.Controller.RemediationQueueDepth != 1 {
+		t.Errorf("controller stats = %+v, want 3 issues and 1 queued", health.Controller)
+	}
+	if health.Controller.CircuitBreakers["pods-api"].State != "open" {
+		t.Errorf("circuit breakers = %+v, want pods-api open", health.Controller.CircuitBreakers)
+	}
+}
@@ -0,0 +1,103 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultShutdownTimeout bounds the graceful shutdown of all servers
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Manager runs the HTTP servers of KubeGuardian together. A server that fails
+// stops all of them, and all of them are shut down gracefully when the context
+// of Run is canceled.
+type Manager struct {
+	servers         []namedServer
+	shutdownTimeout time.Duration
+}
+
+// namedServer is a server and the name it is logged with
+type namedServer struct {
+	name   string
+	server *http.Server
+}
+
+// NewManager creates a manager that waits up to shutdownTimeout for in-flight
+// requests on shutdown; zero uses DefaultShutdownTimeout
+func NewManager(shutdownTimeout time.Duration) *Manager {
+	return &Manager{shutdownTimeout: orDefault(shutdownTimeout, DefaultShutdownTimeout)}
+}
+
+// Add adds a server; servers must be added before Run
+func (m *Manager) Add(name string, server *http.Server) {
+	m.servers = append(m.servers, namedServer{name: name, server: server})
+}
+
+// Run binds the listeners of all servers, serves until the context is canceled
+// or a server fails, then shuts all servers down. It returns the error of the
+// first server that failed, or nil after a graceful shutdown.
+func (m *Manager) Run(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	// Bind all listeners first so a busy address fails startup at once
+	listeners := make([]net.Listener, len(m.servers))
+	for i, s := range m.servers {
+		listener, err := net.Listen("tcp", s.server.Addr)
+		if err != nil {
+			for _, l := range listeners[:i] {
+				l.Close()
+			}
+			return fmt.Errorf("%s server: %w", s.name, err)
+		}
+		listeners[i] = listener
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, s := range m.servers {
+		listener := listeners[i]
+		g.Go(func() error {
+			logger.Info("Serving HTTP", "server", s.name, "address", listener.Addr().String(), "tls", s.server.TLSConfig != nil)
+			serve := s.server.Serve
+			if s.server.TLSConfig != nil {
+				// The certificates are in the TLS configuration
+				serve = func(l net.Listener) error { return s.server.ServeTLS(l, "", "") }
+			}
+			if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("%s server: %w", s.name, err)
+			}
+			return nil
+		})
+	}
+
+	g.Go(func() error {
+		<-gctx.Done()
+		return m.shutdown(ctx)
+	})
+
+	return g.Wait()
+}
+
+// shutdown stops all servers, waiting for in-flight requests until the shutdown timeout
+func (m *Manager) shutdown(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, s := range m.servers {
+		if err := s.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s server shutdown: %w", s.name, err))
+			s.server.Close()
+			continue
+		}
+		logger.Info("HTTP server stopped", "server", s.name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerShutsDownOnCancel(t *testing.T) {
+	manager := NewManager(time.Second)
+	manager.Add("probe", &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+	manager.Add("metrics", &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- manager.Run(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v, want nil after a graceful shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after the context was canceled")
+	}
+}
+
+func TestManagerFailsOnBusyAddress(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	manager := NewManager(time.Second)
+	manager.Add("probe", &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+	manager.Add("api", &http.Server{Addr: busy.Addr().String(), Handler: http.NotFoundHandler()})
+
+	err = manager.Run(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "api server") {
+		t.Errorf("Run() error = %v, want the api server bind error", err)
+	}
+}
+
+func TestManagerWaitsForInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	manager := NewManager(5 * time.Second)
+	manager.Add("api", &http.Server{Addr: addr, Handler: handler})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- manager.Run(ctx) }()
+
+	// Retry until the server listens
+	responses := make(chan *http.Response, 1)
+	go func() {
+		for {
+			resp, err := http.Get("http://" + addr)
+			if err == nil {
+				responses <- resp
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	<-started
+	cancel()
+
+	resp := <-responses
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("in-flight request status = %d, want 200", resp.StatusCode)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
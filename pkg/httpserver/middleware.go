@@ -0,0 +1,136 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+)
+
+// routeUnmatched is the route label of requests no route matched
+const routeUnmatched = "unmatched"
+
+// Options configure the middleware of a server
+type Options struct {
+	// Name identifies the server in access logs and metrics, e.g. probe, metrics or api
+	Name string
+	// Metrics records the latency of each route; nil records nothing
+	Metrics *metrics.Metrics
+	// QuietSuccess logs successful requests at verbosity 1 only, for servers that
+	// are probed or scraped every few seconds
+	QuietSuccess bool
+	// Tracing assigns each request a trace ID, continuing the trace of a W3C
+	// traceparent header, and passes it to the handler in the request context
+	Tracing bool
+}
+
+// Wrap wraps a handler with structured access logs, panic recovery and per-route
+// latency metrics. A panicking handler is answered with 500 Internal Server Error
+// if it has not written a response yet. Routes are the patterns of the
+// http.ServeMux serving the request, so path parameters do not inflate metric
+// cardinality.
+func Wrap(next http.Handler, opts Options) http.Handler {
+	baseLogger := log.Log.WithName("http").WithValues("server", opts.Name)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+
+		logger := baseLogger
+		if opts.Tracing {
+			traceID, ok := tracing.ParseTraceparent(r.Header.Get(tracing.HeaderTraceparent))
+			if !ok {
+				traceID = tracing.NewTraceID()
+			}
+			r = r.WithContext(tracing.WithTraceID(r.Context(), traceID))
+			logger = logger.WithValues("traceID", traceID)
+		}
+
+		func() {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// ErrAbortHandler aborts the response on purpose; let the server close the connection
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				logger.Error(fmt.Errorf("%v", recovered), "HTTP handler panicked",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()))
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		}()
+
+		status := rw.statusCode()
+		route := r.Pattern
+		if route == "" {
+			route = routeUnmatched
+		}
+		duration := time.Since(start)
+		if opts.Metrics != nil {
+			opts.Metrics.RecordHTTPRequest(r.Context(), opts.Name, route, r.Method, status, duration)
+		}
+
+		accessLog := logger
+		if opts.QuietSuccess && status < http.StatusBadRequest {
+			accessLog = logger.V(1)
+		}
+		accessLog.Info("HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", status,
+			"bytes", rw.bytes,
+			"duration", duration,
+			"remoteAddr", r.RemoteAddr)
+	})
+}
+
+// responseWriter records the status code and size of a response
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the status code of the response; 200 if none was written
+func (w *responseWriter) statusCode() int {
+	if !w.wroteHeader {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+)
+
+func TestWrap(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/items/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("/late-panic", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		panic("boom")
+	})
+	handler := Wrap(mux, Options{Name: "test", Metrics: metrics.NewMetrics()})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/ok", wantStatus: http.StatusOK},
+		{path: "/items/42", wantStatus: http.StatusAccepted},
+		{path: "/missing", wantStatus: http.StatusNotFound},
+		{path: "/panic", wantStatus: http.StatusInternalServerError},
+		{path: "/late-panic", wantStatus: http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestWrapTracing(t *testing.T) {
+	var traceID string
+	handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID, _ = tracing.TraceIDFromContext(r.Context())
+	}), Options{Name: "test", Tracing: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(tracing.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %q, want the trace of the traceparent header", traceID)
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if traceID == "" || traceID == "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %q, want a new trace for a request without traceparent", traceID)
+	}
+}
+
+func TestResponseWriterCountsBytes(t *testing.T) {
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	if rw.statusCode() != http.StatusOK {
+		t.Errorf("statusCode() = %d before writing, want 200", rw.statusCode())
+	}
+	rw.Write([]byte("hello"))
+	rw.WriteHeader(http.StatusInternalServerError)
+	if rw.statusCode() != http.StatusOK || rw.bytes != 5 {
+		t.Errorf("status = %d, bytes = %d, want 200 and 5", rw.statusCode(), rw.bytes)
+	}
+}
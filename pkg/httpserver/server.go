@@ -0,0 +1,47 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+)
+
+// Default server-side timeouts
+const (
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 2 * time.Minute
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+// Timeouts bound how long a server waits for a client; zero uses the default
+type Timeouts struct {
+	// ReadHeaderTimeout bounds reading the request headers
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout bounds reading the whole request
+	ReadTimeout time.Duration
+	// WriteTimeout bounds serving a request, from the end of its headers to the
+	// end of the response
+	WriteTimeout time.Duration
+	// IdleTimeout bounds waiting for the next request on a keep-alive connection
+	IdleTimeout time.Duration
+}
+
+// NewServer creates an HTTP server serving a handler wrapped in the middleware
+func NewServer(addr string, handler http.Handler, timeouts Timeouts, opts Options) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           Wrap(handler, opts),
+		ReadHeaderTimeout: orDefault(timeouts.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		ReadTimeout:       orDefault(timeouts.ReadTimeout, DefaultReadTimeout),
+		WriteTimeout:      orDefault(timeouts.WriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       orDefault(timeouts.IdleTimeout, DefaultIdleTimeout),
+	}
+}
+
+// orDefault returns d, or def if d is not positive
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
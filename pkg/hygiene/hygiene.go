@@ -0,0 +1,246 @@
+package hygiene
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/pager"
+)
+
+// Advisory checks of workload configuration
+const (
+	// CheckMissingReadinessProbe reports containers without a readiness probe
+	CheckMissingReadinessProbe = "missing-readiness-probe"
+	// CheckIdenticalProbes reports containers whose liveness probe equals their
+	// readiness probe, so a slow dependency restarts them instead of taking them
+	// out of rotation
+	CheckIdenticalProbes = "identical-probes"
+	// CheckMissingRequests reports containers without CPU or memory requests
+	CheckMissingRequests = "missing-requests"
+	// CheckMissingLimits reports containers without a memory limit
+	CheckMissingLimits = "missing-limits"
+	// CheckSingleReplica reports Deployments and StatefulSets with a single
+	// replica in production namespaces
+	CheckSingleReplica = "single-replica"
+	// CheckPrivilegedContainer reports containers that run privileged or
+	// explicitly allow privilege escalation
+	CheckPrivilegedContainer = "privileged-container"
+)
+
+// Checks are all advisory checks
+var Checks = []string{
+	CheckMissingReadinessProbe,
+	CheckIdenticalProbes,
+	CheckMissingRequests,
+	CheckMissingLimits,
+	CheckSingleReplica,
+	CheckPrivilegedContainer,
+}
+
+// IsValidCheck returns true if name is a known check
+func IsValidCheck(name string) bool {
+	for _, check := range Checks {
+		if check == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Finding is a workload, or a container of it, failing a check
+type Finding struct {
+	Check     string `json:"check"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Report is the outcome of a scan of the workloads of the cluster
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	// Workloads is the number of workloads checked
+	Workloads int `json:"workloads"`
+	// Counts is the number of findings by check
+	Counts   map[string]int `json:"counts"`
+	Findings []Finding      `json:"findings"`
+}
+
+// Title returns the headline of the report
+func (r *Report) Title() string {
+	return fmt.Sprintf("KubeGuardian hygiene report %s", r.GeneratedAt.Format("2006-01-02"))
+}
+
+// ByNamespace returns the number of findings by namespace and check
+func (r *Report) ByNamespace() map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	for _, f := range r.Findings {
+		if counts[f.Namespace] == nil {
+			counts[f.Namespace] = make(map[string]int)
+		}
+		counts[f.Namespace][f.Check]++
+	}
+	return counts
+}
+
+// Config configures a scanner
+type Config struct {
+	// Checks are the checks run; empty runs all of them
+	Checks []string
+	// ProductionNamespaces are glob patterns of the namespaces single-replica
+	// workloads are reported in
+	ProductionNamespaces []string
+	// ExcludeNamespaces are glob patterns of namespaces that are not checked
+	ExcludeNamespaces []string
+	// PageSize is the number of workloads listed per request; zero lists them at once
+	PageSize int64
+}
+
+// Scanner checks the configuration of the Deployments and StatefulSets of the
+// cluster. Its findings are advisory: they are reported, never remediated.
+type Scanner struct {
+	client kubernetes.Interface
+	config Config
+	checks map[string]bool
+}
+
+// NewScanner creates a scanner running the configured checks
+func NewScanner(client kubernetes.Interface, cfg Config) *Scanner {
+	names := cfg.Checks
+	if len(names) == 0 {
+		names = Checks
+	}
+	checks := make(map[string]bool, len(names))
+	for _, name := range names {
+		checks[name] = true
+	}
+	return &Scanner{client: client, config: cfg, checks: checks}
+}
+
+// Scan checks all workloads and returns the findings sorted by check and workload
+func (s *Scanner) Scan(ctx context.Context, now time.Time) (*Report, error) {
+	report := &Report{GeneratedAt: now, Counts: make(map[string]int), Findings: []Finding{}}
+	add := func(meta metav1.Object, kind string, replicas *int32, spec *corev1.PodSpec) {
+		if matchesAny(s.config.ExcludeNamespaces, meta.GetNamespace()) {
+			return
+		}
+		report.Workloads++
+		report.Findings = append(report.Findings, s.checkWorkload(meta.GetNamespace(), kind, meta.GetName(), replicas, spec)...)
+	}
+
+	err := s.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return s.client.AppsV1().Deployments("").List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		deployment := obj.(*appsv1.Deployment)
+		add(deployment, "Deployment", deployment.Spec.Replicas, &deployment.Spec.Template.Spec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	err = s.eachListItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return s.client.AppsV1().StatefulSets("").List(ctx, opts)
+	}, func(obj runtime.Object) error {
+		statefulSet := obj.(*appsv1.StatefulSet)
+		add(statefulSet, "StatefulSet", statefulSet.Spec.Replicas, &statefulSet.Spec.Template.Spec)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	sort.Slice(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		for _, pair := range [][2]string{{a.Check, b.Check}, {a.Namespace, b.Namespace}, {a.Kind, b.Kind}, {a.Name, b.Name}} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		return a.Container < b.Container
+	})
+	for _, f := range report.Findings {
+		report.Counts[f.Check]++
+	}
+	return report, nil
+}
+
+// checkWorkload runs the checks against a workload and its containers
+func (s *Scanner) checkWorkload(namespace, kind, name string, replicas *int32, spec *corev1.PodSpec) []Finding {
+	var findings []Finding
+	finding := func(check, container, message string) {
+		if s.checks[check] {
+			findings = append(findings, Finding{Check: check, Namespace: namespace, Kind: kind, Name: name, Container: container, Message: message})
+		}
+	}
+
+	// An unset replica count defaults to one; workloads scaled to zero are not reported
+	if (replicas == nil || *replicas == 1) && matchesAny(s.config.ProductionNamespaces, namespace) {
+		finding(CheckSingleReplica, "", "runs a single replica in a production namespace")
+	}
+
+	for _, container := range spec.Containers {
+		switch {
+		case container.ReadinessProbe == nil:
+			finding(CheckMissingReadinessProbe, container.Name, "has no readiness probe")
+		case container.LivenessProbe != nil && equality.Semantic.DeepEqual(container.LivenessProbe, container.ReadinessProbe):
+			finding(CheckIdenticalProbes, container.Name, "uses the same liveness and readiness probe")
+		}
+
+		if missing := missingResources(container.Resources.Requests, corev1.ResourceCPU, corev1.ResourceMemory); len(missing) > 0 {
+			finding(CheckMissingRequests, container.Name, fmt.Sprintf("has no %s request", strings.Join(missing, " or ")))
+		}
+		if missing := missingResources(container.Resources.Limits, corev1.ResourceMemory); len(missing) > 0 {
+			finding(CheckMissingLimits, container.Name, "has no memory limit")
+		}
+
+		if sc := container.SecurityContext; sc != nil {
+			switch {
+			case sc.Privileged != nil && *sc.Privileged:
+				finding(CheckPrivilegedContainer, container.Name, "runs privileged")
+			case sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation:
+				finding(CheckPrivilegedContainer, container.Name, "allows privilege escalation")
+			}
+		}
+	}
+	return findings
+}
+
+// missingResources returns the resources that are not set in list
+func missingResources(list corev1.ResourceList, resources ...corev1.ResourceName) []string {
+	var missing []string
+	for _, resource := range resources {
+		if quantity, exists := list[resource]; !exists || quantity.IsZero() {
+			missing = append(missing, string(resource))
+		}
+	}
+	return missing
+}
+
+// matchesAny returns true if the namespace matches one of the glob patterns
+func matchesAny(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// eachListItem calls fn for the objects of a paged list
+func (s *Scanner) eachListItem(ctx context.Context, page pager.ListPageFunc, fn func(runtime.Object) error) error {
+	p := pager.New(page)
+	p.PageSize = s.config.PageSize
+	return p.EachListItem(ctx, metav1.ListOptions{}, fn)
+}
@@ -0,0 +1,146 @@
+package hygiene
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+)
+
+func probe(path string) *corev1.Probe {
+	return &corev1.Probe{ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: path, Port: intstr.FromInt32(8080)}}}
+}
+
+func resources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+}
+
+func deployment(namespace, name string, replicas int32, containers ...corev1.Container) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}},
+		},
+	}
+}
+
+func TestScan(t *testing.T) {
+	healthy := corev1.Container{Name: "app", ReadinessProbe: probe("/ready"), LivenessProbe: probe("/live"), Resources: resources()}
+	client := fake.NewSimpleClientset(
+		deployment("prod", "api", 3, healthy),
+		deployment("prod", "worker", 1, healthy),
+		deployment("prod", "web", 2,
+			corev1.Container{Name: "app", ReadinessProbe: probe("/health"), LivenessProbe: probe("/health"), Resources: resources()},
+			corev1.Container{Name: "proxy", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}}},
+		),
+		deployment("dev", "sandbox", 1, healthy),
+		deployment("prod", "paused", 0, healthy),
+		deployment("kube-system", "dns", 1, corev1.Container{Name: "dns"}),
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod-eu"},
+			Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{healthy}}}},
+		},
+	)
+
+	scanner := NewScanner(client, Config{ProductionNamespaces: []string{"prod", "prod-*"}, ExcludeNamespaces: []string{"kube-*"}})
+	report, err := scanner.Scan(context.Background(), time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	if report.Workloads != 6 {
+		t.Errorf("Workloads = %d, want 6 outside excluded namespaces", report.Workloads)
+	}
+	want := []Finding{
+		{Check: CheckIdenticalProbes, Namespace: "prod", Kind: "Deployment", Name: "web", Container: "app"},
+		{Check: CheckMissingLimits, Namespace: "prod", Kind: "Deployment", Name: "web", Container: "proxy"},
+		{Check: CheckMissingReadinessProbe, Namespace: "prod", Kind: "Deployment", Name: "web", Container: "proxy"},
+		{Check: CheckMissingRequests, Namespace: "prod", Kind: "Deployment", Name: "web", Container: "proxy"},
+		{Check: CheckSingleReplica, Namespace: "prod", Kind: "Deployment", Name: "worker"},
+		{Check: CheckSingleReplica, Namespace: "prod-eu", Kind: "StatefulSet", Name: "db"},
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("findings = %+v, want %d", report.Findings, len(want))
+	}
+	for i, f := range report.Findings {
+		w := want[i]
+		if f.Check != w.Check || f.Namespace != w.Namespace || f.Kind != w.Kind || f.Name != w.Name || f.Container != w.Container {
+			t.Errorf("finding %d = %+v, want %+v", i, f, w)
+		}
+	}
+	if msg := report.Findings[3].Message; msg != "has no memory request" {
+		t.Errorf("message = %q, want the missing memory request", msg)
+	}
+	if report.Counts[CheckSingleReplica] != 2 || report.ByNamespace()["prod"][CheckSingleReplica] != 1 {
+		t.Errorf("unexpected counts: %v, %v", report.Counts, report.ByNamespace())
+	}
+
+	only := NewScanner(client, Config{Checks: []string{CheckIdenticalProbes}, ExcludeNamespaces: []string{"kube-*"}})
+	report, err = only.Scan(context.Background(), time.Now())
+	if err != nil || len(report.Findings) != 1 {
+		t.Errorf("expected only the identical probes finding, got %+v (%v)", report, err)
+	}
+}
+
+func TestReporterSendDue(t *testing.T) {
+	ctx := context.Background()
+	sched, err := schedule.Parse("0 9 * * 1", "UTC")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	client := fake.NewSimpleClientset(deployment("prod", "api", 1, corev1.Container{Name: "app"}))
+	clock := clocktesting.NewFakePassiveClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) // Sunday
+	var sent []*Report
+	var sendErr error
+	scans := 0
+	r := NewReporter(NewScanner(client, Config{}), ReporterConfig{
+		Schedule: sched,
+		Clock:    clock,
+		Scanned:  func(*Report) { scans++ },
+		Send: func(_ context.Context, report *Report) error {
+			if sendErr != nil {
+				return sendErr
+			}
+			sent = append(sent, report)
+			return nil
+		},
+	})
+
+	r.SendDue(ctx)
+	if len(sent) != 0 || scans != 0 || r.Latest() != nil {
+		t.Fatalf("expected the first poll to only schedule the first report")
+	}
+
+	sendErr = errors.New("unavailable")
+	clock.SetTime(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	sendErr = nil
+	clock.SetTime(time.Date(2026, 3, 2, 9, 1, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	if len(sent) != 1 || scans != 2 {
+		t.Fatalf("expected the failed report to be retried, got %d reports after %d scans", len(sent), scans)
+	}
+	if sent[0].Counts[CheckMissingReadinessProbe] != 1 || r.Latest() != sent[0] {
+		t.Errorf("unexpected report: %+v", sent[0])
+	}
+
+	clock.SetTime(time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC))
+	r.SendDue(ctx)
+	if len(sent) != 1 {
+		t.Errorf("expected one report per week, got %d", len(sent))
+	}
+}
@@ -0,0 +1,124 @@
+package hygiene
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/schedule"
+)
+
+// pollInterval is how often the reporter checks whether a report is due
+const pollInterval = time.Minute
+
+// ReporterConfig configures a reporter
+type ReporterConfig struct {
+	Schedule *schedule.Schedule
+	// Send delivers a report; nil only keeps the latest report
+	Send func(ctx context.Context, report *Report) error
+	// Scanned is called after each scan, e.g. to record metrics
+	Scanned func(report *Report)
+	// Clock is the time source; nil uses the real clock
+	Clock clock.PassiveClock
+}
+
+// Reporter scans the workloads of the cluster on a schedule and sends one report
+// of all findings, instead of notifying each of them. A failed scan or send is
+// retried on the next poll.
+type Reporter struct {
+	scanner *Scanner
+	config  ReporterConfig
+
+	mu     sync.Mutex
+	next   time.Time // Zero until the first poll
+	latest *Report
+}
+
+// NewReporter creates a reporter of the findings of scanner
+func NewReporter(scanner *Scanner, cfg ReporterConfig) *Reporter {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.RealClock{}
+	}
+	return &Reporter{scanner: scanner, config: cfg}
+}
+
+// Run sends reports when due until the context is cancelled
+func (r *Reporter) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	r.SendDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.SendDue(ctx)
+		}
+	}
+}
+
+// SendDue scans the workloads and sends the report if the schedule activated
+// since the previous report. The first poll only schedules the first report.
+func (r *Reporter) SendDue(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	now := r.config.Clock.Now()
+
+	r.mu.Lock()
+	if r.next.IsZero() {
+		r.next = r.config.Schedule.Next(now)
+	}
+	due := !now.Before(r.next)
+	r.mu.Unlock()
+	if !due {
+		return
+	}
+
+	report, err := r.Scan(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to scan workload hygiene")
+		return
+	}
+	if r.config.Send != nil {
+		if err := r.config.Send(ctx, report); err != nil {
+			logger.Error(err, "Failed to send hygiene report")
+			return
+		}
+	}
+
+	next := r.config.Schedule.Next(now)
+	r.mu.Lock()
+	r.next = next
+	r.mu.Unlock()
+	logger.Info("Sent hygiene report", "workloads", report.Workloads, "findings", len(report.Findings), "next", next)
+}
+
+// Scan scans the workloads now and keeps the report as the latest one
+func (r *Reporter) Scan(ctx context.Context) (*Report, error) {
+	report, err := r.scanner.Scan(ctx, r.config.Clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if r.config.Scanned != nil {
+		r.config.Scanned(report)
+	}
+
+	r.mu.Lock()
+	r.latest = report
+	r.mu.Unlock()
+	return report, nil
+}
+
+// Latest returns the report of the latest scan, or nil before the first scan
+func (r *Reporter) Latest() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latest
+}
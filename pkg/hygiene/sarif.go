@@ -0,0 +1,175 @@
+package hygiene
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Hygiene reports can be written in the Static Analysis Results Interchange
+// Format (SARIF), which code scanning dashboards and security tools ingest
+const (
+	// FormatSARIF selects SARIF output
+	FormatSARIF = "sarif"
+	// SARIFContentType is the media type of SARIF reports
+	SARIFContentType = "application/sarif+json"
+)
+
+// SARIF log format and tool
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolURI      = "https://github.com/NotHarshhaa/kubeguardian"
+)
+
+// checkRule describes a check as a SARIF reporting rule
+type checkRule struct {
+	description string
+	// level is error, warning or note
+	level string
+	tags  []string
+}
+
+// checkRules describe the checks in SARIF reports
+var checkRules = map[string]checkRule{
+	CheckMissingReadinessProbe: {"Container has no readiness probe", "warning", []string{"reliability"}},
+	CheckIdenticalProbes:       {"Container's liveness probe equals its readiness probe", "warning", []string{"reliability"}},
+	CheckMissingRequests:       {"Container has no CPU or memory request", "warning", []string{"reliability"}},
+	CheckMissingLimits:         {"Container has no memory limit", "note", []string{"reliability"}},
+	CheckSingleReplica:         {"Workload runs a single replica in a production namespace", "warning", []string{"reliability"}},
+	CheckPrivilegedContainer:   {"Container runs privileged or allows privilege escalation", "error", []string{"security"}},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the findings of the report as a SARIF 2.1.0 log of a
+// KubeGuardian run of the given version. Results are fingerprinted by check and
+// location, so dashboards track a finding across reports.
+func (r *Report) WriteSARIF(w io.Writer, version string) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "KubeGuardian", Version: version, InformationURI: toolURI, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, f := range r.Findings {
+		rule := checkRules[f.Check]
+		index, exists := ruleIndex[f.Check]
+		if !exists {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[f.Check] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   f.Check,
+				ShortDescription:     sarifMessage{Text: rule.description},
+				DefaultConfiguration: sarifConfiguration{Level: rule.level},
+				Properties:           sarifProperties{Tags: rule.tags},
+			})
+		}
+
+		location := f.location()
+		fingerprint := sha256.Sum256([]byte(f.Check + "\x00" + location))
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.Check,
+			RuleIndex: index,
+			Level:     rule.level,
+			Message:   sarifMessage{Text: f.sentence()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: location}},
+				LogicalLocations: []sarifLogicalLocation{{Name: f.Name, FullyQualifiedName: location, Kind: "resource"}},
+			}},
+			PartialFingerprints: map[string]string{"kubeguardian/v1": hex.EncodeToString(fingerprint[:])},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sentence describes a finding, e.g. "Deployment shop/web container app has no readiness probe"
+func (f Finding) sentence() string {
+	subject := fmt.Sprintf("%s %s/%s", f.Kind, f.Namespace, f.Name)
+	if f.Container != "" {
+		subject += " container " + f.Container
+	}
+	return subject + " " + f.Message
+}
+
+// location returns the workload or container of a finding, e.g. shop/Deployment/web/app
+func (f Finding) location() string {
+	parts := []string{f.Namespace, f.Kind, f.Name}
+	if f.Container != "" {
+		parts = append(parts, f.Container)
+	}
+	return strings.Join(parts, "/")
+}
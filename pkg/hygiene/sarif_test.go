@@ -0,0 +1,64 @@
+package hygiene
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	privileged := true
+	client := fake.NewSimpleClientset(
+		deployment("shop", "web", 2,
+			corev1.Container{Name: "app", ReadinessProbe: probe("/ready"), Resources: resources(), SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+			corev1.Container{Name: "proxy", ReadinessProbe: probe("/ready"), Resources: resources()},
+		),
+		deployment("shop", "api", 2, corev1.Container{Name: "app", Resources: resources(), SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: &privileged}}),
+	)
+	report, err := NewScanner(client, Config{}).Scan(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteSARIF(&buf, "v1.6.0"); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF %s: %v", buf.String(), err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Version != "v1.6.0" {
+		t.Fatalf("log = %+v, want one run of KubeGuardian v1.6.0", log)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 3 {
+		t.Fatalf("rules = %+v, results = %+v, want 2 rules and 3 results", run.Tool.Driver.Rules, run.Results)
+	}
+	for _, result := range run.Results {
+		rule := run.Tool.Driver.Rules[result.RuleIndex]
+		if rule.ID != result.RuleID {
+			t.Errorf("result %s refers to rule %s", result.RuleID, rule.ID)
+		}
+		if result.RuleID == CheckPrivilegedContainer && (result.Level != "error" || rule.Properties.Tags[0] != "security") {
+			t.Errorf("privileged result = %+v of rule %+v, want a security error", result, rule)
+		}
+	}
+
+	web := run.Results[len(run.Results)-1]
+	if web.Locations[0].PhysicalLocation.ArtifactLocation.URI != "shop/Deployment/web/app" || web.Message.Text != "Deployment shop/web container app runs privileged" {
+		t.Errorf("result = %+v, want the privileged app container of shop/web", web)
+	}
+
+	// Fingerprints identify a finding across reports
+	var again bytes.Buffer
+	if err := report.WriteSARIF(&again, "v1.6.0"); err != nil || again.String() != buf.String() {
+		t.Errorf("WriteSARIF() is not deterministic: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"path"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OverflowLabel replaces label values beyond the configured limits
+const OverflowLabel = "other"
+
+// NamespaceBucket reports all namespaces matching one of its glob patterns under a
+// single namespace label value
+type NamespaceBucket struct {
+	Name     string
+	Patterns []string
+}
+
+// LabelLimits bounds the cardinality of the namespace and rule labels. Zero
+// limits are unlimited.
+type LabelLimits struct {
+	// NamespaceBuckets are applied in order before MaxNamespaces is enforced
+	NamespaceBuckets []NamespaceBucket
+	// MaxNamespaces is the number of distinct namespace label values
+	MaxNamespaces int
+	// MaxRules is the number of distinct rule label values
+	MaxRules int
+}
+
+var labelOverflowTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kubeguardian_metric_label_overflow_total",
+		Help: "Total number of label values reported as \"other\" because a cardinality limit was reached, by label",
+	},
+	[]string{"label"},
+)
+
+// labelLimiter maps label values to bounded sets. Values seen first keep their
+// own label; later values beyond the limit share the overflow label.
+type labelLimiter struct {
+	mu         sync.Mutex
+	limits     LabelLimits
+	namespaces map[string]bool
+	rules      map[string]bool
+}
+
+func newLabelLimiter(limits LabelLimits) *labelLimiter {
+	return &labelLimiter{
+		limits:     limits,
+		namespaces: make(map[string]bool),
+		rules:      make(map[string]bool),
+	}
+}
+
+// namespace returns the label value reported for a namespace
+func (l *labelLimiter) namespace(namespace string) string {
+	if l == nil {
+		return namespace
+	}
+	for _, bucket := range l.limits.NamespaceBuckets {
+		for _, pattern := range bucket.Patterns {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				return bucket.Name
+			}
+		}
+	}
+	return l.bound("namespace", namespace, l.namespaces, l.limits.MaxNamespaces)
+}
+
+// rule returns the label value reported for a rule
+func (l *labelLimiter) rule(rule string) string {
+	if l == nil {
+		return rule
+	}
+	return l.bound("rule", rule, l.rules, l.limits.MaxRules)
+}
+
+func (l *labelLimiter) bound(label, value string, seen map[string]bool, max int) string {
+	if max <= 0 {
+		return value
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if seen[value] {
+		return value
+	}
+	if len(seen) < max {
+		seen[value] = true
+		return value
+	}
+	labelOverflowTotal.WithLabelValues(label).Inc()
+	return OverflowLabel
+}
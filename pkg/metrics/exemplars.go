@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+)
+
+// ExemplarTraceIDLabel is the exemplar label holding the trace ID, as expected by
+// Grafana to link exemplars to traces
+const ExemplarTraceIDLabel = "trace_id"
+
+// observe records a value in a histogram. If the context carries a trace ID, it is
+// attached as an exemplar so the observation links to its trace; exemplars are
+// only exposed in the OpenMetrics format.
+func observe(ctx context.Context, observer prometheus.Observer, value float64) {
+	if traceID, ok := tracing.TraceIDFromContext(ctx); ok {
+		if exemplars, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplars.ObserveWithExemplar(value, prometheus.Labels{ExemplarTraceIDLabel: traceID})
+			return
+		}
+	}
+	observer.Observe(value)
+}
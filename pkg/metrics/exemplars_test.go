@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracing"
+)
+
+func TestObserveAttachesTraceExemplar(t *testing.T) {
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_duration_seconds", Buckets: []float64{1, 10}})
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	observe(context.Background(), histogram, 5)
+	observe(tracing.WithTraceID(context.Background(), traceID), histogram, 0.5)
+
+	var metric dto.Metric
+	if err := histogram.Write(&metric); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := metric.GetHistogram().GetSampleCount(); got != 2 {
+		t.Errorf("sample count = %d, want 2", got)
+	}
+
+	buckets := metric.GetHistogram().GetBucket()
+	exemplar := buckets[0].GetExemplar()
+	if exemplar == nil || exemplar.GetValue() != 0.5 {
+		t.Fatalf("first bucket exemplar = %v, want the traced observation", exemplar)
+	}
+	if labels := exemplar.GetLabel(); len(labels) != 1 || labels[0].GetName() != ExemplarTraceIDLabel || labels[0].GetValue() != traceID {
+		t.Errorf("exemplar labels = %v, want %s=%s", labels, ExemplarTraceIDLabel, traceID)
+	}
+	if buckets[1].GetExemplar() != nil {
+		t.Errorf("untraced observation has exemplar %v", buckets[1].GetExemplar())
+	}
+}
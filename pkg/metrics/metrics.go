@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"context"
+	"strconv"
 	"sync"
 	"time"
 
@@ -8,6 +10,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// lifecycleBuckets covers issue lifetimes from seconds to a day
+var lifecycleBuckets = []float64{10, 30, 60, 120, 300, 600, 1800, 3600, 7200, 21600, 86400}
+
 var (
 	once sync.Once
 
@@ -29,6 +34,14 @@ var (
 		[]string{"rule"},
 	)
 
+	issuesExcludedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_issues_excluded_total",
+			Help: "Total number of issues of excluded workloads by stage (detection or remediation) and rule",
+		},
+		[]string{"stage", "rule"},
+	)
+
 	// Remediation metrics
 	remediationTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -47,7 +60,238 @@ var (
 		[]string{"action"},
 	)
 
+	// Issue lifecycle metrics
+	issueTimeToRemediation = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeguardian_issue_time_to_remediation_seconds",
+			Help:    "Time from first detection of an issue to its first successful remediation",
+			Buckets: lifecycleBuckets,
+		},
+		[]string{"rule", "namespace"},
+	)
+
+	issueTimeToResolution = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeguardian_issue_time_to_resolution_seconds",
+			Help:    "Time from first detection of an issue until it is no longer detected",
+			Buckets: lifecycleBuckets,
+		},
+		[]string{"rule", "namespace", "outcome"},
+	)
+
+	issuesResolvedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_issues_resolved_total",
+			Help: "Total number of resolved issues by outcome (auto_resolved or escalated)",
+		},
+		[]string{"rule", "namespace", "outcome"},
+	)
+
+	issueTransitionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_issue_transitions_total",
+			Help: "Total number of issues observed per evaluation cycle by transition (new, active or resolved)",
+		},
+		[]string{"transition"},
+	)
+
+	issueSeverityEscalationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_issue_severity_escalations_total",
+			Help: "Total number of unresolved issues raised to a severity by rule, severity and namespace",
+		},
+		[]string{"rule", "severity", "namespace"},
+	)
+
+	// Rule evaluation state metrics
+	conditionStates = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_condition_states",
+			Help: "Number of conditions tracked for duration-based rules",
+		},
+	)
+
+	conditionStatesExpiredTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_condition_states_expired_total",
+			Help: "Total number of tracked conditions removed after their TTL",
+		},
+	)
+
+	stateStoreErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_state_store_errors_total",
+			Help: "Total number of rule evaluation state store errors by operation",
+		},
+		[]string{"operation"},
+	)
+
+	featureEnabled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_feature_enabled",
+			Help: "Whether a feature flag is enabled (1) or disabled (0)",
+		},
+		[]string{"feature"},
+	)
+
+	ruleInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_rule_info",
+			Help: "Detection rules by owner and source (builtin, file or crd); always 1",
+		},
+		[]string{"rule", "owner", "source"},
+	)
+
+	ruleLastModified = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_rule_last_modified_timestamp_seconds",
+			Help: "Unix timestamp of the last change of a detection rule, if known",
+		},
+		[]string{"rule"},
+	)
+
+	ruleDisabledAtRuntime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_rule_disabled_at_runtime",
+			Help: "Whether a detection rule was disabled at runtime through the API (1) or not (0)",
+		},
+		[]string{"rule"},
+	)
+
+	ruleChangesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_rule_changes_total",
+			Help: "Total number of detection rules enabled or disabled at runtime",
+		},
+		[]string{"rule", "enabled"},
+	)
+
+	ruleDecisionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_rule_decisions_total",
+			Help: "Total number of rule evaluations against a resource by outcome and, for skipped ones, the first unmatched condition",
+		},
+		[]string{"rule", "outcome", "condition"},
+	)
+
+	incidentModeActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_incident_mode_active",
+			Help: "Number of active incidents relaxing remediation cooldowns and the action budget",
+		},
+	)
+
+	remediationPreChecksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_remediation_prechecks_total",
+			Help: "Total number of pre-checks remediation actions passed through by action, check and outcome",
+		},
+		[]string{"action", "check", "outcome"},
+	)
+
+	rolloutWatchesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_rollout_watch_total",
+			Help: "Total number of Deployment rollouts followed after remediation actions by action and final state",
+		},
+		[]string{"action", "outcome"},
+	)
+
+	apiRequestTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_api_request_timeouts_total",
+			Help: "Total number of Kubernetes API requests that exceeded their timeout by budget (list or mutate) and resource",
+		},
+		[]string{"budget", "resource"},
+	)
+
+	interventionRequired = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_issues_intervention_required",
+			Help: "Number of active issues whose remediation failed more often than the retries allow and requires human intervention",
+		},
+	)
+
+	configDriftHashes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_config_drift_hashes",
+			Help: "Number of distinct configuration hashes among the live KubeGuardian instances; above 1 the instances drifted apart",
+		},
+	)
+
+	informerCacheSynced = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_informer_cache_synced",
+			Help: "Whether the informer cache of a resource has synced (1) or not (0)",
+		},
+		[]string{"resource"},
+	)
+
+	informerCacheObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_informer_cache_objects",
+			Help: "Number of objects in the informer cache of a resource",
+		},
+		[]string{"resource"},
+	)
+
+	informerProgressAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_informer_seconds_since_progress",
+			Help: "Seconds since the watch of a resource last delivered an event or advanced its resource version",
+		},
+		[]string{"resource"},
+	)
+
+	informerWatchLag = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeguardian_informer_watch_lag_seconds",
+			Help:    "Time from the last write of an object to its watch event reaching the informer, with the one second precision of managed fields",
+			Buckets: []float64{1, 2, 5, 10, 30, 60, 300},
+		},
+		[]string{"resource"},
+	)
+
+	clusterCapability = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_cluster_capability",
+			Help: "Whether the cluster has a capability required by remediation actions (1) or not (0)",
+		},
+		[]string{"capability"},
+	)
+
+	batchSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_batch_size",
+			Help: "Adaptive batch size by kind (listPageSize or remediationWorkers)",
+		},
+		[]string{"kind"},
+	)
+
+	remediationQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_remediation_queue_depth",
+			Help: "Number of remediation actions in the remediation queue",
+		},
+	)
+
+	remediationQueueItemsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_remediation_queue_items_total",
+			Help: "Total number of remediation queue items by outcome (enqueued, done, retried, dropped or drained)",
+		},
+		[]string{"outcome"},
+	)
+
 	// Cooldown metrics
+	hygieneFindings = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_hygiene_findings",
+			Help: "Advisory workload configuration findings of the latest hygiene scan by check and namespace",
+		},
+		[]string{"check", "namespace"},
+	)
+
 	cooldownActive = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "kubeguardian_cooldown_active",
@@ -65,6 +309,23 @@ var (
 		[]string{"method", "resource", "status"},
 	)
 
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_http_requests_total",
+			Help: "Total number of HTTP requests served by server, route, method and status code",
+		},
+		[]string{"server", "route", "method", "code"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeguardian_http_request_duration_seconds",
+			Help:    "Time spent serving HTTP requests by server and route",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"server", "route"},
+	)
+
 	apiDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "kubeguardian_api_duration_seconds",
@@ -83,6 +344,69 @@ var (
 		[]string{"type", "status"},
 	)
 
+	notificationsDroppedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_notifications_dropped_total",
+			Help: "Total number of notifications dropped by type and reason",
+		},
+		[]string{"type", "reason"},
+	)
+
+	notificationRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_notification_retries_total",
+			Help: "Total number of notification delivery retries by type",
+		},
+		[]string{"type"},
+	)
+
+	notificationQueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeguardian_notification_queue_depth",
+			Help: "Number of notifications waiting for delivery",
+		},
+	)
+
+	analysisTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_analysis_total",
+			Help: "Total number of root cause analyses by result",
+		},
+		[]string{"result"},
+	)
+
+	analysisDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "kubeguardian_analysis_duration_seconds",
+			Help:    "Time spent on root cause analysis of an issue",
+			Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60},
+		},
+	)
+
+	exportedDocumentsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_exported_documents_total",
+			Help: "Total number of documents exported to Elasticsearch/OpenSearch by index and result",
+		},
+		[]string{"index", "result"},
+	)
+
+	slackThrottledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_slack_throttled_total",
+			Help: "Total number of Slack messages delayed by channel and reason (paced or rate_limited)",
+		},
+		[]string{"channel", "reason"},
+	)
+
+	slackThrottleWaitSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeguardian_slack_throttle_wait_seconds_total",
+			Help: "Total time Slack messages were held back by channel and reason",
+		},
+		[]string{"channel", "reason"},
+	)
+
 	// System metrics
 	lastDetectionTime = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -102,6 +426,7 @@ var (
 // Metrics holds all metrics
 type Metrics struct {
 	startTime time.Time
+	labels    *labelLimiter // nil reports label values unchanged
 }
 
 // NewMetrics creates a new metrics instance
@@ -111,12 +436,53 @@ func NewMetrics() *Metrics {
 		metrics.Registry.MustRegister(
 			issuesDetectedTotal,
 			detectionDuration,
+			issuesExcludedTotal,
 			remediationTotal,
 			remediationDuration,
+			issueTimeToRemediation,
+			issueTimeToResolution,
+			issuesResolvedTotal,
+			issueTransitionsTotal,
+			issueSeverityEscalationsTotal,
+			conditionStates,
+			conditionStatesExpiredTotal,
+			stateStoreErrorsTotal,
+			featureEnabled,
+			ruleInfo,
+			ruleLastModified,
+			ruleDisabledAtRuntime,
+			ruleChangesTotal,
+			ruleDecisionsTotal,
+			incidentModeActive,
+			remediationPreChecksTotal,
+			rolloutWatchesTotal,
+			apiRequestTimeoutsTotal,
+			interventionRequired,
+			configDriftHashes,
+			informerCacheSynced,
+			informerCacheObjects,
+			informerProgressAge,
+			informerWatchLag,
+			clusterCapability,
+			batchSize,
+			remediationQueueDepth,
+			remediationQueueItemsTotal,
+			hygieneFindings,
 			cooldownActive,
 			apiCallsTotal,
 			apiDuration,
+			httpRequestsTotal,
+			httpRequestDuration,
 			notificationsTotal,
+			notificationsDroppedTotal,
+			notificationRetriesTotal,
+			notificationQueueDepth,
+			slackThrottledTotal,
+			slackThrottleWaitSeconds,
+			analysisTotal,
+			analysisDuration,
+			exportedDocumentsTotal,
+			labelOverflowTotal,
 			lastDetectionTime,
 			uptime,
 		)
@@ -127,25 +493,189 @@ func NewMetrics() *Metrics {
 	}
 }
 
+// SetLabelLimits bounds the cardinality of the namespace and rule labels
+func (m *Metrics) SetLabelLimits(limits LabelLimits) {
+	m.labels = newLabelLimiter(limits)
+}
+
 // RecordIssueDetected records a detected issue
 func (m *Metrics) RecordIssueDetected(rule, severity, namespace string) {
-	issuesDetectedTotal.WithLabelValues(rule, severity, namespace).Inc()
+	issuesDetectedTotal.WithLabelValues(m.labels.rule(rule), severity, m.labels.namespace(namespace)).Inc()
+}
+
+// RecordIssueExcluded records an issue of a workload excluded at a stage
+func (m *Metrics) RecordIssueExcluded(stage, rule string) {
+	issuesExcludedTotal.WithLabelValues(stage, m.labels.rule(rule)).Inc()
 }
 
 // RecordDetectionDuration records detection duration
-func (m *Metrics) RecordDetectionDuration(rule string, duration time.Duration) {
-	detectionDuration.WithLabelValues(rule).Observe(duration.Seconds())
+func (m *Metrics) RecordDetectionDuration(ctx context.Context, rule string, duration time.Duration) {
+	observe(ctx, detectionDuration.WithLabelValues(rule), duration.Seconds())
 }
 
 // RecordRemediation records a remediation action
-func (m *Metrics) RecordRemediation(action, result, namespace string, duration time.Duration) {
-	remediationTotal.WithLabelValues(action, result, namespace).Inc()
-	remediationDuration.WithLabelValues(action).Observe(duration.Seconds())
+func (m *Metrics) RecordRemediation(ctx context.Context, action, result, namespace string, duration time.Duration) {
+	remediationTotal.WithLabelValues(action, result, m.labels.namespace(namespace)).Inc()
+	observe(ctx, remediationDuration.WithLabelValues(action), duration.Seconds())
+}
+
+// RecordPreCheck records the outcome of a pre-check of a remediation action
+func (m *Metrics) RecordPreCheck(action, check, outcome string) {
+	remediationPreChecksTotal.WithLabelValues(action, check, outcome).Inc()
+}
+
+// RecordRolloutWatch records the final state of a rollout followed after an action
+func (m *Metrics) RecordRolloutWatch(action, outcome string) {
+	rolloutWatchesTotal.WithLabelValues(action, outcome).Inc()
+}
+
+// RecordAPITimeout records a Kubernetes API request that exceeded the timeout of
+// its budget
+func (m *Metrics) RecordAPITimeout(budget, resource string) {
+	apiRequestTimeoutsTotal.WithLabelValues(budget, resource).Inc()
+}
+
+// RecordIssueRemediated records the time from detection to the first successful remediation
+func (m *Metrics) RecordIssueRemediated(ctx context.Context, rule, namespace string, sinceDetection time.Duration) {
+	observe(ctx, issueTimeToRemediation.WithLabelValues(m.labels.rule(rule), m.labels.namespace(namespace)), sinceDetection.Seconds())
+}
+
+// RecordIssueResolved records a resolved issue and its time to resolution
+func (m *Metrics) RecordIssueResolved(ctx context.Context, rule, namespace, outcome string, sinceDetection time.Duration) {
+	rule, namespace = m.labels.rule(rule), m.labels.namespace(namespace)
+	issuesResolvedTotal.WithLabelValues(rule, namespace, outcome).Inc()
+	observe(ctx, issueTimeToResolution.WithLabelValues(rule, namespace, outcome), sinceDetection.Seconds())
+}
+
+// RecordIssueTransitions records the number of issues observed with a transition in a cycle
+func (m *Metrics) RecordIssueTransitions(transition string, count int) {
+	issueTransitionsTotal.WithLabelValues(transition).Add(float64(count))
+}
+
+// RecordIssueSeverityEscalated records an unresolved issue raised to a severity
+func (m *Metrics) RecordIssueSeverityEscalated(rule, severity, namespace string) {
+	issueSeverityEscalationsTotal.WithLabelValues(m.labels.rule(rule), severity, m.labels.namespace(namespace)).Inc()
+}
+
+// RecordConditionStates records the number of tracked conditions and how many expired
+func (m *Metrics) RecordConditionStates(tracked, expired int) {
+	conditionStates.Set(float64(tracked))
+	conditionStatesExpiredTotal.Add(float64(expired))
+}
+
+// RecordStateStoreError records a failed load or save of rule evaluation state
+func (m *Metrics) RecordStateStoreError(operation string) {
+	stateStoreErrorsTotal.WithLabelValues(operation).Inc()
+}
+
+// SetBatchSize records the current adaptive batch size of a kind
+func (m *Metrics) SetBatchSize(kind string, size int) {
+	batchSize.WithLabelValues(kind).Set(float64(size))
+}
+
+// RecordRemediationQueue records remediation queue items with an outcome and the
+// resulting queue depth
+func (m *Metrics) RecordRemediationQueue(outcome string, items, depth int) {
+	remediationQueueItemsTotal.WithLabelValues(outcome).Add(float64(items))
+	remediationQueueDepth.Set(float64(depth))
+}
+
+// RecordFeatureEnabled records the current value of a feature flag
+func (m *Metrics) RecordFeatureEnabled(feature string, enabled bool) {
+	value := 0.0
+	if enabled {
+		value = 1
+	}
+	featureEnabled.WithLabelValues(feature).Set(value)
+}
+
+// RecordRule records the owner, source and last modification time of a detection rule
+func (m *Metrics) RecordRule(rule, owner, source string, lastModified time.Time) {
+	ruleInfo.WithLabelValues(rule, owner, source).Set(1)
+	if !lastModified.IsZero() {
+		ruleLastModified.WithLabelValues(rule).Set(float64(lastModified.Unix()))
+	}
+}
+
+// RecordRuleDisabledAtRuntime records whether a detection rule is disabled at runtime
+func (m *Metrics) RecordRuleDisabledAtRuntime(rule string, disabled bool) {
+	value := 0.0
+	if disabled {
+		value = 1
+	}
+	ruleDisabledAtRuntime.WithLabelValues(rule).Set(value)
+}
+
+// RecordRuleChange records a detection rule enabled or disabled at runtime
+func (m *Metrics) RecordRuleChange(rule string, enabled bool) {
+	ruleChangesTotal.WithLabelValues(rule, strconv.FormatBool(enabled)).Inc()
+}
+
+// RecordRuleDecisions records the evaluations of a rule against resources with
+// the same outcome and deciding condition in a detection cycle
+func (m *Metrics) RecordRuleDecisions(rule, outcome, condition string, count int) {
+	ruleDecisionsTotal.WithLabelValues(m.labels.rule(rule), outcome, condition).Add(float64(count))
+}
+
+// RecordIncidentMode records the number of active incidents
+func (m *Metrics) RecordIncidentMode(active int) {
+	incidentModeActive.Set(float64(active))
+}
+
+// RecordInterventionRequired records the number of active issues requiring human intervention
+func (m *Metrics) RecordInterventionRequired(count int) {
+	interventionRequired.Set(float64(count))
+}
+
+// RecordConfigDrift records the number of distinct configuration hashes among the live instances
+func (m *Metrics) RecordConfigDrift(hashes int) {
+	configDriftHashes.Set(float64(hashes))
+}
+
+// RecordInformerCache records the state of the informer cache of a resource
+func (m *Metrics) RecordInformerCache(resource string, synced bool, objects int, sinceProgress time.Duration) {
+	value := 0.0
+	if synced {
+		value = 1
+	}
+	informerCacheSynced.WithLabelValues(resource).Set(value)
+	informerCacheObjects.WithLabelValues(resource).Set(float64(objects))
+	informerProgressAge.WithLabelValues(resource).Set(sinceProgress.Seconds())
+}
+
+// RecordWatchLag records how long after its last write a watch event of an object arrived
+func (m *Metrics) RecordWatchLag(resource string, lag time.Duration) {
+	informerWatchLag.WithLabelValues(resource).Observe(lag.Seconds())
+}
+
+// RecordClusterCapability records whether the cluster has a capability
+func (m *Metrics) RecordClusterCapability(capability string, available bool) {
+	value := 0.0
+	if available {
+		value = 1
+	}
+	clusterCapability.WithLabelValues(capability).Set(value)
 }
 
 // RecordCooldownActive records active cooldowns
 func (m *Metrics) RecordCooldownActive(namespace string, count int) {
-	cooldownActive.WithLabelValues(namespace).Set(float64(count))
+	cooldownActive.WithLabelValues(m.labels.namespace(namespace)).Set(float64(count))
+}
+
+// RecordHygieneFindings replaces the findings of the previous hygiene scan with
+// the counts of the latest one, keyed by namespace and check
+func (m *Metrics) RecordHygieneFindings(findings map[string]map[string]int) {
+	totals := make(map[[2]string]int)
+	for namespace, checks := range findings {
+		for check, count := range checks {
+			totals[[2]string{check, m.labels.namespace(namespace)}] += count
+		}
+	}
+
+	hygieneFindings.Reset()
+	for labels, count := range totals {
+		hygieneFindings.WithLabelValues(labels[0], labels[1]).Set(float64(count))
+	}
 }
 
 // RecordAPICall records an API call
@@ -154,11 +684,49 @@ func (m *Metrics) RecordAPICall(method, resource, status string, duration time.D
 	apiDuration.WithLabelValues(method, resource).Observe(duration.Seconds())
 }
 
+// RecordHTTPRequest records an HTTP request served by one of the servers of KubeGuardian
+func (m *Metrics) RecordHTTPRequest(ctx context.Context, server, route, method string, code int, duration time.Duration) {
+	httpRequestsTotal.WithLabelValues(server, route, method, strconv.Itoa(code)).Inc()
+	observe(ctx, httpRequestDuration.WithLabelValues(server, route), duration.Seconds())
+}
+
 // RecordNotification records a notification
 func (m *Metrics) RecordNotification(notificationType, status string) {
 	notificationsTotal.WithLabelValues(notificationType, status).Inc()
 }
 
+// RecordNotificationDropped records a notification that was not delivered
+func (m *Metrics) RecordNotificationDropped(notificationType, reason string) {
+	notificationsDroppedTotal.WithLabelValues(notificationType, reason).Inc()
+}
+
+// RecordNotificationRetry records a retried notification delivery
+func (m *Metrics) RecordNotificationRetry(notificationType string) {
+	notificationRetriesTotal.WithLabelValues(notificationType).Inc()
+}
+
+// RecordNotificationQueueDepth records the number of notifications waiting for delivery
+func (m *Metrics) RecordNotificationQueueDepth(depth int) {
+	notificationQueueDepth.Set(float64(depth))
+}
+
+// RecordSlackThrottled records a Slack message held back by pacing or a rate limit
+func (m *Metrics) RecordSlackThrottled(channel, reason string, wait time.Duration) {
+	slackThrottledTotal.WithLabelValues(channel, reason).Inc()
+	slackThrottleWaitSeconds.WithLabelValues(channel, reason).Add(wait.Seconds())
+}
+
+// RecordAnalysis records a root cause analysis
+func (m *Metrics) RecordAnalysis(result string, duration time.Duration) {
+	analysisTotal.WithLabelValues(result).Inc()
+	analysisDuration.Observe(duration.Seconds())
+}
+
+// RecordExportedDocuments records documents exported to Elasticsearch/OpenSearch
+func (m *Metrics) RecordExportedDocuments(index, result string, count int) {
+	exportedDocumentsTotal.WithLabelValues(index, result).Add(float64(count))
+}
+
 // UpdateLastDetectionTime updates the last detection timestamp
 func (m *Metrics) UpdateLastDetectionTime() {
 	lastDetectionTime.SetToCurrentTime()
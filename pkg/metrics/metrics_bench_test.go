@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -19,7 +20,7 @@ func BenchmarkRecordRemediation(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		m.RecordRemediation("restart-pod", "success", "test-namespace", time.Millisecond)
+		m.RecordRemediation(context.Background(), "restart-pod", "success", "test-namespace", time.Millisecond)
 	}
 }
 
@@ -39,7 +40,7 @@ func BenchmarkConcurrentMetrics(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			m.RecordIssueDetected("test-rule", "medium", "test-namespace")
-			m.RecordRemediation("test-action", "success", "test-namespace", time.Millisecond)
+			m.RecordRemediation(context.Background(), "test-action", "success", "test-namespace", time.Millisecond)
 			m.RecordAPICall("GET", "test", "success", time.Millisecond)
 		}
 	})
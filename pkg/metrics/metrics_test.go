@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -24,6 +25,7 @@ func TestRecordIssueDetected(t *testing.T) {
 
 	// Record an issue
 	m.RecordIssueDetected("crashloop", "high", "default")
+	m.RecordIssueExcluded("detection", "crashloop")
 
 	// This test mainly ensures no panic occurs
 	// In a real scenario, you'd need to collect metrics and verify values
@@ -33,7 +35,26 @@ func TestRecordRemediation(t *testing.T) {
 	m := NewMetrics()
 
 	// Record a remediation
-	m.RecordRemediation("restart-pod", "success", "default", time.Second)
+	m.RecordRemediation(context.Background(), "restart-pod", "success", "default", time.Second)
+
+	// Test panic-free execution
+}
+
+func TestRecordIssueLifecycle(t *testing.T) {
+	m := NewMetrics()
+
+	// Record issue lifecycle events
+	m.RecordIssueRemediated(context.Background(), "crashloop", "default", 2*time.Minute)
+	m.RecordIssueResolved(context.Background(), "crashloop", "default", "auto_resolved", 5*time.Minute)
+	m.RecordIssueResolved(context.Background(), "failed-deployment", "default", "escalated", time.Hour)
+	m.RecordIssueTransitions("new", 2)
+	m.RecordConditionStates(3, 1)
+	m.RecordStateStoreError("save")
+	m.RecordFeatureEnabled("aiAnalysis", true)
+	m.RecordClusterCapability("ephemeral-containers", false)
+	m.RecordRule("crashloop", "sre", "builtin", time.Now())
+	m.RecordRuleDisabledAtRuntime("crashloop", true)
+	m.RecordRuleChange("crashloop", false)
 
 	// Test panic-free execution
 }
@@ -54,6 +75,12 @@ func TestRecordNotification(t *testing.T) {
 	// Record notifications
 	m.RecordNotification("issue", "success")
 	m.RecordNotification("remediation", "failed")
+	m.RecordNotificationDropped("issue", "queue_full")
+	m.RecordNotificationRetry("issue")
+	m.RecordNotificationQueueDepth(3)
+	m.RecordSlackThrottled("#alerts", "rate_limited", 30*time.Second)
+	m.RecordAnalysis("success", 3*time.Second)
+	m.RecordExportedDocuments("issues", "indexed", 2)
 
 	// Test panic-free execution
 }
@@ -80,7 +107,7 @@ func TestRecordDetectionDuration(t *testing.T) {
 	m := NewMetrics()
 
 	// Record detection duration
-	m.RecordDetectionDuration("detection_cycle", 500*time.Millisecond)
+	m.RecordDetectionDuration(context.Background(), "detection_cycle", 500*time.Millisecond)
 
 	// Test panic-free execution
 }
@@ -95,7 +122,7 @@ func TestMetricsConcurrency(t *testing.T) {
 		go func(id int) {
 			for j := 0; j < 100; j++ {
 				m.RecordIssueDetected("test-rule", "medium", "test-namespace")
-				m.RecordRemediation("test-action", "success", "test-namespace", time.Millisecond)
+				m.RecordRemediation(context.Background(), "test-action", "success", "test-namespace", time.Millisecond)
 				m.RecordAPICall("GET", "test", "success", time.Millisecond)
 			}
 			done <- true
@@ -109,3 +136,41 @@ func TestMetricsConcurrency(t *testing.T) {
 
 	// If we reach here, no race conditions occurred
 }
+
+func TestLabelLimits(t *testing.T) {
+	limiter := newLabelLimiter(LabelLimits{
+		NamespaceBuckets: []NamespaceBucket{{Name: "tenants", Patterns: []string{"tenant-*"}}},
+		MaxNamespaces:    2,
+		MaxRules:         1,
+	})
+
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"tenant-a", "tenants"},
+		{"default", "default"},
+		{"tenant-b", "tenants"},
+		{"kube-system", "kube-system"},
+		{"payments", OverflowLabel},
+		{"default", "default"},
+	}
+	for _, tt := range tests {
+		if got := limiter.namespace(tt.namespace); got != tt.want {
+			t.Errorf("namespace(%q) = %q, want %q", tt.namespace, got, tt.want)
+		}
+	}
+
+	if got := limiter.rule("crash-loop-backoff"); got != "crash-loop-backoff" {
+		t.Errorf("rule() = %q, want crash-loop-backoff", got)
+	}
+	if got := limiter.rule("pending-pod"); got != OverflowLabel {
+		t.Errorf("rule() beyond the limit = %q, want %q", got, OverflowLabel)
+	}
+
+	// Without limits label values are unchanged
+	var unlimited *labelLimiter
+	if got := unlimited.namespace("payments"); got != "payments" {
+		t.Errorf("namespace() without limits = %q", got)
+	}
+}
@@ -0,0 +1,195 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// maxBatchNamespaces limits the namespaces listed per rule in an aggregated message
+const maxBatchNamespaces = 3
+
+// BatchConfig controls aggregation of issue notifications during bursts
+type BatchConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Window is how long issue notifications are collected before they are sent
+	Window time.Duration `yaml:"window"`
+	// MinIssues is the number of issues in a window from which a single aggregated
+	// message is sent per channel instead of one message per issue
+	MinIssues int `yaml:"minIssues"`
+	// DashboardURL is linked from aggregated messages
+	DashboardURL string `yaml:"dashboardURL"`
+}
+
+// batcher collects issue notifications per channel and flushes them once per window
+type batcher struct {
+	config BatchConfig
+
+	mu      sync.Mutex
+	pending map[string][]detection.Issue // Key: channel
+}
+
+func newBatcher(config BatchConfig) *batcher {
+	return &batcher{
+		config:  config,
+		pending: make(map[string][]detection.Issue),
+	}
+}
+
+// Add collects an issue for the next flush
+func (b *batcher) Add(channel string, issue detection.Issue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending[channel] = append(b.pending[channel], issue)
+}
+
+// Take returns and clears the collected issues
+func (b *batcher) Take() map[string][]detection.Issue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pending := b.pending
+	b.pending = make(map[string][]detection.Issue)
+	return pending
+}
+
+// runBatches flushes collected issue notifications once per window until the
+// context is cancelled, then flushes the remaining ones
+func (s *SlackNotifier) runBatches(ctx context.Context) {
+	ticker := time.NewTicker(s.batch.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			s.flushBatches(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.flushBatches(ctx)
+		}
+	}
+}
+
+// flushBatches sends the collected issues, aggregated per channel if there are
+// at least MinIssues of them
+func (s *SlackNotifier) flushBatches(ctx context.Context) {
+	for channel, issues := range s.batch.Take() {
+		if len(issues) < s.batch.config.MinIssues {
+			for _, issue := range issues {
+				s.sendIssue(ctx, s.route(ctx, issueWorkload(issue), issue.Owner), issue)
+			}
+			continue
+		}
+
+		msg := s.batchMessage(channel, issues)
+		if err := s.deliver(ctx, msg); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to send aggregated Slack notification", "channel", channel, "issues", len(issues))
+		}
+	}
+}
+
+// batchMessage builds a single message summarizing issues by rule
+func (s *SlackNotifier) batchMessage(channel string, issues []detection.Issue) Message {
+	type ruleSummary struct {
+		name       string
+		count      int
+		namespaces []string
+	}
+
+	summaries := make(map[string]*ruleSummary)
+	severity := ""
+	for _, issue := range issues {
+		summary, exists := summaries[issue.RuleName]
+		if !exists {
+			summary = &ruleSummary{name: issue.RuleName}
+			summaries[issue.RuleName] = summary
+		}
+		summary.count++
+		if !containsString(summary.namespaces, issue.Namespace) {
+			summary.namespaces = append(summary.namespaces, issue.Namespace)
+		}
+		if config.SeverityRank(issue.Severity) > config.SeverityRank(severity) {
+			severity = issue.Severity
+		}
+	}
+
+	ordered := make([]*ruleSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		ordered = append(ordered, summary)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].count != ordered[j].count {
+			return ordered[i].count > ordered[j].count
+		}
+		return ordered[i].name < ordered[j].name
+	})
+
+	lines := make([]string, 0, len(ordered))
+	for _, summary := range ordered {
+		sort.Strings(summary.namespaces)
+		namespaces := summary.namespaces
+		more := ""
+		if len(namespaces) > maxBatchNamespaces {
+			more = fmt.Sprintf(" +%d more", len(namespaces)-maxBatchNamespaces)
+			namespaces = namespaces[:maxBatchNamespaces]
+		}
+		lines = append(lines, fmt.Sprintf("• *%s*: %d (%s%s)", summary.name, summary.count, strings.Join(namespaces, ", "), more))
+	}
+
+	attachment := slack.Attachment{
+		Color: s.getColorBySeverity(severity),
+		Title: fmt.Sprintf("🚨 KubeGuardian: %d issues detected in the last %s", len(issues), s.batch.config.Window),
+		Text:  strings.Join(lines, "\n"),
+		Fields: []slack.AttachmentField{
+			{
+				Title: "Highest Severity",
+				Value: strings.ToUpper(severity),
+				Short: true,
+			},
+			{
+				Title: "Rules",
+				Value: fmt.Sprintf("%d", len(ordered)),
+				Short: true,
+			},
+		},
+		Footer:     "KubeGuardian",
+		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+		Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+	}
+	if s.batch.config.DashboardURL != "" {
+		attachment.TitleLink = s.batch.config.DashboardURL
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Dashboard",
+			Value: s.batch.config.DashboardURL,
+			Short: false,
+		})
+	}
+
+	return Message{
+		Type:       "issue_batch",
+		Channel:    channel,
+		Resource:   fmt.Sprintf("%d issues", len(issues)),
+		Text:       "Multiple issues detected in Kubernetes cluster",
+		Attachment: attachment,
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+func newBatchingNotifier(minIssues int) *SlackNotifier {
+	return &SlackNotifier{
+		config: SlackConfig{Enabled: true, Channel: "#alerts"},
+		queue:  newQueue(QueueConfig{Size: 100}, nil, nil),
+		batch: newBatcher(BatchConfig{
+			Enabled:      true,
+			Window:       30 * time.Second,
+			MinIssues:    minIssues,
+			DashboardURL: "https://grafana.example.com/d/kubeguardian",
+		}),
+	}
+}
+
+func queued(s *SlackNotifier) []Message {
+	var messages []Message
+	for len(s.queue.messages) > 0 {
+		messages = append(messages, <-s.queue.messages)
+	}
+	return messages
+}
+
+func TestBatchAggregatesBursts(t *testing.T) {
+	s := newBatchingNotifier(3)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Severity: "high", Namespace: fmt.Sprintf("team-%d", i), Kind: "Pod", Name: "web"})
+	}
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "oom-killed", Severity: "critical", Namespace: "team-0", Kind: "Pod", Name: "db"})
+
+	if messages := queued(s); len(messages) != 0 {
+		t.Fatalf("issues should be held until the window is flushed, got %d messages", len(messages))
+	}
+
+	s.flushBatches(ctx)
+	messages := queued(s)
+	if len(messages) != 1 {
+		t.Fatalf("expected one aggregated message, got %d", len(messages))
+	}
+
+	msg := messages[0]
+	if msg.Type != "issue_batch" || msg.Channel != "#alerts" {
+		t.Errorf("unexpected message type %q or channel %q", msg.Type, msg.Channel)
+	}
+	if !strings.Contains(msg.Attachment.Title, "5 issues") {
+		t.Errorf("title should count all issues: %q", msg.Attachment.Title)
+	}
+	lines := strings.Split(msg.Attachment.Text, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "• *crashloop*: 4 (team-0, team-1, team-2 +1 more)") {
+		t.Errorf("unexpected per-rule summary: %q", msg.Attachment.Text)
+	}
+	if msg.Attachment.Color != "danger" {
+		t.Errorf("color should follow the highest severity, got %q", msg.Attachment.Color)
+	}
+	if msg.Attachment.TitleLink == "" {
+		t.Error("aggregated message should link to the dashboard")
+	}
+}
+
+func TestBatchSendsSmallWindowsIndividually(t *testing.T) {
+	s := newBatchingNotifier(3)
+	ctx := context.Background()
+
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Namespace: "default", Kind: "Pod", Name: "web"})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Namespace: "default", Kind: "Pod", Name: "api"})
+	s.flushBatches(ctx)
+
+	messages := queued(s)
+	if len(messages) != 2 {
+		t.Fatalf("expected two individual messages, got %d", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.Type != "issue" {
+			t.Errorf("message type = %q, want issue", msg.Type)
+		}
+	}
+
+	s.flushBatches(ctx)
+	if messages := queued(s); len(messages) != 0 {
+		t.Errorf("flushed issues should not be sent again, got %d messages", len(messages))
+	}
+}
@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"sync"
+	"time"
+)
+
+// Deduplicator suppresses repeated notifications for the same unresolved issue,
+// identified by its fingerprint, until the repeat interval has elapsed
+type Deduplicator struct {
+	mu             sync.Mutex
+	repeatInterval time.Duration
+	lastNotified   map[string]time.Time // Key: issue fingerprint
+}
+
+// NewDeduplicator creates a new deduplicator. A zero repeat interval disables deduplication.
+func NewDeduplicator(repeatInterval time.Duration) *Deduplicator {
+	return &Deduplicator{
+		repeatInterval: repeatInterval,
+		lastNotified:   make(map[string]time.Time),
+	}
+}
+
+// ShouldNotify returns true if a notification for the fingerprint should be sent now,
+// and records the notification time if so
+func (d *Deduplicator) ShouldNotify(fingerprint string, now time.Time) bool {
+	if d == nil || d.repeatInterval <= 0 {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, exists := d.lastNotified[fingerprint]; exists && now.Sub(last) < d.repeatInterval {
+		return false
+	}
+
+	d.lastNotified[fingerprint] = now
+	return true
+}
+
+// Forget removes a fingerprint so the next occurrence is notified immediately,
+// e.g. when the notification could not be delivered
+func (d *Deduplicator) Forget(fingerprint string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.lastNotified, fingerprint)
+}
+
+// Prune forgets all fingerprints that are no longer active, so an issue that
+// resolves and later recurs is notified again
+func (d *Deduplicator) Prune(active map[string]bool) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for fingerprint := range d.lastNotified {
+		if !active[fingerprint] {
+			delete(d.lastNotified, fingerprint)
+		}
+	}
+}
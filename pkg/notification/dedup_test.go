@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduplicatorRepeatInterval(t *testing.T) {
+	d := NewDeduplicator(time.Hour)
+	now := time.Now()
+
+	if !d.ShouldNotify("abc", now) {
+		t.Error("first occurrence should be notified")
+	}
+	if d.ShouldNotify("abc", now.Add(30*time.Minute)) {
+		t.Error("repeat within interval should be suppressed")
+	}
+	if !d.ShouldNotify("def", now.Add(30*time.Minute)) {
+		t.Error("different fingerprint should be notified")
+	}
+	if !d.ShouldNotify("abc", now.Add(time.Hour)) {
+		t.Error("repeat after interval should be notified")
+	}
+}
+
+func TestDeduplicatorPruneAndForget(t *testing.T) {
+	d := NewDeduplicator(time.Hour)
+	now := time.Now()
+
+	d.ShouldNotify("resolved", now)
+	d.ShouldNotify("active", now)
+	d.Prune(map[string]bool{"active": true})
+
+	if !d.ShouldNotify("resolved", now) {
+		t.Error("recurring issue should be notified after it was pruned")
+	}
+	if d.ShouldNotify("active", now) {
+		t.Error("active issue should still be suppressed")
+	}
+
+	d.Forget("active")
+	if !d.ShouldNotify("active", now) {
+		t.Error("forgotten fingerprint should be notified")
+	}
+}
+
+func TestDeduplicatorDisabled(t *testing.T) {
+	d := NewDeduplicator(0)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if !d.ShouldNotify("abc", now) {
+			t.Error("zero repeat interval should never suppress notifications")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"sync"
+	"time"
+)
+
+// pacer spaces out messages to the same channel and holds channels that Slack
+// rate limited until their Retry-After has passed
+type pacer struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next map[string]time.Time // Key: channel, earliest time of the next message
+}
+
+func newPacer(interval time.Duration) *pacer {
+	return &pacer{
+		interval: interval,
+		now:      time.Now,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Reserve reserves the next slot of a channel and returns how long to wait for it
+func (p *pacer) Reserve(channel string) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	slot := p.next[channel]
+	if slot.Before(now) {
+		slot = now
+	}
+	p.next[channel] = slot.Add(p.interval)
+	return slot.Sub(now)
+}
+
+// Throttle holds a channel for retryAfter, as requested by a rate-limited response
+func (p *pacer) Throttle(channel string, retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	until := p.now().Add(retryAfter)
+	if until.After(p.next[channel]) {
+		p.next[channel] = until
+	}
+}
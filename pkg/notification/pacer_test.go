@@ -0,0 +1,32 @@
+package notification
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPacer(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := newPacer(time.Second)
+	p.now = func() time.Time { return now }
+
+	if wait := p.Reserve("#alerts"); wait != 0 {
+		t.Errorf("first message should not wait, got %v", wait)
+	}
+	if wait := p.Reserve("#alerts"); wait != time.Second {
+		t.Errorf("second message should wait for the interval, got %v", wait)
+	}
+	if wait := p.Reserve("#other"); wait != 0 {
+		t.Errorf("channels should be paced independently, got %v", wait)
+	}
+
+	p.Throttle("#other", 30*time.Second)
+	if wait := p.Reserve("#other"); wait != 30*time.Second {
+		t.Errorf("rate-limited channel should wait for Retry-After, got %v", wait)
+	}
+
+	now = now.Add(time.Minute)
+	if wait := p.Reserve("#alerts"); wait != 0 {
+		t.Errorf("idle channel should not wait, got %v", wait)
+	}
+}
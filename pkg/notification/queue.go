@@ -0,0 +1,188 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/slack-go/slack"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+)
+
+// Reasons a notification is dropped
+const (
+	DropReasonQueueFull        = "queue_full"
+	DropReasonRetriesExhausted = "retries_exhausted"
+	DropReasonPermanentError   = "permanent_error"
+	DropReasonShutdown         = "shutdown"
+)
+
+// ErrQueueFull is returned when a notification cannot be buffered
+var ErrQueueFull = errors.New("notification queue is full")
+
+// Message is a notification waiting to be delivered
+type Message struct {
+	// Type is issue, issue_batch, remediation, resolved, startup or shutdown
+	Type string
+	// Channel overrides the configured channel
+	Channel    string
+	Resource   string
+	Text       string
+	Attachment slack.Attachment
+}
+
+// QueueConfig controls asynchronous delivery of notifications
+type QueueConfig struct {
+	// Size is the number of buffered notifications; 0 sends synchronously
+	Size int `yaml:"size"`
+	// MaxRetries is the number of retries after a failed delivery
+	MaxRetries int `yaml:"maxRetries"`
+	// InitialBackoff is doubled after each failed attempt up to MaxBackoff
+	InitialBackoff time.Duration `yaml:"initialBackoff"`
+	MaxBackoff     time.Duration `yaml:"maxBackoff"`
+}
+
+// queue buffers notifications and delivers them in order with retries, so
+// notifications survive transient Slack outages up to the buffer size
+type queue struct {
+	config   QueueConfig
+	messages chan Message
+	send     func(ctx context.Context, msg Message) error
+	metrics  *metrics.Metrics
+}
+
+// newQueue creates a queue delivering messages with send
+func newQueue(config QueueConfig, send func(ctx context.Context, msg Message) error, metricsCollector *metrics.Metrics) *queue {
+	return &queue{
+		config:   config,
+		messages: make(chan Message, config.Size),
+		send:     send,
+		metrics:  metricsCollector,
+	}
+}
+
+// Enqueue buffers a message without blocking; the message is dropped if the queue is full
+func (q *queue) Enqueue(ctx context.Context, msg Message) error {
+	select {
+	case q.messages <- msg:
+		q.recordDepth()
+		return nil
+	default:
+		q.deadLetter(ctx, msg, DropReasonQueueFull, 0, ErrQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// Run delivers queued messages until the context is cancelled. Messages still
+// queued on shutdown are dead-lettered.
+func (q *queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			q.drain(ctx)
+			return
+		case msg := <-q.messages:
+			q.recordDepth()
+			q.deliver(ctx, msg)
+		}
+	}
+}
+
+// deliver sends a message, retrying transient failures with exponential backoff.
+// Rate-limited attempts wait for Slack's Retry-After and do not count as failures.
+func (q *queue) deliver(ctx context.Context, msg Message) {
+	logger := log.FromContext(ctx)
+
+	attempt := 0
+	for {
+		err := q.send(ctx, msg)
+		if err == nil {
+			return
+		}
+
+		var delay time.Duration
+		var rateLimited *slack.RateLimitedError
+		switch {
+		case errors.As(err, &rateLimited):
+			delay = rateLimited.RetryAfter
+		case !retryable(err):
+			q.deadLetter(ctx, msg, DropReasonPermanentError, attempt+1, err)
+			return
+		case attempt >= q.config.MaxRetries:
+			q.deadLetter(ctx, msg, DropReasonRetriesExhausted, attempt+1, err)
+			return
+		default:
+			attempt++
+			delay = q.backoff(attempt)
+		}
+
+		if q.metrics != nil {
+			q.metrics.RecordNotificationRetry(msg.Type)
+		}
+		logger.Info("Slack notification failed, retrying", "type", msg.Type, "resource", msg.Resource, "attempt", attempt, "retryIn", delay, "error", err.Error())
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			q.deadLetter(ctx, msg, DropReasonShutdown, attempt, err)
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt
+func (q *queue) backoff(attempt int) time.Duration {
+	delay := q.config.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if q.config.MaxBackoff > 0 && delay >= q.config.MaxBackoff {
+			return q.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// drain dead-letters all buffered messages
+func (q *queue) drain(ctx context.Context) {
+	for {
+		select {
+		case msg := <-q.messages:
+			q.deadLetter(ctx, msg, DropReasonShutdown, 0, ctx.Err())
+		default:
+			q.recordDepth()
+			return
+		}
+	}
+}
+
+// deadLetter logs the full content of a dropped message so it can be recovered from the logs
+func (q *queue) deadLetter(ctx context.Context, msg Message, reason string, attempts int, err error) {
+	if q.metrics != nil {
+		q.metrics.RecordNotificationDropped(msg.Type, reason)
+	}
+	log.FromContext(ctx).Error(err, "Dropping Slack notification",
+		"type", msg.Type,
+		"resource", msg.Resource,
+		"reason", reason,
+		"attempts", attempts,
+		"text", msg.Text,
+		"title", msg.Attachment.Title,
+		"body", msg.Attachment.Text)
+}
+
+func (q *queue) recordDepth() {
+	if q.metrics != nil {
+		q.metrics.RecordNotificationQueueDepth(len(q.messages))
+	}
+}
+
+// retryable returns false for errors reported by the Slack API, such as an
+// unknown channel or invalid token, which fail again on retry
+func retryable(err error) bool {
+	var apiErr slack.SlackErrorResponse
+	return !errors.As(err, &apiErr)
+}
@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestQueueRetriesTransientErrors(t *testing.T) {
+	config := QueueConfig{Size: 10, MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	attempts := 0
+	delivered := make(chan Message, 1)
+	q := newQueue(config, func(ctx context.Context, msg Message) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		delivered <- msg
+		return nil
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	if err := q.Enqueue(ctx, Message{Type: "issue"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("message was not delivered after transient errors")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestQueueDropsMessages(t *testing.T) {
+	config := QueueConfig{Size: 1, MaxRetries: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	// Slack API errors are not retried
+	attempts := 0
+	q := newQueue(config, func(ctx context.Context, msg Message) error {
+		attempts++
+		return slack.SlackErrorResponse{Err: "channel_not_found"}
+	}, nil)
+	q.deliver(context.Background(), Message{Type: "issue"})
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 for a permanent error", attempts)
+	}
+
+	// The queue does not block when full
+	if err := q.Enqueue(context.Background(), Message{Type: "issue"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := q.Enqueue(context.Background(), Message{Type: "issue"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue() on a full queue error = %v, want ErrQueueFull", err)
+	}
+
+	// Buffered messages are drained on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Run(ctx)
+	if len(q.messages) != 0 {
+		t.Errorf("queue still holds %d messages after shutdown", len(q.messages))
+	}
+}
+
+func TestQueueBackoff(t *testing.T) {
+	q := newQueue(QueueConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}, nil, nil)
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := q.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestQueueWaitsForRateLimits(t *testing.T) {
+	config := QueueConfig{Size: 1, MaxRetries: 0, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	// Rate-limited attempts do not exhaust the retries
+	attempts := 0
+	q := newQueue(config, func(ctx context.Context, msg Message) error {
+		attempts++
+		if attempts < 3 {
+			return &slack.RateLimitedError{RetryAfter: time.Millisecond}
+		}
+		return nil
+	}, nil)
+	q.deliver(context.Background(), Message{Type: "issue"})
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -3,30 +3,57 @@ package notification
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/slack-go/slack"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/NotHarshhaa/kubeguardian/pkg/analysis"
+	"github.com/NotHarshhaa/kubeguardian/pkg/catalog"
 	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
 	"github.com/NotHarshhaa/kubeguardian/pkg/remediation"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
 )
 
 // SlackNotifier handles Slack notifications
 type SlackNotifier struct {
-	client *slack.Client
+	mu     sync.RWMutex
+	client *slack.Client // Replaced when the token is rotated
 	config SlackConfig
+	queue  *queue   // nil sends synchronously
+	batch  *batcher // nil sends issue notifications immediately
+	pacer  *pacer
 }
 
 // SlackConfig contains Slack configuration
 type SlackConfig struct {
-	Enabled   bool   `yaml:"enabled"`
-	Token     string `yaml:"token"`
-	Channel   string `yaml:"channel"`
-	Username  string `yaml:"username"`
-	IconEmoji string `yaml:"iconEmoji"`
+	Enabled   bool        `yaml:"enabled"`
+	Token     string      `yaml:"token"`
+	Channel   string      `yaml:"channel"`
+	Username  string      `yaml:"username"`
+	IconEmoji string      `yaml:"iconEmoji"`
+	Queue     QueueConfig `yaml:"queue"`
+	Batch     BatchConfig `yaml:"batch"`
+	// MinInterval is the minimum time between messages to the same channel
+	MinInterval time.Duration `yaml:"minInterval"`
+	// TeamChannels routes notifications about resources owned by a team to its channel
+	TeamChannels map[string]string `yaml:"teamChannels"`
+	// SeverityChannels routes issue notifications of a severity to a channel,
+	// taking precedence over team and catalog channels
+	SeverityChannels map[string]string `yaml:"severityChannels"`
+	// Catalog resolves workloads to their owning team, channel and escalation
+	// policy; entries found in the catalog take precedence over owner labels
+	Catalog catalog.Resolver `yaml:"-"`
+	// Metrics records queue depth, retries and dropped notifications
+	Metrics *metrics.Metrics `yaml:"-"`
 }
 
 // NewSlackNotifier creates a new Slack notifier
@@ -36,18 +63,292 @@ func NewSlackNotifier(config SlackConfig) *SlackNotifier {
 	}
 
 	client := slack.New(config.Token)
-	return &SlackNotifier{
+	notifier := &SlackNotifier{
 		client: client,
 		config: config,
+		pacer:  newPacer(config.MinInterval),
+	}
+	if config.Queue.Size > 0 {
+		notifier.queue = newQueue(config.Queue, notifier.post, config.Metrics)
+	}
+	if config.Batch.Enabled && config.Batch.Window > 0 {
+		notifier.batch = newBatcher(config.Batch)
+	}
+	return notifier
+}
+
+// SetToken replaces the token messages are sent with, e.g. after it was rotated
+// in the secret store it is read from
+func (s *SlackNotifier) SetToken(token string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = slack.New(token)
+}
+
+// slackClient returns the client of the current token
+func (s *SlackNotifier) slackClient() *slack.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// Run delivers queued and batched notifications until the context is cancelled.
+// It returns immediately if notifications are sent synchronously.
+func (s *SlackNotifier) Run(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	queueCtx := ctx
+	if s.batch != nil {
+		// Stop the queue only after the final batches were flushed into it
+		var cancel context.CancelFunc
+		queueCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			s.runBatches(ctx)
+		}()
+	}
+	if s.queue != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.queue.Run(queueCtx)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends a message, through the queue if one is configured
+func (s *SlackNotifier) deliver(ctx context.Context, msg Message) error {
+	if s.queue != nil {
+		return s.queue.Enqueue(ctx, msg)
+	}
+	return s.post(ctx, msg)
+}
+
+// post sends a message to its channel, or the configured channel if none is set
+func (s *SlackNotifier) post(ctx context.Context, msg Message) error {
+	channel := msg.Channel
+	if channel == "" {
+		channel = s.config.Channel
+	}
+
+	if err := s.pace(ctx, channel); err != nil {
+		return err
+	}
+
+	_, _, err := s.slackClient().PostMessageContext(
+		ctx,
+		channel,
+		slack.MsgOptionText(msg.Text, false),
+		slack.MsgOptionAttachments(msg.Attachment),
+		slack.MsgOptionAsUser(true),
+	)
+	if err != nil {
+		var rateLimited *slack.RateLimitedError
+		if errors.As(err, &rateLimited) {
+			// Hold all messages to the channel, not only this one, until Slack accepts them again
+			s.pacer.Throttle(channel, rateLimited.RetryAfter)
+			if s.config.Metrics != nil {
+				s.config.Metrics.RecordSlackThrottled(channel, "rate_limited", rateLimited.RetryAfter)
+			}
+			log.FromContext(ctx).Info("Slack rate limited notifications", "channel", channel, "retryAfter", rateLimited.RetryAfter)
+		}
+		return err
+	}
+
+	log.FromContext(ctx).Info("Successfully sent Slack notification", "type", msg.Type, "resource", msg.Resource)
+	return nil
+}
+
+// pace waits until a message may be sent to the channel
+func (s *SlackNotifier) pace(ctx context.Context, channel string) error {
+	if s.pacer == nil {
+		return nil
+	}
+
+	wait := s.pacer.Reserve(channel)
+	if wait <= 0 {
+		return nil
+	}
+	if s.config.Metrics != nil {
+		s.config.Metrics.RecordSlackThrottled(channel, "paced", wait)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
 
-// SendIssueNotification sends a notification about a detected issue
+// SendIssueNotification sends a notification about a detected issue. With batching
+// enabled the issue is collected and sent when the current window is flushed.
 func (s *SlackNotifier) SendIssueNotification(ctx context.Context, issue detection.Issue) error {
 	if s == nil || !s.config.Enabled {
 		return nil
 	}
 
+	r := s.route(ctx, issueWorkload(issue), issue.Owner)
+	issue.Owner = r.Owner
+	if channel, exists := s.config.SeverityChannels[strings.ToLower(issue.Severity)]; exists {
+		r.Channel = channel
+	}
+	if s.batch != nil {
+		s.batch.Add(r.Channel, issue)
+		return nil
+	}
+	return s.sendIssue(ctx, r, issue)
+}
+
+// routing is where a notification about a workload is sent and who is responsible for it
+type routing struct {
+	Channel          string
+	Owner            detection.Owner
+	EscalationPolicy string
+}
+
+// route resolves the owner of a workload from the catalog, falling back to the
+// owner read from its labels, and returns the channel of the owning team
+func (s *SlackNotifier) route(ctx context.Context, workload catalog.Workload, owner detection.Owner) routing {
+	r := routing{Owner: owner}
+	if s.config.Catalog != nil {
+		entry, found, err := s.config.Catalog.Resolve(ctx, workload)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to resolve owner from service catalog", "namespace", workload.Namespace, "kind", workload.Kind, "name", workload.Name)
+		} else if found {
+			if entry.Team != "" {
+				r.Owner.Team = entry.Team
+			}
+			r.EscalationPolicy = entry.EscalationPolicy
+			r.Channel = entry.Channel
+		}
+	}
+	if r.Channel == "" {
+		r.Channel = s.channelFor(r.Owner.Team)
+	}
+	return r
+}
+
+// channelFor returns the channel of a team, or the configured channel if the
+// team has none
+func (s *SlackNotifier) channelFor(team string) string {
+	if channel, exists := s.config.TeamChannels[team]; exists && team != "" {
+		return channel
+	}
+	return s.config.Channel
+}
+
+// issueWorkload identifies the affected resource of an issue for catalog lookups
+func issueWorkload(issue detection.Issue) catalog.Workload {
+	workload := catalog.Workload{Namespace: issue.Namespace, Kind: issue.Kind, Name: issue.Name}
+	if issue.Resource != nil {
+		if obj, err := meta.Accessor(issue.Resource); err == nil {
+			workload.Labels = obj.GetLabels()
+		}
+	}
+	return workload
+}
+
+// actionsValue lists the actions of an issue; issues without actions are only notified
+func actionsValue(actions []string) string {
+	if len(actions) == 0 {
+		return "none (notify only)"
+	}
+	return strings.Join(actions, ", ")
+}
+
+// diagnosisField lists the matched failure signatures with their runbooks
+func diagnosisField(diagnoses []analysis.Diagnosis) slack.AttachmentField {
+	lines := make([]string, 0, len(diagnoses))
+	for _, diagnosis := range diagnoses {
+		line := "• " + diagnosis.Cause
+		if diagnosis.Runbook != "" {
+			line += fmt.Sprintf(" (<%s|runbook>)", diagnosis.Runbook)
+		}
+		lines = append(lines, line)
+	}
+	return slack.AttachmentField{
+		Title: "Diagnosis",
+		Value: strings.Join(lines, "\n"),
+		Short: false,
+	}
+}
+
+// analysisFields describes an AI-generated root-cause hypothesis
+func analysisFields(result *analysis.Result) []slack.AttachmentField {
+	fields := []slack.AttachmentField{
+		{
+			Title: "Likely Root Cause (AI-generated)",
+			Value: result.Hypothesis,
+			Short: false,
+		},
+	}
+	if len(result.NextSteps) > 0 {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Suggested Next Steps",
+			Value: "• " + strings.Join(result.NextSteps, "\n• "),
+			Short: false,
+		})
+	}
+	return fields
+}
+
+// ruleFields describes whose rule fired and when it last changed, if known
+func ruleFields(metadata detection.RuleMetadata) []slack.AttachmentField {
+	if metadata.Owner == "" {
+		return nil
+	}
+	value := fmt.Sprintf("%s (%s)", metadata.Owner, metadata.Source)
+	if !metadata.LastModified.IsZero() {
+		value = fmt.Sprintf("%s (%s, changed %s)", metadata.Owner, metadata.Source, metadata.LastModified.UTC().Format("2006-01-02"))
+	}
+	return []slack.AttachmentField{{
+		Title: "Rule Owner",
+		Value: value,
+		Short: true,
+	}}
+}
+
+// ownerFields describes the owner of an affected resource, if known
+func ownerFields(r routing) []slack.AttachmentField {
+	var fields []slack.AttachmentField
+	if r.Owner.Team != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Team",
+			Value: r.Owner.Team,
+			Short: true,
+		})
+	}
+	if r.Owner.Contact != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Owner",
+			Value: r.Owner.Contact,
+			Short: true,
+		})
+	}
+	if r.EscalationPolicy != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Escalation Policy",
+			Value: r.EscalationPolicy,
+			Short: true,
+		})
+	}
+	return fields
+}
+
+// sendIssue sends a notification about a single issue to its routed channel
+func (s *SlackNotifier) sendIssue(ctx context.Context, r routing, issue detection.Issue) error {
 	logger := log.FromContext(ctx)
 
 	// Create Slack attachment
@@ -78,7 +379,7 @@ func (s *SlackNotifier) SendIssueNotification(ctx context.Context, issue detecti
 			},
 			{
 				Title: "Actions",
-				Value: strings.Join(issue.Actions, ", "),
+				Value: actionsValue(issue.Actions),
 				Short: false,
 			},
 		},
@@ -86,21 +387,54 @@ func (s *SlackNotifier) SendIssueNotification(ctx context.Context, issue detecti
 		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
 		Ts:         json.Number(fmt.Sprintf("%d", issue.DetectedAt.Unix())),
 	}
+	if issue.Container != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Container",
+			Value: issue.Container,
+			Short: true,
+		})
+	}
+	if issue.EscalatedFrom != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Escalated",
+			Value: fmt.Sprintf("%s → %s while unresolved", strings.ToUpper(issue.EscalatedFrom), strings.ToUpper(issue.Severity)),
+			Short: false,
+		})
+	}
+	attachment.Fields = append(attachment.Fields, ownerFields(r)...)
+	attachment.Fields = append(attachment.Fields, ruleFields(issue.RuleMetadata)...)
+	if issue.Change != nil {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Recent Change",
+			Value: fmt.Sprintf("%s %s", issue.Change.Deployment, issue.Change.Summary(time.Now())),
+			Short: false,
+		})
+	}
+	if issue.Runbook != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: "Runbook",
+			Value: fmt.Sprintf("<%s|%s>", issue.Runbook, issue.RuleName),
+			Short: false,
+		})
+	}
+	if len(issue.Diagnoses) > 0 {
+		attachment.Fields = append(attachment.Fields, diagnosisField(issue.Diagnoses))
+	}
+	if issue.Analysis != nil {
+		attachment.Fields = append(attachment.Fields, analysisFields(issue.Analysis)...)
+	}
 
-	// Send the message
-	_, _, err := s.client.PostMessage(
-		s.config.Channel,
-		slack.MsgOptionText("Issue detected in Kubernetes cluster", false),
-		slack.MsgOptionAttachments(attachment),
-		slack.MsgOptionAsUser(true),
-	)
-
-	if err != nil {
+	msg := Message{
+		Type:       "issue",
+		Channel:    r.Channel,
+		Resource:   fmt.Sprintf("%s/%s/%s", issue.Namespace, issue.Kind, issue.Name),
+		Text:       "Issue detected in Kubernetes cluster",
+		Attachment: attachment,
+	}
+	if err := s.deliver(ctx, msg); err != nil {
 		logger.Error(err, "Failed to send Slack notification")
 		return fmt.Errorf("failed to send Slack notification: %w", err)
 	}
-
-	logger.Info("Successfully sent Slack notification for issue", "rule", issue.RuleName, "resource", issue.Name)
 	return nil
 }
 
@@ -111,6 +445,7 @@ func (s *SlackNotifier) SendRemediationNotification(ctx context.Context, issue d
 	}
 
 	logger := log.FromContext(ctx)
+	r := s.route(ctx, issueWorkload(issue), issue.Owner)
 
 	// Create Slack attachment
 	var color string
@@ -165,21 +500,241 @@ func (s *SlackNotifier) SendRemediationNotification(ctx context.Context, issue d
 		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
 		Ts:         json.Number(fmt.Sprintf("%d", result.ExecutedAt.Unix())),
 	}
+	attachment.Fields = append(attachment.Fields, ownerFields(r)...)
+	attachment.Fields = append(attachment.Fields, ruleFields(issue.RuleMetadata)...)
+	if result.Patch != "" {
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: fmt.Sprintf("Patch (%s)", result.PatchType),
+			Value: fmt.Sprintf("```%s```", result.Patch),
+			Short: false,
+		})
+	}
 
-	// Send the message
-	_, _, err := s.client.PostMessage(
-		s.config.Channel,
-		slack.MsgOptionText("Remediation action executed", false),
-		slack.MsgOptionAttachments(attachment),
-		slack.MsgOptionAsUser(true),
-	)
-
-	if err != nil {
+	msg := Message{
+		Type:       "remediation",
+		Channel:    r.Channel,
+		Resource:   fmt.Sprintf("%s/%s/%s", issue.Namespace, issue.Kind, issue.Name),
+		Text:       "Remediation action executed",
+		Attachment: attachment,
+	}
+	if err := s.deliver(ctx, msg); err != nil {
 		logger.Error(err, "Failed to send Slack remediation notification")
 		return fmt.Errorf("failed to send Slack remediation notification: %w", err)
 	}
+	return nil
+}
+
+// RemediationFailure is a failed remediation attempt of an issue, as notified to Slack
+type RemediationFailure struct {
+	Action  string
+	Message string
+	// Attempts counts the consecutive failed attempts, including this one
+	Attempts   int
+	MaxRetries int
+	// History holds the latest failed attempts, the most recent last
+	History []tracker.Failure
+	// NextRetry is when remediation is retried; zero once intervention is required
+	NextRetry            time.Time
+	InterventionRequired bool
+}
+
+// SendRemediationFailure notifies a failed remediation attempt with the failures
+// so far, and either when remediation is retried or that the issue requires
+// human intervention
+func (s *SlackNotifier) SendRemediationFailure(ctx context.Context, issue detection.Issue, failure RemediationFailure) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	r := s.route(ctx, issueWorkload(issue), issue.Owner)
+	msg := remediationFailureMessage(issue, failure, r)
+	if err := s.deliver(ctx, msg); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send Slack remediation failure notification")
+		return fmt.Errorf("failed to send Slack remediation failure notification: %w", err)
+	}
+	return nil
+}
+
+// remediationFailureMessage renders a failed remediation attempt as a Slack message
+func remediationFailureMessage(issue detection.Issue, failure RemediationFailure, r routing) Message {
+	title := fmt.Sprintf("❌ KubeGuardian Action Failed: %s (attempt %d of %d)", failure.Action, failure.Attempts, failure.MaxRetries+1)
+	status := "🔁 Retrying"
+	if !failure.NextRetry.IsZero() {
+		status = fmt.Sprintf("🔁 Retrying at %s", failure.NextRetry.UTC().Format(time.RFC3339))
+	}
+	if failure.InterventionRequired {
+		title = fmt.Sprintf("🆘 KubeGuardian Requires Intervention: %s failed %d times", failure.Action, failure.Attempts)
+		status = "🆘 Automatic remediation stopped: requires human intervention"
+	}
+
+	fields := []slack.AttachmentField{
+		{Title: "Resource", Value: fmt.Sprintf("%s/%s", issue.Kind, issue.Name), Short: true},
+		{Title: "Namespace", Value: issue.Namespace, Short: true},
+		{Title: "Issue", Value: issue.RuleName, Short: true},
+		{Title: "Status", Value: status, Short: true},
+	}
+	if len(failure.History) > 0 {
+		lines := make([]string, 0, len(failure.History))
+		for _, f := range failure.History {
+			lines = append(lines, fmt.Sprintf("• %s %s: %s", f.At.UTC().Format(time.RFC3339), f.Action, f.Message))
+		}
+		fields = append(fields, slack.AttachmentField{Title: "Failure History", Value: strings.Join(lines, "\n")})
+	}
+	fields = append(fields, ownerFields(r)...)
+	fields = append(fields, ruleFields(issue.RuleMetadata)...)
+
+	return Message{
+		Type:     "remediation",
+		Channel:  r.Channel,
+		Resource: fmt.Sprintf("%s/%s/%s", issue.Namespace, issue.Kind, issue.Name),
+		Text:     "Remediation action failed",
+		Attachment: slack.Attachment{
+			Color:      "danger",
+			Title:      title,
+			Text:       failure.Message,
+			Fields:     fields,
+			Footer:     "KubeGuardian",
+			FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+			Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+		},
+	}
+}
+
+// States of a rollout followed after a remediation action
+const (
+	RolloutComplete = "complete"
+	RolloutFailed   = "failed"
+	RolloutTimedOut = "timeout"
+	RolloutDeleted  = "deleted"
+)
 
-	logger.Info("Successfully sent Slack remediation notification", "action", result.Action, "resource", result.Resource)
+// RolloutOutcome is the final state of the Deployment rollout started by a
+// rollback or restart action, as notified to Slack
+type RolloutOutcome struct {
+	Action     string
+	Namespace  string
+	Deployment string
+	// State is one of RolloutComplete, RolloutFailed, RolloutTimedOut or RolloutDeleted
+	State   string
+	Message string
+	// Duration is how long the rollout was followed after the action
+	Duration          time.Duration
+	Replicas          int32
+	UpdatedReplicas   int32
+	AvailableReplicas int32
+}
+
+// SendRolloutNotification follows up a rollback or restart action with the
+// final state of the rollout it started
+func (s *SlackNotifier) SendRolloutNotification(ctx context.Context, issue detection.Issue, outcome RolloutOutcome) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	r := s.route(ctx, issueWorkload(issue), issue.Owner)
+	msg := rolloutMessage(issue, outcome, r)
+	if err := s.deliver(ctx, msg); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send Slack rollout notification")
+		return fmt.Errorf("failed to send Slack rollout notification: %w", err)
+	}
+	return nil
+}
+
+// rolloutMessage renders the final state of a followed rollout as a Slack message
+func rolloutMessage(issue detection.Issue, outcome RolloutOutcome, r routing) Message {
+	color, status := "danger", "❌ Rollout failed"
+	switch outcome.State {
+	case RolloutComplete:
+		color, status = "good", "✅ Rollout complete"
+	case RolloutTimedOut:
+		color, status = "warning", "⏳ Rollout timed out"
+	case RolloutDeleted:
+		color, status = "warning", "🗑️ Deployment deleted"
+	}
+
+	fields := []slack.AttachmentField{
+		{Title: "Resource", Value: fmt.Sprintf("Deployment/%s", outcome.Deployment), Short: true},
+		{Title: "Namespace", Value: outcome.Namespace, Short: true},
+		{Title: "Status", Value: status, Short: true},
+		{Title: "Followed For", Value: outcome.Duration.Round(time.Second).String(), Short: true},
+		{Title: "Replicas", Value: fmt.Sprintf("%d desired, %d updated, %d available", outcome.Replicas, outcome.UpdatedReplicas, outcome.AvailableReplicas), Short: true},
+		{Title: "Issue", Value: issue.RuleName, Short: true},
+	}
+	fields = append(fields, ownerFields(r)...)
+	fields = append(fields, ruleFields(issue.RuleMetadata)...)
+
+	return Message{
+		Type:     "remediation",
+		Channel:  r.Channel,
+		Resource: fmt.Sprintf("%s/Deployment/%s", outcome.Namespace, outcome.Deployment),
+		Text:     "Rollout followed after remediation action",
+		Attachment: slack.Attachment{
+			Color:      color,
+			Title:      fmt.Sprintf("🚀 KubeGuardian Rollout: %s %s", outcome.Action, outcome.State),
+			Text:       outcome.Message,
+			Fields:     fields,
+			Footer:     "KubeGuardian",
+			FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+			Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+		},
+	}
+}
+
+// SendResolvedNotification announces that a previously detected issue is no longer detected
+func (s *SlackNotifier) SendResolvedNotification(ctx context.Context, record tracker.Record) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	outcome := "Resolved outside of KubeGuardian"
+	if record.Remediated() {
+		outcome = "Resolved after remediation"
+	}
+
+	attachment := slack.Attachment{
+		Color: "good",
+		Title: fmt.Sprintf("✅ KubeGuardian Resolved: %s", record.RuleName),
+		Text:  outcome,
+		Fields: []slack.AttachmentField{
+			{
+				Title: "Resource",
+				Value: fmt.Sprintf("%s/%s", record.Kind, record.Name),
+				Short: true,
+			},
+			{
+				Title: "Namespace",
+				Value: record.Namespace,
+				Short: true,
+			},
+			{
+				Title: "Duration",
+				Value: record.TimeToResolution().Round(time.Second).String(),
+				Short: true,
+			},
+			{
+				Title: "Remediation Attempts",
+				Value: fmt.Sprintf("%d", record.Attempts),
+				Short: true,
+			},
+		},
+		Footer:     "KubeGuardian",
+		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+		Ts:         json.Number(fmt.Sprintf("%d", record.ResolvedAt.Unix())),
+	}
+
+	msg := Message{
+		Type:       "resolved",
+		Channel:    s.route(ctx, catalog.Workload{Namespace: record.Namespace, Kind: record.Kind, Name: record.Name}, detection.Owner{Team: record.Team}).Channel,
+		Resource:   fmt.Sprintf("%s/%s/%s", record.Namespace, record.Kind, record.Name),
+		Text:       "Issue resolved",
+		Attachment: attachment,
+	}
+	if err := s.deliver(ctx, msg); err != nil {
+		logger.Error(err, "Failed to send Slack resolved notification")
+		return fmt.Errorf("failed to send Slack resolved notification: %w", err)
+	}
 	return nil
 }
 
@@ -194,7 +749,7 @@ func (s *SlackNotifier) SendStartupNotification(ctx context.Context, version str
 	attachment := slack.Attachment{
 		Color: "good",
 		Title: "🚀 KubeGuardian Started",
-		Text:  fmt.Sprintf("KubeGuardian v%s is now monitoring your Kubernetes cluster", version),
+		Text:  fmt.Sprintf("KubeGuardian %s is now monitoring your Kubernetes cluster", version),
 		Fields: []slack.AttachmentField{
 			{
 				Title: "Version",
@@ -212,22 +767,174 @@ func (s *SlackNotifier) SendStartupNotification(ctx context.Context, version str
 		Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
 	}
 
-	_, _, err := s.client.PostMessage(
-		s.config.Channel,
-		slack.MsgOptionText("KubeGuardian started", false),
-		slack.MsgOptionAttachments(attachment),
-		slack.MsgOptionAsUser(true),
-	)
-
-	if err != nil {
+	msg := Message{
+		Type:       "startup",
+		Text:       "KubeGuardian started",
+		Attachment: attachment,
+	}
+	if err := s.deliver(ctx, msg); err != nil {
 		logger.Error(err, "Failed to send Slack startup notification")
 		return fmt.Errorf("failed to send Slack startup notification: %w", err)
 	}
+	return nil
+}
+
+// SendShutdownNotification sends a notification when KubeGuardian stops. It is
+// posted directly rather than queued, since the queue stops with the controller.
+func (s *SlackNotifier) SendShutdownNotification(ctx context.Context, version string, uptime time.Duration) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
 
-	logger.Info("Successfully sent Slack startup notification")
+	attachment := slack.Attachment{
+		Color: "warning",
+		Title: "🛑 KubeGuardian Stopped",
+		Text:  fmt.Sprintf("KubeGuardian %s stopped monitoring your Kubernetes cluster", version),
+		Fields: []slack.AttachmentField{
+			{
+				Title: "Version",
+				Value: version,
+				Short: true,
+			},
+			{
+				Title: "Uptime",
+				Value: uptime.Round(time.Second).String(),
+				Short: true,
+			},
+		},
+		Footer:     "KubeGuardian",
+		FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+		Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+	}
+
+	msg := Message{
+		Type:       "shutdown",
+		Text:       "KubeGuardian stopped",
+		Attachment: attachment,
+	}
+	if err := s.post(ctx, msg); err != nil {
+		logger.Error(err, "Failed to send Slack shutdown notification")
+		return fmt.Errorf("failed to send Slack shutdown notification: %w", err)
+	}
+	return nil
+}
+
+// SendDigest posts a digest of the activity of KubeGuardian to a channel, or the
+// configured channel if empty. It is posted directly rather than queued so the
+// reporter can retry a failed digest.
+func (s *SlackNotifier) SendDigest(ctx context.Context, channel string, summary digest.Summary) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	if err := s.post(ctx, digestMessage(channel, summary)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send Slack digest", "channel", channel)
+		return fmt.Errorf("failed to send Slack digest: %w", err)
+	}
 	return nil
 }
 
+// digestMessage renders a digest as a Slack message
+func digestMessage(channel string, summary digest.Summary) Message {
+	fields := []slack.AttachmentField{
+		{Title: "Issues Detected", Value: fmt.Sprintf("%d", summary.Detected), Short: true},
+		{Title: "Auto-Resolved", Value: fmt.Sprintf("%d", summary.AutoResolved), Short: true},
+		{Title: "Escalated", Value: fmt.Sprintf("%d", summary.Escalated), Short: true},
+		{Title: "Remediations", Value: fmt.Sprintf("%d (%d failed)", summary.Remediations, summary.FailedRemediations), Short: true},
+		{Title: "Estimated Toil Saved", Value: summary.ToilSaved.Round(time.Minute).String(), Short: true},
+	}
+	if len(summary.TopWorkloads) > 0 {
+		lines := make([]string, 0, len(summary.TopWorkloads))
+		for _, w := range summary.TopWorkloads {
+			lines = append(lines, fmt.Sprintf("• %s/%s (%s): %d", w.Namespace, w.Name, w.Kind, w.Issues))
+		}
+		fields = append(fields, slack.AttachmentField{Title: "Noisiest Workloads", Value: strings.Join(lines, "\n")})
+	}
+	if summary.Usage != nil && len(summary.Usage.Teams) > 0 {
+		lines := make([]string, 0, len(summary.Usage.Teams))
+		for _, team := range summary.Usage.Teams {
+			lines = append(lines, "• "+team.Line())
+		}
+		fields = append(fields, slack.AttachmentField{Title: "Usage by Team (" + summary.Usage.Month + ")", Value: strings.Join(lines, "\n")})
+	}
+
+	return Message{
+		Type:    "digest",
+		Channel: channel,
+		Text:    summary.Title(),
+		Attachment: slack.Attachment{
+			Color:      "#439fe0",
+			Title:      "📊 " + summary.Title(),
+			Fields:     fields,
+			Footer:     "KubeGuardian",
+			FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+			Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+		},
+	}
+}
+
+// SendHygieneReport posts a hygiene report listing at most maxFindings findings
+// to channel, or the default channel if empty
+func (s *SlackNotifier) SendHygieneReport(ctx context.Context, channel string, report *hygiene.Report, maxFindings int) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	if err := s.post(ctx, hygieneMessage(channel, report, maxFindings)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send Slack hygiene report", "channel", channel)
+		return fmt.Errorf("failed to send Slack hygiene report: %w", err)
+	}
+	return nil
+}
+
+// hygieneMessage renders a hygiene report as a Slack message
+func hygieneMessage(channel string, report *hygiene.Report, maxFindings int) Message {
+	fields := []slack.AttachmentField{
+		{Title: "Workloads Checked", Value: fmt.Sprintf("%d", report.Workloads), Short: true},
+		{Title: "Findings", Value: fmt.Sprintf("%d", len(report.Findings)), Short: true},
+	}
+	for _, check := range hygiene.Checks {
+		if count := report.Counts[check]; count > 0 {
+			fields = append(fields, slack.AttachmentField{Title: check, Value: fmt.Sprintf("%d", count), Short: true})
+		}
+	}
+	if len(report.Findings) > 0 {
+		lines := make([]string, 0, maxFindings+1)
+		for i, f := range report.Findings {
+			if i == maxFindings {
+				lines = append(lines, fmt.Sprintf("… and %d more", len(report.Findings)-maxFindings))
+				break
+			}
+			workload := fmt.Sprintf("%s/%s (%s)", f.Namespace, f.Name, f.Kind)
+			if f.Container != "" {
+				workload += " container " + f.Container
+			}
+			lines = append(lines, fmt.Sprintf("• %s %s", workload, f.Message))
+		}
+		fields = append(fields, slack.AttachmentField{Title: "Details", Value: strings.Join(lines, "\n")})
+	}
+
+	color := "good"
+	if len(report.Findings) > 0 {
+		color = "#ff9900"
+	}
+	return Message{
+		Type:    "hygiene",
+		Channel: channel,
+		Text:    report.Title(),
+		Attachment: slack.Attachment{
+			Color:      color,
+			Title:      "🩺 " + report.Title(),
+			Fields:     fields,
+			Footer:     "KubeGuardian",
+			FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+			Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+		},
+	}
+}
+
 // getColorBySeverity returns a color based on severity level
 func (s *SlackNotifier) getColorBySeverity(severity string) string {
 	switch strings.ToLower(severity) {
@@ -253,7 +960,7 @@ func (s *SlackNotifier) TestConnection(ctx context.Context) error {
 	logger := log.FromContext(ctx)
 
 	// Test by getting auth info
-	_, err := s.client.AuthTest()
+	_, err := s.slackClient().AuthTest()
 	if err != nil {
 		logger.Error(err, "Slack connection test failed")
 		return fmt.Errorf("Slack connection test failed: %w", err)
@@ -262,3 +969,64 @@ func (s *SlackNotifier) TestConnection(ctx context.Context) error {
 	logger.Info("Slack connection test successful")
 	return nil
 }
+
+// IncidentMode is an incident mode started or ended, as announced to Slack
+type IncidentMode struct {
+	ID              string
+	Scope           string
+	CooldownSeconds int
+	Reason          string
+	StartedBy       string
+	ExpiresAt       time.Time
+	// Ended is why the incident mode ended, e.g. expired; empty when it started
+	Ended string
+}
+
+// SendIncidentMode announces that incident mode started or ended
+func (s *SlackNotifier) SendIncidentMode(ctx context.Context, incident IncidentMode) error {
+	if s == nil || !s.config.Enabled {
+		return nil
+	}
+
+	if err := s.post(ctx, incidentModeMessage(incident)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send Slack incident mode notification", "incident", incident.ID)
+		return fmt.Errorf("failed to send Slack incident mode notification: %w", err)
+	}
+	return nil
+}
+
+// incidentModeMessage renders an incident mode change as a Slack message to the
+// default channel
+func incidentModeMessage(incident IncidentMode) Message {
+	title := fmt.Sprintf("🚨 Incident mode started for %s", incident.Scope)
+	color := "warning"
+	fields := []slack.AttachmentField{
+		{Title: "Incident", Value: incident.ID, Short: true},
+		{Title: "Started By", Value: incident.StartedBy, Short: true},
+	}
+	if incident.Ended != "" {
+		title = fmt.Sprintf("✅ Incident mode for %s %s", incident.Scope, incident.Ended)
+		color = "good"
+	} else {
+		fields = append(fields,
+			slack.AttachmentField{Title: "Cooldown", Value: fmt.Sprintf("%ds", incident.CooldownSeconds), Short: true},
+			slack.AttachmentField{Title: "Reverts At", Value: incident.ExpiresAt.UTC().Format(time.RFC3339), Short: true},
+		)
+	}
+	if incident.Reason != "" {
+		fields = append(fields, slack.AttachmentField{Title: "Reason", Value: incident.Reason})
+	}
+
+	return Message{
+		Type: "incident",
+		Text: title,
+		Attachment: slack.Attachment{
+			Color:      color,
+			Title:      title,
+			Fields:     fields,
+			Footer:     "KubeGuardian",
+			FooterIcon: "https://platform.slack-edge.com/img/default_application_icon.png",
+			Ts:         json.Number(fmt.Sprintf("%d", time.Now().Unix())),
+		},
+	}
+}
@@ -0,0 +1,284 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/catalog"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/digest"
+	"github.com/NotHarshhaa/kubeguardian/pkg/hygiene"
+	"github.com/NotHarshhaa/kubeguardian/pkg/tracker"
+)
+
+func TestIssuesRoutedToTeamChannels(t *testing.T) {
+	s := &SlackNotifier{
+		config: SlackConfig{
+			Enabled:      true,
+			Channel:      "#alerts",
+			TeamChannels: map[string]string{"payments": "#payments-alerts"},
+		},
+		queue: newQueue(QueueConfig{Size: 10}, nil, nil),
+	}
+	ctx := context.Background()
+
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "api", Owner: detection.Owner{Team: "payments", Contact: "@alice"}})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "web", Owner: detection.Owner{Team: "search"}})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "db"})
+
+	messages := queued(s)
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	for i, want := range []string{"#payments-alerts", "#alerts", "#alerts"} {
+		if messages[i].Channel != want {
+			t.Errorf("message %d channel = %q, want %q", i, messages[i].Channel, want)
+		}
+	}
+
+	fields := map[string]string{}
+	for _, field := range messages[0].Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Team"] != "payments" || fields["Owner"] != "@alice" {
+		t.Errorf("owner should be included in the notification: %v", fields)
+	}
+	for _, field := range messages[2].Attachment.Fields {
+		if field.Title == "Team" || field.Title == "Owner" {
+			t.Errorf("unexpected %s field without owner metadata", field.Title)
+		}
+	}
+}
+
+func TestEscalatedIssuesRoutedBySeverity(t *testing.T) {
+	s := &SlackNotifier{
+		config: SlackConfig{
+			Enabled:          true,
+			Channel:          "#alerts",
+			TeamChannels:     map[string]string{"payments": "#payments-alerts"},
+			SeverityChannels: map[string]string{"critical": "#incidents"},
+		},
+		queue: newQueue(QueueConfig{Size: 10}, nil, nil),
+	}
+	ctx := context.Background()
+
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "api", Severity: "high", Owner: detection.Owner{Team: "payments"}})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "api", Severity: "critical", EscalatedFrom: "high", Owner: detection.Owner{Team: "payments"}})
+
+	messages := queued(s)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	for i, want := range []string{"#payments-alerts", "#incidents"} {
+		if messages[i].Channel != want {
+			t.Errorf("message %d channel = %q, want %q", i, messages[i].Channel, want)
+		}
+	}
+
+	fields := map[string]string{}
+	for _, field := range messages[1].Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if want := "HIGH → CRITICAL while unresolved"; fields["Escalated"] != want {
+		t.Errorf("escalated = %q, want %q", fields["Escalated"], want)
+	}
+}
+
+type staticCatalog map[string]catalog.Entry
+
+func (c staticCatalog) Resolve(ctx context.Context, workload catalog.Workload) (catalog.Entry, bool, error) {
+	entry, found := c[workload.Namespace+"/"+workload.Component()]
+	return entry, found, nil
+}
+
+func TestIssuesRoutedByCatalog(t *testing.T) {
+	s := &SlackNotifier{
+		config: SlackConfig{
+			Enabled:      true,
+			Channel:      "#alerts",
+			TeamChannels: map[string]string{"search": "#search-alerts"},
+			Catalog: staticCatalog{
+				"shop/checkout": {Team: "payments", Channel: "#checkout", EscalationPolicy: "checkout-oncall"},
+				"shop/search":   {Team: "search"},
+			},
+		},
+		queue: newQueue(QueueConfig{Size: 10}, nil, nil),
+	}
+	ctx := context.Background()
+
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Namespace: "shop", Kind: "Deployment", Name: "checkout", Owner: detection.Owner{Team: "web"}})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Namespace: "shop", Kind: "Deployment", Name: "search"})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Namespace: "shop", Kind: "Deployment", Name: "cart"})
+
+	messages := queued(s)
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	for i, want := range []string{"#checkout", "#search-alerts", "#alerts"} {
+		if messages[i].Channel != want {
+			t.Errorf("message %d channel = %q, want %q", i, messages[i].Channel, want)
+		}
+	}
+
+	fields := map[string]string{}
+	for _, field := range messages[0].Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Team"] != "payments" || fields["Escalation Policy"] != "checkout-oncall" {
+		t.Errorf("catalog owner should be included in the notification: %v", fields)
+	}
+}
+
+func TestIssueNotificationIncludesRuleOwner(t *testing.T) {
+	s := &SlackNotifier{
+		config: SlackConfig{Enabled: true, Channel: "#alerts"},
+		queue:  newQueue(QueueConfig{Size: 10}, nil, nil),
+	}
+	ctx := context.Background()
+
+	changed := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "api", RuleMetadata: detection.RuleMetadata{Owner: "sre", Source: detection.RuleSourceBuiltin, LastModified: changed}})
+	s.SendIssueNotification(ctx, detection.Issue{RuleName: "crashloop", Kind: "Pod", Name: "web"})
+
+	messages := queued(s)
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	fields := map[string]string{}
+	for _, field := range messages[0].Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if want := "sre (builtin, changed 2024-05-02)"; fields["Rule Owner"] != want {
+		t.Errorf("rule owner = %q, want %q", fields["Rule Owner"], want)
+	}
+	for _, field := range messages[1].Attachment.Fields {
+		if field.Title == "Rule Owner" {
+			t.Error("unexpected rule owner field without rule metadata")
+		}
+	}
+}
+
+func TestDigestMessage(t *testing.T) {
+	msg := digestMessage("#sre", digest.Summary{
+		From:         time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		To:           time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC),
+		Detected:     4,
+		AutoResolved: 3,
+		ToilSaved:    45 * time.Minute,
+		TopWorkloads: []digest.WorkloadCount{{Namespace: "prod", Kind: "Deployment", Name: "api", Issues: 3}},
+	})
+	if msg.Channel != "#sre" || msg.Type != "digest" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	fields := map[string]string{}
+	for _, field := range msg.Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Issues Detected"] != "4" || fields["Auto-Resolved"] != "3" || fields["Estimated Toil Saved"] != "45m0s" {
+		t.Errorf("unexpected digest fields: %v", fields)
+	}
+	if want := "• prod/api (Deployment): 3"; fields["Noisiest Workloads"] != want {
+		t.Errorf("noisiest workloads = %q, want %q", fields["Noisiest Workloads"], want)
+	}
+}
+
+func TestHygieneMessage(t *testing.T) {
+	report := &hygiene.Report{
+		GeneratedAt: time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC),
+		Workloads:   12,
+		Counts:      map[string]int{hygiene.CheckMissingReadinessProbe: 1, hygiene.CheckSingleReplica: 2},
+		Findings: []hygiene.Finding{
+			{Check: hygiene.CheckMissingReadinessProbe, Namespace: "prod", Kind: "Deployment", Name: "web", Container: "proxy", Message: "has no readiness probe"},
+			{Check: hygiene.CheckSingleReplica, Namespace: "prod", Kind: "Deployment", Name: "worker", Message: "runs a single replica in a production namespace"},
+			{Check: hygiene.CheckSingleReplica, Namespace: "prod", Kind: "StatefulSet", Name: "db", Message: "runs a single replica in a production namespace"},
+		},
+	}
+	msg := hygieneMessage("#platform", report, 2)
+	if msg.Channel != "#platform" || msg.Type != "hygiene" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	fields := map[string]string{}
+	for _, field := range msg.Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Workloads Checked"] != "12" || fields["Findings"] != "3" || fields[hygiene.CheckSingleReplica] != "2" {
+		t.Errorf("unexpected hygiene fields: %v", fields)
+	}
+	want := "• prod/web (Deployment) container proxy has no readiness probe\n• prod/worker (Deployment) runs a single replica in a production namespace\n… and 1 more"
+	if fields["Details"] != want {
+		t.Errorf("details = %q, want %q", fields["Details"], want)
+	}
+}
+
+func TestRemediationFailureMessage(t *testing.T) {
+	issue := detection.Issue{RuleName: "crash-loop-backoff", Namespace: "prod", Kind: "Pod", Name: "web-1"}
+	at := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	failure := RemediationFailure{
+		Action:     "restart-pod",
+		Message:    "admission webhook denied the request",
+		Attempts:   2,
+		MaxRetries: 3,
+		History: []tracker.Failure{
+			{Action: "restart-pod", Message: "timeout", At: at},
+			{Action: "restart-pod", Message: "admission webhook denied the request", At: at.Add(time.Minute)},
+		},
+		NextRetry: at.Add(time.Minute + 20*time.Second),
+	}
+
+	msg := remediationFailureMessage(issue, failure, routing{Channel: "#alerts"})
+	if msg.Channel != "#alerts" || msg.Attachment.Title != "❌ KubeGuardian Action Failed: restart-pod (attempt 2 of 4)" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	fields := map[string]string{}
+	for _, field := range msg.Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Status"] != "🔁 Retrying at 2024-05-06T09:01:20Z" {
+		t.Errorf("status = %q, want the next retry", fields["Status"])
+	}
+	want := "• 2024-05-06T09:00:00Z restart-pod: timeout\n• 2024-05-06T09:01:00Z restart-pod: admission webhook denied the request"
+	if fields["Failure History"] != want {
+		t.Errorf("failure history = %q, want %q", fields["Failure History"], want)
+	}
+
+	failure.Attempts, failure.NextRetry, failure.InterventionRequired = 4, time.Time{}, true
+	msg = remediationFailureMessage(issue, failure, routing{Channel: "#alerts"})
+	if msg.Attachment.Title != "🆘 KubeGuardian Requires Intervention: restart-pod failed 4 times" {
+		t.Errorf("title = %q, want intervention required", msg.Attachment.Title)
+	}
+}
+
+func TestRolloutMessage(t *testing.T) {
+	issue := detection.Issue{RuleName: "crash-loop-backoff", Namespace: "prod", Kind: "Pod", Name: "web-1"}
+	outcome := RolloutOutcome{
+		Action:            "rollback-deployment",
+		Namespace:         "prod",
+		Deployment:        "web",
+		State:             RolloutComplete,
+		Message:           "Deployment web successfully rolled out",
+		Duration:          95 * time.Second,
+		Replicas:          3,
+		UpdatedReplicas:   3,
+		AvailableReplicas: 3,
+	}
+
+	msg := rolloutMessage(issue, outcome, routing{Channel: "#alerts"})
+	if msg.Channel != "#alerts" || msg.Resource != "prod/Deployment/web" || msg.Attachment.Color != "good" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	fields := map[string]string{}
+	for _, field := range msg.Attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	if fields["Status"] != "✅ Rollout complete" || fields["Followed For"] != "1m35s" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	outcome.State = RolloutFailed
+	if msg := rolloutMessage(issue, outcome, routing{}); msg.Attachment.Color != "danger" {
+		t.Errorf("color = %q, want danger for failed rollouts", msg.Attachment.Color)
+	}
+}
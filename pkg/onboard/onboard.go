@@ -0,0 +1,211 @@
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+)
+
+const (
+	// usageHeadroomPercent is added to the highest observed usage so that normal
+	// load does not trip the proposed CPU and memory thresholds
+	usageHeadroomPercent = 10.0
+	// maxThresholdPercent caps proposed CPU and memory thresholds
+	maxThresholdPercent = 95.0
+	// maxRestartLimit caps the proposed crash loop restart limit
+	maxRestartLimit = 20
+)
+
+// Proposal is a baseline policy for a namespace derived from its workloads
+type Proposal struct {
+	Namespace   string
+	GeneratedAt time.Time
+	// Pods and Deployments are the numbers of workloads inspected
+	Pods        int
+	Deployments int
+	// Notes explain the settings that differ from the defaults, and what could not be observed
+	Notes []string
+	// Settings are the proposed namespace settings
+	Settings config.NamespaceConfig
+}
+
+// Inspector proposes namespace policies from the state of existing workloads
+type Inspector struct {
+	client  kubernetes.Interface
+	metrics metricsclientset.Interface
+}
+
+// NewInspector creates an inspector. Without a metrics client, or if metrics-server
+// is not available, the CPU and memory thresholds are kept at their defaults.
+func NewInspector(client kubernetes.Interface, metrics metricsclientset.Interface) *Inspector {
+	return &Inspector{client: client, metrics: metrics}
+}
+
+// Propose inspects the pods and deployments of a namespace and proposes settings,
+// starting from base (usually the defaults of the configuration):
+//   - the crash loop restart limit is raised above the restarts most pods already
+//     have, so onboarding does not start with a burst of alerts
+//   - the CPU and memory thresholds are raised above the highest usage observed,
+//     as a percentage of container limits
+//   - remediation is limited to high severity issues while single-replica
+//     deployments would be unavailable during a restart
+func (i *Inspector) Propose(ctx context.Context, namespace string, base config.NamespaceConfig, now time.Time) (*Proposal, error) {
+	pods, err := i.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	deployments, err := i.client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	proposal := &Proposal{
+		Namespace:   namespace,
+		GeneratedAt: now,
+		Pods:        len(pods.Items),
+		Deployments: len(deployments.Items),
+		Settings:    base,
+	}
+	proposal.proposeRestartLimit(pods.Items)
+	i.proposeUsageThresholds(ctx, proposal, pods.Items)
+
+	var singleReplica []string
+	for _, deployment := range deployments.Items {
+		if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 1 {
+			singleReplica = append(singleReplica, deployment.Name)
+		}
+	}
+	if len(singleReplica) > 0 && config.SeverityRank(base.Remediation.MinSeverity) < config.SeverityRank("high") {
+		proposal.Settings.Remediation.MinSeverity = "high"
+		proposal.note("remediation.minSeverity: high, single-replica deployments are unavailable while restarted: %s",
+			summarize(singleReplica))
+	}
+
+	return proposal, nil
+}
+
+// proposeRestartLimit raises the restart limit above the 90th percentile of the
+// restart counts of the pods
+func (p *Proposal) proposeRestartLimit(pods []corev1.Pod) {
+	var restarts []int
+	for _, pod := range pods {
+		total := 0
+		for _, status := range pod.Status.ContainerStatuses {
+			total += int(status.RestartCount)
+		}
+		restarts = append(restarts, total)
+	}
+	if len(restarts) == 0 {
+		p.note("no pods found, detection settings are the defaults")
+		return
+	}
+
+	sort.Ints(restarts)
+	p90 := restarts[int(math.Ceil(0.9*float64(len(restarts))))-1]
+	if p90 < p.Settings.CrashLoop.RestartLimit {
+		return
+	}
+	limit := min(p90+1, maxRestartLimit)
+	if limit > p.Settings.CrashLoop.RestartLimit {
+		p.Settings.CrashLoop.RestartLimit = limit
+		p.note("crashloop.restartLimit: %d, 90%% of pods restarted up to %d times", limit, p90)
+	}
+}
+
+// proposeUsageThresholds raises the CPU and memory thresholds above the highest
+// usage of the containers with limits
+func (i *Inspector) proposeUsageThresholds(ctx context.Context, p *Proposal, pods []corev1.Pod) {
+	if i.metrics == nil {
+		p.note("resource usage not inspected, cpu and memory thresholds are the defaults")
+		return
+	}
+	list, err := i.metrics.MetricsV1beta1().PodMetricses(p.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		p.note("metrics-server not available (%v), cpu and memory thresholds are the defaults", err)
+		return
+	}
+
+	limits := make(map[string]corev1.ResourceList) // Key: pod/container
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			limits[pod.Name+"/"+container.Name] = container.Resources.Limits
+		}
+	}
+
+	peak := map[corev1.ResourceName]float64{}
+	peakContainer := map[corev1.ResourceName]string{}
+	for _, podMetrics := range list.Items {
+		for _, container := range podMetrics.Containers {
+			key := podMetrics.Name + "/" + container.Name
+			for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				limit, used := limits[key][resource], container.Usage[resource]
+				if limit.IsZero() {
+					continue
+				}
+				if percent := used.AsApproximateFloat64() / limit.AsApproximateFloat64() * 100; percent > peak[resource] {
+					peak[resource], peakContainer[resource] = percent, key
+				}
+			}
+		}
+	}
+
+	propose := func(resource corev1.ResourceName, threshold *float64, field string) {
+		if peakContainer[resource] == "" {
+			p.note("%s: no %s usage of containers with limits observed, kept at the default", field, resource)
+			return
+		}
+		proposed := math.Min(math.Ceil(peak[resource]+usageHeadroomPercent), maxThresholdPercent)
+		if proposed > *threshold {
+			*threshold = proposed
+			p.note("%s: %.0f, %s already uses %.1f%% of its %s limit", field, proposed, peakContainer[resource], peak[resource], resource)
+		}
+	}
+	propose(corev1.ResourceCPU, &p.Settings.CPU.ThresholdPercent, "cpu.thresholdPercent")
+	propose(corev1.ResourceMemory, &p.Settings.Memory.ThresholdPercent, "memory.thresholdPercent")
+}
+
+// note records the reason of a proposed setting
+func (p *Proposal) note(format string, args ...interface{}) {
+	p.Notes = append(p.Notes, fmt.Sprintf(format, args...))
+}
+
+// YAML renders the proposal as a namespaces block of the configuration file,
+// with the notes as comments
+func (p *Proposal) YAML() ([]byte, error) {
+	block := map[string]map[string]config.NamespaceConfig{
+		"namespaces": {p.Namespace: p.Settings},
+	}
+	out, err := yaml.Marshal(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render proposal: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Baseline policy for namespace %s, proposed by kubeguardian onboard at %s\n",
+		p.Namespace, p.GeneratedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "# from %d pods and %d deployments\n", p.Pods, p.Deployments)
+	for _, note := range p.Notes {
+		fmt.Fprintf(&b, "# - %s\n", note)
+	}
+	b.Write(out)
+	return []byte(b.String()), nil
+}
+
+// summarize lists up to three names
+func summarize(names []string) string {
+	if len(names) <= 3 {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:3], ", "), len(names)-3)
+}
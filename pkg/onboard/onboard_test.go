@@ -0,0 +1,130 @@
+package onboard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+)
+
+func pod(name string, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-x"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}}},
+	}
+}
+
+// metricsClient serves pod metrics from a reactor, since the object tracker of the
+// fake clientset does not map PodMetrics to the pods resource of metrics.k8s.io
+func metricsClient(err error, items ...metricsv1beta1.PodMetrics) *metricsfake.Clientset {
+	client := metricsfake.NewSimpleClientset()
+	client.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if err != nil {
+			return true, nil, err
+		}
+		return true, &metricsv1beta1.PodMetricsList{Items: items}, nil
+	})
+	return client
+}
+
+func TestPropose(t *testing.T) {
+	one, three := int32(1), int32(3)
+	objects := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-x"}, Spec: appsv1.DeploymentSpec{Replicas: &three}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cron-ui", Namespace: "team-x"}, Spec: appsv1.DeploymentSpec{Replicas: &one}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+	}
+	for i, restarts := range []int32{0, 1, 2, 4, 5, 6, 6, 7, 7, 8} {
+		objects = append(objects, pod(string(rune('a'+i)), restarts))
+	}
+	usage := []metricsv1beta1.PodMetrics{{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "team-x"},
+		Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("300m"),
+			corev1.ResourceMemory: resource.MustParse("870Mi"),
+		}}},
+	}}
+	base := config.DefaultConfig().DefaultNamespaceConfig()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	inspector := NewInspector(fake.NewSimpleClientset(objects...), metricsClient(nil, usage...))
+	proposal, err := inspector.Propose(context.Background(), "team-x", base, now)
+	if err != nil {
+		t.Fatalf("Propose() failed: %v", err)
+	}
+
+	if proposal.Pods != 10 || proposal.Deployments != 2 {
+		t.Errorf("inspected %d pods and %d deployments, want 10 and 2", proposal.Pods, proposal.Deployments)
+	}
+	settings := proposal.Settings
+	if settings.CrashLoop.RestartLimit != 8 {
+		t.Errorf("restart limit = %d, want 8 above the 90th percentile of restarts", settings.CrashLoop.RestartLimit)
+	}
+	if settings.CPU.ThresholdPercent != base.CPU.ThresholdPercent {
+		t.Errorf("CPU threshold = %.0f, want the default above the observed usage", settings.CPU.ThresholdPercent)
+	}
+	if settings.Memory.ThresholdPercent != 95 {
+		t.Errorf("memory threshold = %.0f, want 95 above the observed 85%% usage", settings.Memory.ThresholdPercent)
+	}
+	if settings.Remediation.MinSeverity != "high" {
+		t.Errorf("remediation min severity = %q, want high with a single-replica deployment", settings.Remediation.MinSeverity)
+	}
+	if len(proposal.Notes) != 3 {
+		t.Errorf("notes = %q, want one per changed setting", proposal.Notes)
+	}
+
+	out, err := proposal.YAML()
+	if err != nil {
+		t.Fatalf("YAML() failed: %v", err)
+	}
+	if !strings.HasPrefix(string(out), "# Baseline policy for namespace team-x") || !strings.Contains(string(out), "# - remediation.minSeverity: high") {
+		t.Errorf("unexpected header:\n%s", out)
+	}
+	// The block applies as is
+	cfg := config.DefaultConfig()
+	if err := yaml.Unmarshal(out, cfg); err != nil {
+		t.Fatalf("failed to load proposal: %v", err)
+	}
+	if got := cfg.Namespaces["team-x"]; got.CrashLoop.RestartLimit != 8 || got.CPU.CheckDuration != 5*time.Minute {
+		t.Errorf("loaded settings = %+v", got)
+	}
+	if result := cfg.Validate(); !result.Valid {
+		t.Errorf("proposal is invalid: %v", result.Errors)
+	}
+}
+
+func TestProposeWithoutMetricsServer(t *testing.T) {
+	base := config.DefaultConfig().DefaultNamespaceConfig()
+	inspector := NewInspector(fake.NewSimpleClientset(pod("a", 0)), metricsClient(errors.New("the server could not find the requested resource")))
+	proposal, err := inspector.Propose(context.Background(), "team-x", base, time.Now())
+	if err != nil {
+		t.Fatalf("Propose() failed: %v", err)
+	}
+	if proposal.Settings != base {
+		t.Errorf("settings = %+v, want the defaults", proposal.Settings)
+	}
+	if len(proposal.Notes) != 1 || !strings.Contains(proposal.Notes[0], "metrics-server not available") {
+		t.Errorf("notes = %q, want metrics-server unavailable", proposal.Notes)
+	}
+}
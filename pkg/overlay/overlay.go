@@ -0,0 +1,231 @@
+package overlay
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// DefaultMaxAuditRecords is how many rule changes are kept for auditing
+const DefaultMaxAuditRecords = 100
+
+// ConfigMapKey is the data key of the state ConfigMap holding the rule overlay,
+// next to the condition state
+const ConfigMapKey = "rules.json"
+
+// Override disables a detection rule at runtime, e.g. to silence a noisy rule
+// during an incident
+type Override struct {
+	Rule       string    `json:"rule"`
+	Reason     string    `json:"reason,omitempty"`
+	DisabledBy string    `json:"disabledBy"`
+	DisabledAt time.Time `json:"disabledAt"`
+}
+
+// AuditRecord records who enabled or disabled a rule at runtime, when and why
+type AuditRecord struct {
+	Rule    string    `json:"rule"`
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason,omitempty"`
+	User    string    `json:"user"`
+	Source  string    `json:"source,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// key identifies an audit record across instances
+func (r AuditRecord) key() string {
+	return r.Rule + "/" + r.User + "/" + r.Time.UTC().Format(time.RFC3339Nano)
+}
+
+// State is the persisted overlay
+type State struct {
+	Disabled []Override    `json:"disabled"`
+	Audit    []AuditRecord `json:"audit"`
+}
+
+// Backend persists the overlay with the state backend so it survives restarts
+type Backend = detection.Backend[State]
+
+// Overlay holds the rules disabled at runtime on top of the configured rules,
+// and the audit trail of the changes. It is persisted, so a silenced rule stays
+// silenced after a restart until it is enabled again.
+type Overlay struct {
+	mu              sync.Mutex
+	backend         Backend
+	maxAuditRecords int
+	disabled        map[string]Override
+	audit           []AuditRecord // Oldest first
+	loaded          bool
+	dirty           bool
+}
+
+// New creates an overlay; a nil backend keeps it in memory only
+func New(backend Backend, maxAuditRecords int) *Overlay {
+	if maxAuditRecords <= 0 {
+		maxAuditRecords = DefaultMaxAuditRecords
+	}
+	return &Overlay{
+		backend:         backend,
+		maxAuditRecords: maxAuditRecords,
+		disabled:        make(map[string]Override),
+		loaded:          backend == nil,
+	}
+}
+
+// Load restores the overlay from the backend. It is a no-op once loaded; rules
+// changed before the load take precedence over the persisted overrides.
+func (o *Overlay) Load(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.loaded {
+		return nil
+	}
+
+	state, err := o.backend.Load(ctx)
+	if err != nil {
+		return err
+	}
+	changed := make(map[string]bool, len(o.audit))
+	for _, record := range o.audit {
+		changed[record.Rule] = true
+	}
+	for _, override := range state.Disabled {
+		if !changed[override.Rule] {
+			o.disabled[override.Rule] = override
+		}
+	}
+	o.audit = append(state.Audit, o.audit...)
+	o.trimAudit()
+	o.loaded = true
+	o.dirty = true
+	return nil
+}
+
+// Set enables or disables a rule and records the change. Enabling a rule removes
+// its override, so it follows the configuration again.
+func (o *Overlay) Set(rule string, enabled bool, reason, user, source string, now time.Time) AuditRecord {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if enabled {
+		delete(o.disabled, rule)
+	} else {
+		o.disabled[rule] = Override{Rule: rule, Reason: reason, DisabledBy: user, DisabledAt: now}
+	}
+
+	record := AuditRecord{Rule: rule, Enabled: enabled, Reason: reason, User: user, Source: source, Time: now}
+	o.audit = append(o.audit, record)
+	o.trimAudit()
+	o.dirty = true
+	return record
+}
+
+// State returns the overrides and audit records of the overlay
+func (o *Overlay) State() State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return State{Disabled: o.sortedDisabled(), Audit: append([]AuditRecord{}, o.audit...)}
+}
+
+// Merge adds the overrides and audit records of another overlay, e.g. imported
+// from another instance. Rules with an audit record here after the imported
+// override keep their state.
+func (o *Overlay) Merge(state State) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	changed := make(map[string]time.Time, len(o.audit))
+	for _, record := range o.audit {
+		changed[record.Rule] = record.Time
+	}
+	for _, override := range state.Disabled {
+		if last, exists := changed[override.Rule]; !exists || last.Before(override.DisabledAt) {
+			o.disabled[override.Rule] = override
+		}
+	}
+
+	seen := make(map[string]bool, len(o.audit))
+	for _, record := range o.audit {
+		seen[record.key()] = true
+	}
+	for _, record := range state.Audit {
+		if !seen[record.key()] {
+			o.audit = append(o.audit, record)
+		}
+	}
+	sort.SliceStable(o.audit, func(i, j int) bool { return o.audit[i].Time.Before(o.audit[j].Time) })
+	o.trimAudit()
+	o.dirty = true
+}
+
+// Override returns the override of a rule, if it is disabled at runtime
+func (o *Overlay) Override(rule string) (Override, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	override, found := o.disabled[rule]
+	return override, found
+}
+
+// Disabled returns the rules disabled at runtime, sorted by rule name
+func (o *Overlay) Disabled() []Override {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.sortedDisabled()
+}
+
+// Audit returns the recorded rule changes, newest first
+func (o *Overlay) Audit() []AuditRecord {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	records := make([]AuditRecord, len(o.audit))
+	for i, record := range o.audit {
+		records[len(o.audit)-1-i] = record
+	}
+	return records
+}
+
+// Flush persists the overlay if it changed. Changes are never written before
+// the overlay has been loaded so a failed load cannot overwrite them.
+func (o *Overlay) Flush(ctx context.Context) error {
+	o.mu.Lock()
+	if o.backend == nil || !o.loaded || !o.dirty {
+		o.mu.Unlock()
+		return nil
+	}
+	state := State{Disabled: o.sortedDisabled(), Audit: append([]AuditRecord(nil), o.audit...)}
+	o.dirty = false
+	o.mu.Unlock()
+
+	if err := o.backend.Save(ctx, state); err != nil {
+		o.mu.Lock()
+		o.dirty = true
+		o.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// sortedDisabled returns the overrides sorted by rule name; the caller holds the lock
+func (o *Overlay) sortedDisabled() []Override {
+	overrides := make([]Override, 0, len(o.disabled))
+	for _, override := range o.disabled {
+		overrides = append(overrides, override)
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Rule < overrides[j].Rule })
+	return overrides
+}
+
+// trimAudit drops the oldest audit records beyond the limit; the caller holds the lock
+func (o *Overlay) trimAudit() {
+	if excess := len(o.audit) - o.maxAuditRecords; excess > 0 {
+		o.audit = append([]AuditRecord(nil), o.audit[excess:]...)
+	}
+}
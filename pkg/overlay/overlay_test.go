@@ -0,0 +1,107 @@
+package overlay
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+func TestOverlaySetAndAudit(t *testing.T) {
+	o := New(nil, 2)
+	now := time.Now()
+
+	o.Set("pod-crash-loop", false, "noisy during incident", "alice", "api", now)
+	if override, found := o.Override("pod-crash-loop"); !found || override.DisabledBy != "alice" || override.Reason != "noisy during incident" {
+		t.Fatalf("expected rule disabled by alice, got %+v (%v)", override, found)
+	}
+
+	o.Set("pod-crash-loop", true, "", "bob", "cli", now.Add(time.Minute))
+	if _, found := o.Override("pod-crash-loop"); found {
+		t.Error("enabling a rule should remove its override")
+	}
+
+	o.Set("high-memory-usage", false, "", "bob", "cli", now.Add(2*time.Minute))
+	audit := o.Audit()
+	if len(audit) != 2 {
+		t.Fatalf("expected audit trail capped at 2 records, got %d", len(audit))
+	}
+	if audit[0].Rule != "high-memory-usage" || audit[1].Rule != "pod-crash-loop" || !audit[1].Enabled {
+		t.Errorf("expected newest audit records first, got %+v", audit)
+	}
+}
+
+func TestOverlayPersistsAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	backend := detection.NewFileValueBackend[State](filepath.Join(t.TempDir(), "rules.json"))
+
+	o := New(backend, 0)
+	if err := o.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	o.Set("pod-crash-loop", false, "noisy", "alice", "api", time.Now())
+	o.Set("high-cpu-usage", false, "", "alice", "api", time.Now())
+	if err := o.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	restarted := New(backend, 0)
+	// A rule changed before the load takes precedence over the persisted override
+	restarted.Set("high-cpu-usage", true, "", "bob", "api", time.Now())
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	disabled := restarted.Disabled()
+	if len(disabled) != 1 || disabled[0].Rule != "pod-crash-loop" || disabled[0].Reason != "noisy" {
+		t.Errorf("expected only pod-crash-loop to stay disabled, got %+v", disabled)
+	}
+	if audit := restarted.Audit(); len(audit) != 3 || audit[0].User != "bob" {
+		t.Errorf("expected persisted audit records followed by the new one, got %+v", audit)
+	}
+}
+
+func TestOverlayFlushWaitsForLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rules.json")
+
+	o := New(detection.NewFileValueBackend[State](path), 0)
+	o.Set("pod-crash-loop", false, "", "alice", "api", time.Now())
+	if err := o.Flush(ctx); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	state, err := detection.NewFileValueBackend[State](path).Load(ctx)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(state.Disabled) != 0 {
+		t.Errorf("expected nothing written before the overlay was loaded, got %+v", state.Disabled)
+	}
+}
+
+func TestOverlayMerge(t *testing.T) {
+	now := time.Now()
+	imported := New(nil, 0)
+	imported.Set("high-cpu-usage", false, "noisy", "alice", "api", now)
+	imported.Set("oom-kill-detected", false, "", "alice", "api", now)
+
+	o := New(nil, 0)
+	// A rule changed here after the imported override keeps its state
+	o.Set("oom-kill-detected", true, "fixed", "bob", "cli", now.Add(time.Minute))
+	o.Merge(imported.State())
+	o.Merge(imported.State())
+
+	if _, found := o.Override("high-cpu-usage"); !found {
+		t.Error("expected the imported override of high-cpu-usage")
+	}
+	if _, found := o.Override("oom-kill-detected"); found {
+		t.Error("expected oom-kill-detected to stay enabled")
+	}
+	audit := o.Audit()
+	if len(audit) != 3 || audit[0].User != "bob" {
+		t.Errorf("expected the merged audit trail without duplicates, newest first, got %+v", audit)
+	}
+}
@@ -0,0 +1,123 @@
+package overrides
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Workload annotations overriding namespace configuration
+const (
+	AnnotationCrashLoopRestartLimit   = "kubeguardian.io/crashloop-restart-limit"
+	AnnotationCrashLoopCheckDuration  = "kubeguardian.io/crashloop-check-duration"
+	AnnotationDeploymentCheckDuration = "kubeguardian.io/deployment-check-duration"
+	AnnotationOOMKillThreshold        = "kubeguardian.io/oom-kill-threshold"
+	AnnotationCooldown                = "kubeguardian.io/cooldown"
+)
+
+// Workload annotations opting out of detection and remediation
+const (
+	// AnnotationDisable set to true excludes a workload from detection and remediation
+	AnnotationDisable = "kubeguardian.io/disable"
+	// AnnotationActions lists the only remediation actions allowed on a workload,
+	// comma-separated; an empty value allows none
+	AnnotationActions = "kubeguardian.io/actions"
+)
+
+// IntBounds limits an integer override; a zero Max means no upper bound
+type IntBounds struct {
+	Min int
+	Max int
+}
+
+// DurationBounds limits a duration override; a zero Max means no upper bound
+type DurationBounds struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Bounds are the admin-set limits applied to workload overrides
+type Bounds struct {
+	RestartLimit     IntBounds
+	OOMKillThreshold IntBounds
+	CheckDuration    DurationBounds
+	Cooldown         DurationBounds
+	// OptOut honors the disable and actions annotations
+	OptOut bool
+}
+
+// Int parses an integer annotation and clamps it to the bounds. It returns false
+// if the annotation is not set.
+func Int(annotations map[string]string, key string, bounds IntBounds) (int, bool, error) {
+	raw, exists := annotations[key]
+	if !exists {
+		return 0, false, nil
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value %q for annotation %s: must be an integer", raw, key)
+	}
+
+	if value < bounds.Min {
+		value = bounds.Min
+	}
+	if bounds.Max > 0 && value > bounds.Max {
+		value = bounds.Max
+	}
+	return value, true, nil
+}
+
+// Duration parses a duration annotation such as "15m" and clamps it to the bounds.
+// It returns false if the annotation is not set.
+func Duration(annotations map[string]string, key string, bounds DurationBounds) (time.Duration, bool, error) {
+	raw, exists := annotations[key]
+	if !exists {
+		return 0, false, nil
+	}
+
+	value, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || value < 0 {
+		return 0, false, fmt.Errorf("invalid value %q for annotation %s: must be a non-negative duration", raw, key)
+	}
+
+	if value < bounds.Min {
+		value = bounds.Min
+	}
+	if bounds.Max > 0 && value > bounds.Max {
+		value = bounds.Max
+	}
+	return value, true, nil
+}
+
+// Disabled reports whether a workload opted out with its disable annotation
+func Disabled(annotations map[string]string) (bool, error) {
+	raw, exists := annotations[AnnotationDisable]
+	if !exists {
+		return false, nil
+	}
+
+	disabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for annotation %s: must be true or false", raw, AnnotationDisable)
+	}
+	return disabled, nil
+}
+
+// Actions returns the remediation actions a workload allows with its actions
+// annotation. It returns false if the annotation is not set.
+func Actions(annotations map[string]string) ([]string, bool) {
+	raw, exists := annotations[AnnotationActions]
+	if !exists {
+		return nil, false
+	}
+
+	actions := []string{}
+	for _, action := range strings.Split(raw, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			actions = append(actions, action)
+		}
+	}
+	return actions, true
+}
@@ -0,0 +1,117 @@
+package overrides
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInt(t *testing.T) {
+	bounds := IntBounds{Min: 1, Max: 20}
+
+	tests := []struct {
+		name    string
+		value   string
+		set     bool
+		want    int
+		wantErr bool
+	}{
+		{"not set", "", false, 0, false},
+		{"within bounds", "10", true, 10, false},
+		{"clamped to max", "100", true, 20, false},
+		{"clamped to min", "0", true, 1, false},
+		{"whitespace", " 5 ", true, 5, false},
+		{"invalid", "ten", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := map[string]string{}
+			if tt.set {
+				annotations[AnnotationCrashLoopRestartLimit] = tt.value
+			}
+
+			got, ok, err := Int(annotations, AnnotationCrashLoopRestartLimit, bounds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Int() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != (tt.set && !tt.wantErr) {
+				t.Errorf("Int() ok = %v", ok)
+			}
+			if got != tt.want {
+				t.Errorf("Int() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	bounds := DurationBounds{Min: time.Minute, Max: time.Hour}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"within bounds", "15m", 15 * time.Minute, false},
+		{"clamped to max", "24h", time.Hour, false},
+		{"clamped to min", "10s", time.Minute, false},
+		{"negative", "-5m", 0, true},
+		{"invalid", "soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := Duration(map[string]string{AnnotationCooldown: tt.value}, AnnotationCooldown, bounds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Duration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Duration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok, _ := Duration(nil, AnnotationCooldown, bounds); ok {
+		t.Error("missing annotation should not be reported as set")
+	}
+	if got, _, _ := Duration(map[string]string{AnnotationCooldown: "2h"}, AnnotationCooldown, DurationBounds{}); got != 2*time.Hour {
+		t.Errorf("zero max should not bound the value, got %v", got)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+		wantErr     bool
+	}{
+		{"not set", map[string]string{}, false, false},
+		{"true", map[string]string{AnnotationDisable: "true"}, true, false},
+		{"false", map[string]string{AnnotationDisable: " false "}, false, false},
+		{"invalid", map[string]string{AnnotationDisable: "yes"}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Disabled(tt.annotations)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Disabled() = %v, %v, want %v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	if _, ok := Actions(map[string]string{}); ok {
+		t.Error("Actions() ok without the annotation")
+	}
+	if got, ok := Actions(map[string]string{AnnotationActions: "restart-pod, scale-replicas,"}); !ok || !slices.Equal(got, []string{"restart-pod", "scale-replicas"}) {
+		t.Errorf("Actions() = %v, %v", got, ok)
+	}
+	if got, ok := Actions(map[string]string{AnnotationActions: ""}); !ok || len(got) != 0 {
+		t.Errorf("Actions() of an empty value = %v, %v, want no actions allowed", got, ok)
+	}
+}
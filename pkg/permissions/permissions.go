@@ -0,0 +1,356 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Requirement represents a single permission KubeGuardian needs
+type Requirement struct {
+	Verb        string `json:"verb"`
+	Group       string `json:"group"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	// Namespace limits the requirement to one namespace; empty means cluster-wide
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// String returns a kubectl-style description of the requirement
+func (r Requirement) String() string {
+	resource := r.Resource
+	if r.Subresource != "" {
+		resource = resource + "/" + r.Subresource
+	}
+	if r.Group != "" {
+		resource = resource + "." + r.Group
+	}
+	if r.Namespace != "" {
+		return fmt.Sprintf("%s %s in %s", r.Verb, resource, r.Namespace)
+	}
+	return fmt.Sprintf("%s %s", r.Verb, resource)
+}
+
+// DetectionRequirements are the permissions needed to run detection
+var DetectionRequirements = []Requirement{
+	{Verb: "list", Group: "", Resource: "pods"},
+	{Verb: "list", Group: "apps", Resource: "deployments"},
+	{Verb: "watch", Group: "", Resource: "pods"},            // Event-driven detection
+	{Verb: "watch", Group: "apps", Resource: "deployments"}, // Event-driven detection
+	{Verb: "list", Group: "apps", Resource: "replicasets"},  // Change correlation
+	{Verb: "get", Group: "apps", Resource: "replicasets"},   // Change correlation
+}
+
+// ActionRequirements maps remediation actions to the permissions they need
+var ActionRequirements = map[string][]Requirement{
+	"restart-pod": {
+		{Verb: "create", Group: "", Resource: "pods", Subresource: "eviction"},
+		{Verb: "delete", Group: "", Resource: "pods"},
+		{Verb: "list", Group: "", Resource: "pods"}, // Failure domain check
+		{Verb: "get", Group: "", Resource: "nodes"}, // Failure domain check
+	},
+	"restart-container": {
+		{Verb: "get", Group: "", Resource: "pods"},
+		{Verb: "update", Group: "", Resource: "pods", Subresource: "ephemeralcontainers"},
+		{Verb: "create", Group: "", Resource: "pods", Subresource: "eviction"}, // Fallback when the container does not restart
+		{Verb: "delete", Group: "", Resource: "pods"},                          // Fallback when the container does not restart
+	},
+	"rolling-restart-pods": {
+		{Verb: "list", Group: "", Resource: "pods"},
+		{Verb: "delete", Group: "", Resource: "pods"},
+	},
+	"restart-deployment": {
+		{Verb: "patch", Group: "apps", Resource: "deployments"},
+	},
+	"rollback-deployment": {
+		{Verb: "get", Group: "apps", Resource: "deployments"},
+		{Verb: "patch", Group: "apps", Resource: "deployments"},
+	},
+	"scale-replicas": {
+		{Verb: "get", Group: "apps", Resource: "replicasets"},
+		{Verb: "get", Group: "apps", Resource: "deployments"},
+		{Verb: "patch", Group: "apps", Resource: "deployments"},
+	},
+	"cordon-node": {
+		{Verb: "patch", Group: "", Resource: "nodes"},
+	},
+	"cordon-drain-node": {
+		{Verb: "patch", Group: "", Resource: "nodes"},
+		{Verb: "list", Group: "", Resource: "pods"},
+		{Verb: "create", Group: "", Resource: "pods", Subresource: "eviction"},
+	},
+	"cleanup-evicted-pods": {
+		{Verb: "list", Group: "", Resource: "pods"},
+		{Verb: "delete", Group: "", Resource: "pods"},
+	},
+	"force-delete-pod": {
+		{Verb: "get", Group: "", Resource: "pods"},
+		{Verb: "delete", Group: "", Resource: "pods"},
+	},
+	"remove-finalizer": {
+		{Verb: "patch", Group: "", Resource: "pods"},
+		{Verb: "patch", Group: "apps", Resource: "deployments"},
+		{Verb: "patch", Group: "", Resource: "services"},
+		{Verb: "patch", Group: "", Resource: "persistentvolumeclaims"},
+	},
+}
+
+// RuleRequirements maps detection rules to the permissions they need beyond
+// DetectionRequirements
+var RuleRequirements = map[string][]Requirement{
+	"scheduling-constraint-conflict": {
+		{Verb: "list", Group: "", Resource: "nodes"},
+		{Verb: "get", Group: "apps", Resource: "deployments"}, // Owning workload
+		{Verb: "get", Group: "apps", Resource: "statefulsets"},
+	},
+	"service-without-endpoints": {
+		{Verb: "list", Group: "", Resource: "services"},
+		{Verb: "list", Group: "discovery.k8s.io", Resource: "endpointslices"},
+	},
+	"resource-quota-exhaustion": {
+		{Verb: "list", Group: "", Resource: "resourcequotas"},
+		{Verb: "get", Group: "", Resource: "resourcequotas"}, // Rule traces
+	},
+}
+
+// PreCheckRequirements maps remediation pre-checks to the permissions they need
+var PreCheckRequirements = map[string][]Requirement{
+	"conflict": {
+		{Verb: "get", Group: "", Resource: "pods"},
+		{Verb: "get", Group: "apps", Resource: "deployments"},
+		{Verb: "get", Group: "", Resource: "nodes"},
+	},
+	"pdb": {
+		{Verb: "list", Group: "", Resource: "pods"},
+		{Verb: "list", Group: "policy", Resource: "poddisruptionbudgets"},
+	},
+	"capacity": {
+		{Verb: "get", Group: "apps", Resource: "replicasets"},
+		{Verb: "get", Group: "apps", Resource: "deployments"},
+		{Verb: "list", Group: "", Resource: "nodes"},
+		{Verb: "list", Group: "", Resource: "pods"},
+	},
+	"blast-radius": {
+		{Verb: "list", Group: "", Resource: "pods"},
+		{Verb: "get", Group: "", Resource: "nodes"}, // Failure domain check
+	},
+}
+
+// HandshakeRequirements are the permissions needed to annotate pods before
+// disruptive actions
+var HandshakeRequirements = []Requirement{
+	{Verb: "patch", Group: "", Resource: "pods"},
+}
+
+// ConfigMapRequirements are the permissions needed to keep data in a ConfigMap
+// of namespace, such as the state ConfigMap
+func ConfigMapRequirements(namespace string) []Requirement {
+	return []Requirement{
+		{Verb: "get", Group: "", Resource: "configmaps", Namespace: namespace},
+		{Verb: "create", Group: "", Resource: "configmaps", Namespace: namespace},
+		{Verb: "update", Group: "", Resource: "configmaps", Namespace: namespace},
+	}
+}
+
+// CheckResult represents the outcome of a single permission check
+type CheckResult struct {
+	Requirement Requirement `json:"requirement"`
+	Allowed     bool        `json:"allowed"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// Report represents the outcome of a permission preflight
+type Report struct {
+	Detection []CheckResult            `json:"detection"`
+	Actions   map[string][]CheckResult `json:"actions"`
+	Features  map[string][]CheckResult `json:"features,omitempty"`
+}
+
+// Checker verifies permissions using SelfSubjectAccessReview
+type Checker struct {
+	client kubernetes.Interface
+}
+
+// NewChecker creates a new permission checker
+func NewChecker(client kubernetes.Interface) *Checker {
+	return &Checker{
+		client: client,
+	}
+}
+
+// Check verifies the detection permissions, the permissions for each given action
+// and the requirements of each enabled feature, such as rules, pre-checks or the
+// state ConfigMap. Actions without known requirements are ignored.
+func (c *Checker) Check(ctx context.Context, actions []string, features map[string][]Requirement) (*Report, error) {
+	report := &Report{
+		Actions:  make(map[string][]CheckResult),
+		Features: make(map[string][]CheckResult),
+	}
+
+	// Cache results so shared requirements are only reviewed once
+	cache := make(map[Requirement]CheckResult)
+
+	for _, req := range DetectionRequirements {
+		result, err := c.check(ctx, req, cache)
+		if err != nil {
+			return nil, err
+		}
+		report.Detection = append(report.Detection, result)
+	}
+
+	for _, action := range actions {
+		reqs, exists := ActionRequirements[action]
+		if !exists {
+			continue
+		}
+		if _, done := report.Actions[action]; done {
+			continue
+		}
+
+		results := make([]CheckResult, 0, len(reqs))
+		for _, req := range reqs {
+			result, err := c.check(ctx, req, cache)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		report.Actions[action] = results
+	}
+
+	for feature, reqs := range features {
+		results := make([]CheckResult, 0, len(reqs))
+		for _, req := range reqs {
+			result, err := c.check(ctx, req, cache)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		report.Features[feature] = results
+	}
+
+	return report, nil
+}
+
+// check runs a SelfSubjectAccessReview for a single requirement
+func (c *Checker) check(ctx context.Context, req Requirement, cache map[Requirement]CheckResult) (CheckResult, error) {
+	if result, exists := cache[req]; exists {
+		return result, nil
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:        req.Verb,
+				Group:       req.Group,
+				Resource:    req.Resource,
+				Subresource: req.Subresource,
+				Namespace:   req.Namespace,
+			},
+		},
+	}
+
+	response, err := c.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("failed to review permission %q: %w", req.String(), err)
+	}
+
+	result := CheckResult{
+		Requirement: req,
+		Allowed:     response.Status.Allowed,
+		Reason:      response.Status.Reason,
+	}
+	cache[req] = result
+	return result, nil
+}
+
+// DetectionAllowed returns true if all detection permissions are granted
+func (r *Report) DetectionAllowed() bool {
+	return len(r.MissingDetection()) == 0
+}
+
+// MissingDetection returns the detection permissions that are not granted
+func (r *Report) MissingDetection() []Requirement {
+	var missing []Requirement
+	for _, result := range r.Detection {
+		if !result.Allowed {
+			missing = append(missing, result.Requirement)
+		}
+	}
+	return missing
+}
+
+// MissingActions returns the actions that lack permissions, mapped to the missing permissions
+func (r *Report) MissingActions() map[string][]Requirement {
+	missing := make(map[string][]Requirement)
+	for action, results := range r.Actions {
+		for _, result := range results {
+			if !result.Allowed {
+				missing[action] = append(missing[action], result.Requirement)
+			}
+		}
+	}
+	return missing
+}
+
+// MissingFeatures returns the features that lack permissions, mapped to the missing permissions
+func (r *Report) MissingFeatures() map[string][]Requirement {
+	missing := make(map[string][]Requirement)
+	for feature, results := range r.Features {
+		for _, result := range results {
+			if !result.Allowed {
+				missing[feature] = append(missing[feature], result.Requirement)
+			}
+		}
+	}
+	return missing
+}
+
+// Summary returns a human readable summary of the report
+func (r *Report) Summary() string {
+	var b strings.Builder
+
+	for _, result := range r.Detection {
+		fmt.Fprintf(&b, "%s detection: %s\n", status(result.Allowed), result.Requirement)
+	}
+
+	actions := make([]string, 0, len(r.Actions))
+	for action := range r.Actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	for _, action := range actions {
+		for _, result := range r.Actions[action] {
+			fmt.Fprintf(&b, "%s %s: %s\n", status(result.Allowed), action, result.Requirement)
+		}
+	}
+
+	features := make([]string, 0, len(r.Features))
+	for feature := range r.Features {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	for _, feature := range features {
+		for _, result := range r.Features[feature] {
+			fmt.Fprintf(&b, "%s %s: %s\n", status(result.Allowed), feature, result.Requirement)
+		}
+	}
+
+	return b.String()
+}
+
+// status returns a short pass/fail marker
+func status(allowed bool) string {
+	if allowed {
+		return "[PASS]"
+	}
+	return "[FAIL]"
+}
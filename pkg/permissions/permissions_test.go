@@ -0,0 +1,125 @@
+package permissions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a fake client that allows every verb except the denied ones
+func newFakeClient(denied ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == attrs.Verb+" "+attrs.Resource {
+				review.Status.Allowed = false
+				review.Status.Reason = "denied by test"
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestCheckAllAllowed(t *testing.T) {
+	checker := NewChecker(newFakeClient())
+
+	report, err := checker.Check(context.Background(), []string{"restart-pod", "rollback-deployment", "scale-replicas"}, nil)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if !report.DetectionAllowed() {
+		t.Errorf("detection should be allowed, missing %v", report.MissingDetection())
+	}
+
+	if missing := report.MissingActions(); len(missing) != 0 {
+		t.Errorf("expected no missing actions, got %v", missing)
+	}
+
+	if len(report.Actions) != 3 {
+		t.Errorf("expected 3 checked actions, got %d", len(report.Actions))
+	}
+}
+
+func TestCheckMissingPermissions(t *testing.T) {
+	checker := NewChecker(newFakeClient("delete pods", "list deployments"))
+
+	report, err := checker.Check(context.Background(), []string{"restart-pod", "scale-replicas", "notify-only"}, nil)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if report.DetectionAllowed() {
+		t.Error("detection should not be allowed without list deployments")
+	}
+
+	missing := report.MissingActions()
+	if _, exists := missing["restart-pod"]; !exists {
+		t.Error("restart-pod should be reported as missing permissions")
+	}
+	if _, exists := missing["scale-replicas"]; exists {
+		t.Error("scale-replicas should have all permissions")
+	}
+	if _, exists := report.Actions["notify-only"]; exists {
+		t.Error("actions without requirements should not be checked")
+	}
+
+	summary := report.Summary()
+	if !strings.Contains(summary, "[FAIL] restart-pod: delete pods") {
+		t.Errorf("summary missing failed restart-pod check:\n%s", summary)
+	}
+}
+
+func TestCheckFeatures(t *testing.T) {
+	checker := NewChecker(newFakeClient("list poddisruptionbudgets", "update configmaps"))
+
+	report, err := checker.Check(context.Background(), nil, map[string][]Requirement{
+		"rule service-without-endpoints": RuleRequirements["service-without-endpoints"],
+		"pre-check pdb":                  PreCheckRequirements["pdb"],
+		"state":                          ConfigMapRequirements("kubeguardian"),
+	})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	missing := report.MissingFeatures()
+	if len(missing) != 2 {
+		t.Errorf("expected 2 features with missing permissions, got %v", missing)
+	}
+	if _, exists := missing["rule service-without-endpoints"]; exists {
+		t.Error("rule service-without-endpoints should have all permissions")
+	}
+
+	summary := report.Summary()
+	if !strings.Contains(summary, "[FAIL] state: update configmaps in kubeguardian") {
+		t.Errorf("summary missing failed state check:\n%s", summary)
+	}
+}
+
+func TestRequirementString(t *testing.T) {
+	tests := []struct {
+		req  Requirement
+		want string
+	}{
+		{Requirement{Verb: "delete", Resource: "pods"}, "delete pods"},
+		{Requirement{Verb: "patch", Group: "apps", Resource: "deployments"}, "patch deployments.apps"},
+		{Requirement{Verb: "create", Resource: "pods", Subresource: "eviction"}, "create pods/eviction"},
+		{Requirement{Verb: "update", Resource: "configmaps", Namespace: "kubeguardian"}, "update configmaps in kubeguardian"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.req.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
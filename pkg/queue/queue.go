@@ -0,0 +1,281 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+	"github.com/NotHarshhaa/kubeguardian/pkg/ratelimit"
+)
+
+// Defaults of the remediation queue
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 10 * time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// ConfigMapKey is the data key of the state ConfigMap holding the queued items,
+// next to the condition state
+const ConfigMapKey = "queue.json"
+
+// Backend persists queued items with the state backend so they survive restarts
+type Backend = detection.Backend[[]Item]
+
+// Item is a remediation action waiting to be executed. It carries what is needed
+// to look up the resource again and rebuild the issue after a restart.
+type Item struct {
+	Key         string    `json:"key"`
+	Action      string    `json:"action"`
+	Rule        string    `json:"rule"`
+	Severity    string    `json:"severity"`
+	Namespace   string    `json:"namespace"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Container   string    `json:"container,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Attempts    int       `json:"attempts"`
+	EnqueuedAt  time.Time `json:"enqueuedAt"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+	// TraceID is the trace of the detection cycle that queued the action, if tracing is enabled
+	TraceID string `json:"traceID,omitempty"`
+}
+
+// Config configures a queue
+type Config struct {
+	// Backend persists the queued items; nil keeps them in memory only
+	Backend Backend
+	// Rate is the number of items handed out per second, and Burst how many can
+	// be handed out at once; a zero rate is unlimited
+	Rate  int
+	Burst int
+	// MaxAttempts is how often an item is attempted before it is dropped
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles with each
+	// further attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Clock is the time source for backoff and rate limiting; nil uses the real clock
+	Clock clock.PassiveClock
+}
+
+// Queue holds remediation actions between detection and execution. An item stays
+// queued, and persisted, until it is marked done or dropped, so an action
+// interrupted by a restart is attempted again: actions are executed at least once.
+type Queue struct {
+	mu       sync.Mutex
+	config   Config
+	limiter  *ratelimit.RateLimiter
+	items    map[string]*Item
+	inFlight map[string]bool
+	loaded   bool
+	dirty    bool
+}
+
+// New creates a queue
+func New(cfg Config) *Queue {
+	if cfg.Clock == nil {
+		cfg.Clock = clock.RealClock{}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = DefaultInitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.InitialBackoff)
+	}
+
+	q := &Queue{
+		config:   cfg,
+		items:    make(map[string]*Item),
+		inFlight: make(map[string]bool),
+		loaded:   cfg.Backend == nil,
+	}
+	if cfg.Rate > 0 {
+		q.limiter = ratelimit.NewRateLimiterWithClock(max(cfg.Burst, 1), cfg.Rate, cfg.Clock)
+	}
+	return q
+}
+
+// Load reads persisted items from the backend. Items queued before loading are kept.
+func (q *Queue) Load(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.loaded {
+		return nil
+	}
+
+	items, err := q.config.Backend.Load(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		if _, exists := q.items[items[i].Key]; !exists {
+			q.items[items[i].Key] = &items[i]
+		}
+	}
+	q.loaded = true
+	q.dirty = true
+	return nil
+}
+
+// Add queues an item due immediately and returns false if an item with the same
+// key is already queued
+func (q *Queue) Add(item Item) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.items[item.Key]; exists {
+		return false
+	}
+	now := q.config.Clock.Now()
+	item.Attempts = 0
+	item.EnqueuedAt = now
+	item.NextAttempt = now
+	q.items[item.Key] = &item
+	q.dirty = true
+	return true
+}
+
+// Next hands out the due item queued first, if the rate limit allows it. The item
+// stays queued until it is marked done or retried.
+func (q *Queue) Next() (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.config.Clock.Now()
+	var next *Item
+	for key, item := range q.items {
+		if q.inFlight[key] || item.NextAttempt.After(now) {
+			continue
+		}
+		if next == nil || item.NextAttempt.Before(next.NextAttempt) ||
+			(item.NextAttempt.Equal(next.NextAttempt) && item.EnqueuedAt.Before(next.EnqueuedAt)) {
+			next = item
+		}
+	}
+	if next == nil || (q.limiter != nil && !q.limiter.Allow()) {
+		return Item{}, false
+	}
+
+	next.Attempts++
+	q.inFlight[next.Key] = true
+	q.dirty = true
+	return *next, true
+}
+
+// Done removes an item after its action was executed
+func (q *Queue) Done(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.inFlight, key)
+	if _, exists := q.items[key]; exists {
+		delete(q.items, key)
+		q.dirty = true
+	}
+}
+
+// Retry schedules another attempt of an item whose action failed, after an
+// exponential backoff. It drops the item and returns false once it used up its attempts.
+func (q *Queue) Retry(key string, cause error) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.inFlight, key)
+	item, exists := q.items[key]
+	if !exists {
+		return Item{}, false
+	}
+	q.dirty = true
+	if cause != nil {
+		item.LastError = cause.Error()
+	}
+	if item.Attempts >= q.config.MaxAttempts {
+		delete(q.items, key)
+		return *item, false
+	}
+
+	backoff := q.config.InitialBackoff
+	for i := 1; i < item.Attempts && backoff < q.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	item.NextAttempt = q.config.Clock.Now().Add(min(backoff, q.config.MaxBackoff))
+	return *item, true
+}
+
+// Items returns the queued items in the order they were queued
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sorted(func(string) bool { return true })
+}
+
+// Drain removes and returns the queued items that are not being executed
+func (q *Queue) Drain() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	drained := q.sorted(func(key string) bool { return !q.inFlight[key] })
+	for _, item := range drained {
+		delete(q.items, item.Key)
+	}
+	if len(drained) > 0 {
+		q.dirty = true
+	}
+	return drained
+}
+
+// Len returns the number of queued items, including items being executed
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Flush writes the queued items to the backend if they changed. Items are not
+// written before they have been loaded so a failed load cannot overwrite them.
+func (q *Queue) Flush(ctx context.Context) error {
+	q.mu.Lock()
+	if q.config.Backend == nil || !q.loaded || !q.dirty {
+		q.mu.Unlock()
+		return nil
+	}
+	items := q.sorted(func(string) bool { return true })
+	q.dirty = false
+	q.mu.Unlock()
+
+	if err := q.config.Backend.Save(ctx, items); err != nil {
+		q.mu.Lock()
+		q.dirty = true
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// sorted copies the items with keys matching keep, ordered by time queued
+func (q *Queue) sorted(keep func(key string) bool) []Item {
+	items := make([]Item, 0, len(q.items))
+	for key, item := range q.items {
+		if keep(key) {
+			items = append(items, *item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].EnqueuedAt.Equal(items[j].EnqueuedAt) {
+			return items[i].EnqueuedAt.Before(items[j].EnqueuedAt)
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
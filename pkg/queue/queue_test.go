@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+func TestQueueRetriesWithBackoff(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	q := New(Config{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Clock: clock})
+
+	if !q.Add(Item{Key: "a", Action: "restart-pod"}) {
+		t.Fatal("first add should queue the item")
+	}
+	if q.Add(Item{Key: "a", Action: "restart-pod"}) {
+		t.Error("an item with a queued key should not be queued again")
+	}
+
+	item, ok := q.Next()
+	if !ok || item.Attempts != 1 {
+		t.Fatalf("expected first attempt, got %+v (%v)", item, ok)
+	}
+	if _, ok := q.Next(); ok {
+		t.Error("an item being executed should not be handed out again")
+	}
+
+	next, ok := q.Retry("a", errors.New("boom"))
+	if !ok || next.LastError != "boom" {
+		t.Fatalf("expected retry with the error recorded, got %+v (%v)", next, ok)
+	}
+	if _, ok := q.Next(); ok {
+		t.Error("a retried item should not be due before its backoff")
+	}
+
+	clock.SetTime(clock.Now().Add(time.Second))
+	if item, ok = q.Next(); !ok || item.Attempts != 2 {
+		t.Fatalf("expected second attempt after backoff, got %+v (%v)", item, ok)
+	}
+	next, _ = q.Retry("a", errors.New("boom"))
+	if backoff := next.NextAttempt.Sub(clock.Now()); backoff != 2*time.Second {
+		t.Errorf("expected backoff to double to 2s, got %s", backoff)
+	}
+
+	clock.SetTime(next.NextAttempt)
+	if _, ok = q.Next(); !ok {
+		t.Fatal("expected third attempt")
+	}
+	if _, ok := q.Retry("a", errors.New("boom")); ok {
+		t.Error("an item should be dropped after its last attempt")
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue, got %d items", q.Len())
+	}
+}
+
+func TestQueueRateLimit(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	q := New(Config{Rate: 1, Burst: 2, Clock: clock})
+	for _, key := range []string{"a", "b", "c"} {
+		q.Add(Item{Key: key})
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, ok := q.Next(); !ok {
+			t.Fatalf("item %d should be allowed within the burst", i)
+		}
+	}
+	if _, ok := q.Next(); ok {
+		t.Error("item should be rate limited after the burst")
+	}
+
+	clock.SetTime(clock.Now().Add(time.Second))
+	if _, ok := q.Next(); !ok {
+		t.Error("item should be allowed after the rate limit refilled")
+	}
+}
+
+func TestQueueDrainSkipsInFlight(t *testing.T) {
+	q := New(Config{})
+	q.Add(Item{Key: "a"})
+	q.Add(Item{Key: "b"})
+
+	inFlight, _ := q.Next()
+	drained := q.Drain()
+	if len(drained) != 1 || drained[0].Key == inFlight.Key {
+		t.Fatalf("expected only the idle item to be drained, got %+v", drained)
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected the item being executed to stay queued, got %d items", q.Len())
+	}
+}
+
+func TestQueuePersistsAcrossRestarts(t *testing.T) {
+	ctx := context.Background()
+	backend := detection.NewFileValueBackend[[]Item](filepath.Join(t.TempDir(), "queue.json"))
+
+	q := New(Config{Backend: backend})
+	if err := q.Load(ctx); err != nil {
+		t.Fatalf("failed to load empty queue: %v", err)
+	}
+	q.Add(Item{Key: "a", Action: "restart-pod", Namespace: "default", Kind: "Pod", Name: "web"})
+	q.Add(Item{Key: "b", Action: "restart-pod", Namespace: "default", Kind: "Pod", Name: "api"})
+	// An item handed out but not marked done is attempted again after a restart
+	q.Next()
+	if err := q.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush queue: %v", err)
+	}
+
+	restarted := New(Config{Backend: backend})
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("failed to load queue: %v", err)
+	}
+	items := restarted.Items()
+	if len(items) != 2 {
+		t.Fatalf("expected 2 persisted items, got %d", len(items))
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := restarted.Next(); !ok {
+			t.Errorf("persisted item %d should be due", i)
+		}
+	}
+}
+
+func TestQueueFlushWaitsForLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "queue.json")
+	if err := detection.NewFileValueBackend[[]Item](path).Save(ctx, []Item{{Key: "a"}}); err != nil {
+		t.Fatalf("failed to save queue: %v", err)
+	}
+
+	q := New(Config{Backend: detection.NewFileValueBackend[[]Item](path)})
+	q.Add(Item{Key: "b"})
+	if err := q.Flush(ctx); err != nil {
+		t.Fatalf("failed to flush queue: %v", err)
+	}
+
+	items, err := detection.NewFileValueBackend[[]Item](path).Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Key != "a" {
+		t.Errorf("flush before load should not overwrite persisted items, got %+v", items)
+	}
+}
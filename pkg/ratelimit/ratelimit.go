@@ -3,6 +3,8 @@ package ratelimit
 import (
 	"sync"
 	"time"
+
+	"k8s.io/utils/clock"
 )
 
 // RateLimiter implements a token bucket rate limiter
@@ -12,15 +14,22 @@ type RateLimiter struct {
 	capacity   int
 	refillRate int // tokens per second
 	lastRefill time.Time
+	clock      clock.PassiveClock
 }
 
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(capacity, refillRate int) *RateLimiter {
+	return NewRateLimiterWithClock(capacity, refillRate, clock.RealClock{})
+}
+
+// NewRateLimiterWithClock creates a new rate limiter refilled by the time of a clock
+func NewRateLimiterWithClock(capacity, refillRate int, clk clock.PassiveClock) *RateLimiter {
 	return &RateLimiter{
 		tokens:     capacity,
 		capacity:   capacity,
 		refillRate: refillRate,
-		lastRefill: time.Now(),
+		lastRefill: clk.Now(),
+		clock:      clk,
 	}
 }
 
@@ -41,7 +50,7 @@ func (rl *RateLimiter) Allow() bool {
 
 // refill adds tokens based on elapsed time
 func (rl *RateLimiter) refill() {
-	now := time.Now()
+	now := rl.clock.Now()
 	elapsed := now.Sub(rl.lastRefill)
 	tokensToAdd := int(elapsed.Seconds() * float64(rl.refillRate))
 
@@ -60,14 +69,21 @@ type ActionRateLimiter struct {
 	limiter     map[string]*RateLimiter
 	defaultRate int
 	defaultCap  int
+	clock       clock.PassiveClock
 }
 
 // NewActionRateLimiter creates a new action rate limiter
 func NewActionRateLimiter(defaultRate, defaultCap int) *ActionRateLimiter {
+	return NewActionRateLimiterWithClock(defaultRate, defaultCap, clock.RealClock{})
+}
+
+// NewActionRateLimiterWithClock creates a new action rate limiter refilled by the time of a clock
+func NewActionRateLimiterWithClock(defaultRate, defaultCap int, clk clock.PassiveClock) *ActionRateLimiter {
 	return &ActionRateLimiter{
 		limiter:     make(map[string]*RateLimiter),
 		defaultRate: defaultRate,
 		defaultCap:  defaultCap,
+		clock:       clk,
 	}
 }
 
@@ -81,7 +97,7 @@ func (arl *ActionRateLimiter) Allow(action string) bool {
 		arl.mu.Lock()
 		// Double-check after acquiring write lock
 		if limiter, exists = arl.limiter[action]; !exists {
-			limiter = NewRateLimiter(arl.defaultCap, arl.defaultRate)
+			limiter = NewRateLimiterWithClock(arl.defaultCap, arl.defaultRate, arl.clock)
 			arl.limiter[action] = limiter
 		}
 		arl.mu.Unlock()
@@ -95,7 +111,7 @@ func (arl *ActionRateLimiter) SetRate(action string, rate, capacity int) {
 	arl.mu.Lock()
 	defer arl.mu.Unlock()
 
-	arl.limiter[action] = NewRateLimiter(capacity, rate)
+	arl.limiter[action] = NewRateLimiterWithClock(capacity, rate, arl.clock)
 }
 
 // GetStats returns current stats for an action
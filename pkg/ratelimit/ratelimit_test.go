@@ -3,11 +3,14 @@ package ratelimit
 import (
 	"testing"
 	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestActionRateLimiter(t *testing.T) {
 	// Create a rate limiter: 2 requests per second, bucket capacity 5
-	rl := NewActionRateLimiter(2, 5)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	rl := NewActionRateLimiterWithClock(2, 5, clock)
 
 	if rl == nil {
 		t.Fatal("NewActionRateLimiter returned nil")
@@ -26,7 +29,7 @@ func TestActionRateLimiter(t *testing.T) {
 	}
 
 	// Wait for token refill (1 token per 0.5 seconds for 2 req/sec)
-	time.Sleep(600 * time.Millisecond)
+	clock.SetTime(clock.Now().Add(600 * time.Millisecond))
 
 	// Should allow one request now
 	if !rl.Allow("test-action") {
@@ -62,7 +65,8 @@ func TestMultipleActions(t *testing.T) {
 }
 
 func TestRateLimiterRefill(t *testing.T) {
-	rl := NewActionRateLimiter(10, 10) // 10 req/sec, capacity 10
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	rl := NewActionRateLimiterWithClock(10, 10, clock) // 10 req/sec, capacity 10
 
 	// Empty the bucket
 	for i := 0; i < 10; i++ {
@@ -75,7 +79,7 @@ func TestRateLimiterRefill(t *testing.T) {
 	}
 
 	// Wait for refill
-	time.Sleep(150 * time.Millisecond) // Should refill ~1.5 tokens
+	clock.SetTime(clock.Now().Add(150 * time.Millisecond)) // Should refill ~1.5 tokens
 
 	// Should allow at least one request
 	if !rl.Allow("test") {
@@ -118,7 +122,8 @@ func TestRateLimiterConcurrency(t *testing.T) {
 }
 
 func TestRateLimiterZeroRate(t *testing.T) {
-	rl := NewActionRateLimiter(0, 10) // Zero rate
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	rl := NewActionRateLimiterWithClock(0, 10, clock) // Zero rate
 
 	// Should allow initial capacity
 	for i := 0; i < 10; i++ {
@@ -128,21 +133,22 @@ func TestRateLimiterZeroRate(t *testing.T) {
 	}
 
 	// Should never allow more (no refill)
-	time.Sleep(100 * time.Millisecond)
+	clock.SetTime(clock.Now().Add(time.Hour))
 	if rl.Allow("test") {
 		t.Error("should never allow requests with zero rate")
 	}
 }
 
 func TestRateLimiterZeroCapacity(t *testing.T) {
-	rl := NewActionRateLimiter(10, 0) // Zero capacity
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	rl := NewActionRateLimiterWithClock(10, 0, clock) // Zero capacity
 
 	// Should never allow any requests
 	if rl.Allow("test") {
 		t.Error("should never allow requests with zero capacity")
 	}
 
-	time.Sleep(100 * time.Millisecond)
+	clock.SetTime(clock.Now().Add(time.Hour))
 	if rl.Allow("test") {
 		t.Error("should never allow requests even after time with zero capacity")
 	}
@@ -0,0 +1,160 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActionCleanupEvictedPods deletes the evicted and failed pods of a namespace
+const ActionCleanupEvictedPods = "cleanup-evicted-pods"
+
+// DefaultCleanupMaxPodsPerCycle bounds the pods cleanup-evicted-pods deletes per cycle
+const DefaultCleanupMaxPodsPerCycle = 50
+
+// ResetCleanupBudget starts a new cycle of cleanup-evicted-pods, which deletes
+// at most CleanupMaxPodsPerCycle pods across all namespaces per cycle
+func (e *Engine) ResetCleanupBudget() {
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+	e.cleanupDeleted = 0
+}
+
+// reserveCleanup takes up to n pods from the cleanup budget of the cycle and
+// returns how many may be deleted
+func (e *Engine) reserveCleanup(n int) int {
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+	n = max(min(n, e.cleanupBudget()-e.cleanupDeleted), 0)
+	e.cleanupDeleted += n
+	return n
+}
+
+// releaseCleanup returns pods that were reserved but not deleted to the budget
+func (e *Engine) releaseCleanup(n int) {
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+	e.cleanupDeleted = max(e.cleanupDeleted-n, 0)
+}
+
+// removablePods returns the failed pods of a namespace that failed at least
+// CleanupMinAge ago, the earliest failed first. Pods of Jobs are left to the Job.
+func (e *Engine) removablePods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	pods, err := e.clientFor(ctx).CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := e.config.Clock.Now()
+	var removable []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodFailed || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "Job" {
+			continue
+		}
+		if now.Sub(podFailedAt(&pod)) < e.config.CleanupMinAge {
+			continue
+		}
+		removable = append(removable, pod)
+	}
+	sort.SliceStable(removable, func(i, j int) bool {
+		return podFailedAt(&removable[i]).Before(podFailedAt(&removable[j]))
+	})
+	return removable, nil
+}
+
+// podFailedAt returns when the last container of a failed pod terminated, or
+// when the pod started if no container terminated
+func podFailedAt(pod *corev1.Pod) time.Time {
+	var failed time.Time
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(failed) {
+			failed = terminated.FinishedAt.Time
+		}
+	}
+	if failed.IsZero() && pod.Status.StartTime != nil {
+		failed = pod.Status.StartTime.Time
+	}
+	if failed.IsZero() {
+		failed = pod.CreationTimestamp.Time
+	}
+	return failed
+}
+
+// cleanupEvictedPods deletes the evicted and failed pods of a namespace, the
+// earliest failed first, within the per-cycle budget of CleanupMaxPodsPerCycle
+// pods; the remaining pods are deleted in the next cycles. Each pod is deleted
+// with a UID precondition, so a pod recreated with the same name is kept.
+func (e *Engine) cleanupEvictedPods(ctx context.Context, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	result := &Result{Action: ActionCleanupEvictedPods, Resource: namespace, Namespace: namespace, ExecutedAt: startTime}
+
+	pods, err := e.removablePods(ctx, namespace)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to list pods of namespace %s: %v", namespace, err)
+		return result, err
+	}
+	if len(pods) == 0 {
+		result.Success = true
+		result.Message = fmt.Sprintf("Namespace %s has no failed pods to delete", namespace)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would delete failed pods", "namespace", namespace, "pods", len(pods))
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would delete %d failed pods of namespace %s, at most %d per cycle%s", len(pods), namespace, e.cleanupBudget(), podList(pods))
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	reserved := e.reserveCleanup(len(pods))
+	if reserved == 0 {
+		result.Message = fmt.Sprintf("Cleanup of %d failed pods of namespace %s skipped: the budget of %d deleted pods per cycle is exhausted", len(pods), namespace, e.cleanupBudget())
+		result.Duration = time.Since(startTime)
+		result.Skipped = true
+		return result, nil
+	}
+
+	deleted := 0
+	for _, pod := range pods[:reserved] {
+		uid := pod.UID
+		err := e.clientFor(ctx).CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &uid},
+		})
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			e.releaseCleanup(reserved - deleted)
+			result.Duration = time.Since(startTime)
+			result.Message = fmt.Sprintf("Deleted %d failed pods of namespace %s, then failed to delete pod %s: %v", deleted, namespace, pod.Name, err)
+			return result, err
+		}
+		deleted++
+	}
+
+	result.Duration = time.Since(startTime)
+	result.Success = true
+	result.Message = fmt.Sprintf("Deleted %d failed pods of namespace %s", deleted, namespace)
+	if remaining := len(pods) - deleted; remaining > 0 {
+		result.Message += fmt.Sprintf(", %d left for later cycles", remaining)
+	}
+	logger.Info("Deleted failed pods", "namespace", namespace, "deleted", deleted, "remaining", len(pods)-deleted)
+	return result, nil
+}
+
+// cleanupBudget returns the pods cleanup-evicted-pods deletes per cycle
+func (e *Engine) cleanupBudget() int {
+	if e.config.CleanupMaxPodsPerCycle <= 0 {
+		return DefaultCleanupMaxPodsPerCycle
+	}
+	return e.config.CleanupMaxPodsPerCycle
+}
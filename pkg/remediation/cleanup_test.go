@@ -0,0 +1,111 @@
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// cleanupFixture returns a client holding failed pods of namespace shop that
+// failed 3, 2 and 1 hours ago, a failed Job pod and a recently failed pod
+func cleanupFixture() *fake.Clientset {
+	controller := true
+	pod := func(name string, age time.Duration, owner string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID(name)},
+			Status: corev1.PodStatus{
+				Phase:     corev1.PodFailed,
+				Reason:    "Evicted",
+				StartTime: &metav1.Time{Time: time.Now().Add(-age)},
+			},
+		}
+		if owner != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name, Controller: &controller}}
+		}
+		return pod
+	}
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-4", Namespace: "shop"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	return fake.NewSimpleClientset(
+		pod("web-2", 2*time.Hour, "ReplicaSet"),
+		pod("web-1", 3*time.Hour, "ReplicaSet"),
+		pod("web-3", time.Hour+time.Minute, "ReplicaSet"),
+		pod("web-new", time.Minute, "ReplicaSet"),
+		pod("migrate-1", 3*time.Hour, "Job"),
+		running,
+	)
+}
+
+// remainingPods returns the names of the pods left in namespace shop
+func remainingPods(t *testing.T, client *fake.Clientset) string {
+	t.Helper()
+	pods, err := client.CoreV1().Pods("shop").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestCleanupEvictedPods(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}
+
+	t.Run("deletes the earliest failed pods within the cycle budget", func(t *testing.T) {
+		client := cleanupFixture()
+		engine := NewEngine(client, RemediationConfig{Enabled: true, CleanupMaxPodsPerCycle: 2, CleanupMinAge: time.Hour})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCleanupEvictedPods, namespace, "shop")
+		if err != nil || !result.Success || !strings.Contains(result.Message, "Deleted 2 failed pods of namespace shop, 1 left") {
+			t.Fatalf("ExecuteAction() = %+v, %v, want 2 pods deleted", result, err)
+		}
+		if got := remainingPods(t, client); got != "migrate-1,web-3,web-4,web-new" {
+			t.Errorf("remaining pods = %s, want the Job, running, recent and latest failed pods", got)
+		}
+
+		if reserved := engine.reserveCleanup(1); reserved != 0 {
+			t.Errorf("reserveCleanup() = %d with the budget of the cycle spent", reserved)
+		}
+		engine.ResetCleanupBudget()
+		if reserved := engine.reserveCleanup(5); reserved != 2 {
+			t.Errorf("reserveCleanup() = %d after a new cycle, want 2", reserved)
+		}
+	})
+
+	t.Run("skips once the budget is exhausted", func(t *testing.T) {
+		client := cleanupFixture()
+		engine := NewEngine(client, RemediationConfig{Enabled: true, CleanupMaxPodsPerCycle: 1, CleanupMinAge: time.Hour})
+		engine.reserveCleanup(1)
+
+		result, err := engine.cleanupEvictedPods(context.Background(), "shop")
+		if err != nil || result.Success || !strings.Contains(result.Message, "exhausted") {
+			t.Fatalf("cleanupEvictedPods() = %+v, %v, want a skipped cleanup", result, err)
+		}
+		if got := remainingPods(t, client); strings.Count(got, ",") != 5 {
+			t.Errorf("remaining pods = %s, want all six", got)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		client := cleanupFixture()
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true, CleanupMinAge: time.Hour})
+
+		result, err := engine.cleanupEvictedPods(context.Background(), "shop")
+		if err != nil || !result.Success || !strings.Contains(result.Message, "would delete 3 failed pods") {
+			t.Fatalf("cleanupEvictedPods() = %+v, %v, want a dry run deleting 3 pods", result, err)
+		}
+		if got := remainingPods(t, client); strings.Count(got, ",") != 5 {
+			t.Errorf("dry run deleted pods, remaining %s", got)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrConcurrentChange is returned when a deployment was changed by someone else
+// after the action was decided on; the action is not applied so the change is
+// not overwritten
+var ErrConcurrentChange = errors.New("deployment changed concurrently")
+
+// patchDeployment applies a merge patch to the deployment as observed when the
+// action was decided on. The patch is conditional on the observed resourceVersion;
+// on a conflict the deployment is re-read and the patch retried as long as its
+// generation is unchanged, i.e. only its status or metadata changed. A changed
+// generation means its spec was edited, e.g. by a human or a GitOps sync, and
+// fails with ErrConcurrentChange.
+func (e *Engine) patchDeployment(ctx context.Context, observed *appsv1.Deployment, patch []byte) (*appsv1.Deployment, error) {
+	logger := log.FromContext(ctx)
+	deployments := e.clientFor(ctx).AppsV1().Deployments(observed.Namespace)
+
+	current := observed
+	var patched *appsv1.Deployment
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if current.Generation != observed.Generation {
+			return fmt.Errorf("%w: %s/%s is at generation %d, the action was decided at generation %d",
+				ErrConcurrentChange, observed.Namespace, observed.Name, current.Generation, observed.Generation)
+		}
+		conditional, err := withResourceVersion(patch, current.ResourceVersion)
+		if err != nil {
+			return err
+		}
+
+		patched, err = deployments.Patch(ctx, observed.Name, types.MergePatchType, conditional, metav1.PatchOptions{})
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		logger.V(1).Info("Deployment changed since it was read, re-reading it", "deployment", observed.Name, "namespace", observed.Namespace, "resourceVersion", current.ResourceVersion)
+		latest, getErr := deployments.Get(ctx, observed.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		current = latest
+		return err
+	})
+	if apierrors.IsConflict(err) {
+		return nil, fmt.Errorf("%w: %s/%s kept changing: %v", ErrConcurrentChange, observed.Namespace, observed.Name, err)
+	}
+	return patched, err
+}
+
+// withResourceVersion adds a resourceVersion precondition to a merge patch; an
+// empty resourceVersion leaves the patch unconditional
+func withResourceVersion(patch []byte, resourceVersion string) ([]byte, error) {
+	if resourceVersion == "" {
+		return patch, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(patch, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode patch: %w", err)
+	}
+	metadata, _ := fields["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["resourceVersion"] = resourceVersion
+	fields["metadata"] = metadata
+	return json.Marshal(fields)
+}
@@ -0,0 +1,115 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPatchDeploymentConcurrency(t *testing.T) {
+	newDeployment := func() *appsv1.Deployment {
+		replicas := int32(2)
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 3, ResourceVersion: "100"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+
+	// concurrentChange makes the first patch conflict with a change of the
+	// deployment and records the resourceVersion preconditions of all patches
+	concurrentChange := func(client *fake.Clientset, change func(*appsv1.Deployment)) *[]string {
+		var preconditions []string
+		client.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			var patch struct {
+				Metadata struct {
+					ResourceVersion string `json:"resourceVersion"`
+				} `json:"metadata"`
+			}
+			if err := json.Unmarshal(action.(k8stesting.PatchAction).GetPatch(), &patch); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+			preconditions = append(preconditions, patch.Metadata.ResourceVersion)
+			if len(preconditions) > 1 {
+				return false, nil, nil
+			}
+
+			changed := newDeployment()
+			changed.ResourceVersion = "101"
+			change(changed)
+			if err := client.Tracker().Update(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, changed, "default"); err != nil {
+				t.Fatal(err)
+			}
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web", errors.New("the object has been modified"))
+		})
+		return &preconditions
+	}
+
+	t.Run("status change is retried", func(t *testing.T) {
+		deployment := newDeployment()
+		client := fake.NewSimpleClientset(deployment)
+		preconditions := concurrentChange(client, func(d *appsv1.Deployment) { d.Status.ReadyReplicas = 1 })
+		engine := NewEngine(client, RemediationConfig{Enabled: true, AutoScaleEnabled: true})
+
+		result, err := engine.scaleDeployment(context.Background(), deployment)
+		if err != nil || !result.Success || result.Conflict {
+			t.Fatalf("scaleDeployment() = %+v, %v, want success", result, err)
+		}
+		if len(*preconditions) != 2 || (*preconditions)[0] != "100" || (*preconditions)[1] != "101" {
+			t.Errorf("patch preconditions = %v, want [100 101]", *preconditions)
+		}
+	})
+
+	t.Run("spec change is not overwritten", func(t *testing.T) {
+		deployment := newDeployment()
+		client := fake.NewSimpleClientset(deployment)
+		humanReplicas := int32(5)
+		preconditions := concurrentChange(client, func(d *appsv1.Deployment) {
+			d.Generation = 4
+			d.Spec.Replicas = &humanReplicas
+		})
+		engine := NewEngine(client, RemediationConfig{Enabled: true, AutoScaleEnabled: true})
+
+		result, err := engine.scaleDeployment(context.Background(), deployment)
+		if !errors.Is(err, ErrConcurrentChange) || result.Success || !result.Conflict {
+			t.Fatalf("scaleDeployment() = %+v, %v, want a conflict", result, err)
+		}
+		if len(*preconditions) != 1 {
+			t.Errorf("patched %d times, want once", len(*preconditions))
+		}
+		current, err := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *current.Spec.Replicas != humanReplicas {
+			t.Errorf("replicas = %d, want the concurrent change %d", *current.Spec.Replicas, humanReplicas)
+		}
+	})
+
+	t.Run("undo after a spec change is refused", func(t *testing.T) {
+		deployment := newDeployment()
+		client := fake.NewSimpleClientset(deployment)
+		engine := NewEngine(client, RemediationConfig{Enabled: true, AutoScaleEnabled: true})
+		id := engine.recordUndo(context.Background(), UndoRecord{
+			Action:        "scale-replicas",
+			Kind:          "Deployment",
+			Resource:      "web",
+			Namespace:     "default",
+			Generation:    2,
+			PriorReplicas: deployment.Spec.Replicas,
+		})
+
+		result, err := engine.Undo(context.Background(), id)
+		if !errors.Is(err, ErrConcurrentChange) || !result.Conflict {
+			t.Fatalf("Undo() = %+v, %v, want a conflict", result, err)
+		}
+	})
+}
@@ -0,0 +1,365 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Defaults of the restart-container action
+const (
+	// DefaultContainerRestartImage is the image of the ephemeral container that restarts a container
+	DefaultContainerRestartImage = "busybox:1.36"
+	// DefaultContainerRestartTimeout bounds the wait for the restarted container
+	DefaultContainerRestartTimeout = time.Minute
+)
+
+// nobodyUser runs the ephemeral container of a non-root target whose user is
+// only known to its image
+const nobodyUser int64 = 65534
+
+// containerRestartPollInterval is how often the pod is read while waiting for
+// the restarted container
+var containerRestartPollInterval = 2 * time.Second
+
+type containerKey struct{}
+
+// WithContainer returns a context carrying the container targeted by an action
+func WithContainer(ctx context.Context, container string) context.Context {
+	return context.WithValue(ctx, containerKey{}, container)
+}
+
+// ContainerFromContext returns the container targeted by an action, if any
+func ContainerFromContext(ctx context.Context) (string, bool) {
+	container, ok := ctx.Value(containerKey{}).(string)
+	return container, ok && container != ""
+}
+
+// restartableContainer returns the container of a pod that restart-container
+// targets, or an error explaining why it cannot be restarted in place. Regular
+// containers are restarted by the kubelet unless the pod restart policy is
+// Never; sidecars (init containers with restartPolicy Always) always are.
+func restartableContainer(pod *corev1.Pod, name string) (string, error) {
+	if name == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return "", fmt.Errorf("pod has %d containers and no container was given", len(pod.Spec.Containers))
+		}
+		name = pod.Spec.Containers[0].Name
+	}
+	if pod.Spec.ShareProcessNamespace != nil && *pod.Spec.ShareProcessNamespace {
+		return "", fmt.Errorf("pod shares its process namespace between containers")
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			if pod.Spec.RestartPolicy == corev1.RestartPolicyNever {
+				return "", fmt.Errorf("pod restart policy is Never")
+			}
+			return name, nil
+		}
+	}
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == name {
+			if container.RestartPolicy == nil || *container.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+				return "", fmt.Errorf("container %s is an init container", name)
+			}
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("container %s not found", name)
+}
+
+// restarterSecurityContext returns the security context of the ephemeral
+// container restarting a container. It runs as the user of the target, which it
+// must to signal its process, and meets the restricted Pod Security level
+// whenever the target does: no privilege escalation, no capabilities and the
+// runtime default seccomp profile.
+func restarterSecurityContext(pod *corev1.Pod, name string) *corev1.SecurityContext {
+	noEscalation := false
+	security := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &noEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	if podContext := pod.Spec.SecurityContext; podContext != nil {
+		security.RunAsUser = podContext.RunAsUser
+		security.RunAsGroup = podContext.RunAsGroup
+		security.RunAsNonRoot = podContext.RunAsNonRoot
+	}
+	for _, container := range slices.Concat(pod.Spec.Containers, pod.Spec.InitContainers) {
+		if container.Name != name || container.SecurityContext == nil {
+			continue
+		}
+		if container.SecurityContext.RunAsUser != nil {
+			security.RunAsUser = container.SecurityContext.RunAsUser
+		}
+		if container.SecurityContext.RunAsGroup != nil {
+			security.RunAsGroup = container.SecurityContext.RunAsGroup
+		}
+		if container.SecurityContext.RunAsNonRoot != nil {
+			security.RunAsNonRoot = container.SecurityContext.RunAsNonRoot
+		}
+	}
+
+	nonRoot := security.RunAsNonRoot != nil && *security.RunAsNonRoot
+	if security.RunAsUser != nil && *security.RunAsUser != 0 {
+		nonRoot = true
+		security.RunAsNonRoot = &nonRoot
+	}
+	if nonRoot && security.RunAsUser == nil {
+		// The image of the target sets its user, which the signal may then not
+		// reach; the restart is verified and the pod removed if it did not
+		user := nobodyUser
+		security.RunAsUser = &user
+	}
+	return security
+}
+
+// isPodNotFound returns true if err reports that the pod itself is gone, rather
+// than a subresource the cluster does not serve
+func isPodNotFound(err error, name string) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsNotFound(err) || !errors.As(err, &status) {
+		return false
+	}
+	details := status.Status().Details
+	return details != nil && details.Name == name
+}
+
+// restarterName returns a name for the ephemeral container restarting a
+// container of a pod that none of its ephemeral containers has yet
+func restarterName(pod *corev1.Pod, now time.Time) string {
+	base := fmt.Sprintf("kubeguardian-restart-%d", now.UnixNano())
+	name := base
+	taken := func(container corev1.EphemeralContainer) bool { return container.Name == name }
+	for i := 1; slices.ContainsFunc(pod.Spec.EphemeralContainers, taken); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// containerRestartCount returns the restart count of a container of a pod
+func containerRestartCount(pod *corev1.Pod, name string) (int32, bool) {
+	for _, status := range slices.Concat(pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses) {
+		if status.Name == name {
+			return status.RestartCount, true
+		}
+	}
+	return 0, false
+}
+
+// waitForContainerRestart waits until the restart count of a container of a pod
+// exceeds restarts, which proves the kubelet restarted it: PID 1 ignores SIGTERM
+// without a handler, so a delivered signal may have no effect
+func (e *Engine) waitForContainerRestart(ctx context.Context, pod *corev1.Pod, name string, restarts int32) error {
+	timeout := e.config.ContainerRestartTimeout
+	if timeout <= 0 {
+		timeout = DefaultContainerRestartTimeout
+	}
+	deadline := e.config.Clock.Now().Add(timeout)
+
+	for {
+		current, err := e.clientFor(ctx).CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod: %w", err)
+		}
+		if count, ok := containerRestartCount(current, name); ok && count > restarts {
+			return nil
+		}
+		if e.config.Clock.Now().After(deadline) {
+			return fmt.Errorf("container %s did not restart within %s", name, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(containerRestartPollInterval):
+		}
+	}
+}
+
+// restartContainer restarts a single container of a pod without deleting the pod.
+// An ephemeral container sharing the process namespace of the target container
+// sends SIGTERM to its main process, and the kubelet restarts the container. The
+// restart is verified with the restart count of the container; if it does not
+// go up, the pod is removed like restart-pod does instead.
+func (e *Engine) restartContainer(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := e.config.Clock.Now()
+
+	pod, ok := resource.(*corev1.Pod)
+	if !ok || pod == nil {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    "Resource is not a valid Pod",
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, fmt.Errorf("resource is not a valid Pod")
+	}
+
+	requested, _ := ContainerFromContext(ctx)
+	name, err := restartableContainer(pod, requested)
+	if err != nil {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    fmt.Sprintf("Container cannot be restarted in place: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, nil
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would restart container", "pod", pod.Name, "namespace", pod.Namespace, "container", name)
+		return &Result{
+			Action:     "restart-container",
+			Success:    true,
+			Message:    fmt.Sprintf("Dry run: would restart container %s of pod %s", name, pod.Name),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, nil
+	}
+
+	announced, err := e.announceRestart(ctx, pod.Namespace, pod)
+	if err != nil {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    fmt.Sprintf("Restart cancelled during the pre-remediation grace period: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, err
+	}
+	// The pod is kept, so the announcement is withdrawn once the container restarts
+	defer e.withdrawRestart(ctx, announced)
+
+	client := e.clientFor(ctx)
+	current, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    fmt.Sprintf("Failed to get pod: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, err
+	}
+
+	image := e.config.ContainerRestartImage
+	if image == "" {
+		image = DefaultContainerRestartImage
+	}
+	current.Spec.EphemeralContainers = append(current.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:            restarterName(current, e.config.Clock.Now()),
+			Image:           image,
+			Command:         []string{"kill", "-TERM", "1"},
+			SecurityContext: restarterSecurityContext(current, name),
+		},
+		TargetContainerName: name,
+	})
+
+	_, err = client.CoreV1().Pods(pod.Namespace).UpdateEphemeralContainers(ctx, pod.Name, current, metav1.UpdateOptions{})
+	if isPodNotFound(err, pod.Name) {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Skipped:    true,
+			Message:    fmt.Sprintf("Pod %s was deleted before its container could be restarted", pod.Name),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, nil
+	}
+	if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+		// The pods/ephemeralcontainers subresource is not served by the cluster
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    fmt.Sprintf("Container restarts are not supported by the cluster: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, nil
+	}
+	if err != nil {
+		return &Result{
+			Action:     "restart-container",
+			Success:    false,
+			Message:    fmt.Sprintf("Failed to restart container: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, err
+	}
+
+	restarts, _ := containerRestartCount(current, name)
+	if err := e.waitForContainerRestart(ctx, current, name, restarts); err != nil {
+		if ctx.Err() != nil {
+			return &Result{
+				Action:     "restart-container",
+				Success:    false,
+				Message:    fmt.Sprintf("Signaled container %s but stopped waiting for its restart: %v", name, err),
+				Resource:   pod.Name,
+				Namespace:  pod.Namespace,
+				ExecutedAt: e.config.Clock.Now(),
+				Duration:   e.config.Clock.Since(startTime),
+			}, err
+		}
+
+		logger.Info("Container did not restart after the signal, removing pod", "pod", pod.Name, "namespace", pod.Namespace, "container", name, "reason", err.Error())
+		method, removeErr := e.removePod(ctx, current)
+		if removeErr != nil {
+			return &Result{
+				Action:     "restart-container",
+				Success:    false,
+				Message:    fmt.Sprintf("Container %s did not restart (%v) and removing the pod failed: %v", name, err, removeErr),
+				Resource:   pod.Name,
+				Namespace:  pod.Namespace,
+				Method:     method,
+				ExecutedAt: e.config.Clock.Now(),
+				Duration:   e.config.Clock.Since(startTime),
+			}, removeErr
+		}
+		return &Result{
+			Action:     "restart-container",
+			Success:    true,
+			Message:    fmt.Sprintf("Container %s did not restart (%v), removed pod %s instead", name, err, pod.Name),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			Method:     method,
+			ExecutedAt: e.config.Clock.Now(),
+			Duration:   e.config.Clock.Since(startTime),
+		}, nil
+	}
+
+	logger.Info("Successfully restarted container", "pod", pod.Name, "namespace", pod.Namespace, "container", name)
+	return &Result{
+		Action:     "restart-container",
+		Success:    true,
+		Message:    fmt.Sprintf("Successfully restarted container %s of pod %s", name, pod.Name),
+		Resource:   pod.Name,
+		Namespace:  pod.Namespace,
+		ExecutedAt: e.config.Clock.Now(),
+		Duration:   e.config.Clock.Since(startTime),
+	}, nil
+}
@@ -0,0 +1,265 @@
+package remediation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// restartOnSignal makes the fake client act like a kubelet restarting the
+// container targeted by each ephemeral container added to a pod
+func restartOnSignal(client *fake.Clientset) {
+	client.PrependReactor("update", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "ephemeralcontainers" {
+			return false, nil, nil
+		}
+		pod := action.(k8stesting.UpdateAction).GetObject().(*corev1.Pod).DeepCopy()
+		target := pod.Spec.EphemeralContainers[len(pod.Spec.EphemeralContainers)-1].TargetContainerName
+		for i := range pod.Status.ContainerStatuses {
+			if pod.Status.ContainerStatuses[i].Name == target {
+				pod.Status.ContainerStatuses[i].RestartCount++
+			}
+		}
+		err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("pods"), pod, pod.Namespace)
+		return true, pod, err
+	})
+}
+
+func TestRestartableContainer(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	shared := true
+	pod := func(mutate func(*corev1.Pod)) *corev1.Pod {
+		p := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyAlways,
+				InitContainers: []corev1.Container{
+					{Name: "migrate"},
+					{Name: "proxy", RestartPolicy: &always},
+				},
+				Containers: []corev1.Container{{Name: "app"}, {Name: "log-shipper"}},
+			},
+		}
+		if mutate != nil {
+			mutate(p)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name      string
+		pod       *corev1.Pod
+		container string
+		want      string
+		wantErr   bool
+	}{
+		{"regular container", pod(nil), "log-shipper", "log-shipper", false},
+		{"sidecar", pod(nil), "proxy", "proxy", false},
+		{"init container", pod(nil), "migrate", "", true},
+		{"unknown container", pod(nil), "db", "", true},
+		{"ambiguous", pod(nil), "", "", true},
+		{"single container", pod(func(p *corev1.Pod) { p.Spec.Containers = p.Spec.Containers[:1] }), "", "app", false},
+		{"restart policy never", pod(func(p *corev1.Pod) { p.Spec.RestartPolicy = corev1.RestartPolicyNever }), "app", "", true},
+		{"sidecar with restart policy never", pod(func(p *corev1.Pod) { p.Spec.RestartPolicy = corev1.RestartPolicyNever }), "proxy", "proxy", false},
+		{"shared process namespace", pod(func(p *corev1.Pod) { p.Spec.ShareProcessNamespace = &shared }), "app", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := restartableContainer(tt.pod, tt.container)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restartableContainer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("restartableContainer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestartContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyAlways,
+			Containers:    []corev1.Container{{Name: "app"}, {Name: "log-shipper"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app"}, {Name: "log-shipper", RestartCount: 3}},
+		},
+	}
+	client := fake.NewSimpleClientset(pod)
+	restartOnSignal(client)
+	engine := NewEngine(client, RemediationConfig{Enabled: true, CooldownSeconds: 300})
+
+	ctx := WithContainer(context.Background(), "log-shipper")
+	result, err := engine.ExecuteAction(ctx, "restart-container", pod, "default")
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+	}
+
+	updated, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if len(updated.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("ephemeral containers = %d, want 1", len(updated.Spec.EphemeralContainers))
+	}
+	restarter := updated.Spec.EphemeralContainers[0]
+	if restarter.TargetContainerName != "log-shipper" || restarter.Image != DefaultContainerRestartImage {
+		t.Errorf("ephemeral container = %+v, want target log-shipper and image %s", restarter, DefaultContainerRestartImage)
+	}
+	if result.Method != "" {
+		t.Errorf("method = %q, want the pod kept", result.Method)
+	}
+
+	// The cooldown is tracked per container
+	if result, _ := engine.ExecuteAction(ctx, "restart-container", pod, "default"); result.Success {
+		t.Errorf("second restart of log-shipper succeeded, want cooldown")
+	}
+	if result, err := engine.ExecuteAction(WithContainer(context.Background(), "app"), "restart-container", pod, "default"); err != nil || !result.Success {
+		t.Errorf("restart of app failed: %v (%v)", err, result)
+	}
+}
+
+func TestRestartContainerUpdateNotFound(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantSkipped bool
+	}{
+		{"pod deleted", apierrors.NewNotFound(corev1.Resource("pods"), "web-1"), true},
+		{"subresource not served", apierrors.NewGenericServerResponse(http.StatusNotFound, "put", corev1.Resource("pods"), "", "", 0, false), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+				Spec:       corev1.PodSpec{RestartPolicy: corev1.RestartPolicyAlways, Containers: []corev1.Container{{Name: "app"}}},
+			}
+			client := fake.NewSimpleClientset(pod)
+			client.PrependReactor("update", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return action.GetSubresource() == "ephemeralcontainers", nil, tt.err
+			})
+			engine := NewEngine(client, RemediationConfig{Enabled: true})
+
+			result, err := engine.ExecuteAction(context.Background(), "restart-container", pod, "default")
+			if err != nil || result.Success {
+				t.Fatalf("ExecuteAction() = %+v, %v, want a failed result without error", result, err)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Errorf("skipped = %t, want %t: %s", result.Skipped, tt.wantSkipped, result.Message)
+			}
+			if !tt.wantSkipped && !strings.Contains(result.Message, "not supported") {
+				t.Errorf("message = %q, want restarts reported as not supported", result.Message)
+			}
+		})
+	}
+}
+
+func TestRestartContainerRemovesPodWithoutRestart(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyAlways,
+			Containers:    []corev1.Container{{Name: "app"}},
+		},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app"}}},
+	}
+	// PID 1 of the container ignores the signal, so it never restarts; every read
+	// of the pod takes longer than the restart timeout
+	client := fake.NewSimpleClientset(pod)
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	client.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		clock.SetTime(clock.Now().Add(2 * time.Minute))
+		return false, nil, nil
+	})
+	engine := NewEngine(client, RemediationConfig{
+		Enabled:                 true,
+		ContainerRestartTimeout: time.Minute,
+		RestartMethod:           RestartMethodDelete,
+		Clock:                   clock,
+	})
+
+	result, err := engine.ExecuteAction(context.Background(), "restart-container", pod, "default")
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+	}
+	if result.Method != RestartMethodDelete {
+		t.Errorf("method = %q, want %s", result.Method, RestartMethodDelete)
+	}
+	if !result.ExecutedAt.After(time.Now()) {
+		t.Errorf("executed at %s, want the time of the engine clock", result.ExecutedAt)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Get() error = %v, want the pod removed", err)
+	}
+}
+
+func TestRestarterName(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	pod := &corev1.Pod{}
+	first := restarterName(pod, now)
+
+	// A restart within the same instant, or a retry, gets another name
+	pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: first}}}
+	second := restarterName(pod, now)
+	if second == first {
+		t.Errorf("restarterName() = %q again, want a name not used by the pod", second)
+	}
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: second}})
+	if third := restarterName(pod, now); third == first || third == second {
+		t.Errorf("restarterName() = %q, want a name not used by the pod", third)
+	}
+}
+
+func TestRestarterSecurityContext(t *testing.T) {
+	user, root, nobody := int64(1000), int64(0), nobodyUser
+	nonRoot := true
+
+	tests := []struct {
+		name        string
+		pod         corev1.PodSecurityContext
+		container   *corev1.SecurityContext
+		wantUser    *int64
+		wantNonRoot bool
+	}{
+		{"root target", corev1.PodSecurityContext{}, nil, nil, false},
+		{"pod user", corev1.PodSecurityContext{RunAsUser: &user}, nil, &user, true},
+		{"container user", corev1.PodSecurityContext{RunAsUser: &root}, &corev1.SecurityContext{RunAsUser: &user}, &user, true},
+		{"user of the image", corev1.PodSecurityContext{RunAsNonRoot: &nonRoot}, nil, &nobody, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{
+				SecurityContext: &tt.pod,
+				Containers:      []corev1.Container{{Name: "app", SecurityContext: tt.container}},
+			}}
+
+			got := restarterSecurityContext(pod, "app")
+			if got.AllowPrivilegeEscalation == nil || *got.AllowPrivilegeEscalation {
+				t.Error("privilege escalation should be disallowed")
+			}
+			if got.Capabilities == nil || len(got.Capabilities.Drop) != 1 || got.Capabilities.Drop[0] != "ALL" {
+				t.Errorf("capabilities = %+v, want all dropped", got.Capabilities)
+			}
+			if (got.RunAsUser == nil) != (tt.wantUser == nil) || (got.RunAsUser != nil && *got.RunAsUser != *tt.wantUser) {
+				t.Errorf("runAsUser = %v, want %v", got.RunAsUser, tt.wantUser)
+			}
+			if (got.RunAsNonRoot != nil && *got.RunAsNonRoot) != tt.wantNonRoot {
+				t.Errorf("runAsNonRoot = %v, want %t", got.RunAsNonRoot, tt.wantNonRoot)
+			}
+		})
+	}
+}
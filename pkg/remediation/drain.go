@@ -0,0 +1,268 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// ActionCordonDrainNode cordons a node and evicts its pods
+const ActionCordonDrainNode = "cordon-drain-node"
+
+// Defaults of node drains
+const (
+	DefaultDrainTimeout         = 5 * time.Minute
+	DefaultDrainMaxNodesPerHour = 1
+)
+
+// annotationMirrorPod marks static pods mirrored by the kubelet, which cannot be evicted
+const annotationMirrorPod = "kubernetes.io/config.mirror"
+
+// DrainsConfigMapKey is the data key of the state ConfigMap holding the drain
+// budget, next to the condition state
+const DrainsConfigMapKey = "drains.json"
+
+// DrainBackend persists the drains started per hour window, keyed by the start of
+// the window, so the budget survives restarts and leader failovers
+type DrainBackend = detection.Backend[map[string]int]
+
+// reserveDrain takes a node from the drain budget of the current hour window,
+// returning false if the budget is exhausted. Only dry runs pass reserve false, to
+// check the budget. A budget that cannot be read or written refuses the drain.
+func (e *Engine) reserveDrain(ctx context.Context, reserve bool) (bool, error) {
+	budget := e.config.DrainMaxNodesPerHour
+	if budget <= 0 {
+		budget = DefaultDrainMaxNodesPerHour
+	}
+
+	e.drainsMu.Lock()
+	defer e.drainsMu.Unlock()
+	if e.config.DrainBackend != nil {
+		drains, err := e.config.DrainBackend.Load(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to load the drain budget: %w", err)
+		}
+		e.drains = drains
+	}
+
+	window := e.config.Clock.Now().UTC().Truncate(time.Hour).Format(time.RFC3339)
+	drained := e.drains[window]
+	if drained >= budget {
+		return false, nil
+	}
+	if !reserve {
+		return true, nil
+	}
+
+	// Earlier windows are dropped, only the current one counts
+	e.drains = map[string]int{window: drained + 1}
+	if e.config.DrainBackend != nil {
+		if err := e.config.DrainBackend.Save(ctx, e.drains); err != nil {
+			return false, fmt.Errorf("failed to persist the drain budget: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// drainablePods returns the pods of a node a drain evicts: pods of DaemonSets,
+// mirror pods and pods that have terminated stay on the node
+func (e *Engine) drainablePods(ctx context.Context, node string) ([]corev1.Pod, error) {
+	pods, err := e.clientFor(ctx).CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var drainable []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != node || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if _, mirror := pod.Annotations[annotationMirrorPod]; mirror {
+			continue
+		}
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+			continue
+		}
+		drainable = append(drainable, pod)
+	}
+	return drainable, nil
+}
+
+// cordonDrainNode cordons a node and evicts its pods through policy/v1, so
+// PodDisruptionBudgets are honored: evictions a budget refuses are retried every
+// EvictionRetryInterval until DrainTimeout expires, and the action succeeds once
+// the evicted pods are gone. At most DrainMaxNodesPerHour nodes are drained per
+// hour window, counted in DrainBackend if set; further drains are skipped. The
+// node stays cordoned if the drain fails.
+func (e *Engine) cordonDrainNode(ctx context.Context, resource interface{}) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	result := &Result{Action: ActionCordonDrainNode, ExecutedAt: startTime}
+
+	node, ok := resource.(*corev1.Node)
+	if !ok || node == nil {
+		result.Message = "Resource is not a valid Node"
+		return result, fmt.Errorf("resource is not a valid Node")
+	}
+	result.Resource = node.Name
+
+	reserved, err := e.reserveDrain(ctx, !e.config.DryRun)
+	if err != nil {
+		result.Message = fmt.Sprintf("Drain of node %s refused: %v", node.Name, err)
+		return result, err
+	}
+	if !reserved {
+		budget := e.config.DrainMaxNodesPerHour
+		if budget <= 0 {
+			budget = DefaultDrainMaxNodesPerHour
+		}
+		result.Message = fmt.Sprintf("Drain of node %s skipped: the budget of %d drained nodes per hour is exhausted", node.Name, budget)
+		result.Skipped = true
+		return result, nil
+	}
+
+	pods, err := e.drainablePods(ctx, node.Name)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to list pods of node %s: %v", node.Name, err)
+		return result, err
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would cordon and drain node", "node", node.Name, "pods", len(pods))
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would cordon node %s and evict %d pods%s", node.Name, len(pods), podList(pods))
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if !node.Spec.Unschedulable {
+		if cordoned, err := e.cordonNode(ctx, node); err != nil {
+			result.Message = cordoned.Message
+			result.Duration = time.Since(startTime)
+			return result, err
+		}
+	}
+
+	err = e.drainPods(ctx, node.Name, pods)
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		result.Message = fmt.Sprintf("Cordoned node %s but failed to drain it: %v", node.Name, err)
+		return result, err
+	}
+
+	logger.Info("Cordoned and drained node", "node", node.Name, "pods", len(pods))
+	result.Success = true
+	result.Message = fmt.Sprintf("Cordoned node %s and evicted %d pods", node.Name, len(pods))
+	return result, nil
+}
+
+// drainPods evicts pods of a node and waits until they are gone
+func (e *Engine) drainPods(ctx context.Context, node string, pods []corev1.Pod) error {
+	logger := log.FromContext(ctx)
+
+	timeout := e.config.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	interval := e.config.EvictionRetryInterval
+	if interval <= 0 {
+		interval = DefaultEvictionRetryInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	options := metav1.DeleteOptions{}
+	if e.config.DrainGracePeriod > 0 {
+		seconds := int64(e.config.DrainGracePeriod / time.Second)
+		options.GracePeriodSeconds = &seconds
+	}
+
+	pending := pods
+	for {
+		var blocked []corev1.Pod
+		for i := range pending {
+			pod := &pending[i]
+			err := e.evictPod(ctx, pod, options)
+			switch {
+			case err == nil, apierrors.IsNotFound(err):
+			case apierrors.IsTooManyRequests(err):
+				blocked = append(blocked, *pod)
+			default:
+				return fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		if len(blocked) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w for %s: %d pods%s", errEvictionBlocked, timeout, len(blocked), podList(blocked))
+		}
+
+		logger.V(1).Info("Evictions blocked by PodDisruptionBudgets, retrying", "node", node, "pods", len(blocked))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		pending = blocked
+	}
+
+	// Evicted pods terminate within their grace period
+	for {
+		remaining, err := e.drainablePods(ctx, node)
+		if err != nil {
+			return err
+		}
+		remaining = evictedPods(remaining, pods)
+		if len(remaining) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d evicted pods still running after %s%s", len(remaining), timeout, podList(remaining))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// evictedPods returns the pods of a node that are among the evicted pods;
+// pods placed on the node since, e.g. tolerating the cordon, are not waited for
+func evictedPods(pods, evicted []corev1.Pod) []corev1.Pod {
+	uids := make(map[string]bool, len(evicted))
+	for _, pod := range evicted {
+		uids[string(pod.UID)] = true
+	}
+	var remaining []corev1.Pod
+	for _, pod := range pods {
+		if uids[string(pod.UID)] {
+			remaining = append(remaining, pod)
+		}
+	}
+	return remaining
+}
+
+// podList formats the names of pods for result messages, e.g. " (shop/web-1, shop/web-2)"
+func podList(pods []corev1.Pod) string {
+	if len(pods) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Namespace+"/"+pod.Name)
+	}
+	return " (" + strings.Join(names, ", ") + ")"
+}
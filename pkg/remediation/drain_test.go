@@ -0,0 +1,144 @@
+package remediation
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// drainFixture returns a node with a web pod, a DaemonSet pod, a mirror pod and
+// a completed pod, and a client whose evictions delete pods unless blocked
+func drainFixture(blocked map[string]bool) (*corev1.Node, *fake.Clientset, *[]string) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-3"}}
+	pod := func(name string, modify func(*corev1.Pod)) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID(name)},
+			Spec:       corev1.PodSpec{NodeName: "worker-3"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if modify != nil {
+			modify(pod)
+		}
+		return pod
+	}
+	controller := true
+	client := fake.NewSimpleClientset(node,
+		pod("web-1", nil),
+		pod("api-1", nil),
+		pod("other-node", func(p *corev1.Pod) { p.Spec.NodeName = "worker-4" }),
+		pod("log-agent", func(p *corev1.Pod) {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "log-agent", Controller: &controller}}
+		}),
+		pod("static", func(p *corev1.Pod) { p.Annotations = map[string]string{annotationMirrorPod: "hash"} }),
+		pod("job-1", func(p *corev1.Pod) { p.Status.Phase = corev1.PodSucceeded }),
+	)
+
+	var evicted []string
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		name := action.(k8stesting.CreateAction).GetObject().(metav1.Object).GetName()
+		if blocked[name] {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		evicted = append(evicted, name)
+		return true, nil, client.Tracker().Delete(corev1.SchemeGroupVersion.WithResource("pods"), "shop", name)
+	})
+	return node, client, &evicted
+}
+
+func TestCordonDrainNode(t *testing.T) {
+	t.Run("evicts the pods of the node", func(t *testing.T) {
+		node, client, evicted := drainFixture(nil)
+		engine := NewEngine(client, RemediationConfig{Enabled: true})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCordonDrainNode, node, "")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() = %+v, %v, want success", result, err)
+		}
+		if strings.Join(*evicted, ",") != "web-1,api-1" && strings.Join(*evicted, ",") != "api-1,web-1" {
+			t.Errorf("evicted %v, want web-1 and api-1", *evicted)
+		}
+		updated, _ := client.CoreV1().Nodes().Get(context.Background(), node.Name, metav1.GetOptions{})
+		if !updated.Spec.Unschedulable {
+			t.Error("node was not cordoned")
+		}
+	})
+
+	t.Run("times out on a blocking PodDisruptionBudget", func(t *testing.T) {
+		node, client, evicted := drainFixture(map[string]bool{"web-1": true})
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DrainTimeout: 30 * time.Millisecond, EvictionRetryInterval: 10 * time.Millisecond})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCordonDrainNode, node, "")
+		if err == nil || result.Success || !strings.Contains(result.Message, "shop/web-1") {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a drain blocked on web-1", result, err)
+		}
+		if len(*evicted) != 1 || (*evicted)[0] != "api-1" {
+			t.Errorf("evicted %v, want api-1", *evicted)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		node, client, evicted := drainFixture(nil)
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCordonDrainNode, node, "")
+		if err != nil || !result.Success || !strings.Contains(result.Message, "evict 2 pods") {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a dry run evicting 2 pods", result, err)
+		}
+		updated, _ := client.CoreV1().Nodes().Get(context.Background(), node.Name, metav1.GetOptions{})
+		if len(*evicted) != 0 || updated.Spec.Unschedulable {
+			t.Errorf("dry run changed the node or evicted %v", *evicted)
+		}
+	})
+
+	t.Run("hourly budget", func(t *testing.T) {
+		start := time.Now()
+		clock := clocktesting.NewFakePassiveClock(start)
+		engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, DrainMaxNodesPerHour: 2, Clock: clock})
+
+		for i, want := range []bool{true, true, false} {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-" + string(rune('a'+i))}}
+			if _, err := engine.client.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			result, err := engine.ExecuteAction(context.Background(), ActionCordonDrainNode, node, "")
+			if err != nil || result.Success != want {
+				t.Errorf("drain %d = %+v, %v, want success %v", i+1, result, err, want)
+			}
+		}
+
+		clock.SetTime(start.Add(time.Hour))
+		if reserved, err := engine.reserveDrain(context.Background(), false); err != nil || !reserved {
+			t.Errorf("budget not restored after an hour: %v", err)
+		}
+	})
+
+	t.Run("persisted budget", func(t *testing.T) {
+		clock := clocktesting.NewFakePassiveClock(time.Now())
+		backend := detection.NewFileValueBackend[map[string]int](filepath.Join(t.TempDir(), "drains.json"))
+		config := RemediationConfig{Enabled: true, DrainMaxNodesPerHour: 1, DrainBackend: backend, Clock: clock}
+
+		if reserved, err := NewEngine(fake.NewSimpleClientset(), config).reserveDrain(context.Background(), true); err != nil || !reserved {
+			t.Fatalf("reserveDrain() = %v, %v, want the first drain reserved", reserved, err)
+		}
+		// A restarted engine, or the next leader, shares the budget of the hour
+		if reserved, err := NewEngine(fake.NewSimpleClientset(), config).reserveDrain(context.Background(), true); err != nil || reserved {
+			t.Errorf("reserveDrain() after a restart = %v, %v, want the budget exhausted", reserved, err)
+		}
+	})
+}
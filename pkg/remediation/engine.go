@@ -2,7 +2,11 @@ package remediation
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -10,10 +14,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/NotHarshhaa/kubeguardian/pkg/circuitbreaker"
 	"github.com/NotHarshhaa/kubeguardian/pkg/metrics"
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
 	"github.com/NotHarshhaa/kubeguardian/pkg/ratelimit"
 )
 
@@ -21,10 +27,23 @@ import (
 type Engine struct {
 	client         kubernetes.Interface
 	config         RemediationConfig
-	cooldowns      map[string]CooldownEntry // Key: "namespace:resource:action"
+	cooldownsMu    sync.Mutex
+	cooldowns      map[string]CooldownEntry // Key: "namespace:resource:action", guarded by cooldownsMu
 	circuitBreaker map[string]*circuitbreaker.CircuitBreaker
 	rateLimiter    *ratelimit.ActionRateLimiter
 	metrics        *metrics.Metrics
+
+	namespacesMu    sync.RWMutex      // Guards config.Namespaces, replaced by SetNamespaces
+	disabledActions map[string]string // Key: action, value: reason
+	impersonate     ClientFactory
+	undo            *undoLog
+	executor        Executor
+
+	drainsMu sync.Mutex
+	drains   map[string]int // Drains started per hour window, guarded by drainsMu
+
+	cleanupMu      sync.Mutex
+	cleanupDeleted int // Pods deleted by cleanup-evicted-pods this cycle, guarded by cleanupMu
 }
 
 // RemediationConfig contains remediation configuration
@@ -37,6 +56,58 @@ type RemediationConfig struct {
 	AutoScaleEnabled    bool                                  `yaml:"autoScaleEnabled"`
 	CooldownSeconds     int                                   `yaml:"cooldownSeconds"`
 	Namespaces          map[string]NamespaceRemediationConfig `yaml:"namespaces"`
+	FailureDomainAware  bool                                  `yaml:"failureDomainAware"`
+	TopologyKey         string                                `yaml:"topologyKey"`
+	// ContainerRestartImage is the image of the ephemeral container used by restart-container
+	ContainerRestartImage string `yaml:"containerRestartImage"`
+	// ContainerRestartTimeout bounds the wait for the restarted container to come
+	// back, after which the pod is removed instead
+	ContainerRestartTimeout time.Duration `yaml:"containerRestartTimeout"`
+	// RollingRestartTimeout bounds the wait for the replacement of each pod deleted by
+	// rolling-restart-pods, which checks readiness every RollingRestartInterval
+	RollingRestartTimeout  time.Duration `yaml:"rollingRestartTimeout"`
+	RollingRestartInterval time.Duration `yaml:"rollingRestartInterval"`
+	// RestartMethod is how restart-pod removes pods: evict (default) or delete. An
+	// eviction a PodDisruptionBudget blocks is retried every EvictionRetryInterval
+	// for EvictionTimeout, then the pod is deleted if EvictionFallback is set.
+	RestartMethod         string        `yaml:"restartMethod"`
+	EvictionTimeout       time.Duration `yaml:"evictionTimeout"`
+	EvictionRetryInterval time.Duration `yaml:"evictionRetryInterval"`
+	EvictionFallback      bool          `yaml:"evictionFallback"`
+	// HandshakeEnabled sets HandshakeAnnotation on pods and waits HandshakeGracePeriod
+	// before disrupting them, in namespaces without namespace-specific settings
+	HandshakeEnabled     bool          `yaml:"handshakeEnabled"`
+	HandshakeAnnotation  string        `yaml:"handshakeAnnotation"`
+	HandshakeGracePeriod time.Duration `yaml:"handshakeGracePeriod"`
+	// DrainTimeout bounds cordon-drain-node, which evicts the pods of a node with
+	// DrainGracePeriod, or their own grace period if zero, and drains at most
+	// DrainMaxNodesPerHour nodes per hour
+	DrainTimeout         time.Duration `yaml:"drainTimeout"`
+	DrainGracePeriod     time.Duration `yaml:"drainGracePeriod"`
+	DrainMaxNodesPerHour int           `yaml:"drainMaxNodesPerHour"`
+	// DrainBackend persists the drain budget; nil keeps it in memory only
+	DrainBackend DrainBackend `yaml:"-"`
+	// CleanupMaxPodsPerCycle bounds the pods cleanup-evicted-pods deletes per
+	// cycle across all namespaces; it deletes failed pods that failed at least
+	// CleanupMinAge ago
+	CleanupMaxPodsPerCycle int           `yaml:"cleanupMaxPodsPerCycle"`
+	CleanupMinAge          time.Duration `yaml:"cleanupMinAge"`
+	// PreChecks overrides DefaultPreChecks per action; the policy check always
+	// runs. MaxAffectedPods is the most pods the blast-radius check lets an
+	// action disrupt; zero is unlimited.
+	PreChecks       map[string][]string `yaml:"preChecks"`
+	MaxAffectedPods int                 `yaml:"maxAffectedPods"`
+	// Finalizers are the finalizers remove-finalizer may remove; no other
+	// finalizer is ever removed
+	Finalizers []AllowedFinalizer `yaml:"-"`
+	// Plugins are actions implemented by external programs
+	Plugins []Plugin `yaml:"-"`
+	// StrippedActions are never executed, whichever rule or request names them
+	StrippedActions []string `yaml:"-"`
+	// Overrides bounds workload annotation overrides; nil ignores the annotations
+	Overrides *overrides.Bounds `yaml:"-"`
+	// Clock times cooldowns, circuit breakers and rate limits; nil is the real clock
+	Clock clock.PassiveClock `yaml:"-"`
 }
 
 // NamespaceRemediationConfig contains namespace-specific remediation settings
@@ -47,6 +118,11 @@ type NamespaceRemediationConfig struct {
 	MaxRetries          int           `yaml:"maxRetries"`
 	RetryInterval       time.Duration `yaml:"retryInterval"`
 	CooldownSeconds     int           `yaml:"cooldownSeconds"`
+	// HandshakeEnabled announces disruptive actions to the pods of the namespace
+	HandshakeEnabled bool `yaml:"handshakeEnabled"`
+	// ForceDeleteEnabled lets force-delete-pod delete pods of the namespace stuck
+	// terminating; it is never enabled for namespaces without settings
+	ForceDeleteEnabled bool `yaml:"forceDeleteEnabled"`
 }
 
 // Action represents a remediation action
@@ -66,6 +142,32 @@ type Result struct {
 	Namespace  string        `yaml:"namespace"`
 	ExecutedAt time.Time     `yaml:"executedAt"`
 	Duration   time.Duration `yaml:"duration"`
+
+	RequestedBy string `yaml:"requestedBy,omitempty"`
+	UndoID      string `yaml:"undoID,omitempty"` // Set for reversible actions
+
+	// Method is how restart-pod, or restart-container falling back to it, removed
+	// the pod: evict or delete
+	Method string `yaml:"method,omitempty"`
+
+	// PatchType and Patch are the patch a dry-run action would have submitted
+	PatchType types.PatchType `yaml:"patchType,omitempty"`
+	Patch     string          `yaml:"patch,omitempty"`
+
+	// Conflict is set when the action was not applied because the resource was
+	// changed by someone else after the action was decided on
+	Conflict bool `yaml:"conflict,omitempty"`
+
+	// PendingApproval is set when the action was not applied because it must be
+	// requested through the action API
+	PendingApproval bool `yaml:"pendingApproval,omitempty"`
+
+	// Skipped is set when the action was deliberately not applied, e.g. during its
+	// cooldown or because a pre-check refused it; nothing failed
+	Skipped bool `yaml:"skipped,omitempty"`
+
+	// PreChecks are the outcomes of the pre-checks the action passed through
+	PreChecks []PreCheckResult `yaml:"preChecks,omitempty"`
 }
 
 // CooldownEntry tracks the last remediation time for a resource-action pair
@@ -77,39 +179,77 @@ type CooldownEntry struct {
 
 // NewEngine creates a new remediation engine
 func NewEngine(client kubernetes.Interface, config RemediationConfig) *Engine {
+	if config.Clock == nil {
+		config.Clock = clock.RealClock{}
+	}
+
 	// Create circuit breakers for different API operations
 	circuitBreakers := make(map[string]*circuitbreaker.CircuitBreaker)
 	circuitBreakers["pods"] = circuitbreaker.NewCircuitBreaker("pods-api", circuitbreaker.Config{
 		MaxRequests: 5,
 		Interval:    60 * time.Second,
 		Timeout:     30 * time.Second,
+		Clock:       config.Clock,
 	})
 	circuitBreakers["deployments"] = circuitbreaker.NewCircuitBreaker("deployments-api", circuitbreaker.Config{
 		MaxRequests: 3,
 		Interval:    60 * time.Second,
 		Timeout:     30 * time.Second,
+		Clock:       config.Clock,
 	})
 	circuitBreakers["replicasets"] = circuitbreaker.NewCircuitBreaker("replicasets-api", circuitbreaker.Config{
 		MaxRequests: 3,
 		Interval:    60 * time.Second,
 		Timeout:     30 * time.Second,
+		Clock:       config.Clock,
 	})
 
 	// Create rate limiter
-	rateLimiter := ratelimit.NewActionRateLimiter(10, 100) // 10 actions/sec, 100 bucket capacity
-
-	return &Engine{
-		client:         client,
-		config:         config,
-		cooldowns:      make(map[string]CooldownEntry),
-		circuitBreaker: circuitBreakers,
-		rateLimiter:    rateLimiter,
+	rateLimiter := ratelimit.NewActionRateLimiterWithClock(10, 100, config.Clock) // 10 actions/sec, 100 bucket capacity
+
+	engine := &Engine{
+		client:          client,
+		config:          config,
+		cooldowns:       make(map[string]CooldownEntry),
+		circuitBreaker:  circuitBreakers,
+		rateLimiter:     rateLimiter,
+		disabledActions: make(map[string]string),
+		undo:            newUndoLog(),
 	}
+	engine.executor = clientExecutor{engine: engine}
+	return engine
+}
+
+// DisableAction prevents an action from being executed, e.g. when required permissions are missing
+func (e *Engine) DisableAction(action, reason string) {
+	e.disabledActions[action] = reason
+}
+
+// IsActionStripped returns true if the cluster action policy strips an action
+func (e *Engine) IsActionStripped(action string) bool {
+	return slices.Contains(e.config.StrippedActions, action)
+}
+
+// IsActionDisabled returns true and the reason if an action has been disabled
+func (e *Engine) IsActionDisabled(action string) (bool, string) {
+	reason, disabled := e.disabledActions[action]
+	return disabled, reason
+}
+
+// SetNamespaces replaces the namespace-specific configuration, e.g. after namespace
+// selectors have been expanded
+func (e *Engine) SetNamespaces(namespaces map[string]NamespaceRemediationConfig) {
+	e.namespacesMu.Lock()
+	defer e.namespacesMu.Unlock()
+	e.config.Namespaces = namespaces
 }
 
 // GetNamespaceConfig returns the namespace-specific remediation configuration, falling back to defaults
 func (e *Engine) GetNamespaceConfig(namespace string) NamespaceRemediationConfig {
-	if nsConfig, exists := e.config.Namespaces[namespace]; exists {
+	e.namespacesMu.RLock()
+	nsConfig, exists := e.config.Namespaces[namespace]
+	e.namespacesMu.RUnlock()
+	if exists {
 		return nsConfig
 	}
 
@@ -121,6 +261,7 @@ func (e *Engine) GetNamespaceConfig(namespace string) NamespaceRemediationConfig
 		MaxRetries:          e.config.MaxRetries,
 		RetryInterval:       e.config.RetryInterval,
 		CooldownSeconds:     e.config.CooldownSeconds,
+		HandshakeEnabled:    e.config.HandshakeEnabled,
 	}
 }
 
@@ -137,12 +278,82 @@ func (e *Engine) ExecuteAction(ctx context.Context, action string, resource inte
 			Success:    false,
 			Message:    "Remediation is disabled for this namespace",
 			ExecutedAt: time.Now(),
+			Skipped:    true,
 		}, nil
 	}
 
 	// Get resource name for cooldown tracking
 	resourceName := e.getResourceName(resource)
 	cooldownKey := fmt.Sprintf("%s:%s:%s", namespace, resourceName, action)
+	if container, ok := ContainerFromContext(ctx); ok {
+		cooldownKey += ":" + container
+	}
+	nsConfig.CooldownSeconds = e.workloadCooldown(ctx, resource, nsConfig.CooldownSeconds)
+	if cooldown, ok := cooldownFromContext(ctx); ok {
+		nsConfig.CooldownSeconds = cooldown
+	}
+
+	// Stripped actions are refused for automatic and manual remediation alike
+	if e.IsActionStripped(action) {
+		logger.Info("Action skipped because the action policy strips it",
+			"action", action,
+			"resource", resourceName,
+			"namespace", namespace)
+		return &Result{
+			Action:     action,
+			Success:    false,
+			Message:    "Action is stripped by the cluster action policy",
+			Resource:   resourceName,
+			Namespace:  namespace,
+			ExecutedAt: time.Now(),
+			Skipped:    true,
+		}, nil
+	}
+
+	// Workloads opted out with their annotations refuse automatic and manual actions
+	if reason := e.workloadOptOut(ctx, resource, action); reason != "" {
+		logger.Info("Action skipped because the workload opted out",
+			"action", action,
+			"resource", resourceName,
+			"namespace", namespace,
+			"reason", reason)
+		return &Result{
+			Action:     action,
+			Success:    false,
+			Message:    reason,
+			Resource:   resourceName,
+			Namespace:  namespace,
+			ExecutedAt: time.Now(),
+			Skipped:    true,
+		}, nil
+	}
+
+	// Check if action has been disabled
+	if disabled, reason := e.IsActionDisabled(action); disabled {
+		logger.Info("Action skipped because it is disabled",
+			"action", action,
+			"resource", resourceName,
+			"namespace", namespace,
+			"reason", reason)
+		return &Result{
+			Action:     action,
+			Success:    false,
+			Message:    fmt.Sprintf("Action is disabled: %s", reason),
+			Resource:   resourceName,
+			Namespace:  namespace,
+			ExecutedAt: time.Now(),
+			Skipped:    true,
+		}, nil
+	}
+
+	// Actions that need approval are only executed when requested
+	if result := e.pendingApproval(ctx, action, resource, namespace); result != nil {
+		logger.Info("Action held for approval",
+			"action", action,
+			"resource", resourceName,
+			"namespace", namespace)
+		return result, nil
+	}
 
 	// Check if action is in cooldown period
 	if e.isInCooldown(cooldownKey, nsConfig.CooldownSeconds) {
@@ -158,37 +369,106 @@ func (e *Engine) ExecuteAction(ctx context.Context, action string, resource inte
 			Resource:   resourceName,
 			Namespace:  namespace,
 			ExecutedAt: time.Now(),
+			Skipped:    true,
+		}, nil
+	}
+
+	// Every action passes through its pre-checks, run with the engine's own client
+	checks, failed := e.runPreChecks(ctx, action, resource, namespace)
+	if failed != nil {
+		logger.Info("Action skipped because a pre-check failed",
+			"action", action,
+			"resource", resourceName,
+			"namespace", namespace,
+			"preCheck", failed.Name,
+			"reason", failed.Message)
+		return &Result{
+			Action:     action,
+			Success:    false,
+			Message:    fmt.Sprintf("Pre-check %s failed: %s", failed.Name, failed.Message),
+			Resource:   resourceName,
+			Namespace:  namespace,
+			ExecutedAt: time.Now(),
+			PreChecks:  checks,
+			Skipped:    true,
 		}, nil
 	}
 
 	startTime := time.Now()
 
+	// Manually requested actions run as the requesting user when impersonation is configured
+	requester, manual := RequesterFromContext(ctx)
+	if manual && e.impersonate != nil {
+		client, err := e.impersonate(requester)
+		if err != nil {
+			return &Result{
+				Action:      action,
+				Success:     false,
+				Message:     fmt.Sprintf("Failed to impersonate %s: %v", requester.User, err),
+				Resource:    resourceName,
+				Namespace:   namespace,
+				ExecutedAt:  time.Now(),
+				Duration:    time.Since(startTime),
+				RequestedBy: requester.User,
+				PreChecks:   checks,
+			}, err
+		}
+		ctx = withClient(ctx, client)
+	}
+
+	result, err := e.executeAction(ctx, action, resource, namespace, cooldownKey)
+	if result != nil {
+		result.PreChecks = checks
+		if manual {
+			result.RequestedBy = requester.User
+		}
+	}
+	return result, err
+}
+
+// executeAction executes an action with the configured executor and records the cooldown on success
+func (e *Engine) executeAction(ctx context.Context, action string, resource interface{}, namespace, cooldownKey string) (*Result, error) {
+	result, err := e.executor.Execute(ctx, action, resource, namespace)
+	if err == nil && result != nil && result.Success {
+		e.recordCooldown(cooldownKey)
+	}
+	return result, err
+}
+
+// apply dispatches an action to its implementation, which applies it through the Kubernetes API
+func (e *Engine) apply(ctx context.Context, action string, resource interface{}, namespace string) (*Result, error) {
 	switch action {
 	case "restart-pod":
-		result, err := e.restartPod(ctx, resource, namespace)
-		if err == nil && result.Success {
-			e.recordCooldown(cooldownKey)
-		}
-		return result, err
+		return e.restartPod(ctx, resource, namespace)
+	case "restart-container":
+		return e.restartContainer(ctx, resource, namespace)
+	case "rolling-restart-pods":
+		return e.rollingRestartPods(ctx, resource, namespace)
+	case "restart-deployment":
+		return e.restartDeployment(ctx, resource, namespace)
 	case "rollback-deployment":
-		result, err := e.rollbackDeployment(ctx, resource, namespace)
-		if err == nil && result.Success {
-			e.recordCooldown(cooldownKey)
-		}
-		return result, err
+		return e.rollbackDeployment(ctx, resource, namespace)
 	case "scale-replicas":
-		result, err := e.scaleReplicas(ctx, resource, namespace)
-		if err == nil && result.Success {
-			e.recordCooldown(cooldownKey)
-		}
-		return result, err
+		return e.scaleReplicas(ctx, resource, namespace)
+	case ActionRemoveFinalizer:
+		return e.removeFinalizer(ctx, resource, namespace)
+	case ActionCordonNode:
+		return e.cordonNode(ctx, resource)
+	case ActionCordonDrainNode:
+		return e.cordonDrainNode(ctx, resource)
+	case ActionCleanupEvictedPods:
+		return e.cleanupEvictedPods(ctx, namespace)
+	case ActionForceDeletePod:
+		return e.forceDeletePod(ctx, resource, namespace)
 	default:
+		if plugin, found := e.plugin(action); found {
+			return e.runPlugin(ctx, plugin, resource, namespace)
+		}
 		return &Result{
 			Action:     action,
 			Success:    false,
 			Message:    fmt.Sprintf("Unknown action: %s", action),
 			ExecutedAt: time.Now(),
-			Duration:   time.Since(startTime),
 		}, fmt.Errorf("unknown action: %s", action)
 	}
 }
@@ -217,33 +497,121 @@ func (e *Engine) getResourceName(resource interface{}) string {
 	return "unknown"
 }
 
+// workloadCooldown returns the cooldown of a workload, which may be overridden by
+// its annotation within the configured bounds
+func (e *Engine) workloadCooldown(ctx context.Context, resource interface{}, cooldownSeconds int) int {
+	obj, ok := resource.(metav1.Object)
+	if !ok || e.config.Overrides == nil {
+		return cooldownSeconds
+	}
+
+	cooldown, ok, err := overrides.Duration(obj.GetAnnotations(), overrides.AnnotationCooldown, e.config.Overrides.Cooldown)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring workload override", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return cooldownSeconds
+	}
+	if !ok {
+		return cooldownSeconds
+	}
+	return int(cooldown / time.Second)
+}
+
+// workloadOptOut returns why a workload refuses an action with its disable or
+// actions annotation, or an empty string if it allows it
+func (e *Engine) workloadOptOut(ctx context.Context, resource interface{}, action string) string {
+	obj, ok := resource.(metav1.Object)
+	if !ok || e.config.Overrides == nil || !e.config.Overrides.OptOut {
+		return ""
+	}
+
+	annotations := obj.GetAnnotations()
+	if disabled, err := overrides.Disabled(annotations); err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring workload override", "namespace", obj.GetNamespace(), "name", obj.GetName())
+	} else if disabled {
+		return fmt.Sprintf("Workload opted out of remediation with the %s annotation", overrides.AnnotationDisable)
+	}
+	if allowed, ok := overrides.Actions(annotations); ok && !slices.Contains(allowed, action) {
+		return fmt.Sprintf("Action is not allowed by the %s annotation of the workload", overrides.AnnotationActions)
+	}
+	return ""
+}
+
+// cooldownOverrideKey is the context key of a cooldown replacing the configured one
+type cooldownOverrideKey struct{}
+
+// WithCooldown returns a context replacing the cooldown of an action, including
+// workload overrides, e.g. while an incident relaxes cooldowns
+func WithCooldown(ctx context.Context, seconds int) context.Context {
+	return context.WithValue(ctx, cooldownOverrideKey{}, seconds)
+}
+
+// cooldownFromContext returns the cooldown set with WithCooldown, if any
+func cooldownFromContext(ctx context.Context) (int, bool) {
+	seconds, ok := ctx.Value(cooldownOverrideKey{}).(int)
+	return seconds, ok
+}
+
 // isInCooldown checks if an action is currently in cooldown period
 func (e *Engine) isInCooldown(cooldownKey string, cooldownSeconds int) bool {
 	if cooldownSeconds <= 0 {
 		return false // Cooldown disabled
 	}
 
+	e.cooldownsMu.Lock()
 	entry, exists := e.cooldowns[cooldownKey]
+	e.cooldownsMu.Unlock()
 	if !exists {
 		return false // No previous action recorded
 	}
 
 	// Check if cooldown period has passed
 	cooldownDuration := time.Duration(cooldownSeconds) * time.Second
-	return time.Since(entry.LastAction) < cooldownDuration
+	return e.config.Clock.Since(entry.LastAction) < cooldownDuration
 }
 
 // recordCooldown records the timestamp of a successful remediation action
 func (e *Engine) recordCooldown(cooldownKey string) {
+	e.cooldownsMu.Lock()
+	defer e.cooldownsMu.Unlock()
 	e.cooldowns[cooldownKey] = CooldownEntry{
 		ResourceKey: cooldownKey,
-		LastAction:  time.Now(),
+		LastAction:  e.config.Clock.Now(),
+	}
+}
+
+// Cooldowns returns the times of the latest remediation actions of resources
+func (e *Engine) Cooldowns() []CooldownEntry {
+	e.cooldownsMu.Lock()
+	defer e.cooldownsMu.Unlock()
+
+	entries := make([]CooldownEntry, 0, len(e.cooldowns))
+	for _, entry := range e.cooldowns {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ResourceKey < entries[j].ResourceKey })
+	return entries
+}
+
+// MergeCooldowns adds cooldown entries, e.g. imported from another instance;
+// the later action time of a resource is kept
+func (e *Engine) MergeCooldowns(entries []CooldownEntry) {
+	e.cooldownsMu.Lock()
+	defer e.cooldownsMu.Unlock()
+
+	for _, entry := range entries {
+		if current, exists := e.cooldowns[entry.ResourceKey]; exists && current.LastAction.After(entry.LastAction) {
+			continue
+		}
+		e.cooldowns[entry.ResourceKey] = entry
 	}
 }
 
 // CleanupCooldowns removes expired cooldown entries to prevent memory leaks
 func (e *Engine) CleanupCooldowns() {
-	now := time.Now()
+	e.cooldownsMu.Lock()
+	defer e.cooldownsMu.Unlock()
+
+	now := e.config.Clock.Now()
 	for key, entry := range e.cooldowns {
 		// Remove entries older than 1 hour to prevent memory buildup
 		if now.Sub(entry.LastAction) > time.Hour {
@@ -252,6 +620,15 @@ func (e *Engine) CleanupCooldowns() {
 	}
 }
 
+// CircuitBreakers returns the circuit breakers guarding the API operations of actions
+func (e *Engine) CircuitBreakers() []*circuitbreaker.CircuitBreaker {
+	breakers := make([]*circuitbreaker.CircuitBreaker, 0, len(e.circuitBreaker))
+	for _, breaker := range e.circuitBreaker {
+		breakers = append(breakers, breaker)
+	}
+	return breakers
+}
+
 // restartPod restarts a pod by deleting it
 func (e *Engine) restartPod(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
 	logger := log.FromContext(ctx)
@@ -291,15 +668,36 @@ func (e *Engine) restartPod(ctx context.Context, resource interface{}, namespace
 		}, nil
 	}
 
-	// Use propagation policy to ensure graceful deletion
-	deleteOptions := metav1.DeleteOptions{
-		PropagationPolicy: func() *metav1.DeletionPropagation {
-			policy := metav1.DeletePropagationForeground
-			return &policy
-		}(),
+	announced, err := e.announceRestart(ctx, pod.Namespace, pod)
+	if err != nil {
+		return &Result{
+			Action:     "restart-pod",
+			Success:    false,
+			Message:    fmt.Sprintf("Restart cancelled during the pre-remediation grace period: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, err
 	}
 
-	err := e.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, deleteOptions)
+	method, err := e.removePod(ctx, pod)
+	if err != nil {
+		e.withdrawRestart(ctx, announced)
+	}
+	if errors.Is(err, errEvictionBlocked) {
+		logger.Info("Refusing to delete pod whose eviction is blocked", "pod", pod.Name, "namespace", pod.Namespace)
+		return &Result{
+			Action:     "restart-pod",
+			Success:    false,
+			Message:    fmt.Sprintf("Restart refused: %v", err),
+			Resource:   pod.Name,
+			Namespace:  pod.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+			Method:     method,
+		}, nil
+	}
 	if err != nil {
 		return &Result{
 			Action:     "restart-pod",
@@ -309,18 +707,20 @@ func (e *Engine) restartPod(ctx context.Context, resource interface{}, namespace
 			Namespace:  pod.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
+			Method:     method,
 		}, err
 	}
 
-	logger.Info("Successfully restarted pod", "pod", pod.Name, "namespace", pod.Namespace)
+	logger.Info("Successfully restarted pod", "pod", pod.Name, "namespace", pod.Namespace, "method", method)
 	return &Result{
 		Action:     "restart-pod",
 		Success:    true,
-		Message:    fmt.Sprintf("Successfully restarted pod %s", pod.Name),
+		Message:    fmt.Sprintf("Successfully restarted pod %s (%s)", pod.Name, method),
 		Resource:   pod.Name,
 		Namespace:  pod.Namespace,
 		ExecutedAt: time.Now(),
 		Duration:   time.Since(startTime),
+		Method:     method,
 	}, nil
 }
 
@@ -329,9 +729,6 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 	logger := log.FromContext(ctx)
 	startTime := time.Now()
 
-	// Get namespace-specific configuration
-	nsConfig := e.GetNamespaceConfig(namespace)
-
 	if resource == nil {
 		return &Result{
 			Action:     "rollback-deployment",
@@ -353,20 +750,25 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 		}, fmt.Errorf("resource is not a valid Deployment")
 	}
 
-	if !nsConfig.AutoRollbackEnabled {
+	// The rollback patch does not depend on the current revision
+	var previousRevision int64 = 1
+	patch, err := buildMergePatch(ctx, nil, map[string]string{
+		"deployment.kubernetes.io/revision": fmt.Sprintf("%d", previousRevision),
+	})
+	if err != nil {
 		return &Result{
 			Action:     "rollback-deployment",
 			Success:    false,
-			Message:    "Auto rollback is disabled for this namespace",
+			Message:    fmt.Sprintf("Failed to build rollback patch: %v", err),
 			Resource:   deployment.Name,
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
-		}, nil
+		}, err
 	}
 
 	if e.config.DryRun {
-		logger.Info("Dry run: would rollback deployment", "deployment", deployment.Name, "namespace", deployment.Namespace)
+		logger.Info("Dry run: would rollback deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "patch", string(patch))
 		return &Result{
 			Action:     "rollback-deployment",
 			Success:    true,
@@ -375,11 +777,13 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
+			PatchType:  types.MergePatchType,
+			Patch:      string(patch),
 		}, nil
 	}
 
 	// Get the current deployment to check revision
-	currentDeployment, err := e.client.AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
+	currentDeployment, err := e.clientFor(ctx).AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
 	if err != nil {
 		return &Result{
 			Action:     "rollback-deployment",
@@ -400,7 +804,6 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 
 	// For simplicity, we'll rollback to revision 1 if current revision > 1
 	// In a real implementation, you'd maintain revision history
-	var previousRevision int64 = 1
 	if currentRevision == "1" {
 		return &Result{
 			Action:     "rollback-deployment",
@@ -413,9 +816,9 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 		}, fmt.Errorf("no previous revision found")
 	}
 
-	// Create a rollback annotation
-	patch := fmt.Sprintf(`{"metadata":{"annotations":{"deployment.kubernetes.io/revision":"%d"}}}`, previousRevision)
-	_, err = e.client.AppsV1().Deployments(deployment.Namespace).Patch(ctx, deployment.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	// Roll back only the deployment the issue was detected on, not a newer
+	// version someone rolled out since
+	_, err = e.patchDeployment(ctx, deployment, patch)
 	if err != nil {
 		return &Result{
 			Action:     "rollback-deployment",
@@ -425,10 +828,19 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
+			Conflict:   errors.Is(err, ErrConcurrentChange),
 		}, err
 	}
 
-	logger.Info("Successfully rolled back deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "revision", previousRevision)
+	undoID := e.recordUndo(ctx, UndoRecord{
+		Action:           "rollback-deployment",
+		Kind:             "Deployment",
+		Resource:         deployment.Name,
+		Namespace:        deployment.Namespace,
+		PriorAnnotations: priorAnnotations(currentDeployment.Annotations, "deployment.kubernetes.io/revision"),
+	})
+
+	logger.Info("Successfully rolled back deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "revision", previousRevision, "undoID", undoID)
 	return &Result{
 		Action:     "rollback-deployment",
 		Success:    true,
@@ -437,6 +849,7 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 		Namespace:  deployment.Namespace,
 		ExecutedAt: time.Now(),
 		Duration:   time.Since(startTime),
+		UndoID:     undoID,
 	}, nil
 }
 
@@ -444,19 +857,6 @@ func (e *Engine) rollbackDeployment(ctx context.Context, resource interface{}, n
 func (e *Engine) scaleReplicas(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
 	startTime := time.Now()
 
-	// Get namespace-specific configuration
-	nsConfig := e.GetNamespaceConfig(namespace)
-
-	if !nsConfig.AutoScaleEnabled {
-		return &Result{
-			Action:     "scale-replicas",
-			Success:    false,
-			Message:    "Auto scaling is disabled for this namespace",
-			ExecutedAt: time.Now(),
-			Duration:   time.Since(startTime),
-		}, nil
-	}
-
 	switch r := resource.(type) {
 	case *corev1.Pod:
 		return e.scalePodDeployment(ctx, r)
@@ -491,7 +891,7 @@ func (e *Engine) scalePodDeployment(ctx context.Context, pod *corev1.Pod) (*Resu
 	for _, ownerRef := range pod.OwnerReferences {
 		if ownerRef.Kind == "ReplicaSet" {
 			// Get the replicaset to find its owner deployment
-			replicaSet, err := e.client.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, ownerRef.Name, metav1.GetOptions{})
+			replicaSet, err := e.clientFor(ctx).AppsV1().ReplicaSets(pod.Namespace).Get(ctx, ownerRef.Name, metav1.GetOptions{})
 			if err != nil {
 				return &Result{
 					Action:     "scale-replicas",
@@ -507,7 +907,7 @@ func (e *Engine) scalePodDeployment(ctx context.Context, pod *corev1.Pod) (*Resu
 			for _, rsOwnerRef := range replicaSet.OwnerReferences {
 				if rsOwnerRef.Kind == "Deployment" {
 					// Get the actual deployment to ensure we have correct spec
-					deployment, err := e.client.AppsV1().Deployments(pod.Namespace).Get(ctx, rsOwnerRef.Name, metav1.GetOptions{})
+					deployment, err := e.clientFor(ctx).AppsV1().Deployments(pod.Namespace).Get(ctx, rsOwnerRef.Name, metav1.GetOptions{})
 					if err != nil {
 						return &Result{
 							Action:     "scale-replicas",
@@ -536,6 +936,15 @@ func (e *Engine) scalePodDeployment(ctx context.Context, pod *corev1.Pod) (*Resu
 	}, fmt.Errorf("could not find owning deployment for pod")
 }
 
+// maxScaledReplicas is the most replicas scale-replicas scales a deployment to
+const maxScaledReplicas = 10
+
+// scaledReplicas returns the replicas scale-replicas scales a deployment to:
+// 50% more or 2 more, whichever is larger, up to maxScaledReplicas
+func scaledReplicas(current int32) int32 {
+	return min(current+max(current/2, 2), max(current, maxScaledReplicas))
+}
+
 // scaleDeployment scales a deployment by increasing replicas
 func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deployment) (*Result, error) {
 	logger := log.FromContext(ctx)
@@ -552,7 +961,7 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 	}
 
 	// Get the current deployment
-	currentDeployment, err := e.client.AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
+	currentDeployment, err := e.clientFor(ctx).AppsV1().Deployments(deployment.Namespace).Get(ctx, deployment.Name, metav1.GetOptions{})
 	if err != nil {
 		return &Result{
 			Action:     "scale-replicas",
@@ -565,19 +974,13 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 		}, err
 	}
 
-	// Increase replicas by 50% or add 2, whichever is smaller
-	currentReplicas := int32(1)
-	if currentDeployment.Spec.Replicas != nil {
-		currentReplicas = *currentDeployment.Spec.Replicas
-	}
-
 	// Set reasonable limits to prevent excessive scaling
-	maxReplicas := int32(10)
-	if currentReplicas >= maxReplicas {
+	currentReplicas := deploymentReplicas(currentDeployment)
+	if currentReplicas >= maxScaledReplicas {
 		return &Result{
 			Action:     "scale-replicas",
 			Success:    false,
-			Message:    fmt.Sprintf("Deployment already at maximum replicas (%d)", maxReplicas),
+			Message:    fmt.Sprintf("Deployment already at maximum replicas (%d)", maxScaledReplicas),
 			Resource:   deployment.Name,
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
@@ -585,18 +988,24 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 		}, fmt.Errorf("deployment already at maximum replicas")
 	}
 
-	increase := currentReplicas / 2
-	if increase < 2 {
-		increase = 2
-	}
+	newReplicas := scaledReplicas(currentReplicas)
 
-	newReplicas := currentReplicas + increase
-	if newReplicas > maxReplicas {
-		newReplicas = maxReplicas
+	// Scale the deployment
+	patch, err := buildMergePatch(ctx, map[string]interface{}{"replicas": newReplicas}, nil)
+	if err != nil {
+		return &Result{
+			Action:     "scale-replicas",
+			Success:    false,
+			Message:    fmt.Sprintf("Failed to build scale patch: %v", err),
+			Resource:   deployment.Name,
+			Namespace:  deployment.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, err
 	}
 
 	if e.config.DryRun {
-		logger.Info("Dry run: would scale deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "from", currentReplicas, "to", newReplicas)
+		logger.Info("Dry run: would scale deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "from", currentReplicas, "to", newReplicas, "patch", string(patch))
 		return &Result{
 			Action:     "scale-replicas",
 			Success:    true,
@@ -605,12 +1014,13 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
+			PatchType:  types.MergePatchType,
+			Patch:      string(patch),
 		}, nil
 	}
-
-	// Scale the deployment
-	patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, newReplicas)
-	_, err = e.client.AppsV1().Deployments(deployment.Namespace).Patch(ctx, deployment.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	// The new replica count is based on the current one, so the patch must not
+	// apply to a deployment scaled or edited since the issue was detected
+	patched, err := e.patchDeployment(ctx, deployment, patch)
 	if err != nil {
 		return &Result{
 			Action:     "scale-replicas",
@@ -620,10 +1030,20 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 			Namespace:  deployment.Namespace,
 			ExecutedAt: time.Now(),
 			Duration:   time.Since(startTime),
+			Conflict:   errors.Is(err, ErrConcurrentChange),
 		}, err
 	}
 
-	logger.Info("Successfully scaled deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "from", currentReplicas, "to", newReplicas)
+	undoID := e.recordUndo(ctx, UndoRecord{
+		Action:        "scale-replicas",
+		Kind:          "Deployment",
+		Resource:      deployment.Name,
+		Namespace:     deployment.Namespace,
+		Generation:    patched.Generation,
+		PriorReplicas: &currentReplicas,
+	})
+
+	logger.Info("Successfully scaled deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "from", currentReplicas, "to", newReplicas, "undoID", undoID)
 	return &Result{
 		Action:     "scale-replicas",
 		Success:    true,
@@ -632,5 +1052,6 @@ func (e *Engine) scaleDeployment(ctx context.Context, deployment *appsv1.Deploym
 		Namespace:  deployment.Namespace,
 		ExecutedAt: time.Now(),
 		Duration:   time.Since(startTime),
+		UndoID:     undoID,
 	}, nil
 }
@@ -0,0 +1,154 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/overrides"
+)
+
+func TestWorkloadCooldown(t *testing.T) {
+	bounds := &overrides.Bounds{Cooldown: overrides.DurationBounds{Min: time.Minute, Max: time.Hour}}
+	engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Overrides: bounds})
+
+	tests := []struct {
+		name       string
+		annotation string
+		want       int
+	}{
+		{"no annotation", "", 300},
+		{"override", "15m", 900},
+		{"clamped to min", "0s", 60},
+		{"clamped to max", "2h", 3600},
+		{"invalid", "later", 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+			if tt.annotation != "" {
+				pod.Annotations = map[string]string{overrides.AnnotationCooldown: tt.annotation}
+			}
+			if got := engine.workloadCooldown(context.Background(), pod, 300); got != tt.want {
+				t.Errorf("workloadCooldown() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkloadOptOut(t *testing.T) {
+	engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, Overrides: &overrides.Bounds{OptOut: true}})
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		refused     bool
+	}{
+		{"no annotations", nil, false},
+		{"disabled", map[string]string{overrides.AnnotationDisable: "true"}, true},
+		{"enabled", map[string]string{overrides.AnnotationDisable: "false"}, false},
+		{"invalid", map[string]string{overrides.AnnotationDisable: "maybe"}, false},
+		{"action allowed", map[string]string{overrides.AnnotationActions: "restart-pod, rollback-deployment"}, false},
+		{"action not allowed", map[string]string{overrides.AnnotationActions: "rollback-deployment"}, true},
+		{"no actions allowed", map[string]string{overrides.AnnotationActions: ""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Annotations: tt.annotations}}
+			if reason := engine.workloadOptOut(context.Background(), pod, "restart-pod"); (reason != "") != tt.refused {
+				t.Errorf("workloadOptOut() = %q, want refused %v", reason, tt.refused)
+			}
+		})
+	}
+
+	result, err := engine.ExecuteAction(context.Background(), "restart-pod", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web-1",
+		Namespace:   "default",
+		Annotations: map[string]string{overrides.AnnotationDisable: "true"},
+	}}, "default")
+	if err != nil || result.Success {
+		t.Errorf("ExecuteAction() = %v, %v, want refused", result, err)
+	}
+}
+
+func TestDryRunIncludesPatch(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	client := fake.NewSimpleClientset(deployment)
+	engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true})
+
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Source: "api"})
+	result, err := engine.scaleDeployment(ctx, deployment)
+	if err != nil || !result.Success {
+		t.Fatalf("scaleDeployment() failed: %v (%v)", err, result)
+	}
+	want := `{"metadata":{"annotations":{"kubeguardian.io/request-source":"api","kubeguardian.io/requested-by":"alice"}},"spec":{"replicas":4}}`
+	if result.PatchType != types.MergePatchType || result.Patch != want {
+		t.Errorf("patch = %s %s, want %s %s", result.PatchType, result.Patch, types.MergePatchType, want)
+	}
+
+	// Nothing is submitted in dry-run mode
+	current, _ := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if *current.Spec.Replicas != 2 {
+		t.Errorf("replicas = %d, want unchanged 2", *current.Spec.Replicas)
+	}
+}
+
+func TestCooldownClock(t *testing.T) {
+	clock := clocktesting.NewFakePassiveClock(time.Now())
+	engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, Clock: clock})
+
+	key := "default:web-1:restart-pod"
+	engine.recordCooldown(key)
+	if !engine.isInCooldown(key, 300) {
+		t.Error("action not in cooldown right after it was recorded")
+	}
+
+	clock.SetTime(clock.Now().Add(5 * time.Minute))
+	if engine.isInCooldown(key, 300) {
+		t.Error("action still in cooldown after the cooldown period")
+	}
+
+	// Entries are kept for an hour
+	engine.CleanupCooldowns()
+	if len(engine.cooldowns) != 1 {
+		t.Errorf("cooldowns = %d after cleanup, want the recent entry kept", len(engine.cooldowns))
+	}
+	clock.SetTime(clock.Now().Add(time.Hour))
+	engine.CleanupCooldowns()
+	if len(engine.cooldowns) != 0 {
+		t.Errorf("cooldowns = %d after cleanup, want the expired entry removed", len(engine.cooldowns))
+	}
+}
+
+func TestStrippedActions(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := fake.NewSimpleClientset(pod)
+	engine := NewEngine(client, RemediationConfig{Enabled: true, StrippedActions: []string{"restart-pod"}})
+
+	if !engine.IsActionStripped("restart-pod") || engine.IsActionStripped("rollback-deployment") {
+		t.Error("IsActionStripped() does not match the stripped actions")
+	}
+
+	// Manually requested actions are refused as well
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Source: "api"})
+	result, err := engine.ExecuteAction(ctx, "restart-pod", pod, "default")
+	if err != nil || result.Success || result.Message != "Action is stripped by the cluster action policy" {
+		t.Fatalf("ExecuteAction() = %+v, %v, want a refused action", result, err)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("pod was deleted: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Methods restart-pod uses to remove a pod
+const (
+	// RestartMethodEvict evicts the pod through policy/v1, honoring PodDisruptionBudgets
+	RestartMethodEvict = "evict"
+	// RestartMethodDelete deletes the pod
+	RestartMethodDelete = "delete"
+)
+
+// Defaults of pod eviction
+const (
+	DefaultEvictionTimeout       = 2 * time.Minute
+	DefaultEvictionRetryInterval = 10 * time.Second
+)
+
+// errEvictionBlocked means a PodDisruptionBudget kept refusing the eviction of a pod
+var errEvictionBlocked = errors.New("eviction blocked by a PodDisruptionBudget")
+
+// removePod evicts or deletes a pod depending on the configured restart method and
+// returns the method used. An eviction refused by a PodDisruptionBudget is retried
+// until the eviction timeout expires, then falls back to a deletion if enabled.
+func (e *Engine) removePod(ctx context.Context, pod *corev1.Pod) (string, error) {
+	logger := log.FromContext(ctx)
+
+	if e.config.RestartMethod == RestartMethodDelete {
+		return RestartMethodDelete, e.deletePod(ctx, pod)
+	}
+
+	timeout := e.config.EvictionTimeout
+	if timeout <= 0 {
+		timeout = DefaultEvictionTimeout
+	}
+	interval := e.config.EvictionRetryInterval
+	if interval <= 0 {
+		interval = DefaultEvictionRetryInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := e.evictPod(ctx, pod, metav1.DeleteOptions{})
+		switch {
+		case err == nil:
+			return RestartMethodEvict, nil
+		case apierrors.IsMethodNotSupported(err), apierrors.IsNotFound(err):
+			// The eviction API is not served, or the pod is gone and the deletion reports it
+			logger.Info("Eviction unavailable, deleting pod", "pod", pod.Name, "namespace", pod.Namespace, "error", err.Error())
+			return RestartMethodDelete, e.deletePod(ctx, pod)
+		case !apierrors.IsTooManyRequests(err):
+			return RestartMethodEvict, err
+		}
+
+		if time.Now().After(deadline) {
+			if !e.config.EvictionFallback {
+				return RestartMethodEvict, fmt.Errorf("%w for %s: %v", errEvictionBlocked, timeout, err)
+			}
+			logger.Info("Eviction blocked by a PodDisruptionBudget, deleting pod",
+				"pod", pod.Name,
+				"namespace", pod.Namespace,
+				"timeout", timeout)
+			return RestartMethodDelete, e.deletePod(ctx, pod)
+		}
+
+		logger.V(1).Info("Eviction blocked by a PodDisruptionBudget, retrying", "pod", pod.Name, "namespace", pod.Namespace, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return RestartMethodEvict, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// evictPod requests the eviction of a pod with the options of its deletion
+func (e *Engine) evictPod(ctx context.Context, pod *corev1.Pod, options metav1.DeleteOptions) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &options,
+	}
+	return e.clientFor(ctx).CoreV1().Pods(pod.Namespace).EvictV1(ctx, eviction)
+}
+
+// deletePod deletes a pod in the foreground
+func (e *Engine) deletePod(ctx context.Context, pod *corev1.Pod) error {
+	policy := metav1.DeletePropagationForeground
+	return e.clientFor(ctx).CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{PropagationPolicy: &policy})
+}
@@ -0,0 +1,102 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRestartPodMethods(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		fallback    bool
+		evictionErr error
+		wantSuccess bool
+		wantMethod  string
+		wantDeleted bool
+	}{
+		{
+			name:        "eviction",
+			wantSuccess: true,
+			wantMethod:  RestartMethodEvict,
+		},
+		{
+			name:        "delete",
+			method:      RestartMethodDelete,
+			wantSuccess: true,
+			wantMethod:  RestartMethodDelete,
+			wantDeleted: true,
+		},
+		{
+			name:        "blocked eviction falls back to delete",
+			fallback:    true,
+			evictionErr: apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0),
+			wantSuccess: true,
+			wantMethod:  RestartMethodDelete,
+			wantDeleted: true,
+		},
+		{
+			name:        "blocked eviction without fallback",
+			evictionErr: apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0),
+			wantMethod:  RestartMethodEvict,
+		},
+		{
+			name:        "eviction not served",
+			evictionErr: apierrors.NewNotFound(schema.GroupResource{Resource: "pods/eviction"}, "web-1"),
+			wantSuccess: true,
+			wantMethod:  RestartMethodDelete,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+			client := fake.NewSimpleClientset(pod)
+			evictions := 0
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				evictions++
+				return true, nil, tt.evictionErr
+			})
+
+			engine := NewEngine(client, RemediationConfig{
+				Enabled:               true,
+				RestartMethod:         tt.method,
+				EvictionTimeout:       30 * time.Millisecond,
+				EvictionRetryInterval: 10 * time.Millisecond,
+				EvictionFallback:      tt.fallback,
+			})
+
+			result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "default")
+			if err != nil {
+				t.Fatalf("ExecuteAction() error = %v", err)
+			}
+			if result.Success != tt.wantSuccess || result.Method != tt.wantMethod {
+				t.Errorf("result = %+v, want success %t and method %s", result, tt.wantSuccess, tt.wantMethod)
+			}
+
+			_, err = client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{})
+			if deleted := apierrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("pod deleted = %t, want %t", deleted, tt.wantDeleted)
+			}
+			if tt.method == RestartMethodDelete && evictions > 0 {
+				t.Errorf("evictions = %d, want none with the delete method", evictions)
+			}
+			if tt.evictionErr != nil && apierrors.IsTooManyRequests(tt.evictionErr) && evictions < 2 {
+				t.Errorf("evictions = %d, want retries until the timeout", evictions)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the desired actions posted
+// by the webhook executor, as sha256=<hex digest of the body>
+const SignatureHeader = "X-KubeGuardian-Signature"
+
+// Executor types
+const (
+	// ExecutorClient applies actions through the Kubernetes API
+	ExecutorClient = "client"
+	// ExecutorRecord only logs the desired actions
+	ExecutorRecord = "record"
+	// ExecutorWebhook posts the desired actions to another system that applies them
+	ExecutorWebhook = "webhook"
+)
+
+// Executor applies the remediation actions the engine decided to take. The engine
+// checks namespace settings, disabled actions and cooldowns before calling it, and
+// records the cooldown when the returned result is successful.
+type Executor interface {
+	Execute(ctx context.Context, action string, resource interface{}, namespace string) (*Result, error)
+}
+
+// SetExecutor replaces the executor, which applies actions through the Kubernetes API by default
+func (e *Engine) SetExecutor(executor Executor) {
+	e.executor = executor
+}
+
+// clientExecutor applies actions through the Kubernetes API
+type clientExecutor struct {
+	engine *Engine
+}
+
+// Execute applies an action through the Kubernetes API
+func (x clientExecutor) Execute(ctx context.Context, action string, resource interface{}, namespace string) (*Result, error) {
+	return x.engine.apply(ctx, action, resource, namespace)
+}
+
+// DesiredAction is an action for another system to apply
+type DesiredAction struct {
+	Action      string    `json:"action"`
+	Namespace   string    `json:"namespace"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"`
+	Container   string    `json:"container,omitempty"`
+	RequestedBy string    `json:"requestedBy,omitempty"`
+	DryRun      bool      `json:"dryRun,omitempty"`
+	DecidedAt   time.Time `json:"decidedAt"`
+}
+
+// newDesiredAction describes an action on a resource
+func newDesiredAction(ctx context.Context, action string, resource interface{}, namespace string, dryRun bool) DesiredAction {
+	desired := DesiredAction{
+		Action:    action,
+		Namespace: namespace,
+		DryRun:    dryRun,
+		DecidedAt: time.Now(),
+	}
+	desired.Kind = resourceKind(resource)
+	if obj, ok := resource.(metav1.Object); ok {
+		desired.Name = obj.GetName()
+	}
+	desired.Container, _ = ContainerFromContext(ctx)
+	if requester, ok := RequesterFromContext(ctx); ok {
+		desired.RequestedBy = requester.User
+	}
+	return desired
+}
+
+// RecordExecutor logs the desired actions without applying them
+type RecordExecutor struct{}
+
+// NewRecordExecutor creates an executor that only records desired actions
+func NewRecordExecutor() *RecordExecutor {
+	return &RecordExecutor{}
+}
+
+// Execute logs the desired action
+func (x *RecordExecutor) Execute(ctx context.Context, action string, resource interface{}, namespace string) (*Result, error) {
+	desired := newDesiredAction(ctx, action, resource, namespace, false)
+	log.FromContext(ctx).Info("Recorded desired remediation action",
+		"action", desired.Action,
+		"kind", desired.Kind,
+		"resource", desired.Name,
+		"namespace", desired.Namespace,
+		"container", desired.Container)
+
+	return &Result{
+		Action:     action,
+		Success:    true,
+		Message:    fmt.Sprintf("Recorded desired action %s for %s %s", action, desired.Kind, desired.Name),
+		Resource:   desired.Name,
+		Namespace:  namespace,
+		ExecutedAt: desired.DecidedAt,
+	}, nil
+}
+
+// WebhookExecutor posts desired actions as JSON to another system that applies them
+type WebhookExecutor struct {
+	url        string
+	dryRun     bool
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	secret string // Signs the posted actions if set
+}
+
+// NewWebhookExecutor creates an executor that posts desired actions to url; dry-run
+// actions are posted with dryRun set
+func NewWebhookExecutor(url string, timeout time.Duration, dryRun bool) *WebhookExecutor {
+	return &WebhookExecutor{
+		url:        url,
+		dryRun:     dryRun,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// SetSecret sets the secret the posted actions are signed with in the
+// SignatureHeader; an empty secret posts them unsigned
+func (x *WebhookExecutor) SetSecret(secret string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.secret = secret
+}
+
+// Execute posts the desired action; the action succeeds when the webhook accepts it
+func (x *WebhookExecutor) Execute(ctx context.Context, action string, resource interface{}, namespace string) (*Result, error) {
+	startTime := time.Now()
+	desired := newDesiredAction(ctx, action, resource, namespace, x.dryRun)
+
+	result := &Result{
+		Action:    action,
+		Resource:  desired.Name,
+		Namespace: namespace,
+	}
+	if err := x.post(ctx, desired); err != nil {
+		result.Message = fmt.Sprintf("Failed to submit desired action: %v", err)
+		result.ExecutedAt = time.Now()
+		result.Duration = time.Since(startTime)
+		return result, err
+	}
+
+	result.Success = true
+	result.Message = fmt.Sprintf("Submitted desired action %s for %s %s", action, desired.Kind, desired.Name)
+	result.ExecutedAt = time.Now()
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// post sends a desired action to the webhook
+func (x *WebhookExecutor) post(ctx context.Context, desired DesiredAction) error {
+	body, err := json.Marshal(desired)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	x.mu.RLock()
+	if x.secret != "" {
+		mac := hmac.New(sha256.New, []byte(x.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	x.mu.RUnlock()
+
+	resp, err := x.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
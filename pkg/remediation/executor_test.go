@@ -0,0 +1,115 @@
+package remediation
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWebhookExecutor(t *testing.T) {
+	var received []DesiredAction
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var desired DesiredAction
+		if err := json.NewDecoder(r.Body).Decode(&desired); err != nil {
+			t.Errorf("failed to decode desired action: %v", err)
+		}
+		received = append(received, desired)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := fake.NewSimpleClientset(pod)
+	engine := NewEngine(client, RemediationConfig{Enabled: true, AutoScaleEnabled: true, CooldownSeconds: 300})
+	engine.SetExecutor(NewWebhookExecutor(server.URL, time.Second, false))
+
+	ctx := WithContainer(WithRequester(context.Background(), Requester{User: "alice", Source: "api"}), "app")
+	result, err := engine.ExecuteAction(ctx, "restart-pod", pod, "default")
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+	}
+
+	want := DesiredAction{Action: "restart-pod", Namespace: "default", Kind: "Pod", Name: "web-1", Container: "app", RequestedBy: "alice"}
+	if len(received) != 1 {
+		t.Fatalf("received %d desired actions, want 1", len(received))
+	}
+	got := received[0]
+	got.DecidedAt = time.Time{}
+	if got != want {
+		t.Errorf("desired action = %+v, want %+v", got, want)
+	}
+
+	// The pod is left alone for the other system
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("pod was deleted: %v", err)
+	}
+
+	// Accepted actions are in cooldown, rejected ones fail
+	if result, _ := engine.ExecuteAction(ctx, "restart-pod", pod, "default"); result.Success {
+		t.Errorf("second action succeeded, want cooldown")
+	}
+	status = http.StatusInternalServerError
+	if result, err := engine.ExecuteAction(ctx, "scale-replicas", pod, "default"); err == nil || result.Success {
+		t.Errorf("ExecuteAction() with a failing webhook = %v (%v), want an error", result, err)
+	}
+}
+
+func TestWebhookExecutorSignature(t *testing.T) {
+	var signatures []string
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	executor := NewWebhookExecutor(server.URL, time.Second, false)
+	ctx := context.Background()
+
+	if _, err := executor.Execute(ctx, "restart-pod", pod, "default"); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	executor.SetSecret("s3cret")
+	if _, err := executor.Execute(ctx, "restart-pod", pod, "default"); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	if signatures[0] != "" {
+		t.Errorf("expected no signature without a secret, got %q", signatures[0])
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(bodies[1])
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signatures[1] != want {
+		t.Errorf("signature = %q, want %q", signatures[1], want)
+	}
+}
+
+func TestRecordExecutor(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+	client := fake.NewSimpleClientset(pod)
+	engine := NewEngine(client, RemediationConfig{Enabled: true})
+	engine.SetExecutor(NewRecordExecutor())
+
+	result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "default")
+	if err != nil || !result.Success {
+		t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("pod was deleted: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActionRemoveFinalizer removes an allow-listed finalizer from a resource stuck deleting
+const ActionRemoveFinalizer = "remove-finalizer"
+
+// Modes of allowed finalizers
+const (
+	// FinalizerModeApproval only removes the finalizer when the action is
+	// requested through the action API; detected issues are held for approval
+	FinalizerModeApproval = "approval"
+	// FinalizerModeAutomatic removes the finalizer when the issue is detected
+	FinalizerModeAutomatic = "automatic"
+)
+
+// AllowedFinalizer is a finalizer remove-finalizer may remove from resources of Kinds
+type AllowedFinalizer struct {
+	Name  string
+	Kinds []string
+	// Automatic removes the finalizer without approval
+	Automatic bool
+}
+
+// resourceKind returns the kind of a resource remediation actions apply to
+func resourceKind(resource interface{}) string {
+	switch resource.(type) {
+	case *corev1.Pod:
+		return "Pod"
+	case *appsv1.Deployment:
+		return "Deployment"
+	case *corev1.Service:
+		return "Service"
+	case *corev1.PersistentVolumeClaim:
+		return "PersistentVolumeClaim"
+	case *corev1.Node:
+		return "Node"
+	default:
+		return ""
+	}
+}
+
+// removableFinalizer returns the first finalizer of a resource that is allowed
+// to be removed, and its index in the finalizers of the resource
+func (e *Engine) removableFinalizer(resource metav1.Object, kind string) (AllowedFinalizer, int, bool) {
+	for i, finalizer := range resource.GetFinalizers() {
+		for _, allowed := range e.config.Finalizers {
+			if allowed.Name == finalizer && slices.Contains(allowed.Kinds, kind) {
+				return allowed, i, true
+			}
+		}
+	}
+	return AllowedFinalizer{}, 0, false
+}
+
+// pendingApproval returns the result of a remove-finalizer action held for
+// approval: the allowed finalizer it would remove is not automatic and the
+// action was not requested through the action API. Other actions are never held.
+func (e *Engine) pendingApproval(ctx context.Context, action string, resource interface{}, namespace string) *Result {
+	if action != ActionRemoveFinalizer {
+		return nil
+	}
+	if _, manual := RequesterFromContext(ctx); manual {
+		return nil
+	}
+	object, ok := resource.(metav1.Object)
+	if !ok {
+		return nil
+	}
+	kind := resourceKind(resource)
+	allowed, _, found := e.removableFinalizer(object, kind)
+	if !found || allowed.Automatic {
+		return nil
+	}
+	return &Result{
+		Action:  action,
+		Success: false,
+		Message: fmt.Sprintf("Removing finalizer %s from %s %s awaits approval: request %s for it through the action API",
+			allowed.Name, kind, object.GetName(), ActionRemoveFinalizer),
+		Resource:        object.GetName(),
+		Namespace:       namespace,
+		ExecutedAt:      time.Now(),
+		PendingApproval: true,
+	}
+}
+
+// removeFinalizer removes the first allow-listed finalizer from a resource that
+// is being deleted. The JSON patch tests that the finalizer is still at the same
+// position, so a finalizer list changed in the meantime fails with a conflict
+// instead of removing another finalizer.
+func (e *Engine) removeFinalizer(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	result := &Result{Action: ActionRemoveFinalizer, Namespace: namespace, ExecutedAt: startTime}
+
+	kind := resourceKind(resource)
+	object, ok := resource.(metav1.Object)
+	if !ok || kind == "" {
+		result.Message = "Resource does not support finalizer removal"
+		return result, fmt.Errorf("resource does not support finalizer removal")
+	}
+	result.Resource = object.GetName()
+
+	if object.GetDeletionTimestamp() == nil {
+		result.Message = fmt.Sprintf("%s %s is not being deleted, its finalizers are kept", kind, object.GetName())
+		return result, nil
+	}
+	allowed, index, found := e.removableFinalizer(object, kind)
+	if !found {
+		result.Message = fmt.Sprintf("%s %s has no allow-listed finalizer", kind, object.GetName())
+		return result, nil
+	}
+
+	path := fmt.Sprintf("/metadata/finalizers/%d", index)
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": path, "value": allowed.Name},
+		{"op": "remove", "path": path},
+	})
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to build finalizer patch: %v", err)
+		return result, err
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would remove finalizer", "kind", kind, "resource", object.GetName(), "namespace", namespace, "finalizer", allowed.Name)
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would remove finalizer %s from %s %s", allowed.Name, kind, object.GetName())
+		result.PatchType = types.JSONPatchType
+		result.Patch = string(patch)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	err = e.patchFinalizers(ctx, kind, namespace, object.GetName(), patch)
+	result.Duration = time.Since(startTime)
+	switch {
+	case apierrors.IsNotFound(err):
+		result.Success = true
+		result.Message = fmt.Sprintf("%s %s was deleted before finalizer %s was removed", kind, object.GetName(), allowed.Name)
+		return result, nil
+	case apierrors.IsInvalid(err), apierrors.IsConflict(err):
+		err = fmt.Errorf("%w: finalizers of %s %s changed: %v", ErrConcurrentChange, kind, object.GetName(), err)
+		result.Message = fmt.Sprintf("Failed to remove finalizer %s: %v", allowed.Name, err)
+		result.Conflict = true
+		return result, err
+	case err != nil:
+		result.Message = fmt.Sprintf("Failed to remove finalizer %s: %v", allowed.Name, err)
+		return result, err
+	}
+
+	logger.Info("Removed finalizer", "kind", kind, "resource", object.GetName(), "namespace", namespace, "finalizer", allowed.Name)
+	result.Success = true
+	result.Message = fmt.Sprintf("Removed finalizer %s from %s %s", allowed.Name, kind, object.GetName())
+	return result, nil
+}
+
+// patchFinalizers applies a JSON patch to the finalizers of a resource
+func (e *Engine) patchFinalizers(ctx context.Context, kind, namespace, name string, patch []byte) error {
+	client := e.clientFor(ctx)
+	var err error
+	switch kind {
+	case "Pod":
+		_, err = client.CoreV1().Pods(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	case "Deployment":
+		_, err = client.AppsV1().Deployments(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	case "Service":
+		_, err = client.CoreV1().Services(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	case "PersistentVolumeClaim":
+		_, err = client.CoreV1().PersistentVolumeClaims(namespace).Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	default:
+		err = errors.New("unsupported resource kind " + kind)
+	}
+	return err
+}
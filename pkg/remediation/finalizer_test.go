@@ -0,0 +1,131 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const lbFinalizer = "service.kubernetes.io/load-balancer-cleanup"
+
+func deletingService(finalizers ...string) *corev1.Service {
+	deleted := metav1.NewTime(time.Now().Add(-time.Hour))
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:              "web",
+		Namespace:         "default",
+		Finalizers:        finalizers,
+		DeletionTimestamp: &deleted,
+	}}
+}
+
+func TestRemoveFinalizer(t *testing.T) {
+	allowed := []AllowedFinalizer{{Name: lbFinalizer, Kinds: []string{"Service"}, Automatic: true}}
+
+	t.Run("removes only the allowed finalizer", func(t *testing.T) {
+		service := deletingService("example.com/protect", lbFinalizer)
+		client := fake.NewSimpleClientset(service)
+		engine := NewEngine(client, RemediationConfig{Enabled: true, Finalizers: allowed})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionRemoveFinalizer, service, "default")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() = %+v, %v, want success", result, err)
+		}
+		updated, err := client.CoreV1().Services("default").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(updated.Finalizers, []string{"example.com/protect"}) {
+			t.Errorf("finalizers = %v, want [example.com/protect]", updated.Finalizers)
+		}
+	})
+
+	t.Run("keeps finalizers of other kinds and live resources", func(t *testing.T) {
+		engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, Finalizers: allowed})
+
+		claim := &corev1.PersistentVolumeClaim{ObjectMeta: deletingService(lbFinalizer).ObjectMeta}
+		live := deletingService(lbFinalizer)
+		live.DeletionTimestamp = nil
+		for _, resource := range []interface{}{claim, live} {
+			result, err := engine.removeFinalizer(context.Background(), resource, "default")
+			if err != nil || result.Success {
+				t.Errorf("removeFinalizer(%T) = %+v, %v, want no change", resource, result, err)
+			}
+		}
+		if actions := engine.client.(*fake.Clientset).Actions(); len(actions) != 0 {
+			t.Errorf("client actions = %v, want none", actions)
+		}
+	})
+
+	t.Run("dry run returns the patch", func(t *testing.T) {
+		service := deletingService("example.com/protect", lbFinalizer)
+		client := fake.NewSimpleClientset(service)
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true, Finalizers: allowed})
+
+		result, err := engine.removeFinalizer(context.Background(), service, "default")
+		if err != nil || !result.Success {
+			t.Fatalf("removeFinalizer() = %+v, %v, want success", result, err)
+		}
+		want := `[{"op":"test","path":"/metadata/finalizers/1","value":"` + lbFinalizer + `"},{"op":"remove","path":"/metadata/finalizers/1"}]`
+		if result.PatchType != types.JSONPatchType || result.Patch != want {
+			t.Errorf("patch = %s %s, want %s", result.PatchType, result.Patch, want)
+		}
+		if len(client.Actions()) != 0 {
+			t.Errorf("client actions = %v, want none", client.Actions())
+		}
+	})
+
+	t.Run("changed finalizers conflict", func(t *testing.T) {
+		service := deletingService(lbFinalizer)
+		client := fake.NewSimpleClientset(service)
+		client.PrependReactor("patch", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, "web", nil)
+		})
+		engine := NewEngine(client, RemediationConfig{Enabled: true, Finalizers: allowed})
+
+		result, err := engine.removeFinalizer(context.Background(), service, "default")
+		if !errors.Is(err, ErrConcurrentChange) || !result.Conflict {
+			t.Errorf("removeFinalizer() = %+v, %v, want a conflict", result, err)
+		}
+	})
+}
+
+func TestRemoveFinalizerApproval(t *testing.T) {
+	service := deletingService(lbFinalizer)
+	client := fake.NewSimpleClientset(service)
+	engine := NewEngine(client, RemediationConfig{
+		Enabled:    true,
+		Finalizers: []AllowedFinalizer{{Name: lbFinalizer, Kinds: []string{"Service"}}},
+	})
+
+	result, err := engine.ExecuteAction(context.Background(), ActionRemoveFinalizer, service, "default")
+	if err != nil || result.Success || !result.PendingApproval {
+		t.Fatalf("ExecuteAction() = %+v, %v, want a result pending approval", result, err)
+	}
+	if len(client.Actions()) != 0 {
+		t.Errorf("client actions = %v, want none before approval", client.Actions())
+	}
+
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Source: "api"})
+	result, err = engine.ExecuteAction(ctx, ActionRemoveFinalizer, service, "default")
+	if err != nil || !result.Success || result.RequestedBy != "alice" {
+		t.Fatalf("approved ExecuteAction() = %+v, %v, want success", result, err)
+	}
+	updated, err := client.CoreV1().Services("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Finalizers) != 0 {
+		t.Errorf("finalizers = %v, want none", updated.Finalizers)
+	}
+}
@@ -0,0 +1,81 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActionForceDeletePod deletes a pod stuck terminating without waiting for its kubelet
+const ActionForceDeletePod = "force-delete-pod"
+
+// forceDeletePod deletes a pod that is terminating beyond its grace period with
+// a grace period of zero, removing it from the API server without waiting for
+// its kubelet to confirm its containers stopped. Since the containers may still
+// run on a node that lost contact, the policy pre-check only lets the action run
+// in namespaces that opted in with ForceDeleteEnabled, and it only applies to a
+// pod that is still terminating beyond its grace period when read again.
+// Finalizers are left alone: a pod waiting on finalizers is removed once they
+// are done.
+func (e *Engine) forceDeletePod(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	result := &Result{Action: ActionForceDeletePod, Namespace: namespace, ExecutedAt: startTime}
+
+	pod, ok := resource.(*corev1.Pod)
+	if !ok || pod == nil {
+		result.Message = "Resource is not a valid Pod"
+		return result, fmt.Errorf("resource is not a valid Pod")
+	}
+	result.Resource = pod.Name
+
+	current, err := e.clientFor(ctx).CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		result.Success = true
+		result.Message = fmt.Sprintf("Pod %s no longer exists", pod.Name)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to get pod: %v", err)
+		return result, err
+	}
+	if current.DeletionTimestamp == nil || e.config.Clock.Now().Before(current.DeletionTimestamp.Time) {
+		result.Message = fmt.Sprintf("Pod %s is not terminating beyond its grace period", pod.Name)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would force delete pod", "pod", pod.Name, "namespace", namespace)
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would force delete pod %s", pod.Name)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	gracePeriod := int64(0)
+	uid := current.UID
+	err = e.clientFor(ctx).CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+		Preconditions:      &metav1.Preconditions{UID: &uid},
+	})
+	result.Duration = time.Since(startTime)
+	if err != nil && !apierrors.IsNotFound(err) {
+		result.Message = fmt.Sprintf("Failed to force delete pod: %v", err)
+		return result, err
+	}
+
+	logger.Info("Force deleted pod", "pod", pod.Name, "namespace", namespace, "finalizers", current.Finalizers)
+	result.Success = true
+	result.Message = fmt.Sprintf("Force deleted pod %s", pod.Name)
+	if len(current.Finalizers) > 0 {
+		result.Message += fmt.Sprintf(", which is removed once its finalizers %v are done", current.Finalizers)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestForceDeletePod(t *testing.T) {
+	stuck := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "web-1",
+		Namespace:         "shop",
+		DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+	}}
+	terminating := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "web-2",
+		Namespace:         "shop",
+		DeletionTimestamp: &metav1.Time{Time: time.Now().Add(time.Minute)},
+	}}
+	optedIn := map[string]NamespaceRemediationConfig{"shop": {Enabled: true, ForceDeleteEnabled: true}}
+
+	exists := func(t *testing.T, client *fake.Clientset, name string) bool {
+		t.Helper()
+		_, err := client.CoreV1().Pods("shop").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	t.Run("requires the namespace to opt in", func(t *testing.T) {
+		client := fake.NewSimpleClientset(stuck.DeepCopy())
+		engine := NewEngine(client, RemediationConfig{Enabled: true})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionForceDeletePod, stuck, "shop")
+		if err != nil || result.Success || !strings.Contains(result.Message, "not enabled for namespace shop") {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a refusal", result, err)
+		}
+		if !exists(t, client, "web-1") {
+			t.Error("pod deleted without the namespace opting in")
+		}
+	})
+
+	t.Run("deletes a pod terminating beyond its grace period", func(t *testing.T) {
+		client := fake.NewSimpleClientset(stuck.DeepCopy(), terminating.DeepCopy())
+		engine := NewEngine(client, RemediationConfig{Enabled: true, Namespaces: optedIn})
+
+		result, err := engine.forceDeletePod(context.Background(), terminating, "shop")
+		if err != nil || result.Success || !strings.Contains(result.Message, "not terminating beyond its grace period") {
+			t.Fatalf("forceDeletePod() = %+v, %v, want a refusal within the grace period", result, err)
+		}
+
+		result, err = engine.ExecuteAction(context.Background(), ActionForceDeletePod, stuck, "shop")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() = %+v, %v, want the pod force deleted", result, err)
+		}
+		if exists(t, client, "web-1") || !exists(t, client, "web-2") {
+			t.Error("want only the stuck pod deleted")
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		client := fake.NewSimpleClientset(stuck.DeepCopy())
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true, Namespaces: optedIn})
+
+		result, err := engine.forceDeletePod(context.Background(), stuck, "shop")
+		if err != nil || !result.Success || !strings.Contains(result.Message, "Dry run") {
+			t.Fatalf("forceDeletePod() = %+v, %v, want a dry run", result, err)
+		}
+		if !exists(t, client, "web-1") {
+			t.Error("dry run deleted the pod")
+		}
+	})
+}
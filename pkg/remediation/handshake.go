@@ -0,0 +1,87 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Defaults of the pre-remediation handshake
+const (
+	DefaultHandshakeAnnotation  = "kubeguardian.io/pending-restart"
+	DefaultHandshakeGracePeriod = 30 * time.Second
+)
+
+// announceRestart annotates pods about to be disrupted and waits the handshake
+// grace period, so applications watching their own metadata can checkpoint or
+// drain. It returns the pods that were annotated; the handshake is skipped in
+// namespaces without it. Failing to annotate a pod does not block the action.
+func (e *Engine) announceRestart(ctx context.Context, namespace string, pods ...*corev1.Pod) ([]*corev1.Pod, error) {
+	if !e.GetNamespaceConfig(namespace).HandshakeEnabled {
+		return nil, nil
+	}
+	logger := log.FromContext(ctx)
+
+	annotated := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if err := e.patchHandshakeAnnotation(ctx, pod, "true"); err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "Failed to annotate pod before remediation", "pod", pod.Name, "namespace", pod.Namespace)
+			}
+			continue
+		}
+		annotated = append(annotated, pod)
+	}
+	if len(annotated) == 0 {
+		return nil, nil
+	}
+
+	grace := e.config.HandshakeGracePeriod
+	if grace <= 0 {
+		grace = DefaultHandshakeGracePeriod
+	}
+	logger.Info("Announced pending restart, waiting for the application", "pods", len(annotated), "namespace", namespace, "gracePeriod", grace)
+	select {
+	case <-ctx.Done():
+		e.withdrawRestart(ctx, annotated)
+		return nil, ctx.Err()
+	case <-time.After(grace):
+	}
+	return annotated, nil
+}
+
+// withdrawRestart removes the handshake annotation from pods that were not
+// removed, e.g. when the action failed or only restarted a container
+func (e *Engine) withdrawRestart(ctx context.Context, pods []*corev1.Pod) {
+	// Withdraw even if the action was cancelled
+	ctx = context.WithoutCancel(ctx)
+	for _, pod := range pods {
+		if err := e.patchHandshakeAnnotation(ctx, pod, nil); err != nil && !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to remove pre-remediation annotation", "pod", pod.Name, "namespace", pod.Namespace)
+		}
+	}
+}
+
+// patchHandshakeAnnotation sets the handshake annotation of a pod, or removes it if value is nil
+func (e *Engine) patchHandshakeAnnotation(ctx context.Context, pod *corev1.Pod, value interface{}) error {
+	annotation := e.config.HandshakeAnnotation
+	if annotation == "" {
+		annotation = DefaultHandshakeAnnotation
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{annotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = e.clientFor(ctx).CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
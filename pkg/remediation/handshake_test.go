@@ -0,0 +1,101 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPreRemediationHandshake(t *testing.T) {
+	config := RemediationConfig{
+		Enabled:               true,
+		EvictionTimeout:       20 * time.Millisecond,
+		EvictionRetryInterval: 10 * time.Millisecond,
+		HandshakeEnabled:      true,
+		HandshakeGracePeriod:  50 * time.Millisecond,
+	}
+
+	t.Run("announces the restart before evicting", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+		client := fake.NewSimpleClientset(pod)
+		start := time.Now()
+		var annotation string
+		var waited time.Duration
+		client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			waited = time.Since(start)
+			current, err := client.Tracker().Get(corev1.SchemeGroupVersion.WithResource("pods"), "default", "web-1")
+			if err != nil {
+				return true, nil, err
+			}
+			annotation = current.(*corev1.Pod).Annotations[DefaultHandshakeAnnotation]
+			return true, nil, nil
+		})
+
+		engine := NewEngine(client, config)
+		result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "default")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+		}
+		if annotation != "true" {
+			t.Errorf("annotation at eviction = %q, want true", annotation)
+		}
+		if waited < config.HandshakeGracePeriod {
+			t.Errorf("evicted after %s, want the %s grace period", waited, config.HandshakeGracePeriod)
+		}
+	})
+
+	t.Run("withdraws the announcement when the restart is refused", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+		client := fake.NewSimpleClientset(pod)
+		client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		})
+
+		engine := NewEngine(client, config)
+		result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "default")
+		if err != nil || result.Success {
+			t.Fatalf("ExecuteAction() = %v (%v), want a refused restart", result, err)
+		}
+		current, err := client.CoreV1().Pods("default").Get(context.Background(), "web-1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get pod: %v", err)
+		}
+		if _, found := current.Annotations[DefaultHandshakeAnnotation]; found {
+			t.Errorf("annotations = %v, want the announcement withdrawn", current.Annotations)
+		}
+	})
+
+	t.Run("skipped in namespaces without the handshake", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "batch"}}
+		client := fake.NewSimpleClientset(pod)
+		patches := 0
+		client.PrependReactor("patch", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+			patches++
+			return false, nil, nil
+		})
+
+		cfg := config
+		cfg.Namespaces = map[string]NamespaceRemediationConfig{"batch": {Enabled: true}}
+		engine := NewEngine(client, cfg)
+		result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "batch")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+		}
+		if patches != 0 {
+			t.Errorf("patches = %d, want no announcement", patches)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActionCordonNode marks a node unschedulable, so no new pods are placed on it
+const ActionCordonNode = "cordon-node"
+
+// AnnotationCordonedAt records when KubeGuardian cordoned a node
+const AnnotationCordonedAt = "kubeguardian.io/cordoned-at"
+
+// cordonNode marks a node unschedulable. Pods already running on the node are
+// left alone; a node that is already unschedulable is not changed.
+func (e *Engine) cordonNode(ctx context.Context, resource interface{}) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	result := &Result{Action: ActionCordonNode, ExecutedAt: startTime}
+
+	node, ok := resource.(*corev1.Node)
+	if !ok || node == nil {
+		result.Message = "Resource is not a valid Node"
+		return result, fmt.Errorf("resource is not a valid Node")
+	}
+	result.Resource = node.Name
+
+	if node.Spec.Unschedulable {
+		result.Success = true
+		result.Message = fmt.Sprintf("Node %s is already cordoned", node.Name)
+		return result, nil
+	}
+
+	patch, err := buildMergePatch(ctx, map[string]interface{}{"unschedulable": true},
+		map[string]string{AnnotationCordonedAt: time.Now().Format(time.RFC3339)})
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to build cordon patch: %v", err)
+		return result, err
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would cordon node", "node", node.Name)
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would cordon node %s", node.Name)
+		result.PatchType = types.MergePatchType
+		result.Patch = string(patch)
+		result.Duration = time.Since(startTime)
+		return result, nil
+	}
+
+	_, err = e.clientFor(ctx).CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to cordon node: %v", err)
+		return result, err
+	}
+
+	logger.Info("Cordoned node", "node", node.Name)
+	result.Success = true
+	result.Message = fmt.Sprintf("Cordoned node %s", node.Name)
+	return result, nil
+}
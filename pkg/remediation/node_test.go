@@ -0,0 +1,50 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCordonNode(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+
+	t.Run("dry run", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node.DeepCopy())
+		engine := NewEngine(client, RemediationConfig{Enabled: true, DryRun: true})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCordonNode, node, "")
+		if err != nil || !result.Success || result.Patch == "" {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a dry run patch", result, err)
+		}
+		if len(client.Actions()) != 0 {
+			t.Errorf("client actions = %v, want none", client.Actions())
+		}
+	})
+
+	t.Run("cordons the node", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node.DeepCopy())
+		engine := NewEngine(client, RemediationConfig{Enabled: true})
+
+		result, err := engine.ExecuteAction(context.Background(), ActionCordonNode, node, "")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() = %+v, %v, want success", result, err)
+		}
+		cordoned, err := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cordoned.Spec.Unschedulable || cordoned.Annotations[AnnotationCordonedAt] == "" {
+			t.Errorf("node = %+v, want unschedulable with %s", cordoned, AnnotationCordonedAt)
+		}
+
+		// An unschedulable node is left unchanged
+		client.ClearActions()
+		if result, err := engine.cordonNode(context.Background(), cordoned); err != nil || !result.Success || len(client.Actions()) != 0 {
+			t.Errorf("cordonNode() of a cordoned node = %+v, %v with %d calls, want success without calls", result, err, len(client.Actions()))
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PluginProtocolVersion is the version of the JSON protocol between the engine and
+// action plugins, sent as version in every request
+const PluginProtocolVersion = 1
+
+// Default bounds of action plugins
+const (
+	DefaultPluginTimeout        = 30 * time.Second
+	DefaultPluginMaxOutputBytes = 64 << 10
+)
+
+// pluginStderrBytes bounds the stderr of a plugin kept for logs
+const pluginStderrBytes = 4 << 10
+
+// Plugin is a remediation action implemented by an external program, e.g. an
+// organization's proprietary action. The engine runs the program for every
+// action with a PluginRequest as JSON on stdin and reads a PluginResponse as JSON
+// from stdout; a non-zero exit status fails the action.
+type Plugin struct {
+	// Name is the action the plugin implements
+	Name string
+	// Command is the program and its arguments
+	Command []string
+	// Kinds are the resource kinds the plugin applies to; empty is any kind
+	Kinds []string
+	// Env is the environment of the program, which inherits nothing else
+	Env map[string]string
+	// Timeout bounds a run; the program and its children are killed when it expires
+	Timeout time.Duration
+	// MaxOutputBytes bounds the response read from stdout
+	MaxOutputBytes int
+	// MaxMemoryBytes and MaxCPUTime limit the address space and CPU time of the
+	// program where the platform supports it; zero is unlimited
+	MaxMemoryBytes int64
+	MaxCPUTime     time.Duration
+}
+
+// PluginRequest is the action a plugin is asked to apply, with the resource as
+// last read from the cluster
+type PluginRequest struct {
+	Version int `json:"version"`
+	DesiredAction
+	Resource json.RawMessage `json:"resource,omitempty"`
+}
+
+// PluginResponse is the result of a plugin run
+type PluginResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// plugin returns the plugin implementing an action
+func (e *Engine) plugin(action string) (Plugin, bool) {
+	for _, plugin := range e.config.Plugins {
+		if plugin.Name == action {
+			return plugin, true
+		}
+	}
+	return Plugin{}, false
+}
+
+// runPlugin runs the plugin of an action for a resource. Plugins are not run in
+// dry-run mode, since the engine cannot tell what they would change.
+func (e *Engine) runPlugin(ctx context.Context, plugin Plugin, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+	desired := newDesiredAction(ctx, plugin.Name, resource, namespace, false)
+	result := &Result{Action: plugin.Name, Resource: desired.Name, Namespace: namespace, ExecutedAt: startTime}
+
+	if len(plugin.Kinds) > 0 && !slices.Contains(plugin.Kinds, desired.Kind) {
+		result.Message = fmt.Sprintf("Plugin %s does not apply to %s resources", plugin.Name, desired.Kind)
+		return result, fmt.Errorf("plugin %s does not apply to %s resources", plugin.Name, desired.Kind)
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would run action plugin", "action", plugin.Name, "kind", desired.Kind, "resource", desired.Name, "namespace", namespace)
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would run plugin %s for %s %s", plugin.Name, desired.Kind, desired.Name)
+		return result, nil
+	}
+
+	request := PluginRequest{Version: PluginProtocolVersion, DesiredAction: desired}
+	if resource != nil {
+		data, err := json.Marshal(resource)
+		if err != nil {
+			result.Message = fmt.Sprintf("Failed to encode resource for plugin %s: %v", plugin.Name, err)
+			return result, err
+		}
+		request.Resource = data
+	}
+
+	response, err := execPlugin(ctx, plugin, request)
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		result.Message = fmt.Sprintf("Plugin %s failed: %v", plugin.Name, err)
+		return result, err
+	}
+
+	logger.Info("Ran action plugin", "action", plugin.Name, "kind", desired.Kind, "resource", desired.Name, "namespace", namespace, "success", response.Success)
+	result.Success = response.Success
+	result.Message = response.Message
+	if result.Message == "" {
+		result.Message = fmt.Sprintf("Plugin %s ran for %s %s", plugin.Name, desired.Kind, desired.Name)
+	}
+	return result, nil
+}
+
+// execPlugin runs the program of a plugin in an empty working directory with
+// only the configured environment, bounded by its timeout, output and resource
+// limits, and decodes its response
+func execPlugin(ctx context.Context, plugin Plugin, request PluginRequest) (*PluginResponse, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	timeout := plugin.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "kubeguardian-plugin-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	maxOutput := plugin.MaxOutputBytes
+	if maxOutput <= 0 {
+		maxOutput = DefaultPluginMaxOutputBytes
+	}
+	stdout := &limitedBuffer{limit: maxOutput}
+	stderr := &limitedBuffer{limit: pluginStderrBytes}
+
+	cmd := exec.CommandContext(ctx, plugin.Command[0], plugin.Command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = []string{"KUBEGUARDIAN_PLUGIN_PROTOCOL=" + fmt.Sprint(PluginProtocolVersion)}
+	for name, value := range plugin.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	isolatePlugin(cmd)
+	if err := limitPlugin(cmd, plugin); err != nil {
+		return nil, fmt.Errorf("failed to limit resources: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start: %w", err)
+	}
+	err = cmd.Wait()
+
+	switch {
+	case ctx.Err() != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	case err != nil:
+		return nil, fmt.Errorf("%w%s", err, stderrSuffix(stderr))
+	case stdout.truncated:
+		return nil, fmt.Errorf("response exceeds %d bytes", maxOutput)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w%s", err, stderrSuffix(stderr))
+	}
+	return &response, nil
+}
+
+// stderrSuffix formats the stderr of a failed plugin run for its error
+func stderrSuffix(stderr *limitedBuffer) string {
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return ": " + message
+	}
+	return ""
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest,
+// so a misbehaving plugin cannot exhaust the memory of KubeGuardian. The buffer
+// is not embedded, so copies cannot bypass Write through its ReadFrom.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write keeps what fits within the limit; it never fails so the plugin is not
+// interrupted by a broken pipe
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the kept bytes
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// String returns the kept bytes as a string
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,100 @@
+//go:build linux
+
+package remediation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// pluginLimitsEnv passes the resource limits of a plugin to the shim that sets
+// them before executing the plugin, e.g. "as=67108864,cpu=5"
+const pluginLimitsEnv = "KUBEGUARDIAN_PLUGIN_RLIMITS"
+
+// pluginShimName is the program name of KubeGuardian running as the shim
+const pluginShimName = "kubeguardian-plugin-shim"
+
+func init() {
+	if limits, ok := os.LookupEnv(pluginLimitsEnv); ok {
+		execPluginShim(limits)
+	}
+}
+
+// isolatePlugin starts the plugin in its own process group, so the plugin and
+// any process it started are killed together when it times out
+func isolatePlugin(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+		return os.ErrProcessDone
+	}
+}
+
+// limitPlugin limits the address space and CPU time of a plugin before it runs.
+// The command is started through KubeGuardian itself as a shim, which sets the
+// limits on its own process and then executes the plugin, so neither the plugin
+// nor any process it starts ever runs without them.
+func limitPlugin(cmd *exec.Cmd, plugin Plugin) error {
+	var limits []string
+	if plugin.MaxMemoryBytes > 0 {
+		limits = append(limits, fmt.Sprintf("as=%d", plugin.MaxMemoryBytes))
+	}
+	if plugin.MaxCPUTime > 0 {
+		limits = append(limits, fmt.Sprintf("cpu=%d", uint64(max(plugin.MaxCPUTime.Seconds(), 1))))
+	}
+	// Without limits, or a program to run, the command is started as it is
+	if len(limits) == 0 || cmd.Err != nil {
+		return nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the plugin shim: %w", err)
+	}
+	cmd.Args = append([]string{pluginShimName, cmd.Path}, cmd.Args...)
+	cmd.Path = self
+	cmd.Env = append(cmd.Env, pluginLimitsEnv+"="+strings.Join(limits, ","))
+	return nil
+}
+
+// execPluginShim sets the resource limits on the current process and replaces
+// it with the plugin given by its path and arguments. It never returns.
+func execPluginShim(limits string) {
+	fail := func(err error) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", pluginShimName, err)
+		os.Exit(126)
+	}
+	if len(os.Args) < 3 {
+		fail(errors.New("no plugin given"))
+	}
+
+	resources := map[string]int{"as": unix.RLIMIT_AS, "cpu": unix.RLIMIT_CPU}
+	for _, limit := range strings.Split(limits, ",") {
+		name, value, _ := strings.Cut(limit, "=")
+		resource, known := resources[name]
+		n, err := strconv.ParseUint(value, 10, 64)
+		if !known || err != nil {
+			fail(fmt.Errorf("invalid limit %q", limit))
+		}
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: n, Max: n}); err != nil {
+			fail(fmt.Errorf("failed to set limit %q: %w", limit, err))
+		}
+	}
+
+	env := make([]string, 0, len(os.Environ()))
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, pluginLimitsEnv+"=") {
+			env = append(env, variable)
+		}
+	}
+	fail(syscall.Exec(os.Args[1], os.Args[2:], env))
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package remediation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecPluginLimitsResourcesBeforeStart(t *testing.T) {
+	// The plugin allocates past its memory limit right away and reports the
+	// limits it runs with
+	script := `grep -E 'Max (address space|cpu time)' /proc/self/limits >&2
+echo "rlimits=${KUBEGUARDIAN_PLUGIN_RLIMITS:-unset}" >&2
+dd if=/dev/zero of=/dev/null bs=256M count=1 2>/dev/null && echo '{"success": true, "message": "allocated"}'`
+	plugin := Plugin{
+		Name:    "allocate",
+		Command: []string{writePlugin(t, script)},
+		Env:     map[string]string{"PATH": "/usr/bin:/bin"},
+	}
+
+	if _, err := execPlugin(context.Background(), plugin, PluginRequest{}); err != nil {
+		t.Fatalf("execPlugin() without limits error = %v", err)
+	}
+
+	plugin.MaxMemoryBytes = 64 << 20
+	plugin.MaxCPUTime = 5 * time.Second
+	_, err := execPlugin(context.Background(), plugin, PluginRequest{})
+	if err == nil {
+		t.Fatal("execPlugin() succeeded, want the allocation to fail under the memory limit")
+	}
+	for _, want := range []string{`Max address space\s+67108864\s`, `Max cpu time\s+5\s`, `rlimits=unset`} {
+		if !regexp.MustCompile(want).MatchString(err.Error()) {
+			t.Errorf("error = %v, want the plugin to report %s", err, strings.ReplaceAll(want, `\s+`, " "))
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package remediation
+
+import "os/exec"
+
+// isolatePlugin leaves the plugin in the process group of KubeGuardian; only the
+// plugin itself is killed when it times out
+func isolatePlugin(cmd *exec.Cmd) {}
+
+// limitPlugin does not limit the resources of plugins on this platform
+func limitPlugin(cmd *exec.Cmd, plugin Plugin) error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// writePlugin writes a shell script plugin and returns its path
+func writePlugin(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunPlugin(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}}
+	env := map[string]string{"PATH": "/usr/bin:/bin"}
+
+	t.Run("sends the action and reads the response", func(t *testing.T) {
+		requestFile := filepath.Join(t.TempDir(), "request.json")
+		plugin := Plugin{
+			Name:    "flush-cache",
+			Command: []string{writePlugin(t, `cat > "$REQUEST"; echo '{"success": true, "message": "flushed"}'`)},
+			Kinds:   []string{"Pod"},
+			Env:     map[string]string{"PATH": env["PATH"], "REQUEST": requestFile},
+		}
+		engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, Plugins: []Plugin{plugin}})
+
+		result, err := engine.ExecuteAction(context.Background(), "flush-cache", pod, "shop")
+		if err != nil || !result.Success || result.Message != "flushed" {
+			t.Fatalf("ExecuteAction() = %+v, %v, want success", result, err)
+		}
+
+		data, err := os.ReadFile(requestFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var request struct {
+			PluginRequest
+			Resource corev1.Pod `json:"resource"`
+		}
+		if err := json.Unmarshal(data, &request); err != nil {
+			t.Fatalf("invalid request %s: %v", data, err)
+		}
+		if request.Version != PluginProtocolVersion || request.Action != "flush-cache" || request.Kind != "Pod" || request.Name != "web-1" || request.Resource.Namespace != "shop" {
+			t.Errorf("request = %s, want flush-cache of Pod shop/web-1", data)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		plugin  Plugin
+		wantErr string
+	}{
+		{"exit status", Plugin{Command: []string{writePlugin(t, "echo 'cache unavailable' >&2; exit 3")}}, "exit status 3: cache unavailable"},
+		{"invalid response", Plugin{Command: []string{writePlugin(t, "echo done")}}, "invalid response"},
+		{"timeout", Plugin{Command: []string{writePlugin(t, "sleep 5")}, Timeout: 100 * time.Millisecond}, "timed out after 100ms"},
+		{"output limit", Plugin{Command: []string{writePlugin(t, `echo '{"success": true, "message": "flushed"}'`)}, MaxOutputBytes: 10}, "response exceeds 10 bytes"},
+		{"other kind", Plugin{Command: []string{"/bin/true"}, Kinds: []string{"Deployment"}}, "does not apply to Pod resources"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Name = "flush-cache"
+			tt.plugin.Env = env
+			engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, Plugins: []Plugin{tt.plugin}})
+
+			start := time.Now()
+			result, err := engine.ExecuteAction(context.Background(), "flush-cache", pod, "shop")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || result.Success {
+				t.Errorf("ExecuteAction() = %+v, %v, want error %q", result, err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("ExecuteAction() took %s, want the plugin to be killed", elapsed)
+			}
+		})
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "ran")
+		plugin := Plugin{Name: "flush-cache", Command: []string{writePlugin(t, `touch "$MARKER"`)}, Env: map[string]string{"PATH": env["PATH"], "MARKER": marker}}
+		engine := NewEngine(fake.NewSimpleClientset(), RemediationConfig{Enabled: true, DryRun: true, Plugins: []Plugin{plugin}})
+
+		result, err := engine.ExecuteAction(context.Background(), "flush-cache", pod, "shop")
+		if err != nil || !result.Success || !strings.HasPrefix(result.Message, "Dry run") {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a dry run", result, err)
+		}
+		if _, err := os.Stat(marker); !os.IsNotExist(err) {
+			t.Errorf("plugin ran in dry-run mode")
+		}
+	})
+}
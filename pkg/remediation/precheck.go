@@ -0,0 +1,504 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Pre-checks an action passes through before it is applied
+const (
+	PreCheckPolicy      = "policy"
+	PreCheckConflict    = "conflict"
+	PreCheckPDB         = "pdb"
+	PreCheckCapacity    = "capacity"
+	PreCheckBlastRadius = "blast-radius"
+)
+
+// Outcomes of a pre-check
+const (
+	PreCheckPassed  = "passed"
+	PreCheckFailed  = "failed"
+	PreCheckSkipped = "skipped"
+)
+
+// DefaultPreChecks are the pre-checks of the built-in actions unless configured
+// otherwise; other actions, e.g. plugins, run the policy and conflict checks
+var DefaultPreChecks = map[string][]string{
+	"restart-pod":            {PreCheckPolicy, PreCheckConflict, PreCheckBlastRadius},
+	"restart-container":      {PreCheckPolicy, PreCheckConflict},
+	"rolling-restart-pods":   {PreCheckPolicy, PreCheckConflict, PreCheckPDB, PreCheckBlastRadius},
+	"restart-deployment":     {PreCheckPolicy, PreCheckConflict, PreCheckCapacity, PreCheckBlastRadius},
+	"rollback-deployment":    {PreCheckPolicy, PreCheckConflict, PreCheckBlastRadius},
+	"scale-replicas":         {PreCheckPolicy, PreCheckConflict, PreCheckCapacity},
+	ActionRemoveFinalizer:    {PreCheckPolicy, PreCheckConflict},
+	ActionCordonNode:         {PreCheckPolicy, PreCheckConflict},
+	ActionCordonDrainNode:    {PreCheckPolicy, PreCheckConflict, PreCheckBlastRadius},
+	ActionCleanupEvictedPods: {PreCheckPolicy},
+	ActionForceDeletePod:     {PreCheckPolicy, PreCheckConflict},
+}
+
+// PreCheckResult is the outcome of a pre-check of an action
+type PreCheckResult struct {
+	Name    string `yaml:"name"`
+	Outcome string `yaml:"outcome"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// preCheck decides whether an action may be applied to a resource, returning
+// the outcome and a message explaining it
+type preCheck func(e *Engine, ctx context.Context, action string, resource interface{}, namespace string) (string, string)
+
+// preChecks are the pre-checks in the order they run
+var preChecks = []struct {
+	name  string
+	check preCheck
+}{
+	{PreCheckPolicy, (*Engine).policyCheck},
+	{PreCheckConflict, (*Engine).conflictCheck},
+	{PreCheckPDB, (*Engine).pdbCheck},
+	{PreCheckCapacity, (*Engine).capacityCheck},
+	{PreCheckBlastRadius, (*Engine).blastRadiusCheck},
+}
+
+// runPreChecks runs the pre-checks of an action, stopping at the first that
+// fails; the checks after it are recorded as skipped. It returns the outcomes
+// and the failed check, if any.
+func (e *Engine) runPreChecks(ctx context.Context, action string, resource interface{}, namespace string) ([]PreCheckResult, *PreCheckResult) {
+	enabled := e.preChecksOf(action)
+	results := make([]PreCheckResult, 0, len(enabled))
+	failed := -1
+	for _, pc := range preChecks {
+		if !enabled[pc.name] {
+			continue
+		}
+		if failed >= 0 {
+			results = append(results, PreCheckResult{
+				Name:    pc.name,
+				Outcome: PreCheckSkipped,
+				Message: fmt.Sprintf("not run after the %s check failed", results[failed].Name),
+			})
+			continue
+		}
+
+		outcome, message := pc.check(e, ctx, action, resource, namespace)
+		results = append(results, PreCheckResult{Name: pc.name, Outcome: outcome, Message: message})
+		if outcome == PreCheckFailed {
+			failed = len(results) - 1
+		}
+	}
+
+	if failed < 0 {
+		return results, nil
+	}
+	return results, &results[failed]
+}
+
+// preChecksOf returns the pre-checks an action runs: the configured ones, or
+// its defaults. The policy check always runs.
+func (e *Engine) preChecksOf(action string) map[string]bool {
+	names, configured := e.config.PreChecks[action]
+	if !configured {
+		names, configured = DefaultPreChecks[action]
+	}
+	if !configured {
+		names = []string{PreCheckPolicy, PreCheckConflict}
+	}
+
+	enabled := map[string]bool{PreCheckPolicy: true}
+	for _, name := range names {
+		enabled[name] = true
+	}
+	return enabled
+}
+
+// PreChecks returns the names of the pre-checks an action runs, in the order
+// they run
+func (e *Engine) PreChecks(action string) []string {
+	enabled := e.preChecksOf(action)
+	names := make([]string, 0, len(enabled))
+	for _, pc := range preChecks {
+		if enabled[pc.name] {
+			names = append(names, pc.name)
+		}
+	}
+	return names
+}
+
+// policyCheck enforces the actions a namespace opted in to: rollbacks, scaling
+// and force deletion are only applied where the namespace settings allow them
+func (e *Engine) policyCheck(ctx context.Context, action string, resource interface{}, namespace string) (string, string) {
+	nsConfig := e.GetNamespaceConfig(namespace)
+	switch {
+	case action == "rollback-deployment" && !nsConfig.AutoRollbackEnabled:
+		return PreCheckFailed, fmt.Sprintf("auto rollback is disabled for namespace %s", namespace)
+	case action == "scale-replicas" && !nsConfig.AutoScaleEnabled:
+		return PreCheckFailed, fmt.Sprintf("auto scaling is disabled for namespace %s", namespace)
+	case action == ActionForceDeletePod && !nsConfig.ForceDeleteEnabled:
+		return PreCheckFailed, fmt.Sprintf("force deleting pods is not enabled for namespace %s", namespace)
+	}
+	return PreCheckPassed, "allowed by the namespace settings"
+}
+
+// conflictCheck reads the resource again and fails if it changed since the
+// issue was detected: it is gone, was recreated, started deleting or, for
+// resources with a generation, its spec was edited. Resources that were not
+// read from the API server, i.e. have no resourceVersion, are not checked.
+func (e *Engine) conflictCheck(ctx context.Context, action string, resource interface{}, namespace string) (string, string) {
+	observed, ok := resource.(metav1.Object)
+	if !ok || observed.GetResourceVersion() == "" {
+		return PreCheckSkipped, "the resource was not read from the API server"
+	}
+
+	var current metav1.Object
+	var err error
+	client := e.clientFor(ctx)
+	switch r := resource.(type) {
+	case *corev1.Pod:
+		current, err = client.CoreV1().Pods(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+	case *appsv1.Deployment:
+		current, err = client.AppsV1().Deployments(r.Namespace).Get(ctx, r.Name, metav1.GetOptions{})
+	case *corev1.Node:
+		current, err = client.CoreV1().Nodes().Get(ctx, r.Name, metav1.GetOptions{})
+	default:
+		return PreCheckSkipped, "changes of the resource kind are not checked"
+	}
+
+	name := observed.GetName()
+	switch {
+	case apierrors.IsNotFound(err):
+		return PreCheckFailed, fmt.Sprintf("%s no longer exists", name)
+	case err != nil:
+		return PreCheckFailed, fmt.Sprintf("failed to read %s: %v", name, err)
+	case current.GetUID() != observed.GetUID():
+		return PreCheckFailed, fmt.Sprintf("%s was recreated since the issue was detected", name)
+	case observed.GetDeletionTimestamp() == nil && current.GetDeletionTimestamp() != nil:
+		return PreCheckFailed, fmt.Sprintf("%s started deleting since the issue was detected", name)
+	case observed.GetGeneration() != 0 && current.GetGeneration() != observed.GetGeneration():
+		return PreCheckFailed, fmt.Sprintf("%s changed since the issue was detected: generation %d, detected at generation %d",
+			name, current.GetGeneration(), observed.GetGeneration())
+	}
+	return PreCheckPassed, fmt.Sprintf("%s is unchanged", name)
+}
+
+// pdbCheck fails if a PodDisruptionBudget allows no disruption of a ready pod
+// the action removes: the pod itself, the pods of a workload or the pods a
+// drain evicts from a node
+func (e *Engine) pdbCheck(ctx context.Context, action string, resource interface{}, namespace string) (string, string) {
+	pods, err := e.disruptedPods(ctx, action, resource)
+	if err != nil {
+		return PreCheckFailed, err.Error()
+	}
+	if pods == nil {
+		return PreCheckSkipped, "the action removes no pods"
+	}
+
+	budgets := make(map[string][]disruptionBudget) // Key: namespace
+	for i := range pods {
+		pod := &pods[i]
+		if !isPodReady(pod) {
+			// Removing a pod that is not ready does not reduce availability
+			continue
+		}
+		if _, listed := budgets[pod.Namespace]; !listed {
+			if budgets[pod.Namespace], err = e.listBudgets(ctx, pod.Namespace); err != nil {
+				return PreCheckFailed, err.Error()
+			}
+		}
+		for _, budget := range budgets[pod.Namespace] {
+			if budget.allowed < 1 && budget.selector.Matches(labels.Set(pod.Labels)) {
+				return PreCheckFailed, fmt.Sprintf("PodDisruptionBudget %s/%s allows no disruption of pod %s", pod.Namespace, budget.name, pod.Name)
+			}
+		}
+	}
+	return PreCheckPassed, fmt.Sprintf("PodDisruptionBudgets allow removing %d pods", len(pods))
+}
+
+// disruptionBudget is the part of a PodDisruptionBudget the pdb check needs
+type disruptionBudget struct {
+	name     string
+	selector labels.Selector
+	allowed  int32
+}
+
+// listBudgets returns the PodDisruptionBudgets of a namespace
+func (e *Engine) listBudgets(ctx context.Context, namespace string) ([]disruptionBudget, error) {
+	list, err := e.clientFor(ctx).PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+
+	budgets := make([]disruptionBudget, 0, len(list.Items))
+	for _, pdb := range list.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector of PodDisruptionBudget %s: %w", pdb.Name, err)
+		}
+		budgets = append(budgets, disruptionBudget{name: pdb.Name, selector: selector, allowed: pdb.Status.DisruptionsAllowed})
+	}
+	return budgets, nil
+}
+
+// disruptedPods returns the pods an action removes, or nil if it removes none
+func (e *Engine) disruptedPods(ctx context.Context, action string, resource interface{}) ([]corev1.Pod, error) {
+	switch r := resource.(type) {
+	case *corev1.Pod:
+		if action != "rolling-restart-pods" {
+			return []corev1.Pod{*r}, nil
+		}
+	case *appsv1.Deployment:
+	case *corev1.Node:
+		if action != ActionCordonDrainNode {
+			return nil, nil
+		}
+		pods, err := e.drainablePods(ctx, r.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		return append([]corev1.Pod{}, pods...), nil
+	default:
+		return nil, nil
+	}
+
+	workload, err := workloadPodsOf(resource)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := e.listWorkloadPods(ctx, workload)
+	if err != nil {
+		return nil, err
+	}
+	return append([]corev1.Pod{}, pods...), nil
+}
+
+// capacityCheck fails if the schedulable nodes lack the free CPU and memory for
+// the pods an action adds: the surge pods of a rolling restart or the replicas
+// scaling adds. Requests are compared with the allocatable resources of ready,
+// schedulable nodes less the requests of their pods; node selectors, affinity
+// and taints are ignored, so the check catches shortages rather than proving
+// the pods can be scheduled.
+func (e *Engine) capacityCheck(ctx context.Context, action string, resource interface{}, namespace string) (string, string) {
+	deployment, err := e.deploymentOf(ctx, resource)
+	if err != nil {
+		return PreCheckFailed, err.Error()
+	}
+	if deployment == nil {
+		return PreCheckSkipped, "the action adds no pods"
+	}
+
+	replicas := deploymentReplicas(deployment)
+	var added int64
+	switch action {
+	case "scale-replicas":
+		added = int64(scaledReplicas(replicas) - replicas)
+	case "restart-deployment":
+		added = surgePods(deployment, replicas)
+	}
+	if added <= 0 {
+		return PreCheckSkipped, fmt.Sprintf("the action adds no pods to deployment %s", deployment.Name)
+	}
+
+	requests := podRequests(deployment.Spec.Template.Spec)
+	if requests.Cpu().IsZero() && requests.Memory().IsZero() {
+		return PreCheckPassed, fmt.Sprintf("the pods of deployment %s request no CPU or memory", deployment.Name)
+	}
+
+	free, err := e.freeCapacity(ctx)
+	if err != nil {
+		return PreCheckFailed, err.Error()
+	}
+	var fitting int64
+	for _, node := range free {
+		fitting += podsFitting(node, requests)
+		if fitting >= added {
+			return PreCheckPassed, fmt.Sprintf("the free capacity of the nodes fits %d more pods of deployment %s", added, deployment.Name)
+		}
+	}
+	return PreCheckFailed, fmt.Sprintf("deployment %s needs %d more pods requesting %s CPU and %s memory each, but the free capacity of %d schedulable nodes fits %d",
+		deployment.Name, added, requests.Cpu(), requests.Memory(), len(free), fitting)
+}
+
+// deploymentOf returns the deployment an action on a Deployment, or on a pod
+// of one, adds pods to, as currently stored; it is nil for other resources
+func (e *Engine) deploymentOf(ctx context.Context, resource interface{}) (*appsv1.Deployment, error) {
+	client := e.clientFor(ctx)
+	namespace, name := "", ""
+	switch r := resource.(type) {
+	case *appsv1.Deployment:
+		namespace, name = r.Namespace, r.Name
+	case *corev1.Pod:
+		owner := metav1.GetControllerOf(r)
+		if owner == nil || owner.Kind != "ReplicaSet" {
+			return nil, nil
+		}
+		replicaSet, err := client.AppsV1().ReplicaSets(r.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get replicaset: %w", err)
+		}
+		owner = metav1.GetControllerOf(replicaSet)
+		if owner == nil || owner.Kind != "Deployment" {
+			return nil, nil
+		}
+		namespace, name = r.Namespace, owner.Name
+	default:
+		return nil, nil
+	}
+
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	return deployment, nil
+}
+
+// deploymentReplicas returns the desired replicas of a deployment
+func deploymentReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+// surgePods returns the pods a rolling update of a deployment adds on top of
+// its replicas, rounding a percentage up like the deployment controller
+func surgePods(deployment *appsv1.Deployment, replicas int32) int64 {
+	strategy := deployment.Spec.Strategy
+	if strategy.Type == appsv1.RecreateDeploymentStrategyType {
+		return 0
+	}
+	surge := intstr.FromString("25%")
+	if strategy.RollingUpdate != nil && strategy.RollingUpdate.MaxSurge != nil {
+		surge = *strategy.RollingUpdate.MaxSurge
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(&surge, int(replicas), true)
+	if err != nil {
+		return 0
+	}
+	return int64(value)
+}
+
+// podRequests returns the CPU and memory the containers of a pod request
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	for _, container := range spec.Containers {
+		cpu.Add(*container.Resources.Requests.Cpu())
+		memory.Add(*container.Resources.Requests.Memory())
+	}
+	return corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}
+}
+
+// freeCapacity returns the allocatable CPU and memory of the ready, schedulable
+// nodes less the requests of the pods running on them
+func (e *Engine) freeCapacity(ctx context.Context) ([]corev1.ResourceList, error) {
+	client := e.clientFor(ctx)
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	free := make(map[string]corev1.ResourceList)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable || !isNodeReady(&node) {
+			continue
+		}
+		free[node.Name] = corev1.ResourceList{
+			corev1.ResourceCPU:    node.Status.Allocatable.Cpu().DeepCopy(),
+			corev1.ResourceMemory: node.Status.Allocatable.Memory().DeepCopy(),
+		}
+	}
+	for _, pod := range pods.Items {
+		available, found := free[pod.Spec.NodeName]
+		if !found || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests := podRequests(pod.Spec)
+		for name, quantity := range requests {
+			remaining := available[name]
+			remaining.Sub(quantity)
+			available[name] = remaining
+		}
+	}
+
+	capacity := make([]corev1.ResourceList, 0, len(free))
+	for _, available := range free {
+		capacity = append(capacity, available)
+	}
+	return capacity, nil
+}
+
+// podsFitting returns how many pods with the given requests fit in the free
+// capacity of a node
+func podsFitting(free, requests corev1.ResourceList) int64 {
+	fitting := int64(math.MaxInt64)
+	for name, request := range requests {
+		if request.IsZero() {
+			continue
+		}
+		available := free[name]
+		fitting = min(fitting, max(available.MilliValue(), 0)/request.MilliValue())
+	}
+	return fitting
+}
+
+// isNodeReady returns true if the node has the Ready condition set to true
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// blastRadiusCheck fails if an action disrupts more than MaxAffectedPods pods:
+// a pod, the replicas of a deployment or the pods a drain evicts. With
+// FailureDomainAware, it also fails if disrupting a pod leaves a failure domain
+// of its workload without ready pods.
+func (e *Engine) blastRadiusCheck(ctx context.Context, action string, resource interface{}, namespace string) (string, string) {
+	affected := 0
+	switch r := resource.(type) {
+	case *appsv1.Deployment:
+		affected = int(deploymentReplicas(r))
+	default:
+		pods, err := e.disruptedPods(ctx, action, resource)
+		if err != nil {
+			return PreCheckFailed, err.Error()
+		}
+		if pods == nil {
+			return PreCheckSkipped, "the action disrupts no pods"
+		}
+		affected = len(pods)
+	}
+
+	limit := e.config.MaxAffectedPods
+	if limit > 0 && affected > limit {
+		return PreCheckFailed, fmt.Sprintf("the action disrupts %d pods, more than the %d allowed", affected, limit)
+	}
+
+	if pod, ok := resource.(*corev1.Pod); ok && affected == 1 && e.config.FailureDomainAware {
+		if report, err := e.checkFailureDomains(ctx, pod); err != nil {
+			if report != nil {
+				log.FromContext(ctx).Info("Refusing to disrupt pod to preserve failure domain availability",
+					"pod", pod.Name,
+					"namespace", pod.Namespace,
+					"domain", report.Domain,
+					"readyPods", report.ReadyPods)
+			}
+			return PreCheckFailed, err.Error()
+		}
+	}
+	return PreCheckPassed, fmt.Sprintf("the action disrupts %d pods", affected)
+}
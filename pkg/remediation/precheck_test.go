@@ -0,0 +1,212 @@
+package remediation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// outcomes returns the pre-check results as "name=outcome" strings
+func outcomes(results []PreCheckResult) string {
+	var parts []string
+	for _, result := range results {
+		parts = append(parts, result.Name+"="+result.Outcome)
+	}
+	return strings.Join(parts, " ")
+}
+
+// capacityNode returns a ready node with the given allocatable CPU
+func capacityNode(name, cpu string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestPreChecks(t *testing.T) {
+	replicas := int32(4)
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", UID: "web-uid", ResourceVersion: "7", Generation: 3},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "web",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					}},
+				}}},
+			},
+		},
+	}
+	webPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{
+			Name:      "web",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	budget := func(allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+	optedIn := map[string]NamespaceRemediationConfig{"shop": {Enabled: true, AutoRollbackEnabled: true, AutoScaleEnabled: true}}
+
+	tests := []struct {
+		name     string
+		config   RemediationConfig
+		objects  []runtime.Object
+		action   string
+		resource interface{}
+		want     string
+		message  string
+	}{
+		{
+			name:     "policy refuses actions the namespace did not opt in to",
+			action:   "scale-replicas",
+			resource: web,
+			want:     "policy=failed conflict=skipped capacity=skipped",
+			message:  "auto scaling is disabled for namespace shop",
+		},
+		{
+			name:     "conflict refuses a resource changed since it was detected",
+			config:   RemediationConfig{Namespaces: optedIn},
+			objects:  []runtime.Object{func() *appsv1.Deployment { d := web.DeepCopy(); d.Generation = 4; return d }()},
+			action:   "rollback-deployment",
+			resource: web,
+			want:     "policy=passed conflict=failed blast-radius=skipped",
+			message:  "generation 4, detected at generation 3",
+		},
+		{
+			name:     "conflict refuses a resource that no longer exists",
+			config:   RemediationConfig{Namespaces: optedIn},
+			action:   "rollback-deployment",
+			resource: web,
+			want:     "policy=passed conflict=failed blast-radius=skipped",
+			message:  "web no longer exists",
+		},
+		{
+			name:     "pdb refuses to remove pods a budget protects",
+			objects:  []runtime.Object{web.DeepCopy(), webPod.DeepCopy(), budget(0)},
+			action:   "rolling-restart-pods",
+			resource: web,
+			want:     "policy=passed conflict=passed pdb=failed blast-radius=skipped",
+			message:  "PodDisruptionBudget shop/web allows no disruption of pod web-1",
+		},
+		{
+			name:     "pdb passes when the budget allows disruptions",
+			objects:  []runtime.Object{web.DeepCopy(), webPod.DeepCopy(), budget(1)},
+			action:   "rolling-restart-pods",
+			resource: web,
+			want:     "policy=passed conflict=passed pdb=passed blast-radius=passed",
+		},
+		{
+			name:     "capacity refuses surge pods the nodes cannot fit",
+			objects:  []runtime.Object{web.DeepCopy(), webPod.DeepCopy(), capacityNode("node-1", "1500m")},
+			action:   "restart-deployment",
+			resource: web,
+			want:     "policy=passed conflict=passed capacity=failed blast-radius=skipped",
+			message:  "needs 1 more pods requesting 1 CPU",
+		},
+		{
+			name:     "capacity passes when the nodes fit the surge pods",
+			objects:  []runtime.Object{web.DeepCopy(), webPod.DeepCopy(), capacityNode("node-1", "1500m"), capacityNode("node-2", "2")},
+			action:   "restart-deployment",
+			resource: web,
+			want:     "policy=passed conflict=passed capacity=passed blast-radius=passed",
+		},
+		{
+			name:     "blast radius refuses actions disrupting too many pods",
+			config:   RemediationConfig{Namespaces: optedIn, MaxAffectedPods: 3},
+			objects:  []runtime.Object{web.DeepCopy()},
+			action:   "rollback-deployment",
+			resource: web,
+			want:     "policy=passed conflict=passed blast-radius=failed",
+			message:  "disrupts 4 pods, more than the 3 allowed",
+		},
+		{
+			name:     "configured pre-checks replace the defaults but keep policy",
+			config:   RemediationConfig{PreChecks: map[string][]string{"restart-pod": {PreCheckPDB}}},
+			objects:  []runtime.Object{webPod.DeepCopy(), budget(0)},
+			action:   "restart-pod",
+			resource: webPod,
+			want:     "policy=passed pdb=failed",
+			message:  "allows no disruption of pod web-1",
+		},
+		{
+			name:     "plugins run the policy and conflict checks",
+			action:   "page-oncall",
+			resource: webPod,
+			want:     "policy=passed conflict=skipped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(fake.NewSimpleClientset(tt.objects...), tt.config)
+
+			results, failed := engine.runPreChecks(context.Background(), tt.action, tt.resource, "shop")
+			if got := outcomes(results); got != tt.want {
+				t.Errorf("pre-checks = %q, want %q", got, tt.want)
+			}
+			if (failed != nil) != (tt.message != "") {
+				t.Fatalf("failed = %+v, want a failure %q", failed, tt.message)
+			}
+			if failed != nil && !strings.Contains(failed.Message, tt.message) {
+				t.Errorf("failure = %q, want it to contain %q", failed.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestExecuteActionRecordsPreChecks(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", UID: "web-1", ResourceVersion: "1"}}
+
+	t.Run("failed pre-check", func(t *testing.T) {
+		client := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", UID: "web-1-recreated"}})
+		engine := NewEngine(client, RemediationConfig{Enabled: true})
+
+		result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "shop")
+		if err != nil || result.Success || result.Message != "Pre-check conflict failed: web-1 was recreated since the issue was detected" {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a conflict", result, err)
+		}
+		if got := outcomes(result.PreChecks); got != "policy=passed conflict=failed blast-radius=skipped" {
+			t.Errorf("PreChecks = %q", got)
+		}
+		if _, err := client.CoreV1().Pods("shop").Get(context.Background(), "web-1", metav1.GetOptions{}); err != nil {
+			t.Errorf("pod removed despite the failed pre-check: %v", err)
+		}
+	})
+
+	t.Run("passed pre-checks", func(t *testing.T) {
+		engine := NewEngine(fake.NewSimpleClientset(pod.DeepCopy()), RemediationConfig{Enabled: true, DryRun: true})
+
+		result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "shop")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() = %+v, %v, want a dry run", result, err)
+		}
+		if got := outcomes(result.PreChecks); got != "policy=passed conflict=passed blast-radius=passed" {
+			t.Errorf("PreChecks = %q", got)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Audit annotations set on resources changed by manually requested actions
+const (
+	AnnotationRequestedBy   = "kubeguardian.io/requested-by"
+	AnnotationRequestSource = "kubeguardian.io/request-source"
+)
+
+// Requester identifies the user who manually triggered an action
+type Requester struct {
+	User   string              `json:"user"`
+	Groups []string            `json:"groups,omitempty"`
+	Extra  map[string][]string `json:"extra,omitempty"`
+	Source string              `json:"source"` // e.g. "api", "cli", "slack"
+}
+
+// ClientFactory creates a Kubernetes client that impersonates the given requester
+type ClientFactory func(requester Requester) (kubernetes.Interface, error)
+
+type requesterKey struct{}
+
+type clientKey struct{}
+
+// WithRequester returns a context carrying the requester of a manual action
+func WithRequester(ctx context.Context, requester Requester) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requester)
+}
+
+// RequesterFromContext returns the requester of a manual action, if any
+func RequesterFromContext(ctx context.Context) (Requester, bool) {
+	requester, ok := ctx.Value(requesterKey{}).(Requester)
+	return requester, ok && requester.User != ""
+}
+
+// withClient returns a context carrying the client to use for an action
+func withClient(ctx context.Context, client kubernetes.Interface) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFor returns the client resolved for the current action, falling back to the engine client
+func (e *Engine) clientFor(ctx context.Context) kubernetes.Interface {
+	if client, ok := ctx.Value(clientKey{}).(kubernetes.Interface); ok {
+		return client
+	}
+	return e.client
+}
+
+// SetImpersonation configures a factory used to execute manually requested actions
+// as the requesting user, so cluster audit logs attribute the change correctly
+func (e *Engine) SetImpersonation(factory ClientFactory) {
+	e.impersonate = factory
+}
+
+// auditAnnotations returns the annotations recording who requested an action
+func auditAnnotations(ctx context.Context) map[string]string {
+	requester, ok := RequesterFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return map[string]string{
+		AnnotationRequestedBy:   requester.User,
+		AnnotationRequestSource: requester.Source,
+	}
+}
+
+// buildMergePatch creates a merge patch for the given spec fields and annotations,
+// adding audit annotations for manually requested actions
+func buildMergePatch(ctx context.Context, spec map[string]interface{}, annotations map[string]string) ([]byte, error) {
+	merged := make(map[string]string)
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	for k, v := range auditAnnotations(ctx) {
+		merged[k] = v
+	}
+
+	patch := make(map[string]interface{})
+	if len(spec) > 0 {
+		patch["spec"] = spec
+	}
+	if len(merged) > 0 {
+		patch["metadata"] = map[string]interface{}{"annotations": merged}
+	}
+	return json.Marshal(patch)
+}
@@ -0,0 +1,109 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExecuteActionImpersonatesRequester(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+
+	controllerClient := fake.NewSimpleClientset(pod.DeepCopy())
+	userClient := fake.NewSimpleClientset(pod.DeepCopy())
+
+	engine := NewEngine(controllerClient, RemediationConfig{Enabled: true})
+
+	var impersonated Requester
+	engine.SetImpersonation(func(requester Requester) (kubernetes.Interface, error) {
+		impersonated = requester
+		return userClient, nil
+	})
+
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Groups: []string{"sre"}, Source: "api"})
+	result, err := engine.ExecuteAction(ctx, "restart-pod", pod, "default")
+	if err != nil {
+		t.Fatalf("ExecuteAction() error = %v", err)
+	}
+
+	if !result.Success {
+		t.Fatalf("expected success, got %q", result.Message)
+	}
+	if result.RequestedBy != "alice" {
+		t.Errorf("RequestedBy = %q, want %q", result.RequestedBy, "alice")
+	}
+	if impersonated.User != "alice" {
+		t.Errorf("impersonated user = %q, want %q", impersonated.User, "alice")
+	}
+
+	if len(userClient.Actions()) == 0 {
+		t.Error("expected the action to use the impersonating client")
+	}
+	if len(controllerClient.Actions()) != 0 {
+		t.Errorf("expected no calls on the controller client, got %d", len(controllerClient.Actions()))
+	}
+}
+
+func TestExecuteActionWithoutRequesterUsesEngineClient(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+	}
+
+	client := fake.NewSimpleClientset(pod)
+	engine := NewEngine(client, RemediationConfig{Enabled: true})
+	engine.SetImpersonation(func(requester Requester) (kubernetes.Interface, error) {
+		t.Fatal("impersonation should only be used for manual actions")
+		return nil, nil
+	})
+
+	result, err := engine.ExecuteAction(context.Background(), "restart-pod", pod, "default")
+	if err != nil {
+		t.Fatalf("ExecuteAction() error = %v", err)
+	}
+	if result.RequestedBy != "" {
+		t.Errorf("RequestedBy = %q, want empty", result.RequestedBy)
+	}
+}
+
+func TestBuildMergePatchAddsAuditAnnotations(t *testing.T) {
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Source: "api"})
+
+	data, err := buildMergePatch(ctx, map[string]interface{}{"replicas": 3}, map[string]string{"existing": "value"})
+	if err != nil {
+		t.Fatalf("buildMergePatch() error = %v", err)
+	}
+
+	var patch struct {
+		Spec     map[string]interface{} `json:"spec"`
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &patch); err != nil {
+		t.Fatalf("failed to decode patch: %v", err)
+	}
+
+	if patch.Spec["replicas"] != float64(3) {
+		t.Errorf("spec.replicas = %v, want 3", patch.Spec["replicas"])
+	}
+	annotations := patch.Metadata.Annotations
+	if annotations[AnnotationRequestedBy] != "alice" || annotations[AnnotationRequestSource] != "api" {
+		t.Errorf("missing audit annotations: %v", annotations)
+	}
+	if annotations["existing"] != "value" {
+		t.Errorf("existing annotation not preserved: %v", annotations)
+	}
+}
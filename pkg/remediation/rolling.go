@@ -0,0 +1,210 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Defaults of the rolling-restart-pods action
+const (
+	DefaultRollingRestartTimeout  = 5 * time.Minute
+	DefaultRollingRestartInterval = 5 * time.Second
+)
+
+// workloadPods selects the pods of a workload
+type workloadPods struct {
+	namespace string
+	name      string
+	selector  labels.Selector // Set for Deployments
+	owner     *metav1.OwnerReference
+}
+
+// matches returns true if a pod belongs to the workload
+func (w workloadPods) matches(pod *corev1.Pod) bool {
+	if w.selector != nil {
+		return w.selector.Matches(labels.Set(pod.Labels))
+	}
+	return isOwnedBy(pod, w.owner.UID)
+}
+
+// listWorkloadPods returns the pods of a workload
+func (e *Engine) listWorkloadPods(ctx context.Context, workload workloadPods) ([]corev1.Pod, error) {
+	opts := metav1.ListOptions{}
+	if workload.selector != nil {
+		opts.LabelSelector = workload.selector.String()
+	}
+	pods, err := e.clientFor(ctx).CoreV1().Pods(workload.namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var matching []corev1.Pod
+	for i := range pods.Items {
+		if workload.matches(&pods.Items[i]) {
+			matching = append(matching, pods.Items[i])
+		}
+	}
+	return matching, nil
+}
+
+// workloadPodsOf returns the pods of a Deployment, or the pods sharing the controller of a pod
+func workloadPodsOf(resource interface{}) (workloadPods, error) {
+	switch r := resource.(type) {
+	case *appsv1.Deployment:
+		selector, err := metav1.LabelSelectorAsSelector(r.Spec.Selector)
+		if err != nil {
+			return workloadPods{}, fmt.Errorf("invalid selector of deployment %s: %w", r.Name, err)
+		}
+		return workloadPods{namespace: r.Namespace, name: r.Name, selector: selector}, nil
+	case *corev1.Pod:
+		owner := metav1.GetControllerOf(r)
+		if owner == nil {
+			return workloadPods{}, fmt.Errorf("pod %s has no controller", r.Name)
+		}
+		return workloadPods{namespace: r.Namespace, name: owner.Name, owner: owner}, nil
+	default:
+		return workloadPods{}, fmt.Errorf("resource is not a valid Pod or Deployment")
+	}
+}
+
+// rollingRestartPods deletes the pods of a workload one at a time, waiting for the
+// workload to get back to its number of ready pods before deleting the next one
+func (e *Engine) rollingRestartPods(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+
+	workload, err := workloadPodsOf(resource)
+	if err != nil {
+		return &Result{
+			Action:     "rolling-restart-pods",
+			Success:    false,
+			Message:    err.Error(),
+			Resource:   e.getResourceName(resource),
+			Namespace:  namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, err
+	}
+
+	failed := func(message string, err error) (*Result, error) {
+		return &Result{
+			Action:     "rolling-restart-pods",
+			Success:    false,
+			Message:    message,
+			Resource:   workload.name,
+			Namespace:  workload.namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, err
+	}
+
+	pods, err := e.listWorkloadPods(ctx, workload)
+	if err != nil {
+		return failed(fmt.Sprintf("Failed to list pods: %v", err), err)
+	}
+	if len(pods) == 0 {
+		return failed(fmt.Sprintf("No pods found for %s", workload.name), nil)
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would restart pods one at a time", "workload", workload.name, "namespace", workload.namespace, "pods", len(pods))
+		return &Result{
+			Action:     "rolling-restart-pods",
+			Success:    true,
+			Message:    fmt.Sprintf("Dry run: would restart %d pods of %s one at a time", len(pods), workload.name),
+			Resource:   workload.name,
+			Namespace:  workload.namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, nil
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		ready, err := e.readyWorkloadPods(ctx, workload)
+		if err != nil {
+			return failed(fmt.Sprintf("Restarted %d/%d pods: %v", i, len(pods), err), err)
+		}
+
+		announced, err := e.announceRestart(ctx, workload.namespace, pod)
+		if err != nil {
+			return failed(fmt.Sprintf("Restarted %d/%d pods: cancelled during the pre-remediation grace period: %v", i, len(pods), err), err)
+		}
+		if err := e.clientFor(ctx).CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			e.withdrawRestart(ctx, announced)
+			return failed(fmt.Sprintf("Restarted %d/%d pods: failed to delete pod %s: %v", i, len(pods), pod.Name, err), err)
+		}
+		logger.Info("Deleted pod, waiting for its replacement", "pod", pod.Name, "namespace", pod.Namespace, "readyPods", ready)
+
+		if err := e.waitForReadyPods(ctx, workload, ready); err != nil {
+			return failed(fmt.Sprintf("Restarted %d/%d pods: replacement of pod %s not ready: %v", i+1, len(pods), pod.Name, err), nil)
+		}
+	}
+
+	logger.Info("Successfully restarted pods one at a time", "workload", workload.name, "namespace", workload.namespace, "pods", len(pods))
+	return &Result{
+		Action:     "rolling-restart-pods",
+		Success:    true,
+		Message:    fmt.Sprintf("Successfully restarted %d pods of %s one at a time", len(pods), workload.name),
+		Resource:   workload.name,
+		Namespace:  workload.namespace,
+		ExecutedAt: time.Now(),
+		Duration:   time.Since(startTime),
+	}, nil
+}
+
+// readyWorkloadPods counts the ready pods of a workload that are not being deleted
+func (e *Engine) readyWorkloadPods(ctx context.Context, workload workloadPods) (int, error) {
+	pods, err := e.listWorkloadPods(ctx, workload)
+	if err != nil {
+		return 0, err
+	}
+
+	ready := 0
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && isPodReady(&pods[i]) {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+// waitForReadyPods waits until the workload has the given number of ready pods
+// again. Terminating pods are not counted, so a StatefulSet pod only counts once
+// it has been recreated under the same name.
+func (e *Engine) waitForReadyPods(ctx context.Context, workload workloadPods, want int) error {
+	timeout := e.config.RollingRestartTimeout
+	if timeout <= 0 {
+		timeout = DefaultRollingRestartTimeout
+	}
+	interval := e.config.RollingRestartInterval
+	if interval <= 0 {
+		interval = DefaultRollingRestartInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ready, err := e.readyWorkloadPods(ctx, workload)
+		if err == nil && ready >= want {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d/%d pods ready after %s", ready, want, timeout)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func readyPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "cache"}},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestRollingRestartPods(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+		},
+	}
+	config := RemediationConfig{
+		Enabled:                true,
+		RollingRestartTimeout:  200 * time.Millisecond,
+		RollingRestartInterval: 10 * time.Millisecond,
+	}
+
+	t.Run("waits for each replacement", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment, readyPod("cache-a"), readyPod("cache-b"), readyPod("cache-c"))
+		var deleted []string
+		client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			name := action.(k8stesting.DeleteAction).GetName()
+			deleted = append(deleted, name)
+			// The ReplicaSet replaces the pod with one that becomes Ready
+			return false, nil, client.Tracker().Add(readyPod(name + "-new"))
+		})
+
+		engine := NewEngine(client, config)
+		result, err := engine.ExecuteAction(context.Background(), "rolling-restart-pods", deployment, "default")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+		}
+		if len(deleted) != 3 {
+			t.Errorf("deleted pods = %v, want the 3 original pods", deleted)
+		}
+	})
+
+	t.Run("stops when a replacement is not ready", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment, readyPod("cache-a"), readyPod("cache-b"))
+		engine := NewEngine(client, config)
+
+		result, err := engine.ExecuteAction(context.Background(), "rolling-restart-pods", deployment, "default")
+		if err != nil {
+			t.Fatalf("ExecuteAction() error = %v", err)
+		}
+		if result.Success {
+			t.Fatalf("ExecuteAction() succeeded without replacement pods")
+		}
+
+		pods, _ := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		if len(pods.Items) != 1 {
+			t.Errorf("remaining pods = %d, want 1", len(pods.Items))
+		}
+	})
+
+	t.Run("dry run deletes nothing", func(t *testing.T) {
+		client := fake.NewSimpleClientset(deployment, readyPod("cache-a"))
+		dryRun := config
+		dryRun.DryRun = true
+		engine := NewEngine(client, dryRun)
+
+		result, err := engine.ExecuteAction(context.Background(), "rolling-restart-pods", deployment, "default")
+		if err != nil || !result.Success {
+			t.Fatalf("ExecuteAction() failed: %v (%v)", err, result)
+		}
+		pods, _ := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+		if len(pods.Items) != 1 {
+			t.Errorf("remaining pods = %d, want 1", len(pods.Items))
+		}
+	})
+}
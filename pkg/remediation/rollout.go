@@ -0,0 +1,91 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AnnotationRestartedAt is set on the pod template to restart all pods of a
+// Deployment with a rolling update, like kubectl rollout restart
+const AnnotationRestartedAt = "kubectl.kubernetes.io/restartedAt"
+
+// restartDeployment restarts all pods of a deployment with a rolling update
+func (e *Engine) restartDeployment(ctx context.Context, resource interface{}, namespace string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+
+	deployment, ok := resource.(*appsv1.Deployment)
+	if !ok || deployment == nil {
+		return &Result{
+			Action:     "restart-deployment",
+			Success:    false,
+			Message:    "Resource is not a valid Deployment",
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, fmt.Errorf("resource is not a valid Deployment")
+	}
+
+	template := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{AnnotationRestartedAt: time.Now().Format(time.RFC3339)},
+		},
+	}
+	patch, err := buildMergePatch(ctx, map[string]interface{}{"template": template}, nil)
+	if err != nil {
+		return &Result{
+			Action:     "restart-deployment",
+			Success:    false,
+			Message:    fmt.Sprintf("Failed to build restart patch: %v", err),
+			Resource:   deployment.Name,
+			Namespace:  deployment.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+		}, err
+	}
+
+	if e.config.DryRun {
+		logger.Info("Dry run: would restart deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "patch", string(patch))
+		return &Result{
+			Action:     "restart-deployment",
+			Success:    true,
+			Message:    fmt.Sprintf("Dry run: would restart deployment %s", deployment.Name),
+			Resource:   deployment.Name,
+			Namespace:  deployment.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+			PatchType:  types.MergePatchType,
+			Patch:      string(patch),
+		}, nil
+	}
+
+	_, err = e.patchDeployment(ctx, deployment, patch)
+	if err != nil {
+		return &Result{
+			Action:     "restart-deployment",
+			Success:    false,
+			Message:    fmt.Sprintf("Failed to restart deployment: %v", err),
+			Resource:   deployment.Name,
+			Namespace:  deployment.Namespace,
+			ExecutedAt: time.Now(),
+			Duration:   time.Since(startTime),
+			Conflict:   errors.Is(err, ErrConcurrentChange),
+		}, err
+	}
+
+	logger.Info("Successfully restarted deployment", "deployment", deployment.Name, "namespace", deployment.Namespace)
+	return &Result{
+		Action:     "restart-deployment",
+		Success:    true,
+		Message:    fmt.Sprintf("Successfully restarted deployment %s", deployment.Name),
+		Resource:   deployment.Name,
+		Namespace:  deployment.Namespace,
+		ExecutedAt: time.Now(),
+		Duration:   time.Since(startTime),
+	}, nil
+}
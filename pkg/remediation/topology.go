@@ -0,0 +1,109 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultTopologyKey is the node label used to group pods into failure domains
+const DefaultTopologyKey = corev1.LabelTopologyZone
+
+// failureDomainReport describes the ready pods of a workload per failure domain
+type failureDomainReport struct {
+	// Domain is the failure domain of the pod being restarted
+	Domain string
+	// ReadyPods maps each failure domain to the number of ready pods of the workload
+	ReadyPods map[string]int
+}
+
+// checkFailureDomains verifies that disrupting the pod leaves every failure domain used
+// by its workload with at least one ready pod. Pods without a controller are not checked.
+func (e *Engine) checkFailureDomains(ctx context.Context, pod *corev1.Pod) (*failureDomainReport, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || !isPodReady(pod) {
+		// Restarting a pod that is not ready cannot reduce availability
+		return nil, nil
+	}
+
+	client := e.clientFor(ctx)
+	pods, err := client.CoreV1().Pods(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	topologyKey := e.config.TopologyKey
+	if topologyKey == "" {
+		topologyKey = DefaultTopologyKey
+	}
+
+	domains := make(map[string]string) // Key: node name, value: failure domain
+	report := &failureDomainReport{ReadyPods: make(map[string]int)}
+
+	for i := range pods.Items {
+		sibling := &pods.Items[i]
+		if !isOwnedBy(sibling, owner.UID) || !isPodReady(sibling) {
+			continue
+		}
+
+		domain, err := e.failureDomain(ctx, sibling.Spec.NodeName, topologyKey, domains)
+		if err != nil {
+			return nil, err
+		}
+
+		report.ReadyPods[domain]++
+		if sibling.Name == pod.Name {
+			report.Domain = domain
+		}
+	}
+
+	if report.Domain != "" && report.ReadyPods[report.Domain] <= 1 {
+		return report, fmt.Errorf("restarting pod %s would leave failure domain %s with no ready pods", pod.Name, report.Domain)
+	}
+
+	return report, nil
+}
+
+// failureDomain returns the failure domain of a node, falling back to the node name
+// when the node has no topology label
+func (e *Engine) failureDomain(ctx context.Context, nodeName, topologyKey string, cache map[string]string) (string, error) {
+	if domain, exists := cache[nodeName]; exists {
+		return domain, nil
+	}
+
+	domain := nodeName
+	if nodeName != "" {
+		node, err := e.clientFor(ctx).CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		}
+		if zone := node.Labels[topologyKey]; zone != "" {
+			domain = zone
+		}
+	}
+
+	cache[nodeName] = domain
+	return domain, nil
+}
+
+// isOwnedBy returns true if the pod is controlled by the owner with the given UID
+func isOwnedBy(pod *corev1.Pod, uid types.UID) bool {
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.UID == uid
+}
+
+// isPodReady returns true if the pod has the Ready condition set to true
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
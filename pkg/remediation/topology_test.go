@@ -0,0 +1,141 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newReplicaPod returns a pod controlled by the given ReplicaSet UID and scheduled on a node
+func newReplicaPod(name, node string, owner types.UID, ready bool) *corev1.Pod {
+	isController := true
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", UID: owner, Controller: &isController},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+// newZoneNode returns a node labelled with the given zone
+func newZoneNode(name, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		},
+	}
+}
+
+func TestRestartPodFailureDomains(t *testing.T) {
+	nodes := []runtime.Object{
+		newZoneNode("node-a", "zone-a"),
+		newZoneNode("node-b1", "zone-b"),
+		newZoneNode("node-b2", "zone-b"),
+	}
+
+	tests := []struct {
+		name        string
+		pods        []*corev1.Pod
+		target      string
+		wantSuccess bool
+	}{
+		{
+			name: "last ready pod in zone is refused",
+			pods: []*corev1.Pod{
+				newReplicaPod("web-a", "node-a", "rs-1", true),
+				newReplicaPod("web-b1", "node-b1", "rs-1", true),
+			},
+			target:      "web-a",
+			wantSuccess: false,
+		},
+		{
+			name: "zone keeps another ready pod",
+			pods: []*corev1.Pod{
+				newReplicaPod("web-a", "node-a", "rs-1", true),
+				newReplicaPod("web-b1", "node-b1", "rs-1", true),
+				newReplicaPod("web-b2", "node-b2", "rs-1", true),
+			},
+			target:      "web-b1",
+			wantSuccess: true,
+		},
+		{
+			name: "pods of other workloads are ignored",
+			pods: []*corev1.Pod{
+				newReplicaPod("web-b1", "node-b1", "rs-1", true),
+				newReplicaPod("api-b2", "node-b2", "rs-2", true),
+			},
+			target:      "web-b1",
+			wantSuccess: false,
+		},
+		{
+			name: "pod that is not ready can be restarted",
+			pods: []*corev1.Pod{
+				newReplicaPod("web-a", "node-a", "rs-1", false),
+				newReplicaPod("web-b1", "node-b1", "rs-1", true),
+			},
+			target:      "web-a",
+			wantSuccess: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append([]runtime.Object{}, nodes...)
+			var target *corev1.Pod
+			for _, pod := range tt.pods {
+				objects = append(objects, pod)
+				if pod.Name == tt.target {
+					target = pod
+				}
+			}
+
+			engine := NewEngine(fake.NewSimpleClientset(objects...), RemediationConfig{
+				Enabled:            true,
+				FailureDomainAware: true,
+			})
+
+			result, err := engine.ExecuteAction(context.Background(), "restart-pod", target, "default")
+			if err != nil {
+				t.Fatalf("ExecuteAction() error = %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (%s)", result.Success, tt.wantSuccess, result.Message)
+			}
+		})
+	}
+}
+
+func TestFailureDomainFallsBackToNodeName(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	engine := NewEngine(client, RemediationConfig{})
+
+	domain, err := engine.failureDomain(context.Background(), "node-1", DefaultTopologyKey, make(map[string]string))
+	if err != nil {
+		t.Fatalf("failureDomain() error = %v", err)
+	}
+	if domain != "node-1" {
+		t.Errorf("domain = %q, want %q", domain, "node-1")
+	}
+
+	_, err = engine.failureDomain(context.Background(), "missing", DefaultTopologyKey, make(map[string]string))
+	if err == nil {
+		t.Error("expected error for missing node")
+	}
+}
@@ -0,0 +1,253 @@
+package remediation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AnnotationUndoOf links a restored resource to the undo record of the original action
+const AnnotationUndoOf = "kubeguardian.io/undo-of"
+
+// Errors returned by Undo
+var (
+	ErrUndoNotFound  = errors.New("undo record not found")
+	ErrAlreadyUndone = errors.New("action was already undone")
+)
+
+// maxUndoRecords bounds the number of undo records kept in memory
+const maxUndoRecords = 1000
+
+// UndoRecord captures the state of a resource before a reversible action so it can be restored
+type UndoRecord struct {
+	ID          string    `json:"id"`
+	Action      string    `json:"action"`
+	Kind        string    `json:"kind"`
+	Resource    string    `json:"resource"`
+	Namespace   string    `json:"namespace"`
+	ExecutedAt  time.Time `json:"executedAt"`
+	RequestedBy string    `json:"requestedBy,omitempty"`
+	// Generation of the resource after the action; undo is refused once the
+	// resource was changed again. Zero skips the check.
+	Generation int64 `json:"generation,omitempty"`
+
+	// Prior state; only the fields changed by the action are set
+	PriorReplicas    *int32             `json:"priorReplicas,omitempty"`
+	PriorAnnotations map[string]*string `json:"priorAnnotations,omitempty"` // nil value: annotation was absent
+
+	// Audit trail of the undo
+	UndoneAt *time.Time `json:"undoneAt,omitempty"`
+	UndoneBy string     `json:"undoneBy,omitempty"`
+}
+
+// undoLog stores undo records of reversible actions
+type undoLog struct {
+	mu      sync.Mutex
+	records map[string]*UndoRecord
+}
+
+// newUndoLog creates an empty undo log
+func newUndoLog() *undoLog {
+	return &undoLog{records: make(map[string]*UndoRecord)}
+}
+
+// add stores a record, assigning it an ID, and returns the ID
+func (l *undoLog) add(record UndoRecord) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.ID = newUndoID()
+	l.records[record.ID] = &record
+
+	// Drop the oldest records beyond the limit
+	if len(l.records) > maxUndoRecords {
+		var oldest *UndoRecord
+		for _, r := range l.records {
+			if oldest == nil || r.ExecutedAt.Before(oldest.ExecutedAt) {
+				oldest = r
+			}
+		}
+		delete(l.records, oldest.ID)
+	}
+
+	return record.ID
+}
+
+// newUndoID returns a random identifier for an undo record
+func newUndoID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// recordUndo stores the prior state of a resource changed by a reversible action
+func (e *Engine) recordUndo(ctx context.Context, record UndoRecord) string {
+	record.ExecutedAt = time.Now()
+	if requester, ok := RequesterFromContext(ctx); ok {
+		record.RequestedBy = requester.User
+	}
+	return e.undo.add(record)
+}
+
+// UndoRecords returns the undo records, most recent first
+func (e *Engine) UndoRecords() []UndoRecord {
+	e.undo.mu.Lock()
+	defer e.undo.mu.Unlock()
+
+	records := make([]UndoRecord, 0, len(e.undo.records))
+	for _, r := range e.undo.records {
+		records = append(records, *r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ExecutedAt.After(records[j].ExecutedAt)
+	})
+	return records
+}
+
+// Undo restores the state recorded before a reversible action. Each record can be
+// undone once; the restored resource is annotated with the record ID.
+func (e *Engine) Undo(ctx context.Context, id string) (*Result, error) {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+
+	e.undo.mu.Lock()
+	record, exists := e.undo.records[id]
+	if !exists {
+		e.undo.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrUndoNotFound, id)
+	}
+	if record.UndoneAt != nil {
+		e.undo.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s at %s by %s", ErrAlreadyUndone, id, record.UndoneAt.Format(time.RFC3339), record.UndoneBy)
+	}
+	snapshot := *record
+	e.undo.mu.Unlock()
+
+	result := &Result{
+		Action:    "undo",
+		Resource:  snapshot.Resource,
+		Namespace: snapshot.Namespace,
+	}
+	requester, manual := RequesterFromContext(ctx)
+	if manual {
+		result.RequestedBy = requester.User
+	}
+
+	// Undo runs as the requesting user when impersonation is configured
+	if manual && e.impersonate != nil {
+		client, err := e.impersonate(requester)
+		if err != nil {
+			result.Message = fmt.Sprintf("Failed to impersonate %s: %v", requester.User, err)
+			result.ExecutedAt = time.Now()
+			return result, err
+		}
+		ctx = withClient(ctx, client)
+	}
+
+	patch, err := buildUndoPatch(ctx, snapshot)
+	if err == nil && !e.config.DryRun {
+		err = e.undoPatch(ctx, snapshot, patch)
+	}
+	result.ExecutedAt = time.Now()
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to undo %s: %v", snapshot.Action, err)
+		result.Conflict = errors.Is(err, ErrConcurrentChange)
+		return result, err
+	}
+
+	if e.config.DryRun {
+		result.Success = true
+		result.Message = fmt.Sprintf("Dry run: would undo %s on %s/%s (%s)", snapshot.Action, snapshot.Kind, snapshot.Resource, id)
+		result.PatchType = types.MergePatchType
+		result.Patch = string(patch)
+		return result, nil
+	}
+
+	now := time.Now()
+	e.undo.mu.Lock()
+	record.UndoneAt = &now
+	record.UndoneBy = result.RequestedBy
+	e.undo.mu.Unlock()
+
+	logger.Info("Undid remediation action",
+		"id", id,
+		"action", snapshot.Action,
+		"resource", snapshot.Resource,
+		"namespace", snapshot.Namespace,
+		"originalRequestedBy", snapshot.RequestedBy,
+		"undoneBy", result.RequestedBy)
+
+	result.Success = true
+	result.Message = fmt.Sprintf("Undid %s on %s/%s (%s)", snapshot.Action, snapshot.Kind, snapshot.Resource, id)
+	return result, nil
+}
+
+// undoPatch applies the undo patch of a record to the resource as long as it is
+// at the generation the action left it at
+func (e *Engine) undoPatch(ctx context.Context, record UndoRecord, patch []byte) error {
+	current, err := e.clientFor(ctx).AppsV1().Deployments(record.Namespace).Get(ctx, record.Resource, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if record.Generation != 0 && current.Generation != record.Generation {
+		return fmt.Errorf("%w: %s/%s is at generation %d, %s left it at generation %d",
+			ErrConcurrentChange, record.Namespace, record.Resource, current.Generation, record.Action, record.Generation)
+	}
+	_, err = e.patchDeployment(ctx, current, patch)
+	return err
+}
+
+// buildUndoPatch creates a merge patch restoring the prior state of a record
+func buildUndoPatch(ctx context.Context, record UndoRecord) ([]byte, error) {
+	if record.Kind != "Deployment" {
+		return nil, fmt.Errorf("undo is not supported for kind %s", record.Kind)
+	}
+
+	// Prior annotations use nil to remove annotations that did not exist
+	annotations := map[string]interface{}{AnnotationUndoOf: record.ID}
+	for k, v := range record.PriorAnnotations {
+		if v == nil {
+			annotations[k] = nil
+		} else {
+			annotations[k] = *v
+		}
+	}
+	for k, v := range auditAnnotations(ctx) {
+		annotations[k] = v
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	}
+	if record.PriorReplicas != nil {
+		patch["spec"] = map[string]interface{}{"replicas": *record.PriorReplicas}
+	}
+	return json.Marshal(patch)
+}
+
+// priorAnnotations captures the current values of annotations about to be changed
+func priorAnnotations(current map[string]string, keys ...string) map[string]*string {
+	prior := make(map[string]*string, len(keys))
+	for _, key := range keys {
+		if value, exists := current[key]; exists {
+			v := value
+			prior[key] = &v
+		} else {
+			prior[key] = nil
+		}
+	}
+	return prior
+}
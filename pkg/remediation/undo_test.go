@@ -0,0 +1,96 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestUndoScaleReplicas(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+
+	client := fake.NewSimpleClientset(deployment)
+	engine := NewEngine(client, RemediationConfig{Enabled: true, AutoScaleEnabled: true})
+
+	result, err := engine.scaleDeployment(context.Background(), deployment)
+	if err != nil || !result.Success {
+		t.Fatalf("scaleDeployment() failed: %v (%v)", err, result)
+	}
+	if result.UndoID == "" {
+		t.Fatal("expected an undo ID for a reversible action")
+	}
+
+	records := engine.UndoRecords()
+	if len(records) != 1 || records[0].PriorReplicas == nil || *records[0].PriorReplicas != 2 {
+		t.Fatalf("unexpected undo records: %+v", records)
+	}
+
+	ctx := WithRequester(context.Background(), Requester{User: "alice", Source: "cli"})
+	undo, err := engine.Undo(ctx, result.UndoID)
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if !undo.Success || undo.RequestedBy != "alice" {
+		t.Errorf("unexpected undo result: %+v", undo)
+	}
+
+	restored, err := client.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if *restored.Spec.Replicas != 2 {
+		t.Errorf("replicas = %d, want 2", *restored.Spec.Replicas)
+	}
+	if restored.Annotations[AnnotationUndoOf] != result.UndoID {
+		t.Errorf("missing undo annotation: %v", restored.Annotations)
+	}
+	if restored.Annotations[AnnotationRequestedBy] != "alice" {
+		t.Errorf("missing requester annotation: %v", restored.Annotations)
+	}
+
+	record := engine.UndoRecords()[0]
+	if record.UndoneAt == nil || record.UndoneBy != "alice" {
+		t.Errorf("undo not recorded in audit trail: %+v", record)
+	}
+
+	if _, err := engine.Undo(ctx, result.UndoID); !errors.Is(err, ErrAlreadyUndone) {
+		t.Errorf("second Undo() error = %v, want ErrAlreadyUndone", err)
+	}
+	if _, err := engine.Undo(ctx, "missing"); !errors.Is(err, ErrUndoNotFound) {
+		t.Errorf("Undo(missing) error = %v, want ErrUndoNotFound", err)
+	}
+}
+
+func TestBuildUndoPatchRemovesAbsentAnnotations(t *testing.T) {
+	record := UndoRecord{
+		ID:               "abc",
+		Kind:             "Deployment",
+		PriorAnnotations: priorAnnotations(map[string]string{"kept": "v1"}, "kept", "added"),
+	}
+
+	patch, err := buildUndoPatch(context.Background(), record)
+	if err != nil {
+		t.Fatalf("buildUndoPatch() error = %v", err)
+	}
+
+	want := `{"metadata":{"annotations":{"added":null,"kept":"v1","kubeguardian.io/undo-of":"abc"}}}`
+	if string(patch) != want {
+		t.Errorf("patch = %s, want %s", patch, want)
+	}
+
+	record.Kind = "Pod"
+	if _, err := buildUndoPatch(context.Background(), record); err == nil {
+		t.Error("expected error for unsupported kind")
+	}
+}
@@ -0,0 +1,287 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embed the time zone database so schedules work in minimal container images
+	_ "time/tzdata"
+)
+
+// maxSearch bounds the search for the next activation of a schedule
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// field describes the valid range and names of a cron field
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros maps predefined schedules to their cron expressions
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression evaluated in a time zone
+type Schedule struct {
+	expr     string
+	location *time.Location
+
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+
+	// Cron matches either day field when both are restricted
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+// Parse parses a standard five-field cron expression (minute hour day-of-month month
+// day-of-week) or a macro such as @daily. The time zone may be given as an IANA name
+// or with a CRON_TZ= / TZ= prefix in the expression; it defaults to UTC.
+func Parse(expr, timezone string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(spec, prefix) {
+			parts := strings.SplitN(spec, " ", 2)
+			if timezone == "" {
+				timezone = strings.TrimPrefix(parts[0], prefix)
+			}
+			spec = ""
+			if len(parts) == 2 {
+				spec = strings.TrimSpace(parts[1])
+			}
+			break
+		}
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+		location = loc
+	}
+
+	if macro, exists := macros[strings.ToLower(spec)]; exists {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{
+		expr:               expr,
+		location:           location,
+		daysRestricted:     fields[2] != "*" && fields[2] != "?",
+		weekdaysRestricted: fields[4] != "*" && fields[4] != "?",
+	}
+
+	var err error
+	if s.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if s.days, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if s.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if s.weekdays, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	// 7 is an alias for Sunday
+	if s.weekdays[7] {
+		s.weekdays[0] = true
+	}
+
+	return s, nil
+}
+
+// String returns the original expression
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Location returns the time zone the schedule is evaluated in
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// Matches returns true if the schedule fires in the minute containing t
+func (s *Schedule) Matches(t time.Time) bool {
+	t = t.In(s.location)
+	return s.minutes[t.Minute()] && s.hours[t.Hour()] && s.months[int(t.Month())] && s.dayMatches(t)
+}
+
+// Next returns the first activation strictly after t, or the zero time if the
+// schedule never fires (e.g. February 30th)
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.location)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches applies the cron day-of-month / day-of-week semantics
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.days[t.Day()]
+	dow := s.weekdays[int(t.Weekday())]
+	if s.daysRestricted && s.weekdaysRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// parseField parses a comma-separated list of values, ranges and steps
+func parseField(expr string, f field) (map[int]bool, error) {
+	values := make(map[int]bool)
+	for _, part := range strings.Split(expr, ",") {
+		if err := parsePart(part, f, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// parsePart parses a single element of a cron field such as "*/5", "1-5" or "mon"
+func parsePart(part string, f field, values map[int]bool) error {
+	rangeExpr, step := part, 1
+	if idx := strings.Index(part, "/"); idx >= 0 {
+		var err error
+		rangeExpr = part[:idx]
+		step, err = strconv.Atoi(part[idx+1:])
+		if err != nil || step < 1 {
+			return fmt.Errorf("invalid step in %s field %q", f.name, part)
+		}
+	}
+
+	start, end := f.min, f.max
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+	case strings.Contains(rangeExpr, "-"):
+		bounds := strings.SplitN(rangeExpr, "-", 2)
+		var err error
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return err
+		}
+		if start > end {
+			return fmt.Errorf("invalid range in %s field %q", f.name, part)
+		}
+	default:
+		value, err := parseValue(rangeExpr, f)
+		if err != nil {
+			return err
+		}
+		start = value
+		// "5/10" means starting at 5 every 10
+		if step == 1 {
+			end = value
+		}
+	}
+
+	for v := start; v <= end; v += step {
+		values[v] = true
+	}
+	return nil
+}
+
+// parseValue parses a number or name within the range of a field
+func parseValue(value string, f field) (int, error) {
+	if n, exists := f.names[strings.ToLower(value)]; exists {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, f.name)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", n, f.min, f.max, f.name)
+	}
+	return n, nil
+}
+
+// Window is a recurring time window that opens at each activation of a schedule
+// and stays open for a duration, e.g. a nightly maintenance window
+type Window struct {
+	Schedule *Schedule
+	Duration time.Duration
+}
+
+// NewWindow parses a schedule and creates a window of the given duration. A zero
+// duration keeps the window open for the minute of each activation.
+func NewWindow(expr, timezone string, duration time.Duration) (*Window, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("window duration cannot be negative")
+	}
+	schedule, err := Parse(expr, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &Window{Schedule: schedule, Duration: duration}, nil
+}
+
+// Active returns true if the window is open at t
+func (w *Window) Active(t time.Time) bool {
+	if w.Duration <= 0 {
+		return w.Schedule.Matches(t)
+	}
+	next := w.Schedule.Next(t.Add(-w.Duration))
+	return !next.IsZero() && !next.After(t)
+}
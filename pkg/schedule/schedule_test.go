@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr     string
+		timezone string
+	}{
+		{"* * * *", ""},
+		{"60 * * * *", ""},
+		{"* 24 * * *", ""},
+		{"*/0 * * * *", ""},
+		{"5-1 * * * *", ""},
+		{"* * * foo *", ""},
+		{"* * * * *", "Mars/Olympus"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.expr, tt.timezone); err == nil {
+			t.Errorf("Parse(%q, %q) expected error", tt.expr, tt.timezone)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC) // Friday
+
+	tests := []struct {
+		expr     string
+		timezone string
+		want     time.Time
+	}{
+		{"*/15 * * * *", "", time.Date(2024, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", "", time.Date(2024, 3, 16, 2, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", "", time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)},
+		{"@monthly", "", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", "", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", "", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		// Day of month or day of week when both are restricted
+		{"0 0 20 * fri", "", time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)},
+		// 12:00 in Berlin is 11:00 UTC in March (CET)
+		{"0 12 * * *", "Europe/Berlin", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=America/New_York 0 6 * * *", "", time.Date(2024, 3, 16, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.expr, tt.timezone)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+		}
+		if got := s.Next(base); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got.UTC(), tt.want)
+		}
+	}
+}
+
+func TestNextNeverFires(t *testing.T) {
+	s, err := Parse("0 0 30 feb *", "")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next() = %v, want zero time", next)
+	}
+}
+
+func TestWindowActive(t *testing.T) {
+	// Nightly maintenance window from 02:00 to 04:00 in Tokyo (17:00-19:00 UTC)
+	w, err := NewWindow("0 2 * * *", "Asia/Tokyo", 2*time.Hour)
+	if err != nil {
+		t.Fatalf("NewWindow() error = %v", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 3, 15, 16, 59, 0, 0, time.UTC), false},
+		{time.Date(2024, 3, 15, 17, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 15, 19, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := w.Active(tt.at); got != tt.want {
+			t.Errorf("Active(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if _, err := NewWindow("0 2 * * *", "", -time.Hour); err == nil {
+		t.Error("expected error for negative duration")
+	}
+}
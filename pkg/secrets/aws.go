@@ -0,0 +1,241 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const awsSecretsManagerService = "secretsmanager"
+
+// AWSConfig configures reading secrets from AWS Secrets Manager. References
+// name the secret by name or ARN, e.g. aws-sm://prod/kubeguardian#slackToken
+// for the slackToken field of a secret holding a JSON object.
+//
+// Credentials are taken from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables, or else exchanged for the web
+// identity token of an IAM role for service accounts (AWS_ROLE_ARN and
+// AWS_WEB_IDENTITY_TOKEN_FILE).
+type AWSConfig struct {
+	// Region of the secrets; AWS_REGION is used if empty
+	Region string
+	// Endpoint overrides the Secrets Manager endpoint, e.g. a VPC endpoint
+	Endpoint string
+	// STSEndpoint overrides the STS endpoint the web identity token is exchanged at
+	STSEndpoint string
+	Timeout     time.Duration
+}
+
+// awsCredentials are AWS access keys
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time // Zero if the keys do not expire
+}
+
+// awsProvider reads secrets from AWS Secrets Manager
+type awsProvider struct {
+	config AWSConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	credentials awsCredentials
+}
+
+func newAWSProvider(config AWSConfig) *awsProvider {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", config.Region)
+	}
+	if config.STSEndpoint == "" {
+		config.STSEndpoint = fmt.Sprintf("https://sts.%s.amazonaws.com", config.Region)
+	}
+	return &awsProvider{config: config, client: httpClient(config.Timeout), now: time.Now}
+}
+
+// Fetch reads a secret with GetSecretValue
+func (a *awsProvider) Fetch(ctx context.Context, ref Ref) (string, error) {
+	if a.config.Region == "" {
+		return "", fmt.Errorf("aws region is not configured")
+	}
+	credentials, err := a.credentialsFor(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, credentials, a.config.Region, awsSecretsManagerService, a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := readResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", err
+	}
+	value := string(response.SecretBinary)
+	if response.SecretString != nil {
+		value = *response.SecretString
+	}
+	return field(value, ref.Key)
+}
+
+// credentialsFor returns the credentials of the environment, or of the IAM role
+// for service accounts, renewed when they expire soon
+func (a *awsProvider) credentialsFor(ctx context.Context) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	role, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	if role == "" || tokenFile == "" {
+		return awsCredentials{}, fmt.Errorf("no aws credentials: set AWS_ACCESS_KEY_ID or use an IAM role for the service account")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.credentials.AccessKeyID != "" && a.credentials.Expiration.Sub(a.now()) > 5*time.Minute {
+		return a.credentials, nil
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {"kubeguardian"},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.STSEndpoint, strings.NewReader(query.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer resp.Body.Close()
+	data, err := readResponse(resp)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume role %s: %w", role, err)
+	}
+
+	var response struct {
+		Credentials awsCredentials `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(data, &response); err != nil {
+		return awsCredentials{}, err
+	}
+	a.credentials = response.Credentials
+	return a.credentials, nil
+}
+
+// signV4 signs a request with AWS Signature Version 4, covering the host, the
+// content type and the x-amz-* headers
+func signV4(req *http.Request, body []byte, credentials awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,52 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignV4(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	credentials := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, credentials, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestAWSProvider(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || r.Header.Get("X-Amz-Security-Token") != "session" ||
+			!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request") || !strings.Contains(auth, "x-amz-security-token;x-amz-target") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Name":"prod/kubeguardian","SecretString":"{\"slackToken\":\"xoxb-1\"}"}`))
+	}))
+	defer server.Close()
+
+	provider := newAWSProvider(AWSConfig{Region: "eu-west-1", Endpoint: server.URL})
+	ctx := context.Background()
+
+	if got, err := provider.Fetch(ctx, Ref{Provider: ProviderAWS, Path: "prod/kubeguardian", Key: "slackToken"}); err != nil || got != "xoxb-1" {
+		t.Errorf("Fetch() = %q, %v, want xoxb-1", got, err)
+	}
+	if got, err := provider.Fetch(ctx, Ref{Provider: ProviderAWS, Path: "prod/kubeguardian"}); err != nil || got != `{"slackToken":"xoxb-1"}` {
+		t.Errorf("Fetch() without key = %q, %v", got, err)
+	}
+	if _, err := provider.Fetch(ctx, Ref{Provider: ProviderAWS, Path: "prod/kubeguardian", Key: "missing"}); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
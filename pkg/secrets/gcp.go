@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGCPEndpoint    = "https://secretmanager.googleapis.com"
+	defaultGCPMetadataURL = "http://metadata.google.internal"
+)
+
+// GCPConfig configures reading secrets from Google Cloud Secret Manager.
+// References name the secret and optionally its version, e.g.
+// gcp-sm://projects/prod/secrets/slack-token or gcp-sm://slack-token in the
+// configured project; the latest version is read by default. Access tokens are
+// taken from the metadata server, which serves the Workload Identity of the pod
+// on GKE.
+type GCPConfig struct {
+	// Project of references naming only the secret
+	Project string
+	// Endpoint overrides the Secret Manager endpoint
+	Endpoint string
+	// MetadataURL overrides the metadata server access tokens are taken from
+	MetadataURL string
+	Timeout     time.Duration
+}
+
+// gcpProvider reads secrets from Google Cloud Secret Manager
+type gcpProvider struct {
+	config GCPConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+func newGCPProvider(config GCPConfig) *gcpProvider {
+	if config.Endpoint == "" {
+		config.Endpoint = defaultGCPEndpoint
+	}
+	if config.MetadataURL == "" {
+		config.MetadataURL = defaultGCPMetadataURL
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	config.MetadataURL = strings.TrimSuffix(config.MetadataURL, "/")
+	return &gcpProvider{config: config, client: httpClient(config.Timeout)}
+}
+
+// Fetch reads a secret version with AccessSecretVersion
+func (g *gcpProvider) Fetch(ctx context.Context, ref Ref) (string, error) {
+	name, err := g.versionName(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.Endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var response struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	if err := g.do(req, &response); err != nil {
+		return "", err
+	}
+	return field(string(response.Payload.Data), ref.Key)
+}
+
+// versionName returns the resource name of the secret version a path refers to
+func (g *gcpProvider) versionName(path string) (string, error) {
+	if !strings.HasPrefix(path, "projects/") {
+		if g.config.Project == "" {
+			return "", fmt.Errorf("gcp project is not configured")
+		}
+		path = "projects/" + g.config.Project + "/secrets/" + path
+	}
+	if !strings.Contains(path, "/versions/") {
+		path += "/versions/latest"
+	}
+	return path, nil
+}
+
+// accessToken returns an access token of the default service account, renewed
+// when it expires soon
+func (g *gcpProvider) accessToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Until(g.tokenExpires) > time.Minute {
+		return g.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.config.MetadataURL+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := g.do(req, &response); err != nil {
+		return "", fmt.Errorf("failed to get access token from the metadata server: %w", err)
+	}
+	g.token = response.AccessToken
+	g.tokenExpires = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	return g.token, nil
+}
+
+// do sends a request and decodes its response into out
+func (g *gcpProvider) do(req *http.Request, out interface{}) error {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := readResponse(resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGCPProvider(t *testing.T) {
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			tokens++
+			_, _ = w.Write([]byte(`{"access_token":"gcp-token","expires_in":3600}`))
+		case "/v1/projects/prod/secrets/slack-token/versions/latest:access":
+			if r.Header.Get("Authorization") != "Bearer gcp-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"payload":{"data":"` + base64.StdEncoding.EncodeToString([]byte("xoxb-1")) + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newGCPProvider(GCPConfig{Project: "prod", Endpoint: server.URL, MetadataURL: server.URL})
+	ctx := context.Background()
+
+	for _, path := range []string{"slack-token", "projects/prod/secrets/slack-token", "projects/prod/secrets/slack-token/versions/latest"} {
+		if got, err := provider.Fetch(ctx, Ref{Provider: ProviderGCP, Path: path}); err != nil || got != "xoxb-1" {
+			t.Errorf("Fetch(%s) = %q, %v, want xoxb-1", path, got, err)
+		}
+	}
+	if tokens != 1 {
+		t.Errorf("expected the access token to be reused, got %d", tokens)
+	}
+	if _, err := provider.Fetch(ctx, Ref{Provider: ProviderGCP, Path: "projects/prod/secrets/missing"}); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+
+	unscoped := newGCPProvider(GCPConfig{Endpoint: server.URL, MetadataURL: server.URL})
+	if _, err := unscoped.Fetch(ctx, Ref{Provider: ProviderGCP, Path: "slack-token"}); err == nil {
+		t.Error("expected an error without a project")
+	}
+}
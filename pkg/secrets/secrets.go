@@ -0,0 +1,337 @@
+// Package secrets resolves credentials that reference an external secret store,
+// such as HashiCorp Vault or a cloud secret manager, so that they do not have to
+// be kept in ConfigMaps or environment variables
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Secret store providers, used as the scheme of references
+const (
+	// ProviderVault reads secrets from HashiCorp Vault
+	ProviderVault = "vault"
+	// ProviderAWS reads secrets from AWS Secrets Manager
+	ProviderAWS = "aws-sm"
+	// ProviderGCP reads secrets from Google Cloud Secret Manager
+	ProviderGCP = "gcp-sm"
+)
+
+const (
+	// DefaultRefreshInterval is how often resolved secrets are re-read
+	DefaultRefreshInterval = 5 * time.Minute
+
+	defaultTimeout = 10 * time.Second
+)
+
+var providers = map[string]bool{
+	ProviderVault: true,
+	ProviderAWS:   true,
+	ProviderGCP:   true,
+}
+
+// Ref references a secret as provider://path#key, e.g.
+// vault://kv/data/kubeguardian#slack-token. Key selects a field of a secret
+// holding several fields; it may be empty for secrets holding a single value.
+type Ref struct {
+	Provider string
+	Path     string
+	Key      string
+}
+
+// String returns the reference as it is written in the configuration
+func (r Ref) String() string {
+	if r.Key == "" {
+		return r.Provider + "://" + r.Path
+	}
+	return r.Provider + "://" + r.Path + "#" + r.Key
+}
+
+// ParseRef parses a reference to a secret; ok is false for plain values, which
+// are used as they are
+func ParseRef(value string) (ref Ref, ok bool, err error) {
+	scheme, rest, found := strings.Cut(value, "://")
+	if !found || !providers[scheme] {
+		return Ref{}, false, nil
+	}
+
+	path, key, _ := strings.Cut(rest, "#")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return Ref{}, true, fmt.Errorf("secret reference '%s' has no path", value)
+	}
+	return Ref{Provider: scheme, Path: path, Key: key}, true, nil
+}
+
+// Provider reads secrets from a secret store
+type Provider interface {
+	// Fetch returns the current value of a secret
+	Fetch(ctx context.Context, ref Ref) (string, error)
+}
+
+// Config configures the secret store providers
+type Config struct {
+	// RefreshInterval is how often resolved secrets are re-read to pick up rotations
+	RefreshInterval time.Duration
+	Vault           VaultConfig
+	AWS             AWSConfig
+	GCP             GCPConfig
+}
+
+// secret is a resolved reference and the functions notified of its rotations
+type secret struct {
+	ref      Ref
+	value    string
+	resolved bool
+	rotated  []func(string)
+}
+
+// Resolver resolves references to secrets and keeps their values up to date. A
+// nil resolver returns all values as they are.
+type Resolver struct {
+	providers map[string]Provider
+	refresh   time.Duration
+
+	mu      sync.Mutex
+	secrets map[string]*secret // Key: reference
+}
+
+// New creates a resolver reading secrets from the providers of a configuration
+func New(config Config) *Resolver {
+	return NewResolver(map[string]Provider{
+		ProviderVault: newVaultProvider(config.Vault),
+		ProviderAWS:   newAWSProvider(config.AWS),
+		ProviderGCP:   newGCPProvider(config.GCP),
+	}, config.RefreshInterval)
+}
+
+// NewResolver creates a resolver reading secrets from providers by reference
+// scheme, re-reading them every refresh interval
+func NewResolver(providers map[string]Provider, refresh time.Duration) *Resolver {
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	return &Resolver{
+		providers: providers,
+		refresh:   refresh,
+		secrets:   make(map[string]*secret),
+	}
+}
+
+// Resolve returns plain values as they are and the value of references. A
+// reference is read from its provider the first time it is resolved and is then
+// kept up to date by Run.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	ref, ok, err := ParseRef(value)
+	if err != nil || !ok {
+		return value, err
+	}
+	if r == nil {
+		return "", fmt.Errorf("secret '%s' is referenced but no secret providers are configured", ref)
+	}
+
+	r.mu.Lock()
+	if s := r.secrets[value]; s != nil && s.resolved {
+		r.mu.Unlock()
+		return s.value, nil
+	}
+	r.mu.Unlock()
+
+	data, err := r.fetch(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.entry(value, ref)
+	s.value, s.resolved = data, true
+	return data, nil
+}
+
+// Current returns the last value resolved for value without reading its
+// provider; plain values are returned as they are
+func (r *Resolver) Current(value string) string {
+	if _, ok, _ := ParseRef(value); !ok {
+		return value
+	}
+	if r == nil {
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s := r.secrets[value]; s != nil {
+		return s.value
+	}
+	return ""
+}
+
+// OnRotate calls fn with the new value of the secret referenced by value each
+// time Run reads a changed value; plain values never change
+func (r *Resolver) OnRotate(value string, fn func(string)) {
+	ref, ok, err := ParseRef(value)
+	if r == nil || !ok || err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.entry(value, ref)
+	s.rotated = append(s.rotated, fn)
+}
+
+// entry returns the entry of a reference, adding it if needed. The caller
+// must hold r.mu.
+func (r *Resolver) entry(value string, ref Ref) *secret {
+	s := r.secrets[value]
+	if s == nil {
+		s = &secret{ref: ref}
+		r.secrets[value] = s
+	}
+	return s
+}
+
+// Run re-reads the resolved secrets every refresh interval until ctx is done,
+// so rotated credentials are picked up without a restart. A secret that cannot
+// be read keeps its previous value.
+func (r *Resolver) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.refreshSecrets(ctx)
+		}
+	}
+}
+
+// refreshSecrets re-reads all secrets and notifies the rotation of changed ones
+func (r *Resolver) refreshSecrets(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	r.mu.Lock()
+	keys := make([]string, 0, len(r.secrets))
+	for key := range r.secrets {
+		keys = append(keys, key)
+	}
+	r.mu.Unlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r.mu.Lock()
+		ref := r.secrets[key].ref
+		r.mu.Unlock()
+
+		data, err := r.fetch(ctx, ref)
+		if err != nil {
+			logger.Error(err, "Failed to refresh secret, keeping its previous value", "secret", ref.String())
+			continue
+		}
+
+		r.mu.Lock()
+		s := r.secrets[key]
+		changed := s.resolved && s.value != data
+		s.value, s.resolved = data, true
+		rotated := append([]func(string){}, s.rotated...)
+		r.mu.Unlock()
+
+		if changed {
+			logger.Info("Secret rotated", "secret", ref.String())
+			for _, fn := range rotated {
+				fn(data)
+			}
+		}
+	}
+}
+
+// fetch reads a secret from its provider
+func (r *Resolver) fetch(ctx context.Context, ref Ref) (string, error) {
+	provider := r.providers[ref.Provider]
+	if provider == nil {
+		return "", fmt.Errorf("secret provider %s is not configured", ref.Provider)
+	}
+	data, err := provider.Fetch(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret '%s': %w", ref, err)
+	}
+	return data, nil
+}
+
+// field returns the field key of a secret holding a JSON object, or the whole
+// secret if key is empty
+func field(data, key string) (string, error) {
+	if key == "" {
+		return data, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, it has no field %s", key)
+	}
+	return fieldValue(fields, key)
+}
+
+// fieldValue returns a field of a secret; key may be empty if the secret holds
+// a single field
+func fieldValue(fields map[string]interface{}, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields, select one with #key", len(fields))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %s", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// readResponse returns the body of a successful response, and an error with the
+// start of the body otherwise
+func readResponse(resp *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		if len(data) > 1<<10 {
+			data = data[:1<<10]
+		}
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// httpClient returns a client bounding requests by timeout, or the default timeout
+func httpClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
@@ -0,0 +1,198 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Vault authentication methods
+const (
+	// VaultAuthKubernetes logs in with the service account token of the pod
+	VaultAuthKubernetes = "kubernetes"
+	// VaultAuthToken reads a Vault token from a file, e.g. written by the Vault agent
+	VaultAuthToken = "token"
+)
+
+const (
+	defaultVaultAuthPath           = "kubernetes"
+	defaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// errVaultForbidden is returned when Vault rejects the token of a request
+var errVaultForbidden = errors.New("permission denied")
+
+// VaultConfig configures reading secrets from HashiCorp Vault. References name
+// the API path of a secret, e.g. vault://kv/data/kubeguardian#slack-token for
+// the KV version 2 engine mounted at kv.
+type VaultConfig struct {
+	// Address is the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Namespace is the Vault Enterprise namespace, empty for the root namespace
+	Namespace string
+	// AuthMethod is kubernetes (default) or token
+	AuthMethod string
+	// Role is the role of the Kubernetes auth method
+	Role string
+	// AuthPath is the mount path of the Kubernetes auth method, kubernetes by default
+	AuthPath string
+	// ServiceAccountTokenFile is the token presented to the Kubernetes auth method
+	ServiceAccountTokenFile string
+	// TokenFile holds the token of the token auth method. It is read for each
+	// request, so it may be renewed by another process.
+	TokenFile string
+	Timeout   time.Duration
+}
+
+// vaultProvider reads secrets from the KV engines of Vault
+type vaultProvider struct {
+	config VaultConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	tokenExpires time.Time // Zero if the token does not expire
+}
+
+func newVaultProvider(config VaultConfig) *vaultProvider {
+	if config.AuthMethod == "" {
+		config.AuthMethod = VaultAuthKubernetes
+	}
+	if config.AuthPath == "" {
+		config.AuthPath = defaultVaultAuthPath
+	}
+	if config.ServiceAccountTokenFile == "" {
+		config.ServiceAccountTokenFile = defaultServiceAccountTokenFile
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	return &vaultProvider{config: config, client: httpClient(config.Timeout)}
+}
+
+// Fetch reads a secret, logging in again once if the cached token was revoked
+func (v *vaultProvider) Fetch(ctx context.Context, ref Ref) (string, error) {
+	if v.config.Address == "" {
+		return "", fmt.Errorf("vault address is not configured")
+	}
+
+	data, err := v.read(ctx, ref)
+	if errors.Is(err, errVaultForbidden) && v.config.AuthMethod == VaultAuthKubernetes {
+		v.mu.Lock()
+		v.token = ""
+		v.mu.Unlock()
+		data, err = v.read(ctx, ref)
+	}
+	return data, err
+}
+
+// read reads the field of a secret with the current token
+func (v *vaultProvider) read(ctx context.Context, ref Ref) (string, error) {
+	token, err := v.authenticate(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, "/v1/"+ref.Path, token, nil, &response); err != nil {
+		return "", err
+	}
+	if response.Data == nil {
+		return "", fmt.Errorf("secret not found")
+	}
+
+	fields := response.Data
+	// The KV version 2 engine nests the fields next to their metadata
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = nested
+		}
+	}
+	return fieldValue(fields, ref.Key)
+}
+
+// authenticate returns the token of the configured auth method, logging in with
+// the Kubernetes auth method when the cached token is missing or expires soon
+func (v *vaultProvider) authenticate(ctx context.Context) (string, error) {
+	if v.config.AuthMethod == VaultAuthToken {
+		data, err := os.ReadFile(v.config.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read vault token: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != "" && (v.tokenExpires.IsZero() || time.Until(v.tokenExpires) > time.Minute) {
+		return v.token, nil
+	}
+
+	jwt, err := os.ReadFile(v.config.ServiceAccountTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %w", err)
+	}
+	body, err := json.Marshal(map[string]string{"role": v.config.Role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	if err := v.do(ctx, http.MethodPost, "/v1/auth/"+strings.Trim(v.config.AuthPath, "/")+"/login", "", body, &response); err != nil {
+		return "", fmt.Errorf("vault login failed: %w", err)
+	}
+	if response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login returned no token")
+	}
+
+	v.token = response.Auth.ClientToken
+	v.tokenExpires = time.Time{}
+	if response.Auth.LeaseDuration > 0 {
+		v.tokenExpires = time.Now().Add(time.Duration(response.Auth.LeaseDuration) * time.Second)
+	}
+	return v.token, nil
+}
+
+// do sends a request to the Vault API and decodes its response into out
+func (v *vaultProvider) do(ctx context.Context, method, path, token string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, v.config.Address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return errVaultForbidden
+	}
+	data, err := readResponse(resp)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
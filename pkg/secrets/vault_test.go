@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultProvider(t *testing.T) {
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtFile, []byte("service-account-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logins := 0
+	revoked := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["role"] != "kubeguardian" || body["jwt"] != "service-account-jwt" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+		case "/v1/kv/data/kubeguardian":
+			if r.Header.Get("X-Vault-Token") != "vault-token" || r.Header.Get("X-Vault-Namespace") != "team" || revoked {
+				revoked = false
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"slack-token":"xoxb-1","password":"hunter2"},"metadata":{"version":3}}}`))
+		case "/v1/secret/single":
+			_, _ = w.Write([]byte(`{"data":{"value":"only"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := newVaultProvider(VaultConfig{
+		Address:                 server.URL,
+		Namespace:               "team",
+		Role:                    "kubeguardian",
+		ServiceAccountTokenFile: jwtFile,
+	})
+	ctx := context.Background()
+
+	got, err := provider.Fetch(ctx, Ref{Provider: ProviderVault, Path: "kv/data/kubeguardian", Key: "slack-token"})
+	if err != nil || got != "xoxb-1" {
+		t.Fatalf("Fetch() = %q, %v, want xoxb-1", got, err)
+	}
+	if got, err := provider.Fetch(ctx, Ref{Provider: ProviderVault, Path: "secret/single"}); err != nil || got != "only" {
+		t.Errorf("Fetch() of a KV v1 secret with a single field = %q, %v", got, err)
+	}
+	if _, err := provider.Fetch(ctx, Ref{Provider: ProviderVault, Path: "kv/data/kubeguardian"}); err == nil {
+		t.Error("expected an error without a key for a secret with several fields")
+	}
+	if _, err := provider.Fetch(ctx, Ref{Provider: ProviderVault, Path: "kv/data/missing", Key: "x"}); err == nil {
+		t.Error("expected an error for a missing secret")
+	}
+	if logins != 1 {
+		t.Errorf("expected the token to be reused, got %d logins", logins)
+	}
+
+	// A revoked token is replaced by logging in again
+	revoked = true
+	if got, err := provider.Fetch(ctx, Ref{Provider: ProviderVault, Path: "kv/data/kubeguardian", Key: "password"}); err != nil || got != "hunter2" {
+		t.Errorf("Fetch() after revocation = %q, %v", got, err)
+	}
+	if logins != 2 {
+		t.Errorf("expected a second login after revocation, got %d", logins)
+	}
+}
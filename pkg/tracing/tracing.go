@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// HeaderTraceparent is the W3C Trace Context header carrying the trace of a request
+const HeaderTraceparent = "traceparent"
+
+// zeroTraceID is the invalid all-zero trace ID
+const zeroTraceID = "00000000000000000000000000000000"
+
+type traceIDKey struct{}
+
+// NewTraceID returns a random W3C trace ID of 32 lowercase hex digits
+func NewTraceID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// WithTraceID returns a context carrying the trace ID, with the logger of the
+// context logging it as traceID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	ctx = context.WithValue(ctx, traceIDKey{}, traceID)
+	return log.IntoContext(ctx, log.FromContext(ctx).WithValues("traceID", traceID))
+}
+
+// TraceIDFromContext returns the trace ID of the context, if any
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDKey{}).(string)
+	return traceID, ok && traceID != ""
+}
+
+// Start returns a context carrying a new trace ID, or the context unchanged if
+// it already carries one
+func Start(ctx context.Context) context.Context {
+	if _, ok := TraceIDFromContext(ctx); ok {
+		return ctx
+	}
+	return WithTraceID(ctx, NewTraceID())
+}
+
+// ParseTraceparent returns the trace ID of a W3C traceparent header value of the
+// form version-traceid-parentid-flags, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(value string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", false
+	}
+	traceID := parts[1]
+	if len(traceID) != 32 || traceID == zeroTraceID || !isLowerHex(traceID) {
+		return "", false
+	}
+	return traceID, true
+}
+
+// isLowerHex returns true if s only contains lowercase hex digits
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
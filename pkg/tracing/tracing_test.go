@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		ok    bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"future version with extra fields", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", false},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", false},
+		{"uppercase trace ID", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "", false},
+		{"short trace ID", "00-4bf92f35-00f067aa0ba902b7-01", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTraceparent(tt.value)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseTraceparent(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx := Start(context.Background())
+	traceID, ok := TraceIDFromContext(ctx)
+	if !ok {
+		t.Fatal("Start() did not attach a trace ID")
+	}
+	if _, valid := ParseTraceparent("00-" + traceID + "-00f067aa0ba902b7-01"); !valid {
+		t.Errorf("trace ID %q is not a valid W3C trace ID", traceID)
+	}
+
+	if continued, _ := TraceIDFromContext(Start(ctx)); continued != traceID {
+		t.Errorf("Start() replaced trace ID %q with %q", traceID, continued)
+	}
+	if _, ok := TraceIDFromContext(context.Background()); ok {
+		t.Error("a context without trace ID reported one")
+	}
+}
@@ -0,0 +1,87 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+)
+
+// EscalationStep raises the severity of issues active for After to at least Severity
+type EscalationStep struct {
+	After    time.Duration
+	Severity string
+}
+
+// EscalationPolicy raises the severity of issues that stay unresolved. Escalated
+// severities never drop while an issue is active, and an issue detected again
+// within Hysteresis of being resolved keeps its escalation and active time.
+type EscalationPolicy struct {
+	Steps      []EscalationStep
+	Hysteresis time.Duration
+}
+
+// SetEscalation sets the policy escalating long-lived issues; an empty policy
+// disables escalation
+func (t *Tracker) SetEscalation(policy EscalationPolicy) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.escalation = policy
+}
+
+// escalate raises the severity of an active record to the highest step it is
+// due for and returns true if it was raised; the caller holds the lock
+func (t *Tracker) escalate(record *Record, now time.Time) bool {
+	active := now.Sub(record.ActiveSince)
+	target := ""
+	for _, step := range t.escalation.Steps {
+		if active >= step.After && config.SeverityRank(step.Severity) > config.SeverityRank(target) {
+			target = step.Severity
+		}
+	}
+	if config.SeverityRank(target) <= config.SeverityRank(record.EffectiveSeverity()) {
+		return false
+	}
+
+	record.EscalatedSeverity = target
+	record.EscalatedAt = now
+	return true
+}
+
+// resume continues the escalation of an issue resolved within the hysteresis
+// and detected again; the caller holds the lock
+func (t *Tracker) resume(record *Record, now time.Time) {
+	previous, exists := t.recent[record.Fingerprint]
+	if !exists {
+		return
+	}
+	delete(t.recent, record.Fingerprint)
+	if now.Sub(previous.ResolvedAt) > t.escalation.Hysteresis {
+		return
+	}
+
+	record.ActiveSince = previous.ActiveSince
+	record.EscalatedSeverity = previous.EscalatedSeverity
+	record.EscalatedAt = previous.EscalatedAt
+}
+
+// remember keeps a resolved record for the hysteresis so its escalation resumes
+// if the issue is detected again; the caller holds the lock
+func (t *Tracker) remember(record Record) {
+	if len(t.escalation.Steps) == 0 || t.escalation.Hysteresis <= 0 {
+		return
+	}
+	t.recent[record.Fingerprint] = record
+}
+
+// forget drops resolved records older than the hysteresis; the caller holds the lock
+func (t *Tracker) forget(now time.Time) {
+	for fingerprint, record := range t.recent {
+		if now.Sub(record.ResolvedAt) > t.escalation.Hysteresis {
+			delete(t.recent, fingerprint)
+		}
+	}
+}
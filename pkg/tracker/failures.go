@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"time"
+)
+
+// maxFailureHistory bounds the failed remediation attempts kept per issue
+const maxFailureHistory = 10
+
+// Failure is a failed remediation attempt of an issue
+type Failure struct {
+	Action  string    `json:"action"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+// Get returns the record of an active issue
+func (t *Tracker) Get(fingerprint string) (Record, bool) {
+	if t == nil {
+		return Record{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, exists := t.active[fingerprint]
+	if !exists {
+		return Record{}, false
+	}
+	return *record, true
+}
+
+// RecordFailure records a failed remediation attempt of an active issue. Once
+// remediation failed more than maxRetries times in a row, the issue requires
+// human intervention; it returns the updated record and true if it did not
+// require intervention before.
+func (t *Tracker) RecordFailure(fingerprint string, failure Failure, maxRetries int) (Record, bool) {
+	if t == nil {
+		return Record{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, exists := t.active[fingerprint]
+	if !exists {
+		return Record{}, false
+	}
+
+	record.ConsecutiveFailures++
+	record.Failures = append(record.Failures, failure)
+	if len(record.Failures) > maxFailureHistory {
+		record.Failures = record.Failures[len(record.Failures)-maxFailureHistory:]
+	}
+	escalate := record.ConsecutiveFailures > maxRetries && !record.InterventionRequired
+	if escalate {
+		record.InterventionRequired = true
+	}
+	return *record, escalate
+}
+
+// NextRetry returns when remediation of an issue that failed may be retried:
+// interval after the first failure, doubling with each further consecutive
+// failure. It is zero if the last attempt did not fail.
+func (r Record) NextRetry(interval time.Duration) time.Time {
+	if r.ConsecutiveFailures == 0 || len(r.Failures) == 0 {
+		return time.Time{}
+	}
+	backoff := interval << min(r.ConsecutiveFailures-1, 16)
+	return r.Failures[len(r.Failures)-1].At.Add(backoff)
+}
+
+// InterventionRequired returns the number of active issues whose remediation
+// failed more often than the retries allow
+func (t *Tracker) InterventionRequired() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := 0
+	for _, record := range t.active {
+		if record.InterventionRequired {
+			count++
+		}
+	}
+	return count
+}
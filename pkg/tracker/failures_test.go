@@ -0,0 +1,59 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+func TestTrackerRemediationFailures(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+	issue := newIssue("web-1")
+	fingerprint := issue.Fingerprint()
+	tracker.Observe([]detection.Issue{issue}, start)
+
+	if _, escalated := tracker.RecordFailure("unknown", Failure{Action: "restart-pod"}, 1); escalated {
+		t.Error("failures of untracked issues should not require intervention")
+	}
+
+	record, escalated := tracker.RecordFailure(fingerprint, Failure{Action: "restart-pod", Message: "forbidden", At: start}, 2)
+	if escalated || record.ConsecutiveFailures != 1 || len(record.Failures) != 1 {
+		t.Fatalf("first failure = %+v, %v", record, escalated)
+	}
+	if next := record.NextRetry(10 * time.Second); !next.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("NextRetry() after 1 failure = %v, want 10s later", next.Sub(start))
+	}
+
+	record, _ = tracker.RecordFailure(fingerprint, Failure{Action: "restart-pod", Message: "forbidden", At: start.Add(time.Minute)}, 2)
+	if next := record.NextRetry(10 * time.Second); !next.Equal(start.Add(time.Minute + 20*time.Second)) {
+		t.Errorf("NextRetry() after 2 failures = %v, want 20s after the last failure", next.Sub(start))
+	}
+
+	record, escalated = tracker.RecordFailure(fingerprint, Failure{Action: "restart-pod", Message: "forbidden", At: start.Add(2 * time.Minute)}, 2)
+	if !escalated || !record.InterventionRequired {
+		t.Fatalf("failure beyond the retries should require intervention: %+v", record)
+	}
+	if _, escalated := tracker.RecordFailure(fingerprint, Failure{Action: "restart-pod", At: start.Add(3 * time.Minute)}, 2); escalated {
+		t.Error("intervention should be reported once")
+	}
+	if got := tracker.InterventionRequired(); got != 1 {
+		t.Errorf("InterventionRequired() = %d, want 1", got)
+	}
+
+	for i := 0; i < 2*maxFailureHistory; i++ {
+		record, _ = tracker.RecordFailure(fingerprint, Failure{Action: "restart-pod", At: start.Add(time.Hour)}, 2)
+	}
+	if len(record.Failures) != maxFailureHistory || record.ConsecutiveFailures != 4+2*maxFailureHistory {
+		t.Errorf("failure history = %d of %d failures, want the latest %d", len(record.Failures), record.ConsecutiveFailures, maxFailureHistory)
+	}
+
+	record, _ = tracker.RecordRemediation(fingerprint, true, start.Add(2*time.Hour))
+	if record.ConsecutiveFailures != 0 || len(record.Failures) != 0 || record.InterventionRequired {
+		t.Errorf("successful remediation should clear the failures: %+v", record)
+	}
+	if !record.NextRetry(10 * time.Second).IsZero() {
+		t.Error("NextRetry() should be zero without failures")
+	}
+}
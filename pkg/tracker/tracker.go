@@ -0,0 +1,295 @@
+package tracker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/config"
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+// Outcomes of a resolved issue
+const (
+	// OutcomeAutoResolved means the issue disappeared after a successful remediation
+	OutcomeAutoResolved = "auto_resolved"
+	// OutcomeEscalated means the issue disappeared without a successful remediation,
+	// i.e. it was handled outside of KubeGuardian
+	OutcomeEscalated = "escalated"
+)
+
+// Transitions of an issue between evaluation cycles
+const (
+	// TransitionNew means the issue was detected for the first time
+	TransitionNew = "new"
+	// TransitionActive means a previously detected issue is still detected
+	TransitionActive = "active"
+	// TransitionResolved means a previously detected issue is no longer detected
+	TransitionResolved = "resolved"
+)
+
+// Transitions classifies the issues detected in an evaluation cycle
+type Transitions struct {
+	// New issues were not detected in the previous cycle
+	New []detection.Issue
+	// Reverify are still-active issues due for re-verification
+	Reverify []detection.Issue
+	// Unchanged counts still-active issues that are not yet due for re-verification
+	Unchanged int
+	// Resolved are the records of issues that are no longer detected
+	Resolved []Record
+	// Escalated are the issues whose severity was raised in this cycle because
+	// they stayed unresolved; they are also New or due for re-verification
+	Escalated []detection.Issue
+}
+
+// Actionable returns the issues to process in this cycle: new issues first, then
+// active issues due for re-verification
+func (t Transitions) Actionable() []detection.Issue {
+	issues := make([]detection.Issue, 0, len(t.New)+len(t.Reverify))
+	issues = append(issues, t.New...)
+	return append(issues, t.Reverify...)
+}
+
+// Record tracks the lifecycle of a single issue across evaluation cycles
+type Record struct {
+	Fingerprint   string    `json:"fingerprint"`
+	RuleName      string    `json:"ruleName"`
+	Namespace     string    `json:"namespace"`
+	Kind          string    `json:"kind"`
+	Name          string    `json:"name"`
+	Container     string    `json:"container,omitempty"`
+	Team          string    `json:"team,omitempty"`
+	Runbook       string    `json:"runbook,omitempty"`
+	RuleOwner     string    `json:"ruleOwner,omitempty"`
+	FirstDetected time.Time `json:"firstDetected"`
+	LastSeen      time.Time `json:"lastSeen"`
+	LastVerified  time.Time `json:"lastVerified"`
+	RemediatedAt  time.Time `json:"remediatedAt"`
+	Attempts      int       `json:"attempts"`
+	ResolvedAt    time.Time `json:"resolvedAt"`
+	// Severity is the severity of the rule in the latest detection
+	Severity string `json:"severity"`
+	// ActiveSince is FirstDetected, or the first detection of an issue that was
+	// resolved and detected again within the escalation hysteresis
+	ActiveSince time.Time `json:"activeSince"`
+	// EscalatedSeverity is the severity the issue was raised to for staying unresolved
+	EscalatedSeverity string    `json:"escalatedSeverity,omitempty"`
+	EscalatedAt       time.Time `json:"escalatedAt,omitempty"`
+	// ConsecutiveFailures counts the failed remediation attempts since the last
+	// successful one; Failures are the latest of them, the most recent last
+	ConsecutiveFailures int       `json:"consecutiveFailures,omitempty"`
+	Failures            []Failure `json:"failures,omitempty"`
+	// InterventionRequired is set once remediation failed more often than the
+	// retries allow; the issue is no longer remediated automatically
+	InterventionRequired bool `json:"interventionRequired,omitempty"`
+	// Snapshot is a trimmed manifest of the resource when the issue was first
+	// detected, kept as the resource changes afterwards
+	Snapshot *detection.Snapshot `json:"snapshot,omitempty"`
+}
+
+// Remediated returns true if the issue was successfully remediated
+func (r Record) Remediated() bool {
+	return !r.RemediatedAt.IsZero()
+}
+
+// Outcome returns how the issue was resolved
+func (r Record) Outcome() string {
+	if r.Remediated() {
+		return OutcomeAutoResolved
+	}
+	return OutcomeEscalated
+}
+
+// EffectiveSeverity returns the escalated severity of the issue, or the severity
+// of its rule if it was not escalated above it
+func (r Record) EffectiveSeverity() string {
+	if config.SeverityRank(r.EscalatedSeverity) > config.SeverityRank(r.Severity) {
+		return r.EscalatedSeverity
+	}
+	return r.Severity
+}
+
+// TimeToRemediation returns the time from first detection to the first successful remediation
+func (r Record) TimeToRemediation() time.Duration {
+	if !r.Remediated() {
+		return 0
+	}
+	return r.RemediatedAt.Sub(r.FirstDetected)
+}
+
+// TimeToResolution returns the time from first detection until the issue was resolved
+func (r Record) TimeToResolution() time.Duration {
+	if r.ResolvedAt.IsZero() {
+		return 0
+	}
+	return r.ResolvedAt.Sub(r.FirstDetected)
+}
+
+// Tracker follows detected issues by fingerprint from detection to resolution
+type Tracker struct {
+	mu         sync.Mutex
+	active     map[string]*Record // Key: issue fingerprint
+	recent     map[string]Record  // Issues resolved within the escalation hysteresis, by fingerprint
+	escalation EscalationPolicy
+}
+
+// NewTracker creates a new issue tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		active: make(map[string]*Record),
+		recent: make(map[string]Record),
+	}
+}
+
+// Observe updates the tracker with the issues detected in an evaluation cycle and
+// returns the records of issues that are no longer detected
+func (t *Tracker) Observe(issues []detection.Issue, now time.Time) []Record {
+	return t.Diff(issues, now, 0).Resolved
+}
+
+// Diff updates the tracker with the issues detected in an evaluation cycle and
+// classifies them by transition. Active issues are due for re-verification once
+// reverifyInterval has elapsed since they were last processed; a zero interval
+// re-verifies them every cycle.
+func (t *Tracker) Diff(issues []detection.Issue, now time.Time, reverifyInterval time.Duration) Transitions {
+	return t.DiffWithin(issues, now, reverifyInterval, nil)
+}
+
+// DiffWithin is Diff for an evaluation cycle limited to a scope: active issues
+// outside the scope are not resolved when they are missing from issues. A nil
+// scope includes all issues. Issues carry their escalated severity; issues
+// escalated in this cycle are re-verified even if they are not due.
+func (t *Tracker) DiffWithin(issues []detection.Issue, now time.Time, reverifyInterval time.Duration, inScope func(Record) bool) Transitions {
+	var transitions Transitions
+	if t == nil {
+		transitions.New = issues
+		return transitions
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.forget(now)
+	seen := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		fingerprint := issue.Fingerprint()
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+
+		record, exists := t.active[fingerprint]
+		if !exists {
+			record = &Record{
+				Fingerprint:   fingerprint,
+				RuleName:      issue.RuleName,
+				Namespace:     issue.Namespace,
+				Kind:          issue.Kind,
+				Name:          issue.Name,
+				Container:     issue.Container,
+				Team:          issue.Owner.Team,
+				Runbook:       issue.Runbook,
+				RuleOwner:     issue.RuleMetadata.Owner,
+				Severity:      issue.Severity,
+				FirstDetected: now,
+				ActiveSince:   now,
+				LastVerified:  now,
+				Snapshot:      detection.NewSnapshot(issue.Resource),
+			}
+			t.resume(record, now)
+			t.active[fingerprint] = record
+		}
+		record.Severity = issue.Severity
+		escalated := t.escalate(record, now)
+		if severity := record.EffectiveSeverity(); severity != issue.Severity {
+			issue.EscalatedFrom = issue.Severity
+			issue.Severity = severity
+		}
+
+		switch {
+		case !exists:
+			transitions.New = append(transitions.New, issue)
+		case escalated || now.Sub(record.LastVerified) >= reverifyInterval:
+			record.LastVerified = now
+			transitions.Reverify = append(transitions.Reverify, issue)
+		default:
+			transitions.Unchanged++
+		}
+		if escalated {
+			transitions.Escalated = append(transitions.Escalated, issue)
+		}
+		record.LastSeen = now
+	}
+
+	for fingerprint, record := range t.active {
+		if seen[fingerprint] || (inScope != nil && !inScope(*record)) {
+			continue
+		}
+		record.ResolvedAt = now
+		transitions.Resolved = append(transitions.Resolved, *record)
+		t.remember(*record)
+		delete(t.active, fingerprint)
+	}
+
+	return transitions
+}
+
+// RecordRemediation records a remediation attempt for an active issue. It returns the
+// updated record and true if this was the first successful remediation of the issue.
+func (t *Tracker) RecordRemediation(fingerprint string, success bool, now time.Time) (Record, bool) {
+	if t == nil {
+		return Record{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, exists := t.active[fingerprint]
+	if !exists {
+		return Record{}, false
+	}
+
+	record.Attempts++
+	first := success && !record.Remediated()
+	if first {
+		record.RemediatedAt = now
+	}
+	if success {
+		record.ConsecutiveFailures, record.Failures, record.InterventionRequired = 0, nil, false
+	}
+	return *record, first
+}
+
+// Merge adds active issues, e.g. imported from another instance, so their
+// detection history and escalation continue. Issues that are already active
+// keep their record. It returns the number of records added.
+func (t *Tracker) Merge(records []Record) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	added := 0
+	for _, record := range records {
+		if _, exists := t.active[record.Fingerprint]; exists || record.Fingerprint == "" {
+			continue
+		}
+		t.active[record.Fingerprint] = &record
+		added++
+	}
+	return added
+}
+
+// Active returns the records of all currently active issues
+func (t *Tracker) Active() []Record {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	records := make([]Record, 0, len(t.active))
+	for _, record := range t.active {
+		records = append(records, *record)
+	}
+	return records
+}
@@ -0,0 +1,236 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/NotHarshhaa/kubeguardian/pkg/detection"
+)
+
+func newIssue(name string) detection.Issue {
+	return detection.Issue{
+		RuleName:  "crash-loop-backoff",
+		Namespace: "default",
+		Kind:      "Pod",
+		Name:      name,
+		Reason:    "CrashLoopBackOff",
+	}
+}
+
+func TestTrackerAutoResolved(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+	issue := newIssue("web-1")
+
+	if resolved := tracker.Observe([]detection.Issue{issue}, start); len(resolved) != 0 {
+		t.Fatalf("expected no resolved issues, got %d", len(resolved))
+	}
+
+	record, first := tracker.RecordRemediation(issue.Fingerprint(), true, start.Add(time.Minute))
+	if !first {
+		t.Error("first successful remediation should be reported")
+	}
+	if record.TimeToRemediation() != time.Minute {
+		t.Errorf("TimeToRemediation() = %v, want %v", record.TimeToRemediation(), time.Minute)
+	}
+
+	if _, first := tracker.RecordRemediation(issue.Fingerprint(), true, start.Add(2*time.Minute)); first {
+		t.Error("only the first successful remediation should be reported")
+	}
+
+	// Still detected in the next cycle
+	tracker.Observe([]detection.Issue{issue}, start.Add(3*time.Minute))
+
+	resolved := tracker.Observe(nil, start.Add(5*time.Minute))
+	if len(resolved) != 1 {
+		t.Fatalf("expected 1 resolved issue, got %d", len(resolved))
+	}
+	if resolved[0].Outcome() != OutcomeAutoResolved {
+		t.Errorf("Outcome() = %q, want %q", resolved[0].Outcome(), OutcomeAutoResolved)
+	}
+	if resolved[0].Attempts != 2 {
+		t.Errorf("Attempts = %d, want 2", resolved[0].Attempts)
+	}
+	if resolved[0].TimeToResolution() != 5*time.Minute {
+		t.Errorf("TimeToResolution() = %v, want %v", resolved[0].TimeToResolution(), 5*time.Minute)
+	}
+	if len(tracker.Active()) != 0 {
+		t.Error("resolved issues should no longer be active")
+	}
+}
+
+func TestTrackerEscalated(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+	issue := newIssue("web-1")
+
+	tracker.Observe([]detection.Issue{issue, newIssue("web-2")}, start)
+	tracker.RecordRemediation(issue.Fingerprint(), false, start.Add(time.Minute))
+
+	resolved := tracker.Observe([]detection.Issue{newIssue("web-2")}, start.Add(10*time.Minute))
+	if len(resolved) != 1 || resolved[0].Name != "web-1" {
+		t.Fatalf("expected web-1 to be resolved, got %+v", resolved)
+	}
+	if resolved[0].Outcome() != OutcomeEscalated {
+		t.Errorf("Outcome() = %q, want %q", resolved[0].Outcome(), OutcomeEscalated)
+	}
+	if len(tracker.Active()) != 1 {
+		t.Errorf("expected 1 active issue, got %d", len(tracker.Active()))
+	}
+}
+
+func TestTrackerUnknownFingerprint(t *testing.T) {
+	tracker := NewTracker()
+	if _, first := tracker.RecordRemediation("unknown", true, time.Now()); first {
+		t.Error("remediation of an untracked issue should not be reported")
+	}
+}
+
+func TestTrackerDiff(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+	web, api := newIssue("web-1"), newIssue("api-1")
+
+	transitions := tracker.Diff([]detection.Issue{web, web}, start, 5*time.Minute)
+	if len(transitions.New) != 1 || len(transitions.Reverify) != 0 {
+		t.Fatalf("expected one new issue, got %+v", transitions)
+	}
+
+	// Still active and not yet due for re-verification
+	transitions = tracker.Diff([]detection.Issue{web, api}, start.Add(time.Minute), 5*time.Minute)
+	if len(transitions.New) != 1 || transitions.New[0].Name != "api-1" || transitions.Unchanged != 1 {
+		t.Errorf("expected api-1 new and web-1 unchanged, got %+v", transitions)
+	}
+	if len(transitions.Actionable()) != 1 {
+		t.Errorf("expected only the new issue to be actionable, got %d", len(transitions.Actionable()))
+	}
+
+	// Due for re-verification
+	transitions = tracker.Diff([]detection.Issue{web, api}, start.Add(5*time.Minute), 5*time.Minute)
+	if len(transitions.Reverify) != 1 || transitions.Reverify[0].Name != "web-1" || transitions.Unchanged != 1 {
+		t.Errorf("expected web-1 to be re-verified, got %+v", transitions)
+	}
+
+	// Resolved
+	transitions = tracker.Diff([]detection.Issue{api}, start.Add(6*time.Minute), 5*time.Minute)
+	if len(transitions.Resolved) != 1 || transitions.Resolved[0].Name != "web-1" {
+		t.Errorf("expected web-1 to be resolved, got %+v", transitions)
+	}
+}
+
+func TestNilTrackerDiff(t *testing.T) {
+	var tracker *Tracker
+	transitions := tracker.Diff([]detection.Issue{newIssue("web-1")}, time.Now(), time.Minute)
+	if len(transitions.Actionable()) != 1 {
+		t.Error("nil tracker should treat every issue as new")
+	}
+}
+
+func TestTrackerDiffWithin(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+
+	other := newIssue("api-1")
+	other.Namespace = "payments"
+	tracker.Diff([]detection.Issue{newIssue("web-1"), other}, start, time.Hour)
+
+	// A cycle scoped to the default namespace does not resolve issues elsewhere
+	inDefault := func(r Record) bool { return r.Namespace == "default" }
+	transitions := tracker.DiffWithin(nil, start.Add(time.Minute), time.Hour, inDefault)
+	if len(transitions.Resolved) != 1 || transitions.Resolved[0].Name != "web-1" {
+		t.Fatalf("Resolved = %+v, want only web-1", transitions.Resolved)
+	}
+	if active := tracker.Active(); len(active) != 1 || active[0].Name != "api-1" {
+		t.Errorf("Active() = %+v, want only api-1", active)
+	}
+}
+
+func TestTrackerEscalatesLongLivedIssues(t *testing.T) {
+	tracker := NewTracker()
+	tracker.SetEscalation(EscalationPolicy{
+		Steps:      []EscalationStep{{After: time.Hour, Severity: "high"}, {After: 4 * time.Hour, Severity: "critical"}},
+		Hysteresis: 10 * time.Minute,
+	})
+	start := time.Now()
+	issue := newIssue("web-1")
+	issue.Severity = "medium"
+
+	transitions := tracker.Diff([]detection.Issue{issue}, start, time.Hour)
+	if len(transitions.New) != 1 || transitions.New[0].Severity != "medium" || len(transitions.Escalated) != 0 {
+		t.Fatalf("expected a new medium issue, got %+v", transitions)
+	}
+
+	// Escalated issues are re-verified before their re-verification interval
+	transitions = tracker.Diff([]detection.Issue{issue}, start.Add(time.Hour), 2*time.Hour)
+	if len(transitions.Escalated) != 1 || len(transitions.Reverify) != 1 || transitions.Reverify[0].Severity != "high" || transitions.Reverify[0].EscalatedFrom != "medium" {
+		t.Fatalf("expected the issue to be escalated to high, got %+v", transitions)
+	}
+	transitions = tracker.Diff([]detection.Issue{issue}, start.Add(2*time.Hour), 2*time.Hour)
+	if len(transitions.Escalated) != 0 || transitions.Unchanged != 1 {
+		t.Errorf("expected an escalated issue to be escalated once, got %+v", transitions)
+	}
+
+	// An issue that disappears briefly keeps its severity and active time
+	tracker.Diff(nil, start.Add(3*time.Hour), 0)
+	transitions = tracker.Diff([]detection.Issue{issue}, start.Add(3*time.Hour+5*time.Minute), 0)
+	if len(transitions.New) != 1 || transitions.New[0].Severity != "high" {
+		t.Fatalf("expected the recurring issue to stay high, got %+v", transitions)
+	}
+	transitions = tracker.Diff([]detection.Issue{issue}, start.Add(4*time.Hour), 0)
+	if len(transitions.Escalated) != 1 || transitions.Escalated[0].Severity != "critical" {
+		t.Fatalf("expected the issue to be escalated to critical, got %+v", transitions)
+	}
+	if active := tracker.Active(); len(active) != 1 || active[0].EffectiveSeverity() != "critical" || !active[0].ActiveSince.Equal(start) {
+		t.Errorf("unexpected active record: %+v", active)
+	}
+
+	// After the hysteresis the issue starts over
+	tracker.Diff(nil, start.Add(5*time.Hour), 0)
+	transitions = tracker.Diff([]detection.Issue{issue}, start.Add(5*time.Hour+11*time.Minute), 0)
+	if len(transitions.New) != 1 || transitions.New[0].Severity != "medium" {
+		t.Errorf("expected the issue to start over at medium, got %+v", transitions)
+	}
+}
+
+func TestTrackerDoesNotLowerSeverity(t *testing.T) {
+	tracker := NewTracker()
+	tracker.SetEscalation(EscalationPolicy{Steps: []EscalationStep{{After: time.Hour, Severity: "high"}}})
+	start := time.Now()
+	issue := newIssue("web-1")
+	issue.Severity = "critical"
+
+	tracker.Diff([]detection.Issue{issue}, start, 0)
+	transitions := tracker.Diff([]detection.Issue{issue}, start.Add(2*time.Hour), 0)
+	if len(transitions.Escalated) != 0 || transitions.Reverify[0].Severity != "critical" || transitions.Reverify[0].EscalatedFrom != "" {
+		t.Errorf("expected a critical issue to stay critical, got %+v", transitions)
+	}
+}
+
+func TestTrackerSnapshotsResourceAtDetection(t *testing.T) {
+	tracker := NewTracker()
+	start := time.Now()
+	issue := newIssue("web-1")
+	issue.Resource = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", ResourceVersion: "7"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "web:1.4"}}},
+	}
+	tracker.Observe([]detection.Issue{issue}, start)
+
+	// Later changes of the resource keep the snapshot taken at detection time
+	issue.Resource = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", ResourceVersion: "9"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "web:1.5"}}},
+	}
+	tracker.Observe([]detection.Issue{issue}, start.Add(time.Minute))
+
+	active := tracker.Active()
+	if len(active) != 1 || active[0].Snapshot == nil {
+		t.Fatalf("Active() = %+v, want a record with a snapshot", active)
+	}
+	if snapshot := active[0].Snapshot; snapshot.ResourceVersion != "7" || len(snapshot.Images) != 1 || snapshot.Images[0] != "web:1.4" {
+		t.Errorf("Snapshot = %+v, want the pod at detection time", snapshot)
+	}
+}
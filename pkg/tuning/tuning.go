@@ -0,0 +1,151 @@
+package tuning
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// DefaultCgroupRoot is where the cgroup filesystem is mounted in containers
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// unlimitedMemory is the smallest cgroup v1 memory limit treated as unlimited;
+// v1 reports no limit as a page-aligned value close to math.MaxInt64
+const unlimitedMemory = int64(1) << 62
+
+// Config tunes the Go runtime to the limits of the container. Each setting is
+// left alone when its environment variable (GOMAXPROCS, GOMEMLIMIT, GOGC) is set.
+type Config struct {
+	// AutoMaxProcs sets GOMAXPROCS to the CPU quota, rounded up
+	AutoMaxProcs bool
+	// MemoryLimitRatio sets the soft memory limit of the runtime to this fraction
+	// of the container memory limit, so the GC works harder before the container
+	// is OOM killed; 0 disables it
+	MemoryLimitRatio float64
+	// GCPercent is the GC target percentage; 0 keeps the runtime default
+	GCPercent int
+	// CgroupRoot is where the cgroup filesystem is mounted; empty uses DefaultCgroupRoot
+	CgroupRoot string
+}
+
+// Settings are the limits found and the runtime settings applied
+type Settings struct {
+	// CPUQuota is the CPU quota of the container in cores; 0 is unlimited
+	CPUQuota float64
+	// MaxProcs is GOMAXPROCS after tuning
+	MaxProcs int
+	// MemoryLimit is the memory limit of the container in bytes; 0 is unlimited
+	MemoryLimit int64
+	// SoftMemoryLimit is the soft memory limit set on the runtime; 0 if not set
+	SoftMemoryLimit int64
+	// GCPercent is the GC target percentage set on the runtime; 0 if not set
+	GCPercent int
+}
+
+// Apply tunes the Go runtime to the CPU and memory limits of the container
+func Apply(cfg Config) Settings {
+	root := cfg.CgroupRoot
+	if root == "" {
+		root = DefaultCgroupRoot
+	}
+
+	var settings Settings
+	if quota, ok := CPUQuota(root); ok {
+		settings.CPUQuota = quota
+		if cfg.AutoMaxProcs && os.Getenv("GOMAXPROCS") == "" {
+			runtime.GOMAXPROCS(maxProcs(quota, runtime.NumCPU()))
+		}
+	}
+	settings.MaxProcs = runtime.GOMAXPROCS(0)
+
+	if limit, ok := MemoryLimit(root); ok {
+		settings.MemoryLimit = limit
+		if cfg.MemoryLimitRatio > 0 && os.Getenv("GOMEMLIMIT") == "" {
+			settings.SoftMemoryLimit = int64(float64(limit) * cfg.MemoryLimitRatio)
+			debug.SetMemoryLimit(settings.SoftMemoryLimit)
+		}
+	}
+
+	if cfg.GCPercent > 0 && os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(cfg.GCPercent)
+		settings.GCPercent = cfg.GCPercent
+	}
+
+	return settings
+}
+
+// maxProcs returns the GOMAXPROCS value for a CPU quota: the quota rounded up,
+// at least 1 and at most the number of CPUs
+func maxProcs(quota float64, cpus int) int {
+	procs := int(math.Ceil(quota))
+	if procs < 1 {
+		procs = 1
+	}
+	if procs > cpus {
+		procs = cpus
+	}
+	return procs
+}
+
+// CPUQuota returns the CPU quota of the cgroup in cores, from cpu.max (cgroup v2)
+// or cpu.cfs_quota_us and cpu.cfs_period_us (cgroup v1). It returns false if
+// there is no quota.
+func CPUQuota(root string) (float64, bool) {
+	if data, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return quotaCores(fields[0], fields[1])
+	}
+
+	quota, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+	return quotaCores(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+// quotaCores divides a CFS quota by its period; a negative quota is unlimited
+func quotaCores(quota, period string) (float64, bool) {
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return float64(q) / float64(p), true
+}
+
+// MemoryLimit returns the memory limit of the cgroup in bytes, from memory.max
+// (cgroup v2) or memory.limit_in_bytes (cgroup v1). It returns false if there
+// is no limit.
+func MemoryLimit(root string) (int64, bool) {
+	data, err := os.ReadFile(filepath.Join(root, "memory.max"))
+	if err != nil {
+		data, err = os.ReadFile(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+		if err != nil {
+			return 0, false
+		}
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "max" {
+		return 0, false
+	}
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 || limit >= unlimitedMemory {
+		return 0, false
+	}
+	return limit, true
+}
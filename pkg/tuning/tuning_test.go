@@ -0,0 +1,89 @@
+package tuning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates a fake cgroup filesystem
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCPUQuota(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		want   float64
+		wantOK bool
+	}{
+		{"cgroup v2", map[string]string{"cpu.max": "150000 100000\n"}, 1.5, true},
+		{"cgroup v2 unlimited", map[string]string{"cpu.max": "max 100000\n"}, 0, false},
+		{"cgroup v1", map[string]string{"cpu/cpu.cfs_quota_us": "50000\n", "cpu/cpu.cfs_period_us": "100000\n"}, 0.5, true},
+		{"cgroup v1 unlimited", map[string]string{"cpu/cpu.cfs_quota_us": "-1\n", "cpu/cpu.cfs_period_us": "100000\n"}, 0, false},
+		{"no cgroup", map[string]string{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CPUQuota(writeFiles(t, tt.files))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("CPUQuota() = %v, %t, want %v, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		want   int64
+		wantOK bool
+	}{
+		{"cgroup v2", map[string]string{"memory.max": "268435456\n"}, 268435456, true},
+		{"cgroup v2 unlimited", map[string]string{"memory.max": "max\n"}, 0, false},
+		{"cgroup v1", map[string]string{"memory/memory.limit_in_bytes": "134217728\n"}, 134217728, true},
+		{"cgroup v1 unlimited", map[string]string{"memory/memory.limit_in_bytes": "9223372036854771712\n"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MemoryLimit(writeFiles(t, tt.files))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("MemoryLimit() = %d, %t, want %d, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMaxProcs(t *testing.T) {
+	tests := []struct {
+		quota float64
+		cpus  int
+		want  int
+	}{
+		{0.5, 8, 1},
+		{1.5, 8, 2},
+		{4, 8, 4},
+		{16, 8, 8},
+	}
+
+	for _, tt := range tests {
+		if got := maxProcs(tt.quota, tt.cpus); got != tt.want {
+			t.Errorf("maxProcs(%v, %d) = %d, want %d", tt.quota, tt.cpus, got, tt.want)
+		}
+	}
+}